@@ -0,0 +1,578 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	yamlenv "github.com/ifuryst/go-yaml-env"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/ifuryst/ripple/internal/config"
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service"
+	"github.com/ifuryst/ripple/internal/service/notion"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/internal/service/source"
+	"github.com/ifuryst/ripple/pkg/httpclient"
+	"github.com/ifuryst/ripple/pkg/logger"
+)
+
+// Output formats for the one-shot commands
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+var (
+	outputFormat     string
+	syncPublish      bool
+	publishPlatforms string
+	previewPlatform  string
+	listStatus       string
+	exportOutDir     string
+	importPlatforms  string
+	importDryRun     bool
+	backupOut        string
+	restoreForce     bool
+	restoreConfigOut string
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync pages from Notion and the configured sources once and exit",
+	Long: `Sync every page from the Notion database and the enabled sources (such as a markdown
+directory) into the local database.
+With --publish, pending pages are published and expired pages unpublished afterwards,
+the same cycle the server scheduler runs.`,
+	Args: cobra.NoArgs,
+	RunE: runSync,
+}
+
+var publishCmd = &cobra.Command{
+	Use:   "publish <page-id>",
+	Short: "Publish a synced page and exit",
+	Long:  `Publish a page to the platforms listed on it in Notion, or only to the platforms given with --platform.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPublish,
+}
+
+var previewCmd = &cobra.Command{
+	Use:   "preview <page-id>",
+	Short: "Print a page as it would be published to a platform",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPreview,
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export <page-id>",
+	Short: "Export a page in every platform format to a local directory",
+	Long: `Write the output of every registered publisher (markdown, WeChat HTML, Substack JSON),
+the raw Notion blocks and the page's images to a directory for archival and debugging.
+Nothing is uploaded or published.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExport,
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import posts already published on the platforms as completed jobs",
+	Long: `List the posts already on the platforms (the al-folio _posts and _notes, Substack posts
+and drafts), match them to synced pages by title and then by slug, and record each match
+as a completed job so the page isn't published there again.
+Posts matching no page, or several, are reported and left alone.`,
+	Args: cobra.NoArgs,
+	RunE: runImport,
+}
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Write a backup archive of the Ripple state",
+	Long: `Write the pages, jobs, platforms, monitoring data and platform configs to a gzipped JSON
+archive, for moving to another server or disaster recovery. Platform secrets are encrypted
+with BACKUP_PASSPHRASE, or left out when it isn't set.`,
+	Args: cobra.NoArgs,
+	RunE: runBackup,
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <archive>",
+	Short: "Restore a backup archive into the database",
+	Long: `Restore an archive written by backup. The database must not have pages, jobs or platforms
+yet unless --force is given, which replaces them.
+Platform configs are not applied, the configuration still defines the platforms; with
+--platform-config-out they are written to a file, decrypted with BACKUP_PASSPHRASE.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRestore,
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List synced resources",
+}
+
+var listPagesCmd = &cobra.Command{
+	Use:   "pages",
+	Short: "List synced Notion pages",
+	Args:  cobra.NoArgs,
+	RunE:  runListPages,
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{syncCmd, publishCmd, previewCmd, exportCmd, importCmd, backupCmd, restoreCmd, listPagesCmd} {
+		cmd.Flags().StringVarP(&outputFormat, "output", "o", outputTable, "output format: table or json")
+	}
+	syncCmd.Flags().BoolVar(&syncPublish, "publish", false, "publish pending pages after syncing")
+	publishCmd.Flags().StringVar(&publishPlatforms, "platform", "", "comma-separated platforms to publish to (default: the page's platforms)")
+	previewCmd.Flags().StringVar(&previewPlatform, "platform", "", "platform to render the page for")
+	previewCmd.MarkFlagRequired("platform")
+	exportCmd.Flags().StringVar(&exportOutDir, "out", "", "directory to write the export to (default: the page ID)")
+	importCmd.Flags().StringVar(&importPlatforms, "platform", "", "comma-separated platforms to import from (default: every platform that can list its posts)")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "only report the matches, record nothing")
+	backupCmd.Flags().StringVar(&backupOut, "out", "", "file to write the archive to (default: ripple-backup-<time>.json.gz)")
+	restoreCmd.Flags().BoolVar(&restoreForce, "force", false, "replace the pages, jobs and platforms already in the database")
+	restoreCmd.Flags().StringVar(&restoreConfigOut, "platform-config-out", "", "write the platform configs of the backup to this file")
+	listPagesCmd.Flags().StringVar(&listStatus, "status", "", "only list pages with this Notion status")
+
+	listCmd.AddCommand(listPagesCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(publishCmd)
+	rootCmd.AddCommand(previewCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(restoreCmd)
+	rootCmd.AddCommand(listCmd)
+}
+
+// cliServices holds the services a one-shot command needs, without the HTTP server
+type cliServices struct {
+	config           *config.Config
+	logger           *zap.Logger
+	db               *gorm.DB
+	notionService    *notion.Service
+	sourceSyncer     *source.Syncer
+	publisherService *service.PublisherService
+}
+
+func newCLIServices() (*cliServices, error) {
+	if outputFormat != outputTable && outputFormat != outputJSON {
+		return nil, fmt.Errorf("unknown output format %q, use table or json", outputFormat)
+	}
+
+	cfg, err := yamlenv.LoadConfig[config.Config](configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	appLogger, err := logger.NewLogger(cfg.Logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize logger: %w", err)
+	}
+
+	if err := httpclient.Configure(cfg.HTTP, appLogger); err != nil {
+		return nil, fmt.Errorf("failed to configure HTTP client: %w", err)
+	}
+
+	db, err := service.NewDatabase(&cfg.Database, appLogger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+
+	notionService := notion.NewService(&cfg.Notion, db, appLogger)
+	return &cliServices{
+		config:           cfg,
+		logger:           appLogger,
+		db:               db,
+		notionService:    notionService,
+		sourceSyncer:     source.NewSyncerFromConfig(&cfg.Sources, db, appLogger),
+		publisherService: service.NewPublisherService(cfg, db, appLogger, notionService),
+	}, nil
+}
+
+func (c *cliServices) close() {
+	c.logger.Sync()
+	if sqlDB, err := c.db.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	services, err := newCLIServices()
+	if err != nil {
+		return err
+	}
+	defer services.close()
+
+	// A sync or publish running on a server sharing the database finishes first
+	locker := services.publisherService.Locker()
+	var report *models.SyncRun
+	if err := locker.Run(cmd.Context(), service.LockSync, func() error {
+		if services.sourceSyncer.HasSources() {
+			if err := services.sourceSyncer.SyncAll(cmd.Context()); err != nil {
+				return fmt.Errorf("source sync failed: %w", err)
+			}
+		}
+
+		var err error
+		report, err = services.notionService.SyncPages()
+		if err != nil {
+			return fmt.Errorf("sync failed: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if syncPublish {
+		if err := locker.Run(cmd.Context(), service.LockPublish, func() error {
+			if err := services.publisherService.ProcessPendingPages(cmd.Context()); err != nil {
+				return fmt.Errorf("publishing pending pages failed: %w", err)
+			}
+			if err := services.publisherService.ProcessExpiredPages(cmd.Context()); err != nil {
+				return fmt.Errorf("processing expired pages failed: %w", err)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	pages, err := services.notionService.GetAllPages()
+	if err != nil {
+		return err
+	}
+
+	if outputFormat == outputJSON {
+		return printJSON(map[string]interface{}{
+			"pages":     len(pages),
+			"published": syncPublish,
+			"report":    report,
+		})
+	}
+
+	fmt.Printf("Synced %d pages (scanned %d: %d created, %d updated, %d unchanged, %d failed, %d archived)\n",
+		len(pages), report.Scanned, report.Created, report.Updated, report.Skipped, report.Failed, report.Archived)
+	for _, pageErr := range report.Errors {
+		fmt.Printf("  failed %s %q: %s\n", pageErr.PageID, pageErr.Title, pageErr.Error)
+	}
+	if syncPublish {
+		fmt.Println("Published pending pages and unpublished expired pages")
+	}
+	return nil
+}
+
+func runPublish(cmd *cobra.Command, args []string) error {
+	services, err := newCLIServices()
+	if err != nil {
+		return err
+	}
+	defer services.close()
+
+	pageID := notion.NormalizePageID(args[0])
+
+	results := make(map[string]*publisher.PublishResult)
+	if publishPlatforms == "" {
+		results, err = services.publisherService.PublishPage(cmd.Context(), pageID)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, platformName := range strings.Split(publishPlatforms, ",") {
+			platformName = strings.TrimSpace(platformName)
+			if platformName == "" {
+				continue
+			}
+			result, err := services.publisherService.PublishPageToPlatform(cmd.Context(), pageID, platformName)
+			if err != nil {
+				result = &publisher.PublishResult{Success: false, Error: err, ErrorMsg: err.Error()}
+			}
+			results[platformName] = result
+		}
+	}
+
+	platforms := make([]string, 0, len(results))
+	failed := 0
+	for platformName, result := range results {
+		platforms = append(platforms, platformName)
+		if result.ErrorMsg == "" && result.Error != nil {
+			result.ErrorMsg = result.Error.Error()
+		}
+		if !result.Success {
+			failed++
+		}
+	}
+	sort.Strings(platforms)
+
+	if outputFormat == outputJSON {
+		if err := printJSON(results); err != nil {
+			return err
+		}
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PLATFORM\tSUCCESS\tPUBLISH ID\tURL\tERROR")
+		for _, platformName := range platforms {
+			result := results[platformName]
+			fmt.Fprintf(w, "%s\t%t\t%s\t%s\t%s\n", platformName, result.Success, result.PublishID, result.URL, result.ErrorMsg)
+		}
+		w.Flush()
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("publish failed on %d platform(s)", failed)
+	}
+	return nil
+}
+
+func runPreview(cmd *cobra.Command, args []string) error {
+	services, err := newCLIServices()
+	if err != nil {
+		return err
+	}
+	defer services.close()
+
+	content, err := services.publisherService.PreviewPage(cmd.Context(), args[0], previewPlatform)
+	if err != nil {
+		return err
+	}
+
+	if outputFormat == outputJSON {
+		return printJSON(content)
+	}
+
+	fmt.Println(content.Content)
+	return nil
+}
+
+func runListPages(cmd *cobra.Command, args []string) error {
+	services, err := newCLIServices()
+	if err != nil {
+		return err
+	}
+	defer services.close()
+
+	pages, err := services.notionService.GetAllPages()
+	if err != nil {
+		return err
+	}
+
+	if listStatus != "" {
+		filtered := pages[:0]
+		for _, page := range pages {
+			if strings.EqualFold(page.Status, listStatus) {
+				filtered = append(filtered, page)
+			}
+		}
+		pages = filtered
+	}
+
+	if outputFormat == outputJSON {
+		// Content is left out to keep the listing readable
+		for i := range pages {
+			pages[i].Content = ""
+		}
+		return printJSON(pages)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PAGE ID\tTITLE\tSTATUS\tPLATFORMS\tLAST MODIFIED")
+	for _, page := range pages {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", page.NotionID, page.Title, page.Status,
+			strings.Join(page.Platforms, ","), page.LastModified.Format("2006-01-02 15:04"))
+	}
+	w.Flush()
+	return nil
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	services, err := newCLIServices()
+	if err != nil {
+		return err
+	}
+	defer services.close()
+
+	outDir := exportOutDir
+	if outDir == "" {
+		outDir = notion.NormalizePageID(args[0])
+	}
+
+	result, err := services.publisherService.ExportPage(cmd.Context(), args[0], outDir)
+	if err != nil {
+		return err
+	}
+
+	if outputFormat == outputJSON {
+		return printJSON(result)
+	}
+
+	fmt.Printf("Exported %s to %s\n\n", result.Title, result.Dir)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tERROR")
+	for _, file := range append(result.Files, result.Images...) {
+		fmt.Fprintf(w, "%s\t\n", file)
+	}
+	for name, message := range result.Errors {
+		fmt.Fprintf(w, "%s\t%s\n", name, message)
+	}
+	w.Flush()
+	return nil
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	services, err := newCLIServices()
+	if err != nil {
+		return err
+	}
+	defer services.close()
+
+	request := service.ImportRequest{DryRun: importDryRun}
+	for _, platformName := range strings.Split(importPlatforms, ",") {
+		if platformName = strings.TrimSpace(platformName); platformName != "" {
+			request.Platforms = append(request.Platforms, platformName)
+		}
+	}
+
+	report, err := services.publisherService.ImportExistingPosts(cmd.Context(), request)
+	if err != nil {
+		return err
+	}
+
+	if outputFormat == outputJSON {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PLATFORM\tPUBLISH ID\tPAGE ID\tTITLE\tMATCHED BY\tOUTCOME")
+		for _, match := range report.Matches {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", match.Platform, match.PublishID, match.PageID,
+				match.Title, match.MatchedBy, match.Outcome)
+		}
+		for _, post := range report.Unmatched {
+			fmt.Fprintf(w, "%s\t%s\t-\t%s\t-\t%s\n", post.Platform, post.PublishID, post.Title, post.Reason)
+		}
+		w.Flush()
+
+		fmt.Printf("\nMatched %d posts, imported %d, %d unmatched\n", len(report.Matches), report.Imported, len(report.Unmatched))
+		for name, message := range report.Errors {
+			fmt.Printf("  failed %s: %s\n", name, message)
+		}
+	}
+
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("import failed for %d platform(s) or post(s)", len(report.Errors))
+	}
+	return nil
+}
+
+func runBackup(cmd *cobra.Command, args []string) error {
+	services, err := newCLIServices()
+	if err != nil {
+		return err
+	}
+	defer services.close()
+
+	path := backupOut
+	if path == "" {
+		path = fmt.Sprintf("ripple-backup-%s.json.gz", time.Now().Format("20060102-150405"))
+	}
+
+	// The archive holds encrypted secrets and page contents, so it is only readable by the owner
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+
+	report, err := services.publisherService.Backup(cmd.Context(), file)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	if outputFormat == outputJSON {
+		return printJSON(map[string]interface{}{
+			"file":   path,
+			"report": report,
+		})
+	}
+
+	fmt.Printf("Wrote backup to %s\n", path)
+	printTableCounts(report.Tables)
+	if !report.SecretsEncrypted {
+		fmt.Println("BACKUP_PASSPHRASE is not set, platform secrets were left out")
+	}
+	return nil
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	services, err := newCLIServices()
+	if err != nil {
+		return err
+	}
+	defer services.close()
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	report, err := services.publisherService.Restore(cmd.Context(), file, service.RestoreOptions{Force: restoreForce})
+	if err != nil {
+		return err
+	}
+
+	if restoreConfigOut != "" {
+		data, err := json.MarshalIndent(report.PlatformConfigs, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(restoreConfigOut, data, 0600); err != nil {
+			return fmt.Errorf("failed to write platform configs: %w", err)
+		}
+	}
+
+	if outputFormat == outputJSON {
+		return printJSON(report)
+	}
+
+	fmt.Printf("Restored backup from %s\n", report.BackupCreatedAt.Format("2006-01-02 15:04:05"))
+	printTableCounts(report.Tables)
+	if restoreConfigOut != "" {
+		fmt.Printf("Wrote the configs of %s to %s\n", strings.Join(report.Platforms, ", "), restoreConfigOut)
+		if !report.SecretsDecrypted {
+			fmt.Println("Platform secrets are still encrypted or missing, set BACKUP_PASSPHRASE to the passphrase of the backup")
+		}
+	}
+	return nil
+}
+
+func printTableCounts(tables map[string]int) {
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TABLE\tROWS")
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\t%d\n", name, tables[name])
+	}
+	w.Flush()
+}
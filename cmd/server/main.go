@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"text/tabwriter"
 
 	yamlenv "github.com/ifuryst/go-yaml-env"
 	"github.com/spf13/cobra"
@@ -14,11 +16,14 @@ import (
 
 	"github.com/ifuryst/ripple/internal/config"
 	"github.com/ifuryst/ripple/internal/server"
+	"github.com/ifuryst/ripple/internal/service"
+	"github.com/ifuryst/ripple/pkg/httpclient"
 	"github.com/ifuryst/ripple/pkg/logger"
 )
 
 var (
 	configPath string
+	webDir     string
 	version    = "0.1.0"
 	gitCommit  = "unknown"
 	buildTime  = "unknown"
@@ -41,9 +46,73 @@ var versionCmd = &cobra.Command{
 	},
 }
 
+var (
+	rerunPlatforms string
+	rerunForce     bool
+)
+
+var rerunCmd = &cobra.Command{
+	Use:   "rerun <page-id>",
+	Short: "Re-sync a page from Notion and republish it",
+	Long: `Re-sync a single page from Notion, refresh its content and republish it to every platform.
+Platforms that are already up to date are skipped unless --force is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRerun,
+}
+
+var workerCount int
+
+var workerCmd = &cobra.Command{
+	Use:   "worker",
+	Short: "Run publish workers consuming the publish queue",
+	Long: `Run publish workers that publish the pages the server schedulers queue, until interrupted.
+Run servers with QUEUE_WORKERS=0 and as many worker processes as needed to scale publishing
+out while the servers only serve the API.`,
+	Args: cobra.NoArgs,
+	RunE: runWorker,
+}
+
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "configs/server.yaml", "config file path")
+	rootCmd.Flags().StringVar(&webDir, "web-dir", "", "serve the dashboard from this directory instead of the embedded build")
+	rerunCmd.Flags().StringVar(&rerunPlatforms, "platform", "all", "comma-separated platforms to republish, or all")
+	rerunCmd.Flags().BoolVar(&rerunForce, "force", false, "republish even if the content has not changed")
+	workerCmd.Flags().IntVar(&workerCount, "workers", 0, "number of publish workers (default: queue.workers, at least 1)")
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(rerunCmd)
+	rootCmd.AddCommand(workerCmd)
+}
+
+func runRerun(cmd *cobra.Command, args []string) error {
+	services, err := newCLIServices()
+	if err != nil {
+		return err
+	}
+	defer services.close()
+
+	report, err := services.publisherService.RerunPage(cmd.Context(), args[0], strings.Split(rerunPlatforms, ","), rerunForce)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Page: %s (%s)\n", report.Title, report.PageID)
+	fmt.Printf("Content changed: %t\n\n", report.ContentChanged)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PLATFORM\tACTION\tPREVIOUS\tCURRENT\tERROR")
+	failed := 0
+	for _, result := range report.Platforms {
+		if result.Action == service.RerunActionFailed {
+			failed++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", result.Platform, result.Action, result.PreviousPublishID, result.PublishID, result.Error)
+	}
+	w.Flush()
+
+	if failed > 0 {
+		return fmt.Errorf("rerun failed on %d platform(s)", failed)
+	}
+	return nil
 }
 
 func runServer(*cobra.Command, []string) error {
@@ -52,6 +121,9 @@ func runServer(*cobra.Command, []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	if webDir != "" {
+		cfg.Server.WebDir = webDir
+	}
 
 	// Initialize logger
 	appLogger, err := logger.NewLogger(cfg.Logger)
@@ -60,6 +132,10 @@ func runServer(*cobra.Command, []string) error {
 	}
 	defer appLogger.Sync()
 
+	if err := httpclient.Configure(cfg.HTTP, appLogger); err != nil {
+		return fmt.Errorf("failed to configure HTTP client: %w", err)
+	}
+
 	appLogger.Info("Starting Ripple server", zap.String("version", version))
 
 	// Create server
@@ -67,6 +143,7 @@ func runServer(*cobra.Command, []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to create server: %w", err)
 	}
+	srv.ConfigPath = configPath
 
 	// Start server
 	ctx, cancel := context.WithCancel(context.Background())
@@ -82,15 +159,27 @@ func runServer(*cobra.Command, []string) error {
 		}
 	}()
 
-	// Wait for interrupt signal
+	// Wait for interrupt signal, reloading the configuration on SIGHUP
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-
-	select {
-	case <-quit:
-		appLogger.Info("Shutting down server...")
-	case <-ctx.Done():
-		appLogger.Info("Server context cancelled")
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+
+wait:
+	for {
+		select {
+		case <-reload:
+			appLogger.Info("Received SIGHUP, reloading configuration")
+			if _, err := srv.Reload(); err != nil {
+				appLogger.Error("Failed to reload configuration", zap.Error(err))
+			}
+		case <-quit:
+			appLogger.Info("Shutting down server...")
+			break wait
+		case <-ctx.Done():
+			appLogger.Info("Server context cancelled")
+			break wait
+		}
 	}
 
 	// Graceful shutdown
@@ -103,6 +192,41 @@ func runServer(*cobra.Command, []string) error {
 	return nil
 }
 
+func runWorker(cmd *cobra.Command, args []string) error {
+	services, err := newCLIServices()
+	if err != nil {
+		return err
+	}
+	defer services.close()
+
+	queue, err := service.NewTaskQueue(services.config.Queue, services.db, services.logger)
+	if err != nil {
+		return fmt.Errorf("failed to initialize publish queue: %w", err)
+	}
+	defer queue.Close()
+
+	freeze, err := service.ParseFreezeSchedule(services.config.Scheduler.Freeze)
+	if err != nil {
+		return fmt.Errorf("invalid freeze settings: %w", err)
+	}
+
+	count := workerCount
+	if count <= 0 {
+		count = max(services.config.Queue.Workers, 1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Publishes in progress aren't cancelled by the signal, Stop waits for them
+	workers := service.NewPublishWorkers(services.publisherService, queue, freeze.Status, services.logger, count)
+	workers.Start(context.Background())
+	<-ctx.Done()
+	services.logger.Info("Shutting down publish workers...")
+	workers.Stop()
+	return nil
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -1,18 +1,25 @@
 package config
 
 import (
+	"github.com/ifuryst/ripple/pkg/httpclient"
 	"github.com/ifuryst/ripple/pkg/logger"
 	"time"
 )
 
 type Config struct {
-	Server    ServerConfig    `yaml:"server"`
-	Database  DatabaseConfig  `yaml:"database"`
-	Logger    logger.Config   `yaml:"logger"`
-	Notion    NotionConfig    `yaml:"notion"`
-	Scheduler SchedulerConfig `yaml:"scheduler"`
-	Publisher PublisherConfig `yaml:"publisher"`
-	Auth      AuthConfig      `yaml:"auth"`
+	Server    ServerConfig      `yaml:"server"`
+	Database  DatabaseConfig    `yaml:"database"`
+	Logger    logger.Config     `yaml:"logger"`
+	HTTP      httpclient.Config `yaml:"http"`
+	Notion    NotionConfig      `yaml:"notion"`
+	Sources   SourcesConfig     `yaml:"sources"`
+	AI        AIConfig          `yaml:"ai"`
+	Scheduler SchedulerConfig   `yaml:"scheduler"`
+	Publisher PublisherConfig   `yaml:"publisher"`
+	Auth      AuthConfig        `yaml:"auth"`
+	Backup    BackupConfig      `yaml:"backup"`
+	Retention RetentionConfig   `yaml:"retention"`
+	Queue     QueueConfig       `yaml:"queue"`
 }
 
 type ServerConfig struct {
@@ -21,6 +28,11 @@ type ServerConfig struct {
 	Mode     string `yaml:"mode"`
 	CertFile string `yaml:"cert_file"`
 	KeyFile  string `yaml:"key_file"`
+	// WebDir serves the dashboard from a directory instead of the embedded build
+	WebDir string `yaml:"web_dir"`
+	// DashboardCacheTTL is how long the dashboard summary and platform stats are served from
+	// memory; 0 disables the cache, responses still carry ETags
+	DashboardCacheTTL time.Duration `yaml:"dashboard_cache_ttl"`
 }
 
 type DatabaseConfig struct {
@@ -32,23 +44,314 @@ type DatabaseConfig struct {
 	Database string `yaml:"database"`
 	SSLMode  string `yaml:"ssl_mode"`
 	TimeZone string `yaml:"timezone"`
+	// AdvisoryLocks coordinates replicas sharing the database with Postgres advisory locks;
+	// disable it behind a connection pooler in transaction mode
+	AdvisoryLocks bool `yaml:"advisory_locks"`
+
+	// Connection pool of each process; 0 leaves a limit unset
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+
+	// QueryLogLevel is silent, error (failed queries), warn (failed and slow queries) or info
+	// (every query); queries taking SlowQueryThreshold or longer are slow, 0 disables it
+	QueryLogLevel      string        `yaml:"query_log_level"`
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold"`
+
+	// PrepareStmt caches prepared statements per connection. SimpleProtocol sends queries
+	// without the implicit prepared statements of the extended protocol, for connection
+	// poolers in transaction mode.
+	PrepareStmt    bool `yaml:"prepare_stmt"`
+	SimpleProtocol bool `yaml:"simple_protocol"`
 }
 
 type NotionConfig struct {
 	Token      string `yaml:"token"`
 	DatabaseID string `yaml:"database_id"`
 	APIVersion string `yaml:"api_version"`
+	// RateLimit is the number of requests per second sent to the Notion API
+	RateLimit      float64 `yaml:"rate_limit"`
+	MaxConcurrency int     `yaml:"max_concurrency"`
+	// MaxRetries is how often a request throttled with 429 is retried before giving up
+	MaxRetries int `yaml:"max_retries"`
+	// SyncConcurrency is the number of pages synced at once
+	SyncConcurrency int `yaml:"sync_concurrency"`
+	// ArchivedRetention is how long pages archived or deleted in Notion are kept before they
+	// can be purged
+	ArchivedRetention time.Duration `yaml:"archived_retention"`
+	// FailureComments comments on pages that failed to publish, notifying their owners
+	FailureComments FailureCommentConfig `yaml:"failure_comments"`
+}
+
+// FailureCommentConfig configures the Notion comments posted when a page fails to publish
+type FailureCommentConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Levels is a comma separated list of the error levels commented on, ERROR and WARN
+	Levels string `yaml:"levels"`
+	// DashboardURL is the public URL of the Ripple dashboard linked from the comments
+	DashboardURL string `yaml:"dashboard_url"`
+}
+
+// SourcesConfig configures content sources besides the Notion database
+type SourcesConfig struct {
+	Markdown   MarkdownSourceConfig   `yaml:"markdown"`
+	Obsidian   ObsidianSourceConfig   `yaml:"obsidian"`
+	GoogleDocs GoogleDocsSourceConfig `yaml:"google_docs"`
+}
+
+type MarkdownSourceConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Dir     string `yaml:"dir"`
+	// DefaultStatus is used for files without a status in their front matter
+	DefaultStatus string `yaml:"default_status"`
+	// DefaultPlatforms is a comma-separated list used for files without platforms in their front matter
+	DefaultPlatforms string `yaml:"default_platforms"`
+	// ImageBaseURL serves the directory's images; when empty they are read from disk
+	ImageBaseURL string `yaml:"image_base_url"`
+}
+
+type ObsidianSourceConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	VaultDir string `yaml:"vault_dir"`
+	// Folder limits syncing to notes in this vault folder; attachments are found anywhere in the vault
+	Folder           string `yaml:"folder"`
+	DefaultStatus    string `yaml:"default_status"`
+	DefaultPlatforms string `yaml:"default_platforms"`
+	// ImageBaseURL serves the vault's attachments; when empty they are read from disk
+	ImageBaseURL string `yaml:"image_base_url"`
+}
+
+type GoogleDocsSourceConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// CredentialsFile is the JSON key of a service account the Drive folder is shared with
+	CredentialsFile string `yaml:"credentials_file"`
+	FolderID        string `yaml:"folder_id"`
+	// PropertiesSheetID is an optional spreadsheet with one row of properties per document
+	PropertiesSheetID    string `yaml:"properties_sheet_id"`
+	PropertiesSheetRange string `yaml:"properties_sheet_range"`
+	DefaultStatus        string `yaml:"default_status"`
+	DefaultPlatforms     string `yaml:"default_platforms"`
+}
+
+// AIConfig configures generating summaries, SEO descriptions and tag suggestions with an
+// OpenAI compatible API
+type AIConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	APIKey  string `yaml:"api_key"`
+	Model   string `yaml:"model"`
+	BaseURL string `yaml:"base_url"`
+	// EnrichOn is sync to enrich pages after every sync, or publish to enrich them right
+	// before they are published
+	EnrichOn string `yaml:"enrich_on"`
+	// MaxPagesPerSync limits how many pages are enriched after a sync
+	MaxPagesPerSync int `yaml:"max_pages_per_sync"`
+	// MaxInputChars is how much of the post body is sent to the model
+	MaxInputChars int           `yaml:"max_input_chars"`
+	Timeout       time.Duration `yaml:"timeout"`
+}
+
+// BackupConfig configures backup archives of the Ripple state
+type BackupConfig struct {
+	// Passphrase encrypts the platform secrets in backups; without it secrets are left out
+	Passphrase string `yaml:"passphrase"`
+}
+
+// RetentionConfig sets how long monitoring data and logs are kept; 0 days keeps them forever
+type RetentionConfig struct {
+	// Interval controls how often old data is cleaned up; 0 disables the scheduled cleanup
+	Interval time.Duration `yaml:"interval"`
+	// MetricsDays covers metrics samples, system and platform stats and dashboard summaries
+	MetricsDays int `yaml:"metrics_days"`
+	// PostMetricsDays covers the daily stats snapshots of published posts
+	PostMetricsDays int `yaml:"post_metrics_days"`
+	// ErrorLogDays covers resolved error logs; unresolved errors are kept
+	ErrorLogDays int `yaml:"error_log_days"`
+	JobLogDays   int `yaml:"job_log_days"`
+}
+
+// QueueConfig sets where the scheduler queues the publishes for the publish workers
+type QueueConfig struct {
+	// Backend is "database" or "redis"
+	Backend string `yaml:"backend"`
+	// Workers is the number of publish workers run by the server; 0 leaves the queue to
+	// worker processes, so the server only serves the API and queues publishes
+	Workers  int    `yaml:"workers"`
+	RedisURL string `yaml:"redis_url"`
+	// VisibilityTimeout is how long a publish taken by a worker is held before another
+	// worker may take it, in case the first one died
+	VisibilityTimeout time.Duration `yaml:"visibility_timeout"`
 }
 
 type SchedulerConfig struct {
 	SyncInterval time.Duration `yaml:"sync_interval"`
 	Enabled      bool          `yaml:"enabled"`
+	Freeze       FreezeConfig  `yaml:"freeze"`
+}
+
+// FreezeConfig stops the scheduler from publishing at set times; API publishes need
+// override=true while publishing is frozen
+type FreezeConfig struct {
+	// Maintenance stops scheduled syncing and publishing altogether
+	Maintenance bool `yaml:"maintenance"`
+	// Weekdays is a comma separated list of days without scheduled publishing, e.g. sat,sun
+	Weekdays string `yaml:"weekdays"`
+	// Windows is a comma separated list of start/end dates or times, e.g. 2024-12-24/2025-01-02
+	Windows string `yaml:"windows"`
+	// Timezone of the weekdays and windows; empty means the server's local time
+	Timezone string `yaml:"timezone"`
 }
 
 type PublisherConfig struct {
 	AlFolio        AlFolioConfig        `yaml:"al_folio"`
 	WeChatOfficial WeChatOfficialConfig `yaml:"wechat_official"`
 	Substack       SubstackConfig       `yaml:"substack"`
+	Xiaohongshu    XiaohongshuConfig    `yaml:"xiaohongshu"`
+	Mastodon       MastodonConfig       `yaml:"mastodon"`
+	Bluesky        BlueskyConfig        `yaml:"bluesky"`
+	Discord        DiscordConfig        `yaml:"discord"`
+	Slack          SlackConfig          `yaml:"slack"`
+	Confluence     ConfluenceConfig     `yaml:"confluence"`
+	Buffer         BufferConfig         `yaml:"buffer"`
+	Typefully      TypefullyConfig      `yaml:"typefully"`
+	Mock           MockPublisherConfig  `yaml:"mock"`
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit_breaker"`
+	Hooks          HooksConfig          `yaml:"hooks"`
+	Cards          CardConfig           `yaml:"cards"`
+	LinkCheck      LinkCheckConfig      `yaml:"link_check"`
+	UTM            UTMConfig            `yaml:"utm"`
+	ImageHost      ImageHostConfig      `yaml:"image_host"`
+	Lint           LintConfig           `yaml:"lint"`
+	Variants       VariantsConfig       `yaml:"variants"`
+	Routing        RoutingConfig        `yaml:"routing"`
+	Series         SeriesConfig         `yaml:"series"`
+	// Sandbox replaces every real publisher with a mock so nothing reaches the platforms
+	Sandbox bool `yaml:"sandbox"`
+	// CanonicalPlatform is where the posts other platforms link to are published, e.g. al-folio
+	CanonicalPlatform string `yaml:"canonical_platform"`
+	// CredentialCheckInterval controls how often platform credentials are verified; 0 disables it
+	CredentialCheckInterval time.Duration `yaml:"credential_check_interval"`
+	// DeploymentCheckInterval controls how often pending static site deployments and WeChat
+	// publishes are verified; 0 disables it
+	DeploymentCheckInterval time.Duration `yaml:"deployment_check_interval"`
+	// DeploymentTimeout is how long a deployment may stay pending before it is reported as failed
+	DeploymentTimeout time.Duration `yaml:"deployment_timeout"`
+	// MetricsInterval controls how often post stats are pulled from the platforms; 0 disables it
+	MetricsInterval time.Duration `yaml:"metrics_interval"`
+	// MetricsWindow is how long after publishing the stats of a post keep being pulled
+	MetricsWindow time.Duration `yaml:"metrics_window"`
+	// QuarantineAfter is how many consecutive failed publishes quarantine a page on a platform; 0 disables it
+	QuarantineAfter int `yaml:"quarantine_after"`
+	// PublishesPerCycle is how many page and platform pairs the scheduler publishes per sync cycle
+	PublishesPerCycle int `yaml:"publishes_per_cycle"`
+}
+
+type CircuitBreakerConfig struct {
+	Enabled          bool          `yaml:"enabled"`
+	FailureThreshold int           `yaml:"failure_threshold"`
+	CoolDown         time.Duration `yaml:"cool_down"`
+}
+
+// HooksConfig configures the webhook called during publishing
+type HooksConfig struct {
+	WebhookURL string `yaml:"webhook_url"`
+	// WebhookStages is a comma separated list of pre_transform, pre_publish and post_publish
+	WebhookStages string `yaml:"webhook_stages"`
+	WebhookSecret string `yaml:"webhook_secret"`
+	// FailOnError stops the publish when the webhook cannot be reached before publishing
+	FailOnError bool          `yaml:"fail_on_error"`
+	Timeout     time.Duration `yaml:"timeout"`
+}
+
+// LintConfig configures the editorial rules posts are checked against before publishing
+type LintConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Rules sets the severity of rules as rule:error|warning|off, e.g. "cover_required:error"
+	Rules string `yaml:"rules"`
+	// MaxTitleLength maps platforms to their longest title, with * for all others
+	MaxTitleLength string `yaml:"max_title_length"`
+	MinWords       int    `yaml:"min_words"`
+}
+
+// VariantsConfig configures publishing different sections of a page to different platforms
+type VariantsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Marker separates the sections of a page: "divider" for divider blocks, or the text of a
+	// block such as a heading or toggle, e.g. "English"
+	Marker string `yaml:"marker"`
+	// Sections maps platforms to the section they publish as platform:number pairs, numbered
+	// from 1; other platforms publish the whole page
+	Sections string `yaml:"sections"`
+}
+
+// RoutingConfig configures the rules that pick the platforms of pages without a Platform property
+type RoutingConfig struct {
+	// Rules is a comma separated list of field:value=platform+platform rules, where field is tag
+	// or content_type; a page is published to the platforms of every rule it matches
+	Rules string `yaml:"rules"`
+}
+
+// SeriesConfig configures linking the parts of a series, the pages sharing a Series property
+type SeriesConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// RefreshPlatforms is a comma separated list of platforms whose earlier parts are republished
+	// when a new part is published there, so their series index lists it
+	RefreshPlatforms string `yaml:"refresh_platforms"`
+}
+
+// LinkCheckConfig configures checking the links of a post before it is published
+type LinkCheckConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// FailOnDead fails the publish on dead links instead of only reporting them on the job
+	FailOnDead  bool          `yaml:"fail_on_dead"`
+	Timeout     time.Duration `yaml:"timeout"`
+	Concurrency int           `yaml:"concurrency"`
+	// IgnoreHosts is a comma separated list of hosts that are never checked
+	IgnoreHosts string `yaml:"ignore_hosts"`
+}
+
+// UTMConfig configures the UTM parameters appended to the outbound links of a post
+type UTMConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Sources maps platforms to utm_source as platform:source pairs, comma separated; other
+	// platforms use their name
+	Sources string `yaml:"sources"`
+	Medium  string `yaml:"medium"`
+	// Campaign is a fixed utm_campaign; when empty the first tag of the page is used
+	Campaign string `yaml:"campaign"`
+	// IgnoreHosts is a comma separated list of hosts whose links are never tagged, e.g. your own site
+	IgnoreHosts string `yaml:"ignore_hosts"`
+}
+
+// ImageHostConfig configures rehosting the images of a post on our own object storage
+type ImageHostConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Provider is s3, r2 or oss
+	Provider        string `yaml:"provider"`
+	Bucket          string `yaml:"bucket"`
+	Region          string `yaml:"region"`
+	Endpoint        string `yaml:"endpoint"`
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+	// PublicURL is the CDN base URL the images are served from
+	PublicURL string `yaml:"public_url"`
+	Prefix    string `yaml:"prefix"`
+	// Platforms is a comma separated list of platforms to rehost images for; empty means all
+	Platforms string `yaml:"platforms"`
+}
+
+// CardConfig configures the cover cards generated for pages without a cover
+type CardConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Renderer is the headless Chrome or Chromium binary screenshotting the card template
+	Renderer string `yaml:"renderer"`
+	// Template is an HTML template file replacing the built-in card design
+	Template   string `yaml:"template"`
+	OutputDir  string `yaml:"output_dir"`
+	SiteName   string `yaml:"site_name"`
+	BrandColor string `yaml:"brand_color"`
+	// BaseURL is the public URL of this server, so platforms can fetch the cards it serves
+	BaseURL string `yaml:"base_url"`
 }
 
 type AlFolioConfig struct {
@@ -61,6 +364,20 @@ type AlFolioConfig struct {
 	AutoPublish   bool   `yaml:"auto_publish"`
 	GitUsername   string `yaml:"git_username"`
 	GitEmail      string `yaml:"git_email"`
+	SlugStrategy  string `yaml:"slug_strategy"`
+	PinyinDict    string `yaml:"pinyin_dict"`
+	GitToken      string `yaml:"git_token"`
+	SSHKeyPath    string `yaml:"ssh_key_path"`
+	PRMode        bool   `yaml:"pr_mode"`
+	PRProvider    string `yaml:"pr_provider"`
+	PRAPIURL      string `yaml:"pr_api_url"`
+	// Footnotes turns links into kramdown footnotes with a references section; pages can
+	// override it with their Footnotes checkbox
+	Footnotes bool `yaml:"footnotes"`
+	// FrontMatter is merged into the front matter of every post, replacing the default
+	// giscus_comments, tabs and pretty_table settings and generated fields of the same name;
+	// pages can override it with their Front matter property
+	FrontMatter map[string]interface{} `yaml:"front_matter"`
 }
 
 type WeChatOfficialConfig struct {
@@ -71,13 +388,198 @@ type WeChatOfficialConfig struct {
 	NeedOpenComment    int    `yaml:"need_open_comment"`
 	OnlyFansCanComment int    `yaml:"only_fans_can_comment"`
 	DefaultThumbMediaID string `yaml:"default_thumb_media_id"`
+	AutoThumbnail      bool   `yaml:"auto_thumbnail"`
+	UseStableToken     bool   `yaml:"use_stable_token"`
+	APIBaseURL         string `yaml:"api_base_url"`
+	ProxyURL           string `yaml:"proxy_url"`
+	// CodeTheme highlights code blocks with github, monokai, dracula or solarized-light; none
+	// leaves them plain
+	CodeTheme string `yaml:"code_theme"`
+	// CalloutColors overrides the colors callouts are boxed in, by Notion color (gray, brown,
+	// orange, yellow, green, blue, purple, pink, red, or default for uncolored callouts)
+	CalloutColors map[string]WeChatCalloutColor `yaml:"callout_colors"`
+	// PreviewWxName is the WeChat ID draft previews are sent to when the request names none
+	PreviewWxName string `yaml:"preview_wxname"`
+	// Accounts are further Official Accounts, published to as wechat-official:<name>. Each one
+	// is configured in full and does not inherit from the default account.
+	Accounts map[string]WeChatOfficialConfig `yaml:"accounts"`
+}
+
+// WeChatCalloutColor is the background of a callout box and the bar on its left edge; an empty
+// field keeps the default color
+type WeChatCalloutColor struct {
+	Background string `yaml:"background"`
+	Border     string `yaml:"border"`
 }
 
 type SubstackConfig struct {
+	Enabled        bool   `yaml:"enabled"`
+	Domain         string `yaml:"domain"`
+	Cookie         string `yaml:"cookie"`
+	AutoPublish    bool   `yaml:"auto_publish"`
+	SessionID      string `yaml:"session_id"`
+	LoginLink      string `yaml:"login_link"`
+	Audience       string `yaml:"audience"`
+	SendEmail      bool   `yaml:"send_email"`
+	SectionMapping string `yaml:"section_mapping"`
+	InjectBlocks   string `yaml:"inject_blocks"`
+	// TTSProvider (openai or elevenlabs) narrates every post and attaches the audio as its
+	// podcast episode; empty disables narration
+	TTSProvider string `yaml:"tts_provider"`
+	TTSAPIKey   string `yaml:"tts_api_key"`
+	TTSModel    string `yaml:"tts_model"`
+	TTSVoice    string `yaml:"tts_voice"`
+	TTSBaseURL  string `yaml:"tts_base_url"`
+	// Accounts are further publications, published to as substack:<name>. Each one is
+	// configured in full and does not inherit from the default publication.
+	Accounts map[string]SubstackConfig `yaml:"accounts"`
+}
+
+// XiaohongshuConfig configures posting pages as Xiaohongshu notes: a cover card and cards of the
+// summary, rendered with the card renderer, with the page tags as hashtags
+type XiaohongshuConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Cookie of a creator.xiaohongshu.com session; it has to include a1 and web_session
+	Cookie string `yaml:"cookie"`
+	// SignURL is a signing service computing the X-s and X-t headers the web API checks
+	SignURL     string `yaml:"sign_url"`
+	AutoPublish bool   `yaml:"auto_publish"`
+	// Private posts notes only the account can see
+	Private bool `yaml:"private"`
+	// MaxCards is the most images of a note, the cover card included; Xiaohongshu allows 18
+	MaxCards int `yaml:"max_cards"`
+	// WorkspaceDir keeps the rendered cards of drafts until they are published
+	WorkspaceDir string `yaml:"workspace_dir"`
+	ProxyURL     string `yaml:"proxy_url"`
+}
+
+// MastodonConfig configures posting pages to a Mastodon account, as a link to the canonical post
+// or as a thread
+type MastodonConfig struct {
 	Enabled     bool   `yaml:"enabled"`
-	Domain      string `yaml:"domain"`
-	Cookie      string `yaml:"cookie"`
+	InstanceURL string `yaml:"instance_url"`
+	// AccessToken of an application with the write:statuses and write:media scopes
+	AccessToken string `yaml:"access_token"`
+	// Mode is link, the title and summary linking to the canonical post, or thread, the whole page
+	Mode string `yaml:"mode"`
+	// Visibility of the posts: public, unlisted or private
+	Visibility string `yaml:"visibility"`
+	// MaxCharacters is the post length of the instance, 500 on most instances
+	MaxCharacters int    `yaml:"max_characters"`
+	Language      string `yaml:"language"`
+	AutoPublish   bool   `yaml:"auto_publish"`
+	// WorkspaceDir keeps drafts until they are published
+	WorkspaceDir string `yaml:"workspace_dir"`
+	ProxyURL     string `yaml:"proxy_url"`
+}
+
+// BlueskyConfig configures posting pages to a Bluesky account, as a link to the canonical post or
+// as a thread
+type BlueskyConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// ServiceURL is the PDS of the account, https://bsky.social unless it is self-hosted
+	ServiceURL string `yaml:"service_url"`
+	Handle     string `yaml:"handle"`
+	// AppPassword is an app password created in the account settings, not the account password
+	AppPassword string `yaml:"app_password"`
+	// Mode is link, the title and summary linking to the canonical post, or thread, the whole page
+	Mode        string `yaml:"mode"`
+	Language    string `yaml:"language"`
+	AutoPublish bool   `yaml:"auto_publish"`
+	// WorkspaceDir keeps drafts until they are published
+	WorkspaceDir string `yaml:"workspace_dir"`
+	ProxyURL     string `yaml:"proxy_url"`
+}
+
+// DiscordConfig configures announcing pages with Discord webhooks once they are published to the
+// canonical platform
+type DiscordConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// WebhookURLs is a comma separated list of channel webhook URLs
+	WebhookURLs string `yaml:"webhook_urls"`
+	// Username and AvatarURL override the name and avatar of the webhooks
+	Username  string `yaml:"username"`
+	AvatarURL string `yaml:"avatar_url"`
+	// Color is the hex color along the card, e.g. #5865F2
+	Color string `yaml:"color"`
+	// Mention is sent with the card, e.g. @here or <@&role-id>
+	Mention  string `yaml:"mention"`
+	ProxyURL string `yaml:"proxy_url"`
+}
+
+// SlackConfig configures announcing pages in Slack channels once they are published to the
+// canonical platform
+type SlackConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// BotToken is the xoxb- token of a Slack app with the chat:write scope
+	BotToken string `yaml:"bot_token"`
+	// Channels is a comma separated list of channel IDs the bot is a member of
+	Channels string `yaml:"channels"`
+	// ButtonText is the label of the button opening the post
+	ButtonText string `yaml:"button_text"`
+	ProxyURL   string `yaml:"proxy_url"`
+}
+
+// ConfluenceConfig configures mirroring pages to a space of a Confluence Cloud site
+type ConfluenceConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// BaseURL is the wiki of the site, e.g. https://your-domain.atlassian.net/wiki
+	BaseURL string `yaml:"base_url"`
+	// Email and APIToken are the Atlassian account pages are published as, with an API token
+	// created at id.atlassian.com
+	Email    string `yaml:"email"`
+	APIToken string `yaml:"api_token"`
+	SpaceKey string `yaml:"space_key"`
+	// ParentPageID is the page new pages are created under; empty creates them at the top of
+	// the space
+	ParentPageID string `yaml:"parent_page_id"`
+	// AutoPublish publishes pages right away; otherwise new pages are saved as drafts
 	AutoPublish bool   `yaml:"auto_publish"`
+	ProxyURL    string `yaml:"proxy_url"`
+}
+
+// BufferConfig configures scheduling posts promoting the published pages with Buffer
+type BufferConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// AccessToken is the access token of the Buffer account
+	AccessToken string `yaml:"access_token"`
+	// ProfileIDs is a comma separated list of the profiles to post to; empty posts to every
+	// profile of the account
+	ProfileIDs string `yaml:"profile_ids"`
+	// Schedule is when posts go out: queue (the next free slot), now or publish_date
+	Schedule string `yaml:"schedule"`
+	// Template is the text/template of the posts, with the fields .Title, .Summary, .URL,
+	// .Author, .Tags, .Hashtags and .Network
+	Template string `yaml:"template"`
+	// Templates override Template for the profiles of a network, e.g. twitter or linkedin
+	Templates map[string]string `yaml:"templates"`
+	ProxyURL  string            `yaml:"proxy_url"`
+}
+
+// TypefullyConfig configures scheduling posts promoting the published pages as Typefully drafts
+type TypefullyConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// APIKey is created in the integrations settings of Typefully
+	APIKey string `yaml:"api_key"`
+	// Schedule is when posts go out: queue (the next free slot), now or publish_date
+	Schedule string `yaml:"schedule"`
+	// Template is the text/template of the posts, as for Buffer
+	Template string `yaml:"template"`
+	// Templates override Template for a network; Typefully drafts use the twitter template
+	Templates map[string]string `yaml:"templates"`
+	// Threadify lets Typefully split long posts into a thread
+	Threadify bool   `yaml:"threadify"`
+	ProxyURL  string `yaml:"proxy_url"`
+}
+
+type MockPublisherConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	OutputDir string `yaml:"output_dir"`
+	// FailureRate is the probability between 0 and 1 that a publish fails
+	FailureRate float64 `yaml:"failure_rate"`
+	// FailTag makes publishing pages with this tag always fail
+	FailTag string        `yaml:"fail_tag"`
+	Delay   time.Duration `yaml:"delay"`
 }
 
 type AuthConfig struct {
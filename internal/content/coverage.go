@@ -0,0 +1,63 @@
+package content
+
+// Coverage describes the size of a page and how completely its Notion blocks convert into a
+// Document; publishers render every block of a Document, so blocks lost in the conversion are
+// what renders incompletely
+type Coverage struct {
+	// Bytes is the size of the blocks JSON, when the coverage was computed from it
+	Bytes      int `json:"bytes"`
+	Blocks     int `json:"blocks"`
+	Characters int `json:"characters"`
+	Images     int `json:"images"`
+	// Types counts the blocks per Notion block type
+	Types map[string]int `json:"types"`
+	// Degraded counts the blocks kept only as a plain paragraph of their text, such as callouts
+	// and toggles
+	Degraded map[string]int `json:"degraded,omitempty"`
+	// Unsupported counts the blocks dropped altogether, such as bookmarks, embeds and equations
+	Unsupported map[string]int `json:"unsupported,omitempty"`
+}
+
+func newCoverage() *Coverage {
+	return &Coverage{
+		Types:       make(map[string]int),
+		Degraded:    make(map[string]int),
+		Unsupported: make(map[string]int),
+	}
+}
+
+func (c *Coverage) count(blockType string) {
+	c.Blocks++
+	c.Types[blockType]++
+}
+
+func (c *Coverage) degrade(blockType string) {
+	c.Degraded[blockType]++
+}
+
+func (c *Coverage) drop(blockType string) {
+	c.Unsupported[blockType]++
+}
+
+// DegradedBlocks is the number of blocks kept only as plain paragraphs
+func (c *Coverage) DegradedBlocks() int {
+	return sum(c.Degraded)
+}
+
+// UnsupportedBlocks is the number of blocks dropped from the document
+func (c *Coverage) UnsupportedBlocks() int {
+	return sum(c.Unsupported)
+}
+
+// Complete reports whether every block converted as it is
+func (c *Coverage) Complete() bool {
+	return len(c.Degraded) == 0 && len(c.Unsupported) == 0
+}
+
+func sum(counts map[string]int) int {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
@@ -0,0 +1,509 @@
+package content
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// BlockType identifies the kind of a top-level block in a Document
+type BlockType string
+
+const (
+	BlockParagraph BlockType = "paragraph"
+	BlockHeading   BlockType = "heading"
+	BlockList      BlockType = "list"
+	BlockQuote     BlockType = "quote"
+	BlockCallout   BlockType = "callout"
+	BlockCode      BlockType = "code"
+	BlockImage     BlockType = "image"
+	BlockVideo     BlockType = "video"
+	BlockFile      BlockType = "file"
+	BlockDivider   BlockType = "divider"
+	BlockTable     BlockType = "table"
+)
+
+// Document is the platform independent representation of a page. It is built once when a
+// page is synced, and every publisher renders it instead of parsing the source format itself.
+type Document struct {
+	Blocks []Block `json:"blocks"`
+}
+
+// Block is a top-level node of a document. Which fields are set depends on the type:
+// Text for paragraphs, headings, quotes and callouts, Code and Language for code, and the
+// matching pointer for callout icons, images, videos, files, lists and tables.
+type Block struct {
+	Type     BlockType `json:"type"`
+	Level    int       `json:"level,omitempty"`
+	Text     []Span    `json:"text,omitempty"`
+	Code     string    `json:"code,omitempty"`
+	Language string    `json:"language,omitempty"`
+	Callout  *Callout  `json:"callout,omitempty"`
+	Image    *Image    `json:"image,omitempty"`
+	Video    *Video    `json:"video,omitempty"`
+	File     *File     `json:"file,omitempty"`
+	List     *List     `json:"list,omitempty"`
+	Table    *Table    `json:"table,omitempty"`
+}
+
+// Span is a run of text sharing the same formatting
+type Span struct {
+	Text          string `json:"text"`
+	Bold          bool   `json:"bold,omitempty"`
+	Italic        bool   `json:"italic,omitempty"`
+	Code          bool   `json:"code,omitempty"`
+	Strikethrough bool   `json:"strikethrough,omitempty"`
+	Underline     bool   `json:"underline,omitempty"`
+	Link          string `json:"link,omitempty"`
+}
+
+// Callout is the icon and color of a callout, whose text is the text of its block
+type Callout struct {
+	// Icon is the emoji shown before the text, empty for callouts with an image icon or none
+	Icon string `json:"icon,omitempty"`
+	// Color is the Notion color of the callout, e.g. "gray_background" or "red"
+	Color string `json:"color,omitempty"`
+}
+
+type Image struct {
+	URL     string `json:"url"`
+	Caption []Span `json:"caption,omitempty"`
+}
+
+// Video is an uploaded video file or a link to a video site such as YouTube
+type Video struct {
+	URL     string `json:"url"`
+	Caption []Span `json:"caption,omitempty"`
+}
+
+// videoExtensions are the file types served as-is by a <video> tag
+var videoExtensions = []string{".mp4", ".webm", ".mov", ".m4v", ".ogv"}
+
+// IsFile reports whether the video is a video file rather than a page on a video site
+func (v *Video) IsFile() bool {
+	parsed, err := url.Parse(v.URL)
+	if err != nil {
+		return false
+	}
+	ext := strings.ToLower(path.Ext(parsed.Path))
+	for _, videoExt := range videoExtensions {
+		if ext == videoExt {
+			return true
+		}
+	}
+	return false
+}
+
+// ThumbnailURL returns a preview image of the video, when the video site provides one
+func (v *Video) ThumbnailURL() string {
+	if id := v.youTubeID(); id != "" {
+		return fmt.Sprintf("https://img.youtube.com/vi/%s/hqdefault.jpg", url.PathEscape(id))
+	}
+	return ""
+}
+
+// EmbedURL returns the URL of the video site's embeddable player, or "" for other videos
+func (v *Video) EmbedURL() string {
+	if id := v.youTubeID(); id != "" {
+		return fmt.Sprintf("https://www.youtube.com/embed/%s", url.PathEscape(id))
+	}
+	return ""
+}
+
+// youTubeID returns the ID of a YouTube video from its watch, short or embed URL
+func (v *Video) youTubeID() string {
+	parsed, err := url.Parse(v.URL)
+	if err != nil {
+		return ""
+	}
+
+	switch strings.TrimPrefix(parsed.Hostname(), "www.") {
+	case "youtube.com", "m.youtube.com":
+		if id := parsed.Query().Get("v"); id != "" {
+			return id
+		}
+		return strings.TrimPrefix(parsed.Path, "/embed/")
+	case "youtu.be":
+		return strings.TrimPrefix(parsed.Path, "/")
+	}
+	return ""
+}
+
+// File is an attachment such as a PDF or a slide deck, shown as a download link
+type File struct {
+	URL     string `json:"url"`
+	Name    string `json:"name,omitempty"`
+	Caption []Span `json:"caption,omitempty"`
+}
+
+// fileKinds names the common attachment types by extension
+var fileKinds = map[string]string{
+	".pdf":     "PDF",
+	".ppt":     "Slides",
+	".pptx":    "Slides",
+	".key":     "Slides",
+	".odp":     "Slides",
+	".doc":     "Document",
+	".docx":    "Document",
+	".odt":     "Document",
+	".pages":   "Document",
+	".xls":     "Spreadsheet",
+	".xlsx":    "Spreadsheet",
+	".csv":     "Spreadsheet",
+	".numbers": "Spreadsheet",
+	".zip":     "Archive",
+	".tar":     "Archive",
+	".gz":      "Archive",
+	".7z":      "Archive",
+}
+
+// FileName returns the name of the file, falling back to the last segment of its URL
+func (f *File) FileName() string {
+	if f.Name != "" {
+		return f.Name
+	}
+	if parsed, err := url.Parse(f.URL); err == nil {
+		if base := path.Base(parsed.Path); base != "." && base != "/" {
+			if name, err := url.PathUnescape(base); err == nil {
+				return name
+			}
+			return base
+		}
+	}
+	return "file"
+}
+
+// Ext returns the lowercase extension of the file, from its name or else its URL
+func (f *File) Ext() string {
+	if ext := path.Ext(f.Name); ext != "" {
+		return strings.ToLower(ext)
+	}
+	if parsed, err := url.Parse(f.URL); err == nil {
+		return strings.ToLower(path.Ext(parsed.Path))
+	}
+	return ""
+}
+
+// Kind describes the type of the file for readers, such as "PDF" or "Slides"
+func (f *File) Kind() string {
+	ext := f.Ext()
+	if kind, ok := fileKinds[ext]; ok {
+		return kind
+	}
+	if ext != "" {
+		return strings.ToUpper(strings.TrimPrefix(ext, "."))
+	}
+	return "File"
+}
+
+// List groups consecutive list items of the same kind
+type List struct {
+	Ordered bool       `json:"ordered"`
+	Items   []ListItem `json:"items"`
+}
+
+// ListItem is a list entry; Checked is set for to-do items
+type ListItem struct {
+	Text    []Span `json:"text"`
+	Checked *bool  `json:"checked,omitempty"`
+}
+
+// Table holds rows of cells, each cell being a run of spans
+type Table struct {
+	HasHeader bool       `json:"has_header"`
+	Rows      [][][]Span `json:"rows"`
+}
+
+// Parse decodes stored page content: either an encoded Document or, for pages synced before
+// documents were stored, the raw Notion blocks JSON
+func Parse(data string) (*Document, error) {
+	trimmed := strings.TrimSpace(data)
+	if trimmed == "" {
+		return &Document{}, nil
+	}
+
+	if strings.HasPrefix(trimmed, "{") {
+		var doc Document
+		if err := json.Unmarshal([]byte(trimmed), &doc); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal document: %w", err)
+		}
+		return &doc, nil
+	}
+
+	return FromNotionJSON(trimmed)
+}
+
+// Encode returns the document as the JSON stored with the page
+func (d *Document) Encode() (string, error) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal document: %w", err)
+	}
+	return string(data), nil
+}
+
+// Images returns the URLs of the document's images in order
+func (d *Document) Images() []string {
+	var urls []string
+	for _, block := range d.Blocks {
+		if block.Type == BlockImage && block.Image != nil && block.Image.URL != "" {
+			urls = append(urls, block.Image.URL)
+		}
+	}
+	return urls
+}
+
+// Links returns the distinct hyperlink targets of the document in order
+func (d *Document) Links() []string {
+	var links []string
+	seen := make(map[string]bool)
+	add := func(spans []Span) {
+		for _, span := range spans {
+			if span.Link != "" && !seen[span.Link] {
+				seen[span.Link] = true
+				links = append(links, span.Link)
+			}
+		}
+	}
+
+	for _, block := range d.Blocks {
+		add(block.Text)
+		if block.Image != nil {
+			add(block.Image.Caption)
+		}
+		if block.File != nil {
+			add(block.File.Caption)
+		}
+		if block.List != nil {
+			for _, item := range block.List.Items {
+				add(item.Text)
+			}
+		}
+		if block.Table != nil {
+			for _, row := range block.Table.Rows {
+				for _, cell := range row {
+					add(cell)
+				}
+			}
+		}
+	}
+	return links
+}
+
+// MapImages replaces every image URL with the result of fn. Changed images are copied rather
+// than modified, so shallow copies of the document are left alone.
+func (d *Document) MapImages(fn func(url string) string) {
+	for i := range d.Blocks {
+		block := &d.Blocks[i]
+		if block.Type != BlockImage || block.Image == nil || block.Image.URL == "" {
+			continue
+		}
+		if mapped := fn(block.Image.URL); mapped != block.Image.URL {
+			image := *block.Image
+			image.URL = mapped
+			block.Image = &image
+		}
+	}
+}
+
+// Videos returns the videos of the document in order
+func (d *Document) Videos() []*Video {
+	var videos []*Video
+	for _, block := range d.Blocks {
+		if block.Type == BlockVideo && block.Video != nil && block.Video.URL != "" {
+			videos = append(videos, block.Video)
+		}
+	}
+	return videos
+}
+
+// MapVideos replaces every video URL with the result of fn. Like MapImages, changed videos are
+// copied rather than modified.
+func (d *Document) MapVideos(fn func(url string) string) {
+	for i := range d.Blocks {
+		block := &d.Blocks[i]
+		if block.Type != BlockVideo || block.Video == nil || block.Video.URL == "" {
+			continue
+		}
+		if mapped := fn(block.Video.URL); mapped != block.Video.URL {
+			video := *block.Video
+			video.URL = mapped
+			block.Video = &video
+		}
+	}
+}
+
+// Files returns the attachments of the document in order
+func (d *Document) Files() []*File {
+	var files []*File
+	for _, block := range d.Blocks {
+		if block.Type == BlockFile && block.File != nil && block.File.URL != "" {
+			files = append(files, block.File)
+		}
+	}
+	return files
+}
+
+// MapFiles replaces every attachment URL with the result of fn. Like MapImages, changed files
+// are copied rather than modified.
+func (d *Document) MapFiles(fn func(url string) string) {
+	for i := range d.Blocks {
+		block := &d.Blocks[i]
+		if block.Type != BlockFile || block.File == nil || block.File.URL == "" {
+			continue
+		}
+		if mapped := fn(block.File.URL); mapped != block.File.URL {
+			file := *block.File
+			file.URL = mapped
+			block.File = &file
+		}
+	}
+}
+
+// MapLinks replaces every hyperlink target with the result of fn. Changed spans, lists, tables
+// images and files are copied rather than modified, so shallow copies of the document are left alone.
+func (d *Document) MapLinks(fn func(link string) string) {
+	mapSpans := func(spans []Span) []Span {
+		var mapped []Span
+		for i, span := range spans {
+			if span.Link == "" {
+				continue
+			}
+			link := fn(span.Link)
+			if link == span.Link {
+				continue
+			}
+			if mapped == nil {
+				mapped = append([]Span(nil), spans...)
+			}
+			mapped[i].Link = link
+		}
+		if mapped == nil {
+			return spans
+		}
+		return mapped
+	}
+
+	for i := range d.Blocks {
+		block := &d.Blocks[i]
+		block.Text = mapSpans(block.Text)
+		if block.Image != nil {
+			image := *block.Image
+			image.Caption = mapSpans(image.Caption)
+			block.Image = &image
+		}
+		if block.File != nil {
+			file := *block.File
+			file.Caption = mapSpans(file.Caption)
+			block.File = &file
+		}
+		if block.List != nil {
+			list := *block.List
+			list.Items = make([]ListItem, len(block.List.Items))
+			for j, item := range block.List.Items {
+				item.Text = mapSpans(item.Text)
+				list.Items[j] = item
+			}
+			block.List = &list
+		}
+		if block.Table != nil {
+			table := *block.Table
+			table.Rows = make([][][]Span, len(block.Table.Rows))
+			for j, row := range block.Table.Rows {
+				cells := make([][]Span, len(row))
+				for k, cell := range row {
+					cells[k] = mapSpans(cell)
+				}
+				table.Rows[j] = cells
+			}
+			block.Table = &table
+		}
+	}
+}
+
+// Split cuts the document into sections at the blocks isMarker matches, dropping the markers
+// and empty sections. A document without markers is a single section.
+func (d *Document) Split(isMarker func(Block) bool) []*Document {
+	var sections []*Document
+	var current []Block
+	flush := func() {
+		if len(current) > 0 {
+			sections = append(sections, &Document{Blocks: current})
+			current = nil
+		}
+	}
+
+	for _, block := range d.Blocks {
+		if isMarker(block) {
+			flush()
+			continue
+		}
+		current = append(current, block)
+	}
+	flush()
+	return sections
+}
+
+// PlainText joins the text of spans without formatting
+func PlainText(spans []Span) string {
+	var text strings.Builder
+	for _, span := range spans {
+		text.WriteString(span.Text)
+	}
+	return text.String()
+}
+
+// Text returns the document as plain text, one block per line, for indexing and analysis
+func (d *Document) Text() string {
+	var lines []string
+	for _, block := range d.Blocks {
+		switch block.Type {
+		case BlockCode:
+			lines = append(lines, block.Code)
+		case BlockImage:
+			if block.Image != nil {
+				lines = append(lines, PlainText(block.Image.Caption))
+			}
+		case BlockVideo:
+			if block.Video != nil {
+				lines = append(lines, PlainText(block.Video.Caption))
+			}
+		case BlockFile:
+			if block.File != nil {
+				if caption := PlainText(block.File.Caption); caption != "" {
+					lines = append(lines, caption)
+				} else {
+					lines = append(lines, block.File.FileName())
+				}
+			}
+		case BlockList:
+			if block.List != nil {
+				for _, item := range block.List.Items {
+					lines = append(lines, PlainText(item.Text))
+				}
+			}
+		case BlockTable:
+			if block.Table != nil {
+				for _, row := range block.Table.Rows {
+					cells := make([]string, len(row))
+					for i, cell := range row {
+						cells[i] = PlainText(cell)
+					}
+					lines = append(lines, strings.Join(cells, " "))
+				}
+			}
+		default:
+			lines = append(lines, PlainText(block.Text))
+		}
+	}
+
+	var text []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			text = append(text, line)
+		}
+	}
+	return strings.Join(text, "\n")
+}
@@ -0,0 +1,208 @@
+package content
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+)
+
+// FromNotionJSON converts the raw Notion blocks JSON stored for a page, a flat array with
+// children following their parent, into a Document
+func FromNotionJSON(blocksJSON string) (*Document, error) {
+	var blocks []map[string]any
+	if err := json.Unmarshal([]byte(blocksJSON), &blocks); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal blocks: %w", err)
+	}
+	return FromNotionBlocks(blocks), nil
+}
+
+// FromNotionBlocks converts Notion API blocks into a Document. Consecutive list items become
+// one list, table rows are collected into their table, and container blocks like columns are
+// dropped since their children follow them.
+func FromNotionBlocks(blocks []map[string]any) *Document {
+	doc, _ := convertNotionBlocks(blocks)
+	return doc
+}
+
+// NotionCoverage reports how completely Notion API blocks convert into a Document
+func NotionCoverage(blocks []map[string]any) *Coverage {
+	_, coverage := convertNotionBlocks(blocks)
+	return coverage
+}
+
+// NotionCoverageJSON reports how completely the raw Notion blocks JSON stored for a page
+// converts into a Document
+func NotionCoverageJSON(blocksJSON string) (*Coverage, error) {
+	var blocks []map[string]any
+	if err := json.Unmarshal([]byte(blocksJSON), &blocks); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal blocks: %w", err)
+	}
+	coverage := NotionCoverage(blocks)
+	coverage.Bytes = len(blocksJSON)
+	return coverage, nil
+}
+
+func convertNotionBlocks(blocks []map[string]any) (*Document, *Coverage) {
+	doc := &Document{}
+	coverage := newCoverage()
+
+	for _, block := range blocks {
+		blockType, _ := block["type"].(string)
+		coverage.count(blockType)
+		blockContent, ok := block[blockType].(map[string]any)
+		if !ok {
+			coverage.drop(blockType)
+			continue
+		}
+
+		switch blockType {
+		case "paragraph":
+			doc.Blocks = append(doc.Blocks, Block{Type: BlockParagraph, Text: notionRichText(blockContent["rich_text"])})
+		case "heading_1", "heading_2", "heading_3":
+			doc.Blocks = append(doc.Blocks, Block{
+				Type:  BlockHeading,
+				Level: int(blockType[len(blockType)-1] - '0'),
+				Text:  notionRichText(blockContent["rich_text"]),
+			})
+		case "bulleted_list_item", "numbered_list_item", "to_do":
+			item := ListItem{Text: notionRichText(blockContent["rich_text"])}
+			if blockType == "to_do" {
+				checked, _ := blockContent["checked"].(bool)
+				item.Checked = &checked
+			}
+			doc.appendListItem(blockType == "numbered_list_item", item)
+		case "quote":
+			doc.Blocks = append(doc.Blocks, Block{Type: BlockQuote, Text: notionRichText(blockContent["rich_text"])})
+		case "callout":
+			callout := &Callout{}
+			if icon, ok := blockContent["icon"].(map[string]any); ok {
+				callout.Icon, _ = icon["emoji"].(string)
+			}
+			callout.Color, _ = blockContent["color"].(string)
+			doc.Blocks = append(doc.Blocks, Block{Type: BlockCallout, Text: notionRichText(blockContent["rich_text"]), Callout: callout})
+		case "code":
+			language, _ := blockContent["language"].(string)
+			doc.Blocks = append(doc.Blocks, Block{
+				Type:     BlockCode,
+				Code:     PlainText(notionRichText(blockContent["rich_text"])),
+				Language: language,
+			})
+		case "divider":
+			doc.Blocks = append(doc.Blocks, Block{Type: BlockDivider})
+		case "image":
+			if url := notionFileURL(blockContent); url != "" {
+				doc.Blocks = append(doc.Blocks, Block{
+					Type:  BlockImage,
+					Image: &Image{URL: url, Caption: notionRichText(blockContent["caption"])},
+				})
+			} else {
+				coverage.drop(blockType)
+			}
+		case "video":
+			if url := notionFileURL(blockContent); url != "" {
+				doc.Blocks = append(doc.Blocks, Block{
+					Type:  BlockVideo,
+					Video: &Video{URL: url, Caption: notionRichText(blockContent["caption"])},
+				})
+			} else {
+				coverage.drop(blockType)
+			}
+		case "file", "pdf":
+			if url := notionFileURL(blockContent); url != "" {
+				name, _ := blockContent["name"].(string)
+				doc.Blocks = append(doc.Blocks, Block{
+					Type: BlockFile,
+					File: &File{URL: url, Name: name, Caption: notionRichText(blockContent["caption"])},
+				})
+			} else {
+				coverage.drop(blockType)
+			}
+		case "table":
+			hasHeader, _ := blockContent["has_column_header"].(bool)
+			doc.Blocks = append(doc.Blocks, Block{Type: BlockTable, Table: &Table{HasHeader: hasHeader}})
+		case "table_row":
+			doc.appendTableRow(blockContent)
+		case "column_list", "column":
+			// Containers only, their content comes from the child blocks that follow
+		default:
+			// Toggles and other text blocks keep their text as a paragraph
+			if text := notionRichText(blockContent["rich_text"]); len(text) > 0 {
+				doc.Blocks = append(doc.Blocks, Block{Type: BlockParagraph, Text: text})
+				coverage.degrade(blockType)
+			} else {
+				coverage.drop(blockType)
+			}
+		}
+	}
+
+	coverage.Characters = utf8.RuneCountInString(doc.Text())
+	coverage.Images = len(doc.Images())
+	return doc, coverage
+}
+
+func (d *Document) appendListItem(ordered bool, item ListItem) {
+	if n := len(d.Blocks); n > 0 && d.Blocks[n-1].Type == BlockList && d.Blocks[n-1].List.Ordered == ordered {
+		list := d.Blocks[n-1].List
+		// To-do items only continue a list of to-do items and the other way around
+		if (list.Items[len(list.Items)-1].Checked != nil) == (item.Checked != nil) {
+			list.Items = append(list.Items, item)
+			return
+		}
+	}
+	d.Blocks = append(d.Blocks, Block{Type: BlockList, List: &List{Ordered: ordered, Items: []ListItem{item}}})
+}
+
+func (d *Document) appendTableRow(blockContent map[string]any) {
+	n := len(d.Blocks)
+	if n == 0 || d.Blocks[n-1].Type != BlockTable {
+		return
+	}
+
+	cells, _ := blockContent["cells"].([]any)
+	row := make([][]Span, 0, len(cells))
+	for _, cell := range cells {
+		row = append(row, notionRichText(cell))
+	}
+	d.Blocks[n-1].Table.Rows = append(d.Blocks[n-1].Table.Rows, row)
+}
+
+// notionRichText converts a Notion rich_text array into spans
+func notionRichText(value any) []Span {
+	richText, _ := value.([]any)
+
+	spans := make([]Span, 0, len(richText))
+	for _, rt := range richText {
+		rtMap, ok := rt.(map[string]any)
+		if !ok {
+			continue
+		}
+		text, ok := rtMap["plain_text"].(string)
+		if !ok {
+			continue
+		}
+
+		span := Span{Text: text}
+		if annotations, ok := rtMap["annotations"].(map[string]any); ok {
+			span.Bold, _ = annotations["bold"].(bool)
+			span.Italic, _ = annotations["italic"].(bool)
+			span.Code, _ = annotations["code"].(bool)
+			span.Strikethrough, _ = annotations["strikethrough"].(bool)
+			span.Underline, _ = annotations["underline"].(bool)
+		}
+		span.Link, _ = rtMap["href"].(string)
+		spans = append(spans, span)
+	}
+	return spans
+}
+
+// notionFileURL returns the URL of an uploaded or external Notion file object
+func notionFileURL(blockContent map[string]any) string {
+	for _, source := range []string{"file", "external"} {
+		if obj, ok := blockContent[source].(map[string]any); ok {
+			if url, ok := obj["url"].(string); ok && url != "" {
+				return url
+			}
+		}
+	}
+	return ""
+}
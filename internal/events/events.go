@@ -0,0 +1,101 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types streamed to the dashboard and to followers of a job log
+const (
+	JobStatus    = "job_status"
+	JobLogged    = "job_log"
+	SyncProgress = "sync_progress"
+	ErrorLogged  = "error"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind before it misses events
+const subscriberBuffer = 64
+
+// Event is a state change worth showing live
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+	Time time.Time   `json:"time"`
+}
+
+// JobStatusEvent is sent when a distribution job moves to another state
+type JobStatusEvent struct {
+	JobID      uint   `json:"job_id"`
+	PageID     uint   `json:"page_id"`
+	PlatformID uint   `json:"platform_id"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Broker fans events out to its subscribers. Publishing never blocks: a subscriber that does
+// not keep up misses events instead of holding up publishes and syncs.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+	closed      bool
+}
+
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[chan Event]struct{})}
+}
+
+// Default is the broker the services publish to
+var Default = NewBroker()
+
+// Publish sends an event to the subscribers of the default broker
+func Publish(eventType string, data interface{}) {
+	Default.Publish(eventType, data)
+}
+
+// Subscribe returns a channel receiving all events published from now on, and a function to
+// stop the subscription. The channel is closed when the subscription stops or the broker closes.
+func (b *Broker) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	b.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+func (b *Broker) Publish(eventType string, data interface{}) {
+	event := Event{Type: eventType, Data: data, Time: time.Now()}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Close ends all subscriptions, so streaming requests finish when the server shuts down
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for ch := range b.subscribers {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
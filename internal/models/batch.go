@@ -0,0 +1,98 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Publish batch states
+const (
+	BatchPending   = "pending"
+	BatchRunning   = "running"
+	BatchCompleted = "completed"
+)
+
+// Outcomes of publishing a page in a batch
+const (
+	BatchItemPending   = "pending"
+	BatchItemCompleted = "completed"
+	BatchItemFailed    = "failed"
+	BatchItemSkipped   = "skipped"
+)
+
+// PublishBatchItem tracks one page of a batch; Platforms maps each platform to "completed"
+// or the error it failed with
+type PublishBatchItem struct {
+	PageID    string            `json:"page_id"`
+	Title     string            `json:"title"`
+	Status    string            `json:"status"`
+	Platforms map[string]string `json:"platforms,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// PublishBatchItems represents a PostgreSQL jsonb array of batch items
+type PublishBatchItems []PublishBatchItem
+
+// Scan implements the sql.Scanner interface
+func (i *PublishBatchItems) Scan(value interface{}) error {
+	if value == nil {
+		*i = PublishBatchItems{}
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into PublishBatchItems", value)
+	}
+
+	result := PublishBatchItems{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("failed to unmarshal PublishBatchItems: %w", err)
+	}
+	*i = result
+	return nil
+}
+
+// Value implements the driver.Valuer interface
+func (i PublishBatchItems) Value() (driver.Value, error) {
+	if i == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(i)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// PublishBatch publishes a selection of pages in the background and records the progress
+type PublishBatch struct {
+	ID         uint              `gorm:"primaryKey" json:"id"`
+	Status     string            `gorm:"size:20;not null;index" json:"status"`
+	Platform   string            `gorm:"size:100" json:"platform"`
+	Filter     JSONMap           `gorm:"type:jsonb;default:'{}'" json:"filter"`
+	Total      int               `gorm:"default:0" json:"total"`
+	Completed  int               `gorm:"default:0" json:"completed"`
+	Failed     int               `gorm:"default:0" json:"failed"`
+	Skipped    int               `gorm:"default:0" json:"skipped"`
+	Items      PublishBatchItems `gorm:"type:jsonb;default:'[]'" json:"items"`
+	StartedAt  *time.Time        `json:"started_at"`
+	FinishedAt *time.Time        `json:"finished_at"`
+	CreatedAt  time.Time         `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time         `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// Progress returns the share of pages that have been processed, from 0 to 1
+func (b *PublishBatch) Progress() float64 {
+	if b.Total == 0 {
+		return 1
+	}
+	return float64(b.Completed+b.Failed+b.Skipped) / float64(b.Total)
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrContentVersionImmutable is returned when a stored content version is updated
+var ErrContentVersionImmutable = errors.New("content versions cannot be changed")
+
+// ContentVersion is a snapshot of what a publish sent to a platform: the page content and
+// metadata it was rendered from, and the output of the platform's transformer. Versions are
+// numbered per page and platform and never change once recorded.
+type ContentVersion struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	PageID     uint   `gorm:"not null;uniqueIndex:idx_content_versions_page_platform_version" json:"page_id"`
+	PlatformID uint   `gorm:"not null;uniqueIndex:idx_content_versions_page_platform_version" json:"platform_id"`
+	Version    int    `gorm:"not null;uniqueIndex:idx_content_versions_page_platform_version" json:"version"`
+	JobID      uint   `gorm:"not null;index" json:"job_id"`
+	JobStatus  string `gorm:"size:50" json:"job_status"`
+	// ContentHash fingerprints the page content of the version, see publisher.ContentHash
+	ContentHash string `gorm:"size:64;index" json:"content_hash"`
+
+	Title          string      `gorm:"not null;size:500" json:"title"`
+	ENTitle        string      `gorm:"size:500" json:"en_title,omitempty"`
+	Summary        string      `gorm:"type:text" json:"summary,omitempty"`
+	SEODescription string      `gorm:"size:500" json:"seo_description,omitempty"`
+	Tags           StringArray `gorm:"type:text[]" json:"tags"`
+	Owner          string      `gorm:"size:500" json:"owner,omitempty"`
+	PostDate       *time.Time  `json:"post_date,omitempty"`
+	CoverURL       string      `gorm:"type:text" json:"cover_url,omitempty"`
+	Series         string      `gorm:"size:255" json:"series,omitempty"`
+	ContentType    StringArray `gorm:"type:text[]" json:"content_type"`
+	Properties     string      `gorm:"type:jsonb;default:'{}'" json:"properties,omitempty"`
+	// Content is the Notion blocks of the page, Output what the transformer rendered from them
+	Content  string `gorm:"type:text" json:"content,omitempty"`
+	Document string `gorm:"type:text" json:"-"`
+	Output   string `gorm:"type:text" json:"output,omitempty"`
+
+	// RestoredFrom is the version republished to record this one
+	RestoredFrom *uint     `json:"restored_from,omitempty"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	Platform Platform `gorm:"foreignKey:PlatformID" json:"platform"`
+}
+
+// BeforeUpdate keeps recorded versions as they were published
+func (v *ContentVersion) BeforeUpdate(tx *gorm.DB) error {
+	return ErrContentVersionImmutable
+}
+
+// Page rebuilds the page as it was when the version was published, on top of the current page
+// so its IDs, status and platforms are kept. The generated summary is dropped, the snapshot
+// already holds the summary that was published.
+func (v *ContentVersion) Page(current NotionPage) NotionPage {
+	page := current
+	page.Title = v.Title
+	page.ENTitle = v.ENTitle
+	page.Summary = v.Summary
+	page.AISummary = ""
+	page.SEODescription = v.SEODescription
+	page.Tags = v.Tags
+	page.Owner = v.Owner
+	page.PostDate = v.PostDate
+	page.CoverURL = v.CoverURL
+	page.Series = v.Series
+	page.ContentType = v.ContentType
+	page.Properties = v.Properties
+	page.Content = v.Content
+	page.Document = v.Document
+	return page
+}
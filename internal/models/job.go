@@ -1,22 +1,268 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"gorm.io/gorm"
 	"time"
 )
 
+// Distribution job states
+const (
+	JobPending            = "pending"
+	JobInProgress         = "in_progress"
+	JobCompleted          = "completed"
+	JobFailed             = "failed"
+	JobDraft              = "draft"
+	JobRepublishRequested = "republish_requested"
+	JobUnpublished        = "unpublished"
+	JobDuplicate          = "duplicate"
+	// JobQuarantined jobs failed too often to be retried automatically; republishing releases them
+	JobQuarantined = "quarantined"
+	// JobPaused jobs were held while their platform was paused and run when it is resumed
+	JobPaused = "paused"
+)
+
+// jobTransitions lists the states a job may move to from each state; "" is a job being created.
+// Republish requested, duplicate and failed jobs are kept for history and replaced by new jobs;
+// a quarantined job blocks new jobs until it is released for republishing. A paused job is
+// resumed by the next publish of its page and platform. A completed job fails when the platform
+// rejects the content after accepting it, as WeChat can while publishing in the background.
+var jobTransitions = map[string][]string{
+	"":                    {JobPending, JobInProgress, JobDraft, JobCompleted, JobFailed, JobPaused},
+	JobPending:            {JobInProgress, JobFailed},
+	JobInProgress:         {JobCompleted, JobFailed, JobDraft, JobDuplicate, JobQuarantined},
+	JobDraft:              {JobInProgress, JobRepublishRequested},
+	JobCompleted:          {JobRepublishRequested, JobUnpublished, JobDuplicate, JobFailed},
+	JobFailed:             {JobRepublishRequested},
+	JobUnpublished:        {JobRepublishRequested},
+	JobQuarantined:        {JobRepublishRequested},
+	JobPaused:             {JobInProgress, JobRepublishRequested},
+	JobRepublishRequested: {},
+	JobDuplicate:          {},
+}
+
+// ErrInvalidJobTransition is returned when a job is moved to a state it cannot reach from its current one
+var ErrInvalidJobTransition = errors.New("invalid job status transition")
+
+// CanTransitionJob reports whether a job may move from one state to another
+func CanTransitionJob(from, to string) bool {
+	for _, allowed := range jobTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// JSONMap represents a PostgreSQL jsonb object of string values
+type JSONMap map[string]string
+
+// Scan implements the sql.Scanner interface
+func (m *JSONMap) Scan(value interface{}) error {
+	if value == nil {
+		*m = JSONMap{}
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into JSONMap", value)
+	}
+
+	result := JSONMap{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("failed to unmarshal JSONMap: %w", err)
+	}
+	*m = result
+	return nil
+}
+
+// Value implements the driver.Valuer interface
+func (m JSONMap) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
 type DistributionJob struct {
-	ID          uint           `gorm:"primaryKey" json:"id"`
-	PageID      uint           `gorm:"not null;index" json:"page_id"`
-	PlatformID  uint           `gorm:"not null;index" json:"platform_id"`
-	Status      string         `gorm:"size:50;default:'pending'" json:"status"`
-	Content     string         `gorm:"type:text" json:"content"`
-	Error       string         `gorm:"type:text" json:"error"`
-	PublishedAt *time.Time     `json:"published_at"`
-	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	PageID     uint   `gorm:"not null;index" json:"page_id"`
+	PlatformID uint   `gorm:"not null;index" json:"platform_id"`
+	Status     string `gorm:"size:50;default:'pending'" json:"status"`
+	Content    string `gorm:"type:text" json:"content"`
+	// ContentHash fingerprints the page content the job published, see publisher.ContentHash
+	ContentHash string      `gorm:"size:64" json:"content_hash,omitempty"`
+	Error       string      `gorm:"type:text" json:"error"`
+	ErrorCode   string      `gorm:"size:32;index" json:"error_code,omitempty"`
+	PublishID   string      `gorm:"size:255" json:"publish_id"`
+	URL         string      `gorm:"size:1024" json:"url,omitempty"`
+	Metadata    JSONMap     `gorm:"type:jsonb;default:'{}';index:,type:gin" json:"metadata"`
+	HookResults HookResults `gorm:"type:jsonb;default:'[]'" json:"hook_results"`
+	PublishedAt *time.Time  `json:"published_at"`
+	// StartedAt and FinishedAt bound the latest run of the job
+	StartedAt      *time.Time     `json:"started_at,omitempty"`
+	FinishedAt     *time.Time     `gorm:"index" json:"finished_at,omitempty"`
+	StageDurations StageDurations `gorm:"type:jsonb;default:'{}'" json:"stage_durations"`
+	CreatedAt      time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 
 	Page     NotionPage `gorm:"foreignKey:PageID" json:"page"`
 	Platform Platform   `gorm:"foreignKey:PlatformID" json:"platform"`
 }
+
+// HookResult records the outcome of a publish hook run for a job
+type HookResult struct {
+	Hook       string `json:"hook"`
+	Stage      string `json:"stage"`
+	Passed     bool   `json:"passed"`
+	Message    string `json:"message,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// HookResults represents a PostgreSQL jsonb array of hook results
+type HookResults []HookResult
+
+// Scan implements the sql.Scanner interface
+func (r *HookResults) Scan(value interface{}) error {
+	if value == nil {
+		*r = HookResults{}
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into HookResults", value)
+	}
+
+	result := HookResults{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("failed to unmarshal HookResults: %w", err)
+	}
+	*r = result
+	return nil
+}
+
+// Value implements the driver.Valuer interface
+func (r HookResults) Value() (driver.Value, error) {
+	if r == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Publish stages timed for each job run
+const (
+	StageTransform = "transform"
+	StageMedia     = "media"
+	StageUpload    = "upload"
+	StagePublish   = "publish"
+)
+
+// StageDurations represents a PostgreSQL jsonb object of the milliseconds spent in each stage
+type StageDurations map[string]int64
+
+// Scan implements the sql.Scanner interface
+func (d *StageDurations) Scan(value interface{}) error {
+	if value == nil {
+		*d = StageDurations{}
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into StageDurations", value)
+	}
+
+	result := StageDurations{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("failed to unmarshal StageDurations: %w", err)
+	}
+	*d = result
+	return nil
+}
+
+// Value implements the driver.Valuer interface
+func (d StageDurations) Value() (driver.Value, error) {
+	if d == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// JobTransition records a status change of a distribution job
+type JobTransition struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	JobID      uint      `gorm:"not null;index" json:"job_id"`
+	FromStatus string    `gorm:"size:50" json:"from_status"`
+	ToStatus   string    `gorm:"size:50;not null" json:"to_status"`
+	Error      string    `gorm:"type:text" json:"error"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// JobLog is a log line written while a distribution job was running
+type JobLog struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	JobID     uint      `gorm:"not null;index" json:"job_id"`
+	Level     string    `gorm:"size:10;not null" json:"level"`
+	Message   string    `gorm:"type:text;not null" json:"message"`
+	Fields    JSONMap   `gorm:"type:jsonb;default:'{}'" json:"fields"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// MediaAsset is an image already uploaded to a platform account, keyed by the SHA-256 of its
+// bytes so the same image is not uploaded again on every publish
+type MediaAsset struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Hash       string    `gorm:"size:64;not null;uniqueIndex:idx_media_assets_key" json:"hash"`
+	Platform   string    `gorm:"size:100;not null;uniqueIndex:idx_media_assets_key" json:"platform"`
+	Account    string    `gorm:"size:255;not null;default:'';uniqueIndex:idx_media_assets_key" json:"account"`
+	Kind       string    `gorm:"size:50;not null;uniqueIndex:idx_media_assets_key" json:"kind"`
+	MediaID    string    `gorm:"size:255" json:"media_id"`
+	URL        string    `gorm:"type:text" json:"url"`
+	Size       int64     `json:"size"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+// PostSlug reserves the path of a post in a static-site repository for the page published
+// there, so two pages with the same title and date don't overwrite each other's post
+type PostSlug struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Repository string    `gorm:"size:512;not null;uniqueIndex:idx_post_slugs_path" json:"repository"`
+	Path       string    `gorm:"size:512;not null;uniqueIndex:idx_post_slugs_path" json:"path"`
+	Platform   string    `gorm:"size:100;not null" json:"platform"`
+	NotionID   string    `gorm:"size:255;not null;index" json:"notion_id"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -30,6 +30,9 @@ type PlatformStats struct {
 	FailedJobs       int       `gorm:"default:0" json:"failed_jobs"`
 	PendingJobs      int       `gorm:"default:0" json:"pending_jobs"`
 	AvgProcessTime   float64   `gorm:"default:0" json:"avg_process_time"` // 平均处理时间(秒)
+	P50ProcessTime   float64   `gorm:"default:0" json:"p50_process_time"` // 处理时间中位数(秒)
+	P95ProcessTime   float64   `gorm:"default:0" json:"p95_process_time"` // 处理时间 95 分位(秒)
+	AvgStageDurations StageDurations `gorm:"type:jsonb;default:'{}'" json:"avg_stage_durations"` // 各阶段平均耗时(毫秒)
 	LastSuccessAt    *time.Time `json:"last_success_at"`
 	LastFailureAt    *time.Time `json:"last_failure_at"`
 	ErrorCount       int       `gorm:"default:0" json:"error_count"`
@@ -47,10 +50,15 @@ type ErrorLog struct {
 	PlatformName string     `gorm:"size:100;index" json:"platform_name"`          // 平台名称(如果是平台相关错误)
 	PageID       *uint      `gorm:"index" json:"page_id"`                         // 相关的页面ID
 	JobID        *uint      `gorm:"index" json:"job_id"`                          // 相关的任务ID
+	ErrorType    string     `gorm:"size:50;index" json:"error_type"`              // 错误类型(如 credentials_expired)
+	ErrorCode    string     `gorm:"size:32;index" json:"error_code"`              // 发布失败的错误码(如 RATE_LIMITED)
+	Fingerprint  string     `gorm:"size:64;index" json:"fingerprint"`             // 错误指纹，相同指纹的错误归为同一个问题
+	IssueID      *uint      `gorm:"index" json:"issue_id"`                        // 所属的错误问题
 	Title        string     `gorm:"size:500;not null" json:"title"`               // 错误标题
 	Message      string     `gorm:"type:text;not null" json:"message"`            // 错误信息
 	StackTrace   string     `gorm:"type:text" json:"stack_trace"`                 // 堆栈信息
 	Context      string     `gorm:"type:jsonb" json:"context"`                    // 额外上下文信息
+	RequestID    string     `gorm:"size:128;index" json:"request_id,omitempty"`   // 触发错误的 API 请求 ID
 	Resolved     bool       `gorm:"default:false;index" json:"resolved"`          // 是否已解决
 	ResolvedAt   *time.Time `json:"resolved_at"`
 	CreatedAt    time.Time  `gorm:"autoCreateTime;index" json:"created_at"`
@@ -60,6 +68,26 @@ type ErrorLog struct {
 	Job  *DistributionJob `gorm:"foreignKey:JobID" json:"job,omitempty"`
 }
 
+// ErrorIssue 错误问题，按指纹聚合重复出现的错误日志
+type ErrorIssue struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Fingerprint  string     `gorm:"size:64;uniqueIndex;not null" json:"fingerprint"` // 来源、错误码和归一化后的错误信息的哈希
+	Level        string     `gorm:"size:20;not null;index" json:"level"`           // 最近一次出现的级别
+	Source       string     `gorm:"size:100;not null;index" json:"source"`
+	PlatformName string     `gorm:"size:100;index" json:"platform_name"`           // 最近一次出现的平台
+	ErrorType    string     `gorm:"size:50" json:"error_type"`
+	ErrorCode    string     `gorm:"size:32" json:"error_code"`
+	Title        string     `gorm:"size:500;not null" json:"title"`                // 最近一次出现的标题
+	Message      string     `gorm:"type:text;not null" json:"message"`             // 最近一次出现的错误信息
+	Count        int64      `gorm:"default:0" json:"count"`                        // 出现次数
+	FirstSeenAt  time.Time  `gorm:"not null" json:"first_seen_at"`
+	LastSeenAt   time.Time  `gorm:"not null;index" json:"last_seen_at"`
+	Resolved     bool       `gorm:"default:false;index" json:"resolved"`           // 再次出现时重新打开
+	ResolvedAt   *time.Time `json:"resolved_at"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
 // MetricsSample 指标采样数据
 type MetricsSample struct {
 	ID         uint      `gorm:"primaryKey" json:"id"`
@@ -85,5 +113,22 @@ type DashboardSummary struct {
 	LastPublishTime        *time.Time `json:"last_publish_time"`
 	UnresolvedErrorsCount  int       `gorm:"default:0" json:"unresolved_errors_count"`
 	AvgProcessTimeToday    float64   `gorm:"default:0" json:"avg_process_time_today"`
+	P95ProcessTimeToday    float64   `gorm:"default:0" json:"p95_process_time_today"`
 	UpdatedAt              time.Time `gorm:"autoUpdateTime" json:"updated_at"`
-}
\ No newline at end of file
+}
+// PostMetric is a daily snapshot of how a published post performs on a platform
+type PostMetric struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	JobID        uint      `gorm:"not null;uniqueIndex:idx_post_metrics_job_date" json:"job_id"`
+	PageID       uint      `gorm:"not null;index" json:"page_id"`
+	PlatformName string    `gorm:"size:100;not null;index" json:"platform_name"`
+	Date         time.Time `gorm:"type:date;not null;uniqueIndex:idx_post_metrics_job_date" json:"date"`
+	Views        int64     `gorm:"default:0" json:"views"`
+	Readers      int64     `gorm:"default:0" json:"readers"`
+	Likes        int64     `gorm:"default:0" json:"likes"`
+	Comments     int64     `gorm:"default:0" json:"comments"`
+	Shares       int64     `gorm:"default:0" json:"shares"`
+	CollectedAt  time.Time `json:"collected_at"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"github.com/ifuryst/ripple/internal/content"
 )
 
 // StringArray represents a PostgreSQL text[] type
@@ -75,22 +77,129 @@ func (s StringArray) Value() (driver.Value, error) {
 	return fmt.Sprintf("{%s}", strings.Join(quoted, ",")), nil
 }
 
+// Content sources a page can be synced from
+const (
+	SourceNotion     = "notion"
+	SourceMarkdown   = "markdown"
+	SourceObsidian   = "obsidian"
+	SourceGoogleDocs = "google-docs"
+)
+
+// Publish priorities; the scheduler publishes urgent pages first and backfilled pages last
+const (
+	PriorityUrgent   = "urgent"
+	PriorityNormal   = "normal"
+	PriorityBackfill = "backfill"
+)
+
+// PriorityRank orders priorities, lower ranks are published first. Unknown priorities, like
+// rows created before priorities existed, rank as normal.
+func PriorityRank(priority string) int {
+	switch priority {
+	case PriorityUrgent:
+		return 0
+	case PriorityBackfill:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// IsValidPriority reports whether priority is urgent, normal or backfill
+func IsValidPriority(priority string) bool {
+	return priority == PriorityUrgent || priority == PriorityNormal || priority == PriorityBackfill
+}
+
 type NotionPage struct {
 	ID           uint           `gorm:"primaryKey" json:"id"`
 	NotionID     string         `gorm:"uniqueIndex;not null;size:255" json:"notion_id"`
 	Title        string         `gorm:"not null;size:500" json:"title"`
 	ENTitle      string         `gorm:"size:500" json:"en_title"`
 	Content      string         `gorm:"type:text" json:"content"`
+	Document     string         `gorm:"type:text" json:"-"`
 	Summary      string         `gorm:"type:text" json:"summary"`
 	Tags         StringArray    `gorm:"type:text[]" json:"tags"`
 	Status       string         `gorm:"size:50;default:'draft'" json:"status"`
 	PostDate     *time.Time     `json:"post_date"`
+	UnpublishAt  *time.Time     `gorm:"index" json:"unpublish_at"`
 	Owner        string         `gorm:"size:500" json:"owner"`
 	Platforms    StringArray    `gorm:"type:text[]" json:"platforms"`
 	ContentType  StringArray    `gorm:"type:text[]" json:"content_type"`
+	CoverURL     string         `gorm:"type:text" json:"cover_url"`
+	Source       string         `gorm:"size:50;default:'notion';index" json:"source"`
+	Priority     string         `gorm:"size:20;default:'normal';index" json:"priority"`
+	Series       string         `gorm:"size:255;index" json:"series"`
 	Properties   string         `gorm:"type:jsonb" json:"properties"`
 	LastModified time.Time      `json:"last_modified"`
 	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt    time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+
+	// AISummary, SEODescription and SuggestedTags are generated by the AI enrichment and kept
+	// across syncs; EnrichedAt tells whether they are older than the page
+	AISummary      string      `gorm:"type:text" json:"ai_summary"`
+	SEODescription string      `gorm:"size:500" json:"seo_description"`
+	SuggestedTags  StringArray `gorm:"type:text[]" json:"suggested_tags"`
+	EnrichedAt     *time.Time  `json:"enriched_at"`
+
+	// ArchivedAt is set when the page was archived or deleted in Notion; archived pages are no
+	// longer published and are purged after the retention period
+	ArchivedAt    *time.Time `gorm:"index" json:"archived_at"`
+	ArchiveReason string     `gorm:"size:50" json:"archive_reason,omitempty"`
+
+	// Hold is the Hold checkbox of the page, LockedAt is set when the page was locked through the
+	// API; held pages keep syncing but are not published by the scheduler. The lock is kept across
+	// syncs until it is released.
+	Hold       bool       `gorm:"not null;default:false" json:"hold"`
+	LockedAt   *time.Time `json:"locked_at,omitempty"`
+	LockReason string     `gorm:"size:500" json:"lock_reason,omitempty"`
+
+	// SearchText is the tags and plain body text, kept up to date by BeforeSave
+	SearchText string `gorm:"type:text" json:"-"`
+	// SearchVector is maintained by Postgres from the title, summary and search text
+	SearchVector string `gorm:"->;type:tsvector GENERATED ALWAYS AS (setweight(to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(en_title, '')), 'A') || setweight(to_tsvector('simple', coalesce(summary, '')), 'B') || setweight(to_tsvector('simple', coalesce(search_text, '')), 'C')) STORED;index:,type:gin" json:"-"`
+}
+
+// BeforeSave refreshes the text the page is searched by
+func (p *NotionPage) BeforeSave(tx *gorm.DB) error {
+	p.SearchText = p.BuildSearchText()
+	return nil
+}
+
+// BuildSearchText returns the tags and the plain text of the page body
+func (p *NotionPage) BuildSearchText() string {
+	body := p.Document
+	if body == "" {
+		body = p.Content
+	}
+
+	text := strings.Join(p.Tags, " ")
+	if doc, err := content.Parse(body); err == nil {
+		text += "\n" + doc.Text()
+	}
+	return text
+}
+
+// IsArchived reports whether the page was archived or deleted at its source
+func (p *NotionPage) IsArchived() bool {
+	return p.ArchivedAt != nil
+}
+
+// IsHeld reports whether the page is held back from scheduled publishing, by its Hold checkbox
+// or a lock
+func (p *NotionPage) IsHeld() bool {
+	return p.Hold || p.LockedAt != nil
+}
+
+// StaysUnpublished reports whether a sync keeps the page Unpublished: Ripple marks pages
+// Unpublished itself once their unpublish date passed, and the source still shows them Done.
+// Moving the unpublish date to the future or clearing it lets the page be published again.
+func (p *NotionPage) StaysUnpublished(unpublishAt *time.Time) bool {
+	return p.Status == "Unpublished" && unpublishAt != nil && !unpublishAt.After(time.Now())
+}
+
+// IsFromNotion reports whether the page was synced from the Notion database; rows created before
+// sources existed have no source set
+func (p *NotionPage) IsFromNotion() bool {
+	return p.Source == "" || p.Source == SourceNotion
 }
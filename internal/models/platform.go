@@ -6,11 +6,15 @@ import (
 )
 
 type Platform struct {
-	ID          uint           `gorm:"primaryKey" json:"id"`
-	Name        string         `gorm:"uniqueIndex;not null;size:100" json:"name"`
-	DisplayName string         `gorm:"not null;size:100" json:"display_name"`
-	Config      string         `gorm:"type:jsonb" json:"config"`
-	Enabled     bool           `gorm:"default:true" json:"enabled"`
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	Name        string `gorm:"uniqueIndex;not null;size:100" json:"name"`
+	DisplayName string `gorm:"not null;size:100" json:"display_name"`
+	Config      string `gorm:"type:jsonb" json:"config"`
+	Enabled     bool   `gorm:"default:true" json:"enabled"`
+	// Paused platforms hold their publishes as paused jobs until they are resumed
+	Paused      bool           `gorm:"not null;default:false" json:"paused"`
+	PauseReason string         `gorm:"size:500" json:"pause_reason,omitempty"`
+	PausedAt    *time.Time     `json:"paused_at,omitempty"`
 	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at"`
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// PublishTask is a publish of a page to a platform queued by the scheduler for the publish
+// workers. A page is queued at most once per platform; a task is removed once a worker
+// processed it.
+type PublishTask struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	PageID   uint   `gorm:"not null;uniqueIndex:idx_publish_task" json:"page_id"`
+	Platform string `gorm:"size:100;not null;uniqueIndex:idx_publish_task" json:"platform"`
+	Priority string `gorm:"size:20" json:"priority"`
+	// Rank orders the queue, see PriorityRank
+	Rank int `gorm:"not null;default:1;index" json:"-"`
+	// ClaimedAt is when a worker took the task, nil while it waits
+	ClaimedAt *time.Time `json:"claimed_at,omitempty"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -0,0 +1,168 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ifuryst/ripple/internal/content"
+)
+
+// Sync run states
+const (
+	SyncRunning   = "running"
+	SyncCompleted = "completed"
+	SyncFailed    = "failed"
+)
+
+// Outcomes of syncing a single page
+const (
+	PageCreated = "created"
+	PageUpdated = "updated"
+	PageSkipped = "skipped"
+	// PageArchived is a page archived or deleted in Notion since the last sync
+	PageArchived = "archived"
+)
+
+// Reasons a page was archived
+const (
+	ArchiveReasonArchived = "archived"
+	ArchiveReasonDeleted  = "deleted"
+)
+
+// SyncPageError is a page that failed to sync
+type SyncPageError struct {
+	PageID string `json:"page_id"`
+	Title  string `json:"title"`
+	Error  string `json:"error"`
+}
+
+// SyncPageErrors represents a PostgreSQL jsonb array of page errors
+type SyncPageErrors []SyncPageError
+
+// Scan implements the sql.Scanner interface
+func (e *SyncPageErrors) Scan(value interface{}) error {
+	if value == nil {
+		*e = SyncPageErrors{}
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into SyncPageErrors", value)
+	}
+
+	result := SyncPageErrors{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("failed to unmarshal SyncPageErrors: %w", err)
+	}
+	*e = result
+	return nil
+}
+
+// Value implements the driver.Valuer interface
+func (e SyncPageErrors) Value() (driver.Value, error) {
+	if e == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// SyncRun records one sync of a content source and what it changed
+type SyncRun struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	Source     string         `gorm:"size:50;not null;index" json:"source"`
+	Status     string         `gorm:"size:20;not null;index" json:"status"`
+	StartedAt  time.Time      `gorm:"not null;index" json:"started_at"`
+	FinishedAt *time.Time     `json:"finished_at"`
+	Scanned    int            `gorm:"default:0" json:"scanned"`
+	Created    int            `gorm:"default:0" json:"created"`
+	Updated    int            `gorm:"default:0" json:"updated"`
+	Skipped    int            `gorm:"default:0" json:"skipped"`
+	Failed     int            `gorm:"default:0" json:"failed"`
+	Archived   int            `gorm:"default:0" json:"archived"`
+	Errors     SyncPageErrors `gorm:"type:jsonb;default:'[]'" json:"errors"`
+	Error      string         `gorm:"type:text" json:"error"`
+	CreatedAt  time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Content metrics of the pages the run fetched the content of, see RecordContent
+	ContentBytes      int64 `gorm:"default:0" json:"content_bytes"`
+	Blocks            int   `gorm:"default:0" json:"blocks"`
+	Images            int   `gorm:"default:0" json:"images"`
+	DegradedBlocks    int   `gorm:"default:0" json:"degraded_blocks"`
+	UnsupportedBlocks int   `gorm:"default:0" json:"unsupported_blocks"`
+	IncompletePages   int   `gorm:"default:0" json:"incomplete_pages"`
+}
+
+// NewSyncRun starts a run for the source
+func NewSyncRun(source string) *SyncRun {
+	return &SyncRun{
+		Source:    source,
+		Status:    SyncRunning,
+		StartedAt: time.Now(),
+		Errors:    SyncPageErrors{},
+	}
+}
+
+// Record counts the outcome of syncing a page
+func (r *SyncRun) Record(pageID, title, outcome string, err error) {
+	r.Scanned++
+	if err != nil {
+		r.Failed++
+		r.Errors = append(r.Errors, SyncPageError{PageID: pageID, Title: title, Error: err.Error()})
+		return
+	}
+
+	switch outcome {
+	case PageCreated:
+		r.Created++
+	case PageUpdated:
+		r.Updated++
+	case PageArchived:
+		r.Archived++
+	default:
+		r.Skipped++
+	}
+}
+
+// RecordContent adds the size and block coverage of a page whose content was fetched
+func (r *SyncRun) RecordContent(coverage *content.Coverage) {
+	r.ContentBytes += int64(coverage.Bytes)
+	r.Blocks += coverage.Blocks
+	r.Images += coverage.Images
+	r.DegradedBlocks += coverage.DegradedBlocks()
+	r.UnsupportedBlocks += coverage.UnsupportedBlocks()
+	if !coverage.Complete() {
+		r.IncompletePages++
+	}
+}
+
+// Finish marks the run as completed, or failed when the source could not be read at all
+func (r *SyncRun) Finish(err error) {
+	now := time.Now()
+	r.FinishedAt = &now
+	r.Status = SyncCompleted
+	if err != nil {
+		r.Status = SyncFailed
+		r.Error = err.Error()
+	}
+}
+
+// Duration returns how long the run took so far
+func (r *SyncRun) Duration() time.Duration {
+	if r.FinishedAt == nil {
+		return time.Since(r.StartedAt)
+	}
+	return r.FinishedAt.Sub(r.StartedAt)
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// compressedTypes are the content types worth compressing; images, videos and archives already are
+var compressedTypes = []string{
+	"text/",
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"application/yaml",
+	"image/svg+xml",
+}
+
+var gzipWriters = sync.Pool{
+	New: func() interface{} {
+		writer, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return writer
+	},
+}
+
+// compressionMiddleware gzips responses for clients accepting it. Whether a response is
+// compressed is decided when its body is first written, from its status and content type, so
+// empty responses like 304s and streamed events are sent as they are.
+func compressionMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Vary", "Accept-Encoding")
+		if !acceptsGzip(c.GetHeader("Accept-Encoding")) || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		writer := &gzipResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		defer func() {
+			writer.close()
+			c.Writer = writer.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(header string) bool {
+	for _, coding := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(coding), ";")
+		if strings.EqualFold(strings.TrimSpace(name), "gzip") {
+			return strings.ReplaceAll(strings.TrimSpace(params), " ", "") != "q=0"
+		}
+	}
+	return false
+}
+
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	gzip    *gzip.Writer
+	decided bool
+}
+
+// start decides on the first write whether the response is compressed
+func (w *gzipResponseWriter) start() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" || w.Status() != http.StatusOK || !compressible(header.Get("Content-Type")) {
+		return
+	}
+	header.Set("Content-Encoding", "gzip")
+	header.Del("Content-Length")
+	header.Del("Accept-Ranges")
+
+	w.gzip = gzipWriters.Get().(*gzip.Writer)
+	w.gzip.Reset(w.ResponseWriter)
+}
+
+func compressible(contentType string) bool {
+	if strings.HasPrefix(contentType, "text/event-stream") {
+		return false
+	}
+	for _, prefix := range compressedTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	w.start()
+	if w.gzip == nil {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.gzip.Write(data)
+}
+
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *gzipResponseWriter) Flush() {
+	if w.gzip != nil {
+		_ = w.gzip.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// close writes the end of the compressed stream
+func (w *gzipResponseWriter) close() {
+	if w.gzip == nil {
+		return
+	}
+	_ = w.gzip.Close()
+	w.gzip.Reset(nil)
+	gzipWriters.Put(w.gzip)
+	w.gzip = nil
+}
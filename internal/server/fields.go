@@ -0,0 +1,47 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// parseFieldsParam splits a fields query parameter, e.g. "notion_id,title,status"
+func parseFieldsParam(value string) []string {
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// selectFields keeps only the given fields, and those in keep, of the objects of a list. Without
+// fields the list is returned as it is.
+func selectFields(items interface{}, fields []string, keep ...string) (interface{}, error) {
+	if len(fields) == 0 {
+		return items, nil
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	var objects []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &objects); err != nil {
+		return nil, err
+	}
+
+	names := append(append([]string{}, fields...), keep...)
+	selected := make([]map[string]json.RawMessage, 0, len(objects))
+	for _, object := range objects {
+		picked := make(map[string]json.RawMessage, len(names))
+		for _, name := range names {
+			if value, ok := object[name]; ok {
+				picked[name] = value
+			}
+		}
+		selected = append(selected, picked)
+	}
+	return selected, nil
+}
@@ -0,0 +1,343 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service"
+	"github.com/ifuryst/ripple/pkg/graphql"
+)
+
+// maxGraphQLLimit caps the limit argument of list fields, since one query can nest several
+const maxGraphQLLimit = 200
+
+func (s *Server) handleGraphQL(c *gin.Context) {
+	var req graphql.Request
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				c.JSON(http.StatusBadRequest, graphql.Response{Errors: []*graphql.Error{{Message: "variables must be a JSON object"}}})
+				return
+			}
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, graphql.Response{Errors: []*graphql.Error{{Message: "Invalid GraphQL request"}}})
+		return
+	}
+	if req.Query == "" {
+		c.JSON(http.StatusBadRequest, graphql.Response{Errors: []*graphql.Error{{Message: "query is required"}}})
+		return
+	}
+
+	c.JSON(http.StatusOK, s.graphQLSchema().Execute(c.Request.Context(), req))
+}
+
+func (s *Server) handleGraphQLSchema(c *gin.Context) {
+	c.String(http.StatusOK, s.graphQLSchema().SDL())
+}
+
+func (s *Server) graphQLSchema() *graphql.Schema {
+	s.graphQLOnce.Do(func() {
+		s.graphQL = s.buildGraphQLSchema()
+	})
+	return s.graphQL
+}
+
+// buildGraphQLSchema exposes pages, jobs, platforms, stats and errors with their relations, so
+// the dashboard can fetch what a view needs in one request. Field names are the JSON names of
+// the REST API.
+func (s *Server) buildGraphQLSchema() *graphql.Schema {
+	page := graphql.ObjectOf("Page", "A page synced from Notion or another source", models.NotionPage{}, nil)
+	platform := graphql.ObjectOf("Platform", "A platform pages are published to", models.Platform{}, graphql.Fields{
+		// Platform config may hold credentials
+		"config": nil,
+	})
+	transition := graphql.ObjectOf("JobTransition", "A status change of a job", models.JobTransition{}, nil)
+	job := graphql.ObjectOf("Job", "The publish of a page to a platform", models.DistributionJob{}, graphql.Fields{
+		"page": {
+			Type: page,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				job := graphQLSource[models.DistributionJob](p)
+				if job.Page.ID != 0 {
+					return job.Page, nil
+				}
+				return s.findOrNil(p, &models.NotionPage{}, job.PageID)
+			},
+		},
+		"platform": {
+			Type: platform,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				job := graphQLSource[models.DistributionJob](p)
+				if job.Platform.ID != 0 {
+					return job.Platform, nil
+				}
+				return s.findOrNil(p, &models.Platform{}, job.PlatformID)
+			},
+		},
+		"transitions": {
+			Type:        graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(transition))),
+			Description: "The status changes of the job, oldest first",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return s.PublisherService.GetJobTransitions(graphQLSource[models.DistributionJob](p).ID)
+			},
+		},
+	})
+	platformStats := graphql.ObjectOf("PlatformStats", "Daily statistics of a platform", models.PlatformStats{}, graphql.Fields{
+		"platform": {
+			Type: platform,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return s.findOrNil(p, &models.Platform{}, graphQLSource[models.PlatformStats](p).PlatformID)
+			},
+		},
+	})
+	systemStats := graphql.ObjectOf("SystemStats", "Daily statistics of the system", models.SystemStats{}, nil)
+	summary := graphql.ObjectOf("DashboardSummary", "The overview shown on the dashboard", models.DashboardSummary{}, graphql.Fields{
+		"id": nil,
+	})
+	// The page and job of an error log are preloaded
+	errorLog := graphql.ObjectOf("ErrorLog", "An occurrence of an error", models.ErrorLog{}, graphql.Fields{
+		"page": {Type: page},
+		"job":  {Type: job},
+	})
+	errorIssue := graphql.ObjectOf("ErrorIssue", "Errors grouped by their fingerprint", models.ErrorIssue{}, graphql.Fields{
+		"occurrences": {
+			Type:        graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(errorLog))),
+			Description: "The latest occurrences of the error, newest first",
+			Args:        []*graphql.Argument{limitArgument(20)},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				_, occurrences, err := s.MonitoringService.GetErrorIssue(graphQLSource[models.ErrorIssue](p).ID, graphQLLimit(p))
+				return occurrences, err
+			},
+		},
+	})
+
+	jobFilters := []*graphql.Argument{
+		limitArgument(20),
+		{Name: "offset", Type: graphql.Int, Default: 0},
+		{Name: "status", Type: graphql.String, Description: "pending, completed, failed, ..."},
+		{Name: "error_code", Type: graphql.String, Description: "AUTH_EXPIRED, RATE_LIMITED, CONTENT_INVALID, NETWORK, PLATFORM_5XX"},
+		// Last, so that the jobs of a platform leave it out
+		{Name: "platform", Type: graphql.String, Description: "Name of the platform"},
+	}
+	jobList := graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(job)))
+
+	page.Fields["jobs"] = &graphql.Field{
+		Type:        jobList,
+		Description: "The jobs of the page, most recently updated first",
+		Args:        jobFilters,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return s.graphQLJobs(p, "page_id = ?", graphQLSource[models.NotionPage](p).ID)
+		},
+	}
+	platform.Fields["jobs"] = &graphql.Field{
+		Type:        jobList,
+		Description: "The jobs of the platform, most recently updated first",
+		Args:        jobFilters[:4],
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return s.graphQLJobs(p, "platform_id = ?", graphQLSource[models.Platform](p).ID)
+		},
+	}
+	platform.Fields["stats"] = &graphql.Field{
+		Type:        graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(platformStats))),
+		Description: "The daily statistics of the platform, newest first",
+		Args:        []*graphql.Argument{daysArgument()},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			var stats []models.PlatformStats
+			err := s.DB.WithContext(p.Context).
+				Where("platform_id = ? AND date >= ?", graphQLSource[models.Platform](p).ID, graphQLSince(p)).
+				Order("date desc").
+				Find(&stats).Error
+			return stats, err
+		},
+	}
+
+	query := &graphql.Object{Name: "Query", Fields: graphql.Fields{
+		"summary": {
+			Type: summary,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return s.MonitoringService.GetDashboardSummary()
+			},
+		},
+		"pages": {
+			Type:        graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(page))),
+			Description: "Pages, most recently updated first",
+			Args: []*graphql.Argument{
+				limitArgument(20),
+				{Name: "offset", Type: graphql.Int, Default: 0},
+				{Name: "status", Type: graphql.String},
+				{Name: "source", Type: graphql.String},
+				{Name: "series", Type: graphql.String},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				q := s.DB.WithContext(p.Context)
+				for _, column := range []string{"status", "source", "series"} {
+					if value, ok := p.Args[column].(string); ok {
+						q = q.Where(column+" = ?", value)
+					}
+				}
+				var pages []models.NotionPage
+				err := q.Order("updated_at desc").
+					Offset(p.Args["offset"].(int)).
+					Limit(graphQLLimit(p)).
+					Find(&pages).Error
+				return pages, err
+			},
+		},
+		"page": {
+			Type: page,
+			Args: []*graphql.Argument{{Name: "notion_id", Type: graphql.NonNullOf(graphql.ID)}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				var found models.NotionPage
+				err := s.DB.WithContext(p.Context).Where("notion_id = ?", p.Args["notion_id"]).First(&found).Error
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil, nil
+				}
+				return found, err
+			},
+		},
+		"jobs": {
+			Type:        jobList,
+			Description: "Jobs, most recently updated first",
+			Args:        jobFilters,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return s.graphQLJobs(p, "")
+			},
+		},
+		"job": {
+			Type: job,
+			Args: []*graphql.Argument{{Name: "id", Type: graphql.NonNullOf(graphql.ID)}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				id, err := strconv.ParseUint(p.Args["id"].(string), 10, 32)
+				if err != nil {
+					return nil, fmt.Errorf("invalid job ID")
+				}
+				var found models.DistributionJob
+				err = s.DB.WithContext(p.Context).Preload("Page").Preload("Platform").First(&found, id).Error
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil, nil
+				}
+				return found, err
+			},
+		},
+		"platforms": {
+			Type: graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(platform))),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				var platforms []models.Platform
+				err := s.DB.WithContext(p.Context).Order("name").Find(&platforms).Error
+				return platforms, err
+			},
+		},
+		"platform_stats": {
+			Type:        graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(platformStats))),
+			Description: "The daily statistics of all platforms",
+			Args:        []*graphql.Argument{daysArgument()},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return s.MonitoringService.GetPlatformStats(p.Args["days"].(int))
+			},
+		},
+		"system_stats": {
+			Type:        graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(systemStats))),
+			Description: "The daily statistics of the system, newest first",
+			Args:        []*graphql.Argument{daysArgument()},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				var stats []models.SystemStats
+				err := s.DB.WithContext(p.Context).Where("date >= ?", graphQLSince(p)).Order("date desc").Find(&stats).Error
+				return stats, err
+			},
+		},
+		"errors": {
+			Type:        graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(errorIssue))),
+			Description: "Error issues, most recently seen first",
+			Args: []*graphql.Argument{
+				limitArgument(20),
+				{Name: "status", Type: graphql.String, Default: service.IssuesUnresolved, Description: "unresolved, resolved or all"},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				status := p.Args["status"].(string)
+				switch status {
+				case service.IssuesUnresolved, service.IssuesResolved, service.IssuesAll:
+				default:
+					return nil, fmt.Errorf("status must be unresolved, resolved or all")
+				}
+				return s.MonitoringService.GetErrorIssues(status, graphQLLimit(p))
+			},
+		},
+	}}
+
+	return &graphql.Schema{Query: query}
+}
+
+// graphQLJobs lists jobs matching the filter arguments and an optional extra condition
+func (s *Server) graphQLJobs(p graphql.ResolveParams, condition string, args ...interface{}) ([]models.DistributionJob, error) {
+	q := s.DB.WithContext(p.Context).Preload("Page").Preload("Platform")
+	if condition != "" {
+		q = q.Where(condition, args...)
+	}
+	if status, ok := p.Args["status"].(string); ok {
+		q = q.Where("status = ?", status)
+	}
+	if errorCode, ok := p.Args["error_code"].(string); ok {
+		q = q.Where("error_code = ?", errorCode)
+	}
+	if platformName, ok := p.Args["platform"].(string); ok {
+		q = q.Where("platform_id IN (?)", s.DB.Model(&models.Platform{}).Select("id").Where("name = ?", platformName))
+	}
+
+	var jobs []models.DistributionJob
+	err := q.Order("updated_at desc").
+		Offset(p.Args["offset"].(int)).
+		Limit(graphQLLimit(p)).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// findOrNil loads a record by ID; a missing record resolves to null
+func (s *Server) findOrNil(p graphql.ResolveParams, dest interface{}, id uint) (interface{}, error) {
+	err := s.DB.WithContext(p.Context).First(dest, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return dest, err
+}
+
+// graphQLSource returns the parent value of a field, which resolvers return as values or pointers
+func graphQLSource[T any](p graphql.ResolveParams) T {
+	switch source := p.Source.(type) {
+	case T:
+		return source
+	case *T:
+		return *source
+	}
+	var zero T
+	return zero
+}
+
+func limitArgument(def int) *graphql.Argument {
+	return &graphql.Argument{Name: "limit", Type: graphql.Int, Default: def, Description: fmt.Sprintf("At most %d", maxGraphQLLimit)}
+}
+
+func daysArgument() *graphql.Argument {
+	return &graphql.Argument{Name: "days", Type: graphql.Int, Default: 7}
+}
+
+func graphQLLimit(p graphql.ResolveParams) int {
+	limit := p.Args["limit"].(int)
+	if limit <= 0 || limit > maxGraphQLLimit {
+		return maxGraphQLLimit
+	}
+	return limit
+}
+
+func graphQLSince(p graphql.ResolveParams) time.Time {
+	return time.Now().AddDate(0, 0, -p.Args["days"].(int)).Truncate(24 * time.Hour)
+}
@@ -0,0 +1,698 @@
+package server
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service"
+	"github.com/ifuryst/ripple/internal/service/notion"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/graphql"
+)
+
+// apiDoc documents an API route for the OpenAPI spec. The routes themselves come from the
+// router, so a route without a doc is still listed, only without details.
+type apiDoc struct {
+	Summary string
+	Query   []apiParam
+	// Body and Response are zero values of the request and response; their types are turned
+	// into schemas. A fields value describes a gin.H.
+	Body     interface{}
+	Response interface{}
+	// Status is the success status, 200 when unset
+	Status int
+	// ContentType of the response when it isn't JSON
+	ContentType string
+}
+
+// apiParam is a query parameter
+type apiParam struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+// fields describes a JSON object by the zero values of its fields
+type fields map[string]interface{}
+
+// message is the response of actions that only confirm they were done
+var message = fields{"message": ""}
+
+var (
+	limitParam  = apiParam{"limit", "integer", "maximum number of items"}
+	offsetParam = apiParam{"offset", "integer", "number of items to skip"}
+	daysParam   = apiParam{"days", "integer", "number of days to cover"}
+	forceParam  = apiParam{"force", "boolean", "run even if nothing changed"}
+	fieldsParam = apiParam{"fields", "string", "comma separated page fields to return, every field but the content by default"}
+)
+
+// apiDocs documents the API routes by method and path
+var apiDocs = map[string]apiDoc{
+	"POST /api/v1/auth/login": {
+		Summary: "Log in with a TOTP code",
+		Body: struct {
+			Token string `json:"token"`
+		}{},
+		Response: fields{"message": "", "session_token": ""},
+	},
+	"POST /api/v1/auth/setup": {
+		Summary:  "Generate a TOTP secret, while none is configured",
+		Response: fields{"secret": "", "qr_url": "", "message": ""},
+	},
+	"POST /api/v1/auth/logout": {Summary: "Log out", Response: message},
+	"GET /api/v1/docs": {
+		Summary:     "Browse this spec with Swagger UI",
+		ContentType: "text/html",
+	},
+	"GET /api/v1/docs/openapi.json": {Summary: "Get this OpenAPI spec", ContentType: "application/json"},
+	"GET /api/v1/events": {
+		Summary:     "Stream job status changes, sync progress and new errors as server-sent events",
+		ContentType: "text/event-stream",
+	},
+	"GET /api/v1/graphql": {
+		Summary: "Run a GraphQL query given in the query string",
+		Query: []apiParam{
+			{"query", "string", "the GraphQL query"},
+			{"operationName", "string", "the operation to run when the query has several"},
+			{"variables", "string", "the variables as a JSON object"},
+		},
+		Response: graphql.Response{},
+	},
+	"POST /api/v1/graphql": {
+		Summary:  "Run a GraphQL query",
+		Body:     graphql.Request{},
+		Response: graphql.Response{},
+	},
+	"GET /api/v1/graphql/schema": {Summary: "Get the GraphQL schema in SDL", ContentType: "text/plain"},
+	"GET /api/v1/jobs/:jobId/logs": {
+		Summary: "Get the log lines of a job",
+		Query: []apiParam{
+			{"after", "integer", "only lines after this log ID"},
+			{"follow", "boolean", "stream the lines of a running job as server-sent events"},
+		},
+		Response: fields{"job_id": uint(0), "status": "", "logs": []models.JobLog{}},
+	},
+	"GET /api/v1/notion/pages": {
+		Summary:  "List the synced pages",
+		Query:    []apiParam{fieldsParam},
+		Response: fields{"pages": []models.NotionPage{}},
+	},
+	"POST /api/v1/notion/sync": {
+		Summary:  "Sync every page from Notion",
+		Response: fields{"message": "", "report": &models.SyncRun{}},
+	},
+	"POST /api/v1/notion/sync/:pageId": {
+		Summary:  "Sync a single page from Notion",
+		Query:    []apiParam{{"force", "boolean", "refresh the page even if Notion reports no edit, true by default"}},
+		Response: fields{"message": "", "outcome": "", "page": &models.NotionPage{}},
+	},
+	"GET /api/v1/notion/rate-limit": {
+		Summary: "Get the Notion API rate limiting statistics",
+		Response: fields{
+			"requests": int64(0), "throttled": int64(0), "throttled_wait_seconds": float64(0),
+			"queue_wait_seconds": float64(0), "queued": int64(0), "in_flight": int64(0), "last_throttled": &time.Time{},
+		},
+	},
+	"GET /api/v1/notion/sync-history": {
+		Summary:  "List the latest sync runs",
+		Query:    []apiParam{limitParam, {"source", "string", "only runs of this source"}},
+		Response: fields{"runs": []models.SyncRun{}, "last_successful": &models.SyncRun{}},
+	},
+	"GET /api/v1/notion/coverage": {
+		Summary:  "Report the synced pages with blocks that will not render completely",
+		Query:    []apiParam{{"all", "boolean", "list every page, not only the incomplete ones"}},
+		Response: notion.CoverageReport{},
+	},
+	"GET /api/v1/notion/coverage/:pageId": {
+		Summary:  "Get the content size and block coverage of a page",
+		Response: notion.PageCoverage{},
+	},
+	"GET /api/v1/pages/search": {
+		Summary: "Search pages by text, status, platform and date",
+		Query: []apiParam{
+			{"q", "string", "full text query"},
+			{"status", "string", "Notion status"},
+			{"platform", "string", "platform the page is published to"},
+			{"from", "string", "earliest post date, YYYY-MM-DD"},
+			{"to", "string", "latest post date, YYYY-MM-DD"},
+			limitParam, offsetParam, fieldsParam,
+		},
+		Response: fields{"pages": []service.SearchResult{}, "total": int64(0), "limit": 0, "offset": 0},
+	},
+	"GET /api/v1/pages/:pageId/content": {
+		Summary:  "Get the content of a page, left out of page lists",
+		Response: notion.PageContent{},
+	},
+	"POST /api/v1/pages/:pageId/enrich": {
+		Summary:  "Generate the AI summary, SEO description and tag suggestions of a page",
+		Response: fields{"message": "", "ai_summary": "", "seo_description": "", "suggested_tags": []string{}, "enriched_at": &time.Time{}},
+	},
+	"POST /api/v1/pages/purge-archived": {
+		Summary:  "Delete pages archived in Notion for longer than the retention",
+		Query:    []apiParam{{"older_than", "string", "retention as a duration, e.g. 720h"}},
+		Response: fields{"message": "", "report": &service.PurgeReport{}},
+	},
+	"GET /api/v1/publisher/platforms": {
+		Summary:  "List the registered platforms",
+		Response: fields{"platforms": []string{}},
+	},
+	"POST /api/v1/publisher/publish/:pageId": {
+		Summary:  "Publish a page to all its platforms",
+		Response: fields{"message": "", "results": map[string]*publisher.PublishResult{}},
+	},
+	"POST /api/v1/publisher/publish/:pageId/:platform": {
+		Summary:  "Publish a page to one platform",
+		Response: fields{"message": "", "result": &publisher.PublishResult{}},
+	},
+	"POST /api/v1/publisher/draft/:pageId/:platform": {
+		Summary:  "Save a page as a draft on a platform",
+		Response: fields{"message": "", "result": &publisher.PublishResult{}},
+	},
+	"POST /api/v1/publisher/promote/:jobId": {
+		Summary:  "Publish a saved draft",
+		Response: fields{"message": "", "job": &models.DistributionJob{}, "result": &publisher.PublishResult{}},
+	},
+	"GET /api/v1/publisher/drafts/:jobId/preview": {
+		Summary:  "Get the preview link of a saved draft and its QR code as a data URL",
+		Response: fields{"review": &service.DraftReview{}, "qr_code": ""},
+	},
+	"GET /api/v1/publisher/drafts/:jobId/preview/qrcode": {
+		Summary:     "Get the QR code of the preview link of a saved draft",
+		ContentType: "image/png",
+	},
+	"POST /api/v1/publisher/drafts/:jobId/preview/send": {
+		Summary: "Send a saved draft to a reviewer as a preview message",
+		Body: struct {
+			Recipient string `json:"recipient"`
+		}{},
+		Response: fields{"message": "", "review": &service.DraftReview{}, "qr_code": ""},
+	},
+	"GET /api/v1/publisher/history/:pageId": {
+		Summary:  "List the jobs of a page",
+		Response: fields{"history": []*models.DistributionJob{}},
+	},
+	"GET /api/v1/publisher/export/:pageId": {
+		Summary:     "Download a page in every platform format as a zip archive",
+		ContentType: "application/zip",
+	},
+	"GET /api/v1/publisher/lint/:pageId": {
+		Summary:  "Check a page against the content rules of its platforms",
+		Query:    []apiParam{{"platform", "string", "only lint for this platform"}},
+		Response: fields{"page_id": "", "passed": false, "reports": []*publisher.LintReport{}},
+	},
+	"GET /api/v1/publisher/route/:pageId": {
+		Summary:  "Show the platforms a page is routed to",
+		Response: fields{"page_id": "", "route": &publisher.Route{}},
+	},
+	"GET /api/v1/publisher/simulate/:pageId": {
+		Summary:  "Transform and validate a page for every enabled platform without publishing it",
+		Response: service.PageSimulation{},
+	},
+	"GET /api/v1/publisher/versions/:pageId": {
+		Summary:  "List the content versions published for a page, newest first",
+		Query:    []apiParam{{"platform", "string", "only versions of this platform"}},
+		Response: fields{"page_id": "", "versions": []models.ContentVersion{}, "count": 0},
+	},
+	"GET /api/v1/publisher/versions/:pageId/diff": {
+		Summary: "Diff two content versions of a page",
+		Query: []apiParam{
+			{"from", "integer", "the version ID to compare from"},
+			{"to", "integer", "the version ID to compare to, the current page when left out"},
+		},
+		Response: service.VersionDiff{},
+	},
+	"GET /api/v1/publisher/versions/:pageId/:versionId": {
+		Summary:  "Get a content version with the content and output it published",
+		Response: models.ContentVersion{},
+	},
+	"POST /api/v1/publisher/versions/:pageId/:versionId/republish": {
+		Summary:  "Publish a content version of a page again to its platform",
+		Response: fields{"message": "", "report": &service.VersionRepublishReport{}},
+	},
+	"POST /api/v1/publisher/process-pending": {
+		Summary:  "Queue the pending pages for the publish workers",
+		Response: fields{"message": "", "queued": 0},
+	},
+	"GET /api/v1/publisher/queue": {
+		Summary:  "List the pages waiting to be published per platform, and the held pages",
+		Response: fields{"queues": map[string][]service.QueuedPublish{}, "held": []service.HeldPage{}},
+	},
+	"PUT /api/v1/publisher/priority/:pageId": {
+		Summary: "Change the priority a page is published with",
+		Body: struct {
+			Priority string `json:"priority"`
+		}{},
+		Response: fields{"message": "", "page_id": "", "priority": ""},
+	},
+	"PUT /api/v1/publisher/lock/:pageId": {
+		Summary: "Hold a page back from scheduled publishing until it is unlocked",
+		Body: struct {
+			Reason string `json:"reason"`
+		}{},
+		Response: fields{"message": "", "page_id": "", "locked_at": "", "lock_reason": ""},
+	},
+	"DELETE /api/v1/publisher/lock/:pageId": {
+		Summary:  "Release the lock of a page",
+		Response: fields{"message": "", "page_id": "", "held": false},
+	},
+	"POST /api/v1/publisher/publish-batch": {
+		Summary: "Publish a selection of pages in the background",
+		Body: struct {
+			PageIDs  []string `json:"page_ids"`
+			Tag      string   `json:"tag"`
+			From     string   `json:"from"`
+			To       string   `json:"to"`
+			Platform string   `json:"platform"`
+		}{},
+		Response: fields{"message": "", "batch_id": uint(0), "batch": &models.PublishBatch{}},
+		Status:   http.StatusAccepted,
+	},
+	"GET /api/v1/publisher/batch/:id": {
+		Summary:  "Get the progress of a publish batch",
+		Response: fields{"batch": &models.PublishBatch{}, "progress": float64(0)},
+	},
+	"GET /api/v1/publisher/circuits": {
+		Summary:  "Get the circuit breaker state of every platform",
+		Response: fields{"circuits": []publisher.CircuitStatus{}},
+	},
+	"POST /api/v1/publisher/circuits/:platform/reset": {Summary: "Close the circuit of a platform", Response: message},
+	"GET /api/v1/publisher/paused": {
+		Summary:  "List the paused platforms",
+		Response: fields{"platforms": []models.Platform{}},
+	},
+	"GET /api/v1/publisher/freeze": {
+		Summary:  "Get the maintenance mode and publishing freeze windows",
+		Response: fields{"status": service.FreezeStatus{}, "schedule": &service.FreezeSchedule{}},
+	},
+	"POST /api/v1/publisher/platforms/:platform/pause": {
+		Summary: "Pause publishing to a platform",
+		Body: struct {
+			Reason string `json:"reason"`
+		}{},
+		Response: message,
+	},
+	"POST /api/v1/publisher/platforms/:platform/resume": {
+		Summary:  "Resume publishing to a platform",
+		Response: fields{"message": "", "resumed_jobs": 0},
+	},
+	"GET /api/v1/publisher/platforms/:platform/materials": {
+		Summary: "List the permanent material library of a platform",
+		Query: []apiParam{
+			{"type", "string", "image, video or voice, image by default"},
+			{"offset", "integer", "materials to skip"},
+			{"count", "integer", "materials to return, at most 20"},
+		},
+		Response: &service.MaterialLibrary{},
+	},
+	"POST /api/v1/publisher/platforms/:platform/materials": {
+		Summary:  "Upload a permanent material sent as the \"media\" form file, with a \"type\" form field of image or thumb",
+		Response: fields{"message": "", "material": &publisher.Material{}},
+	},
+	"DELETE /api/v1/publisher/platforms/:platform/materials/:mediaId": {
+		Summary:  "Delete a permanent material",
+		Query:    []apiParam{{"force", "boolean", "delete it even if it is the configured default thumb"}},
+		Response: message,
+	},
+	"POST /api/v1/publisher/import": {
+		Summary:  "Import posts already on the platforms as completed jobs",
+		Body:     service.ImportRequest{},
+		Response: &service.ImportReport{},
+	},
+	"POST /api/v1/admin/reload": {
+		Summary:  "Reload the configuration file",
+		Response: fields{"message": "", "report": &ReloadReport{}},
+	},
+	"GET /api/v1/admin/backup": {
+		Summary:     "Download a backup archive",
+		ContentType: "application/gzip",
+	},
+	"POST /api/v1/admin/restore": {
+		Summary:  "Restore a backup archive sent as the request body",
+		Query:    []apiParam{{"force", "boolean", "replace the pages, jobs and platforms already in the database"}},
+		Response: fields{"message": "", "report": &service.RestoreReport{}},
+	},
+	"GET /api/v1/admin/cleanup": {
+		Summary:  "Get the report of the last data cleanup",
+		Response: fields{"report": &service.CleanupReport{}},
+	},
+	"POST /api/v1/admin/cleanup": {
+		Summary:  "Clean up old monitoring data and logs now",
+		Response: fields{"message": "", "report": &service.CleanupReport{}},
+	},
+	"GET /api/v1/dashboard/summary": {
+		Summary:  "Get the dashboard summary",
+		Response: fields{"summary": &models.DashboardSummary{}},
+	},
+	"GET /api/v1/dashboard/platform-stats": {
+		Summary:  "Get the daily stats per platform",
+		Query:    []apiParam{daysParam},
+		Response: fields{"stats": []models.PlatformStats{}},
+	},
+	"GET /api/v1/dashboard/trends": {
+		Summary: "Get a metric over time",
+		Query: []apiParam{
+			{"bucket", "string", "hour, day or week"},
+			{"metric", "string", "success_rate, volume or duration"},
+			{"platform", "string", "only this platform"},
+		},
+		Response: fields{"trend": &service.Trend{}},
+	},
+	"GET /api/v1/dashboard/recent-errors": {
+		Summary:  "List the error issues seen most recently",
+		Query:    []apiParam{limitParam, {"status", "string", "unresolved, resolved or all"}},
+		Response: fields{"issues": []models.ErrorIssue{}},
+	},
+	"GET /api/v1/dashboard/system-stats": {
+		Summary:  "Get the daily system stats",
+		Query:    []apiParam{daysParam},
+		Response: fields{"stats": []models.SystemStats{}},
+	},
+	"GET /api/v1/dashboard/recent-pages": {
+		Summary:  "List the pages updated most recently",
+		Query:    []apiParam{limitParam, fieldsParam},
+		Response: fields{"pages": []models.NotionPage{}},
+	},
+	"GET /api/v1/dashboard/recent-jobs": {
+		Summary:  "List the jobs updated most recently",
+		Query:    []apiParam{limitParam},
+		Response: fields{"jobs": []models.DistributionJob{}},
+	},
+	"GET /api/v1/dashboard/jobs": {
+		Summary: "List jobs",
+		Query: []apiParam{
+			{"status", "string", "job status"},
+			{"publish_id", "string", "ID of the post on the platform"},
+			{"error_code", "string", "e.g. AUTH_EXPIRED or RATE_LIMITED"},
+			{"metadata", "string", "key:value metadata filter, can be repeated"},
+			limitParam, offsetParam,
+		},
+		Response: fields{"jobs": []models.DistributionJob{}, "total": int64(0), "limit": 0, "offset": 0},
+	},
+	"GET /api/v1/dashboard/jobs/:jobId/history": {
+		Summary:  "List the status changes of a job",
+		Response: fields{"transitions": []models.JobTransition{}},
+	},
+	"GET /api/v1/dashboard/calendar": {
+		Summary: "Get the publishing calendar",
+		Query: []apiParam{
+			{"from", "string", "first month, YYYY-MM"},
+			{"to", "string", "last month, YYYY-MM"},
+			{"tz", "string", "IANA time zone of the days"},
+		},
+		Response: fields{"from": "", "to": "", "timezone": "", "days": []service.CalendarDay{}},
+	},
+	"GET /api/v1/dashboard/page-metrics/:pageId": {
+		Summary:  "Get the daily stats of a page's posts",
+		Response: fields{"metrics": []models.PostMetric{}},
+	},
+	"POST /api/v1/dashboard/update-stats": {Summary: "Recompute today's stats", Response: message},
+	"GET /api/v1/dashboard/issues/:issueId": {
+		Summary:  "Get an error issue with its latest occurrences",
+		Query:    []apiParam{limitParam},
+		Response: fields{"issue": &models.ErrorIssue{}, "errors": []models.ErrorLog{}},
+	},
+	"POST /api/v1/dashboard/issues/:issueId/resolve": {Summary: "Resolve an error issue", Response: message},
+	"POST /api/v1/dashboard/resolve-error/:errorId":  {Summary: "Resolve an error log", Response: message},
+	"POST /api/v1/dashboard/republish-job/:jobId": {
+		Summary: "Republish a job, skipped when the content is unchanged unless forced",
+		Query:   []apiParam{forceParam},
+		Response: fields{
+			"message": "", "action": "", "content_changed": false,
+			"job":    fields{"id": uint(0), "status": "", "error": "", "published_at": &time.Time{}},
+			"result": &publisher.PublishResult{},
+		},
+	},
+	"POST /api/v1/dashboard/refresh-media/:jobId": {
+		Summary: "Upload the images of a job's page again and patch them into its draft or post, leaving the text alone",
+		Response: fields{
+			"message": "", "refreshed": 0, "failed": 0,
+			"job": fields{"id": uint(0), "status": "", "publish_id": "", "url": ""},
+		},
+	},
+}
+
+// swaggerUI renders the spec with Swagger UI loaded from a CDN
+const swaggerUI = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>Ripple API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>SwaggerUIBundle({url: "/api/v1/docs/openapi.json", dom_id: "#swagger-ui"});</script>
+</body>
+</html>`
+
+func (s *Server) handleAPIDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUI))
+}
+
+func (s *Server) handleOpenAPISpec(c *gin.Context) {
+	s.openAPIOnce.Do(func() {
+		s.openAPI = s.buildOpenAPI()
+	})
+	c.JSON(http.StatusOK, s.openAPI)
+}
+
+// buildOpenAPI generates the OpenAPI 3 spec of the API routes registered on the router
+func (s *Server) buildOpenAPI() map[string]interface{} {
+	schemas := newSchemaRegistry()
+	paths := make(map[string]map[string]interface{})
+
+	for _, route := range s.Router.Routes() {
+		if !strings.HasPrefix(route.Path, "/api/") {
+			continue
+		}
+		doc := apiDocs[route.Method+" "+route.Path]
+
+		operation := map[string]interface{}{
+			"operationId": operationID(route.Handler),
+			"summary":     doc.Summary,
+			"tags":        []string{apiTag(route.Path)},
+		}
+
+		var params []map[string]interface{}
+		segments := strings.Split(route.Path, "/")
+		for i, segment := range segments {
+			if !strings.HasPrefix(segment, ":") {
+				continue
+			}
+			name := segment[1:]
+			segments[i] = "{" + name + "}"
+			paramType := "string"
+			// Jobs, issues, errors and batches have numeric IDs, pages Notion IDs
+			if name != "pageId" && (name == "id" || strings.HasSuffix(name, "Id")) {
+				paramType = "integer"
+			}
+			params = append(params, map[string]interface{}{
+				"name": name, "in": "path", "required": true,
+				"schema": map[string]interface{}{"type": paramType},
+			})
+		}
+		for _, param := range doc.Query {
+			params = append(params, map[string]interface{}{
+				"name": param.Name, "in": "query", "description": param.Description,
+				"schema": map[string]interface{}{"type": param.Type},
+			})
+		}
+		if len(params) > 0 {
+			operation["parameters"] = params
+		}
+
+		if doc.Body != nil {
+			operation["requestBody"] = map[string]interface{}{
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": schemas.schemaOf(doc.Body)},
+				},
+			}
+		}
+
+		status := doc.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		response := map[string]interface{}{"description": http.StatusText(status)}
+		switch {
+		case doc.ContentType != "":
+			response["content"] = map[string]interface{}{doc.ContentType: map[string]interface{}{}}
+		case doc.Response != nil:
+			response["content"] = map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": schemas.schemaOf(doc.Response)},
+			}
+		}
+		operation["responses"] = map[string]interface{}{
+			strconv.Itoa(status): response,
+			"default": map[string]interface{}{
+				"description": "Error",
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": schemas.schemaOf(fields{"error": ""})},
+				},
+			},
+		}
+
+		path := strings.Join(segments, "/")
+		if paths[path] == nil {
+			paths[path] = make(map[string]interface{})
+		}
+		paths[path][strings.ToLower(route.Method)] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Ripple API",
+			"description": "Sync pages from Notion and distribute them to the publishing platforms.",
+			"version":     "v1",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas.schemas,
+			"securitySchemes": map[string]interface{}{
+				"session": map[string]interface{}{"type": "apiKey", "in": "cookie", "name": "auth_token"},
+			},
+		},
+		"security": []map[string][]string{{"session": {}}},
+	}
+}
+
+// operationID turns the name of a handler like server.(*Server).handleGetJobs-fm into getJobs
+func operationID(handler string) string {
+	name := handler[strings.LastIndex(handler, ".")+1:]
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "handle"), "-fm")
+	if name == "" {
+		return handler
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// apiTag groups routes by the segment after /api/v1
+func apiTag(path string) string {
+	parts := strings.Split(strings.TrimPrefix(path, "/api/v1/"), "/")
+	return parts[0]
+}
+
+// schemaRegistry turns Go types into JSON schemas, with named structs as shared components
+type schemaRegistry struct {
+	schemas map[string]interface{}
+	names   map[reflect.Type]string
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		schemas: make(map[string]interface{}),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+	errorType     = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+func (r *schemaRegistry) schemaOf(v interface{}) map[string]interface{} {
+	if f, ok := v.(fields); ok {
+		properties := make(map[string]interface{}, len(f))
+		for name, value := range f {
+			properties[name] = r.schemaOf(value)
+		}
+		return map[string]interface{}{"type": "object", "properties": properties}
+	}
+	return r.schemaOfType(reflect.TypeOf(v))
+}
+
+func (r *schemaRegistry) schemaOfType(t reflect.Type) map[string]interface{} {
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case deletedAtType:
+		return map[string]interface{}{"type": "string", "format": "date-time", "nullable": true}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := r.schemaOfType(t.Elem())
+		if _, ref := schema["$ref"]; ref {
+			return map[string]interface{}{"allOf": []interface{}{schema}, "nullable": true}
+		}
+		schema["nullable"] = true
+		return schema
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": r.schemaOfType(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": r.schemaOfType(t.Elem())}
+	case reflect.Struct:
+		return r.structSchema(t)
+	}
+	// Interfaces and anything else can hold any value
+	return map[string]interface{}{}
+}
+
+// structSchema returns a reference to the component of a named struct, adding it on first use;
+// anonymous structs are inlined
+func (r *schemaRegistry) structSchema(t reflect.Type) map[string]interface{} {
+	if t.Name() == "" {
+		return r.objectSchema(t)
+	}
+	name, ok := r.names[t]
+	if !ok {
+		name = t.Name()
+		if _, taken := r.schemas[name]; taken {
+			// Types of the same name in different packages
+			name = t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:] + name
+		}
+		r.names[t] = name
+		// Registered before its fields, so recursive types refer to themselves
+		r.schemas[name] = map[string]interface{}{}
+		r.schemas[name] = r.objectSchema(t)
+	}
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+func (r *schemaRegistry) objectSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	r.addFields(t, properties)
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+// addFields adds the JSON fields of a struct, including those of embedded structs
+func (r *schemaRegistry) addFields(t reflect.Type, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || field.Type == errorType || !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			r.addFields(field.Type, properties)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = r.schemaOfType(field.Type)
+	}
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/pkg/requestid"
+)
+
+// requestIDKey is the gin context key of the request ID
+const requestIDKey = "request_id"
+
+// requestIDMiddleware gives every request an ID, taken from the X-Request-ID header set by a
+// proxy or client when it is valid and generated otherwise. The ID is returned in the same
+// header and carried by the request context into the services, their outbound HTTP calls and
+// the errors they record.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		c.Set(requestIDKey, id)
+		c.Header(requestid.Header, id)
+		c.Request = c.Request.WithContext(requestid.With(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// requestLogger logs every request once it is answered: server errors as errors, client errors
+// as warnings and the rest as info, except health probes which are only logged at debug
+func requestLogger(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path
+		c.Next()
+
+		status := c.Writer.Status()
+		fields := []zap.Field{
+			zap.String("request_id", c.GetString(requestIDKey)),
+			zap.String("method", c.Request.Method),
+			zap.String("path", path),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("client_ip", c.ClientIP()),
+			zap.Int("size", c.Writer.Size()),
+		}
+		if c.Request.URL.RawQuery != "" {
+			fields = append(fields, zap.String("query", c.Request.URL.RawQuery))
+		}
+		if route := c.FullPath(); route != "" && route != path {
+			fields = append(fields, zap.String("route", route))
+		}
+		if userAgent := c.Request.UserAgent(); userAgent != "" {
+			fields = append(fields, zap.String("user_agent", userAgent))
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String("errors", c.Errors.String()))
+		}
+
+		switch {
+		case status >= http.StatusInternalServerError:
+			logger.Error("HTTP request", fields...)
+		case status >= http.StatusBadRequest:
+			logger.Warn("HTTP request", fields...)
+		case strings.HasPrefix(path, "/health"):
+			logger.Debug("HTTP request", fields...)
+		default:
+			logger.Info("HTTP request", fields...)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxCachedResponses bounds the responses kept; query parameters like days make the keys
+// open-ended, but the dashboard only ever asks for a few of them
+const maxCachedResponses = 100
+
+// responseCache keeps the successful responses of GET endpoints the dashboard polls for a
+// short time, so polling doesn't rerun their queries. Every response gets an ETag of its body,
+// and requests whose If-None-Match carries the ETag of the current response get 304 Not
+// Modified without a body, also after the cached copy expired and the response was rebuilt.
+type responseCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cachedResponse
+}
+
+type cachedResponse struct {
+	status      int
+	contentType string
+	body        []byte
+	etag        string
+	expires     time.Time
+}
+
+// newResponseCache returns a cache keeping responses for ttl; with a ttl of 0 every request is
+// answered by the handler, with ETags still set
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{ttl: ttl, entries: make(map[string]cachedResponse)}
+}
+
+// Handler is the middleware caching the response of the handlers after it
+func (rc *responseCache) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// The query is encoded sorted, so the order of its parameters doesn't matter
+		key := c.Request.URL.Path + "?" + c.Request.URL.Query().Encode()
+		if entry, ok := rc.get(key); ok {
+			c.Header("X-Cache", "HIT")
+			writeCachedResponse(c, entry)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = recorder
+		c.Next()
+		c.Writer = recorder.ResponseWriter
+
+		if recorder.status != http.StatusOK {
+			c.Data(recorder.status, recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(recorder.body.Bytes())
+		entry := cachedResponse{
+			status:      recorder.status,
+			contentType: recorder.Header().Get("Content-Type"),
+			body:        recorder.body.Bytes(),
+			etag:        `"` + hex.EncodeToString(sum[:16]) + `"`,
+			expires:     time.Now().Add(rc.ttl),
+		}
+		rc.set(key, entry)
+		c.Header("X-Cache", "MISS")
+		writeCachedResponse(c, entry)
+	}
+}
+
+func (rc *responseCache) get(key string) (cachedResponse, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	entry, ok := rc.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return cachedResponse{}, false
+	}
+	return entry, true
+}
+
+func (rc *responseCache) set(key string, entry cachedResponse) {
+	if rc.ttl <= 0 {
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if len(rc.entries) >= maxCachedResponses {
+		now := time.Now()
+		for cached, old := range rc.entries {
+			if now.After(old.expires) {
+				delete(rc.entries, cached)
+			}
+		}
+		if len(rc.entries) >= maxCachedResponses {
+			rc.entries = make(map[string]cachedResponse)
+		}
+	}
+	rc.entries[key] = entry
+}
+
+// writeCachedResponse sends a response, or 304 Not Modified when the client already has it.
+// no-cache lets browsers keep the response but makes them revalidate it on every request.
+func writeCachedResponse(c *gin.Context, entry cachedResponse) {
+	c.Header("ETag", entry.etag)
+	c.Header("Cache-Control", "no-cache")
+	if etagMatches(c.GetHeader("If-None-Match"), entry.etag) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	c.Data(entry.status, entry.contentType, entry.body)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag; weak validators match too,
+// as If-None-Match compares weakly
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// responseRecorder holds back the response of the handlers, so the cache can store it and
+// decide how to answer
+type responseRecorder struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	r.status = code
+}
+
+func (r *responseRecorder) WriteHeaderNow() {}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	return r.body.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	return r.body.WriteString(s)
+}
+
+func (r *responseRecorder) Status() int {
+	return r.status
+}
+
+func (r *responseRecorder) Size() int {
+	return r.body.Len()
+}
+
+func (r *responseRecorder) Written() bool {
+	return r.body.Len() > 0
+}
@@ -1,21 +1,42 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	yamlenv "github.com/ifuryst/go-yaml-env"
+	"github.com/joho/godotenv"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 
 	"github.com/ifuryst/ripple/internal/config"
+	"github.com/ifuryst/ripple/internal/events"
 	"github.com/ifuryst/ripple/internal/models"
 	"github.com/ifuryst/ripple/internal/service"
 	"github.com/ifuryst/ripple/internal/service/notion"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/internal/service/source"
+	"github.com/ifuryst/ripple/pkg/graphql"
+	"github.com/ifuryst/ripple/web"
+)
+
+const (
+	// eventsHeartbeatInterval is how often idle event streams get a keep-alive comment
+	eventsHeartbeatInterval = 30 * time.Second
+	// maxCalendarMonths limits how many months the publishing calendar returns at once
+	maxCalendarMonths = 12
 )
 
 type Server struct {
@@ -24,14 +45,34 @@ type Server struct {
 	Router *gin.Engine
 	Logger *zap.Logger
 	Server *http.Server
+	// ConfigPath is the configuration file Reload reads
+	ConfigPath string
+	reloadMu   sync.Mutex
+
+	// openAPI is the spec generated from the routes on first request
+	openAPIOnce sync.Once
+	openAPI     map[string]interface{}
+	// graphQL is the schema of the GraphQL endpoint, built on first request
+	graphQLOnce sync.Once
+	graphQL     *graphql.Schema
+	// dashboardCache serves the dashboard endpoints polled by the frontend from memory
+	dashboardCache *responseCache
 
 	// Services
 	NotionService     *notion.Service
 	PublisherService  *service.PublisherService
 	MonitoringService *service.MonitoringService
+	SearchService     *service.SearchService
 	StatsUpdater      *service.StatsUpdater
 	Scheduler         *service.Scheduler
 	AuthService       *service.AuthService
+	HealthService     *service.HealthService
+	CredentialMonitor *service.CredentialMonitor
+	DeploymentTracker *service.DeploymentTracker
+	MetricsCollector  *service.MetricsCollector
+	RetentionCleaner  *service.RetentionCleaner
+	TaskQueue         service.TaskQueue
+	PublishWorkers    *service.PublishWorkers
 }
 
 func NewServer(cfg *config.Config, logger *zap.Logger) (*Server, error) {
@@ -39,7 +80,7 @@ func NewServer(cfg *config.Config, logger *zap.Logger) (*Server, error) {
 	gin.SetMode(cfg.Server.Mode)
 
 	// Initialize database
-	db, err := service.NewDatabase(&cfg.Database)
+	db, err := service.NewDatabase(&cfg.Database, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize database: %w", err)
 	}
@@ -48,9 +89,21 @@ func NewServer(cfg *config.Config, logger *zap.Logger) (*Server, error) {
 	notionService := notion.NewService(&cfg.Notion, db, logger)
 	publisherService := service.NewPublisherService(cfg, db, logger, notionService)
 	monitoringService := service.NewMonitoringService(db, logger)
-	statsUpdater := service.NewStatsUpdater(monitoringService, logger, 15*time.Minute) // Update every 15 minutes
-	scheduler := service.NewScheduler(&cfg.Scheduler, logger, notionService, publisherService)
+	searchService := service.NewSearchService(db, logger)
+	statsUpdater := service.NewStatsUpdater(monitoringService, publisherService.Locker(), logger, 15*time.Minute) // Update every 15 minutes
+	sourceSyncer := source.NewSyncerFromConfig(&cfg.Sources, db, logger)
+	taskQueue, err := service.NewTaskQueue(cfg.Queue, db, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize publish queue: %w", err)
+	}
+	scheduler := service.NewScheduler(&cfg.Scheduler, logger, notionService, sourceSyncer, publisherService, taskQueue)
+	publishWorkers := service.NewPublishWorkers(publisherService, taskQueue, scheduler.FreezeStatus, logger, cfg.Queue.Workers)
 	authService := service.NewAuthService(logger, cfg.Auth.TOTPSecret)
+	healthService := service.NewHealthService(db, logger, notionService, publisherService, 5*time.Minute) // Cache platform credential checks for 5 minutes
+	credentialMonitor := service.NewCredentialMonitor(publisherService, logger, cfg.Publisher.CredentialCheckInterval)
+	deploymentTracker := service.NewDeploymentTracker(publisherService, logger, cfg.Publisher.DeploymentCheckInterval, cfg.Publisher.DeploymentTimeout)
+	metricsCollector := service.NewMetricsCollector(publisherService, logger, cfg.Publisher.MetricsInterval, cfg.Publisher.MetricsWindow)
+	retentionCleaner := service.NewRetentionCleaner(monitoringService, publisherService, cfg.Retention, logger)
 
 	// Create router
 	router := gin.New()
@@ -64,9 +117,18 @@ func NewServer(cfg *config.Config, logger *zap.Logger) (*Server, error) {
 		NotionService:     notionService,
 		PublisherService:  publisherService,
 		MonitoringService: monitoringService,
+		SearchService:     searchService,
 		StatsUpdater:      statsUpdater,
 		Scheduler:         scheduler,
 		AuthService:       authService,
+		HealthService:     healthService,
+		CredentialMonitor: credentialMonitor,
+		DeploymentTracker: deploymentTracker,
+		MetricsCollector:  metricsCollector,
+		RetentionCleaner:  retentionCleaner,
+		TaskQueue:         taskQueue,
+		PublishWorkers:    publishWorkers,
+		dashboardCache:    newResponseCache(cfg.Server.DashboardCacheTTL),
 	}
 
 	// Setup middleware and routes
@@ -77,17 +139,22 @@ func NewServer(cfg *config.Config, logger *zap.Logger) (*Server, error) {
 }
 
 func (s *Server) setupMiddleware() {
+	// Request ID and logging middleware, outside of the recovery so panics are logged as 500s
+	s.Router.Use(requestIDMiddleware())
+	s.Router.Use(requestLogger(s.Logger))
+
 	// Recovery middleware
 	s.Router.Use(gin.Recovery())
 
-	// Logger middleware
-	s.Router.Use(gin.Logger())
+	// Compression middleware
+	s.Router.Use(compressionMiddleware())
 
 	// CORS middleware
 	s.Router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
+		c.Header("Access-Control-Expose-Headers", "X-Request-ID")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -104,44 +171,45 @@ func (s *Server) setupMiddleware() {
 }
 
 func (s *Server) setupRoutes() {
+	dashboard := s.dashboardFS()
+
 	// Login page (bypass auth)
-	s.Router.GET("/login", func(c *gin.Context) {
-		c.File("./web/dist/index.html")
-	})
+	s.Router.GET("/login", s.serveDashboard(dashboard))
 
 	// Serve static files for dashboard
-	s.Router.Static("/assets", "./web/dist/assets")
-	s.Router.StaticFile("/favicon.ico", "./web/dist/favicon.ico")
+	if assets, err := fs.Sub(dashboard, "assets"); err == nil {
+		s.Router.StaticFS("/assets", http.FS(assets))
+	}
+	s.Router.StaticFileFS("/favicon.ico", "favicon.ico", http.FS(dashboard))
+
+	// Generated cover cards are fetched by the platforms
+	if s.Config.Publisher.Cards.Enabled {
+		s.Router.Static(service.CardRoutePrefix, s.Config.Publisher.Cards.OutputDir)
+	}
 
 	// Serve dashboard index.html for root path
-	s.Router.GET("/", func(c *gin.Context) {
-		c.File("./web/dist/index.html")
-	})
+	s.Router.GET("/", s.serveDashboard(dashboard))
 
 	// Serve dashboard for SPA routes (overview, platforms, trends, errors)
 	dashboardRoutes := []string{"/overview", "/platforms", "/trends", "/errors"}
 	for _, route := range dashboardRoutes {
-		s.Router.GET(route, func(c *gin.Context) {
-			c.File("./web/dist/index.html")
-		})
+		s.Router.GET(route, s.serveDashboard(dashboard))
 	}
 
 	// Serve dashboard for any other route that doesn't start with /api
+	serveIndex := s.serveDashboard(dashboard)
 	s.Router.NoRoute(func(c *gin.Context) {
 		if !strings.HasPrefix(c.Request.URL.Path, "/api") {
-			c.File("./web/dist/index.html")
+			serveIndex(c)
 		} else {
 			c.JSON(http.StatusNotFound, gin.H{"error": "API endpoint not found"})
 		}
 	})
 
-	// Health check
-	s.Router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status": "ok",
-			"time":   time.Now().Unix(),
-		})
-	})
+	// Health checks
+	s.Router.GET("/health", s.handleLiveness)
+	s.Router.GET("/health/live", s.handleLiveness)
+	s.Router.GET("/health/ready", s.handleReadiness)
 
 	// API routes
 	api := s.Router.Group("/api/v1")
@@ -154,11 +222,43 @@ func (s *Server) setupRoutes() {
 			auth.POST("/logout", s.handleLogout)
 		}
 
+		// OpenAPI spec of these routes and its Swagger UI
+		api.GET("/docs", s.handleAPIDocs)
+		api.GET("/docs/openapi.json", s.handleOpenAPISpec)
+
+		// Live updates for the dashboard
+		api.GET("/events", s.handleEvents)
+
+		// GraphQL queries over pages, jobs, platforms, stats and errors
+		api.GET("/graphql", s.handleGraphQL)
+		api.POST("/graphql", s.handleGraphQL)
+		api.GET("/graphql/schema", s.handleGraphQLSchema)
+
+		// Job logs; ?follow=true streams the lines of running jobs
+		jobs := api.Group("/jobs")
+		{
+			jobs.GET("/:jobId/logs", s.handleGetJobLogs)
+		}
+
 		// Notion routes
 		notion := api.Group("/notion")
 		{
 			notion.GET("/pages", s.handleGetNotionPages)
 			notion.POST("/sync", s.handleSyncNotionPages)
+			notion.POST("/sync/:pageId", s.handleSyncNotionPage)
+			notion.GET("/rate-limit", s.handleGetNotionRateLimit)
+			notion.GET("/sync-history", s.handleGetSyncHistory)
+			notion.GET("/coverage", s.handleGetContentCoverage)
+			notion.GET("/coverage/:pageId", s.handleGetPageCoverage)
+		}
+
+		// Page routes
+		pages := api.Group("/pages")
+		{
+			pages.GET("/search", s.handleSearchPages)
+			pages.GET("/:pageId/content", s.handleGetPageContent)
+			pages.POST("/:pageId/enrich", s.handleEnrichPage)
+			pages.POST("/purge-archived", s.handlePurgeArchivedPages)
 		}
 
 		// Publisher routes
@@ -168,156 +268,1360 @@ func (s *Server) setupRoutes() {
 			publisher.POST("/publish/:pageId", s.handlePublishPage)
 			publisher.POST("/publish/:pageId/:platform", s.handlePublishPageToPlatform)
 			publisher.POST("/draft/:pageId/:platform", s.handleSavePageToDraft)
+			publisher.POST("/promote/:jobId", s.handlePromoteDraft)
+			publisher.GET("/drafts/:jobId/preview", s.handlePreviewDraft)
+			publisher.GET("/drafts/:jobId/preview/qrcode", s.handleGetDraftQRCode)
+			publisher.POST("/drafts/:jobId/preview/send", s.handleSendDraftPreview)
 			publisher.GET("/history/:pageId", s.handleGetPublishHistory)
+			publisher.GET("/export/:pageId", s.handleExportPage)
+			publisher.GET("/lint/:pageId", s.handleLintPage)
+			publisher.GET("/route/:pageId", s.handleRoutePage)
+			publisher.GET("/simulate/:pageId", s.handleSimulatePage)
+			publisher.GET("/versions/:pageId", s.handleListVersions)
+			publisher.GET("/versions/:pageId/diff", s.handleDiffVersions)
+			publisher.GET("/versions/:pageId/:versionId", s.handleGetVersion)
+			publisher.POST("/versions/:pageId/:versionId/republish", s.handleRepublishVersion)
 			publisher.POST("/process-pending", s.handleProcessPendingPages)
+			publisher.GET("/queue", s.handleGetPublishQueues)
+			publisher.PUT("/priority/:pageId", s.handleSetPagePriority)
+			publisher.PUT("/lock/:pageId", s.handleLockPage)
+			publisher.DELETE("/lock/:pageId", s.handleUnlockPage)
+			publisher.POST("/publish-batch", s.handlePublishBatch)
+			publisher.GET("/batch/:id", s.handleGetPublishBatch)
+			publisher.GET("/circuits", s.handleGetCircuits)
+			publisher.POST("/circuits/:platform/reset", s.handleResetCircuit)
+			publisher.GET("/paused", s.handleGetPausedPlatforms)
+			publisher.GET("/freeze", s.handleGetFreeze)
+			publisher.POST("/platforms/:platform/pause", s.handlePausePlatform)
+			publisher.POST("/platforms/:platform/resume", s.handleResumePlatform)
+			publisher.GET("/platforms/:platform/materials", s.handleListMaterials)
+			publisher.POST("/platforms/:platform/materials", s.handleUploadMaterial)
+			publisher.DELETE("/platforms/:platform/materials/:mediaId", s.handleDeleteMaterial)
+			publisher.POST("/import", s.handleImportPosts)
+		}
+
+		// Admin routes
+		admin := api.Group("/admin")
+		{
+			admin.POST("/reload", s.handleReloadConfig)
+			admin.GET("/backup", s.handleBackup)
+			admin.POST("/restore", s.handleRestore)
+			admin.GET("/cleanup", s.handleGetCleanupReport)
+			admin.POST("/cleanup", s.handleCleanup)
 		}
 
 		// Dashboard routes
 		dashboard := api.Group("/dashboard")
 		{
-			dashboard.GET("/summary", s.handleGetDashboardSummary)
-			dashboard.GET("/platform-stats", s.handleGetPlatformStats)
+			dashboard.GET("/summary", s.dashboardCache.Handler(), s.handleGetDashboardSummary)
+			dashboard.GET("/platform-stats", s.dashboardCache.Handler(), s.handleGetPlatformStats)
+			dashboard.GET("/trends", s.handleGetTrends)
 			dashboard.GET("/recent-errors", s.handleGetRecentErrors)
 			dashboard.GET("/system-stats", s.handleGetSystemStats)
 			dashboard.GET("/recent-pages", s.handleGetRecentPages)
 			dashboard.GET("/recent-jobs", s.handleGetRecentJobs)
 			dashboard.GET("/jobs", s.handleGetJobs)
+			dashboard.GET("/jobs/:jobId/history", s.handleGetJobHistory)
+			dashboard.GET("/calendar", s.handleGetCalendar)
+			dashboard.GET("/page-metrics/:pageId", s.handleGetPageMetrics)
 			dashboard.POST("/update-stats", s.handleUpdateStats)
+			dashboard.GET("/issues/:issueId", s.handleGetErrorIssue)
+			dashboard.POST("/issues/:issueId/resolve", s.handleResolveErrorIssue)
 			dashboard.POST("/resolve-error/:errorId", s.handleResolveError)
 			dashboard.POST("/republish-job/:jobId", s.handleRepublishJob)
+			dashboard.POST("/refresh-media/:jobId", s.handleRefreshJobMedia)
 		}
 	}
 }
 
+func (s *Server) handleLiveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+		"time":   time.Now().Unix(),
+	})
+}
+
+func (s *Server) handleReadiness(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	report := s.HealthService.CheckReadiness(ctx)
+
+	status := http.StatusOK
+	if report.Status != service.HealthStatusUp {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, report)
+}
+
 func (s *Server) handleGetNotionPages(c *gin.Context) {
-	pages, err := s.NotionService.GetAllPages()
+	fields := parseFieldsParam(c.Query("fields"))
+	columns, err := s.NotionService.PageColumns(fields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pages, err := s.NotionService.ListPages(columns)
 	if err != nil {
 		s.Logger.Error("Failed to get notion pages", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get pages"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"pages": pages})
+	result, err := selectFields(pages, fields)
+	if err != nil {
+		s.Logger.Error("Failed to select page fields", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get pages"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pages": result})
+}
+
+func (s *Server) handleGetPageContent(c *gin.Context) {
+	pageID := c.Param("pageId")
+
+	page, err := s.NotionService.GetPageContent(pageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Page not found"})
+			return
+		}
+		s.Logger.Error("Failed to get page content", zap.String("page_id", pageID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get page content"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (s *Server) handleSearchPages(c *gin.Context) {
+	limitParam := c.DefaultQuery("limit", "20")
+	limit := 20
+	if l, err := strconv.Atoi(limitParam); err == nil && l > 0 {
+		limit = l
+	}
+
+	offsetParam := c.DefaultQuery("offset", "0")
+	offset := 0
+	if o, err := strconv.Atoi(offsetParam); err == nil && o >= 0 {
+		offset = o
+	}
+
+	fields := parseFieldsParam(c.Query("fields"))
+	columns, err := s.NotionService.PageColumns(fields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := service.SearchQuery{
+		Query:    c.Query("q"),
+		Status:   c.Query("status"),
+		Platform: c.Query("platform"),
+		Limit:    limit,
+		Offset:   offset,
+		Columns:  columns,
+	}
+
+	if query.From, err = parseDateParam("from", c.Query("from")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if query.To, err = parseDateParam("to", c.Query("to")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, total, err := s.SearchService.Search(query)
+	if err != nil {
+		s.Logger.Error("Failed to search pages", zap.String("query", query.Query), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search pages"})
+		return
+	}
+
+	pages, err := selectFields(results, fields, "rank", "snippet")
+	if err != nil {
+		s.Logger.Error("Failed to select page fields", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search pages"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pages":  pages,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+func (s *Server) handleEnrichPage(c *gin.Context) {
+	pageID := c.Param("pageId")
+
+	page, err := s.PublisherService.EnrichPage(c.Request.Context(), pageID)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Page not found"})
+		case errors.Is(err, service.ErrEnrichmentDisabled):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			s.Logger.Error("Failed to enrich page", zap.String("page_id", pageID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Page enriched successfully",
+		"ai_summary":      page.AISummary,
+		"seo_description": page.SEODescription,
+		"suggested_tags":  page.SuggestedTags,
+		"enriched_at":     page.EnrichedAt,
+	})
+}
+
+func (s *Server) handlePurgeArchivedPages(c *gin.Context) {
+	// older_than overrides the configured retention, e.g. 168h
+	var retention time.Duration
+	if value := c.Query("older_than"); value != "" {
+		var err error
+		if retention, err = time.ParseDuration(value); err != nil || retention <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "older_than must be a positive duration, e.g. 720h"})
+			return
+		}
+	}
+
+	report, err := s.PublisherService.PurgeArchivedPages(c.Request.Context(), retention)
+	if err != nil {
+		s.Logger.Error("Failed to purge archived pages", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Purged %d archived pages", len(report.Pages)),
+		"report":  report,
+	})
+}
+
+// parseDateParam parses a from/to date given as YYYY-MM-DD or RFC 3339; a bare "to" date
+// includes the whole day. Empty values return nil.
+func parseDateParam(name, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		date, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s date %q, expected YYYY-MM-DD", name, value)
+		}
+		if name == "to" {
+			date = date.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+	}
+	return &date, nil
+}
+
+func (s *Server) handleSyncNotionPages(c *gin.Context) {
+	var report *models.SyncRun
+	err := s.PublisherService.Locker().TryRun(c.Request.Context(), service.LockSync, func() error {
+		var err error
+		report, err = s.NotionService.SyncPages()
+		return err
+	})
+	if errors.Is(err, service.ErrLockHeld) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A sync is already running on another replica"})
+		return
+	}
+	if err != nil {
+		s.Logger.Error("Failed to sync notion pages", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sync pages", "report": report})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sync completed successfully", "report": report})
+}
+
+func (s *Server) handleSyncNotionPage(c *gin.Context) {
+	pageID := c.Param("pageId")
+	// Notion only tracks edits to the minute, so refresh by default to pick up recent changes
+	force := c.DefaultQuery("force", "true") != "false"
+
+	page, outcome, err := s.NotionService.SyncPage(pageID, force)
+	if err != nil {
+		if errors.Is(err, notion.ErrPageNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Page not found in Notion"})
+			return
+		}
+		s.Logger.Error("Failed to sync notion page", zap.String("page_id", pageID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sync page"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Page synced successfully", "outcome": outcome, "page": page})
+}
+
+func (s *Server) handleGetSyncHistory(c *gin.Context) {
+	limitParam := c.DefaultQuery("limit", "20")
+	limit := 20
+	if l, err := strconv.Atoi(limitParam); err == nil && l > 0 {
+		limit = l
+	}
+	source := c.Query("source")
+
+	runs, err := s.MonitoringService.GetSyncHistory(source, limit)
+	if err != nil {
+		s.Logger.Error("Failed to get sync history", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sync history"})
+		return
+	}
+
+	lastSuccessful, err := s.MonitoringService.GetLastSuccessfulSync(source)
+	if err != nil {
+		s.Logger.Error("Failed to get last successful sync", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sync history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runs": runs, "last_successful": lastSuccessful})
+}
+
+func (s *Server) handleGetContentCoverage(c *gin.Context) {
+	all, _ := strconv.ParseBool(c.Query("all"))
+
+	report, err := s.NotionService.ContentCoverage(all)
+	if err != nil {
+		s.Logger.Error("Failed to get content coverage", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (s *Server) handleGetPageCoverage(c *gin.Context) {
+	pageID := c.Param("pageId")
+	if pageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Page ID is required"})
+		return
+	}
+
+	coverage, err := s.NotionService.PageContentCoverage(pageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Page not found"})
+			return
+		}
+		s.Logger.Error("Failed to get page coverage", zap.String("page_id", pageID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, coverage)
+}
+
+func (s *Server) handleGetNotionRateLimit(c *gin.Context) {
+	stats := s.NotionService.RateLimitStats()
+	c.JSON(http.StatusOK, gin.H{
+		"requests":               stats.Requests,
+		"throttled":              stats.Throttled,
+		"throttled_wait_seconds": stats.ThrottledWait.Seconds(),
+		"queue_wait_seconds":     stats.QueueWait.Seconds(),
+		"queued":                 stats.Queued,
+		"in_flight":              stats.InFlight,
+		"last_throttled":         stats.LastThrottled,
+	})
+}
+
+func (s *Server) handleGetPlatforms(c *gin.Context) {
+	platforms := s.PublisherService.GetAvailablePlatforms()
+	c.JSON(http.StatusOK, gin.H{"platforms": platforms})
+}
+
+func (s *Server) handlePublishPage(c *gin.Context) {
+	if !s.allowPublish(c) {
+		return
+	}
+
+	pageID := c.Param("pageId")
+	if pageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Page ID is required"})
+		return
+	}
+
+	results, err := s.PublisherService.PublishPage(c.Request.Context(), pageID)
+	if err != nil {
+		s.Logger.Error("Failed to publish page", zap.String("page_id", pageID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Page published successfully",
+		"results": results,
+	})
+}
+
+func (s *Server) handlePublishPageToPlatform(c *gin.Context) {
+	if !s.allowPublish(c) {
+		return
+	}
+
+	pageID := c.Param("pageId")
+	platform := c.Param("platform")
+
+	if pageID == "" || platform == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Page ID and platform are required"})
+		return
+	}
+
+	result, err := s.PublisherService.PublishPageToPlatform(c.Request.Context(), pageID, platform)
+	if err != nil {
+		s.Logger.Error("Failed to publish page to platform",
+			zap.String("page_id", pageID),
+			zap.String("platform", platform),
+			zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Page published to platform successfully",
+		"result":  result,
+	})
+}
+
+func (s *Server) handleSavePageToDraft(c *gin.Context) {
+	pageID := c.Param("pageId")
+	platform := c.Param("platform")
+
+	if pageID == "" || platform == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Page ID and platform are required"})
+		return
+	}
+
+	result, err := s.PublisherService.SavePageToDraft(c.Request.Context(), pageID, platform)
+	if err != nil {
+		s.Logger.Error("Failed to save page to draft",
+			zap.String("page_id", pageID),
+			zap.String("platform", platform),
+			zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Page saved to draft successfully",
+		"result":  result,
+	})
+}
+
+func (s *Server) handlePromoteDraft(c *gin.Context) {
+	if !s.allowPublish(c) {
+		return
+	}
+
+	jobID, err := strconv.ParseUint(c.Param("jobId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	job, result, err := s.PublisherService.PromoteDraft(c.Request.Context(), uint(jobID))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		case errors.Is(err, service.ErrJobNotDraft):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, publisher.ErrPublishInProgress):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			s.Logger.Error("Failed to promote draft", zap.Uint64("job_id", jobID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if !result.Success {
+		c.JSON(http.StatusBadGateway, gin.H{"error": result.ErrorMsg, "result": result})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Draft published successfully",
+		"job":     job,
+		"result":  result,
+	})
+}
+
+func (s *Server) handlePreviewDraft(c *gin.Context) {
+	review, ok := s.reviewDraft(c, false, "")
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"review":  review,
+		"qr_code": qrCodeDataURL(review.QRCode),
+	})
+}
+
+func (s *Server) handleGetDraftQRCode(c *gin.Context) {
+	review, ok := s.reviewDraft(c, false, "")
+	if !ok {
+		return
+	}
+	if len(review.QRCode) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "The draft has no preview link"})
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", review.QRCode)
+}
+
+func (s *Server) handleSendDraftPreview(c *gin.Context) {
+	var req struct {
+		// Recipient is the WeChat ID to send the preview to, the configured reviewer when empty
+		Recipient string `json:"recipient"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	review, ok := s.reviewDraft(c, true, req.Recipient)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Draft preview sent to %s", review.SentTo),
+		"review":  review,
+		"qr_code": qrCodeDataURL(review.QRCode),
+	})
+}
+
+// reviewDraft prepares the review of the draft job in the path, responding with the error when
+// it fails
+func (s *Server) reviewDraft(c *gin.Context, send bool, recipient string) (*service.DraftReview, bool) {
+	jobID, err := strconv.ParseUint(c.Param("jobId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return nil, false
+	}
+
+	review, err := s.PublisherService.ReviewDraft(c.Request.Context(), uint(jobID), send, recipient)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		case errors.Is(err, service.ErrJobNotDraft),
+			errors.Is(err, publisher.ErrPreviewNotSupported),
+			errors.Is(err, publisher.ErrNoPreviewRecipient):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			s.Logger.Error("Failed to preview draft", zap.Uint64("job_id", jobID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return nil, false
+	}
+	return review, true
+}
+
+// qrCodeDataURL embeds a PNG QR code in a data URL the dashboard can show as an image
+func qrCodeDataURL(png []byte) string {
+	if len(png) == 0 {
+		return ""
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(png)
+}
+
+func (s *Server) handleGetPublishHistory(c *gin.Context) {
+	pageID := c.Param("pageId")
+	if pageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Page ID is required"})
+		return
+	}
+
+	history, err := s.PublisherService.GetPublishHistory(c.Request.Context(), pageID)
+	if err != nil {
+		s.Logger.Error("Failed to get publish history", zap.String("page_id", pageID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"history": history})
+}
+
+func (s *Server) handleExportPage(c *gin.Context) {
+	pageID := c.Param("pageId")
+	if pageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Page ID is required"})
+		return
+	}
+
+	exportDir, err := os.MkdirTemp("", "ripple-export-*")
+	if err != nil {
+		s.Logger.Error("Failed to create export directory", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create export directory"})
+		return
+	}
+	defer os.RemoveAll(exportDir)
+
+	result, err := s.PublisherService.ExportPage(c.Request.Context(), pageID, exportDir)
+	if err != nil {
+		s.Logger.Error("Failed to export page", zap.String("page_id", pageID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", result.PageID+".zip"))
+	c.Status(http.StatusOK)
+	if err := service.WriteZip(c.Writer, exportDir); err != nil {
+		s.Logger.Error("Failed to write export archive", zap.String("page_id", pageID), zap.Error(err))
+	}
+}
+
+func (s *Server) handleLintPage(c *gin.Context) {
+	pageID := c.Param("pageId")
+	if pageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Page ID is required"})
+		return
+	}
+
+	reports, err := s.PublisherService.LintPage(pageID, c.Query("platform"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Page not found"})
+			return
+		}
+		s.Logger.Error("Failed to lint page", zap.String("page_id", pageID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	passed := true
+	for _, report := range reports {
+		passed = passed && report.Passed
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"page_id": pageID,
+		"passed":  passed,
+		"reports": reports,
+	})
+}
+
+// handleRoutePage previews the platforms a page would be published to without publishing it
+func (s *Server) handleRoutePage(c *gin.Context) {
+	pageID := c.Param("pageId")
+	if pageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Page ID is required"})
+		return
+	}
+
+	route, err := s.PublisherService.RoutePage(pageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Page not found"})
+			return
+		}
+		s.Logger.Error("Failed to route page", zap.String("page_id", pageID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"page_id": pageID,
+		"route":   route,
+	})
+}
+
+// handleSimulatePage reports how ready a page is on every enabled platform without publishing it
+func (s *Server) handleSimulatePage(c *gin.Context) {
+	pageID := c.Param("pageId")
+	if pageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Page ID is required"})
+		return
+	}
+
+	simulation, err := s.PublisherService.SimulatePage(c.Request.Context(), pageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Page not found"})
+			return
+		}
+		s.Logger.Error("Failed to simulate page", zap.String("page_id", pageID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, simulation)
+}
+
+// handleListVersions lists the content versions published for a page, optionally of one platform
+func (s *Server) handleListVersions(c *gin.Context) {
+	pageID := c.Param("pageId")
+	versions, err := s.PublisherService.ListVersions(c.Request.Context(), pageID, c.Query("platform"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Page not found"})
+			return
+		}
+		s.Logger.Error("Failed to list content versions", zap.String("page_id", pageID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"page_id":  pageID,
+		"versions": versions,
+		"count":    len(versions),
+	})
+}
+
+// handleGetVersion returns a content version with the content and output it published
+func (s *Server) handleGetVersion(c *gin.Context) {
+	pageID := c.Param("pageId")
+	versionID, err := strconv.ParseUint(c.Param("versionId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version ID"})
+		return
+	}
+
+	version, err := s.PublisherService.GetVersion(c.Request.Context(), pageID, uint(versionID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		s.Logger.Error("Failed to get content version", zap.String("page_id", pageID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, version)
+}
+
+// handleDiffVersions compares two content versions of a page, or a version with the current
+// page when to is left out
+func (s *Server) handleDiffVersions(c *gin.Context) {
+	pageID := c.Param("pageId")
+	fromID, err := strconv.ParseUint(c.Query("from"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from version ID"})
+		return
+	}
+	var toID uint64
+	if to := c.Query("to"); to != "" {
+		if toID, err = strconv.ParseUint(to, 10, 32); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to version ID"})
+			return
+		}
+	}
+
+	diff, err := s.PublisherService.DiffVersions(c.Request.Context(), pageID, uint(fromID), uint(toID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		s.Logger.Error("Failed to diff content versions", zap.String("page_id", pageID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// handleRepublishVersion publishes an older content version of a page again to its platform
+func (s *Server) handleRepublishVersion(c *gin.Context) {
+	if !s.allowPublish(c) {
+		return
+	}
+
+	pageID := c.Param("pageId")
+	versionID, err := strconv.ParseUint(c.Param("versionId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version ID"})
+		return
+	}
+
+	report, err := s.PublisherService.RepublishVersion(c.Request.Context(), pageID, uint(versionID))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrVersionNotRepublishable), errors.Is(err, service.ErrPageArchived):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, models.ErrInvalidJobTransition):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			s.Logger.Error("Failed to republish content version", zap.String("page_id", pageID), zap.Uint64("version_id", versionID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to republish version: %v", err)})
+		}
+		return
+	}
+
+	message := fmt.Sprintf("Version %d republished to %s", report.Version, report.Platform)
+	if report.Action == service.RerunActionFailed {
+		message = fmt.Sprintf("Republishing version %d to %s failed", report.Version, report.Platform)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"report":  report,
+	})
+}
+
+func (s *Server) handleGetPublishQueues(c *gin.Context) {
+	queues, err := s.PublisherService.PublishQueues(c.Request.Context())
+	if err != nil {
+		s.Logger.Error("Failed to get publish queues", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	held, err := s.PublisherService.HeldPages(c.Request.Context())
+	if err != nil {
+		s.Logger.Error("Failed to get held pages", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"queues": queues,
+		"held":   held,
+	})
+}
+
+func (s *Server) handleSetPagePriority(c *gin.Context) {
+	pageID := c.Param("pageId")
+	if pageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Page ID is required"})
+		return
+	}
+
+	var req struct {
+		Priority string `json:"priority" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Priority is required"})
+		return
+	}
+
+	page, err := s.PublisherService.SetPagePriority(pageID, req.Priority)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidPriority):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Page not found"})
+		default:
+			s.Logger.Error("Failed to set page priority", zap.String("page_id", pageID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Priority updated",
+		"page_id":  page.NotionID,
+		"priority": req.Priority,
+	})
+}
+
+func (s *Server) handleLockPage(c *gin.Context) {
+	pageID := c.Param("pageId")
+	if pageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Page ID is required"})
+		return
+	}
+
+	// The reason is optional, so an empty body is fine
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	page, err := s.PublisherService.LockPage(pageID, req.Reason)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Page not found"})
+			return
+		}
+		s.Logger.Error("Failed to lock page", zap.String("page_id", pageID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Page locked",
+		"page_id":     page.NotionID,
+		"locked_at":   page.LockedAt,
+		"lock_reason": page.LockReason,
+	})
+}
+
+func (s *Server) handleUnlockPage(c *gin.Context) {
+	pageID := c.Param("pageId")
+	if pageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Page ID is required"})
+		return
+	}
+
+	page, err := s.PublisherService.UnlockPage(pageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Page not found"})
+			return
+		}
+		s.Logger.Error("Failed to unlock page", zap.String("page_id", pageID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Pages with the Hold checkbox ticked stay held until it is cleared in Notion
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Page unlocked",
+		"page_id": page.NotionID,
+		"held":    page.IsHeld(),
+	})
+}
+
+func (s *Server) handleProcessPendingPages(c *gin.Context) {
+	if !s.allowPublish(c) {
+		return
+	}
+
+	var queued int
+	err := s.PublisherService.Locker().TryRun(c.Request.Context(), service.LockPublish, func() (err error) {
+		queued, err = s.PublisherService.EnqueuePendingPages(c.Request.Context(), s.TaskQueue)
+		return err
+	})
+	if errors.Is(err, service.ErrLockHeld) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Pending pages are already being queued on another replica"})
+		return
+	}
+	if err != nil {
+		s.Logger.Error("Failed to process pending pages", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Pending pages queued for publishing",
+		"queued":  queued,
+	})
+}
+
+func (s *Server) handlePublishBatch(c *gin.Context) {
+	if !s.allowPublish(c) {
+		return
+	}
+
+	var req struct {
+		PageIDs  []string `json:"page_ids"`
+		Tag      string   `json:"tag"`
+		From     string   `json:"from"`
+		To       string   `json:"to"`
+		Platform string   `json:"platform"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	request := service.PublishBatchRequest{
+		PageIDs:  req.PageIDs,
+		Tag:      req.Tag,
+		Platform: req.Platform,
+	}
+
+	var err error
+	if request.From, err = parseDateParam("from", req.From); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.To, err = parseDateParam("to", req.To); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	batch, err := s.PublisherService.CreatePublishBatch(request)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidBatchRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		s.Logger.Error("Failed to create publish batch", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create publish batch"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":  fmt.Sprintf("Publishing %d pages", batch.Total),
+		"batch_id": batch.ID,
+		"batch":    batch,
+	})
+}
+
+func (s *Server) handleGetPublishBatch(c *gin.Context) {
+	batchID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid batch ID"})
+		return
+	}
+
+	batch, err := s.PublisherService.GetPublishBatch(uint(batchID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Batch not found"})
+			return
+		}
+		s.Logger.Error("Failed to get publish batch", zap.Uint64("batch_id", batchID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get publish batch"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"batch": batch, "progress": batch.Progress()})
+}
+
+func (s *Server) handleGetCircuits(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"circuits": s.PublisherService.GetCircuitStatuses()})
+}
+
+func (s *Server) handleResetCircuit(c *gin.Context) {
+	platform := c.Param("platform")
+	s.PublisherService.ResetCircuit(platform)
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Circuit for %s reset", platform)})
+}
+
+// allowPublish reports whether a manual publish may run. While publishing is frozen by
+// maintenance mode or a freeze window the request needs override=true, otherwise it is
+// answered with 423 Locked.
+func (s *Server) allowPublish(c *gin.Context) bool {
+	freeze := s.Scheduler.FreezeStatus(time.Now())
+	if !freeze.Frozen {
+		return true
+	}
+
+	if override, _ := strconv.ParseBool(c.Query("override")); override {
+		s.Logger.Info("Overriding publish freeze",
+			zap.String("path", c.FullPath()),
+			zap.String("reason", freeze.Reason))
+		return true
+	}
+
+	c.JSON(http.StatusLocked, gin.H{
+		"error":  fmt.Sprintf("%s (%s), pass override=true to publish anyway", service.ErrPublishFrozen, freeze.Reason),
+		"freeze": freeze,
+	})
+	return false
+}
+
+func (s *Server) handleGetFreeze(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":   s.Scheduler.FreezeStatus(time.Now()),
+		"schedule": s.Scheduler.FreezeSchedule(),
+	})
+}
+
+func (s *Server) handleGetPausedPlatforms(c *gin.Context) {
+	platforms, err := s.PublisherService.PausedPlatforms()
+	if err != nil {
+		s.Logger.Error("Failed to get paused platforms", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"platforms": platforms})
+}
+
+func (s *Server) handlePausePlatform(c *gin.Context) {
+	platform := c.Param("platform")
+
+	// The reason is optional
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	if err := s.PublisherService.PausePlatform(platform, req.Reason); err != nil {
+		if errors.Is(err, service.ErrPlatformNotAvailable) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		s.Logger.Error("Failed to pause platform", zap.String("platform", platform), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Platform %s paused", platform)})
+}
+
+func (s *Server) handleResumePlatform(c *gin.Context) {
+	platform := c.Param("platform")
+
+	resumed, err := s.PublisherService.ResumePlatform(platform)
+	if err != nil {
+		if errors.Is(err, service.ErrPlatformNotAvailable) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		s.Logger.Error("Failed to resume platform", zap.String("platform", platform), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      fmt.Sprintf("Platform %s resumed", platform),
+		"resumed_jobs": resumed,
+	})
 }
 
-func (s *Server) handleSyncNotionPages(c *gin.Context) {
-	err := s.NotionService.SyncPages()
+func (s *Server) handleListMaterials(c *gin.Context) {
+	platform := c.Param("platform")
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	count, _ := strconv.Atoi(c.DefaultQuery("count", "20"))
+
+	library, err := s.PublisherService.ListMaterials(c.Request.Context(), platform, c.DefaultQuery("type", "image"), offset, count)
 	if err != nil {
-		s.Logger.Error("Failed to sync notion pages", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sync pages"})
+		s.materialError(c, "Failed to list materials", platform, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Sync completed successfully"})
+	c.JSON(http.StatusOK, library)
 }
 
-func (s *Server) handleGetPlatforms(c *gin.Context) {
-	platforms := s.PublisherService.GetAvailablePlatforms()
-	c.JSON(http.StatusOK, gin.H{"platforms": platforms})
-}
+func (s *Server) handleUploadMaterial(c *gin.Context) {
+	platform := c.Param("platform")
 
-func (s *Server) handlePublishPage(c *gin.Context) {
-	pageID := c.Param("pageId")
-	if pageID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Page ID is required"})
+	file, err := c.FormFile("media")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A media file upload is required"})
+		return
+	}
+	upload, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	defer upload.Close()
 
-	results, err := s.PublisherService.PublishPage(c.Request.Context(), pageID)
+	materialType := c.DefaultPostForm("type", "image")
+	material, err := s.PublisherService.UploadMaterial(c.Request.Context(), platform, materialType, file.Filename, upload)
 	if err != nil {
-		s.Logger.Error("Failed to publish page", zap.String("page_id", pageID), zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		s.materialError(c, "Failed to upload material", platform, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Page published successfully",
-		"results": results,
-	})
+	c.JSON(http.StatusOK, gin.H{"message": "Material uploaded", "material": material})
 }
 
-func (s *Server) handlePublishPageToPlatform(c *gin.Context) {
-	pageID := c.Param("pageId")
+func (s *Server) handleDeleteMaterial(c *gin.Context) {
 	platform := c.Param("platform")
+	mediaID := c.Param("mediaId")
 
-	if pageID == "" || platform == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Page ID and platform are required"})
+	if err := s.PublisherService.DeleteMaterial(c.Request.Context(), platform, mediaID, c.Query("force") == "true"); err != nil {
+		s.materialError(c, "Failed to delete material", platform, err)
 		return
 	}
 
-	result, err := s.PublisherService.PublishPageToPlatform(c.Request.Context(), pageID, platform)
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Material %s deleted", mediaID)})
+}
+
+// materialError responds to a failed material library request
+func (s *Server) materialError(c *gin.Context, message, platform string, err error) {
+	switch {
+	case errors.Is(err, service.ErrPlatformNotAvailable):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrMaterialInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, publisher.ErrMaterialsNotSupported),
+		errors.Is(err, publisher.ErrInvalidMaterialType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		s.Logger.Error(message, zap.String("platform", platform), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (s *Server) handleImportPosts(c *gin.Context) {
+	var request service.ImportRequest
+	// The body is optional, without it every platform is imported
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	report, err := s.PublisherService.ImportExistingPosts(c.Request.Context(), request)
 	if err != nil {
-		s.Logger.Error("Failed to publish page to platform",
-			zap.String("page_id", pageID),
-			zap.String("platform", platform),
-			zap.Error(err))
+		s.Logger.Error("Failed to import existing posts", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Page published to platform successfully",
-		"result":  result,
-	})
+	c.JSON(http.StatusOK, report)
 }
 
-func (s *Server) handleSavePageToDraft(c *gin.Context) {
-	pageID := c.Param("pageId")
-	platform := c.Param("platform")
+// ReloadReport lists what a configuration reload changed
+type ReloadReport struct {
+	Platforms        *service.PublisherReloadReport `json:"platforms"`
+	SchedulerEnabled bool                           `json:"scheduler_enabled"`
+	SyncInterval     string                         `json:"sync_interval"`
+}
 
-	if pageID == "" || platform == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Page ID and platform are required"})
+// Reload re-reads the configuration file and the .env file and applies the settings that can
+// change at runtime: the platform publishers and the scheduler. Everything else, e.g. the
+// database, the server address and the publish hooks, still needs a restart.
+func (s *Server) Reload() (*ReloadReport, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	// Unlike at startup, values from .env win over the environment, otherwise edits to it
+	// would never be seen by a running server
+	_ = godotenv.Overload()
+	cfg, err := yamlenv.LoadConfig[config.Config](s.ConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.Scheduler.Enabled && cfg.Scheduler.SyncInterval <= 0 {
+		return nil, fmt.Errorf("invalid scheduler sync interval %s", cfg.Scheduler.SyncInterval)
+	}
+	if _, err := service.ParseFreezeSchedule(cfg.Scheduler.Freeze); err != nil {
+		return nil, err
+	}
+
+	report := &ReloadReport{
+		Platforms:        s.PublisherService.ReloadPublishers(&cfg.Publisher),
+		SchedulerEnabled: cfg.Scheduler.Enabled,
+		SyncInterval:     cfg.Scheduler.SyncInterval.String(),
+	}
+	s.Scheduler.Reconfigure(&cfg.Scheduler)
+
+	s.Logger.Info("Configuration reloaded", zap.String("path", s.ConfigPath))
+	return report, nil
+}
+
+func (s *Server) handleReloadConfig(c *gin.Context) {
+	report, err := s.Reload()
+	if err != nil {
+		s.Logger.Error("Failed to reload configuration", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	result, err := s.PublisherService.SavePageToDraft(c.Request.Context(), pageID, platform)
+	c.JSON(http.StatusOK, gin.H{"message": "Configuration reloaded", "report": report})
+}
+
+func (s *Server) handleBackup(c *gin.Context) {
+	// Built in memory first so a failure is still reported as an error response
+	var archive bytes.Buffer
+	report, err := s.PublisherService.Backup(c.Request.Context(), &archive)
 	if err != nil {
-		s.Logger.Error("Failed to save page to draft",
-			zap.String("page_id", pageID),
-			zap.String("platform", platform),
-			zap.Error(err))
+		s.Logger.Error("Failed to create backup", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Page saved to draft successfully",
-		"result":  result,
-	})
+	filename := fmt.Sprintf("ripple-backup-%s.json.gz", report.CreatedAt.Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/gzip", archive.Bytes())
 }
 
-func (s *Server) handleGetPublishHistory(c *gin.Context) {
-	pageID := c.Param("pageId")
-	if pageID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Page ID is required"})
-		return
+func (s *Server) handleRestore(c *gin.Context) {
+	// The archive is sent as the request body or as a "file" form upload
+	archive := c.Request.Body
+	if file, err := c.FormFile("file"); err == nil {
+		upload, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer upload.Close()
+		archive = upload
 	}
 
-	history, err := s.PublisherService.GetPublishHistory(c.Request.Context(), pageID)
+	options := service.RestoreOptions{Force: c.Query("force") == "true"}
+	report, err := s.PublisherService.Restore(c.Request.Context(), archive, options)
 	if err != nil {
-		s.Logger.Error("Failed to get publish history", zap.String("page_id", pageID), zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, service.ErrRestoreNotEmpty):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidBackup), errors.Is(err, service.ErrBackupPassphrase),
+			errors.Is(err, service.ErrBackupVersion):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			s.Logger.Error("Failed to restore backup", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"history": history})
+	c.JSON(http.StatusOK, gin.H{"message": "Backup restored", "report": report})
 }
 
-func (s *Server) handleProcessPendingPages(c *gin.Context) {
-	err := s.PublisherService.ProcessPendingPages(c.Request.Context())
-	if err != nil {
-		s.Logger.Error("Failed to process pending pages", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+func (s *Server) handleCleanup(c *gin.Context) {
+	report := s.RetentionCleaner.Run(c.Request.Context())
+	if len(report.Errors) > 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": strings.Join(report.Errors, "; "), "report": report})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Pending pages processed successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "Cleanup completed", "report": report})
+}
+
+func (s *Server) handleGetCleanupReport(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"report": s.RetentionCleaner.LastReport()})
+}
+
+// dashboardFS returns the dashboard build embedded in the binary, or the configured directory
+// during frontend development
+func (s *Server) dashboardFS() fs.FS {
+	if dir := s.Config.Server.WebDir; dir != "" {
+		s.Logger.Info("Serving dashboard from directory", zap.String("dir", dir))
+		return os.DirFS(dir)
+	}
+	return web.Dist()
+}
+
+// serveDashboard serves the index.html of the single page dashboard, which routes on the client.
+// It is read on every request, so a rebuild in the web directory shows up without a restart.
+func (s *Server) serveDashboard(dashboard fs.FS) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		index, err := fs.ReadFile(dashboard, "index.html")
+		if err != nil {
+			c.String(http.StatusNotFound, "Dashboard not built, run make web-build before building the binary or set WEB_DIR")
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", index)
+	}
 }
 
 func (s *Server) Start(ctx context.Context) error {
 	// Start stats updater
 	s.StatsUpdater.Start(ctx)
 
+	// Start credential monitor
+	s.CredentialMonitor.Start(ctx)
+
+	// Start deployment tracker
+	s.DeploymentTracker.Start(ctx)
+	s.MetricsCollector.Start(ctx)
+	s.RetentionCleaner.Start(ctx)
+	s.PublishWorkers.Start(ctx)
+
 	// Start scheduler
 	if err := s.Scheduler.Start(ctx); err != nil {
 		return fmt.Errorf("failed to start scheduler: %w", err)
@@ -343,9 +1647,24 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	// Stop stats updater first
 	s.StatsUpdater.Stop()
 
+	// Stop credential monitor
+	s.CredentialMonitor.Stop()
+
+	// Stop deployment tracker
+	s.DeploymentTracker.Stop()
+	s.MetricsCollector.Stop()
+	s.RetentionCleaner.Stop()
+
 	// Stop scheduler
 	s.Scheduler.Stop()
 
+	// Let the publish workers finish the publishes in progress
+	s.PublishWorkers.Stop()
+	s.TaskQueue.Close()
+
+	// End event streams, which would otherwise keep the server from shutting down
+	events.Default.Close()
+
 	if s.Server == nil {
 		return nil
 	}
@@ -356,6 +1675,41 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	return s.Server.Shutdown(shutdownCtx)
 }
 
+// handleEvents streams job status changes, sync progress and new errors as server-sent events
+func (s *Server) handleEvents(c *gin.Context) {
+	stream, unsubscribe := events.Default.Subscribe()
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Keep nginx from buffering the stream
+
+	// Send the headers right away, so the browser sees the stream open before the first event
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	// Comments keep idle connections open through proxies
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-stream:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event)
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
 // Dashboard handlers
 
 func (s *Server) handleGetDashboardSummary(c *gin.Context) {
@@ -386,6 +1740,43 @@ func (s *Server) handleGetPlatformStats(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"stats": stats})
 }
 
+// handleGetTrends returns a metric of the distribution jobs per hour, day or week between from
+// and to, given as RFC 3339 times
+func (s *Server) handleGetTrends(c *gin.Context) {
+	query := service.TrendQuery{
+		Bucket:   c.DefaultQuery("bucket", service.TrendBucketDay),
+		Metric:   c.DefaultQuery("metric", service.TrendVolume),
+		Platform: c.Query("platform"),
+	}
+	for name, target := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s must be an RFC 3339 time", name)})
+			return
+		}
+		*target = t
+	}
+
+	trend, err := s.MonitoringService.GetTrends(query)
+	switch {
+	case errors.Is(err, service.ErrInvalidTrendQuery):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		s.Logger.Error("Failed to get trends", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get trends"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"trend": trend})
+}
+
+// handleGetRecentErrors returns the error issues seen most recently, each grouping the
+// occurrences of one error; status is unresolved (default), resolved or all
 func (s *Server) handleGetRecentErrors(c *gin.Context) {
 	limitParam := c.DefaultQuery("limit", "20")
 	limit := 20
@@ -393,14 +1784,71 @@ func (s *Server) handleGetRecentErrors(c *gin.Context) {
 		limit = l
 	}
 
-	errors, err := s.MonitoringService.GetRecentErrors(limit)
+	status := c.DefaultQuery("status", service.IssuesUnresolved)
+	switch status {
+	case service.IssuesUnresolved, service.IssuesResolved, service.IssuesAll:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be unresolved, resolved or all"})
+		return
+	}
+
+	issues, err := s.MonitoringService.GetErrorIssues(status, limit)
 	if err != nil {
 		s.Logger.Error("Failed to get recent errors", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get recent errors"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"errors": errors})
+	c.JSON(http.StatusOK, gin.H{"issues": issues})
+}
+
+// handleGetErrorIssue returns an error issue with its latest occurrences
+func (s *Server) handleGetErrorIssue(c *gin.Context) {
+	issueID, err := strconv.ParseUint(c.Param("issueId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid issue ID"})
+		return
+	}
+
+	limit := 20
+	if l, err := strconv.Atoi(c.DefaultQuery("limit", "20")); err == nil && l > 0 {
+		limit = l
+	}
+
+	issue, occurrences, err := s.MonitoringService.GetErrorIssue(uint(issueID), limit)
+	switch {
+	case errors.Is(err, service.ErrIssueNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Issue not found"})
+		return
+	case err != nil:
+		s.Logger.Error("Failed to get error issue", zap.Uint64("issue_id", issueID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get error issue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"issue": issue, "errors": occurrences})
+}
+
+// handleResolveErrorIssue resolves an error issue and all its occurrences
+func (s *Server) handleResolveErrorIssue(c *gin.Context) {
+	issueID, err := strconv.ParseUint(c.Param("issueId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid issue ID"})
+		return
+	}
+
+	err = s.MonitoringService.ResolveErrorIssue(uint(issueID))
+	switch {
+	case errors.Is(err, service.ErrIssueNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Issue not found"})
+		return
+	case err != nil:
+		s.Logger.Error("Failed to resolve error issue", zap.Uint64("issue_id", issueID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve error issue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Issue resolved successfully"})
 }
 
 func (s *Server) handleGetSystemStats(c *gin.Context) {
@@ -478,15 +1926,29 @@ func (s *Server) handleGetRecentPages(c *gin.Context) {
 		limit = l
 	}
 
+	fields := parseFieldsParam(c.Query("fields"))
+	columns, err := s.NotionService.PageColumns(fields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var pages []models.NotionPage
-	err := s.DB.Order("updated_at desc").Limit(limit).Find(&pages).Error
+	err = s.DB.Select(columns).Order("updated_at desc").Limit(limit).Find(&pages).Error
 	if err != nil {
 		s.Logger.Error("Failed to get recent pages", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get recent pages"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"pages": pages})
+	result, err := selectFields(pages, fields)
+	if err != nil {
+		s.Logger.Error("Failed to select page fields", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get recent pages"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pages": result})
 }
 
 func (s *Server) handleGetRecentJobs(c *gin.Context) {
@@ -524,20 +1986,45 @@ func (s *Server) handleGetJobs(c *gin.Context) {
 	}
 
 	status := c.Query("status") // pending, completed, failed
+	publishID := c.Query("publish_id")
+	errorCode := c.Query("error_code") // AUTH_EXPIRED, RATE_LIMITED, CONTENT_INVALID, NETWORK, PLATFORM_5XX
+
+	// Metadata filters use key:value pairs, e.g. ?metadata=commit_hash:abc123&metadata=media_id:xyz
+	metadataFilter := models.JSONMap{}
+	for _, pair := range c.QueryArray("metadata") {
+		key, value, ok := strings.Cut(pair, ":")
+		if !ok || key == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid metadata filter %q, expected key:value", pair)})
+			return
+		}
+		metadataFilter[key] = value
+	}
 
-	query := s.DB.Preload("Page").Preload("Platform")
-	if status != "" {
-		query = query.Where("status = ?", status)
+	applyFilters := func(q *gorm.DB) *gorm.DB {
+		if status != "" {
+			q = q.Where("status = ?", status)
+		}
+		if publishID != "" {
+			q = q.Where("publish_id = ?", publishID)
+		}
+		if errorCode != "" {
+			q = q.Where("error_code = ?", errorCode)
+		}
+		if len(metadataFilter) > 0 {
+			// jsonb containment uses the GIN index on metadata
+			filterJSON, _ := metadataFilter.Value()
+			q = q.Where("metadata @> ?::jsonb", filterJSON)
+		}
+		return q
 	}
 
+	query := applyFilters(s.DB.Preload("Page").Preload("Platform"))
+
 	var jobs []models.DistributionJob
 	var total int64
 
 	// Get total count
-	countQuery := s.DB.Model(&models.DistributionJob{})
-	if status != "" {
-		countQuery = countQuery.Where("status = ?", status)
-	}
+	countQuery := applyFilters(s.DB.Model(&models.DistributionJob{}))
 	countQuery.Count(&total)
 
 	err := query.Order("updated_at desc").
@@ -558,86 +2045,263 @@ func (s *Server) handleGetJobs(c *gin.Context) {
 	})
 }
 
-func (s *Server) handleRepublishJob(c *gin.Context) {
-	jobIDParam := c.Param("jobId")
-	jobID, err := strconv.ParseUint(jobIDParam, 10, 32)
+func (s *Server) handleGetJobHistory(c *gin.Context) {
+	jobID, err := strconv.ParseUint(c.Param("jobId"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
 		return
 	}
 
-	var job models.DistributionJob
-	err = s.DB.Preload("Page").Preload("Platform").First(&job, uint(jobID)).Error
+	transitions, err := s.PublisherService.GetJobTransitions(uint(jobID))
+	if err != nil {
+		s.Logger.Error("Failed to get job history", zap.Uint64("job_id", jobID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"transitions": transitions})
+}
+
+func (s *Server) handleGetPageMetrics(c *gin.Context) {
+	pageID := c.Param("pageId")
+
+	metrics, err := s.MonitoringService.GetPageMetrics(pageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Page not found"})
+			return
+		}
+		s.Logger.Error("Failed to get page metrics", zap.String("page_id", pageID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get page metrics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"metrics": metrics})
+}
+
+func (s *Server) handleGetJobLogs(c *gin.Context) {
+	jobID, err := strconv.ParseUint(c.Param("jobId"), 10, 32)
 	if err != nil {
-		s.Logger.Error("Failed to find job", zap.Uint64("job_id", jobID), zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	afterID, _ := strconv.ParseUint(c.DefaultQuery("after", "0"), 10, 32)
+	follow := c.Query("follow") == "true"
+
+	// Subscribe before reading the stored lines, so no line falls in between
+	var stream <-chan events.Event
+	if follow {
+		var unsubscribe func()
+		stream, unsubscribe = events.Default.Subscribe()
+		defer unsubscribe()
+	}
+
+	status, logs, err := s.PublisherService.GetJobLogs(uint(jobID), uint(afterID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
 		return
 	}
+	if err != nil {
+		s.Logger.Error("Failed to get job logs", zap.Uint64("job_id", jobID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job logs"})
+		return
+	}
 
-	if job.Page.NotionID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Job has no associated page"})
+	if !follow {
+		c.JSON(http.StatusOK, gin.H{"job_id": jobID, "status": status, "logs": logs})
 		return
 	}
 
-	if job.Platform.Name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Job has no associated platform"})
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	lastID := uint(afterID)
+	for _, line := range logs {
+		c.SSEvent("log", line)
+		lastID = line.ID
+	}
+	c.Writer.Flush()
+
+	// Jobs that are not running get no more lines
+	if status != models.JobInProgress && status != models.JobPending {
+		c.SSEvent("end", gin.H{"status": status})
 		return
 	}
 
-	s.Logger.Info("Manually republishing job",
-		zap.Uint64("job_id", jobID),
-		zap.String("page_id", job.Page.NotionID),
-		zap.String("platform", job.Platform.Name),
-		zap.String("original_status", job.Status))
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-stream:
+			if !ok {
+				return false
+			}
+			switch data := event.Data.(type) {
+			case models.JobLog:
+				if data.JobID == uint(jobID) && data.ID > lastID {
+					c.SSEvent("log", data)
+					lastID = data.ID
+				}
+			case events.JobStatusEvent:
+				if data.JobID == uint(jobID) && data.To != models.JobInProgress {
+					// Send the lines logged while the job was finishing
+					if _, remaining, err := s.PublisherService.GetJobLogs(uint(jobID), lastID); err == nil {
+						for _, line := range remaining {
+							c.SSEvent("log", line)
+						}
+					}
+					c.SSEvent("end", gin.H{"status": data.To})
+					return false
+				}
+			}
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
 
-	// Mark the existing job as "republish_requested" to trigger a new job creation
-	// This bypasses the "already completed" check in the publisher
-	originalStatus := job.Status
-	job.Status = "republish_requested"
-	job.Error = "" // Clear any previous error
-	if err := s.DB.Save(&job).Error; err != nil {
-		s.Logger.Error("Failed to update job status for republish",
-			zap.Uint64("job_id", jobID),
-			zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare job for republish"})
+// handleGetCalendar returns the publications per day and platform for the months from and to,
+// given as YYYY-MM and defaulting to the current month, in the time zone tz
+func (s *Server) handleGetCalendar(c *gin.Context) {
+	loc := time.Local
+	if tz := c.Query("tz"); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid time zone %q", tz)})
+			return
+		}
+	}
+
+	currentMonth := time.Now().In(loc).Format("2006-01")
+	fromMonth, err := time.ParseInLocation("2006-01", c.DefaultQuery("from", currentMonth), loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a month like 2006-01"})
+		return
+	}
+	toMonth, err := time.ParseInLocation("2006-01", c.DefaultQuery("to", fromMonth.Format("2006-01")), loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a month like 2006-01"})
+		return
+	}
+	if toMonth.Before(fromMonth) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return
+	}
+	if toMonth.After(fromMonth.AddDate(0, maxCalendarMonths-1, 0)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d months can be requested", maxCalendarMonths)})
+		return
+	}
+
+	days, err := s.PublisherService.GetCalendar(fromMonth, toMonth.AddDate(0, 1, 0), loc)
+	if err != nil {
+		s.Logger.Error("Failed to get publishing calendar", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get publishing calendar"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":     fromMonth.Format("2006-01"),
+		"to":       toMonth.Format("2006-01"),
+		"timezone": loc.String(),
+		"days":     days,
+	})
+}
+
+func (s *Server) handleRepublishJob(c *gin.Context) {
+	if !s.allowPublish(c) {
 		return
 	}
 
-	s.Logger.Info("Job status updated for republish",
-		zap.Uint64("job_id", jobID),
-		zap.String("old_status", originalStatus),
-		zap.String("new_status", job.Status))
+	jobIDParam := c.Param("jobId")
+	jobID, err := strconv.ParseUint(jobIDParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+	// Unchanged content is only published again when forced
+	force := c.Query("force") == "true"
 
-	// Trigger immediate processing of pending pages to execute the republish
-	s.Logger.Info("Triggering immediate processing of pending pages for republish")
-	err = s.PublisherService.ProcessPendingPages(c.Request.Context())
+	report, err := s.PublisherService.RepublishJob(c.Request.Context(), uint(jobID), force)
 	if err != nil {
-		s.Logger.Error("Failed to process pending pages for republish",
-			zap.Uint64("job_id", jobID),
-			zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to process republish: %v", err)})
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		case errors.Is(err, service.ErrJobNotRepublishable), errors.Is(err, service.ErrPageArchived):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, models.ErrInvalidJobTransition):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			s.Logger.Error("Failed to republish job", zap.Uint64("job_id", jobID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to process republish: %v", err)})
+		}
+		return
+	}
+
+	message := "Job republished successfully"
+	switch report.Action {
+	case service.RerunActionUnchanged:
+		message = "Content unchanged since the last publish, republish skipped"
+	case service.RerunActionFailed:
+		message = "Job republish failed"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         message,
+		"action":          report.Action,
+		"content_changed": report.ContentChanged,
+		"job": map[string]interface{}{
+			"id":           report.Job.ID,
+			"status":       report.Job.Status,
+			"error":        report.Job.Error,
+			"published_at": report.Job.PublishedAt,
+		},
+		"result": report.Result,
+	})
+}
+
+func (s *Server) handleRefreshJobMedia(c *gin.Context) {
+	if !s.allowPublish(c) {
 		return
 	}
 
-	// Check the job status after processing
-	var updatedJob models.DistributionJob
-	if err := s.DB.Preload("Page").Preload("Platform").First(&updatedJob, jobID).Error; err != nil {
-		s.Logger.Error("Failed to get updated job status", zap.Uint64("job_id", jobID), zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get updated job status"})
+	jobID, err := strconv.ParseUint(c.Param("jobId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
 		return
 	}
 
-	s.Logger.Info("Republish processing completed",
-		zap.Uint64("job_id", jobID),
-		zap.String("final_status", updatedJob.Status))
+	job, refresh, err := s.PublisherService.RefreshJobMedia(c.Request.Context(), uint(jobID))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		case errors.Is(err, service.ErrJobNotRefreshable), errors.Is(err, publisher.ErrMediaRefreshNotSupported):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			s.Logger.Error("Failed to refresh job media", zap.Uint64("job_id", jobID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to refresh media: %v", err)})
+		}
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Job republished successfully",
+		"message":   fmt.Sprintf("Refreshed %d images", refresh.Refreshed),
+		"refreshed": refresh.Refreshed,
+		"failed":    refresh.Failed,
 		"job": map[string]interface{}{
-			"id":           updatedJob.ID,
-			"status":       updatedJob.Status,
-			"error":        updatedJob.Error,
-			"published_at": updatedJob.PublishedAt,
+			"id":         job.ID,
+			"status":     job.Status,
+			"publish_id": job.PublishID,
+			"url":        job.URL,
 		},
 	})
 }
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+// ErrPageArchived is returned when publishing a page archived or deleted in Notion
+var ErrPageArchived = errors.New("page is archived")
+
+// PurgeReport lists the archived pages removed by a purge and the rows removed with them
+type PurgeReport struct {
+	ArchivedBefore time.Time      `json:"archived_before"`
+	Pages          []string       `json:"pages"`
+	Deleted        map[string]int `json:"deleted"`
+}
+
+// PurgeArchivedPages permanently deletes the pages archived longer than retention ago, with their
+// jobs, job history and logs, content versions, post metrics and error logs. A zero retention
+// uses the configured retention period.
+func (s *PublisherService) PurgeArchivedPages(ctx context.Context, retention time.Duration) (*PurgeReport, error) {
+	if retention <= 0 {
+		retention = s.config.Notion.ArchivedRetention
+	}
+
+	report := &PurgeReport{
+		ArchivedBefore: time.Now().Add(-retention),
+		Pages:          []string{},
+		Deleted:        make(map[string]int),
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var pages []models.NotionPage
+		if err := tx.Unscoped().Select("id", "notion_id").
+			Where("archived_at IS NOT NULL AND archived_at < ?", report.ArchivedBefore).
+			Find(&pages).Error; err != nil {
+			return fmt.Errorf("failed to get archived pages: %w", err)
+		}
+		if len(pages) == 0 {
+			return nil
+		}
+
+		pageIDs := make([]uint, len(pages))
+		for i, page := range pages {
+			pageIDs[i] = page.ID
+			report.Pages = append(report.Pages, page.NotionID)
+		}
+
+		var jobIDs []uint
+		if err := tx.Unscoped().Model(&models.DistributionJob{}).
+			Where("page_id IN ?", pageIDs).
+			Pluck("id", &jobIDs).Error; err != nil {
+			return fmt.Errorf("failed to get jobs of archived pages: %w", err)
+		}
+
+		// Rows pointing at the jobs and pages go first
+		steps := []struct {
+			name  string
+			model interface{}
+			query string
+			args  interface{}
+		}{
+			{"job_transitions", &models.JobTransition{}, "job_id IN ?", jobIDs},
+			{"job_logs", &models.JobLog{}, "job_id IN ?", jobIDs},
+			{"content_versions", &models.ContentVersion{}, "page_id IN ?", pageIDs},
+			{"post_metrics", &models.PostMetric{}, "page_id IN ?", pageIDs},
+			{"error_logs", &models.ErrorLog{}, "page_id IN ?", pageIDs},
+			{"error_logs", &models.ErrorLog{}, "job_id IN ?", jobIDs},
+			{"jobs", &models.DistributionJob{}, "page_id IN ?", pageIDs},
+			{"pages", &models.NotionPage{}, "id IN ?", pageIDs},
+		}
+		for _, step := range steps {
+			if ids, ok := step.args.([]uint); ok && len(ids) == 0 {
+				continue
+			}
+			result := tx.Unscoped().Where(step.query, step.args).Delete(step.model)
+			if result.Error != nil {
+				return fmt.Errorf("failed to delete %s: %w", step.name, result.Error)
+			}
+			report.Deleted[step.name] += int(result.RowsAffected)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Purged archived pages",
+		zap.Time("archived_before", report.ArchivedBefore),
+		zap.Int("pages", len(report.Pages)),
+		zap.Any("deleted", report.Deleted))
+	return report, nil
+}
@@ -59,12 +59,14 @@ func (a *AuthService) ValidateToken(token string) bool {
 
 func (a *AuthService) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Skip auth for login page, static assets, and API auth endpoints
+		// Skip auth for login page, static assets, health probes, and API auth endpoints
 		if c.Request.URL.Path == "/login" || 
 		   c.Request.URL.Path == "/api/v1/auth/login" ||
 		   c.Request.URL.Path == "/api/v1/auth/setup" ||
 		   c.Request.URL.Path == "/favicon.ico" ||
-		   strings.HasPrefix(c.Request.URL.Path, "/assets/") {
+		   strings.HasPrefix(c.Request.URL.Path, "/assets/") ||
+		   strings.HasPrefix(c.Request.URL.Path, CardRoutePrefix+"/") ||
+		   strings.HasPrefix(c.Request.URL.Path, "/health") {
 			c.Next()
 			return
 		}
@@ -0,0 +1,461 @@
+package service
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// BackupVersion is the format version of backup archives written by this build
+const BackupVersion = 1
+
+const (
+	backupKDFIterations = 600000
+	// encryptedPrefix marks an encrypted value in the platform configs of a backup
+	encryptedPrefix = "enc:"
+)
+
+var (
+	// ErrRestoreNotEmpty is returned when restoring into a database that already has pages,
+	// jobs or platforms without force
+	ErrRestoreNotEmpty = errors.New("database is not empty, restore with force to replace its contents")
+	// ErrBackupPassphrase is returned when the configured passphrase can't decrypt a backup
+	ErrBackupPassphrase = errors.New("backup passphrase does not match")
+	// ErrInvalidBackup is returned for files that aren't backup archives
+	ErrInvalidBackup = errors.New("invalid backup archive")
+	// ErrBackupVersion is returned for archives written by a newer Ripple
+	ErrBackupVersion = errors.New("unsupported backup version")
+)
+
+// secretConfigKeys are the platform config keys whose values are encrypted in backups
+var secretConfigKeys = []string{"secret", "token", "cookie", "password", "api_key", "session_id", "login_link"}
+
+// Backup is a portable archive of the Ripple state
+type Backup struct {
+	Version    int               `json:"version"`
+	CreatedAt  time.Time         `json:"created_at"`
+	Encryption *BackupEncryption `json:"encryption,omitempty"`
+
+	PlatformConfigs []BackupPlatformConfig `json:"platform_configs"`
+
+	Platforms       []models.Platform        `json:"platforms"`
+	Pages           []models.NotionPage      `json:"pages"`
+	Jobs            []models.DistributionJob `json:"jobs"`
+	JobTransitions  []models.JobTransition   `json:"job_transitions"`
+	JobLogs         []models.JobLog          `json:"job_logs"`
+	ContentVersions []models.ContentVersion  `json:"content_versions"`
+	MediaAssets     []models.MediaAsset      `json:"media_assets"`
+	PostSlugs       []models.PostSlug        `json:"post_slugs"`
+	PublishBatches  []models.PublishBatch    `json:"publish_batches"`
+	SyncRuns        []models.SyncRun         `json:"sync_runs"`
+
+	SystemStats        []models.SystemStats      `json:"system_stats"`
+	PlatformStats      []models.PlatformStats    `json:"platform_stats"`
+	ErrorIssues        []models.ErrorIssue       `json:"error_issues"`
+	ErrorLogs          []models.ErrorLog         `json:"error_logs"`
+	MetricsSamples     []models.MetricsSample    `json:"metrics_samples"`
+	DashboardSummaries []models.DashboardSummary `json:"dashboard_summaries"`
+	PostMetrics        []models.PostMetric       `json:"post_metrics"`
+}
+
+// BackupEncryption describes how the secrets of a backup are encrypted
+type BackupEncryption struct {
+	Algorithm  string `json:"algorithm"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       string `json:"salt"`
+}
+
+// BackupPlatformConfig is the config a platform was registered with, with its secrets
+// encrypted, or left out when no passphrase is configured
+type BackupPlatformConfig struct {
+	Platform string            `json:"platform"`
+	Enabled  bool              `json:"enabled"`
+	Config   map[string]string `json:"config"`
+}
+
+// BackupReport lists what a backup contains
+type BackupReport struct {
+	CreatedAt        time.Time      `json:"created_at"`
+	Tables           map[string]int `json:"tables"`
+	Platforms        []string       `json:"platforms"`
+	SecretsEncrypted bool           `json:"secrets_encrypted"`
+}
+
+// RestoreOptions controls a restore
+type RestoreOptions struct {
+	// Force replaces the pages, jobs and platforms already in the database
+	Force bool
+}
+
+// RestoreReport lists what a restore wrote
+type RestoreReport struct {
+	BackupCreatedAt  time.Time      `json:"backup_created_at"`
+	Tables           map[string]int `json:"tables"`
+	Platforms        []string       `json:"platforms"`
+	SecretsDecrypted bool           `json:"secrets_decrypted"`
+	// PlatformConfigs are the platform configs of the backup, decrypted when possible. They
+	// are not applied, the configuration file and environment still define the platforms.
+	PlatformConfigs []BackupPlatformConfig `json:"-"`
+}
+
+// Backup writes a gzipped JSON archive of the pages, jobs, platforms and monitoring data, and of
+// the platform configs with their secrets encrypted with the backup passphrase. The data is
+// read in a single transaction so the archive is consistent.
+func (s *PublisherService) Backup(ctx context.Context, w io.Writer) (*BackupReport, error) {
+	backup := &Backup{
+		Version:   BackupVersion,
+		CreatedAt: time.Now(),
+	}
+
+	key, err := s.backupKey(backup)
+	if err != nil {
+		return nil, err
+	}
+	backup.PlatformConfigs, err = sealPlatformConfigs(s.manager.PlatformConfigs(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Soft-deleted rows are kept, jobs and logs may still point at them
+		query := tx.Unscoped().Order("id").Session(&gorm.Session{})
+		for _, rows := range backup.tables() {
+			if err := query.Find(rows.dest).Error; err != nil {
+				return fmt.Errorf("failed to read %s: %w", rows.name, err)
+			}
+		}
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(w)
+	if err := json.NewEncoder(gz).Encode(backup); err != nil {
+		return nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	report := &BackupReport{
+		CreatedAt:        backup.CreatedAt,
+		Tables:           backup.counts(),
+		Platforms:        backup.platformNames(),
+		SecretsEncrypted: key != nil,
+	}
+	s.logger.Info("Backup created",
+		zap.Any("tables", report.Tables),
+		zap.Bool("secrets_encrypted", report.SecretsEncrypted))
+	return report, nil
+}
+
+// Restore reads an archive written by Backup into the database, keeping the IDs so jobs,
+// logs and metrics still point at their pages. Restoring into a database with pages, jobs or
+// platforms needs force, which replaces them; everything is restored in one transaction.
+func (s *PublisherService) Restore(ctx context.Context, r io.Reader, options RestoreOptions) (*RestoreReport, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	defer gz.Close()
+
+	var backup Backup
+	if err := json.NewDecoder(gz).Decode(&backup); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	if backup.Version > BackupVersion {
+		return nil, fmt.Errorf("%w: %d", ErrBackupVersion, backup.Version)
+	}
+
+	report := &RestoreReport{
+		BackupCreatedAt: backup.CreatedAt,
+		Tables:          backup.counts(),
+		Platforms:       backup.platformNames(),
+		PlatformConfigs: backup.PlatformConfigs,
+	}
+
+	// The passphrase is checked before anything is written
+	if backup.Encryption != nil && s.config.Backup.Passphrase != "" {
+		key, err := deriveBackupKey(s.config.Backup.Passphrase, backup.Encryption)
+		if err != nil {
+			return nil, err
+		}
+		if report.PlatformConfigs, err = openPlatformConfigs(backup.PlatformConfigs, key); err != nil {
+			return nil, err
+		}
+		report.SecretsDecrypted = true
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if !options.Force {
+			for _, model := range []interface{}{&models.NotionPage{}, &models.DistributionJob{}, &models.Platform{}} {
+				var count int64
+				if err := tx.Unscoped().Model(model).Count(&count).Error; err != nil {
+					return err
+				}
+				if count > 0 {
+					return ErrRestoreNotEmpty
+				}
+			}
+		}
+
+		tables := backup.tables()
+		names := make([]string, 0, len(tables))
+		for _, rows := range tables {
+			table, err := tableName(tx, rows.dest)
+			if err != nil {
+				return err
+			}
+			names = append(names, table)
+		}
+		// Monitoring data written since the server started is replaced as well
+		if err := tx.Exec("TRUNCATE TABLE " + strings.Join(names, ", ")).Error; err != nil {
+			return fmt.Errorf("failed to clear tables: %w", err)
+		}
+
+		backup.fillEmptyJSON()
+		for i, rows := range tables {
+			if rows.count == 0 {
+				continue
+			}
+			if err := tx.Omit(clause.Associations).CreateInBatches(rows.dest, 200).Error; err != nil {
+				return fmt.Errorf("failed to restore %s: %w", rows.name, err)
+			}
+			// Rows created after the restore continue after the restored IDs
+			if err := tx.Exec(fmt.Sprintf(
+				"SELECT setval(pg_get_serial_sequence('%[1]s', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM %[1]s",
+				names[i])).Error; err != nil {
+				return fmt.Errorf("failed to reset the %s sequence: %w", rows.name, err)
+			}
+		}
+
+		// Backups taken before errors were grouped into issues have their error logs grouped now
+		return backfillErrorIssues(tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Backup restored",
+		zap.Time("backup_created_at", backup.CreatedAt),
+		zap.Any("tables", report.Tables),
+		zap.Bool("secrets_decrypted", report.SecretsDecrypted))
+	return report, nil
+}
+
+// backupTable is a table of a backup and the slice holding its rows
+type backupTable struct {
+	name  string
+	dest  interface{}
+	count int
+}
+
+// tables lists the tables of a backup in restore order, parents before the rows pointing at them
+func (b *Backup) tables() []backupTable {
+	return []backupTable{
+		{"platforms", &b.Platforms, len(b.Platforms)},
+		{"pages", &b.Pages, len(b.Pages)},
+		{"jobs", &b.Jobs, len(b.Jobs)},
+		{"job_transitions", &b.JobTransitions, len(b.JobTransitions)},
+		{"job_logs", &b.JobLogs, len(b.JobLogs)},
+		{"content_versions", &b.ContentVersions, len(b.ContentVersions)},
+		{"media_assets", &b.MediaAssets, len(b.MediaAssets)},
+		{"post_slugs", &b.PostSlugs, len(b.PostSlugs)},
+		{"publish_batches", &b.PublishBatches, len(b.PublishBatches)},
+		{"sync_runs", &b.SyncRuns, len(b.SyncRuns)},
+		{"system_stats", &b.SystemStats, len(b.SystemStats)},
+		{"platform_stats", &b.PlatformStats, len(b.PlatformStats)},
+		{"error_issues", &b.ErrorIssues, len(b.ErrorIssues)},
+		{"error_logs", &b.ErrorLogs, len(b.ErrorLogs)},
+		{"metrics_samples", &b.MetricsSamples, len(b.MetricsSamples)},
+		{"dashboard_summaries", &b.DashboardSummaries, len(b.DashboardSummaries)},
+		{"post_metrics", &b.PostMetrics, len(b.PostMetrics)},
+	}
+}
+
+func (b *Backup) counts() map[string]int {
+	counts := make(map[string]int)
+	for _, rows := range b.tables() {
+		counts[rows.name] = rows.count
+	}
+	return counts
+}
+
+func (b *Backup) platformNames() []string {
+	names := make([]string, 0, len(b.PlatformConfigs))
+	for _, config := range b.PlatformConfigs {
+		names = append(names, config.Platform)
+	}
+	return names
+}
+
+// fillEmptyJSON replaces empty jsonb columns, which postgres rejects, with an empty object
+func (b *Backup) fillEmptyJSON() {
+	for i := range b.Platforms {
+		if b.Platforms[i].Config == "" {
+			b.Platforms[i].Config = "{}"
+		}
+	}
+	for i := range b.ContentVersions {
+		if b.ContentVersions[i].Properties == "" {
+			b.ContentVersions[i].Properties = "{}"
+		}
+	}
+	for i := range b.ErrorLogs {
+		if b.ErrorLogs[i].Context == "" {
+			b.ErrorLogs[i].Context = "{}"
+		}
+	}
+	for i := range b.MetricsSamples {
+		if b.MetricsSamples[i].Tags == "" {
+			b.MetricsSamples[i].Tags = "{}"
+		}
+	}
+}
+
+func tableName(db *gorm.DB, dest interface{}) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(dest); err != nil {
+		return "", err
+	}
+	return stmt.Schema.Table, nil
+}
+
+// backupKey derives the key encrypting the secrets of a new backup from the passphrase, or
+// returns nil when none is configured
+func (s *PublisherService) backupKey(backup *Backup) ([]byte, error) {
+	if s.config.Backup.Passphrase == "" {
+		s.logger.Warn("No backup passphrase configured, platform secrets are left out of the backup")
+		return nil, nil
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	backup.Encryption = &BackupEncryption{
+		Algorithm:  "aes-256-gcm",
+		KDF:        "pbkdf2-sha256",
+		Iterations: backupKDFIterations,
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+	}
+	return deriveBackupKey(s.config.Backup.Passphrase, backup.Encryption)
+}
+
+func deriveBackupKey(passphrase string, encryption *BackupEncryption) ([]byte, error) {
+	if encryption.Algorithm != "aes-256-gcm" || encryption.KDF != "pbkdf2-sha256" {
+		return nil, fmt.Errorf("unsupported backup encryption %s/%s", encryption.Algorithm, encryption.KDF)
+	}
+	salt, err := base64.StdEncoding.DecodeString(encryption.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup salt: %w", err)
+	}
+	return pbkdf2.Key(sha256.New, passphrase, salt, encryption.Iterations, 32)
+}
+
+func isSecretConfigKey(key string) bool {
+	for _, secret := range secretConfigKeys {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// sealPlatformConfigs copies the platform configs with their secrets encrypted with key, or
+// left out when key is nil
+func sealPlatformConfigs(configs map[string]publisher.PublishConfig, key []byte) ([]BackupPlatformConfig, error) {
+	var gcm cipher.AEAD
+	if key != nil {
+		var err error
+		if gcm, err = newBackupCipher(key); err != nil {
+			return nil, err
+		}
+	}
+
+	sealed := make([]BackupPlatformConfig, 0, len(configs))
+	for platformName, config := range configs {
+		values := make(map[string]string, len(config.Config))
+		for name, value := range config.Config {
+			if value == "" || !isSecretConfigKey(name) {
+				values[name] = value
+				continue
+			}
+			if gcm == nil {
+				continue
+			}
+
+			nonce := make([]byte, gcm.NonceSize())
+			if _, err := rand.Read(nonce); err != nil {
+				return nil, fmt.Errorf("failed to generate nonce: %w", err)
+			}
+			values[name] = encryptedPrefix + base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(value), []byte(platformName)))
+		}
+		sealed = append(sealed, BackupPlatformConfig{Platform: platformName, Enabled: config.Enabled, Config: values})
+	}
+
+	sort.Slice(sealed, func(i, j int) bool { return sealed[i].Platform < sealed[j].Platform })
+	return sealed, nil
+}
+
+// openPlatformConfigs decrypts the secrets of the platform configs of a backup
+func openPlatformConfigs(configs []BackupPlatformConfig, key []byte) ([]BackupPlatformConfig, error) {
+	gcm, err := newBackupCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	opened := make([]BackupPlatformConfig, 0, len(configs))
+	for _, config := range configs {
+		values := make(map[string]string, len(config.Config))
+		for name, value := range config.Config {
+			encoded, ok := strings.CutPrefix(value, encryptedPrefix)
+			if !ok {
+				values[name] = value
+				continue
+			}
+
+			data, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil || len(data) < gcm.NonceSize() {
+				return nil, fmt.Errorf("invalid encrypted value %s of %s", name, config.Platform)
+			}
+			plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], []byte(config.Platform))
+			if err != nil {
+				return nil, ErrBackupPassphrase
+			}
+			values[name] = string(plain)
+		}
+		opened = append(opened, BackupPlatformConfig{Platform: config.Platform, Enabled: config.Enabled, Config: values})
+	}
+	return opened, nil
+}
+
+func newBackupCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/notion"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// PublishBatchRequest selects the pages of a batch, either by ID or by filter. Platform limits
+// publishing to that platform and, when filtering, selects the pages targeting it.
+type PublishBatchRequest struct {
+	PageIDs  []string
+	Tag      string
+	From     *time.Time
+	To       *time.Time
+	Platform string
+}
+
+// ErrInvalidBatchRequest is wrapped when a batch request selects nothing or names an
+// unavailable platform
+var ErrInvalidBatchRequest = errors.New("invalid batch request")
+
+// CreatePublishBatch records a batch for the selected pages and publishes them in the background.
+// Progress is tracked on the returned batch, see GetPublishBatch.
+func (s *PublisherService) CreatePublishBatch(request PublishBatchRequest) (*models.PublishBatch, error) {
+	if len(request.PageIDs) == 0 && request.Tag == "" && request.From == nil && request.To == nil && request.Platform == "" {
+		return nil, fmt.Errorf("%w: page IDs or at least one filter are required", ErrInvalidBatchRequest)
+	}
+
+	platformName := ""
+	if request.Platform != "" {
+		platformName = s.manager.MapPlatformName(request.Platform)
+		if _, err := s.manager.GetPublisher(platformName); platformName == "" || err != nil {
+			return nil, fmt.Errorf("%w: platform %s is not available", ErrInvalidBatchRequest, request.Platform)
+		}
+	}
+
+	pages, err := s.selectBatchPages(request, platformName)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &models.PublishBatch{
+		Status:   models.BatchPending,
+		Platform: platformName,
+		Filter:   batchFilter(request),
+		Total:    len(pages),
+		Items:    make(models.PublishBatchItems, len(pages)),
+	}
+	for i, page := range pages {
+		batch.Items[i] = models.PublishBatchItem{
+			PageID: page.NotionID,
+			Title:  page.Title,
+			Status: models.BatchItemPending,
+		}
+	}
+
+	if err := s.db.Create(batch).Error; err != nil {
+		return nil, fmt.Errorf("failed to create publish batch: %w", err)
+	}
+
+	s.logger.Info("Publish batch created",
+		zap.Uint("batch_id", batch.ID),
+		zap.Int("pages", batch.Total),
+		zap.String("platform", platformName))
+
+	// The batch outlives the request that created it, and works on its own copy of the items
+	running := *batch
+	running.Items = append(models.PublishBatchItems(nil), batch.Items...)
+	go s.runPublishBatch(context.Background(), running, pages)
+
+	return batch, nil
+}
+
+// GetPublishBatch returns a batch with its progress
+func (s *PublisherService) GetPublishBatch(id uint) (*models.PublishBatch, error) {
+	var batch models.PublishBatch
+	if err := s.db.First(&batch, id).Error; err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// selectBatchPages returns the requested pages, or the pages matching the filter. Pages given
+// by ID that do not exist are kept so they show up as failed items of the batch.
+func (s *PublisherService) selectBatchPages(request PublishBatchRequest, platformName string) ([]models.NotionPage, error) {
+	if len(request.PageIDs) > 0 {
+		pages := make([]models.NotionPage, 0, len(request.PageIDs))
+		for _, pageID := range request.PageIDs {
+			var page models.NotionPage
+			if err := s.db.Where("notion_id = ?", notion.NormalizePageID(pageID)).First(&page).Error; err != nil {
+				page = models.NotionPage{NotionID: pageID}
+			}
+			pages = append(pages, page)
+		}
+		return pages, nil
+	}
+
+	query := s.db.Where("status = ? AND archived_at IS NULL", "Done")
+	if request.Tag != "" {
+		query = query.Where("? = ANY(tags)", request.Tag)
+	}
+	if request.From != nil {
+		query = query.Where("COALESCE(post_date, created_at) >= ?", *request.From)
+	}
+	if request.To != nil {
+		query = query.Where("COALESCE(post_date, created_at) <= ?", *request.To)
+	}
+
+	var candidates []models.NotionPage
+	if err := query.Order("post_date ASC").Find(&candidates).Error; err != nil {
+		return nil, fmt.Errorf("failed to select pages: %w", err)
+	}
+
+	if platformName == "" {
+		return candidates, nil
+	}
+
+	// Pages are matched by the platforms they are routed to, so pages routed by rules or to
+	// followers are selected too
+	var pages []models.NotionPage
+	for _, page := range candidates {
+		if slices.Contains(s.manager.RoutePage(&page).Platforms, platformName) {
+			pages = append(pages, page)
+		}
+	}
+	return pages, nil
+}
+
+// runPublishBatch publishes the pages of a batch one after another, saving the progress after each
+func (s *PublisherService) runPublishBatch(ctx context.Context, batch models.PublishBatch, pages []models.NotionPage) {
+	startedAt := time.Now()
+	batch.Status = models.BatchRunning
+	batch.StartedAt = &startedAt
+	s.saveBatch(&batch)
+
+	for i := range pages {
+		item := &batch.Items[i]
+		s.publishBatchItem(ctx, &pages[i], batch.Platform, item)
+
+		switch item.Status {
+		case models.BatchItemCompleted:
+			batch.Completed++
+		case models.BatchItemSkipped:
+			batch.Skipped++
+		default:
+			batch.Failed++
+		}
+		s.saveBatch(&batch)
+	}
+
+	finishedAt := time.Now()
+	batch.Status = models.BatchCompleted
+	batch.FinishedAt = &finishedAt
+	s.saveBatch(&batch)
+
+	s.logger.Info("Publish batch completed",
+		zap.Uint("batch_id", batch.ID),
+		zap.Int("completed", batch.Completed),
+		zap.Int("failed", batch.Failed),
+		zap.Int("skipped", batch.Skipped),
+		zap.Duration("duration", finishedAt.Sub(startedAt)))
+}
+
+func (s *PublisherService) publishBatchItem(ctx context.Context, page *models.NotionPage, platformName string, item *models.PublishBatchItem) {
+	if page.ID == 0 {
+		item.Status = models.BatchItemFailed
+		item.Error = "page not found"
+		return
+	}
+
+	// Same rule as publishing a single page
+	if page.Status != "Done" {
+		item.Status = models.BatchItemSkipped
+		item.Error = fmt.Sprintf("page status is not 'Done', current status: %s", page.Status)
+		return
+	}
+	if page.IsArchived() {
+		item.Status = models.BatchItemSkipped
+		item.Error = fmt.Sprintf("%s: %s", ErrPageArchived, page.ArchiveReason)
+		return
+	}
+
+	var results map[string]*publisher.PublishResult
+	var err error
+	if platformName != "" {
+		results, err = s.manager.PublishToPlatforms(ctx, page, []string{platformName})
+	} else {
+		results, err = s.manager.PublishToAll(ctx, page)
+	}
+	if err != nil {
+		item.Status = models.BatchItemFailed
+		item.Error = err.Error()
+		return
+	}
+
+	item.Status = models.BatchItemCompleted
+	item.Platforms = make(map[string]string)
+	for name, result := range results {
+		s.recordPublishResult(ctx, page, name, result)
+
+		if result.Success {
+			item.Platforms[name] = "completed"
+			continue
+		}
+		item.Status = models.BatchItemFailed
+		item.Platforms[name] = result.ErrorMsg
+	}
+}
+
+func (s *PublisherService) saveBatch(batch *models.PublishBatch) {
+	if err := s.db.Save(batch).Error; err != nil {
+		s.logger.Error("Failed to save publish batch",
+			zap.Uint("batch_id", batch.ID),
+			zap.Error(err))
+	}
+}
+
+// batchFilter records how the pages of a batch were selected
+func batchFilter(request PublishBatchRequest) models.JSONMap {
+	filter := models.JSONMap{}
+	if request.Tag != "" {
+		filter["tag"] = request.Tag
+	}
+	if request.From != nil {
+		filter["from"] = request.From.Format(time.RFC3339)
+	}
+	if request.To != nil {
+		filter["to"] = request.To.Format(time.RFC3339)
+	}
+	if request.Platform != "" {
+		filter["platform"] = request.Platform
+	}
+	if len(request.PageIDs) > 0 {
+		filter["page_ids"] = fmt.Sprintf("%d pages", len(request.PageIDs))
+	}
+	return filter
+}
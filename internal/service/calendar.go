@@ -0,0 +1,178 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+// Calendar entry kinds: planned pages are due on their post date but not published yet,
+// scheduled posts are waiting on the platform to go out
+const (
+	CalendarPlanned   = "planned"
+	CalendarScheduled = "scheduled"
+	CalendarCompleted = "completed"
+)
+
+// CalendarEntry is a publication of a page on a platform
+type CalendarEntry struct {
+	PageID   uint      `json:"page_id"`
+	NotionID string    `json:"notion_id"`
+	Title    string    `json:"title"`
+	Kind     string    `json:"kind"`
+	JobID    uint      `json:"job_id,omitempty"`
+	At       time.Time `json:"at"`
+}
+
+// CalendarPlatformDay lists the publications of a platform on a day
+type CalendarPlatformDay struct {
+	Planned   []CalendarEntry `json:"planned"`
+	Completed []CalendarEntry `json:"completed"`
+}
+
+// CalendarDay lists the publications of a day by platform
+type CalendarDay struct {
+	Date      string                          `json:"date"`
+	Platforms map[string]*CalendarPlatformDay `json:"platforms"`
+}
+
+// GetCalendar returns the planned and completed publications between from and to, grouped by
+// day in loc. Days without publications are left out.
+func (s *PublisherService) GetCalendar(from, to time.Time, loc *time.Location) ([]CalendarDay, error) {
+	var platforms []models.Platform
+	if err := s.db.Select("id", "name").Find(&platforms).Error; err != nil {
+		return nil, fmt.Errorf("failed to get platforms: %w", err)
+	}
+	platformNames := make(map[uint]string, len(platforms))
+	for _, platform := range platforms {
+		platformNames[platform.ID] = platform.Name
+	}
+
+	days := make(map[string]*CalendarDay)
+	add := func(platformName string, entry CalendarEntry) {
+		date := entry.At.In(loc).Format("2006-01-02")
+		day, ok := days[date]
+		if !ok {
+			day = &CalendarDay{Date: date, Platforms: make(map[string]*CalendarPlatformDay)}
+			days[date] = day
+		}
+		platformDay, ok := day.Platforms[platformName]
+		if !ok {
+			platformDay = &CalendarPlatformDay{Planned: []CalendarEntry{}, Completed: []CalendarEntry{}}
+			day.Platforms[platformName] = platformDay
+		}
+		if entry.Kind == CalendarCompleted {
+			platformDay.Completed = append(platformDay.Completed, entry)
+		} else {
+			platformDay.Planned = append(platformDay.Planned, entry)
+		}
+	}
+
+	// Completed jobs, dated by when they went out or, for posts scheduled on the platform, will
+	var jobs []models.DistributionJob
+	if err := s.db.Select("id", "page_id", "platform_id", "metadata", "published_at").
+		Where("status = ?", models.JobCompleted).
+		Where("(published_at >= ? AND published_at < ?) OR metadata->>'scheduled_at' IS NOT NULL", from, to).
+		Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get jobs: %w", err)
+	}
+
+	pageIDs := make([]uint, 0, len(jobs))
+	for _, job := range jobs {
+		pageIDs = append(pageIDs, job.PageID)
+	}
+	pages, err := s.calendarPages(pageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, job := range jobs {
+		page, ok := pages[job.PageID]
+		if !ok {
+			continue
+		}
+
+		entry := CalendarEntry{PageID: page.ID, NotionID: page.NotionID, Title: page.Title, Kind: CalendarCompleted, JobID: job.ID}
+		if scheduledAt, err := time.Parse(time.RFC3339, job.Metadata["scheduled_at"]); err == nil {
+			entry.At = scheduledAt
+			if scheduledAt.After(now) {
+				entry.Kind = CalendarScheduled
+			}
+		} else if job.PublishedAt != nil {
+			entry.At = *job.PublishedAt
+		}
+		if entry.At.Before(from) || !entry.At.Before(to) {
+			continue
+		}
+		add(platformNames[job.PlatformID], entry)
+	}
+
+	// Pages due in the range on platforms they have not been published to yet
+	var duePages []models.NotionPage
+	if err := s.db.Select("id", "notion_id", "title", "post_date", "platforms").
+		Where("post_date >= ? AND post_date < ?", from, to).
+		Find(&duePages).Error; err != nil {
+		return nil, fmt.Errorf("failed to get pages: %w", err)
+	}
+
+	duePageIDs := make([]uint, 0, len(duePages))
+	for _, page := range duePages {
+		duePageIDs = append(duePageIDs, page.ID)
+	}
+	var publishedJobs []models.DistributionJob
+	if len(duePageIDs) > 0 {
+		if err := s.db.Select("page_id", "platform_id").
+			Where("page_id IN ? AND status = ?", duePageIDs, models.JobCompleted).
+			Find(&publishedJobs).Error; err != nil {
+			return nil, fmt.Errorf("failed to get jobs: %w", err)
+		}
+	}
+	published := make(map[string]bool, len(publishedJobs))
+	for _, job := range publishedJobs {
+		published[fmt.Sprintf("%d/%s", job.PageID, platformNames[job.PlatformID])] = true
+	}
+
+	for _, page := range duePages {
+		for _, platformName := range s.manager.RoutePage(&page).Platforms {
+			if published[fmt.Sprintf("%d/%s", page.ID, platformName)] {
+				continue
+			}
+			add(platformName, CalendarEntry{
+				PageID:   page.ID,
+				NotionID: page.NotionID,
+				Title:    page.Title,
+				Kind:     CalendarPlanned,
+				At:       *page.PostDate,
+			})
+		}
+	}
+
+	calendar := make([]CalendarDay, 0, len(days))
+	for _, day := range days {
+		calendar = append(calendar, *day)
+	}
+	sort.Slice(calendar, func(i, j int) bool {
+		return calendar[i].Date < calendar[j].Date
+	})
+	return calendar, nil
+}
+
+// calendarPages loads the titles of the given pages
+func (s *PublisherService) calendarPages(ids []uint) (map[uint]models.NotionPage, error) {
+	pages := make(map[uint]models.NotionPage)
+	if len(ids) == 0 {
+		return pages, nil
+	}
+
+	var found []models.NotionPage
+	if err := s.db.Select("id", "notion_id", "title").Where("id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, fmt.Errorf("failed to get pages: %w", err)
+	}
+	for _, page := range found {
+		pages[page.ID] = page
+	}
+	return pages, nil
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/internal/service/publisher/announce"
+	"github.com/ifuryst/ripple/internal/service/publisher/microblog"
+	"github.com/ifuryst/ripple/internal/service/publisher/promo"
+)
+
+// linkingPlatforms are the platforms whose posts link to the canonical post of a page rather
+// than carrying the page themselves
+var linkingPlatforms = map[string]bool{
+	microblog.MastodonPlatformName: true,
+	microblog.BlueskyPlatformName:  true,
+	announce.DiscordPlatformName:   true,
+	announce.SlackPlatformName:     true,
+	promo.BufferPlatformName:       true,
+	promo.TypefullyPlatformName:    true,
+}
+
+// canonicalLinkHook gives the posts of linking platforms the URL of the page on the canonical
+// platform as the "canonical_url" metadata. While the page is still queued or being published
+// there, or its deployment isn't live yet, the publish fails and the next sync retries it, so
+// links never point at a missing post. Pages that aren't published there are posted without a
+// link.
+type canonicalLinkHook struct {
+	db       *gorm.DB
+	platform string
+}
+
+func (h *canonicalLinkHook) Name() string {
+	return "canonical_link"
+}
+
+func (h *canonicalLinkHook) Stages() []publisher.HookStage {
+	return []publisher.HookStage{publisher.HookPreTransform}
+}
+
+func (h *canonicalLinkHook) Run(ctx context.Context, event *publisher.HookEvent) (string, error) {
+	if !linkingPlatforms[publisher.PlatformType(event.Platform)] || event.Content.Metadata["canonical_url"] != "" {
+		return "", nil
+	}
+
+	var page models.NotionPage
+	if err := h.db.WithContext(ctx).Select("id").Where("notion_id = ?", event.Content.ID).First(&page).Error; err != nil {
+		return fmt.Sprintf("no canonical link: %v", err), nil
+	}
+
+	var job models.DistributionJob
+	err := h.db.WithContext(ctx).
+		Joins("JOIN platforms ON platforms.id = distribution_jobs.platform_id").
+		Where("platforms.name = ? AND distribution_jobs.page_id = ?", h.platform, page.ID).
+		Order("distribution_jobs.id DESC").
+		First(&job).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", fmt.Errorf("failed to find the %s post: %w", h.platform, err)
+	}
+
+	switch {
+	case err == nil && job.Status == models.JobCompleted:
+		if job.Metadata["deploy_status"] == publisher.DeploymentPending {
+			return "", fmt.Errorf("the %s post of the page is not live yet", h.platform)
+		}
+		link := job.URL
+		if link == "" {
+			link = job.Metadata["live_url"]
+		}
+		if link == "" {
+			link = job.Metadata["url"]
+		}
+		if link == "" {
+			return fmt.Sprintf("the %s post has no URL, posting without a link", h.platform), nil
+		}
+		event.Content.Metadata["canonical_url"] = link
+		return "linked " + link, nil
+	case err == nil && (job.Status == models.JobInProgress || job.Status == models.JobPending):
+		return "", fmt.Errorf("the page is still being published to %s", h.platform)
+	}
+
+	var queued int64
+	if err := h.db.WithContext(ctx).Model(&models.PublishTask{}).
+		Where("page_id = ? AND platform = ?", page.ID, h.platform).
+		Count(&queued).Error; err != nil {
+		return "", fmt.Errorf("failed to check the %s queue: %w", h.platform, err)
+	}
+	if queued > 0 {
+		return "", fmt.Errorf("the page is queued for %s and not published there yet", h.platform)
+	}
+	return fmt.Sprintf("the page is not published to %s, posting without a link", h.platform), nil
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/config"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// Cards use the Open Graph image size, which every platform crops well
+const (
+	cardWidth         = 1200
+	cardHeight        = 630
+	cardRenderTimeout = 30 * time.Second
+	// Note cards use the 3:4 portrait size Xiaohongshu shows without cropping
+	noteCardWidth  = 1080
+	noteCardHeight = 1440
+	// CardRoutePrefix is where the server serves generated cards
+	CardRoutePrefix = "/cards"
+)
+
+const defaultCardTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<style>
+  html, body { margin: 0; width: {{.Width}}px; height: {{.Height}}px; }
+  body {
+    box-sizing: border-box; padding: 72px 88px; display: flex; flex-direction: column;
+    justify-content: space-between; color: #fff;
+    background: linear-gradient(135deg, {{.Color}} 0%, #111827 100%);
+    font-family: "Inter", "PingFang SC", "Noto Sans CJK SC", "Microsoft YaHei", sans-serif;
+  }
+  .site { font-size: 28px; letter-spacing: 2px; text-transform: uppercase; opacity: .8; }
+  .title { font-size: 64px; font-weight: 700; line-height: 1.25; overflow: hidden;
+    display: -webkit-box; -webkit-line-clamp: 4; -webkit-box-orient: vertical; }
+  .footer { display: flex; justify-content: space-between; font-size: 28px; opacity: .9; }
+  .tags span { margin-left: 16px; }
+</style>
+</head>
+<body>
+  <div class="site">{{.Site}}</div>
+  <div class="title">{{.Title}}</div>
+  <div class="footer">
+    <div>{{.Author}}</div>
+    <div class="tags">{{range .Tags}}<span>#{{.}}</span>{{end}}</div>
+  </div>
+</body>
+</html>`
+
+const noteCardTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<style>
+  html, body { margin: 0; width: {{.Width}}px; height: {{.Height}}px; }
+  body {
+    box-sizing: border-box; padding: 110px 96px; display: flex; flex-direction: column;
+    font-family: "PingFang SC", "Noto Sans CJK SC", "Microsoft YaHei", "Inter", sans-serif;
+  }
+  body.cover { justify-content: space-between; color: #fff;
+    background: linear-gradient(160deg, {{.Color}} 0%, #111827 100%); }
+  body.text { justify-content: flex-start; color: #1f2937; background: #fffaf3; }
+  .site { font-size: 34px; letter-spacing: 2px; opacity: .8; }
+  .title { font-size: 96px; font-weight: 800; line-height: 1.3; }
+  .author { font-size: 38px; opacity: .9; }
+  .tags span { display: inline-block; margin: 0 18px 12px 0; font-size: 36px; }
+  .heading { font-size: 44px; font-weight: 700; color: {{.Color}}; margin-bottom: 56px; }
+  .body { flex: 1; font-size: 50px; line-height: 1.75; white-space: pre-wrap; overflow: hidden; }
+  .page { align-self: flex-end; font-size: 32px; opacity: .5; }
+</style>
+</head>
+{{if .Cover}}<body class="cover">
+  <div class="site">{{.Site}}</div>
+  <div class="title">{{.Title}}</div>
+  <div>
+    <div class="tags">{{range .Tags}}<span>#{{.}}</span>{{end}}</div>
+    <div class="author">{{.Author}}</div>
+  </div>
+</body>{{else}}<body class="text">
+  <div class="heading">{{.Title}}</div>
+  <div class="body">{{.Text}}</div>
+  <div class="page">{{.Page}} / {{.Pages}}</div>
+</body>{{end}}
+</html>`
+
+// noteCardData is passed to the note card template
+type noteCardData struct {
+	publisher.NoteCard
+	Site   string
+	Color  string
+	Width  int
+	Height int
+}
+
+// cardData is passed to the card template
+type cardData struct {
+	Title  string
+	Author string
+	Tags   []string
+	Site   string
+	Color  string
+	Width  int
+	Height int
+}
+
+// CardService renders branded cover images for pages without a cover, and the cards of
+// image-centric notes. The card HTML templates are screenshotted by a headless Chrome, so any
+// font and CSS the browser supports can be used.
+type CardService struct {
+	config       *config.CardConfig
+	logger       *zap.Logger
+	template     *template.Template
+	noteTemplate *template.Template
+}
+
+func NewCardService(cfg *config.CardConfig, logger *zap.Logger) (*CardService, error) {
+	source := defaultCardTemplate
+	if cfg.Template != "" {
+		data, err := os.ReadFile(cfg.Template)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read card template: %w", err)
+		}
+		source = string(data)
+	}
+
+	tmpl, err := template.New("card").Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse card template: %w", err)
+	}
+
+	if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create card output directory: %w", err)
+	}
+
+	return &CardService{
+		config:       cfg,
+		logger:       logger,
+		template:     tmpl,
+		noteTemplate: template.Must(template.New("note_card").Parse(noteCardTemplate)),
+	}, nil
+}
+
+// Generate renders the card for a page and returns its file name in the output directory.
+// Cards are named after their content, so an unchanged page reuses its card.
+func (s *CardService) Generate(ctx context.Context, title, author string, tags []string) (string, error) {
+	if len(tags) > 3 {
+		tags = tags[:3]
+	}
+	data := cardData{
+		Title:  title,
+		Author: author,
+		Tags:   tags,
+		Site:   s.config.SiteName,
+		Color:  s.config.BrandColor,
+		Width:  cardWidth,
+		Height: cardHeight,
+	}
+
+	var html strings.Builder
+	if err := s.template.Execute(&html, data); err != nil {
+		return "", fmt.Errorf("failed to render card template: %w", err)
+	}
+
+	sum := sha1.Sum([]byte(html.String()))
+	name := hex.EncodeToString(sum[:])[:16] + ".png"
+	output, err := filepath.Abs(filepath.Join(s.config.OutputDir, name))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve card path: %w", err)
+	}
+	if _, err := os.Stat(output); err == nil {
+		return name, nil
+	}
+
+	if err := s.screenshot(ctx, html.String(), cardWidth, cardHeight, output); err != nil {
+		return "", err
+	}
+
+	s.logger.Info("Cover card generated",
+		zap.String("title", title),
+		zap.String("file", name))
+
+	return name, nil
+}
+
+// RenderNoteCards renders the cards of an image-centric note into a directory, one PNG per card
+func (s *CardService) RenderNoteCards(ctx context.Context, cards []publisher.NoteCard, dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create card directory: %w", err)
+	}
+
+	paths := make([]string, 0, len(cards))
+	for _, card := range cards {
+		data := noteCardData{
+			NoteCard: card,
+			Site:     s.config.SiteName,
+			Color:    s.config.BrandColor,
+			Width:    noteCardWidth,
+			Height:   noteCardHeight,
+		}
+		var html strings.Builder
+		if err := s.noteTemplate.Execute(&html, data); err != nil {
+			return nil, fmt.Errorf("failed to render note card template: %w", err)
+		}
+
+		output, err := filepath.Abs(filepath.Join(dir, fmt.Sprintf("card-%02d.png", card.Page)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve card path: %w", err)
+		}
+		if err := s.screenshot(ctx, html.String(), noteCardWidth, noteCardHeight, output); err != nil {
+			return nil, err
+		}
+		paths = append(paths, output)
+	}
+
+	s.logger.Info("Note cards rendered",
+		zap.Int("cards", len(paths)),
+		zap.String("dir", dir))
+
+	return paths, nil
+}
+
+// screenshot renders an HTML page with the headless browser into a PNG of the size
+func (s *CardService) screenshot(ctx context.Context, html string, width, height int, output string) error {
+	htmlFile, err := os.CreateTemp("", "ripple-card-*.html")
+	if err != nil {
+		return fmt.Errorf("failed to create card page: %w", err)
+	}
+	defer os.Remove(htmlFile.Name())
+	if _, err := htmlFile.WriteString(html); err != nil {
+		htmlFile.Close()
+		return fmt.Errorf("failed to write card page: %w", err)
+	}
+	htmlFile.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, cardRenderTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, s.config.Renderer,
+		"--headless=new",
+		"--disable-gpu",
+		"--no-sandbox",
+		"--hide-scrollbars",
+		"--force-device-scale-factor=1",
+		fmt.Sprintf("--window-size=%d,%d", width, height),
+		"--screenshot="+output,
+		"file://"+htmlFile.Name(),
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(output)
+		return fmt.Errorf("failed to render card with %s: %w: %s", s.config.Renderer, err, strings.TrimSpace(string(out)))
+	}
+	if _, err := os.Stat(output); err != nil {
+		return fmt.Errorf("renderer did not write the card: %w", err)
+	}
+	return nil
+}
+
+// Location returns where platforms can fetch a card: its public URL when the server's base URL
+// is configured, otherwise its local path, which only platforms uploading files can use
+func (s *CardService) Location(name string) string {
+	if s.config.BaseURL != "" {
+		return strings.TrimSuffix(s.config.BaseURL, "/") + CardRoutePrefix + "/" + name
+	}
+	path, err := filepath.Abs(filepath.Join(s.config.OutputDir, name))
+	if err != nil {
+		return filepath.Join(s.config.OutputDir, name)
+	}
+	return path
+}
+
+// cardHook gives pages without a cover a generated card before they are transformed
+type cardHook struct {
+	cards *CardService
+}
+
+func (h *cardHook) Name() string {
+	return "cover_card"
+}
+
+func (h *cardHook) Stages() []publisher.HookStage {
+	return []publisher.HookStage{publisher.HookPreTransform}
+}
+
+func (h *cardHook) Run(ctx context.Context, event *publisher.HookEvent) (string, error) {
+	content := event.Content
+	if content.Metadata["cover_url"] != "" {
+		return "", nil
+	}
+
+	// A missing card is not worth failing the publish for
+	name, err := h.cards.Generate(ctx, content.Title, content.Author, content.Tags)
+	if err != nil {
+		return fmt.Sprintf("no cover card: %v", err), nil
+	}
+
+	content.Metadata["cover_url"] = h.cards.Location(name)
+	content.Metadata["cover_generated"] = "true"
+	return "generated cover card " + name, nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// CredentialMonitor periodically verifies platform credentials so expired sessions are
+// reported before a publish fails on them
+type CredentialMonitor struct {
+	publisherService *PublisherService
+	logger           *zap.Logger
+	interval         time.Duration
+	failing          map[string]bool
+	done             chan struct{}
+}
+
+// NewCredentialMonitor creates a credential monitor; an interval of 0 disables it
+func NewCredentialMonitor(publisherService *PublisherService, logger *zap.Logger, interval time.Duration) *CredentialMonitor {
+	return &CredentialMonitor{
+		publisherService: publisherService,
+		logger:           logger,
+		interval:         interval,
+		failing:          make(map[string]bool),
+		done:             make(chan struct{}),
+	}
+}
+
+// Start begins the periodic credential checks
+func (m *CredentialMonitor) Start(ctx context.Context) {
+	if m.interval <= 0 {
+		m.logger.Info("Credential monitor is disabled")
+		return
+	}
+
+	go func() {
+		m.logger.Info("Starting credential monitor", zap.Duration("interval", m.interval))
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		m.checkCredentials(ctx)
+		for {
+			select {
+			case <-m.done:
+				m.logger.Info("Credential monitor stopped")
+				return
+			case <-ctx.Done():
+				m.logger.Info("Credential monitor stopped due to context cancellation")
+				return
+			case <-ticker.C:
+				m.checkCredentials(ctx)
+			}
+		}
+	}()
+}
+
+// Stop stops the credential monitor
+func (m *CredentialMonitor) Stop() {
+	close(m.done)
+}
+
+// checkCredentials checks every platform and records an error only when its state changes
+func (m *CredentialMonitor) checkCredentials(ctx context.Context) {
+	unlock, ok := m.publisherService.locker.lockRound(ctx, LockCredentials, m.logger)
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	for _, platformName := range m.publisherService.GetAvailablePlatforms() {
+		checkCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		err := m.publisherService.CheckPlatformCredentials(checkCtx, platformName)
+		cancel()
+
+		if err != nil {
+			m.logger.Warn("Platform credential check failed",
+				zap.String("platform", platformName),
+				zap.Error(err))
+
+			if m.failing[platformName] {
+				continue
+			}
+			m.failing[platformName] = true
+
+			title := fmt.Sprintf("Credential check failed for %s", platformName)
+			if errors.Is(err, publisher.ErrCredentialsExpired) {
+				title = fmt.Sprintf("Credentials expired for %s", platformName)
+			}
+			m.publisherService.monitoringService.RecordError("ERROR", "credential_check", title, err.Error(),
+				WithPlatform(platformName),
+				WithErrorType(errorTypeOf(err)),
+				WithErrorCode(publisher.CodeOf(err)))
+			continue
+		}
+
+		if m.failing[platformName] {
+			delete(m.failing, platformName)
+			m.publisherService.monitoringService.RecordError("INFO", "credential_check",
+				fmt.Sprintf("Credentials restored for %s", platformName),
+				fmt.Sprintf("%s credentials are valid again", platformName),
+				WithPlatform(platformName))
+		}
+	}
+}
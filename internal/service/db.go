@@ -1,26 +1,48 @@
 package service
 
 import (
+	"context"
 	"fmt"
 
+	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 
 	"github.com/ifuryst/ripple/internal/config"
 	"github.com/ifuryst/ripple/internal/models"
 )
 
-func NewDatabase(cfg *config.DatabaseConfig) (*gorm.DB, error) {
+func NewDatabase(cfg *config.DatabaseConfig, log *zap.Logger) (*gorm.DB, error) {
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=%s",
 		cfg.Host, cfg.Username, cfg.Password, cfg.Database, cfg.Port, cfg.SSLMode, cfg.TimeZone)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
+	logLevel, err := parseQueryLogLevel(cfg.QueryLogLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(postgres.New(postgres.Config{
+		DSN:                  dsn,
+		PreferSimpleProtocol: cfg.SimpleProtocol,
+	}), &gorm.Config{
+		Logger:      newQueryLogger(log, logLevel, cfg.SlowQueryThreshold),
+		PrepareStmt: cfg.PrepareStmt,
+		// Report unique violations as gorm.ErrDuplicatedKey
+		TranslateError: true,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	if err := configurePool(db, cfg); err != nil {
+		return nil, err
+	}
+
+	// Replicas starting together migrate one after the other
+	unlock, err := NewLocker(db, zap.NewNop(), cfg.AdvisoryLocks).acquire(context.Background(), LockMigrate, true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
 	// Auto migrate the schema
 	if err := db.AutoMigrate(
@@ -30,11 +52,72 @@ func NewDatabase(cfg *config.DatabaseConfig) (*gorm.DB, error) {
 		&models.SystemStats{},
 		&models.PlatformStats{},
 		&models.ErrorLog{},
+		&models.ErrorIssue{},
 		&models.MetricsSample{},
 		&models.DashboardSummary{},
+		&models.SyncRun{},
+		&models.PublishBatch{},
+		&models.JobTransition{},
+		&models.JobLog{},
+		&models.PostMetric{},
+		&models.MediaAsset{},
+		&models.PostSlug{},
+		&models.PublishTask{},
+		&models.ContentVersion{},
 	); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
+	if err := ensureUniqueActiveJobs(db); err != nil {
+		return nil, fmt.Errorf("failed to create job index: %w", err)
+	}
+
+	if err := backfillSearchText(db); err != nil {
+		return nil, fmt.Errorf("failed to build search index: %w", err)
+	}
+
+	if err := backfillErrorIssues(db); err != nil {
+		return nil, fmt.Errorf("failed to group error logs: %w", err)
+	}
+
 	return db, nil
 }
+
+// ensureUniqueActiveJobs allows a single in-progress or completed job per page and platform.
+// Duplicates left by concurrent runs before the index existed are marked first, keeping the
+// most recent completed job.
+func ensureUniqueActiveJobs(db *gorm.DB) error {
+	if err := db.Exec(`
+		UPDATE distribution_jobs SET status = 'duplicate'
+		WHERE id IN (
+			SELECT id FROM (
+				SELECT id, ROW_NUMBER() OVER (
+					PARTITION BY page_id, platform_id
+					ORDER BY (status = 'completed') DESC, updated_at DESC
+				) AS position
+				FROM distribution_jobs
+				WHERE status IN ('in_progress', 'completed') AND deleted_at IS NULL
+			) ranked
+			WHERE position > 1
+		)`).Error; err != nil {
+		return err
+	}
+
+	return db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_distribution_jobs_active
+		ON distribution_jobs (page_id, platform_id)
+		WHERE status IN ('in_progress', 'completed') AND deleted_at IS NULL`).Error
+}
+
+// backfillSearchText fills the search text of pages stored before search was added
+func backfillSearchText(db *gorm.DB) error {
+	var pages []models.NotionPage
+	return db.Where("search_text IS NULL").FindInBatches(&pages, 100, func(tx *gorm.DB, batch int) error {
+		for _, page := range pages {
+			if err := tx.Model(&page).UpdateColumn("search_text", page.BuildSearchText()).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
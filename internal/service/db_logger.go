@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// parseQueryLogLevel parses the query log level of the database configuration
+func parseQueryLogLevel(level string) (logger.LogLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "silent":
+		return logger.Silent, nil
+	case "error":
+		return logger.Error, nil
+	case "", "warn":
+		return logger.Warn, nil
+	case "info":
+		return logger.Info, nil
+	default:
+		return logger.Silent, fmt.Errorf("invalid query log level %q, expected silent, error, warn or info", level)
+	}
+}
+
+// queryLogger logs the queries of GORM with zap: failed queries from the error level, slow
+// queries from warn and every query at debug from info
+type queryLogger struct {
+	logger        *zap.Logger
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+func newQueryLogger(log *zap.Logger, level logger.LogLevel, slowThreshold time.Duration) *queryLogger {
+	return &queryLogger{logger: log, level: level, slowThreshold: slowThreshold}
+}
+
+func (l *queryLogger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+func (l *queryLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Info {
+		l.logger.Info(fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *queryLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Warn {
+		l.logger.Warn(fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *queryLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Error {
+		l.logger.Error(fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *queryLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	// Missing records and canceled requests are handled by the callers
+	case err != nil && l.level >= logger.Error &&
+		!errors.Is(err, gorm.ErrRecordNotFound) && !errors.Is(err, context.Canceled):
+		sql, rows := fc()
+		l.logger.Warn("Database query failed",
+			zap.String("sql", sql),
+			zap.Int64("rows", rows),
+			zap.Duration("elapsed", elapsed),
+			zap.Error(err))
+	case l.slowThreshold > 0 && elapsed >= l.slowThreshold && l.level >= logger.Warn:
+		sql, rows := fc()
+		l.logger.Warn("Slow database query",
+			zap.String("sql", sql),
+			zap.Int64("rows", rows),
+			zap.Duration("elapsed", elapsed),
+			zap.Duration("threshold", l.slowThreshold))
+	case l.level >= logger.Info:
+		sql, rows := fc()
+		l.logger.Debug("Database query",
+			zap.String("sql", sql),
+			zap.Int64("rows", rows),
+			zap.Duration("elapsed", elapsed))
+	}
+}
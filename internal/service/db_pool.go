@@ -0,0 +1,104 @@
+package service
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/ifuryst/ripple/internal/config"
+)
+
+// poolSaturationWarning is the share of the maximum connections in use above which the pool is
+// reported as saturated
+const poolSaturationWarning = 0.9
+
+// DatabasePoolStats is the state of the database connection pool of this process
+type DatabasePoolStats struct {
+	MaxOpen int `json:"max_open"`
+	Open    int `json:"open"`
+	InUse   int `json:"in_use"`
+	Idle    int `json:"idle"`
+	// Saturation is the share of the maximum connections in use, 0 without a maximum
+	Saturation float64 `json:"saturation"`
+	// WaitCount and WaitDurationMs add up the waits for a free connection since the start
+	WaitCount         int64 `json:"wait_count"`
+	WaitDurationMs    int64 `json:"wait_duration_ms"`
+	MaxIdleClosed     int64 `json:"max_idle_closed"`
+	MaxIdleTimeClosed int64 `json:"max_idle_time_closed"`
+	MaxLifetimeClosed int64 `json:"max_lifetime_closed"`
+}
+
+// configurePool applies the connection pool settings of the database configuration
+func configurePool(db *gorm.DB, cfg *config.DatabaseConfig) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection pool: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	return nil
+}
+
+// DatabasePool returns the state of the connection pool of db
+func DatabasePool(db *gorm.DB) (*DatabasePoolStats, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection pool: %w", err)
+	}
+
+	stats := sqlDB.Stats()
+	pool := &DatabasePoolStats{
+		MaxOpen:           stats.MaxOpenConnections,
+		Open:              stats.OpenConnections,
+		InUse:             stats.InUse,
+		Idle:              stats.Idle,
+		WaitCount:         stats.WaitCount,
+		WaitDurationMs:    stats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:     stats.MaxIdleClosed,
+		MaxIdleTimeClosed: stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed: stats.MaxLifetimeClosed,
+	}
+	if pool.MaxOpen > 0 {
+		pool.Saturation = float64(pool.InUse) / float64(pool.MaxOpen)
+	}
+	return pool, nil
+}
+
+// RecordDatabasePool records the state of the connection pool of this process as metrics
+// samples, and warns when queries waited for a connection since the last record. It returns the
+// wait count to pass as lastWaits next time.
+func (m *MonitoringService) RecordDatabasePool(lastWaits int64) int64 {
+	pool, err := DatabasePool(m.db)
+	if err != nil {
+		m.logger.Error("Failed to get database pool stats", zap.Error(err))
+		return lastWaits
+	}
+
+	for _, metric := range []struct {
+		name, metricType string
+		value            float64
+	}{
+		{"db_pool_open", "gauge", float64(pool.Open)},
+		{"db_pool_in_use", "gauge", float64(pool.InUse)},
+		{"db_pool_idle", "gauge", float64(pool.Idle)},
+		{"db_pool_saturation", "gauge", pool.Saturation},
+		{"db_pool_wait_count", "counter", float64(pool.WaitCount)},
+		{"db_pool_wait_duration_ms", "counter", float64(pool.WaitDurationMs)},
+	} {
+		if err := m.RecordMetric(metric.name, metric.metricType, metric.value, map[string]interface{}{"max_open": pool.MaxOpen}); err != nil {
+			m.logger.Error("Failed to record database pool metric", zap.String("metric", metric.name), zap.Error(err))
+		}
+	}
+
+	if pool.WaitCount > lastWaits || pool.Saturation >= poolSaturationWarning {
+		m.logger.Warn("Database connection pool saturated",
+			zap.Int("max_open", pool.MaxOpen),
+			zap.Int("in_use", pool.InUse),
+			zap.Int64("waits", pool.WaitCount-lastWaits),
+			zap.Int64("wait_duration_ms", pool.WaitDurationMs))
+	}
+	return pool.WaitCount
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// DeploymentTracker follows up on publishes that only go live after a site build or after the
+// platform processed them, recording the live URL on the distribution job or reporting the
+// failure; jobs the platform rejected fail
+type DeploymentTracker struct {
+	publisherService *PublisherService
+	logger           *zap.Logger
+	interval         time.Duration
+	timeout          time.Duration
+	done             chan struct{}
+}
+
+// NewDeploymentTracker creates a deployment tracker; an interval of 0 disables it
+func NewDeploymentTracker(publisherService *PublisherService, logger *zap.Logger, interval, timeout time.Duration) *DeploymentTracker {
+	return &DeploymentTracker{
+		publisherService: publisherService,
+		logger:           logger,
+		interval:         interval,
+		timeout:          timeout,
+		done:             make(chan struct{}),
+	}
+}
+
+// Start begins polling pending deployments
+func (t *DeploymentTracker) Start(ctx context.Context) {
+	if t.interval <= 0 {
+		t.logger.Info("Deployment tracker is disabled")
+		return
+	}
+
+	go func() {
+		t.logger.Info("Starting deployment tracker",
+			zap.Duration("interval", t.interval),
+			zap.Duration("timeout", t.timeout))
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-t.done:
+				t.logger.Info("Deployment tracker stopped")
+				return
+			case <-ctx.Done():
+				t.logger.Info("Deployment tracker stopped due to context cancellation")
+				return
+			case <-ticker.C:
+				t.checkDeployments(ctx)
+			}
+		}
+	}()
+}
+
+// Stop stops the deployment tracker
+func (t *DeploymentTracker) Stop() {
+	close(t.done)
+}
+
+// checkDeployments verifies every completed job whose deployment is still pending
+func (t *DeploymentTracker) checkDeployments(ctx context.Context) {
+	unlock, ok := t.publisherService.locker.lockRound(ctx, LockDeployments, t.logger)
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	var jobs []models.DistributionJob
+	if err := t.publisherService.db.WithContext(ctx).
+		Preload("Platform").
+		Where("status = ? AND metadata->>'deploy_status' = ?", "completed", publisher.DeploymentPending).
+		Find(&jobs).Error; err != nil {
+		t.logger.Error("Failed to load pending deployments", zap.Error(err))
+		return
+	}
+
+	for i := range jobs {
+		t.checkJob(ctx, &jobs[i])
+	}
+}
+
+func (t *DeploymentTracker) checkJob(ctx context.Context, job *models.DistributionJob) {
+	platformName := job.Platform.Name
+
+	checkCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	status, err := t.publisherService.manager.VerifyDeployment(checkCtx, platformName, job.Metadata)
+	cancel()
+	if err != nil {
+		t.logger.Warn("Failed to verify deployment",
+			zap.Uint("job_id", job.ID),
+			zap.String("platform", platformName),
+			zap.Error(err))
+		status = &publisher.DeploymentStatus{State: publisher.DeploymentPending, Message: err.Error()}
+	}
+
+	publishedAt := job.CreatedAt
+	if job.PublishedAt != nil {
+		publishedAt = *job.PublishedAt
+	}
+	if status.State == publisher.DeploymentPending && t.timeout > 0 && time.Since(publishedAt) > t.timeout {
+		status.State = publisher.DeploymentFailed
+		status.Message = fmt.Sprintf("not live after %s: %s", t.timeout, status.Message)
+	}
+
+	if status.State == publisher.DeploymentPending {
+		return
+	}
+
+	metadata := models.JSONMap{}
+	for key, value := range job.Metadata {
+		metadata[key] = value
+	}
+	metadata["deploy_status"] = status.State
+	metadata["deploy_checked_at"] = time.Now().Format(time.RFC3339)
+	updates := map[string]interface{}{"metadata": metadata}
+
+	switch status.State {
+	case publisher.DeploymentLive:
+		if status.URL != "" {
+			metadata["live_url"] = status.URL
+			updates["url"] = status.URL
+		}
+		t.logger.Info("Deployment is live",
+			zap.Uint("job_id", job.ID),
+			zap.String("platform", platformName),
+			zap.String("url", status.URL))
+	case publisher.DeploymentFailed:
+		if status.Rejected {
+			t.rejectJob(job, platformName, metadata, status.Message)
+			return
+		}
+		metadata["deploy_error"] = status.Message
+		t.logger.Warn("Deployment failed",
+			zap.Uint("job_id", job.ID),
+			zap.String("platform", platformName),
+			zap.String("reason", status.Message))
+		t.publisherService.monitoringService.RecordError("ERROR", "deployment",
+			fmt.Sprintf("Deployment failed for %s", platformName), status.Message,
+			WithPlatform(platformName),
+			WithPage(job.PageID),
+			WithJob(job.ID),
+			WithContext(map[string]interface{}{
+				"url":         metadata["url"],
+				"commit_hash": metadata["commit_hash"],
+			}))
+	}
+
+	if err := t.publisherService.db.Model(job).Updates(updates).Error; err != nil {
+		t.logger.Error("Failed to update deployment status",
+			zap.Uint("job_id", job.ID),
+			zap.Error(err))
+	}
+}
+
+// rejectJob fails a completed job whose content the platform rejected after accepting it
+func (t *DeploymentTracker) rejectJob(job *models.DistributionJob, platformName string, metadata models.JSONMap, reason string) {
+	t.logger.Warn("Publish rejected by platform",
+		zap.Uint("job_id", job.ID),
+		zap.String("platform", platformName),
+		zap.String("reason", reason))
+
+	job.Metadata = metadata
+	job.ErrorCode = string(publisher.ErrorCodeContentInvalid)
+	if err := publisher.TransitionJob(t.publisherService.db, job, models.JobFailed, reason); err != nil {
+		t.logger.Error("Failed to fail rejected job",
+			zap.Uint("job_id", job.ID),
+			zap.Error(err))
+		return
+	}
+
+	t.publisherService.monitoringService.RecordError("ERROR", "publisher",
+		fmt.Sprintf("Publish rejected by %s", platformName), reason,
+		WithPlatform(platformName),
+		WithPage(job.PageID),
+		WithJob(job.ID),
+		WithErrorCode(publisher.ErrorCodeContentInvalid),
+		WithContext(map[string]interface{}{
+			"publish_id": job.PublishID,
+		}))
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image/png"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/qr"
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// previewQRCodeSize is the width and height in pixels of draft preview QR codes
+const previewQRCodeSize = 256
+
+// DraftReview is a draft job prepared for reviewing on a phone
+type DraftReview struct {
+	JobID    uint                    `json:"job_id"`
+	Platform string                  `json:"platform"`
+	Preview  *publisher.DraftPreview `json:"preview"`
+	// QRCode is a PNG of the preview link, scanned to open the draft
+	QRCode []byte `json:"-"`
+	// SentTo is who the draft was sent to as a preview message
+	SentTo string `json:"sent_to,omitempty"`
+}
+
+// ReviewDraft fetches the preview of the draft a job saved and renders its link as a QR code;
+// with send the draft is also sent as a preview message to the recipient or the configured
+// reviewer
+func (s *PublisherService) ReviewDraft(ctx context.Context, jobID uint, send bool, recipient string) (*DraftReview, error) {
+	var job models.DistributionJob
+	if err := s.db.WithContext(ctx).Preload("Platform").First(&job, jobID).Error; err != nil {
+		return nil, err
+	}
+	if job.Status != models.JobDraft {
+		return nil, fmt.Errorf("%w: job %d is %s", ErrJobNotDraft, job.ID, job.Status)
+	}
+
+	preview, sentTo, err := s.manager.PreviewDraft(ctx, job.Platform.Name, job.PublishID, send, recipient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to preview draft on %s: %w", job.Platform.Name, err)
+	}
+
+	review := &DraftReview{JobID: job.ID, Platform: job.Platform.Name, Preview: preview, SentTo: sentTo}
+	if preview.URL != "" {
+		if review.QRCode, err = previewQRCode(preview.URL); err != nil {
+			return nil, err
+		}
+	}
+	if sentTo != "" {
+		s.logger.Info("Sent draft preview",
+			zap.Uint("job_id", job.ID),
+			zap.String("platform", job.Platform.Name),
+			zap.String("recipient", sentTo))
+	}
+	return review, nil
+}
+
+// previewQRCode renders a link as a PNG QR code
+func previewQRCode(link string) ([]byte, error) {
+	code, err := qr.Encode(link, qr.M, qr.Auto)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode preview QR code: %w", err)
+	}
+	code, err = barcode.Scale(code, previewQRCodeSize, previewQRCodeSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scale preview QR code: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, code); err != nil {
+		return nil, fmt.Errorf("failed to render preview QR code: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/ifuryst/ripple/internal/config"
+	"github.com/ifuryst/ripple/internal/content"
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/notion"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/llm"
+)
+
+// When pages are enriched
+const (
+	EnrichOnSync    = "sync"
+	EnrichOnPublish = "publish"
+)
+
+const (
+	defaultMaxPagesPerSync = 20
+	defaultMaxInputChars   = 12000
+	// maxSEODescription is the length search engines show in results
+	maxSEODescription = 160
+	maxSuggestedTags  = 6
+)
+
+// ErrEnrichmentDisabled is returned when enriching a page without AI enrichment configured
+var ErrEnrichmentDisabled = errors.New("AI enrichment is not enabled")
+
+const enrichmentPrompt = `You write metadata for blog posts. Answer with a JSON object with these keys:
+"summary": two or three sentences summarizing the post, used as its subtitle and digest;
+"seo_description": a description for search engines of at most 155 characters;
+"tags": three to six short lowercase tags.
+Write in the language the post is written in and don't invent facts that aren't in the post.`
+
+const titleTranslationPrompt = `You translate blog post titles into English for their URLs. Answer with a JSON object
+with the key "title": the title translated into English, short and plain, without quotes.`
+
+// Enrichment is the metadata generated for a page
+type Enrichment struct {
+	Summary        string   `json:"summary"`
+	SEODescription string   `json:"seo_description"`
+	Tags           []string `json:"tags"`
+}
+
+// Enricher generates summaries, SEO descriptions and tag suggestions for pages with an LLM
+type Enricher struct {
+	client        *llm.Client
+	db            *gorm.DB
+	logger        *zap.Logger
+	maxPages      int
+	maxInputChars int
+	// mu keeps the platforms of a publish from enriching the same page at once
+	mu sync.Mutex
+	// titles remembers translated titles, so a title gets the same slug every time it is published
+	titles sync.Map
+}
+
+// NewEnricher creates an enricher from the AI configuration
+func NewEnricher(cfg config.AIConfig, db *gorm.DB, logger *zap.Logger) (*Enricher, error) {
+	client, err := llm.NewClient(llm.Config{
+		APIKey:  cfg.APIKey,
+		Model:   cfg.Model,
+		BaseURL: cfg.BaseURL,
+		Timeout: cfg.Timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	enricher := &Enricher{
+		client:        client,
+		db:            db,
+		logger:        logger,
+		maxPages:      cfg.MaxPagesPerSync,
+		maxInputChars: cfg.MaxInputChars,
+	}
+	if enricher.maxPages <= 0 {
+		enricher.maxPages = defaultMaxPagesPerSync
+	}
+	if enricher.maxInputChars <= 0 {
+		enricher.maxInputChars = defaultMaxInputChars
+	}
+	return enricher, nil
+}
+
+// NeedsEnrichment reports whether the page was never enriched or changed since
+func NeedsEnrichment(page *models.NotionPage) bool {
+	return page.EnrichedAt == nil || page.EnrichedAt.Before(page.LastModified)
+}
+
+// EnrichPage generates the metadata of a page and stores it on the page. Unless forced, pages
+// enriched since their last change are left alone. It reports whether the page was enriched.
+func (e *Enricher) EnrichPage(ctx context.Context, page *models.NotionPage, force bool) (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// Another publish may have enriched the page while this one waited
+	if err := e.db.WithContext(ctx).First(page, page.ID).Error; err != nil {
+		return false, fmt.Errorf("failed to reload page: %w", err)
+	}
+	if !force && !NeedsEnrichment(page) {
+		return false, nil
+	}
+
+	body := e.pageText(page)
+	if strings.TrimSpace(body) == "" {
+		return false, fmt.Errorf("page has no text to summarize")
+	}
+
+	prompt := fmt.Sprintf("Title: %s\nTags: %s\n\n%s", page.Title, strings.Join(page.Tags, ", "), body)
+	var enrichment Enrichment
+	if err := e.client.CompleteJSON(ctx, enrichmentPrompt, prompt, &enrichment); err != nil {
+		return false, fmt.Errorf("failed to generate metadata: %w", err)
+	}
+
+	now := time.Now()
+	page.AISummary = strings.TrimSpace(enrichment.Summary)
+	page.SEODescription = truncateRunes(strings.TrimSpace(enrichment.SEODescription), maxSEODescription)
+	page.SuggestedTags = normalizeTags(enrichment.Tags)
+	page.EnrichedAt = &now
+
+	if err := e.db.WithContext(ctx).Model(page).Updates(map[string]interface{}{
+		"ai_summary":      page.AISummary,
+		"seo_description": page.SEODescription,
+		"suggested_tags":  page.SuggestedTags,
+		"enriched_at":     page.EnrichedAt,
+	}).Error; err != nil {
+		return false, fmt.Errorf("failed to save metadata: %w", err)
+	}
+
+	e.logger.Info("Page enriched",
+		zap.String("page_id", page.NotionID),
+		zap.String("title", page.Title),
+		zap.String("model", e.client.Model()),
+		zap.Strings("suggested_tags", page.SuggestedTags))
+	return true, nil
+}
+
+// EnrichChangedPages enriches the pages changed since their last enrichment, newest first and
+// at most the configured number per call. Drafts are skipped, they change too often.
+func (e *Enricher) EnrichChangedPages(ctx context.Context) (int, error) {
+	var pages []models.NotionPage
+	if err := e.db.WithContext(ctx).
+		Select("id").
+		Where("LOWER(status) <> ?", "draft").
+		Where("archived_at IS NULL").
+		Where("enriched_at IS NULL OR enriched_at < last_modified").
+		Order("last_modified DESC").
+		Limit(e.maxPages).
+		Find(&pages).Error; err != nil {
+		return 0, fmt.Errorf("failed to get pages to enrich: %w", err)
+	}
+
+	enriched := 0
+	for i := range pages {
+		ok, err := e.EnrichPage(ctx, &pages[i], false)
+		if err != nil {
+			e.logger.Warn("Failed to enrich page",
+				zap.Uint("page_id", pages[i].ID),
+				zap.Error(err))
+			continue
+		}
+		if ok {
+			enriched++
+		}
+	}
+	return enriched, nil
+}
+
+// TranslateTitle translates a post title into English for its slug
+func (e *Enricher) TranslateTitle(ctx context.Context, title string) (string, error) {
+	if translated, ok := e.titles.Load(title); ok {
+		return translated.(string), nil
+	}
+
+	var answer struct {
+		Title string `json:"title"`
+	}
+	if err := e.client.CompleteJSON(ctx, titleTranslationPrompt, title, &answer); err != nil {
+		return "", fmt.Errorf("failed to translate title: %w", err)
+	}
+	translated := strings.TrimSpace(answer.Title)
+	if translated == "" {
+		return "", fmt.Errorf("model returned an empty translation")
+	}
+
+	e.titles.Store(title, translated)
+	return translated, nil
+}
+
+// pageText returns the plain text of the page body, cut to the configured length
+func (e *Enricher) pageText(page *models.NotionPage) string {
+	body := page.Document
+	if body == "" {
+		body = page.Content
+	}
+	text := body
+	if doc, err := content.Parse(body); err == nil {
+		text = doc.Text()
+	}
+	return truncateRunes(text, e.maxInputChars)
+}
+
+// EnrichPage generates the summary, SEO description and suggested tags of a page right away,
+// even when it was enriched since its last change
+func (s *PublisherService) EnrichPage(ctx context.Context, pageID string) (*models.NotionPage, error) {
+	if s.enricher == nil {
+		return nil, ErrEnrichmentDisabled
+	}
+
+	var page models.NotionPage
+	if err := s.db.Where("notion_id = ?", notion.NormalizePageID(pageID)).First(&page).Error; err != nil {
+		return nil, fmt.Errorf("page not found: %w", err)
+	}
+
+	if _, err := s.enricher.EnrichPage(ctx, &page, true); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// EnrichChangedPages enriches the pages changed since their last enrichment when enrichment
+// runs after syncing
+func (s *PublisherService) EnrichChangedPages(ctx context.Context) {
+	if s.enricher == nil || s.config.AI.EnrichOn != EnrichOnSync {
+		return
+	}
+
+	enriched, err := s.enricher.EnrichChangedPages(ctx)
+	if err != nil {
+		s.logger.Error("AI enrichment failed", zap.Error(err))
+		return
+	}
+	if enriched > 0 {
+		s.logger.Info("AI enrichment completed", zap.Int("pages_enriched", enriched))
+	}
+}
+
+// setupEnrichment creates the enricher and, when pages are enriched before publishing,
+// registers the hook doing it
+func (s *PublisherService) setupEnrichment() {
+	aiConfig := s.config.AI
+	if !aiConfig.Enabled {
+		return
+	}
+
+	enrichOn := aiConfig.EnrichOn
+	switch enrichOn {
+	case "":
+		enrichOn = EnrichOnPublish
+	case EnrichOnSync, EnrichOnPublish:
+	default:
+		s.logger.Error("Invalid AI enrich_on, expected sync or publish; enrichment disabled",
+			zap.String("enrich_on", enrichOn))
+		return
+	}
+
+	enricher, err := NewEnricher(aiConfig, s.db, s.logger)
+	if err != nil {
+		s.logger.Error("Failed to set up AI enrichment", zap.Error(err))
+		return
+	}
+	s.enricher = enricher
+	s.manager.SetTitleTranslator(enricher)
+
+	if enrichOn == EnrichOnPublish {
+		s.manager.RegisterHook(&enrichHook{enricher: enricher, db: s.db})
+	}
+	s.logger.Info("AI enrichment enabled",
+		zap.String("model", enricher.client.Model()),
+		zap.String("enrich_on", enrichOn))
+}
+
+// enrichHook enriches a page before its content is transformed for a platform. A failure only
+// leaves the content without generated metadata, it never stops the publish.
+type enrichHook struct {
+	enricher *Enricher
+	db       *gorm.DB
+}
+
+func (h *enrichHook) Name() string {
+	return "ai_enrichment"
+}
+
+func (h *enrichHook) Stages() []publisher.HookStage {
+	return []publisher.HookStage{publisher.HookPreTransform}
+}
+
+func (h *enrichHook) Run(ctx context.Context, event *publisher.HookEvent) (string, error) {
+	var page models.NotionPage
+	if err := h.db.WithContext(ctx).Select("id").Where("notion_id = ?", event.Content.ID).First(&page).Error; err != nil {
+		return fmt.Sprintf("skipped, page not found: %v", err), nil
+	}
+
+	enriched, err := h.enricher.EnrichPage(ctx, &page, false)
+	if err != nil {
+		return fmt.Sprintf("skipped: %v", err), nil
+	}
+
+	// The content was converted before the page was enriched
+	if event.Content.Summary == "" {
+		event.Content.Summary = page.AISummary
+	}
+	if page.SEODescription != "" {
+		event.Content.Metadata["description"] = page.SEODescription
+	}
+
+	if !enriched {
+		return "", nil
+	}
+	return "generated summary and SEO description", nil
+}
+
+// normalizeTags lowercases and deduplicates the suggested tags
+func normalizeTags(tags []string) models.StringArray {
+	seen := make(map[string]bool)
+	normalized := models.StringArray{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(tag, "#")))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+		if len(normalized) == maxSuggestedTags {
+			break
+		}
+	}
+	return normalized
+}
+
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit])
+}
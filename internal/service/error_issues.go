@@ -0,0 +1,175 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+// ErrIssueNotFound is returned for an unknown error issue
+var ErrIssueNotFound = errors.New("error issue not found")
+
+// Filters of the error issue list
+const (
+	IssuesUnresolved = "unresolved"
+	IssuesResolved   = "resolved"
+	IssuesAll        = "all"
+)
+
+// Parts of error messages that change between occurrences of the same error
+var (
+	urlQueryPattern = regexp.MustCompile(`(https?://[^\s?"']+)\?[^\s"']*`)
+	uuidPattern     = regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`)
+	hexPattern      = regexp.MustCompile(`\b[0-9a-fA-F]{16,}\b`)
+	quotedPattern   = regexp.MustCompile(`"[^"]*"`)
+	numberPattern   = regexp.MustCompile(`\d+`)
+)
+
+// normalizeErrorMessage strips the IDs, numbers, quoted values and URL queries of a message,
+// so occurrences of the same error about different pages or requests compare equal
+func normalizeErrorMessage(message string) string {
+	message = urlQueryPattern.ReplaceAllString(message, "$1")
+	message = uuidPattern.ReplaceAllString(message, "<uuid>")
+	message = hexPattern.ReplaceAllString(message, "<hex>")
+	message = quotedPattern.ReplaceAllString(message, `"<s>"`)
+	message = numberPattern.ReplaceAllString(message, "<n>")
+	return strings.ToLower(strings.Join(strings.Fields(message), " "))
+}
+
+// errorFingerprint identifies an error by its source, code and normalized message. Publish
+// failures are told apart by their error code, other errors by their type.
+func errorFingerprint(errorLog *models.ErrorLog) string {
+	code := errorLog.ErrorCode
+	if code == "" {
+		code = errorLog.ErrorType
+	}
+	sum := sha256.Sum256([]byte(errorLog.Source + "\x00" + code + "\x00" + normalizeErrorMessage(errorLog.Message)))
+	return hex.EncodeToString(sum[:])
+}
+
+// upsertIssue counts an error log as an occurrence of the issue with its fingerprint, creating
+// the issue on its first occurrence. The issue takes the details and resolution of the log, so
+// an error seen again reopens its resolved issue.
+func upsertIssue(tx *gorm.DB, errorLog *models.ErrorLog, seenAt time.Time) error {
+	errorLog.Fingerprint = errorFingerprint(errorLog)
+
+	issue := models.ErrorIssue{
+		Fingerprint:  errorLog.Fingerprint,
+		Level:        errorLog.Level,
+		Source:       errorLog.Source,
+		PlatformName: errorLog.PlatformName,
+		ErrorType:    errorLog.ErrorType,
+		ErrorCode:    errorLog.ErrorCode,
+		Title:        errorLog.Title,
+		Message:      errorLog.Message,
+		Count:        1,
+		FirstSeenAt:  seenAt,
+		LastSeenAt:   seenAt,
+		Resolved:     errorLog.Resolved,
+		ResolvedAt:   errorLog.ResolvedAt,
+	}
+	updates := clause.AssignmentColumns([]string{
+		"level", "platform_name", "error_type", "error_code", "title", "message",
+		"last_seen_at", "resolved", "resolved_at", "updated_at",
+	})
+	updates = append(updates, clause.Assignment{
+		Column: clause.Column{Name: "count"},
+		Value:  gorm.Expr("error_issues.count + 1"),
+	})
+
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "fingerprint"}},
+		DoUpdates: updates,
+	}).Create(&issue).Error; err != nil {
+		return err
+	}
+	errorLog.IssueID = &issue.ID
+	return nil
+}
+
+// backfillErrorIssues groups the error logs recorded before errors were fingerprinted
+func backfillErrorIssues(db *gorm.DB) error {
+	var logs []models.ErrorLog
+	return db.Where("fingerprint IS NULL OR fingerprint = ''").Order("id").
+		FindInBatches(&logs, 100, func(tx *gorm.DB, batch int) error {
+			for i := range logs {
+				errorLog := &logs[i]
+				if err := upsertIssue(tx, errorLog, errorLog.CreatedAt); err != nil {
+					return err
+				}
+				if err := tx.Model(errorLog).UpdateColumns(map[string]interface{}{
+					"fingerprint": errorLog.Fingerprint,
+					"issue_id":    errorLog.IssueID,
+				}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
+// GetErrorIssues returns the error issues seen most recently, filtered by resolution
+func (m *MonitoringService) GetErrorIssues(status string, limit int) ([]models.ErrorIssue, error) {
+	query := m.db.Order("last_seen_at desc").Limit(limit)
+	switch status {
+	case IssuesUnresolved:
+		query = query.Where("resolved = ?", false)
+	case IssuesResolved:
+		query = query.Where("resolved = ?", true)
+	}
+
+	var issues []models.ErrorIssue
+	err := query.Find(&issues).Error
+	return issues, err
+}
+
+// GetErrorIssue returns an error issue with its latest occurrences
+func (m *MonitoringService) GetErrorIssue(id uint, limit int) (*models.ErrorIssue, []models.ErrorLog, error) {
+	var issue models.ErrorIssue
+	if err := m.db.First(&issue, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrIssueNotFound
+		}
+		return nil, nil, err
+	}
+
+	var occurrences []models.ErrorLog
+	if err := m.db.Preload("Page").Preload("Job").
+		Where("issue_id = ?", id).
+		Order("created_at desc").
+		Limit(limit).
+		Find(&occurrences).Error; err != nil {
+		return nil, nil, err
+	}
+	return &issue, occurrences, nil
+}
+
+// ResolveErrorIssue marks an error issue and all its occurrences resolved
+func (m *MonitoringService) ResolveErrorIssue(id uint) error {
+	now := time.Now()
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.ErrorIssue{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"resolved":    true,
+			"resolved_at": &now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrIssueNotFound
+		}
+
+		return tx.Model(&models.ErrorLog{}).Where("issue_id = ? AND resolved = ?", id, false).Updates(map[string]interface{}{
+			"resolved":    true,
+			"resolved_at": &now,
+		}).Error
+	})
+}
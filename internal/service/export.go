@@ -0,0 +1,238 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/notion"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/httpclient"
+)
+
+// exportImagesDir is the subdirectory of an export that holds the downloaded images
+const exportImagesDir = "images"
+
+// ExportResult describes the files written by ExportPage
+type ExportResult struct {
+	PageID string            `json:"page_id"`
+	Title  string            `json:"title"`
+	Dir    string            `json:"dir"`
+	Files  []string          `json:"files"`
+	Images []string          `json:"images"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// ExportPage writes the page as transformed by every registered publisher, the raw Notion
+// blocks and the page's images to outDir. Publishers are not initialized, so nothing is
+// uploaded or published. Failures of single platforms or images are collected in Errors.
+func (s *PublisherService) ExportPage(ctx context.Context, pageID string, outDir string) (*ExportResult, error) {
+	var page models.NotionPage
+	if err := s.db.Where("notion_id = ?", notion.NormalizePageID(pageID)).First(&page).Error; err != nil {
+		return nil, fmt.Errorf("page not found: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(outDir, exportImagesDir), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	result := &ExportResult{
+		PageID: page.NotionID,
+		Title:  page.Title,
+		Dir:    outDir,
+		Errors: make(map[string]string),
+	}
+
+	if err := s.writeExportFile(result, "notion.json", []byte(page.Content)); err != nil {
+		return nil, err
+	}
+
+	doc, err := publisher.FromNotionPage(&page).ContentDocument()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse page content: %w", err)
+	}
+	document, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal document: %w", err)
+	}
+	if err := s.writeExportFile(result, "document.json", document); err != nil {
+		return nil, err
+	}
+
+	platforms := s.GetAvailablePlatforms()
+	sort.Strings(platforms)
+	for _, platformName := range platforms {
+		pub, err := s.manager.GetPublisher(platformName)
+		if err != nil {
+			result.Errors[platformName] = err.Error()
+			continue
+		}
+
+		transformed, err := pub.TransformContent(ctx, *publisher.FromNotionPage(&page))
+		if err != nil {
+			result.Errors[platformName] = err.Error()
+			continue
+		}
+
+		// Account names like wechat-official:en are not valid file names everywhere
+		fileName := strings.ReplaceAll(platformName, publisher.AccountSeparator, "-")
+		if err := s.writeExportFile(result, fileName+exportExtension(platformName), []byte(transformed.Content)); err != nil {
+			return nil, err
+		}
+
+		metadata, err := json.MarshalIndent(transformed.Metadata, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		if err := s.writeExportFile(result, fileName+".metadata.json", metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	imageURLs := doc.Images()
+	if page.CoverURL != "" {
+		imageURLs = append([]string{page.CoverURL}, imageURLs...)
+	}
+	for i, imageURL := range imageURLs {
+		name := fmt.Sprintf("%02d-%s", i+1, imageFileName(imageURL))
+		if err := downloadExportImage(ctx, imageURL, filepath.Join(outDir, exportImagesDir, name)); err != nil {
+			s.logger.Warn("Failed to download image for export",
+				zap.String("page_id", page.NotionID),
+				zap.String("url", imageURL),
+				zap.Error(err))
+			result.Errors[name] = err.Error()
+			continue
+		}
+		result.Images = append(result.Images, path.Join(exportImagesDir, name))
+	}
+
+	manifest, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(outDir, "manifest.json"), manifest, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	s.logger.Info("Page exported",
+		zap.String("page_id", page.NotionID),
+		zap.String("dir", outDir),
+		zap.Int("files", len(result.Files)),
+		zap.Int("images", len(result.Images)),
+		zap.Int("errors", len(result.Errors)))
+
+	return result, nil
+}
+
+func (s *PublisherService) writeExportFile(result *ExportResult, name string, data []byte) error {
+	if err := os.WriteFile(filepath.Join(result.Dir, name), data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	result.Files = append(result.Files, name)
+	return nil
+}
+
+// WriteZip archives every file under dir into w, using paths relative to dir
+func WriteZip(w io.Writer, dir string) error {
+	archive := zip.NewWriter(w)
+
+	err := filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+
+		entry, err := archive.Create(filepath.ToSlash(relativePath))
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(entry, file)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive export: %w", err)
+	}
+
+	return archive.Close()
+}
+
+// exportExtension returns the file extension matching a platform's transformed output
+func exportExtension(platformName string) string {
+	switch publisher.PlatformType(platformName) {
+	case "al-folio":
+		return ".md"
+	case "wechat-official", "confluence":
+		return ".html"
+	case "substack", "xiaohongshu", "mastodon", "bluesky", "discord", "slack", "buffer", "typefully":
+		return ".json"
+	default:
+		return ".txt"
+	}
+}
+
+// imageFileName derives a file name from an image URL, ignoring the query string
+func imageFileName(imageURL string) string {
+	name := "image"
+	if parsed, err := url.Parse(imageURL); err == nil {
+		if base := path.Base(parsed.Path); base != "." && base != "/" {
+			name = base
+		}
+	}
+	if path.Ext(name) == "" {
+		name += ".png"
+	}
+	return strings.ReplaceAll(name, " ", "_")
+}
+
+func downloadExportImage(ctx context.Context, imageURL, localPath string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", imageURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := httpclient.New(httpclient.WithTimeout(30 * time.Second))
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download image: status %d", resp.StatusCode)
+	}
+
+	file, err := os.Create(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		return fmt.Errorf("failed to save image: %w", err)
+	}
+	return nil
+}
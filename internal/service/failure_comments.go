@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/notion"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// failureCommentTimeout bounds posting a failure comment, which runs in the publish path
+const failureCommentTimeout = 30 * time.Second
+
+// commentOnFailure posts a Notion comment on a page that failed to publish, mentioning its
+// owners, when comments are enabled for the level of the failure. Only the first failure in a
+// row of a page and platform and its quarantine are commented on, so a page retried every sync
+// doesn't collect a comment per attempt.
+func (s *PublisherService) commentOnFailure(page *models.NotionPage, platformName string, result *publisher.PublishResult, level string) {
+	comments := s.config.Notion.FailureComments
+	if !comments.Enabled || s.notionService == nil || !page.IsFromNotion() || !containsLevel(comments.Levels, level) {
+		return
+	}
+
+	var jobs []models.DistributionJob
+	if err := s.db.Joins("JOIN platforms ON platforms.id = distribution_jobs.platform_id").
+		Where("distribution_jobs.page_id = ? AND platforms.name = ?", page.ID, platformName).
+		Order("distribution_jobs.created_at DESC").
+		Limit(2).
+		Find(&jobs).Error; err != nil || len(jobs) == 0 {
+		return
+	}
+	// Publishes skipped before a job was claimed, e.g. by an open circuit, have nothing to link
+	job := jobs[0]
+	if job.Status != models.JobFailed && job.Status != models.JobQuarantined {
+		return
+	}
+	// Quarantining ends a row of failures, so it is commented on as well
+	if job.Status == models.JobFailed && len(jobs) > 1 && jobs[1].Status == models.JobFailed {
+		return
+	}
+
+	message := result.ErrorMsg
+	if message == "" && result.Error != nil {
+		message = result.Error.Error()
+	}
+	text := fmt.Sprintf("Publishing to %s failed (job #%d", platformName, job.ID)
+	if result.ErrorCode != "" {
+		text += ", " + string(result.ErrorCode)
+	}
+	text += "): " + message
+	if job.Status == models.JobQuarantined {
+		text += "\nThe page will not be retried automatically until the job is republished."
+	}
+
+	comment := notion.Comment{
+		MentionIDs: notion.OwnerIDs(page.Properties),
+		Text:       text,
+	}
+	if dashboard := strings.TrimSuffix(comments.DashboardURL, "/"); dashboard != "" {
+		comment.Text += "\n"
+		comment.LinkText = "Open in Ripple"
+		comment.LinkURL = dashboard + "/errors"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), failureCommentTimeout)
+	defer cancel()
+	if err := s.notionService.AddComment(ctx, page.NotionID, comment); err != nil {
+		s.logger.Warn("Failed to comment on failed page",
+			zap.String("page_id", page.NotionID),
+			zap.String("platform", platformName),
+			zap.Error(err))
+		return
+	}
+	s.logger.Info("Commented on failed page",
+		zap.String("page_id", page.NotionID),
+		zap.String("platform", platformName),
+		zap.Uint("job_id", job.ID))
+}
+
+// containsLevel reports whether a comma separated list of error levels includes a level
+func containsLevel(levels, level string) bool {
+	for _, candidate := range strings.Split(levels, ",") {
+		if strings.EqualFold(strings.TrimSpace(candidate), level) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ifuryst/ripple/internal/config"
+)
+
+// ErrPublishFrozen is returned when publishing during maintenance or a freeze window without
+// overriding the freeze
+var ErrPublishFrozen = errors.New("publishing is frozen")
+
+// freezeDateLayouts are the formats freeze window bounds are written in; a date-only end
+// includes that whole day
+var freezeDateLayouts = []string{"2006-01-02T15:04", "2006-01-02"}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// FreezeWindow is a period without scheduled publishing, End is exclusive
+type FreezeWindow struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// FreezeSchedule decides when the scheduler must not publish: always in maintenance mode, and
+// on the frozen weekdays and within the freeze windows otherwise
+type FreezeSchedule struct {
+	Maintenance bool           `json:"maintenance"`
+	Weekdays    []string       `json:"weekdays"`
+	Windows     []FreezeWindow `json:"windows"`
+	Timezone    string         `json:"timezone"`
+
+	weekdays map[time.Weekday]bool
+	location *time.Location
+}
+
+// FreezeStatus tells whether publishing is frozen at a time and why
+type FreezeStatus struct {
+	Frozen bool   `json:"frozen"`
+	Reason string `json:"reason,omitempty"`
+	// Until is when the freeze ends; unset in maintenance mode, which lasts until it is turned off
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// ParseFreezeSchedule parses the weekdays, e.g. "sat,sun", and the windows, e.g.
+// "2024-12-24/2025-01-02,2025-03-01T09:00/2025-03-01T18:00", in the configured timezone
+func ParseFreezeSchedule(cfg config.FreezeConfig) (*FreezeSchedule, error) {
+	schedule := &FreezeSchedule{
+		Maintenance: cfg.Maintenance,
+		Weekdays:    []string{},
+		Windows:     []FreezeWindow{},
+		weekdays:    make(map[time.Weekday]bool),
+		location:    time.Local,
+	}
+
+	if timezone := strings.TrimSpace(cfg.Timezone); timezone != "" {
+		location, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid freeze timezone %q: %w", timezone, err)
+		}
+		schedule.location = location
+	}
+	schedule.Timezone = schedule.location.String()
+
+	for _, name := range strings.Split(cfg.Weekdays, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		// Accept sat, saturday and anything in between
+		weekday, ok := weekdayNames[name[:min(3, len(name))]]
+		if !ok || len(name) < 3 || !strings.HasPrefix(strings.ToLower(weekday.String()), name) {
+			return nil, fmt.Errorf("invalid freeze weekday %q", name)
+		}
+		schedule.weekdays[weekday] = true
+		schedule.Weekdays = append(schedule.Weekdays, weekday.String())
+	}
+
+	for _, entry := range strings.Split(cfg.Windows, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		startText, endText, ok := strings.Cut(entry, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid freeze window %q: expected start/end", entry)
+		}
+		start, _, err := parseFreezeTime(startText, schedule.location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid freeze window %q: %w", entry, err)
+		}
+		end, dateOnly, err := parseFreezeTime(endText, schedule.location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid freeze window %q: %w", entry, err)
+		}
+		if dateOnly {
+			end = end.AddDate(0, 0, 1)
+		}
+		if !end.After(start) {
+			return nil, fmt.Errorf("invalid freeze window %q: end is before start", entry)
+		}
+		schedule.Windows = append(schedule.Windows, FreezeWindow{Start: start, End: end})
+	}
+
+	return schedule, nil
+}
+
+// parseFreezeTime parses a date or a date and time, and reports whether it was a date only
+func parseFreezeTime(value string, location *time.Location) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range freezeDateLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, location); err == nil {
+			return parsed, len(layout) == len("2006-01-02"), nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("invalid date %q, expected YYYY-MM-DD or YYYY-MM-DDTHH:MM", value)
+}
+
+// Status returns whether publishing is frozen at now
+func (f *FreezeSchedule) Status(now time.Time) FreezeStatus {
+	if f.Maintenance {
+		return FreezeStatus{Frozen: true, Reason: "maintenance mode"}
+	}
+
+	now = now.In(f.location)
+	for _, window := range f.Windows {
+		if !now.Before(window.Start) && now.Before(window.End) {
+			until := window.End
+			return FreezeStatus{
+				Frozen: true,
+				Reason: fmt.Sprintf("freeze window %s to %s", window.Start.Format(time.RFC3339), window.End.Format(time.RFC3339)),
+				Until:  &until,
+			}
+		}
+	}
+
+	if f.weekdays[now.Weekday()] {
+		// The freeze lasts until the start of the next day that isn't frozen
+		until := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, f.location).AddDate(0, 0, 1)
+		for i := 0; i < 6 && f.weekdays[until.Weekday()]; i++ {
+			until = until.AddDate(0, 0, 1)
+		}
+		return FreezeStatus{
+			Frozen: true,
+			Reason: fmt.Sprintf("no publishing on %s", now.Weekday()),
+			Until:  &until,
+		}
+	}
+
+	return FreezeStatus{}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/ifuryst/ripple/internal/service/notion"
+)
+
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+)
+
+// DependencyStatus is the probe result of a single dependency
+type DependencyStatus struct {
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMs int64     `json:"latency_ms"`
+	CheckedAt time.Time `json:"checked_at"`
+	Cached    bool      `json:"cached,omitempty"`
+}
+
+// ReadinessReport aggregates dependency statuses for load balancers and uptime monitors. The
+// platform credential checks are reported without affecting Status, so a platform with bad
+// credentials doesn't take the service out of rotation.
+type ReadinessReport struct {
+	Status    string                      `json:"status"`
+	Database  DependencyStatus            `json:"database"`
+	Notion    DependencyStatus            `json:"notion"`
+	Platforms map[string]DependencyStatus `json:"platforms"`
+	Time      int64                       `json:"time"`
+	// DatabasePool is the connection pool of the process answering
+	DatabasePool *DatabasePoolStats `json:"database_pool,omitempty"`
+}
+
+// HealthService probes service dependencies for liveness and readiness checks
+type HealthService struct {
+	db               *gorm.DB
+	logger           *zap.Logger
+	notionService    *notion.Service
+	publisherService *PublisherService
+	credentialTTL    time.Duration
+
+	// mu guards the cached credential checks, not the checks themselves
+	mu               sync.Mutex
+	platformCache    map[string]DependencyStatus
+	platformChecked  time.Time
+	platformChecking bool
+}
+
+// NewHealthService creates a new health service; platform credential checks are cached for credentialTTL
+func NewHealthService(db *gorm.DB, logger *zap.Logger, notionService *notion.Service, publisherService *PublisherService, credentialTTL time.Duration) *HealthService {
+	return &HealthService{
+		db:               db,
+		logger:           logger,
+		notionService:    notionService,
+		publisherService: publisherService,
+		credentialTTL:    credentialTTL,
+	}
+}
+
+// CheckReadiness probes the database, Notion API and platform credentials; only the database
+// and Notion decide whether the service is ready
+func (s *HealthService) CheckReadiness(ctx context.Context) *ReadinessReport {
+	report := &ReadinessReport{
+		Status:    HealthStatusUp,
+		Database:  s.probe(func() error { return s.pingDatabase(ctx) }),
+		Notion:    s.probe(func() error { return s.notionService.Ping(ctx) }),
+		Platforms: s.checkPlatforms(ctx),
+		Time:      time.Now().Unix(),
+	}
+	if pool, err := DatabasePool(s.db); err == nil {
+		report.DatabasePool = pool
+	}
+
+	if report.Database.Status != HealthStatusUp || report.Notion.Status != HealthStatusUp {
+		report.Status = HealthStatusDown
+	}
+
+	return report
+}
+
+func (s *HealthService) pingDatabase(ctx context.Context) error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// checkPlatforms returns cached credential results while they are fresh. The checks run without
+// holding the lock; while one request refreshes them, the others get the previous results.
+func (s *HealthService) checkPlatforms(ctx context.Context) map[string]DependencyStatus {
+	s.mu.Lock()
+	fresh := time.Since(s.platformChecked) < s.credentialTTL
+	if s.platformCache != nil && (fresh || s.platformChecking) {
+		cached := make(map[string]DependencyStatus, len(s.platformCache))
+		for name, status := range s.platformCache {
+			status.Cached = true
+			cached[name] = status
+		}
+		s.mu.Unlock()
+		return cached
+	}
+	s.platformChecking = true
+	s.mu.Unlock()
+
+	results := make(map[string]DependencyStatus)
+	for _, platform := range s.publisherService.GetAvailablePlatforms() {
+		results[platform] = s.probe(func() error {
+			return s.publisherService.CheckPlatformCredentials(ctx, platform)
+		})
+		if results[platform].Status != HealthStatusUp {
+			s.logger.Warn("Platform credential check failed",
+				zap.String("platform", platform),
+				zap.String("error", results[platform].Error))
+		}
+	}
+
+	s.mu.Lock()
+	s.platformCache = results
+	s.platformChecked = time.Now()
+	s.platformChecking = false
+	s.mu.Unlock()
+	return results
+}
+
+func (s *HealthService) probe(check func() error) DependencyStatus {
+	start := time.Now()
+	err := check()
+
+	status := DependencyStatus{
+		Status:    HealthStatusUp,
+		LatencyMs: time.Since(start).Milliseconds(),
+		CheckedAt: start,
+	}
+	if err != nil {
+		status.Status = HealthStatusDown
+		status.Error = err.Error()
+	}
+	return status
+}
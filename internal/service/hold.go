@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/notion"
+)
+
+// HeldPage is a page ready to publish that the scheduler holds back, because its Hold checkbox
+// is ticked or it was locked
+type HeldPage struct {
+	PageID     string     `json:"page_id"`
+	Title      string     `json:"title"`
+	Hold       bool       `json:"hold"`
+	LockedAt   *time.Time `json:"locked_at,omitempty"`
+	LockReason string     `json:"lock_reason,omitempty"`
+}
+
+// HeldPages returns the Done pages held back from publishing, most recently edited first
+func (s *PublisherService) HeldPages(ctx context.Context) ([]HeldPage, error) {
+	var pages []models.NotionPage
+	if err := s.db.WithContext(ctx).
+		Select("id", "notion_id", "title", "hold", "locked_at", "lock_reason").
+		Where("status = ?", "Done").
+		Where("archived_at IS NULL").
+		Where("hold = ? OR locked_at IS NOT NULL", true).
+		Order("last_modified DESC").
+		Find(&pages).Error; err != nil {
+		return nil, fmt.Errorf("failed to get held pages: %w", err)
+	}
+
+	held := make([]HeldPage, len(pages))
+	for i, page := range pages {
+		held[i] = HeldPage{
+			PageID:     page.NotionID,
+			Title:      page.Title,
+			Hold:       page.Hold,
+			LockedAt:   page.LockedAt,
+			LockReason: page.LockReason,
+		}
+	}
+	return held, nil
+}
+
+// LockPage holds a page back from scheduled publishing until it is unlocked, whatever its Hold
+// checkbox says. Locking a locked page replaces the reason.
+func (s *PublisherService) LockPage(pageID, reason string) (*models.NotionPage, error) {
+	var page models.NotionPage
+	if err := s.db.Where("notion_id = ?", notion.NormalizePageID(pageID)).First(&page).Error; err != nil {
+		return nil, fmt.Errorf("page not found: %w", err)
+	}
+
+	lockedAt := time.Now()
+	if page.LockedAt != nil {
+		lockedAt = *page.LockedAt
+	}
+	if err := s.db.Model(&page).Updates(map[string]interface{}{
+		"locked_at":   lockedAt,
+		"lock_reason": reason,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to lock page: %w", err)
+	}
+	page.LockedAt = &lockedAt
+	page.LockReason = reason
+	return &page, nil
+}
+
+// UnlockPage releases the lock of a page; a page with its Hold checkbox ticked stays held
+func (s *PublisherService) UnlockPage(pageID string) (*models.NotionPage, error) {
+	var page models.NotionPage
+	if err := s.db.Where("notion_id = ?", notion.NormalizePageID(pageID)).First(&page).Error; err != nil {
+		return nil, fmt.Errorf("page not found: %w", err)
+	}
+
+	if err := s.db.Model(&page).Updates(map[string]interface{}{
+		"locked_at":   nil,
+		"lock_reason": "",
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to unlock page: %w", err)
+	}
+	page.LockedAt = nil
+	page.LockReason = ""
+	return &page, nil
+}
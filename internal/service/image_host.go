@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ifuryst/ripple/internal/content"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/httpclient"
+	"github.com/ifuryst/ripple/pkg/imagehost"
+)
+
+const (
+	// maxHostedImageSize caps the images rehosted on the image host
+	maxHostedImageSize = 20 << 20
+	// maxHostedVideoSize caps the videos rehosted on the image host
+	maxHostedVideoSize = 200 << 20
+	// maxHostedFileSize caps the attachments, such as PDFs and slides, rehosted on the image host
+	maxHostedFileSize = 100 << 20
+	// maxHostedImageCache bounds the remembered uploads; Notion image URLs change on every sync,
+	// so the cache only needs to cover a page being published to several platforms
+	maxHostedImageCache = 1000
+)
+
+// hostedImageExtensions names objects by the detected image type
+var hostedImageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+	"image/bmp":  ".bmp",
+}
+
+// hostedVideoExtensions names video objects by their type; types content sniffing cannot
+// detect are taken from the file extension
+var hostedVideoExtensions = map[string]string{
+	"video/mp4":       ".mp4",
+	"video/webm":      ".webm",
+	"video/quicktime": ".mov",
+	"video/ogg":       ".ogv",
+}
+
+var videoTypesByExtension = map[string]string{
+	".mp4":  "video/mp4",
+	".m4v":  "video/mp4",
+	".webm": "video/webm",
+	".mov":  "video/quicktime",
+	".ogv":  "video/ogg",
+}
+
+// imageHostHook rehosts the images, video files and attachments of a post on our own image host
+// before it is transformed, so every output references stable CDN URLs instead of Notion's
+// expiring ones. Objects are named by their content hash, so a file is stored once however often
+// it is published; attachments keep their file name after the hash, which downloads are saved as.
+type imageHostHook struct {
+	uploader  imagehost.Uploader
+	platforms map[string]bool
+	client    *http.Client
+
+	mu     sync.Mutex
+	hosted map[string]string
+}
+
+func newImageHostHook(uploader imagehost.Uploader, platforms []string) *imageHostHook {
+	enabled := make(map[string]bool)
+	for _, platform := range platforms {
+		if platform = strings.TrimSpace(platform); platform != "" {
+			enabled[platform] = true
+		}
+	}
+
+	return &imageHostHook{
+		uploader:  uploader,
+		platforms: enabled,
+		client:    httpclient.New(httpclient.WithTimeout(time.Minute)),
+		hosted:    make(map[string]string),
+	}
+}
+
+func (h *imageHostHook) Name() string {
+	return "image_host"
+}
+
+func (h *imageHostHook) Stages() []publisher.HookStage {
+	return []publisher.HookStage{publisher.HookPreTransform}
+}
+
+func (h *imageHostHook) Run(ctx context.Context, event *publisher.HookEvent) (string, error) {
+	if len(h.platforms) > 0 && !h.platforms[event.Platform] && !h.platforms[publisher.PlatformType(event.Platform)] {
+		return "", nil
+	}
+
+	post := event.Content
+	doc, err := post.ContentDocument()
+	if err != nil {
+		return "", nil
+	}
+
+	// Files that cannot be rehosted keep their original URL rather than failing the publish
+	rehosted, rehostedVideos, rehostedFiles := 0, 0, 0
+	var failures []string
+	rehost := func(source string) string {
+		hostedURL, err := h.rehost(ctx, source, false)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s (%v)", source, err))
+			return source
+		}
+		if hostedURL != source {
+			rehosted++
+		}
+		return hostedURL
+	}
+	rehostVideo := func(source string) string {
+		// Links to video sites are embedded as they are
+		if !(&content.Video{URL: source}).IsFile() {
+			return source
+		}
+		hostedURL, err := h.rehost(ctx, source, true)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s (%v)", source, err))
+			return source
+		}
+		if hostedURL != source {
+			rehostedVideos++
+		}
+		return hostedURL
+	}
+
+	files := make(map[string]*content.File)
+	for _, file := range doc.Files() {
+		files[file.URL] = file
+	}
+	rehostFile := func(source string) string {
+		hostedURL, err := h.rehostFile(ctx, files[source])
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s (%v)", source, err))
+			return source
+		}
+		if hostedURL != source {
+			rehostedFiles++
+		}
+		return hostedURL
+	}
+
+	doc.MapImages(rehost)
+	doc.MapVideos(rehostVideo)
+	doc.MapFiles(rehostFile)
+	post.Document = doc
+	if cover := post.Metadata["cover_url"]; cover != "" {
+		post.Metadata["cover_url"] = rehost(cover)
+	}
+
+	var report []string
+	if rehosted > 0 {
+		report = append(report, fmt.Sprintf("rehosted %d images on %s", rehosted, h.uploader.Name()))
+	}
+	if rehostedVideos > 0 {
+		report = append(report, fmt.Sprintf("rehosted %d videos on %s", rehostedVideos, h.uploader.Name()))
+	}
+	if rehostedFiles > 0 {
+		report = append(report, fmt.Sprintf("rehosted %d attachments on %s", rehostedFiles, h.uploader.Name()))
+	}
+	if len(failures) > 0 {
+		report = append(report, fmt.Sprintf("failed to rehost %d files: %s", len(failures), strings.Join(failures, "; ")))
+	}
+	return strings.Join(report, "; "), nil
+}
+
+// rehost uploads an image or video from a URL or a local file and returns its hosted URL
+func (h *imageHostHook) rehost(ctx context.Context, source string, video bool) (string, error) {
+	if hostedURL, ok := h.cached(source); ok {
+		return hostedURL, nil
+	}
+
+	maxSize := maxHostedImageSize
+	if video {
+		maxSize = maxHostedVideoSize
+	}
+	data, err := h.load(ctx, source, maxSize)
+	if err != nil {
+		return "", err
+	}
+
+	contentType := http.DetectContentType(data)
+	sourceExt := strings.ToLower(filepath.Ext(strings.SplitN(source, "?", 2)[0]))
+	folder := "images"
+	var extension string
+	var ok bool
+	if video {
+		folder = "videos"
+		if _, ok := hostedVideoExtensions[contentType]; !ok {
+			contentType = videoTypesByExtension[sourceExt]
+		}
+		if extension, ok = hostedVideoExtensions[contentType]; !ok {
+			return "", fmt.Errorf("unsupported video type %s", sourceExt)
+		}
+	} else if extension, ok = hostedImageExtensions[contentType]; !ok {
+		if sourceExt == ".svg" {
+			contentType, extension = "image/svg+xml", ".svg"
+		} else {
+			return "", fmt.Errorf("unsupported image type %s", contentType)
+		}
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	hostedURL, err := h.uploader.Upload(ctx, fmt.Sprintf("%s/%s/%s%s", folder, hash[:2], hash, extension), contentType, data)
+	if err != nil {
+		return "", err
+	}
+	h.remember(source, hostedURL)
+	return hostedURL, nil
+}
+
+// rehostFile uploads an attachment and returns its hosted URL. Any type of file is accepted;
+// the content type comes from the file extension, or the content when the extension is unknown.
+func (h *imageHostHook) rehostFile(ctx context.Context, file *content.File) (string, error) {
+	if hostedURL, ok := h.cached(file.URL); ok {
+		return hostedURL, nil
+	}
+
+	data, err := h.load(ctx, file.URL, maxHostedFileSize)
+	if err != nil {
+		return "", err
+	}
+	contentType := mime.TypeByExtension(file.Ext())
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	key := fmt.Sprintf("files/%s/%s/%s", hash[:2], hash, publisher.AttachmentFileName(file))
+	hostedURL, err := h.uploader.Upload(ctx, key, contentType, data)
+	if err != nil {
+		return "", err
+	}
+	h.remember(file.URL, hostedURL)
+	return hostedURL, nil
+}
+
+func (h *imageHostHook) cached(source string) (string, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hostedURL, ok := h.hosted[source]
+	return hostedURL, ok
+}
+
+func (h *imageHostHook) remember(source, hostedURL string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.hosted) >= maxHostedImageCache {
+		h.hosted = make(map[string]string)
+	}
+	h.hosted[source] = hostedURL
+}
+
+// load reads a file of at most maxSize bytes from an http(s) URL or, for generated cover
+// cards, a local path
+func (h *imageHostHook) load(ctx context.Context, source string, maxSize int) ([]byte, error) {
+	if filepath.IsAbs(source) {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image: %w", err)
+		}
+		return data, nil
+	}
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return nil, fmt.Errorf("unsupported image location")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("image download returned status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxSize)+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %w", err)
+	}
+	if len(data) > maxSize {
+		return nil, fmt.Errorf("file is larger than %d MB", maxSize>>20)
+	}
+	return data, nil
+}
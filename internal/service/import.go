@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/util"
+)
+
+// Outcomes of matching an existing post to a page
+const (
+	ImportImported         = "imported"
+	ImportWouldImport      = "would_import"
+	ImportAlreadyPublished = "already_published"
+)
+
+// ImportRequest selects the platforms to import existing posts from
+type ImportRequest struct {
+	// Platforms defaults to every platform that can list its posts
+	Platforms []string `json:"platforms"`
+	// DryRun reports the matches without recording anything
+	DryRun bool `json:"dry_run"`
+}
+
+// ImportMatch is an existing post matched to a page
+type ImportMatch struct {
+	Platform  string `json:"platform"`
+	PageID    string `json:"page_id"`
+	Title     string `json:"title"`
+	PublishID string `json:"publish_id"`
+	URL       string `json:"url,omitempty"`
+	Draft     bool   `json:"draft"`
+	// MatchedBy is title or slug
+	MatchedBy string `json:"matched_by"`
+	Outcome   string `json:"outcome"`
+}
+
+// UnmatchedPost is an existing post no single page matched
+type UnmatchedPost struct {
+	Platform  string `json:"platform"`
+	Title     string `json:"title"`
+	PublishID string `json:"publish_id"`
+	URL       string `json:"url,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+// ImportReport summarizes an import of existing posts
+type ImportReport struct {
+	DryRun    bool              `json:"dry_run"`
+	Scanned   map[string]int    `json:"scanned"`
+	Imported  int               `json:"imported"`
+	Matches   []ImportMatch     `json:"matches"`
+	Unmatched []UnmatchedPost   `json:"unmatched"`
+	Errors    map[string]string `json:"errors,omitempty"`
+}
+
+// pageIndex finds pages by normalized title and by slug
+type pageIndex struct {
+	byTitle map[string][]*models.NotionPage
+	bySlug  map[string][]*models.NotionPage
+}
+
+// ImportExistingPosts matches the posts already on the platforms to pages by title, then by
+// slug, and records them as completed jobs so the pages aren't published there again. Posts
+// matching no page, or several, are reported and left alone.
+func (s *PublisherService) ImportExistingPosts(ctx context.Context, request ImportRequest) (*ImportReport, error) {
+	platforms := request.Platforms
+	explicit := len(platforms) > 0
+	if !explicit {
+		platforms = s.GetAvailablePlatforms()
+	}
+	sort.Strings(platforms)
+
+	var pages []models.NotionPage
+	if err := s.db.WithContext(ctx).
+		Select("id", "notion_id", "title", "en_title", "status", "platforms").
+		Find(&pages).Error; err != nil {
+		return nil, fmt.Errorf("failed to get pages: %w", err)
+	}
+	index := buildPageIndex(pages)
+
+	status, err := s.latestJobStatuses(ctx, pages)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{
+		DryRun:    request.DryRun,
+		Scanned:   make(map[string]int),
+		Matches:   []ImportMatch{},
+		Unmatched: []UnmatchedPost{},
+		Errors:    make(map[string]string),
+	}
+	imported := make(map[uint]*models.NotionPage)
+
+	for _, platformName := range platforms {
+		platformName = strings.TrimSpace(platformName)
+		if platformName == "" {
+			continue
+		}
+
+		posts, err := s.manager.ListExistingPosts(ctx, platformName)
+		if err != nil {
+			if errors.Is(err, publisher.ErrListingNotSupported) && !explicit {
+				continue
+			}
+			report.Errors[platformName] = err.Error()
+			s.logger.Error("Failed to list existing posts", zap.String("platform", platformName), zap.Error(err))
+			continue
+		}
+		report.Scanned[platformName] = len(posts)
+
+		// Published posts win over drafts of the same page
+		sort.SliceStable(posts, func(i, j int) bool {
+			return !posts[i].Draft && posts[j].Draft
+		})
+
+		for _, post := range posts {
+			page, matchedBy, reason := index.match(post)
+			if page == nil {
+				report.Unmatched = append(report.Unmatched, UnmatchedPost{
+					Platform:  platformName,
+					Title:     post.Title,
+					PublishID: post.PublishID,
+					URL:       post.URL,
+					Reason:    reason,
+				})
+				continue
+			}
+
+			match := ImportMatch{
+				Platform:  platformName,
+				PageID:    page.NotionID,
+				Title:     page.Title,
+				PublishID: post.PublishID,
+				URL:       post.URL,
+				Draft:     post.Draft,
+				MatchedBy: matchedBy,
+			}
+
+			switch status[page.ID][platformName] {
+			case models.JobInProgress, models.JobDraft, models.JobCompleted, models.JobQuarantined:
+				match.Outcome = ImportAlreadyPublished
+			default:
+				if request.DryRun {
+					match.Outcome = ImportWouldImport
+					break
+				}
+				_, created, err := s.manager.ImportPost(page, platformName, post)
+				if err != nil {
+					report.Errors[fmt.Sprintf("%s/%s", platformName, post.PublishID)] = err.Error()
+					continue
+				}
+				match.Outcome = ImportAlreadyPublished
+				if created {
+					match.Outcome = ImportImported
+					report.Imported++
+					imported[page.ID] = page
+				}
+			}
+
+			// Later posts matching the same page, e.g. its draft, see it as published
+			if status[page.ID] == nil {
+				status[page.ID] = make(map[string]string)
+			}
+			status[page.ID][platformName] = models.JobCompleted
+			report.Matches = append(report.Matches, match)
+		}
+	}
+
+	// Pages done on every platform now are marked published like after a regular publish
+	for _, page := range imported {
+		var full models.NotionPage
+		if err := s.db.WithContext(ctx).First(&full, page.ID).Error; err == nil {
+			s.markPublishedIfCompleted(ctx, &full)
+		}
+	}
+
+	s.logger.Info("Imported existing posts",
+		zap.Bool("dry_run", request.DryRun),
+		zap.Int("matched", len(report.Matches)),
+		zap.Int("imported", report.Imported),
+		zap.Int("unmatched", len(report.Unmatched)))
+	return report, nil
+}
+
+func buildPageIndex(pages []models.NotionPage) *pageIndex {
+	index := &pageIndex{
+		byTitle: make(map[string][]*models.NotionPage),
+		bySlug:  make(map[string][]*models.NotionPage),
+	}
+
+	for i := range pages {
+		page := &pages[i]
+		titles := map[string]bool{}
+		slugs := map[string]bool{}
+		for _, title := range []string{page.Title, page.ENTitle} {
+			if key := normalizeTitle(title); key != "" {
+				titles[key] = true
+			}
+			if slug := util.GenerateSlug(title); slug != "" {
+				slugs[slug] = true
+			}
+		}
+		// CJK titles are published under their transliterated slug
+		if slug := util.GenerateSlug(util.Transliterate(page.Title)); slug != "" {
+			slugs[slug] = true
+		}
+
+		for key := range titles {
+			index.byTitle[key] = append(index.byTitle[key], page)
+		}
+		for slug := range slugs {
+			index.bySlug[slug] = append(index.bySlug[slug], page)
+		}
+	}
+	return index
+}
+
+// match returns the single page a post belongs to and how it was matched, or why none was
+func (i *pageIndex) match(post publisher.ExistingPost) (*models.NotionPage, string, string) {
+	if candidates := i.byTitle[normalizeTitle(post.Title)]; len(candidates) == 1 {
+		return candidates[0], "title", ""
+	} else if len(candidates) > 1 {
+		return nil, "", fmt.Sprintf("title matches %d pages", len(candidates))
+	}
+
+	// Slugs are compared after the same normalization, which also cuts long platform slugs
+	if slug := util.GenerateSlug(post.Slug); slug != "" {
+		if candidates := i.bySlug[slug]; len(candidates) == 1 {
+			return candidates[0], "slug", ""
+		} else if len(candidates) > 1 {
+			return nil, "", fmt.Sprintf("slug matches %d pages", len(candidates))
+		}
+	}
+	return nil, "", "no page with this title or slug"
+}
+
+// normalizeTitle ignores case and whitespace differences between titles
+func normalizeTitle(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
+}
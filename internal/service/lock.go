@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"hash/fnv"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Names of the advisory locks coordinating the replicas sharing a database
+const (
+	LockMigrate     = "migrate"
+	LockSync        = "sync"
+	LockPublish     = "publish"
+	LockStats       = "stats"
+	LockCredentials = "credential_check"
+	LockDeployments = "deployment_check"
+	LockPostMetrics = "post_metrics"
+	LockRetention   = "retention"
+)
+
+// ErrLockHeld is returned when the work guarded by a lock is already running on another replica
+var ErrLockHeld = errors.New("already running on another replica")
+
+// Locker coordinates the replicas sharing a database with Postgres advisory locks, so the
+// syncs, the scheduled publishing and the periodic checks run on one replica at a time. Locks
+// belong to a database session, so those of a replica that dies are released with its
+// connections.
+type Locker struct {
+	db      *gorm.DB
+	logger  *zap.Logger
+	enabled bool
+}
+
+// NewLocker creates a locker; a disabled locker runs everything without locking, for single
+// replicas behind a connection pooler that doesn't keep sessions
+func NewLocker(db *gorm.DB, logger *zap.Logger, enabled bool) *Locker {
+	return &Locker{
+		db:      db,
+		logger:  logger,
+		enabled: enabled,
+	}
+}
+
+// lockKey maps a lock name to the key of its advisory lock
+func lockKey(name string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte("ripple:" + name))
+	return int64(hash.Sum64())
+}
+
+// TryRun runs fn while holding the named lock, or returns ErrLockHeld without running it when
+// another replica holds the lock
+func (l *Locker) TryRun(ctx context.Context, name string, fn func() error) error {
+	unlock, err := l.acquire(ctx, name, false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn()
+}
+
+// Run runs fn while holding the named lock, waiting for other replicas to release it first
+func (l *Locker) Run(ctx context.Context, name string, fn func() error) error {
+	unlock, err := l.acquire(ctx, name, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn()
+}
+
+// acquire takes the named lock on a connection of its own, which is kept out of the pool until
+// the returned function releases the lock
+func (l *Locker) acquire(ctx context.Context, name string, wait bool) (func(), error) {
+	if l == nil || !l.enabled {
+		return func() {}, nil
+	}
+
+	sqlDB, err := l.db.DB()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a connection for lock %s: %w", name, err)
+	}
+
+	key := lockKey(name)
+	if wait {
+		_, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", key)
+	} else {
+		acquired := false
+		err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired)
+		if err == nil && !acquired {
+			conn.Close()
+			return nil, fmt.Errorf("%s: %w", name, ErrLockHeld)
+		}
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to take lock %s: %w", name, err)
+	}
+
+	return func() {
+		// The unlock isn't cancelled with the work it guarded
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key); err != nil {
+			l.logger.Warn("Failed to release lock, closing its connection", zap.String("lock", name), zap.Error(err))
+			// Discard the connection instead of returning it to the pool still holding the lock
+			conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}, nil
+}
+
+// lockRound takes the named lock for a round of periodic work, reporting false when the round
+// is to be skipped because another replica is running it or the lock couldn't be taken
+func (l *Locker) lockRound(ctx context.Context, name string, logger *zap.Logger) (func(), bool) {
+	unlock, err := l.acquire(ctx, name, false)
+	switch {
+	case errors.Is(err, ErrLockHeld):
+		logger.Debug("Skipping round, another replica is running it", zap.String("lock", name))
+		return nil, false
+	case err != nil:
+		logger.Error("Failed to take lock, skipping round", zap.String("lock", name), zap.Error(err))
+		return nil, false
+	}
+	return unlock, true
+}
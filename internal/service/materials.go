@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// ErrMaterialInUse is returned when deleting the material configured as the default thumb
+var ErrMaterialInUse = errors.New("material is the configured default_thumb_media_id")
+
+// MaterialEntry is a material with what Ripple knows about it, to tell leftover uploads from
+// media still in use
+type MaterialEntry struct {
+	publisher.Material
+	// DefaultThumb marks the material configured as default_thumb_media_id
+	DefaultThumb bool `json:"default_thumb"`
+	// Cached marks media Ripple uploaded while publishing and reuses for the same image;
+	// LastUsedAt is when a publish last used it
+	Cached     bool       `json:"cached"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// MaterialLibrary is one page of the material library of a platform
+type MaterialLibrary struct {
+	Platform string          `json:"platform"`
+	Type     string          `json:"type"`
+	Total    int             `json:"total"`
+	Offset   int             `json:"offset"`
+	Items    []MaterialEntry `json:"items"`
+}
+
+// ListMaterials returns a page of the material library of a platform, marking the default thumb
+// and the media Ripple uploaded itself
+func (s *PublisherService) ListMaterials(ctx context.Context, platformName, materialType string, offset, count int) (*MaterialLibrary, error) {
+	config, err := s.manager.GetPlatformConfig(platformName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrPlatformNotAvailable, platformName)
+	}
+
+	list, err := s.manager.ListMaterials(ctx, platformName, materialType, offset, count)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaIDs := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		mediaIDs = append(mediaIDs, item.MediaID)
+	}
+	var assets []models.MediaAsset
+	if len(mediaIDs) > 0 {
+		if err := s.db.WithContext(ctx).
+			Where("account = ? AND media_id IN ?", config.Config["app_id"], mediaIDs).
+			Find(&assets).Error; err != nil {
+			return nil, fmt.Errorf("failed to load uploaded media: %w", err)
+		}
+	}
+	lastUsed := make(map[string]time.Time, len(assets))
+	for _, asset := range assets {
+		if asset.LastUsedAt.After(lastUsed[asset.MediaID]) {
+			lastUsed[asset.MediaID] = asset.LastUsedAt
+		}
+	}
+
+	library := &MaterialLibrary{
+		Platform: platformName,
+		Type:     list.Type,
+		Total:    list.Total,
+		Offset:   list.Offset,
+		Items:    make([]MaterialEntry, 0, len(list.Items)),
+	}
+	defaultThumb := config.Config["default_thumb_media_id"]
+	for _, item := range list.Items {
+		entry := MaterialEntry{Material: item, DefaultThumb: defaultThumb != "" && item.MediaID == defaultThumb}
+		if usedAt, ok := lastUsed[item.MediaID]; ok {
+			entry.Cached = true
+			entry.LastUsedAt = &usedAt
+		}
+		library.Items = append(library.Items, entry)
+	}
+	return library, nil
+}
+
+// UploadMaterial uploads a file to the material library of a platform, e.g. an image to use as
+// default_thumb_media_id. The name is kept as the file name the platform shows.
+func (s *PublisherService) UploadMaterial(ctx context.Context, platformName, materialType, name string, file io.Reader) (*publisher.Material, error) {
+	if _, err := s.manager.GetPlatformConfig(platformName); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrPlatformNotAvailable, platformName)
+	}
+
+	dir, err := os.MkdirTemp("", "ripple-material-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	name = filepath.Base(name)
+	if name == "." || name == string(filepath.Separator) {
+		name = "material"
+	}
+	filePath := filepath.Join(dir, name)
+	if err := writeMaterialFile(filePath, file); err != nil {
+		return nil, err
+	}
+
+	material, err := s.manager.UploadMaterial(ctx, platformName, materialType, filePath)
+	if err != nil {
+		return nil, err
+	}
+	material.Name = name
+
+	s.logger.Info("Uploaded material",
+		zap.String("platform", platformName),
+		zap.String("type", materialType),
+		zap.String("media_id", material.MediaID))
+	return material, nil
+}
+
+// DeleteMaterial removes media from the material library of a platform and forgets it as an
+// earlier upload, so later publishes upload the image again instead of referencing it. The
+// default thumb is only deleted with force, since articles without an image need it.
+func (s *PublisherService) DeleteMaterial(ctx context.Context, platformName, mediaID string, force bool) error {
+	config, err := s.manager.GetPlatformConfig(platformName)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrPlatformNotAvailable, platformName)
+	}
+	if !force && mediaID == config.Config["default_thumb_media_id"] {
+		return fmt.Errorf("%w: %s", ErrMaterialInUse, mediaID)
+	}
+
+	if err := s.manager.DeleteMaterial(ctx, platformName, mediaID); err != nil {
+		return err
+	}
+
+	result := s.db.WithContext(ctx).
+		Where("account = ? AND media_id = ?", config.Config["app_id"], mediaID).
+		Delete(&models.MediaAsset{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to forget deleted media: %w", result.Error)
+	}
+
+	s.logger.Info("Deleted material",
+		zap.String("platform", platformName),
+		zap.String("media_id", mediaID),
+		zap.Int64("cached_uploads", result.RowsAffected))
+	return nil
+}
+
+// writeMaterialFile copies an upload to a local file for the publisher to send
+func writeMaterialFile(filePath string, file io.Reader) error {
+	out, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create material file: %w", err)
+	}
+	if _, err := io.Copy(out, file); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to write material file: %w", err)
+	}
+	return out.Close()
+}
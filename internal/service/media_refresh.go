@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// ErrJobNotRefreshable is returned when refreshing the media of a job without a live draft or post
+var ErrJobNotRefreshable = errors.New("job media cannot be refreshed")
+
+// RefreshJobMedia re-syncs the page of a job from Notion, whose image links expire, and points
+// the images of the job's draft or post at new uploads of them. Unlike a republish the text is
+// left alone and no new publication is created, so it also works for pages edited since.
+func (s *PublisherService) RefreshJobMedia(ctx context.Context, jobID uint) (*models.DistributionJob, *publisher.MediaRefresh, error) {
+	var job models.DistributionJob
+	if err := s.db.Preload("Page").Preload("Platform").First(&job, jobID).Error; err != nil {
+		return nil, nil, err
+	}
+	if job.Status != models.JobCompleted && job.Status != models.JobDraft {
+		return nil, nil, fmt.Errorf("%w: job %d is %s", ErrJobNotRefreshable, job.ID, job.Status)
+	}
+	if job.Page.NotionID == "" || job.Platform.Name == "" {
+		return nil, nil, fmt.Errorf("%w: job %d has no associated page or platform", ErrJobNotRefreshable, job.ID)
+	}
+	if job.PublishID == "" {
+		return nil, nil, fmt.Errorf("%w: job %d has no draft or post", ErrJobNotRefreshable, job.ID)
+	}
+
+	// Fetch the page again for image links that have not expired; other sources keep theirs
+	if job.Page.IsFromNotion() {
+		if _, _, err := s.notionService.SyncPage(job.Page.NotionID, true); err != nil {
+			return nil, nil, fmt.Errorf("failed to re-sync page from Notion: %w", err)
+		}
+		if err := s.db.First(&job.Page, job.PageID).Error; err != nil {
+			return nil, nil, fmt.Errorf("page not found after sync: %w", err)
+		}
+	}
+
+	s.logger.Info("Refreshing job media",
+		zap.Uint("job_id", job.ID),
+		zap.String("page_id", job.Page.NotionID),
+		zap.String("platform", job.Platform.Name),
+		zap.String("publish_id", job.PublishID))
+
+	refresh, err := s.manager.RefreshMedia(ctx, &job)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to refresh media on %s: %w", job.Platform.Name, err)
+	}
+	return &job, refresh, nil
+}
@@ -1,14 +1,29 @@
 package service
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
+	"github.com/ifuryst/ripple/internal/config"
+	"github.com/ifuryst/ripple/internal/events"
 	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/requestid"
+)
+
+// 错误类型
+const (
+	ErrorTypeCredentialsExpired = "credentials_expired"
+	ErrorTypeQuarantined        = "quarantined"
 )
 
 type MonitoringService struct {
@@ -37,7 +52,19 @@ func (m *MonitoringService) RecordError(level, source, title, message string, op
 		option(errorLog)
 	}
 
-	return m.db.Create(errorLog).Error
+	// 相同指纹的错误归入同一个问题，更新出现次数和最后出现时间
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := upsertIssue(tx, errorLog, time.Now()); err != nil {
+			return err
+		}
+		return tx.Create(errorLog).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	events.Publish(events.ErrorLogged, errorLog)
+	return nil
 }
 
 // ErrorLogOption 错误日志选项
@@ -64,6 +91,44 @@ func WithJob(jobID uint) ErrorLogOption {
 	}
 }
 
+// WithRequest 设置触发错误的 API 请求 ID，不在请求中时不设置
+func WithRequest(ctx context.Context) ErrorLogOption {
+	return func(e *models.ErrorLog) {
+		e.RequestID = requestid.From(ctx)
+	}
+}
+
+// WithErrorType 设置错误类型
+func WithErrorType(errorType string) ErrorLogOption {
+	return func(e *models.ErrorLog) {
+		e.ErrorType = errorType
+	}
+}
+
+// WithErrorCode 设置发布失败的错误码
+func WithErrorCode(code publisher.ErrorCode) ErrorLogOption {
+	return func(e *models.ErrorLog) {
+		e.ErrorCode = string(code)
+	}
+}
+
+// publishErrorLevel 根据错误码决定发布失败的告警级别：限流、网络和平台 5xx 等暂时性故障会自动重试，
+// 记为 WARN；凭证过期、内容无效等需要人工处理的记为 ERROR
+func publishErrorLevel(err error) string {
+	if publisher.CodeOf(err).Transient() {
+		return "WARN"
+	}
+	return "ERROR"
+}
+
+// errorTypeOf 根据错误内容识别错误类型
+func errorTypeOf(err error) string {
+	if errors.Is(err, publisher.ErrCredentialsExpired) {
+		return ErrorTypeCredentialsExpired
+	}
+	return ""
+}
+
 // WithStackTrace 设置堆栈信息
 func WithStackTrace(stackTrace string) ErrorLogOption {
 	return func(e *models.ErrorLog) {
@@ -80,55 +145,153 @@ func WithContext(context map[string]interface{}) ErrorLogOption {
 	}
 }
 
+// jobCounts 一次查询得到的任务数量
+type jobCounts struct {
+	Total      int64
+	Successful int64
+	Failed     int64
+	Pending    int64
+}
+
+// jobCountColumns 按状态统计任务数量的列，每张表只扫描一次
+const jobCountColumns = "COUNT(*) AS total, " +
+	"COUNT(*) FILTER (WHERE status = 'completed') AS successful, " +
+	"COUNT(*) FILTER (WHERE status = 'failed') AS failed, " +
+	"COUNT(*) FILTER (WHERE status = 'pending') AS pending"
+
+// platformCounts 平台总数和启用的平台数
+type platformCounts struct {
+	Total  int64
+	Active int64
+}
+
+func (m *MonitoringService) countPlatforms() (platformCounts, error) {
+	var counts platformCounts
+	err := m.db.Model(&models.Platform{}).
+		Select("COUNT(*) AS total, COUNT(*) FILTER (WHERE enabled) AS active").
+		Scan(&counts).Error
+	return counts, err
+}
+
 // UpdateSystemStats 更新系统统计数据
 func (m *MonitoringService) UpdateSystemStats() error {
 	today := time.Now().Truncate(24 * time.Hour)
 
-	var stats models.SystemStats
-	result := m.db.Where("date = ?", today).First(&stats)
-
 	// 查询各种统计数据
 	var totalPages int64
-	m.db.Model(&models.NotionPage{}).Count(&totalPages)
+	if err := m.db.Model(&models.NotionPage{}).Count(&totalPages).Error; err != nil {
+		return fmt.Errorf("failed to count pages: %w", err)
+	}
 
-	var totalJobs, successfulJobs, failedJobs, pendingJobs int64
-	m.db.Model(&models.DistributionJob{}).Count(&totalJobs)
-	m.db.Model(&models.DistributionJob{}).Where("status = ?", "completed").Count(&successfulJobs)
-	m.db.Model(&models.DistributionJob{}).Where("status = ?", "failed").Count(&failedJobs)
-	m.db.Model(&models.DistributionJob{}).Where("status = ?", "pending").Count(&pendingJobs)
+	var jobs jobCounts
+	if err := m.db.Model(&models.DistributionJob{}).Select(jobCountColumns).Scan(&jobs).Error; err != nil {
+		return fmt.Errorf("failed to count jobs: %w", err)
+	}
 
-	var totalPlatforms, activePlatforms int64
-	m.db.Model(&models.Platform{}).Count(&totalPlatforms)
-	m.db.Model(&models.Platform{}).Where("enabled = ?", true).Count(&activePlatforms)
+	platforms, err := m.countPlatforms()
+	if err != nil {
+		return fmt.Errorf("failed to count platforms: %w", err)
+	}
 
+	var stats models.SystemStats
+	result := m.db.Where("date = ?", today).First(&stats)
 	if result.Error == gorm.ErrRecordNotFound {
 		// 创建新记录
 		stats = models.SystemStats{
 			Date:                  today,
 			TotalNotionPages:      int(totalPages),
-			TotalDistributionJobs: int(totalJobs),
-			SuccessfulJobs:        int(successfulJobs),
-			FailedJobs:            int(failedJobs),
-			PendingJobs:           int(pendingJobs),
-			TotalPlatforms:        int(totalPlatforms),
-			ActivePlatforms:       int(activePlatforms),
+			TotalDistributionJobs: int(jobs.Total),
+			SuccessfulJobs:        int(jobs.Successful),
+			FailedJobs:            int(jobs.Failed),
+			PendingJobs:           int(jobs.Pending),
+			TotalPlatforms:        int(platforms.Total),
+			ActivePlatforms:       int(platforms.Active),
 		}
 		return m.db.Create(&stats).Error
 	} else {
 		// 更新现有记录
 		return m.db.Model(&stats).Updates(map[string]interface{}{
 			"total_notion_pages":      totalPages,
-			"total_distribution_jobs": totalJobs,
-			"successful_jobs":         successfulJobs,
-			"failed_jobs":             failedJobs,
-			"pending_jobs":            pendingJobs,
-			"total_platforms":         totalPlatforms,
-			"active_platforms":        activePlatforms,
+			"total_distribution_jobs": jobs.Total,
+			"successful_jobs":         jobs.Successful,
+			"failed_jobs":             jobs.Failed,
+			"pending_jobs":            jobs.Pending,
+			"total_platforms":         platforms.Total,
+			"active_platforms":        platforms.Active,
 		}).Error
 	}
 }
 
-// UpdatePlatformStats 更新平台统计数据
+// processTimeFilter 选出今日完成的任务，处理时间从任务开始执行到结束
+const processTimeFilter = "status = 'completed' AND started_at IS NOT NULL AND finished_at >= @today"
+
+// processTimeColumns 统计处理时间的平均值和分位数(秒)的列，没有完成的任务时为空，需要传入 today 参数
+func processTimeColumns() string {
+	const duration = "EXTRACT(EPOCH FROM finished_at - started_at)"
+	return fmt.Sprintf("AVG(%[1]s) FILTER (WHERE %[2]s) AS avg_process_time, "+
+		"percentile_cont(0.5) WITHIN GROUP (ORDER BY %[1]s) FILTER (WHERE %[2]s) AS p50_process_time, "+
+		"percentile_cont(0.95) WITHIN GROUP (ORDER BY %[1]s) FILTER (WHERE %[2]s) AS p95_process_time",
+		duration, processTimeFilter)
+}
+
+// publishStages 分别统计平均耗时的发布阶段
+var publishStages = []string{models.StageTransform, models.StageMedia, models.StageUpload, models.StagePublish}
+
+// stageDurationColumns 统计各阶段平均耗时(毫秒)的列，列名为 avg_<阶段>_ms
+func stageDurationColumns() string {
+	columns := make([]string, len(publishStages))
+	for i, stage := range publishStages {
+		columns[i] = fmt.Sprintf("AVG(CAST(stage_durations->>'%[1]s' AS numeric)) FILTER (WHERE %[2]s) AS avg_%[1]s_ms",
+			stage, processTimeFilter)
+	}
+	return strings.Join(columns, ", ")
+}
+
+// platformJobStats 按平台分组统计的任务数据
+type platformJobStats struct {
+	PlatformID     uint
+	Total          int64
+	Successful     int64
+	Failed         int64
+	Pending        int64
+	AvgProcessTime *float64
+	P50ProcessTime *float64
+	P95ProcessTime *float64
+	LastSuccessAt  *time.Time
+	LastFailureAt  *time.Time
+
+	AvgTransformMs *float64
+	AvgMediaMs     *float64
+	AvgUploadMs    *float64
+	AvgPublishMs   *float64
+}
+
+// stageDurations 返回有数据的阶段的平均耗时
+func (s platformJobStats) stageDurations() models.StageDurations {
+	durations := models.StageDurations{}
+	for stage, avg := range map[string]*float64{
+		models.StageTransform: s.AvgTransformMs,
+		models.StageMedia:     s.AvgMediaMs,
+		models.StageUpload:    s.AvgUploadMs,
+		models.StagePublish:   s.AvgPublishMs,
+	} {
+		if avg != nil {
+			durations[stage] = int64(*avg)
+		}
+	}
+	return durations
+}
+
+// orZero 返回指针指向的值，空指针返回 0
+func orZero(value *float64) float64 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+// UpdatePlatformStats 更新平台统计数据。任务、错误日志和已有的统计记录各用一条分组查询读取，
+// 查询次数不随平台数量增加
 func (m *MonitoringService) UpdatePlatformStats() error {
 	today := time.Now().Truncate(24 * time.Hour)
 
@@ -136,138 +299,184 @@ func (m *MonitoringService) UpdatePlatformStats() error {
 	if err := m.db.Find(&platforms).Error; err != nil {
 		return err
 	}
+	if len(platforms) == 0 {
+		return nil
+	}
 
-	for _, platform := range platforms {
-		var stats models.PlatformStats
-		result := m.db.Where("date = ? AND platform_id = ?", today, platform.ID).First(&stats)
-
-		// 查询平台相关统计
-		var totalJobs, successfulJobs, failedJobs, pendingJobs int64
-		m.db.Model(&models.DistributionJob{}).Where("platform_id = ?", platform.ID).Count(&totalJobs)
-		m.db.Model(&models.DistributionJob{}).Where("platform_id = ? AND status = ?", platform.ID, "completed").Count(&successfulJobs)
-		m.db.Model(&models.DistributionJob{}).Where("platform_id = ? AND status = ?", platform.ID, "failed").Count(&failedJobs)
-		m.db.Model(&models.DistributionJob{}).Where("platform_id = ? AND status = ?", platform.ID, "pending").Count(&pendingJobs)
-
-		// 计算平均处理时间 (这里需要根据实际业务逻辑调整)
-		var avgProcessTime float64
-		// TODO: 实现平均处理时间计算
-
-		// 获取最后成功和失败时间
-		var lastSuccessJob, lastFailureJob models.DistributionJob
-		m.db.Where("platform_id = ? AND status = ?", platform.ID, "completed").Order("published_at desc").First(&lastSuccessJob)
-		m.db.Where("platform_id = ? AND status = ?", platform.ID, "failed").Order("updated_at desc").First(&lastFailureJob)
-
-		// 计算错误数量
-		var errorCount int64
-		m.db.Model(&models.ErrorLog{}).Where("platform_name = ? AND created_at >= ?", platform.Name, today).Count(&errorCount)
-
-		if result.Error == gorm.ErrRecordNotFound {
-			// 创建新记录
-			stats = models.PlatformStats{
-				Date:           today,
-				PlatformID:     platform.ID,
-				PlatformName:   platform.Name,
-				TotalJobs:      int(totalJobs),
-				SuccessfulJobs: int(successfulJobs),
-				FailedJobs:     int(failedJobs),
-				PendingJobs:    int(pendingJobs),
-				AvgProcessTime: avgProcessTime,
-				ErrorCount:     int(errorCount),
-			}
+	// 查询平台相关统计，处理时间只统计今日完成的任务
+	var jobRows []platformJobStats
+	if err := m.db.Model(&models.DistributionJob{}).
+		Select("platform_id, "+jobCountColumns+", "+processTimeColumns()+", "+stageDurationColumns()+", "+
+			"MAX(published_at) FILTER (WHERE status = 'completed') AS last_success_at, "+
+			"MAX(updated_at) FILTER (WHERE status = 'failed') AS last_failure_at",
+			sql.Named("today", today)).
+		Group("platform_id").
+		Scan(&jobRows).Error; err != nil {
+		return fmt.Errorf("failed to aggregate jobs: %w", err)
+	}
+	jobStats := make(map[uint]platformJobStats, len(jobRows))
+	for _, row := range jobRows {
+		jobStats[row.PlatformID] = row
+	}
 
-			if lastSuccessJob.ID != 0 {
-				stats.LastSuccessAt = lastSuccessJob.PublishedAt
-			}
-			if lastFailureJob.ID != 0 {
-				stats.LastFailureAt = &lastFailureJob.UpdatedAt
-			}
+	// 计算错误数量
+	var errorRows []struct {
+		PlatformName string
+		Count        int64
+	}
+	if err := m.db.Model(&models.ErrorLog{}).
+		Select("platform_name, COUNT(*) AS count").
+		Where("created_at >= ? AND platform_name <> ''", today).
+		Group("platform_name").
+		Scan(&errorRows).Error; err != nil {
+		return fmt.Errorf("failed to count errors: %w", err)
+	}
+	errorCounts := make(map[string]int64, len(errorRows))
+	for _, row := range errorRows {
+		errorCounts[row.PlatformName] = row.Count
+	}
 
-			if err := m.db.Create(&stats).Error; err != nil {
-				return err
+	var existing []models.PlatformStats
+	if err := m.db.Where("date = ?", today).Find(&existing).Error; err != nil {
+		return fmt.Errorf("failed to get platform stats: %w", err)
+	}
+	existingStats := make(map[uint]models.PlatformStats, len(existing))
+	for _, stats := range existing {
+		existingStats[stats.PlatformID] = stats
+	}
+
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		for _, platform := range platforms {
+			jobs := jobStats[platform.ID]
+			errorCount := errorCounts[platform.Name]
+
+			stats, ok := existingStats[platform.ID]
+			if !ok {
+				// 创建新记录
+				stats = models.PlatformStats{
+					Date:           today,
+					PlatformID:     platform.ID,
+					PlatformName:   platform.Name,
+					TotalJobs:      int(jobs.Total),
+					SuccessfulJobs: int(jobs.Successful),
+					FailedJobs:     int(jobs.Failed),
+					PendingJobs:    int(jobs.Pending),
+					AvgProcessTime: orZero(jobs.AvgProcessTime),
+					P50ProcessTime: orZero(jobs.P50ProcessTime),
+					P95ProcessTime: orZero(jobs.P95ProcessTime),
+					LastSuccessAt:  jobs.LastSuccessAt,
+					LastFailureAt:  jobs.LastFailureAt,
+					ErrorCount:     int(errorCount),
+
+					AvgStageDurations: jobs.stageDurations(),
+				}
+				if err := tx.Omit(clause.Associations).Create(&stats).Error; err != nil {
+					return err
+				}
+				continue
 			}
-		} else {
+
 			// 更新现有记录
 			updates := map[string]interface{}{
-				"total_jobs":      totalJobs,
-				"successful_jobs": successfulJobs,
-				"failed_jobs":     failedJobs,
-				"pending_jobs":    pendingJobs,
-				"avg_process_time": avgProcessTime,
-				"error_count":     errorCount,
+				"total_jobs":       jobs.Total,
+				"successful_jobs":  jobs.Successful,
+				"failed_jobs":      jobs.Failed,
+				"pending_jobs":     jobs.Pending,
+				"avg_process_time":    orZero(jobs.AvgProcessTime),
+				"p50_process_time":    orZero(jobs.P50ProcessTime),
+				"p95_process_time":    orZero(jobs.P95ProcessTime),
+				"avg_stage_durations": jobs.stageDurations(),
+				"error_count":         errorCount,
 			}
-
-			if lastSuccessJob.ID != 0 {
-				updates["last_success_at"] = lastSuccessJob.PublishedAt
+			if jobs.LastSuccessAt != nil {
+				updates["last_success_at"] = jobs.LastSuccessAt
 			}
-			if lastFailureJob.ID != 0 {
-				updates["last_failure_at"] = lastFailureJob.UpdatedAt
+			if jobs.LastFailureAt != nil {
+				updates["last_failure_at"] = jobs.LastFailureAt
 			}
 
-			if err := m.db.Model(&stats).Updates(updates).Error; err != nil {
+			if err := tx.Model(&stats).Updates(updates).Error; err != nil {
 				return err
 			}
 		}
-	}
-
-	return nil
+		return nil
+	})
 }
 
 // UpdateDashboardSummary 更新仪表板摘要数据
 func (m *MonitoringService) UpdateDashboardSummary() error {
 	today := time.Now().Truncate(24 * time.Hour)
 
-	var summary models.DashboardSummary
-	result := m.db.First(&summary)
-
 	// 查询各种统计数据
 	var totalPages int64
-	m.db.Model(&models.NotionPage{}).Count(&totalPages)
-
-	var totalJobsToday, successfulJobsToday, failedJobsToday int64
-	m.db.Model(&models.DistributionJob{}).Where("created_at >= ?", today).Count(&totalJobsToday)
-	m.db.Model(&models.DistributionJob{}).Where("created_at >= ? AND status = ?", today, "completed").Count(&successfulJobsToday)
-	m.db.Model(&models.DistributionJob{}).Where("created_at >= ? AND status = ?", today, "failed").Count(&failedJobsToday)
+	if err := m.db.Model(&models.NotionPage{}).Count(&totalPages).Error; err != nil {
+		return fmt.Errorf("failed to count pages: %w", err)
+	}
 
-	var pendingJobsCount int64
-	m.db.Model(&models.DistributionJob{}).Where("status = ?", "pending").Count(&pendingJobsCount)
+	// 今日任务数量和待处理任务数量在一次查询中完成
+	var jobs struct {
+		TotalToday      int64
+		SuccessfulToday int64
+		FailedToday     int64
+		Pending         int64
+		LastPublishedAt *time.Time
+		AvgProcessTime  *float64
+		P95ProcessTime  *float64
+	}
+	if err := m.db.Model(&models.DistributionJob{}).
+		Select("COUNT(*) FILTER (WHERE created_at >= @today) AS total_today, "+
+			"COUNT(*) FILTER (WHERE created_at >= @today AND status = 'completed') AS successful_today, "+
+			"COUNT(*) FILTER (WHERE created_at >= @today AND status = 'failed') AS failed_today, "+
+			"COUNT(*) FILTER (WHERE status = 'pending') AS pending, "+
+			"MAX(published_at) FILTER (WHERE status = 'completed') AS last_published_at, "+
+			processTimeColumns(),
+			sql.Named("today", today)).
+		Scan(&jobs).Error; err != nil {
+		return fmt.Errorf("failed to count jobs: %w", err)
+	}
 
-	var activePlatformsCount, totalPlatformsCount int64
-	m.db.Model(&models.Platform{}).Where("enabled = ?", true).Count(&activePlatformsCount)
-	m.db.Model(&models.Platform{}).Count(&totalPlatformsCount)
+	platforms, err := m.countPlatforms()
+	if err != nil {
+		return fmt.Errorf("failed to count platforms: %w", err)
+	}
 
-	// 获取最后同步时间和发布时间
+	// 获取最后同步时间
+	var lastSyncRun models.SyncRun
 	var lastSyncPage models.NotionPage
-	var lastPublishJob models.DistributionJob
-	m.db.Order("updated_at desc").First(&lastSyncPage)
-	m.db.Where("status = ?", "completed").Order("published_at desc").First(&lastPublishJob)
+	m.db.Where("status = ?", models.SyncCompleted).Order("finished_at desc").First(&lastSyncRun)
+	if lastSyncRun.ID == 0 {
+		m.db.Order("updated_at desc").First(&lastSyncPage)
+	}
 
 	// 未解决错误数量
 	var unresolvedErrorsCount int64
-	m.db.Model(&models.ErrorLog{}).Where("resolved = ?", false).Count(&unresolvedErrorsCount)
-
-	// 今日平均处理时间
-	var avgProcessTimeToday float64
-	// TODO: 实现今日平均处理时间计算
+	if err := m.db.Model(&models.ErrorLog{}).Where("resolved = ?", false).Count(&unresolvedErrorsCount).Error; err != nil {
+		return fmt.Errorf("failed to count errors: %w", err)
+	}
 
 	summaryData := models.DashboardSummary{
-		TotalPages:             int(totalPages),
-		TotalJobsToday:         int(totalJobsToday),
-		SuccessfulJobsToday:    int(successfulJobsToday),
-		FailedJobsToday:        int(failedJobsToday),
-		PendingJobsCount:       int(pendingJobsCount),
-		ActivePlatformsCount:   int(activePlatformsCount),
-		TotalPlatformsCount:    int(totalPlatformsCount),
-		UnresolvedErrorsCount:  int(unresolvedErrorsCount),
-		AvgProcessTimeToday:    avgProcessTimeToday,
-	}
-
-	if lastSyncPage.ID != 0 {
-		summaryData.LastSyncTime = &lastSyncPage.UpdatedAt
+		TotalPages:            int(totalPages),
+		TotalJobsToday:        int(jobs.TotalToday),
+		SuccessfulJobsToday:   int(jobs.SuccessfulToday),
+		FailedJobsToday:       int(jobs.FailedToday),
+		PendingJobsCount:      int(jobs.Pending),
+		ActivePlatformsCount:  int(platforms.Active),
+		TotalPlatformsCount:   int(platforms.Total),
+		UnresolvedErrorsCount: int(unresolvedErrorsCount),
+		AvgProcessTimeToday:   orZero(jobs.AvgProcessTime),
+		P95ProcessTimeToday:   orZero(jobs.P95ProcessTime),
+		LastPublishTime:       jobs.LastPublishedAt,
 	}
-	if lastPublishJob.ID != 0 {
-		summaryData.LastPublishTime = lastPublishJob.PublishedAt
+
+	// 优先使用同步记录，没有记录时退回到最近更新的页面
+	if lastSyncRun.ID != 0 {
+		summaryData.LastSyncTime = lastSyncRun.FinishedAt
+	} else if lastSyncPage.ID != 0 {
+		summaryData.LastSyncTime = &lastSyncPage.UpdatedAt
 	}
 
+	var summary models.DashboardSummary
+	result := m.db.First(&summary)
 	if result.Error == gorm.ErrRecordNotFound {
 		// 创建新记录
 		summaryData.ID = 1 // 确保只有一条记录
@@ -314,14 +523,31 @@ func (m *MonitoringService) GetDashboardSummary() (*models.DashboardSummary, err
 	return &summary, nil
 }
 
-// GetRecentErrors 获取最近的错误日志
-func (m *MonitoringService) GetRecentErrors(limit int) ([]models.ErrorLog, error) {
-	var errors []models.ErrorLog
-	err := m.db.Preload("Page").Preload("Job").
-		Order("created_at desc").
-		Limit(limit).
-		Find(&errors).Error
-	return errors, err
+// GetSyncHistory 获取同步记录，source 为空时返回所有来源
+func (m *MonitoringService) GetSyncHistory(source string, limit int) ([]models.SyncRun, error) {
+	var runs []models.SyncRun
+	query := m.db.Order("started_at desc").Limit(limit)
+	if source != "" {
+		query = query.Where("source = ?", source)
+	}
+	err := query.Find(&runs).Error
+	return runs, err
+}
+
+// GetLastSuccessfulSync 获取最近一次成功的同步记录
+func (m *MonitoringService) GetLastSuccessfulSync(source string) (*models.SyncRun, error) {
+	var run models.SyncRun
+	query := m.db.Where("status = ?", models.SyncCompleted)
+	if source != "" {
+		query = query.Where("source = ?", source)
+	}
+	if err := query.Order("finished_at desc").First(&run).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &run, nil
 }
 
 // GetPlatformStats 获取平台统计数据
@@ -336,29 +562,54 @@ func (m *MonitoringService) GetPlatformStats(days int) ([]models.PlatformStats,
 	return stats, err
 }
 
-// CleanupOldData 清理旧数据
-func (m *MonitoringService) CleanupOldData(daysToKeep int) error {
-	cutoffDate := time.Now().AddDate(0, 0, -daysToKeep)
-
-	// 清理旧的指标数据
-	if err := m.db.Where("timestamp < ?", cutoffDate).Delete(&models.MetricsSample{}).Error; err != nil {
-		return fmt.Errorf("failed to cleanup metrics samples: %w", err)
+// GetPageMetrics 获取页面在各平台的文章数据快照，按日期排序
+func (m *MonitoringService) GetPageMetrics(notionID string) ([]models.PostMetric, error) {
+	var page models.NotionPage
+	if err := m.db.Select("id").Where("notion_id = ?", notionID).First(&page).Error; err != nil {
+		return nil, err
 	}
 
-	// 清理旧的系统统计数据
-	if err := m.db.Where("date < ?", cutoffDate).Delete(&models.SystemStats{}).Error; err != nil {
-		return fmt.Errorf("failed to cleanup system stats: %w", err)
-	}
+	var metrics []models.PostMetric
+	err := m.db.Where("page_id = ?", page.ID).
+		Order("date, platform_name").
+		Find(&metrics).Error
+	return metrics, err
+}
 
-	// 清理旧的平台统计数据
-	if err := m.db.Where("date < ?", cutoffDate).Delete(&models.PlatformStats{}).Error; err != nil {
-		return fmt.Errorf("failed to cleanup platform stats: %w", err)
+// CleanupOldData 按保留策略清理旧数据，返回每张表删除的行数；保留天数为 0 的数据不清理
+func (m *MonitoringService) CleanupOldData(policy config.RetentionConfig) (map[string]int64, error) {
+	deleted := make(map[string]int64)
+	steps := []struct {
+		table string
+		days  int
+		model interface{}
+		query string
+	}{
+		// 指标和统计数据
+		{"metrics_samples", policy.MetricsDays, &models.MetricsSample{}, "timestamp < ?"},
+		{"system_stats", policy.MetricsDays, &models.SystemStats{}, "date < ?"},
+		{"platform_stats", policy.MetricsDays, &models.PlatformStats{}, "date < ?"},
+		// 文章数据的每日快照
+		{"post_metrics", policy.PostMetricsDays, &models.PostMetric{}, "date < ?"},
+		// 任务日志
+		{"job_logs", policy.JobLogDays, &models.JobLog{}, "created_at < ?"},
+		// 已解决的错误日志
+		{"error_logs", policy.ErrorLogDays, &models.ErrorLog{}, "created_at < ? AND resolved = true"},
+		// 已解决且之后没有再出现的错误问题
+		{"error_issues", policy.ErrorLogDays, &models.ErrorIssue{}, "last_seen_at < ? AND resolved = true"},
 	}
 
-	// 清理已解决的旧错误日志
-	if err := m.db.Where("created_at < ? AND resolved = ?", cutoffDate, true).Delete(&models.ErrorLog{}).Error; err != nil {
-		return fmt.Errorf("failed to cleanup resolved errors: %w", err)
+	for _, step := range steps {
+		if step.days <= 0 {
+			continue
+		}
+		cutoffDate := time.Now().AddDate(0, 0, -step.days)
+		result := m.db.Where(step.query, cutoffDate).Delete(step.model)
+		if result.Error != nil {
+			return deleted, fmt.Errorf("failed to cleanup %s: %w", step.table, result.Error)
+		}
+		deleted[step.table] = result.RowsAffected
 	}
 
-	return nil
+	return deleted, nil
 }
\ No newline at end of file
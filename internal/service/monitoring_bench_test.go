@@ -0,0 +1,217 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+// benchSchema holds the seeded tables, so the benchmark never touches the tables of the database
+// it runs against
+const benchSchema = "ripple_bench"
+
+// Seeded dataset: a job per page and platform, a few of them retried, and a day of errors
+const (
+	benchPlatforms = 12
+	benchPages     = 1000
+	benchErrors    = 3000
+)
+
+// BenchmarkUpdatePlatformStats compares the grouped queries of UpdatePlatformStats with the
+// queries per platform it replaced, on a seeded Postgres database. Set RIPPLE_BENCH_DSN to a
+// database the benchmark may create the ripple_bench schema in:
+//
+//	RIPPLE_BENCH_DSN="host=localhost user=postgres dbname=ripple_bench" go test -run '^$' -bench UpdatePlatformStats ./internal/service
+func BenchmarkUpdatePlatformStats(b *testing.B) {
+	db := openBenchDatabase(b)
+	seedPlatformStats(b, db)
+	m := NewMonitoringService(db, zap.NewNop())
+
+	b.Run("grouped", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := m.UpdatePlatformStats(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("per_platform", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := updatePlatformStatsPerPlatform(db); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func openBenchDatabase(b *testing.B) *gorm.DB {
+	dsn := os.Getenv("RIPPLE_BENCH_DSN")
+	if dsn == "" {
+		b.Skip("RIPPLE_BENCH_DSN is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Silent),
+		NamingStrategy: schema.NamingStrategy{TablePrefix: benchSchema + "."},
+	})
+	if err != nil {
+		b.Fatalf("failed to connect to database: %v", err)
+	}
+	if err := db.Exec("DROP SCHEMA IF EXISTS " + benchSchema + " CASCADE").Error; err != nil {
+		b.Fatal(err)
+	}
+	if err := db.Exec("CREATE SCHEMA " + benchSchema).Error; err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() {
+		db.Exec("DROP SCHEMA IF EXISTS " + benchSchema + " CASCADE")
+	})
+
+	if err := db.AutoMigrate(
+		&models.NotionPage{},
+		&models.Platform{},
+		&models.DistributionJob{},
+		&models.PlatformStats{},
+		&models.ErrorIssue{},
+		&models.ErrorLog{},
+	); err != nil {
+		b.Fatalf("failed to migrate database: %v", err)
+	}
+	return db
+}
+
+func seedPlatformStats(b *testing.B, db *gorm.DB) {
+	platforms := make([]models.Platform, benchPlatforms)
+	for i := range platforms {
+		name := fmt.Sprintf("platform-%d", i)
+		platforms[i] = models.Platform{Name: name, DisplayName: name, Config: "{}", Enabled: i%4 != 0}
+	}
+	if err := db.Create(&platforms).Error; err != nil {
+		b.Fatal(err)
+	}
+
+	pages := make([]models.NotionPage, benchPages)
+	for i := range pages {
+		pages[i] = models.NotionPage{NotionID: fmt.Sprintf("page-%d", i), Title: fmt.Sprintf("Page %d", i)}
+	}
+	if err := db.CreateInBatches(&pages, 500).Error; err != nil {
+		b.Fatal(err)
+	}
+
+	statuses := []string{models.JobCompleted, models.JobCompleted, models.JobCompleted, models.JobFailed, models.JobPending, models.JobDraft}
+	now := time.Now()
+	var jobs []models.DistributionJob
+	for i, page := range pages {
+		for j, platform := range platforms {
+			job := models.DistributionJob{
+				PageID:     page.ID,
+				PlatformID: platform.ID,
+				Status:     statuses[(i+j)%len(statuses)],
+			}
+			if job.Status == models.JobCompleted {
+				publishedAt := now.Add(-time.Duration(i) * time.Minute)
+				job.PublishedAt = &publishedAt
+			}
+			jobs = append(jobs, job)
+			// Every tenth page was retried after a failure
+			if i%10 == 0 {
+				jobs = append(jobs, models.DistributionJob{PageID: page.ID, PlatformID: platform.ID, Status: models.JobFailed})
+			}
+		}
+	}
+	if err := db.CreateInBatches(&jobs, 1000).Error; err != nil {
+		b.Fatal(err)
+	}
+
+	errorLogs := make([]models.ErrorLog, benchErrors)
+	for i := range errorLogs {
+		errorLogs[i] = models.ErrorLog{
+			Level:        "ERROR",
+			Source:       "publisher",
+			PlatformName: platforms[i%len(platforms)].Name,
+			Title:        "Failed to publish",
+			Message:      fmt.Sprintf("error %d", i),
+		}
+	}
+	if err := db.CreateInBatches(&errorLogs, 1000).Error; err != nil {
+		b.Fatal(err)
+	}
+}
+
+// updatePlatformStatsPerPlatform is UpdatePlatformStats as it was before the queries were
+// grouped: eight queries per platform
+func updatePlatformStatsPerPlatform(db *gorm.DB) error {
+	today := time.Now().Truncate(24 * time.Hour)
+
+	var platforms []models.Platform
+	if err := db.Find(&platforms).Error; err != nil {
+		return err
+	}
+
+	for _, platform := range platforms {
+		var stats models.PlatformStats
+		result := db.Where("date = ? AND platform_id = ?", today, platform.ID).First(&stats)
+
+		var totalJobs, successfulJobs, failedJobs, pendingJobs int64
+		db.Model(&models.DistributionJob{}).Where("platform_id = ?", platform.ID).Count(&totalJobs)
+		db.Model(&models.DistributionJob{}).Where("platform_id = ? AND status = ?", platform.ID, "completed").Count(&successfulJobs)
+		db.Model(&models.DistributionJob{}).Where("platform_id = ? AND status = ?", platform.ID, "failed").Count(&failedJobs)
+		db.Model(&models.DistributionJob{}).Where("platform_id = ? AND status = ?", platform.ID, "pending").Count(&pendingJobs)
+
+		var lastSuccessJob, lastFailureJob models.DistributionJob
+		db.Where("platform_id = ? AND status = ?", platform.ID, "completed").Order("published_at desc").First(&lastSuccessJob)
+		db.Where("platform_id = ? AND status = ?", platform.ID, "failed").Order("updated_at desc").First(&lastFailureJob)
+
+		var errorCount int64
+		db.Model(&models.ErrorLog{}).Where("platform_name = ? AND created_at >= ?", platform.Name, today).Count(&errorCount)
+
+		if result.Error == gorm.ErrRecordNotFound {
+			stats = models.PlatformStats{
+				Date:           today,
+				PlatformID:     platform.ID,
+				PlatformName:   platform.Name,
+				TotalJobs:      int(totalJobs),
+				SuccessfulJobs: int(successfulJobs),
+				FailedJobs:     int(failedJobs),
+				PendingJobs:    int(pendingJobs),
+				ErrorCount:     int(errorCount),
+			}
+			if lastSuccessJob.ID != 0 {
+				stats.LastSuccessAt = lastSuccessJob.PublishedAt
+			}
+			if lastFailureJob.ID != 0 {
+				stats.LastFailureAt = &lastFailureJob.UpdatedAt
+			}
+			if err := db.Create(&stats).Error; err != nil {
+				return err
+			}
+			continue
+		}
+
+		updates := map[string]interface{}{
+			"total_jobs":      totalJobs,
+			"successful_jobs": successfulJobs,
+			"failed_jobs":     failedJobs,
+			"pending_jobs":    pendingJobs,
+			"error_count":     errorCount,
+		}
+		if lastSuccessJob.ID != 0 {
+			updates["last_success_at"] = lastSuccessJob.PublishedAt
+		}
+		if lastFailureJob.ID != 0 {
+			updates["last_failure_at"] = lastFailureJob.UpdatedAt
+		}
+		if err := db.Model(&stats).Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
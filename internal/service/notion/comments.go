@@ -0,0 +1,100 @@
+package notion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxCommentText is the longest text Notion accepts in a single rich text object
+const maxCommentText = 2000
+
+// Comment is a comment posted on a page: the users it mentions, then its text and an optional
+// link
+type Comment struct {
+	MentionIDs []string
+	Text       string
+	LinkText   string
+	LinkURL    string
+}
+
+// AddComment posts a comment on a Notion page. The integration needs the "Insert comments"
+// capability.
+func (s *Service) AddComment(ctx context.Context, pageID string, comment Comment) error {
+	var richText []map[string]any
+	for _, id := range comment.MentionIDs {
+		richText = append(richText,
+			map[string]any{
+				"type":    "mention",
+				"mention": map[string]any{"type": "user", "user": map[string]any{"id": id}},
+			},
+			map[string]any{"type": "text", "text": map[string]any{"content": " "}})
+	}
+
+	text := []rune(comment.Text)
+	if len(text) > maxCommentText {
+		text = append(text[:maxCommentText-1], '…')
+	}
+	richText = append(richText, map[string]any{"type": "text", "text": map[string]any{"content": string(text)}})
+	if comment.LinkURL != "" {
+		richText = append(richText, map[string]any{
+			"type": "text",
+			"text": map[string]any{
+				"content": comment.LinkText,
+				"link":    map[string]any{"url": comment.LinkURL},
+			},
+		})
+	}
+
+	jsonBody, err := json.Marshal(map[string]any{
+		"parent":    map[string]any{"page_id": pageID},
+		"rich_text": richText,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal comment: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.notion.com/v1/comments", bytes.NewReader(jsonBody))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+s.config.Token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Notion-Version", s.config.APIVersion)
+
+	resp, err := s.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("notion API returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// OwnerIDs returns the IDs of the users in the Owner people property of stored page properties
+func OwnerIDs(properties string) []string {
+	var props map[string]struct {
+		People []struct {
+			ID string `json:"id"`
+		} `json:"people"`
+	}
+	if properties == "" || json.Unmarshal([]byte(properties), &props) != nil {
+		return nil
+	}
+
+	var ids []string
+	for _, person := range props["Owner"].People {
+		if person.ID != "" {
+			ids = append(ids, person.ID)
+		}
+	}
+	return ids
+}
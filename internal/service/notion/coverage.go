@@ -0,0 +1,96 @@
+package notion
+
+import (
+	"fmt"
+
+	"github.com/ifuryst/ripple/internal/content"
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+// PageCoverage is the content size and block coverage of a synced page
+type PageCoverage struct {
+	PageID string `json:"page_id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	content.Coverage
+}
+
+// CoverageReport is the block coverage of the synced pages
+type CoverageReport struct {
+	Pages []PageCoverage `json:"pages"`
+	// Scanned is the number of pages measured, Incomplete those with degraded or unsupported
+	// blocks
+	Scanned    int `json:"scanned"`
+	Incomplete int `json:"incomplete"`
+	// Unsupported and Degraded count the blocks per type over all pages
+	Unsupported map[string]int `json:"unsupported"`
+	Degraded    map[string]int `json:"degraded"`
+}
+
+// ContentCoverage measures the stored content of the synced Notion pages that are not archived,
+// so pages that will render incompletely show up before they are published. Unless all is set,
+// only the pages with degraded or unsupported blocks are listed.
+func (s *Service) ContentCoverage(all bool) (*CoverageReport, error) {
+	var pages []models.NotionPage
+	if err := s.db.Select("id", "notion_id", "title", "status", "source", "content").
+		Where("archived_at IS NULL").
+		Order("last_modified DESC").
+		Find(&pages).Error; err != nil {
+		return nil, fmt.Errorf("failed to get pages: %w", err)
+	}
+
+	report := &CoverageReport{
+		Pages:       []PageCoverage{},
+		Unsupported: make(map[string]int),
+		Degraded:    make(map[string]int),
+	}
+	for _, page := range pages {
+		if !page.IsFromNotion() {
+			continue
+		}
+		coverage := measureContent(page.Content)
+		if coverage == nil {
+			continue
+		}
+
+		report.Scanned++
+		for blockType, n := range coverage.Unsupported {
+			report.Unsupported[blockType] += n
+		}
+		for blockType, n := range coverage.Degraded {
+			report.Degraded[blockType] += n
+		}
+		if !coverage.Complete() {
+			report.Incomplete++
+		} else if !all {
+			continue
+		}
+		report.Pages = append(report.Pages, pageCoverage(&page, coverage))
+	}
+	return report, nil
+}
+
+// PageContentCoverage measures the stored content of one page
+func (s *Service) PageContentCoverage(pageID string) (*PageCoverage, error) {
+	var page models.NotionPage
+	if err := s.db.Where("notion_id = ?", NormalizePageID(pageID)).First(&page).Error; err != nil {
+		return nil, fmt.Errorf("page not found: %w", err)
+	}
+
+	coverage := measureContent(page.Content)
+	if coverage == nil {
+		// Pages without content have nothing to lose
+		coverage, _ = content.NotionCoverageJSON("[]")
+	}
+	result := pageCoverage(&page, coverage)
+	return &result, nil
+}
+
+func pageCoverage(page *models.NotionPage, coverage *content.Coverage) PageCoverage {
+	return PageCoverage{
+		PageID:   page.NotionID,
+		Title:    page.Title,
+		Status:   page.Status,
+		Coverage: *coverage,
+	}
+}
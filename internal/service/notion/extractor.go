@@ -102,6 +102,30 @@ func (s *Service) extractPostDate(properties map[string]any) *time.Time {
 	return nil
 }
 
+func (s *Service) extractUnpublishDate(properties map[string]any) *time.Time {
+	// Look for Unpublish date property
+	for propName, prop := range properties {
+		if propName == "Unpublish date" {
+			if propMap, ok := prop.(map[string]any); ok {
+				if propMap["type"] == "date" {
+					if dateObj, ok := propMap["date"].(map[string]any); ok {
+						if startStr, ok := dateObj["start"].(string); ok {
+							// Unpublish dates may carry a time component
+							if date, err := time.Parse(time.RFC3339, startStr); err == nil {
+								return &date
+							}
+							if date, err := time.Parse("2006-01-02", startStr); err == nil {
+								return &date
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func (s *Service) extractOwner(properties map[string]any) string {
 	// Look for Owner people property
 	for propName, prop := range properties {
@@ -152,6 +176,53 @@ func (s *Service) extractPlatforms(properties map[string]any) models.StringArray
 	return models.StringArray{}
 }
 
+func (s *Service) extractSeries(properties map[string]any) string {
+	// Look for Series select or rich_text property
+	for propName, prop := range properties {
+		if propName == "Series" {
+			if propMap, ok := prop.(map[string]any); ok {
+				switch propMap["type"] {
+				case "select":
+					if selectObj, ok := propMap["select"].(map[string]any); ok {
+						if name, ok := selectObj["name"].(string); ok {
+							return strings.TrimSpace(name)
+						}
+					}
+				case "rich_text":
+					if richText, ok := propMap["rich_text"].([]any); ok {
+						var text strings.Builder
+						for _, segment := range richText {
+							if textObj, ok := segment.(map[string]any); ok {
+								if plainText, ok := textObj["plain_text"].(string); ok {
+									text.WriteString(plainText)
+								}
+							}
+						}
+						return strings.TrimSpace(text.String())
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func (s *Service) extractHold(properties map[string]any) bool {
+	// Look for Hold checkbox property
+	for propName, prop := range properties {
+		if propName == "Hold" {
+			if propMap, ok := prop.(map[string]any); ok {
+				if propMap["type"] == "checkbox" {
+					if checked, ok := propMap["checkbox"].(bool); ok {
+						return checked
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
 func (s *Service) extractContentType(properties map[string]any) models.StringArray {
 	// Look for Content type multi_select property
 	for propName, prop := range properties {
@@ -175,3 +246,21 @@ func (s *Service) extractContentType(properties map[string]any) models.StringArr
 	}
 	return models.StringArray{}
 }
+
+func (s *Service) extractCoverURL(cover map[string]any) string {
+	// Page covers are either uploaded files or external links
+	if cover == nil {
+		return ""
+	}
+	if fileObj, ok := cover["file"].(map[string]any); ok {
+		if url, ok := fileObj["url"].(string); ok {
+			return url
+		}
+	}
+	if externalObj, ok := cover["external"].(map[string]any); ok {
+		if url, ok := externalObj["url"].(string); ok {
+			return url
+		}
+	}
+	return ""
+}
@@ -2,13 +2,200 @@ package notion
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"go.uber.org/zap"
 	"io"
 	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ifuryst/ripple/pkg/httpclient"
+)
+
+const (
+	// Notion allows an average of three requests per second per integration
+	defaultRateLimit      = 3
+	defaultMaxConcurrency = 3
+	defaultMaxRetries     = 5
+	// defaultThrottleWait is used when a 429 response has no Retry-After header
+	defaultThrottleWait = time.Second
 )
 
+// ErrPageNotFound is returned when Notion has no page with the requested ID, or the
+// integration has not been given access to it
+var ErrPageNotFound = errors.New("notion page not found")
+
+// RateLimitStats reports how often Notion throttled the integration
+type RateLimitStats struct {
+	Requests      int64
+	Throttled     int64
+	ThrottledWait time.Duration
+	QueueWait     time.Duration
+	Queued        int
+	InFlight      int
+	LastThrottled *time.Time
+}
+
+// rateLimiter queues Notion requests so they are sent at the allowed rate with a cap on
+// concurrent requests, and pauses every request when Notion answers with 429
+type rateLimiter struct {
+	interval time.Duration
+	slots    chan struct{}
+
+	mu    sync.Mutex
+	next  time.Time
+	stats RateLimitStats
+}
+
+func newRateLimiter(rate float64, maxConcurrency int) *rateLimiter {
+	if rate <= 0 {
+		rate = defaultRateLimit
+	}
+	if maxConcurrency <= 0 {
+		maxConcurrency = defaultMaxConcurrency
+	}
+	return &rateLimiter{
+		interval: time.Duration(float64(time.Second) / rate),
+		slots:    make(chan struct{}, maxConcurrency),
+	}
+}
+
+// acquire waits for a concurrency slot and the next free send time; the returned function
+// releases the slot
+func (l *rateLimiter) acquire(ctx context.Context) (func(), error) {
+	start := time.Now()
+
+	l.mu.Lock()
+	l.stats.Queued++
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		l.stats.Queued--
+		l.stats.QueueWait += time.Since(start)
+		l.mu.Unlock()
+	}()
+
+	select {
+	case l.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := func() { <-l.slots }
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.stats.Requests++
+	l.mu.Unlock()
+
+	if delay := time.Until(slot); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+
+	return release, nil
+}
+
+// throttle records a 429 response and holds back all queued requests until Notion accepts
+// requests again
+func (l *rateLimiter) throttle(wait time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if resume := now.Add(wait); resume.After(l.next) {
+		l.next = resume
+	}
+	l.stats.Throttled++
+	l.stats.ThrottledWait += wait
+	l.stats.LastThrottled = &now
+}
+
+func (l *rateLimiter) snapshot() RateLimitStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := l.stats
+	stats.InFlight = len(l.slots)
+	return stats
+}
+
+// RateLimitStats returns the throttling metrics of the Notion client
+func (s *Service) RateLimitStats() RateLimitStats {
+	return s.limiter.snapshot()
+}
+
+// observeResponse is registered as a response hook of the HTTP client, so 429s retried
+// inside the client pause the queue as well
+func (s *Service) observeResponse(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+		return
+	}
+
+	wait, ok := httpclient.ParseRetryAfter(resp.Header.Get("Retry-After"))
+	if !ok {
+		wait = defaultThrottleWait
+	}
+	s.limiter.throttle(wait)
+
+	s.logger.Warn("Notion API rate limit hit, pausing requests",
+		zap.String("path", req.URL.Path),
+		zap.Duration("retry_after", wait))
+}
+
+// do sends a request through the rate limiter and keeps retrying while Notion responds with
+// 429, waiting as long as its Retry-After asks
+func (s *Service) do(req *http.Request) (*http.Response, error) {
+	maxRetries := s.config.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultMaxRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		release, err := s.limiter.acquire(req.Context())
+		if err != nil {
+			return nil, err
+		}
+
+		attemptReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				release()
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := s.client.Do(attemptReq)
+		release()
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || attempt >= maxRetries {
+			return resp, err
+		}
+		if req.Body != nil && req.GetBody == nil {
+			return resp, nil
+		}
+
+		// The wait itself was already queued by observeResponse
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+}
+
 func (s *Service) queryDatabase(cursor string) (*DatabaseResponse, error) {
 	url := fmt.Sprintf("https://api.notion.com/v1/databases/%s/query", s.config.DatabaseID)
 
@@ -39,7 +226,7 @@ func (s *Service) queryDatabase(cursor string) (*DatabaseResponse, error) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Notion-Version", s.config.APIVersion)
 
-	resp, err := s.client.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -58,6 +245,39 @@ func (s *Service) queryDatabase(cursor string) (*DatabaseResponse, error) {
 	return &response, nil
 }
 
+func (s *Service) getPage(pageID string) (*PageResponse, error) {
+	url := fmt.Sprintf("https://api.notion.com/v1/pages/%s", pageID)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+s.config.Token)
+	req.Header.Set("Notion-Version", s.config.APIVersion)
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrPageNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("notion API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var page PageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &page, nil
+}
+
 // getAllBlocksRecursively recursively fetches all blocks including children of blocks that have has_children: true
 func (s *Service) getAllBlocksRecursively(blockID string) ([]map[string]any, error) {
 	var allBlocks []map[string]any
@@ -132,7 +352,7 @@ func (s *Service) getPageBlocks(pageID, cursor string) ([]map[string]any, string
 	req.Header.Set("Authorization", "Bearer "+s.config.Token)
 	req.Header.Set("Notion-Version", s.config.APIVersion)
 
-	resp, err := s.client.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
 		return nil, "", false, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -163,3 +383,29 @@ func getBlockType(block map[string]any) string {
 	}
 	return "unknown"
 }
+
+// Ping checks that the Notion API is reachable and the configured database is accessible
+func (s *Service) Ping(ctx context.Context) error {
+	url := fmt.Sprintf("https://api.notion.com/v1/databases/%s", s.config.DatabaseID)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+s.config.Token)
+	req.Header.Set("Notion-Version", s.config.APIVersion)
+
+	resp, err := s.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("notion API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
@@ -9,13 +9,17 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 
 	"github.com/ifuryst/ripple/internal/config"
+	"github.com/ifuryst/ripple/internal/content"
+	"github.com/ifuryst/ripple/internal/events"
 	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/pkg/httpclient"
 )
 
 type (
@@ -30,6 +34,9 @@ type (
 		CreatedTime    string         `json:"created_time"`
 		LastEditedTime string         `json:"last_edited_time"`
 		Properties     map[string]any `json:"properties"`
+		Cover          map[string]any `json:"cover"`
+		Archived       bool           `json:"archived"`
+		InTrash        bool           `json:"in_trash"`
 		Children       []Block        `json:"children,omitempty"`
 	}
 
@@ -41,10 +48,11 @@ type (
 )
 
 type Service struct {
-	config *config.NotionConfig
-	db     *gorm.DB
-	logger *zap.Logger
-	client *http.Client
+	config  *config.NotionConfig
+	db      *gorm.DB
+	logger  *zap.Logger
+	client  *http.Client
+	limiter *rateLimiter
 }
 
 func NewService(config *config.NotionConfig, db *gorm.DB, logger *zap.Logger) *Service {
@@ -55,42 +63,76 @@ func NewService(config *config.NotionConfig, db *gorm.DB, logger *zap.Logger) *S
 		caCertPool = x509.NewCertPool()
 	}
 
-	tr := &http.Transport{
-		IdleConnTimeout:       120 * time.Second,
-		MaxIdleConns:          10,
-		MaxIdleConnsPerHost:   10,
-		TLSHandshakeTimeout:   20 * time.Second,
-		ResponseHeaderTimeout: 20 * time.Second,
-		TLSClientConfig: &tls.Config{
-			RootCAs: caCertPool,
-		},
-	}
-	return &Service{
-		config: config,
-		db:     db,
-		logger: logger,
-		client: &http.Client{
-			Transport: tr,
-			Timeout:   30 * time.Second,
-		},
+	service := &Service{
+		config:  config,
+		db:      db,
+		logger:  logger,
+		limiter: newRateLimiter(config.RateLimit, config.MaxConcurrency),
 	}
+	service.client = httpclient.New(
+		httpclient.WithTLSConfig(&tls.Config{RootCAs: caCertPool}),
+		httpclient.OnResponse(service.observeResponse),
+	)
+	return service
 }
 
-func (s *Service) SyncPages() error {
+// defaultSyncConcurrency is the number of pages synced at once when not configured
+const defaultSyncConcurrency = 4
+
+// SyncPages syncs every page of the database with a pool of workers; all of them share the
+// Notion rate limiter, so more workers only help while requests are waiting on responses.
+// Pages that fail are collected in the run instead of stopping the sync, and the run is
+// stored as sync history.
+func (s *Service) SyncPages() (*models.SyncRun, error) {
 	s.logger.Info("Starting Notion pages sync")
 
+	concurrency := s.config.SyncConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultSyncConcurrency
+	}
+
+	run := models.NewSyncRun(models.SourceNotion)
+	if err := s.db.Create(run).Error; err != nil {
+		s.logger.Warn("Failed to record sync run", zap.Error(err))
+	}
+	var mu sync.Mutex
+
+	pages := make(chan PageResponse)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for page := range pages {
+				outcome, coverage, err := s.processPage(page, false)
+				if err != nil {
+					s.logger.Error("Failed to process page", zap.String("page_id", page.ID), zap.Error(err))
+				}
+
+				mu.Lock()
+				run.Record(page.ID, s.extractTitle(page.Properties), outcome, err)
+				if coverage != nil {
+					run.RecordContent(coverage)
+				}
+				events.Publish(events.SyncProgress, *run)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var queryErr error
+	seen := make(map[string]bool)
 	cursor := ""
 	for {
 		response, err := s.queryDatabase(cursor)
 		if err != nil {
-			return fmt.Errorf("failed to query database: %w", err)
+			queryErr = fmt.Errorf("failed to query database: %w", err)
+			break
 		}
 
 		for _, page := range response.Results {
-			if err := s.processPage(page); err != nil {
-				s.logger.Error("Failed to process page", zap.String("page_id", page.ID), zap.Error(err))
-				continue
-			}
+			seen[page.ID] = true
+			pages <- page
 		}
 
 		if !response.HasMore {
@@ -99,15 +141,150 @@ func (s *Service) SyncPages() error {
 		cursor = response.NextCursor
 	}
 
-	s.logger.Info("Notion pages sync completed")
+	close(pages)
+	wg.Wait()
+
+	// Only a complete listing tells which pages are gone
+	if queryErr == nil {
+		s.archiveMissingPages(seen, run)
+	}
+
+	run.Finish(queryErr)
+	if err := s.db.Save(run).Error; err != nil {
+		s.logger.Warn("Failed to record sync run", zap.Error(err))
+	}
+	events.Publish(events.SyncProgress, *run)
+
+	s.logger.Info("Notion pages sync completed",
+		zap.Int("scanned", run.Scanned),
+		zap.Int("created", run.Created),
+		zap.Int("updated", run.Updated),
+		zap.Int("skipped", run.Skipped),
+		zap.Int("failed", run.Failed),
+		zap.Int("archived", run.Archived),
+		zap.Int64("content_bytes", run.ContentBytes),
+		zap.Int("incomplete_pages", run.IncompletePages),
+		zap.Duration("duration", run.Duration()))
+	return run, queryErr
+}
+
+// archiveMissingPages looks up the synced pages the database query no longer returned. The query
+// only returns Done pages, so a missing page is archived only when Notion reports it archived,
+// trashed or gone; pages that merely changed status are left alone.
+func (s *Service) archiveMissingPages(seen map[string]bool, run *models.SyncRun) {
+	var stored []models.NotionPage
+	if err := s.db.Select("id", "notion_id", "title", "source").
+		Where("archived_at IS NULL").
+		Find(&stored).Error; err != nil {
+		s.logger.Warn("Failed to load pages to check for archiving", zap.Error(err))
+		return
+	}
+
+	for _, page := range stored {
+		if !page.IsFromNotion() || seen[page.NotionID] {
+			continue
+		}
+
+		reason := ""
+		remote, err := s.getPage(page.NotionID)
+		switch {
+		case errors.Is(err, ErrPageNotFound):
+			reason = models.ArchiveReasonDeleted
+		case err != nil:
+			s.logger.Warn("Failed to check whether page was archived", zap.String("page_id", page.NotionID), zap.Error(err))
+			continue
+		case remote.Archived || remote.InTrash:
+			reason = models.ArchiveReasonArchived
+		default:
+			continue
+		}
+
+		if err := s.archivePage(&page, reason); err != nil {
+			run.Record(page.NotionID, page.Title, "", err)
+			continue
+		}
+		run.Archived++
+	}
+}
+
+// archiveSyncedPage archives the stored copy of a page archived in Notion, if there is one
+func (s *Service) archiveSyncedPage(pageID string) (string, error) {
+	var existingPage models.NotionPage
+	if err := s.db.Where("notion_id = ?", pageID).First(&existingPage).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.PageSkipped, nil
+		}
+		return "", fmt.Errorf("failed to query existing page: %w", err)
+	}
+	if existingPage.IsArchived() {
+		return models.PageSkipped, nil
+	}
+
+	if err := s.archivePage(&existingPage, models.ArchiveReasonArchived); err != nil {
+		return "", err
+	}
+	return models.PageArchived, nil
+}
+
+// archivePage marks a stored page as archived so it is no longer published
+func (s *Service) archivePage(page *models.NotionPage, reason string) error {
+	now := time.Now()
+	if err := s.db.Model(&models.NotionPage{}).Where("id = ?", page.ID).
+		Updates(map[string]interface{}{"archived_at": now, "archive_reason": reason}).Error; err != nil {
+		return fmt.Errorf("failed to archive page: %w", err)
+	}
+	page.ArchivedAt = &now
+	page.ArchiveReason = reason
+
+	s.logger.Info("Archived page removed from Notion",
+		zap.String("page_id", page.NotionID),
+		zap.String("title", page.Title),
+		zap.String("reason", reason))
 	return nil
 }
 
-func (s *Service) processPage(page PageResponse) error {
+// SyncPage fetches a single page from Notion and stores it, returning the stored page and
+// whether it was created, updated or skipped. With force the stored content is refreshed
+// even if the page has not been edited since the last sync.
+func (s *Service) SyncPage(pageID string, force bool) (*models.NotionPage, string, error) {
+	page, err := s.getPage(NormalizePageID(pageID))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get page: %w", err)
+	}
+
+	outcome, _, err := s.processPage(*page, force)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var stored models.NotionPage
+	if err := s.db.Where("notion_id = ?", page.ID).First(&stored).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to load synced page: %w", err)
+	}
+	return &stored, outcome, nil
+}
+
+// NormalizePageID converts a 32 character Notion ID into the hyphenated form stored in the database
+func NormalizePageID(pageID string) string {
+	id := strings.ReplaceAll(strings.TrimSpace(pageID), "-", "")
+	if len(id) != 32 {
+		return pageID
+	}
+	return fmt.Sprintf("%s-%s-%s-%s-%s", id[0:8], id[8:12], id[12:16], id[16:20], id[20:32])
+}
+
+// processPage stores a page, returning whether it was created, updated or skipped, and the size
+// and block coverage of its content
+func (s *Service) processPage(page PageResponse, force bool) (string, *content.Coverage, error) {
+	if page.Archived || page.InTrash {
+		outcome, err := s.archiveSyncedPage(page.ID)
+		return outcome, nil, err
+	}
+
 	// Parse timestamps
 	lastModified, err := time.Parse(time.RFC3339, page.LastEditedTime)
 	if err != nil {
-		return fmt.Errorf("failed to parse last_edited_time: %w", err)
+		return "", nil, fmt.Errorf("failed to parse last_edited_time: %w", err)
 	}
 
 	// Extract all properties
@@ -116,14 +293,18 @@ func (s *Service) processPage(page PageResponse) error {
 	tags := s.extractTags(page.Properties)
 	status := s.extractStatus(page.Properties)
 	postDate := s.extractPostDate(page.Properties)
+	unpublishAt := s.extractUnpublishDate(page.Properties)
 	owner := s.extractOwner(page.Properties)
 	platforms := s.extractPlatforms(page.Properties)
 	contentType := s.extractContentType(page.Properties)
+	series := s.extractSeries(page.Properties)
+	hold := s.extractHold(page.Properties)
+	coverURL := s.extractCoverURL(page.Cover)
 
 	// Serialize properties
 	propertiesJSON, err := json.Marshal(page.Properties)
 	if err != nil {
-		return fmt.Errorf("failed to marshal properties: %w", err)
+		return "", nil, fmt.Errorf("failed to marshal properties: %w", err)
 	}
 
 	// Get page content
@@ -133,12 +314,26 @@ func (s *Service) processPage(page PageResponse) error {
 		content = ""
 	}
 
+	// Build the document the publishers render once, instead of every publisher parsing the blocks
+	document, err := encodeDocument(content)
+	if err != nil {
+		s.logger.Warn("Failed to build page document", zap.String("page_id", page.ID), zap.Error(err))
+	}
+
+	coverage := measureContent(content)
+	if coverage != nil && !coverage.Complete() {
+		s.logger.Debug("Page has blocks that will not render completely",
+			zap.String("page_id", page.ID),
+			zap.Any("degraded", coverage.Degraded),
+			zap.Any("unsupported", coverage.Unsupported))
+	}
+
 	// Check if page exists
 	var existingPage models.NotionPage
 	result := s.db.Where("notion_id = ?", page.ID).First(&existingPage)
 
 	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
-		return fmt.Errorf("failed to query existing page: %w", result.Error)
+		return "", nil, fmt.Errorf("failed to query existing page: %w", result.Error)
 	}
 
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -148,41 +343,59 @@ func (s *Service) processPage(page PageResponse) error {
 			Title:        title,
 			ENTitle:      enTitle,
 			Content:      content,
+			Document:     document,
 			Tags:         tags,
 			Status:       status,
 			PostDate:     postDate,
+			UnpublishAt:  unpublishAt,
 			Owner:        owner,
 			Platforms:    platforms,
 			ContentType:  contentType,
+			Series:       series,
+			Hold:         hold,
+			CoverURL:     coverURL,
 			Properties:   string(propertiesJSON),
 			LastModified: lastModified,
 		}
 
 		if err := s.db.Create(&newPage).Error; err != nil {
-			return fmt.Errorf("failed to create page: %w", err)
+			return "", nil, fmt.Errorf("failed to create page: %w", err)
 		}
 
 		s.logger.Info("Created new page", zap.String("page_id", page.ID), zap.String("title", title))
+		return models.PageCreated, coverage, nil
 	} else {
 		// Check if we need to force refresh content (for image link expiration)
-		needsContentRefresh := s.shouldRefreshContent(existingPage)
+		needsContentRefresh := force || s.shouldRefreshContent(existingPage)
 		
+		// Pages restored from the trash are published again
+		restored := existingPage.IsArchived()
+
 		// Update existing page if modified or needs content refresh
-		if existingPage.LastModified.Before(lastModified) || needsContentRefresh {
+		if existingPage.LastModified.Before(lastModified) || needsContentRefresh || restored {
 			existingPage.Title = title
 			existingPage.ENTitle = enTitle
 			existingPage.Content = content
+			existingPage.Document = document
 			existingPage.Tags = tags
-			existingPage.Status = status
+			if !existingPage.StaysUnpublished(unpublishAt) {
+				existingPage.Status = status
+			}
 			existingPage.PostDate = postDate
+			existingPage.UnpublishAt = unpublishAt
 			existingPage.Owner = owner
 			existingPage.Platforms = platforms
 			existingPage.ContentType = contentType
+			existingPage.Series = series
+			existingPage.Hold = hold
+			existingPage.CoverURL = coverURL
 			existingPage.Properties = string(propertiesJSON)
 			existingPage.LastModified = lastModified
+			existingPage.ArchivedAt = nil
+			existingPage.ArchiveReason = ""
 
 			if err := s.db.Save(&existingPage).Error; err != nil {
-				return fmt.Errorf("failed to update page: %w", err)
+				return "", nil, fmt.Errorf("failed to update page: %w", err)
 			}
 
 			if needsContentRefresh {
@@ -190,10 +403,11 @@ func (s *Service) processPage(page PageResponse) error {
 			} else {
 				s.logger.Info("Updated existing page", zap.String("page_id", page.ID), zap.String("title", title))
 			}
+			return models.PageUpdated, coverage, nil
 		}
 	}
 
-	return nil
+	return models.PageSkipped, coverage, nil
 }
 
 func (s *Service) shouldRefreshContent(existingPage models.NotionPage) bool {
@@ -245,6 +459,31 @@ func (s *Service) getPageContent(pageID string) (string, error) {
 	return string(blocksJSON), nil
 }
 
+// encodeDocument converts the page's blocks JSON into the encoded content document
+func encodeDocument(blocksJSON string) (string, error) {
+	if blocksJSON == "" {
+		return "", nil
+	}
+	doc, err := content.FromNotionJSON(blocksJSON)
+	if err != nil {
+		return "", err
+	}
+	return doc.Encode()
+}
+
+// measureContent returns the size and block coverage of the page's blocks JSON, or nil when
+// the page has no content
+func measureContent(blocksJSON string) *content.Coverage {
+	if blocksJSON == "" {
+		return nil
+	}
+	coverage, err := content.NotionCoverageJSON(blocksJSON)
+	if err != nil {
+		return nil
+	}
+	return coverage
+}
+
 func (s *Service) GetAllPages() ([]models.NotionPage, error) {
 	var pages []models.NotionPage
 	if err := s.db.Find(&pages).Error; err != nil {
@@ -286,7 +525,7 @@ func (s *Service) UpdatePageStatus(pageID, status string) error {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Notion-Version", "2022-06-28")
 
-	resp, err := s.client.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
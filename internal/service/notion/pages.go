@@ -0,0 +1,96 @@
+package notion
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+// listOmittedColumns are left out of page lists unless asked for: the page body in its stored
+// forms, which is read with GetPageContent
+var listOmittedColumns = map[string]bool{
+	"content":       true,
+	"document":      true,
+	"search_text":   true,
+	"search_vector": true,
+}
+
+// PageContent is the body of a page, left out of page lists
+type PageContent struct {
+	PageID string `json:"page_id"`
+	Title  string `json:"title"`
+	Source string `json:"source"`
+	// Content is the Notion block JSON of the page, or the Markdown of other sources
+	Content      string    `json:"content"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// PageColumns returns the columns to load for the page fields of a list, named as in the JSON
+// of a page. Without fields every field but the content is loaded.
+func (s *Service) PageColumns(fields []string) ([]string, error) {
+	stmt := &gorm.Statement{DB: s.db}
+	if err := stmt.Parse(&models.NotionPage{}); err != nil {
+		return nil, fmt.Errorf("failed to parse page model: %w", err)
+	}
+
+	columns := make(map[string]string)
+	var all []string
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		if name := strings.Split(field.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+			columns[name] = field.DBName
+		}
+		if !listOmittedColumns[field.DBName] {
+			all = append(all, field.DBName)
+		}
+	}
+	if len(fields) == 0 {
+		return all, nil
+	}
+
+	var selected []string
+	seen := make(map[string]bool)
+	for _, name := range fields {
+		column, ok := columns[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown page field %q", name)
+		}
+		if !seen[column] {
+			seen[column] = true
+			selected = append(selected, column)
+		}
+	}
+	return selected, nil
+}
+
+// ListPages returns every page with only the given columns loaded
+func (s *Service) ListPages(columns []string) ([]models.NotionPage, error) {
+	var pages []models.NotionPage
+	if err := s.db.Select(columns).Find(&pages).Error; err != nil {
+		return nil, fmt.Errorf("failed to get pages: %w", err)
+	}
+	return pages, nil
+}
+
+// GetPageContent returns the body of a page
+func (s *Service) GetPageContent(pageID string) (*PageContent, error) {
+	var page models.NotionPage
+	if err := s.db.Select("notion_id", "title", "source", "content", "last_modified").
+		Where("notion_id = ?", NormalizePageID(pageID)).
+		First(&page).Error; err != nil {
+		return nil, fmt.Errorf("page not found: %w", err)
+	}
+	return &PageContent{
+		PageID:       page.NotionID,
+		Title:        page.Title,
+		Source:       page.Source,
+		Content:      page.Content,
+		LastModified: page.LastModified,
+	}, nil
+}
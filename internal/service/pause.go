@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+// ErrPlatformNotAvailable is returned when pausing or resuming a platform that isn't registered
+var ErrPlatformNotAvailable = errors.New("platform is not available")
+
+// PausePlatform holds the publishes to a platform until it is resumed, e.g. while expired
+// Substack cookies are renewed. Publishes in the meantime are kept as paused jobs.
+func (s *PublisherService) PausePlatform(platformName, reason string) error {
+	if _, err := s.manager.GetPublisher(platformName); err != nil {
+		return fmt.Errorf("%w: %s", ErrPlatformNotAvailable, platformName)
+	}
+
+	if err := s.manager.PausePlatform(platformName, reason); err != nil {
+		return fmt.Errorf("failed to pause %s: %w", platformName, err)
+	}
+
+	s.logger.Info("Paused platform",
+		zap.String("platform", platformName),
+		zap.String("reason", reason))
+	return nil
+}
+
+// ResumePlatform lifts the pause of a platform and publishes the jobs held while it was paused
+// in the background. It returns how many held jobs are resumed.
+func (s *PublisherService) ResumePlatform(platformName string) (int, error) {
+	if _, err := s.manager.GetPublisher(platformName); err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrPlatformNotAvailable, platformName)
+	}
+
+	held, err := s.manager.ResumePlatform(platformName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resume %s: %w", platformName, err)
+	}
+
+	s.logger.Info("Resumed platform",
+		zap.String("platform", platformName),
+		zap.Int("held_jobs", len(held)))
+
+	if len(held) > 0 {
+		go s.publishHeldJobs(context.Background(), platformName, held)
+	}
+	return len(held), nil
+}
+
+// PausedPlatforms returns the paused platforms with the reason and time they were paused
+func (s *PublisherService) PausedPlatforms() ([]models.Platform, error) {
+	return s.manager.PausedPlatforms()
+}
+
+// publishHeldJobs publishes the pages of jobs held while a platform was paused; claiming each
+// page resumes its held job
+func (s *PublisherService) publishHeldJobs(ctx context.Context, platformName string, held []models.DistributionJob) {
+	for _, job := range held {
+		page := job.Page
+		results, err := s.manager.PublishToPlatforms(ctx, &page, []string{platformName})
+		if err != nil {
+			s.logger.Error("Failed to publish held job",
+				zap.Uint("job_id", job.ID),
+				zap.String("platform", platformName),
+				zap.Error(err))
+			continue
+		}
+
+		for name, result := range results {
+			s.recordPublishResult(ctx, &page, name, result)
+		}
+		s.markPublishedIfCompleted(ctx, &page)
+	}
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// MetricsCollector pulls the stats of recently published posts from the platforms and keeps a
+// daily snapshot of them in the post_metrics table
+type MetricsCollector struct {
+	publisherService *PublisherService
+	logger           *zap.Logger
+	interval         time.Duration
+	window           time.Duration
+	done             chan struct{}
+}
+
+// NewMetricsCollector creates a metrics collector; an interval of 0 disables it
+func NewMetricsCollector(publisherService *PublisherService, logger *zap.Logger, interval, window time.Duration) *MetricsCollector {
+	return &MetricsCollector{
+		publisherService: publisherService,
+		logger:           logger,
+		interval:         interval,
+		window:           window,
+		done:             make(chan struct{}),
+	}
+}
+
+// Start begins collecting post stats
+func (c *MetricsCollector) Start(ctx context.Context) {
+	if c.interval <= 0 {
+		c.logger.Info("Metrics collector is disabled")
+		return
+	}
+
+	go func() {
+		c.logger.Info("Starting metrics collector",
+			zap.Duration("interval", c.interval),
+			zap.Duration("window", c.window))
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		c.collect(ctx)
+		for {
+			select {
+			case <-c.done:
+				c.logger.Info("Metrics collector stopped")
+				return
+			case <-ctx.Done():
+				c.logger.Info("Metrics collector stopped due to context cancellation")
+				return
+			case <-ticker.C:
+				c.collect(ctx)
+			}
+		}
+	}()
+}
+
+// Stop stops the metrics collector
+func (c *MetricsCollector) Stop() {
+	close(c.done)
+}
+
+// collect snapshots the stats of every post published within the window
+func (c *MetricsCollector) collect(ctx context.Context) {
+	unlock, ok := c.publisherService.locker.lockRound(ctx, LockPostMetrics, c.logger)
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	query := c.publisherService.db.WithContext(ctx).
+		Preload("Platform").
+		Where("status = ? AND publish_id <> ''", models.JobCompleted)
+	if c.window > 0 {
+		query = query.Where("published_at >= ?", time.Now().Add(-c.window))
+	}
+
+	var jobs []models.DistributionJob
+	if err := query.Find(&jobs).Error; err != nil {
+		c.logger.Error("Failed to load published jobs", zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	byPlatform := make(map[string][]models.DistributionJob)
+	for _, job := range jobs {
+		// Posts scheduled on the platform have no stats until they go out
+		if scheduledAt, err := time.Parse(time.RFC3339, job.Metadata["scheduled_at"]); err == nil && scheduledAt.After(now) {
+			continue
+		}
+		byPlatform[job.Platform.Name] = append(byPlatform[job.Platform.Name], job)
+	}
+
+	for platformName, platformJobs := range byPlatform {
+		c.collectPlatform(ctx, platformName, platformJobs)
+	}
+}
+
+func (c *MetricsCollector) collectPlatform(ctx context.Context, platformName string, jobs []models.DistributionJob) {
+	pageIDs := make([]uint, 0, len(jobs))
+	for _, job := range jobs {
+		pageIDs = append(pageIDs, job.PageID)
+	}
+	pages, err := c.publisherService.calendarPages(pageIDs)
+	if err != nil {
+		c.logger.Error("Failed to load published pages", zap.Error(err))
+		return
+	}
+
+	posts := make([]publisher.PublishedPost, 0, len(jobs))
+	jobsByID := make(map[uint]models.DistributionJob, len(jobs))
+	for _, job := range jobs {
+		publishedAt := job.CreatedAt
+		if job.PublishedAt != nil {
+			publishedAt = *job.PublishedAt
+		}
+		posts = append(posts, publisher.PublishedPost{
+			JobID:       job.ID,
+			PublishID:   job.PublishID,
+			Title:       pages[job.PageID].Title,
+			Metadata:    job.Metadata,
+			PublishedAt: publishedAt,
+		})
+		jobsByID[job.ID] = job
+	}
+
+	collectCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	metrics, err := c.publisherService.manager.CollectMetrics(collectCtx, platformName, posts)
+	cancel()
+	if err != nil {
+		c.logger.Warn("Failed to collect post metrics",
+			zap.String("platform", platformName),
+			zap.Error(err))
+		return
+	}
+	if len(metrics) == 0 {
+		return
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	rows := make([]models.PostMetric, 0, len(metrics))
+	for jobID, postMetrics := range metrics {
+		rows = append(rows, models.PostMetric{
+			JobID:        jobID,
+			PageID:       jobsByID[jobID].PageID,
+			PlatformName: platformName,
+			Date:         today,
+			Views:        postMetrics.Views,
+			Readers:      postMetrics.Readers,
+			Likes:        postMetrics.Likes,
+			Comments:     postMetrics.Comments,
+			Shares:       postMetrics.Shares,
+			CollectedAt:  now,
+		})
+	}
+
+	if err := upsertPostMetrics(c.publisherService.db.WithContext(ctx), rows); err != nil {
+		c.logger.Error("Failed to save post metrics",
+			zap.String("platform", platformName),
+			zap.Error(err))
+		return
+	}
+	c.logger.Info("Collected post metrics",
+		zap.String("platform", platformName),
+		zap.Int("posts", len(rows)))
+}
+
+// upsertPostMetrics saves the snapshots, replacing the ones already taken today
+func upsertPostMetrics(db *gorm.DB, rows []models.PostMetric) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "job_id"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"views", "readers", "likes", "comments", "shares", "collected_at", "updated_at"}),
+	}).Create(&rows).Error
+}
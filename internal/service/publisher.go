@@ -2,9 +2,16 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
 	"gorm.io/gorm"
 
 	"github.com/ifuryst/ripple/internal/config"
@@ -12,8 +19,16 @@ import (
 	"github.com/ifuryst/ripple/internal/service/notion"
 	"github.com/ifuryst/ripple/internal/service/publisher"
 	"github.com/ifuryst/ripple/internal/service/publisher/al_folio"
+	"github.com/ifuryst/ripple/internal/service/publisher/announce"
+	"github.com/ifuryst/ripple/internal/service/publisher/confluence"
+	"github.com/ifuryst/ripple/internal/service/publisher/microblog"
+	"github.com/ifuryst/ripple/internal/service/publisher/mock"
+	"github.com/ifuryst/ripple/internal/service/publisher/promo"
 	"github.com/ifuryst/ripple/internal/service/publisher/substack"
 	"github.com/ifuryst/ripple/internal/service/publisher/wechat_official"
+	"github.com/ifuryst/ripple/internal/service/publisher/xiaohongshu"
+	"github.com/ifuryst/ripple/pkg/imagehost"
+	"github.com/ifuryst/ripple/pkg/util"
 )
 
 // PublisherService manages content publishing to various platforms
@@ -24,6 +39,9 @@ type PublisherService struct {
 	manager            *publisher.Manager
 	monitoringService  *MonitoringService
 	notionService      *notion.Service
+	enricher           *Enricher
+	linter             *publisher.Linter
+	locker             *Locker
 }
 
 func NewPublisherService(cfg *config.Config, db *gorm.DB, logger *zap.Logger, notionService *notion.Service) *PublisherService {
@@ -34,85 +52,743 @@ func NewPublisherService(cfg *config.Config, db *gorm.DB, logger *zap.Logger, no
 		manager:           publisher.NewPublishManager(logger, db),
 		monitoringService: NewMonitoringService(db, logger),
 		notionService:     notionService,
+		locker:            NewLocker(db, logger, cfg.Database.AdvisoryLocks),
 	}
 
 	// Register publishers
-	service.registerPublishers()
+	service.registerPublishers(service.manager, &cfg.Publisher)
+	service.setupCircuitBreaker()
+	service.setupEnrichment()
+	service.setupQuarantine()
+	service.setupRouting(&cfg.Publisher)
+	service.registerHooks()
 
 	return service
 }
 
-func (s *PublisherService) registerPublishers() {
+// Locker returns the locker coordinating the replicas sharing the database. Without a service
+// it is nil, which runs everything without locking.
+func (s *PublisherService) Locker() *Locker {
+	if s == nil {
+		return nil
+	}
+	return s.locker
+}
+
+// setupCircuitBreaker configures the platform circuit breaker and alerts on state changes
+func (s *PublisherService) setupCircuitBreaker() {
+	cbConfig := s.config.Publisher.CircuitBreaker
+	if !cbConfig.Enabled {
+		return
+	}
+
+	breaker := publisher.NewCircuitBreaker(cbConfig.FailureThreshold, cbConfig.CoolDown)
+	breaker.OnStateChange(func(platform string, from, to publisher.CircuitState, lastError string) {
+		s.logger.Warn("Platform circuit state changed",
+			zap.String("platform", platform),
+			zap.String("from", string(from)),
+			zap.String("to", string(to)),
+			zap.String("last_error", lastError))
+
+		switch to {
+		case publisher.CircuitOpen:
+			s.monitoringService.RecordError("ERROR", "circuit_breaker",
+				fmt.Sprintf("Circuit opened for %s", platform),
+				fmt.Sprintf("Publishing to %s paused for %s after repeated failures: %s", platform, cbConfig.CoolDown, lastError),
+				WithPlatform(platform))
+		case publisher.CircuitClosed:
+			s.monitoringService.RecordError("INFO", "circuit_breaker",
+				fmt.Sprintf("Circuit closed for %s", platform),
+				fmt.Sprintf("Publishing to %s resumed", platform),
+				WithPlatform(platform))
+		}
+	})
+
+	s.manager.SetCircuitBreaker(breaker)
+}
+
+// setupRouting configures the rules routing pages without a Platform property, and announces
+// the pages published to the canonical platform on the enabled chat apps; invalid rules are
+// logged and leave the current rules in place
+func (s *PublisherService) setupRouting(settings *config.PublisherConfig) {
+	var announcers []string
+	if settings.Discord.Enabled {
+		announcers = append(announcers, announce.DiscordPlatformName)
+	}
+	if settings.Slack.Enabled {
+		announcers = append(announcers, announce.SlackPlatformName)
+	}
+	s.manager.SetFollowerPlatforms(settings.CanonicalPlatform, announcers)
+
+	rules, err := publisher.ParseRoutingRules(settings.Routing.Rules)
+	if err != nil {
+		s.logger.Error("Invalid routing rules, keeping the current rules", zap.Error(err))
+		return
+	}
+	s.manager.SetRoutingRules(rules)
+}
+
+// RoutePage returns the platforms a page would be published to, and the routing rules it matched
+func (s *PublisherService) RoutePage(pageID string) (*publisher.Route, error) {
+	var page models.NotionPage
+	if err := s.db.Where("notion_id = ?", notion.NormalizePageID(pageID)).First(&page).Error; err != nil {
+		return nil, fmt.Errorf("page not found: %w", err)
+	}
+	return s.manager.RoutePage(&page), nil
+}
+
+// setupQuarantine quarantines jobs that keep failing and records the last failure, including the
+// stack of a recovered panic, in the error log
+func (s *PublisherService) setupQuarantine() {
+	s.manager.SetQuarantine(s.config.Publisher.QuarantineAfter, func(job *models.DistributionJob, platform string, attempts int, cause error) {
+		options := []ErrorLogOption{
+			WithPlatform(platform),
+			WithPage(job.PageID),
+			WithJob(job.ID),
+			WithErrorType(ErrorTypeQuarantined),
+			WithErrorCode(publisher.CodeOf(cause)),
+			WithContext(map[string]interface{}{"attempts": attempts}),
+		}
+		var panicErr *publisher.PanicError
+		if errors.As(cause, &panicErr) {
+			options = append(options, WithStackTrace(panicErr.Stack))
+		}
+
+		s.monitoringService.RecordError("ERROR", "publisher",
+			fmt.Sprintf("Job quarantined on %s", platform),
+			fmt.Sprintf("Publishing to %s stopped after %d failed attempts, republish the job to retry: %s", platform, attempts, cause.Error()),
+			options...)
+	})
+}
+
+// registerHooks adds the configured publish hooks to the manager
+func (s *PublisherService) registerHooks() {
+	// Variants run first, so the other hooks only see the section a platform publishes
+	if variants := s.config.Publisher.Variants; variants.Enabled {
+		hook, err := publisher.NewVariantHook(variants.Marker, variants.Sections)
+		if err != nil {
+			s.logger.Error("Invalid content variant configuration, variants disabled", zap.Error(err))
+		} else {
+			s.manager.RegisterHook(hook)
+		}
+	}
+
+	lintConfig := s.config.Publisher.Lint
+	linter, err := publisher.NewLinter(lintConfig.Rules, lintConfig.MaxTitleLength, lintConfig.MinWords)
+	if err != nil {
+		s.logger.Error("Invalid lint configuration, linting disabled", zap.Error(err))
+	} else {
+		s.linter = linter
+		if lintConfig.Enabled {
+			s.manager.RegisterHook(publisher.NewLintHook(linter))
+		}
+	}
+
+	// Xiaohongshu notes are made of cards, whether or not pages get cover cards
+	if s.config.Publisher.Cards.Enabled || s.config.Publisher.Xiaohongshu.Enabled {
+		cards, err := NewCardService(&s.config.Publisher.Cards, s.logger)
+		if err != nil {
+			s.logger.Error("Failed to set up cards", zap.Error(err))
+		} else {
+			s.manager.SetCardRenderer(cards)
+			if s.config.Publisher.Cards.Enabled {
+				s.manager.RegisterHook(&cardHook{cards: cards})
+			}
+		}
+	}
+
+	if s.config.Publisher.Series.Enabled {
+		s.manager.RegisterHook(&seriesHook{db: s.db})
+	}
+
+	// Microblog posts, announcements and promotions link to the post on the canonical platform,
+	// so they wait for it
+	if canonical := s.config.Publisher.CanonicalPlatform; canonical != "" {
+		s.manager.RegisterHook(&canonicalLinkHook{db: s.db, platform: canonical})
+	}
+
+	if imageHost := s.config.Publisher.ImageHost; imageHost.Enabled {
+		uploader, err := imagehost.NewUploader(imagehost.Config{
+			Provider:        imageHost.Provider,
+			Bucket:          imageHost.Bucket,
+			AccessKeyID:     imageHost.AccessKeyID,
+			SecretAccessKey: imageHost.SecretAccessKey,
+			Region:          imageHost.Region,
+			Endpoint:        imageHost.Endpoint,
+			PublicURL:       imageHost.PublicURL,
+			Prefix:          imageHost.Prefix,
+		})
+		if err != nil {
+			s.logger.Error("Invalid image host configuration, image rehosting disabled", zap.Error(err))
+		} else {
+			s.manager.RegisterHook(newImageHostHook(uploader, strings.Split(imageHost.Platforms, ",")))
+		}
+	}
+
+	if linkCheck := s.config.Publisher.LinkCheck; linkCheck.Enabled {
+		s.manager.RegisterHook(publisher.NewLinkCheckHook(
+			linkCheck.Timeout,
+			linkCheck.Concurrency,
+			linkCheck.FailOnDead,
+			strings.Split(linkCheck.IgnoreHosts, ","),
+		))
+	}
+
+	if utm := s.config.Publisher.UTM; utm.Enabled {
+		sources := make(map[string]string)
+		for _, pair := range strings.Split(utm.Sources, ",") {
+			// Split at the last colon, platform names may name an account like wechat-official:en
+			pair = strings.TrimSpace(pair)
+			separator := strings.LastIndex(pair, ":")
+			if separator <= 0 {
+				continue
+			}
+			platform, source := pair[:separator], pair[separator+1:]
+			if strings.TrimSpace(source) == "" {
+				continue
+			}
+			sources[strings.TrimSpace(platform)] = strings.TrimSpace(source)
+		}
+		s.manager.RegisterHook(publisher.NewUTMHook(
+			sources,
+			utm.Medium,
+			utm.Campaign,
+			strings.Split(utm.IgnoreHosts, ","),
+		))
+	}
+
+	hooksConfig := s.config.Publisher.Hooks
+	if hooksConfig.WebhookURL == "" {
+		return
+	}
+
+	stages, err := publisher.ParseHookStages(hooksConfig.WebhookStages)
+	if err != nil {
+		s.logger.Error("Invalid publish hook stages, webhook disabled", zap.Error(err))
+		return
+	}
+
+	s.manager.RegisterHook(publisher.NewWebhookHook(
+		hooksConfig.WebhookURL,
+		hooksConfig.WebhookSecret,
+		stages,
+		hooksConfig.FailOnError,
+		hooksConfig.Timeout,
+	))
+}
+
+// GetCircuitStatuses returns the circuit state of every platform that has been published to
+func (s *PublisherService) GetCircuitStatuses() []publisher.CircuitStatus {
+	return s.manager.CircuitBreaker().Statuses()
+}
+
+// ResetCircuit manually closes a platform circuit
+func (s *PublisherService) ResetCircuit(platformName string) {
+	s.manager.CircuitBreaker().Reset(platformName)
+}
+
+func (s *PublisherService) registerPublishers(manager *publisher.Manager, settings *config.PublisherConfig) {
+	if settings.Sandbox {
+		s.registerSandboxPublishers(manager, settings)
+		return
+	}
+
 	// Register Al-Folio Blog Publisher
-	if s.config.Publisher.AlFolio.Enabled {
+	if settings.AlFolio.Enabled {
 		alFolioPublisher := al_folio.NewAlFolioPublisher(s.logger)
-		if err := s.manager.RegisterPublisher(alFolioPublisher); err != nil {
+		// Publisher configs are strings, so the front matter fields are passed as YAML
+		frontMatter := ""
+		if len(settings.AlFolio.FrontMatter) > 0 {
+			if data, err := yaml.Marshal(settings.AlFolio.FrontMatter); err == nil {
+				frontMatter = string(data)
+			}
+		}
+		if err := manager.RegisterPublisher(alFolioPublisher); err != nil {
 			s.logger.Error("Failed to register Al-Folio blog publisher", zap.Error(err))
 		} else {
 			// Set platform configuration
 			cfg := publisher.PublishConfig{
 				PlatformName: "al-folio",
-				Enabled:      s.config.Publisher.AlFolio.Enabled,
+				Enabled:      settings.AlFolio.Enabled,
 				Config: map[string]string{
-					"repo_url":       s.config.Publisher.AlFolio.RepoURL,
-					"branch":         s.config.Publisher.AlFolio.Branch,
-					"workspace_dir":  s.config.Publisher.AlFolio.WorkspaceDir,
-					"base_url":       s.config.Publisher.AlFolio.BaseURL,
-					"commit_message": s.config.Publisher.AlFolio.CommitMessage,
-					"auto_publish":   fmt.Sprintf("%t", s.config.Publisher.AlFolio.AutoPublish),
-					"git_username":   s.config.Publisher.AlFolio.GitUsername,
-					"git_email":      s.config.Publisher.AlFolio.GitEmail,
+					"repo_url":       settings.AlFolio.RepoURL,
+					"branch":         settings.AlFolio.Branch,
+					"workspace_dir":  settings.AlFolio.WorkspaceDir,
+					"base_url":       settings.AlFolio.BaseURL,
+					"commit_message": settings.AlFolio.CommitMessage,
+					"auto_publish":   fmt.Sprintf("%t", settings.AlFolio.AutoPublish),
+					"git_username":   settings.AlFolio.GitUsername,
+					"git_email":      settings.AlFolio.GitEmail,
+					"slug_strategy":  settings.AlFolio.SlugStrategy,
+					"git_token":      settings.AlFolio.GitToken,
+					"ssh_key_path":   settings.AlFolio.SSHKeyPath,
+					"pr_mode":        fmt.Sprintf("%t", settings.AlFolio.PRMode),
+					"pr_provider":    settings.AlFolio.PRProvider,
+					"pr_api_url":     settings.AlFolio.PRAPIURL,
+					"footnotes":      fmt.Sprintf("%t", settings.AlFolio.Footnotes),
+					"front_matter":   frontMatter,
 				},
 			}
-			s.manager.SetPlatformConfig("al-folio", cfg)
+			manager.SetPlatformConfig("al-folio", cfg)
+
+			if dictPath := settings.AlFolio.PinyinDict; dictPath != "" {
+				if count, err := util.LoadPinyinDictionary(dictPath); err != nil {
+					s.logger.Error("Failed to load pinyin dictionary", zap.String("path", dictPath), zap.Error(err))
+				} else {
+					s.logger.Info("Pinyin dictionary loaded", zap.String("path", dictPath), zap.Int("entries", count))
+				}
+			}
 			s.logger.Info("Al-Folio blog publisher registered and configured")
 		}
 	}
 
-	// Register WeChat Official Account Publisher
-	if s.config.Publisher.WeChatOfficial.Enabled {
-		wechatPublisher := wechat_official.NewWeChatOfficialPublisher(s.logger)
-		if err := s.manager.RegisterPublisher(wechatPublisher); err != nil {
-			s.logger.Error("Failed to register WeChat Official Account publisher", zap.Error(err))
-		} else {
-			// Set platform configuration
-			cfg := publisher.PublishConfig{
-				PlatformName: "wechat-official",
-				Enabled:      s.config.Publisher.WeChatOfficial.Enabled,
-				Config: map[string]string{
-					"app_id":                s.config.Publisher.WeChatOfficial.AppID,
-					"app_secret":            s.config.Publisher.WeChatOfficial.AppSecret,
-					"auto_publish":          fmt.Sprintf("%t", s.config.Publisher.WeChatOfficial.AutoPublish),
-					"need_open_comment":     fmt.Sprintf("%d", s.config.Publisher.WeChatOfficial.NeedOpenComment),
-					"only_fans_can_comment": fmt.Sprintf("%d", s.config.Publisher.WeChatOfficial.OnlyFansCanComment),
-					"default_thumb_media_id": s.config.Publisher.WeChatOfficial.DefaultThumbMediaID,
-				},
-			}
-			s.manager.SetPlatformConfig("wechat-official", cfg)
-			s.logger.Info("WeChat Official Account publisher registered and configured")
+	// Register WeChat Official Account Publishers
+	if settings.WeChatOfficial.Enabled {
+		s.registerWeChatOfficialPublisher(manager, "", settings.WeChatOfficial)
+	}
+	for _, name := range accountNames(settings.WeChatOfficial.Accounts) {
+		if account := settings.WeChatOfficial.Accounts[name]; account.Enabled {
+			s.registerWeChatOfficialPublisher(manager, name, account)
 		}
 	}
 
-	// Register Substack Publisher
-	if s.config.Publisher.Substack.Enabled {
-		substackPublisher := substack.NewSubstackPublisher(s.logger)
-		if err := s.manager.RegisterPublisher(substackPublisher); err != nil {
-			s.logger.Error("Failed to register Substack publisher", zap.Error(err))
-		} else {
-			// Set platform configuration
-			cfg := publisher.PublishConfig{
-				PlatformName: "substack",
-				Enabled:      s.config.Publisher.Substack.Enabled,
-				Config: map[string]string{
-					"domain":       s.config.Publisher.Substack.Domain,
-					"cookie":       s.config.Publisher.Substack.Cookie,
-					"auto_publish": fmt.Sprintf("%t", s.config.Publisher.Substack.AutoPublish),
-				},
+	// Register Substack Publishers
+	if settings.Substack.Enabled {
+		s.registerSubstackPublisher(manager, "", settings.Substack)
+	}
+	for _, name := range accountNames(settings.Substack.Accounts) {
+		if account := settings.Substack.Accounts[name]; account.Enabled {
+			s.registerSubstackPublisher(manager, name, account)
+		}
+	}
+
+	// Register Xiaohongshu Publisher
+	if settings.Xiaohongshu.Enabled {
+		s.registerXiaohongshuPublisher(manager, settings.Xiaohongshu)
+	}
+
+	// Register Mastodon and Bluesky Publishers
+	if settings.Mastodon.Enabled {
+		s.registerMastodonPublisher(manager, settings.Mastodon)
+	}
+	if settings.Bluesky.Enabled {
+		s.registerBlueskyPublisher(manager, settings.Bluesky)
+	}
+
+	// Register Discord and Slack Announcement Publishers
+	if settings.Discord.Enabled {
+		s.registerDiscordPublisher(manager, settings.Discord)
+	}
+	if settings.Slack.Enabled {
+		s.registerSlackPublisher(manager, settings.Slack)
+	}
+
+	// Register Confluence Publisher
+	if settings.Confluence.Enabled {
+		s.registerConfluencePublisher(manager, settings.Confluence)
+	}
+
+	// Register Buffer and Typefully Promotion Publishers
+	if settings.Buffer.Enabled {
+		s.registerBufferPublisher(manager, settings.Buffer)
+	}
+	if settings.Typefully.Enabled {
+		s.registerTypefullyPublisher(manager, settings.Typefully)
+	}
+
+	// Register Mock Publisher
+	if settings.Mock.Enabled {
+		s.registerMockPublisher(manager, settings, mock.PlatformName)
+	}
+}
+
+// registerWeChatOfficialPublisher registers an Official Account; account is empty for the
+// default account and names the others
+func (s *PublisherService) registerWeChatOfficialPublisher(manager *publisher.Manager, account string, settings config.WeChatOfficialConfig) {
+	wechatPublisher := wechat_official.NewWeChatOfficialPublisher(s.logger)
+	wechatPublisher.(publisher.AccountPublisher).SetAccountName(account)
+	platformName := wechatPublisher.GetPlatformName()
+	if err := manager.RegisterPublisher(wechatPublisher); err != nil {
+		s.logger.Error("Failed to register WeChat Official Account publisher",
+			zap.String("platform", platformName), zap.Error(err))
+	} else {
+		// Publisher configs are strings, so the callout palette is passed as YAML
+		calloutColors := ""
+		if len(settings.CalloutColors) > 0 {
+			if data, err := yaml.Marshal(settings.CalloutColors); err == nil {
+				calloutColors = string(data)
 			}
-			s.manager.SetPlatformConfig("substack", cfg)
-			s.logger.Info("Substack publisher registered and configured")
 		}
+
+		// Set platform configuration
+		cfg := publisher.PublishConfig{
+			PlatformName: platformName,
+			Enabled:      settings.Enabled,
+			Config: map[string]string{
+				"app_id":                settings.AppID,
+				"app_secret":            settings.AppSecret,
+				"auto_publish":          fmt.Sprintf("%t", settings.AutoPublish),
+				"need_open_comment":     fmt.Sprintf("%d", settings.NeedOpenComment),
+				"only_fans_can_comment": fmt.Sprintf("%d", settings.OnlyFansCanComment),
+				"default_thumb_media_id": settings.DefaultThumbMediaID,
+				"auto_thumbnail":        fmt.Sprintf("%t", settings.AutoThumbnail),
+				"use_stable_token":      fmt.Sprintf("%t", settings.UseStableToken),
+				"api_base_url":          settings.APIBaseURL,
+				"proxy_url":             settings.ProxyURL,
+				"code_theme":            settings.CodeTheme,
+				"callout_colors":        calloutColors,
+				"preview_wxname":        settings.PreviewWxName,
+			},
+		}
+		manager.SetPlatformConfig(platformName, cfg)
+		s.logger.Info("WeChat Official Account publisher registered and configured", zap.String("platform", platformName))
+	}
+}
+
+// registerSubstackPublisher registers a Substack publication; account is empty for the default
+// publication and names the others
+func (s *PublisherService) registerSubstackPublisher(manager *publisher.Manager, account string, settings config.SubstackConfig) {
+	substackPublisher := substack.NewSubstackPublisher(s.logger)
+	substackPublisher.(publisher.AccountPublisher).SetAccountName(account)
+	platformName := substackPublisher.GetPlatformName()
+	if err := manager.RegisterPublisher(substackPublisher); err != nil {
+		s.logger.Error("Failed to register Substack publisher", zap.String("platform", platformName), zap.Error(err))
+	} else {
+		// Set platform configuration
+		cfg := publisher.PublishConfig{
+			PlatformName: platformName,
+			Enabled:      settings.Enabled,
+			Config: map[string]string{
+				"domain":          settings.Domain,
+				"cookie":          settings.Cookie,
+				"auto_publish":    fmt.Sprintf("%t", settings.AutoPublish),
+				"session_id":      settings.SessionID,
+				"login_link":      settings.LoginLink,
+				"audience":        settings.Audience,
+				"send_email":      fmt.Sprintf("%t", settings.SendEmail),
+				"section_mapping": settings.SectionMapping,
+				"inject_blocks":   settings.InjectBlocks,
+				"tts_provider":    settings.TTSProvider,
+				"tts_api_key":     settings.TTSAPIKey,
+				"tts_model":       settings.TTSModel,
+				"tts_voice":       settings.TTSVoice,
+				"tts_base_url":    settings.TTSBaseURL,
+			},
+		}
+		manager.SetPlatformConfig(platformName, cfg)
+		s.logger.Info("Substack publisher registered and configured", zap.String("platform", platformName))
+	}
+}
+
+// registerXiaohongshuPublisher registers the Xiaohongshu account notes are posted to
+func (s *PublisherService) registerXiaohongshuPublisher(manager *publisher.Manager, settings config.XiaohongshuConfig) {
+	xiaohongshuPublisher := xiaohongshu.NewXiaohongshuPublisher(s.logger)
+	if err := manager.RegisterPublisher(xiaohongshuPublisher); err != nil {
+		s.logger.Error("Failed to register Xiaohongshu publisher", zap.Error(err))
+		return
 	}
+
+	cfg := publisher.PublishConfig{
+		PlatformName: xiaohongshu.PlatformName,
+		Enabled:      settings.Enabled,
+		Config: map[string]string{
+			"cookie":        settings.Cookie,
+			"sign_url":      settings.SignURL,
+			"auto_publish":  fmt.Sprintf("%t", settings.AutoPublish),
+			"private":       fmt.Sprintf("%t", settings.Private),
+			"max_cards":     fmt.Sprintf("%d", settings.MaxCards),
+			"workspace_dir": settings.WorkspaceDir,
+			"proxy_url":     settings.ProxyURL,
+		},
+	}
+	manager.SetPlatformConfig(xiaohongshu.PlatformName, cfg)
+	s.logger.Info("Xiaohongshu publisher registered and configured")
+}
+
+// registerMastodonPublisher registers the Mastodon account pages are posted to
+func (s *PublisherService) registerMastodonPublisher(manager *publisher.Manager, settings config.MastodonConfig) {
+	mastodonPublisher := microblog.NewMastodonPublisher(s.logger)
+	if err := manager.RegisterPublisher(mastodonPublisher); err != nil {
+		s.logger.Error("Failed to register Mastodon publisher", zap.Error(err))
+		return
+	}
+
+	cfg := publisher.PublishConfig{
+		PlatformName: microblog.MastodonPlatformName,
+		Enabled:      settings.Enabled,
+		Config: map[string]string{
+			"instance_url":   settings.InstanceURL,
+			"access_token":   settings.AccessToken,
+			"mode":           settings.Mode,
+			"visibility":     settings.Visibility,
+			"max_characters": fmt.Sprintf("%d", settings.MaxCharacters),
+			"language":       settings.Language,
+			"auto_publish":   fmt.Sprintf("%t", settings.AutoPublish),
+			"workspace_dir":  settings.WorkspaceDir,
+			"proxy_url":      settings.ProxyURL,
+		},
+	}
+	manager.SetPlatformConfig(microblog.MastodonPlatformName, cfg)
+	s.logger.Info("Mastodon publisher registered and configured")
+}
+
+// registerBlueskyPublisher registers the Bluesky account pages are posted to
+func (s *PublisherService) registerBlueskyPublisher(manager *publisher.Manager, settings config.BlueskyConfig) {
+	blueskyPublisher := microblog.NewBlueskyPublisher(s.logger)
+	if err := manager.RegisterPublisher(blueskyPublisher); err != nil {
+		s.logger.Error("Failed to register Bluesky publisher", zap.Error(err))
+		return
+	}
+
+	cfg := publisher.PublishConfig{
+		PlatformName: microblog.BlueskyPlatformName,
+		Enabled:      settings.Enabled,
+		Config: map[string]string{
+			"service_url":   settings.ServiceURL,
+			"handle":        settings.Handle,
+			"app_password":  settings.AppPassword,
+			"mode":          settings.Mode,
+			"language":      settings.Language,
+			"auto_publish":  fmt.Sprintf("%t", settings.AutoPublish),
+			"workspace_dir": settings.WorkspaceDir,
+			"proxy_url":     settings.ProxyURL,
+		},
+	}
+	manager.SetPlatformConfig(microblog.BlueskyPlatformName, cfg)
+	s.logger.Info("Bluesky publisher registered and configured")
+}
+
+// registerDiscordPublisher registers the Discord webhooks pages are announced with
+func (s *PublisherService) registerDiscordPublisher(manager *publisher.Manager, settings config.DiscordConfig) {
+	discordPublisher := announce.NewDiscordPublisher(s.logger)
+	if err := manager.RegisterPublisher(discordPublisher); err != nil {
+		s.logger.Error("Failed to register Discord publisher", zap.Error(err))
+		return
+	}
+
+	cfg := publisher.PublishConfig{
+		PlatformName: announce.DiscordPlatformName,
+		Enabled:      settings.Enabled,
+		Config: map[string]string{
+			"webhook_urls": settings.WebhookURLs,
+			"username":     settings.Username,
+			"avatar_url":   settings.AvatarURL,
+			"color":        settings.Color,
+			"mention":      settings.Mention,
+			"proxy_url":    settings.ProxyURL,
+		},
+	}
+	manager.SetPlatformConfig(announce.DiscordPlatformName, cfg)
+	s.logger.Info("Discord publisher registered and configured")
+}
+
+// registerSlackPublisher registers the Slack channels pages are announced in
+func (s *PublisherService) registerSlackPublisher(manager *publisher.Manager, settings config.SlackConfig) {
+	slackPublisher := announce.NewSlackPublisher(s.logger)
+	if err := manager.RegisterPublisher(slackPublisher); err != nil {
+		s.logger.Error("Failed to register Slack publisher", zap.Error(err))
+		return
+	}
+
+	cfg := publisher.PublishConfig{
+		PlatformName: announce.SlackPlatformName,
+		Enabled:      settings.Enabled,
+		Config: map[string]string{
+			"bot_token":   settings.BotToken,
+			"channels":    settings.Channels,
+			"button_text": settings.ButtonText,
+			"proxy_url":   settings.ProxyURL,
+		},
+	}
+	manager.SetPlatformConfig(announce.SlackPlatformName, cfg)
+	s.logger.Info("Slack publisher registered and configured")
+}
+
+// registerConfluencePublisher registers the Confluence space pages are mirrored to
+func (s *PublisherService) registerConfluencePublisher(manager *publisher.Manager, settings config.ConfluenceConfig) {
+	confluencePublisher := confluence.NewConfluencePublisher(s.logger)
+	if err := manager.RegisterPublisher(confluencePublisher); err != nil {
+		s.logger.Error("Failed to register Confluence publisher", zap.Error(err))
+		return
+	}
+
+	cfg := publisher.PublishConfig{
+		PlatformName: confluence.PlatformName,
+		Enabled:      settings.Enabled,
+		Config: map[string]string{
+			"base_url":       settings.BaseURL,
+			"email":          settings.Email,
+			"api_token":      settings.APIToken,
+			"space_key":      settings.SpaceKey,
+			"parent_page_id": settings.ParentPageID,
+			"auto_publish":   fmt.Sprintf("%t", settings.AutoPublish),
+			"proxy_url":      settings.ProxyURL,
+		},
+	}
+	manager.SetPlatformConfig(confluence.PlatformName, cfg)
+	s.logger.Info("Confluence publisher registered and configured")
+}
+
+// registerBufferPublisher registers the Buffer account promotions are scheduled with
+func (s *PublisherService) registerBufferPublisher(manager *publisher.Manager, settings config.BufferConfig) {
+	bufferPublisher := promo.NewBufferPublisher(s.logger)
+	if err := manager.RegisterPublisher(bufferPublisher); err != nil {
+		s.logger.Error("Failed to register Buffer publisher", zap.Error(err))
+		return
+	}
+
+	cfg := publisher.PublishConfig{
+		PlatformName: promo.BufferPlatformName,
+		Enabled:      settings.Enabled,
+		Config: map[string]string{
+			"access_token": settings.AccessToken,
+			"profile_ids":  settings.ProfileIDs,
+			"schedule":     settings.Schedule,
+			"template":     settings.Template,
+			"templates":    promoTemplates(settings.Templates),
+			"proxy_url":    settings.ProxyURL,
+		},
+	}
+	manager.SetPlatformConfig(promo.BufferPlatformName, cfg)
+	s.logger.Info("Buffer publisher registered and configured")
+}
+
+// registerTypefullyPublisher registers the Typefully account promotions are scheduled with
+func (s *PublisherService) registerTypefullyPublisher(manager *publisher.Manager, settings config.TypefullyConfig) {
+	typefullyPublisher := promo.NewTypefullyPublisher(s.logger)
+	if err := manager.RegisterPublisher(typefullyPublisher); err != nil {
+		s.logger.Error("Failed to register Typefully publisher", zap.Error(err))
+		return
+	}
+
+	cfg := publisher.PublishConfig{
+		PlatformName: promo.TypefullyPlatformName,
+		Enabled:      settings.Enabled,
+		Config: map[string]string{
+			"api_key":   settings.APIKey,
+			"schedule":  settings.Schedule,
+			"template":  settings.Template,
+			"templates": promoTemplates(settings.Templates),
+			"threadify": fmt.Sprintf("%t", settings.Threadify),
+			"proxy_url": settings.ProxyURL,
+		},
+	}
+	manager.SetPlatformConfig(promo.TypefullyPlatformName, cfg)
+	s.logger.Info("Typefully publisher registered and configured")
+}
+
+// promoTemplates passes the promotion templates per network as YAML, publisher configs are
+// strings
+func promoTemplates(templates map[string]string) string {
+	if len(templates) == 0 {
+		return ""
+	}
+	data, err := yaml.Marshal(templates)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// accountNames returns the names of the configured accounts of a platform in a stable order
+func accountNames[T any](accounts map[string]T) []string {
+	names := make([]string, 0, len(accounts))
+	for name := range accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// registerSandboxPublishers registers mock publishers in place of every real platform, so
+// pages are routed and recorded as usual while their content only ends up on disk
+func (s *PublisherService) registerSandboxPublishers(manager *publisher.Manager, settings *config.PublisherConfig) {
+	s.logger.Warn("Sandbox mode enabled, content is written to disk instead of being published",
+		zap.String("output_dir", settings.Mock.OutputDir))
+
+	platformNames := []string{"al-folio", "wechat-official", "substack", xiaohongshu.PlatformName,
+		microblog.MastodonPlatformName, microblog.BlueskyPlatformName,
+		announce.DiscordPlatformName, announce.SlackPlatformName, confluence.PlatformName,
+		promo.BufferPlatformName, promo.TypefullyPlatformName, mock.PlatformName}
+	for _, name := range accountNames(settings.WeChatOfficial.Accounts) {
+		platformNames = append(platformNames, publisher.AccountPlatformName("wechat-official", name))
+	}
+	for _, name := range accountNames(settings.Substack.Accounts) {
+		platformNames = append(platformNames, publisher.AccountPlatformName("substack", name))
+	}
+
+	for _, platformName := range platformNames {
+		s.registerMockPublisher(manager, settings, platformName)
+	}
+}
+
+func (s *PublisherService) registerMockPublisher(manager *publisher.Manager, settings *config.PublisherConfig, platformName string) {
+	mockPublisher := mock.NewMockPublisher(platformName, s.logger)
+	if err := manager.RegisterPublisher(mockPublisher); err != nil {
+		s.logger.Error("Failed to register mock publisher", zap.String("platform", platformName), zap.Error(err))
+		return
+	}
+
+	cfg := publisher.PublishConfig{
+		PlatformName: platformName,
+		Enabled:      true,
+		Config: map[string]string{
+			"output_dir":   settings.Mock.OutputDir,
+			"failure_rate": fmt.Sprintf("%g", settings.Mock.FailureRate),
+			"fail_tag":     settings.Mock.FailTag,
+			"delay":        settings.Mock.Delay.String(),
+		},
+	}
+	manager.SetPlatformConfig(platformName, cfg)
+	s.logger.Info("Mock publisher registered and configured", zap.String("platform", platformName))
+}
+
+// PublisherReloadReport lists the platforms a configuration reload changed
+type PublisherReloadReport struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Updated []string `json:"updated"`
+}
+
+// ReloadPublishers registers the publishers of a reloaded configuration in place of the current
+// ones. Added and reconfigured platforms get their circuit reset, so a new cookie or secret is
+// tried on the next publish instead of after the cool-down.
+func (s *PublisherService) ReloadPublishers(settings *config.PublisherConfig) *PublisherReloadReport {
+	before := s.manager.PlatformConfigs()
+
+	staging := publisher.NewPublishManager(s.logger, s.db)
+	s.registerPublishers(staging, settings)
+	s.manager.ReplacePublishers(staging)
+	s.setupRouting(settings)
+
+	after := s.manager.PlatformConfigs()
+	report := &PublisherReloadReport{Added: []string{}, Removed: []string{}, Updated: []string{}}
+	for platformName, cfg := range after {
+		previous, existed := before[platformName]
+		switch {
+		case !existed:
+			report.Added = append(report.Added, platformName)
+		case previous.Enabled != cfg.Enabled || !maps.Equal(previous.Config, cfg.Config):
+			report.Updated = append(report.Updated, platformName)
+		default:
+			continue
+		}
+		s.manager.CircuitBreaker().Reset(platformName)
+	}
+	for platformName := range before {
+		if _, exists := after[platformName]; !exists {
+			report.Removed = append(report.Removed, platformName)
+		}
+	}
+	sort.Strings(report.Added)
+	sort.Strings(report.Removed)
+	sort.Strings(report.Updated)
+
+	s.logger.Info("Publishers reloaded",
+		zap.Strings("added", report.Added),
+		zap.Strings("removed", report.Removed),
+		zap.Strings("updated", report.Updated))
+	return report
 }
 
 // PublishPage publishes a single page to all configured platforms
@@ -127,11 +803,15 @@ func (s *PublisherService) PublishPage(ctx context.Context, pageID string) (map[
 	if page.Status != "Done" {
 		return nil, fmt.Errorf("page status is not 'Done', current status: %s", page.Status)
 	}
+	if page.IsArchived() {
+		return nil, fmt.Errorf("%w: %s", ErrPageArchived, page.ArchiveReason)
+	}
 
+	route := s.manager.RoutePage(&page)
 	s.logger.Info("Publishing page",
 		zap.String("page_id", pageID),
 		zap.String("title", page.Title),
-		zap.Strings("platforms", page.Platforms))
+		zap.Strings("platforms", route.Platforms))
 
 	// Publish to all platforms
 	results, err := s.manager.PublishToAll(ctx, &page)
@@ -139,36 +819,20 @@ func (s *PublisherService) PublishPage(ctx context.Context, pageID string) (map[
 		// Record error in monitoring
 		s.monitoringService.RecordError("ERROR", "publisher", "Failed to publish page to all platforms", err.Error(),
 			WithPage(page.ID),
+			WithRequest(ctx),
 			WithContext(map[string]interface{}{
 				"page_id":   pageID,
 				"title":     page.Title,
-				"platforms": page.Platforms,
+				"platforms": route.Platforms,
 			}))
 		return nil, fmt.Errorf("failed to publish page: %w", err)
 	}
 
 	// Record metrics for each platform
 	for platformName, result := range results {
+		s.recordPublishResult(ctx, &page, platformName, result)
 		if result.Success {
-			s.monitoringService.RecordMetric("publish_success", "counter", 1, map[string]interface{}{
-				"platform": platformName,
-				"page_id":  pageID,
-			})
-		} else {
-			s.monitoringService.RecordMetric("publish_failure", "counter", 1, map[string]interface{}{
-				"platform": platformName,
-				"page_id":  pageID,
-			})
-			// Record specific error
-			if result.Error != nil {
-				s.monitoringService.RecordError("ERROR", "publisher", fmt.Sprintf("Failed to publish to %s", platformName), result.Error.Error(),
-					WithPlatform(platformName),
-					WithPage(page.ID),
-					WithContext(map[string]interface{}{
-						"page_id": pageID,
-						"title":   page.Title,
-					}))
-			}
+			s.refreshSeries(ctx, &page, platformName)
 		}
 	}
 
@@ -187,6 +851,9 @@ func (s *PublisherService) PublishPageToPlatform(ctx context.Context, pageID str
 	if page.Status != "Done" {
 		return nil, fmt.Errorf("page status is not 'Done', current status: %s", page.Status)
 	}
+	if page.IsArchived() {
+		return nil, fmt.Errorf("%w: %s", ErrPageArchived, page.ArchiveReason)
+	}
 
 	s.logger.Info("Publishing page to platform",
 		zap.String("page_id", pageID),
@@ -197,9 +864,12 @@ func (s *PublisherService) PublishPageToPlatform(ctx context.Context, pageID str
 	result, err := s.manager.PublishSinglePlatform(ctx, &page, platformName, false)
 	if err != nil {
 		// Record error in monitoring
-		s.monitoringService.RecordError("ERROR", "publisher", fmt.Sprintf("Failed to publish to platform %s", platformName), err.Error(),
+		s.monitoringService.RecordError(publishErrorLevel(err), "publisher", fmt.Sprintf("Failed to publish to platform %s", platformName), err.Error(),
 			WithPlatform(platformName),
 			WithPage(page.ID),
+			WithErrorType(errorTypeOf(err)),
+			WithErrorCode(publisher.CodeOf(err)),
+			WithRequest(ctx),
 			WithContext(map[string]interface{}{
 				"page_id": pageID,
 				"title":   page.Title,
@@ -208,28 +878,47 @@ func (s *PublisherService) PublishPageToPlatform(ctx context.Context, pageID str
 	}
 
 	// Record metrics
+	s.recordPublishResult(ctx, &page, platformName, result)
+	if result.Success {
+		s.refreshSeries(ctx, &page, platformName)
+	}
+
+	return result, nil
+}
+
+// recordPublishResult records the publish metric of a platform and, for failures, the error
+func (s *PublisherService) recordPublishResult(ctx context.Context, page *models.NotionPage, platformName string, result *publisher.PublishResult) {
+	// Publishes held for a paused platform haven't failed, they run when it is resumed
+	if errors.Is(result.Error, publisher.ErrPlatformPaused) {
+		return
+	}
+
 	if result.Success {
 		s.monitoringService.RecordMetric("publish_success", "counter", 1, map[string]interface{}{
 			"platform": platformName,
-			"page_id":  pageID,
+			"page_id":  page.NotionID,
 		})
-	} else {
-		s.monitoringService.RecordMetric("publish_failure", "counter", 1, map[string]interface{}{
-			"platform": platformName,
-			"page_id":  pageID,
-		})
-		if result.Error != nil {
-			s.monitoringService.RecordError("ERROR", "publisher", fmt.Sprintf("Failed to publish to %s", platformName), result.Error.Error(),
-				WithPlatform(platformName),
-				WithPage(page.ID),
-				WithContext(map[string]interface{}{
-					"page_id": pageID,
-					"title":   page.Title,
-				}))
-		}
+		return
 	}
 
-	return result, nil
+	s.monitoringService.RecordMetric("publish_failure", "counter", 1, map[string]interface{}{
+		"platform": platformName,
+		"page_id":  page.NotionID,
+	})
+	if result.Error != nil {
+		level := publishErrorLevel(result.Error)
+		s.monitoringService.RecordError(level, "publisher", fmt.Sprintf("Failed to publish to %s", platformName), result.Error.Error(),
+			WithPlatform(platformName),
+			WithPage(page.ID),
+			WithErrorType(errorTypeOf(result.Error)),
+			WithErrorCode(result.ErrorCode),
+			WithRequest(ctx),
+			WithContext(map[string]interface{}{
+				"page_id": page.NotionID,
+				"title":   page.Title,
+			}))
+		s.commentOnFailure(page, platformName, result, level)
+	}
 }
 
 
@@ -255,11 +944,134 @@ func (s *PublisherService) SavePageToDraft(ctx context.Context, pageID string, p
 	return result, nil
 }
 
+// ErrJobNotDraft is returned when promoting a job that did not create a draft
+var ErrJobNotDraft = errors.New("job is not a draft")
+
+// PromoteDraft publishes a draft created by SaveToDraft after it has been reviewed on the platform
+func (s *PublisherService) PromoteDraft(ctx context.Context, jobID uint) (*models.DistributionJob, *publisher.PublishResult, error) {
+	var job models.DistributionJob
+	if err := s.db.Preload("Page").Preload("Platform").First(&job, jobID).Error; err != nil {
+		return nil, nil, err
+	}
+
+	if job.Status != models.JobDraft {
+		return nil, nil, fmt.Errorf("%w: job %d is %s", ErrJobNotDraft, job.ID, job.Status)
+	}
+
+	s.logger.Info("Promoting draft",
+		zap.Uint("job_id", job.ID),
+		zap.String("page_id", job.Page.NotionID),
+		zap.String("platform", job.Platform.Name),
+		zap.String("draft_id", job.PublishID))
+
+	result, err := s.manager.PromoteDraft(ctx, &job)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to promote draft on %s: %w", job.Platform.Name, err)
+	}
+
+	s.recordPublishResult(ctx, &job.Page, job.Platform.Name, result)
+	if result.Success {
+		s.announcePromoted(ctx, &job.Page)
+	}
+	return &job, result, nil
+}
+
+// announcePromoted publishes a page to the followers of its promoted drafts. Pages with drafts
+// may already be marked Published and out of the queues, so they are published right away.
+func (s *PublisherService) announcePromoted(ctx context.Context, page *models.NotionPage) {
+	statuses, err := s.latestJobStatuses(ctx, []models.NotionPage{*page})
+	if err != nil {
+		s.logger.Error("Failed to get jobs of promoted page",
+			zap.String("page_id", page.NotionID),
+			zap.Error(err))
+		return
+	}
+
+	routed := s.manager.RoutePage(page)
+	route := s.manager.RoutePublishedPage(page, statuses[page.ID])
+	for _, platformName := range route.Platforms {
+		if slices.Contains(routed.Platforms, platformName) || !waitsToPublish(route, platformName, statuses[page.ID][platformName]) {
+			continue
+		}
+		s.publishQueued(ctx, page, platformName, page.Priority)
+	}
+}
+
+// PreviewPage returns a page transformed for a platform without publishing it. The publisher
+// is not initialized, so the preview never touches the platform or its workspace.
+func (s *PublisherService) PreviewPage(ctx context.Context, pageID string, platformName string) (*publisher.PublishContent, error) {
+	var page models.NotionPage
+	if err := s.db.Where("notion_id = ?", notion.NormalizePageID(pageID)).First(&page).Error; err != nil {
+		return nil, fmt.Errorf("page not found: %w", err)
+	}
+
+	pub, err := s.manager.GetPublisher(s.manager.MapPlatformName(platformName))
+	if err != nil {
+		return nil, err
+	}
+
+	transformed, err := pub.TransformContent(ctx, *publisher.FromNotionPage(&page))
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform content for %s: %w", platformName, err)
+	}
+
+	return transformed, nil
+}
+
 // GetPublishHistory returns the publishing history for a page
 func (s *PublisherService) GetPublishHistory(ctx context.Context, pageID string) ([]*models.DistributionJob, error) {
 	return s.manager.GetPublishHistory(ctx, pageID)
 }
 
+// GetJobTransitions returns the status changes of a job, oldest first
+func (s *PublisherService) GetJobTransitions(jobID uint) ([]models.JobTransition, error) {
+	var transitions []models.JobTransition
+	err := s.db.Where("job_id = ?", jobID).Order("created_at ASC, id ASC").Find(&transitions).Error
+	return transitions, err
+}
+
+// GetJobLogs returns the status of a job and its log lines after the given line ID, oldest first
+func (s *PublisherService) GetJobLogs(jobID, afterID uint) (string, []models.JobLog, error) {
+	var job models.DistributionJob
+	if err := s.db.Select("id", "status").First(&job, jobID).Error; err != nil {
+		return "", nil, err
+	}
+
+	var logs []models.JobLog
+	err := s.db.Where("job_id = ? AND id > ?", jobID, afterID).Order("id ASC").Find(&logs).Error
+	return job.Status, logs, err
+}
+
+// LintPage checks a page against the lint rules for the given platform, or for every platform
+// the page is published to when platformName is empty
+func (s *PublisherService) LintPage(pageID, platformName string) ([]*publisher.LintReport, error) {
+	if s.linter == nil {
+		return nil, fmt.Errorf("linting is not configured")
+	}
+
+	var page models.NotionPage
+	if err := s.db.Where("notion_id = ?", notion.NormalizePageID(pageID)).First(&page).Error; err != nil {
+		return nil, fmt.Errorf("page not found: %w", err)
+	}
+
+	platforms := []string{platformName}
+	if platformName == "" {
+		platforms = s.manager.RoutePage(&page).Platforms
+	}
+
+	content := publisher.FromNotionPage(&page)
+	// Pages without a cover get a generated card when they are published
+	if content.Metadata["cover_url"] == "" && s.config.Publisher.Cards.Enabled {
+		content.Metadata["cover_url"] = "generated"
+	}
+
+	reports := make([]*publisher.LintReport, 0, len(platforms))
+	for _, platform := range platforms {
+		reports = append(reports, s.linter.Lint(content, platform))
+	}
+	return reports, nil
+}
+
 // GetAvailablePlatforms returns all available publishing platforms
 func (s *PublisherService) GetAvailablePlatforms() []string {
 	publishers := s.manager.GetAvailablePublishers()
@@ -272,158 +1084,250 @@ func (s *PublisherService) GetAvailablePlatforms() []string {
 	return platforms
 }
 
-// ProcessPendingPages processes all pages that are ready for publishing
+// CheckPlatformCredentials verifies the configured credentials of a registered platform
+func (s *PublisherService) CheckPlatformCredentials(ctx context.Context, platformName string) error {
+	return s.manager.CheckCredentials(ctx, platformName)
+}
+
+// ProcessPendingPages publishes the next pages waiting in the per-platform queues, see
+// PublishQueues and scheduleFairly, then marks the pages published on all their platforms
 func (s *PublisherService) ProcessPendingPages(ctx context.Context) error {
-	// Find pages that are Done but haven't been fully published to all required platforms
-	var pages []models.NotionPage
+	queues, err := s.PublishQueues(ctx)
+	if err != nil {
+		return err
+	}
+	scheduled := scheduleFairly(queues, s.publishesPerCycle())
 
-	// Get pages that are Done and either have no distribution jobs or have failed/pending jobs
-	if err := s.db.Where("status = ?", "Done").
-		Limit(10). // Process in batches
-		Find(&pages).Error; err != nil {
-		return fmt.Errorf("failed to get pending pages: %w", err)
+	s.logger.Info("Processing pending pages",
+		zap.Int("count", len(scheduled)),
+		zap.Int("platforms", len(queues)))
+
+	pages := make(map[uint]*models.NotionPage)
+	var published []*models.NotionPage
+	for _, item := range scheduled {
+		page, ok := pages[item.page]
+		if !ok {
+			page = &models.NotionPage{}
+			if err := s.db.First(page, item.page).Error; err != nil {
+				s.logger.Error("Failed to load pending page",
+					zap.String("page_id", item.PageID),
+					zap.Error(err))
+				continue
+			}
+			pages[item.page] = page
+			published = append(published, page)
+		}
+
+		s.publishQueued(ctx, page, item.Platform, item.Priority)
 	}
 
-	// Filter pages that still need publishing
-	var pendingPages []models.NotionPage
-	for _, page := range pages {
-		needsPublishing, err := s.needsPublishing(ctx, &page)
-		if err != nil {
-			s.logger.Error("Failed to check if page needs publishing",
-				zap.String("page_id", page.NotionID),
-				zap.Error(err))
-			continue
+	for _, page := range published {
+		s.markPublishedIfCompleted(ctx, page)
+	}
+
+	return nil
+}
+
+// EnqueuePendingPages queues the next pages waiting in the per-platform queues for the publish
+// workers, in the order ProcessPendingPages would publish them, and returns how many were added.
+// Publishes still queued from an earlier cycle are not queued again.
+func (s *PublisherService) EnqueuePendingPages(ctx context.Context, queue TaskQueue) (int, error) {
+	queues, err := s.PublishQueues(ctx)
+	if err != nil {
+		return 0, err
+	}
+	scheduled := scheduleFairly(queues, s.publishesPerCycle())
+
+	tasks := make([]models.PublishTask, len(scheduled))
+	for i, item := range scheduled {
+		tasks[i] = models.PublishTask{
+			PageID:   item.page,
+			Platform: item.Platform,
+			Priority: item.Priority,
 		}
-		if needsPublishing {
-			pendingPages = append(pendingPages, page)
+	}
+	added, err := queue.Enqueue(ctx, tasks)
+	if err != nil {
+		return added, err
+	}
+
+	s.logger.Info("Queued pending pages",
+		zap.Int("scheduled", len(scheduled)),
+		zap.Int("queued", added),
+		zap.Int("platforms", len(queues)))
+	return added, nil
+}
+
+// publishQueued publishes a page from the queues to one platform
+func (s *PublisherService) publishQueued(ctx context.Context, page *models.NotionPage, platformName, priority string) {
+	results, err := s.manager.PublishToPlatforms(ctx, page, []string{platformName})
+	if err != nil {
+		s.logger.Error("Failed to publish page",
+			zap.String("page_id", page.NotionID),
+			zap.String("platform", platformName),
+			zap.Error(err))
+		return
+	}
+
+	// Log results
+	for platform, result := range results {
+		s.logger.Info("Publish result",
+			zap.String("page_id", page.NotionID),
+			zap.String("platform", platform),
+			zap.String("priority", priority),
+			zap.Bool("success", result.Success))
+		if result.Success {
+			s.refreshSeries(ctx, page, platform)
+		} else if !errors.Is(result.Error, publisher.ErrPlatformPaused) {
+			s.commentOnFailure(page, platform, result, publishErrorLevel(result.Error))
 		}
 	}
+}
 
-	pages = pendingPages
+// markPublishedIfCompleted marks a Done page Published, locally and in Notion, once all the
+// platforms it is routed to are completed
+func (s *PublisherService) markPublishedIfCompleted(ctx context.Context, page *models.NotionPage) {
+	// Check if all platforms are now completed for this page and page status is Done
+	allCompleted, route, err := s.checkAllPlatformsCompleted(ctx, page)
+	if err != nil {
+		s.logger.Error("Failed to check platform completion status",
+			zap.String("page_id", page.NotionID),
+			zap.Error(err))
+		return
+	}
 
-	s.logger.Info("Processing pending pages", zap.Int("count", len(pages)))
+	s.logger.Info("Platform completion check",
+		zap.String("page_id", page.NotionID),
+		zap.String("current_status", page.Status),
+		zap.Bool("all_completed", allCompleted),
+		zap.Strings("required_platforms", route.Platforms))
 
-	for _, page := range pages {
-		results, err := s.manager.PublishToAll(ctx, &page)
-		if err != nil {
-			s.logger.Error("Failed to publish page",
+	// Only update to Published if all platforms are completed AND page status is Done
+	if !allCompleted || page.Status != "Done" {
+		return
+	}
+
+	// Update page status to Published
+	if err := s.updatePageToPublished(ctx, page); err != nil {
+		s.logger.Error("Failed to update page status to Published",
+			zap.String("page_id", page.NotionID),
+			zap.Error(err))
+		return
+	}
+
+	// Update Notion page status; pages from other sources only exist locally
+	if page.IsFromNotion() {
+		if err := s.updateNotionPageStatus(ctx, page.NotionID, "Published"); err != nil {
+			s.logger.Error("Failed to update Notion page status",
 				zap.String("page_id", page.NotionID),
 				zap.Error(err))
-			continue
 		}
+	}
 
-		// Log results
-		for platform, result := range results {
-			s.logger.Info("Publish result",
-				zap.String("page_id", page.NotionID),
-				zap.String("platform", platform),
-				zap.Bool("success", result.Success))
-		}
+	s.logger.Info("Page published to all platforms and status updated",
+		zap.String("page_id", page.NotionID),
+		zap.String("title", page.Title))
+}
 
-		// Check if all platforms are now completed for this page and page status is Done
-		allCompleted, err := s.checkAllPlatformsCompleted(ctx, &page)
-		if err != nil {
-			s.logger.Error("Failed to check platform completion status",
+// ProcessExpiredPages unpublishes pages whose Notion "Unpublish date" has passed
+func (s *PublisherService) ProcessExpiredPages(ctx context.Context) error {
+	var pages []models.NotionPage
+	if err := s.db.Where("unpublish_at IS NOT NULL AND unpublish_at <= ?", time.Now()).
+		Where("status <> ?", "Unpublished").
+		Find(&pages).Error; err != nil {
+		return fmt.Errorf("failed to get expired pages: %w", err)
+	}
+
+	if len(pages) == 0 {
+		return nil
+	}
+
+	s.logger.Info("Processing expired pages", zap.Int("count", len(pages)))
+
+	for _, page := range pages {
+		var jobs []models.DistributionJob
+		if err := s.db.Preload("Platform").
+			Where("page_id = ? AND status = ?", page.ID, "completed").
+			Find(&jobs).Error; err != nil {
+			s.logger.Error("Failed to get jobs for expired page",
 				zap.String("page_id", page.NotionID),
 				zap.Error(err))
 			continue
 		}
 
-		s.logger.Info("Platform completion check",
-			zap.String("page_id", page.NotionID),
-			zap.String("current_status", page.Status),
-			zap.Bool("all_completed", allCompleted),
-			zap.Strings("required_platforms", page.Platforms))
-
-		// Only update to Published if all platforms are completed AND page status is Done
-		if allCompleted && page.Status == "Done" {
-			// Update page status to Published
-			if err := s.updatePageToPublished(ctx, &page); err != nil {
-				s.logger.Error("Failed to update page status to Published",
+		allUnpublished := true
+		for i := range jobs {
+			err := s.manager.Unpublish(ctx, &jobs[i])
+			if errors.Is(err, publisher.ErrManualRemoval) {
+				// The job is marked unpublished with a note, retrying cannot take the content down
+				s.logger.Warn("Expired content has to be removed manually",
 					zap.String("page_id", page.NotionID),
+					zap.String("platform", jobs[i].Platform.Name),
+					zap.Uint("job_id", jobs[i].ID),
 					zap.Error(err))
 				continue
 			}
-
-			// Update Notion page status
-			if err := s.updateNotionPageStatus(ctx, page.NotionID, "Published"); err != nil {
-				s.logger.Error("Failed to update Notion page status",
+			if err != nil {
+				allUnpublished = false
+				s.logger.Error("Failed to unpublish job",
 					zap.String("page_id", page.NotionID),
+					zap.String("platform", jobs[i].Platform.Name),
 					zap.Error(err))
+				s.monitoringService.RecordError("ERROR", "publisher", fmt.Sprintf("Failed to unpublish from %s", jobs[i].Platform.Name), err.Error(),
+					WithPlatform(jobs[i].Platform.Name),
+					WithPage(page.ID),
+					WithJob(jobs[i].ID),
+					WithRequest(ctx))
 			}
-
-			s.logger.Info("Page published to all platforms and status updated",
-				zap.String("page_id", page.NotionID),
-				zap.String("title", page.Title))
 		}
-	}
 
-	return nil
-}
-
-// needsPublishing checks if a page needs publishing to any of its required platforms
-func (s *PublisherService) needsPublishing(ctx context.Context, page *models.NotionPage) (bool, error) {
-	// Get all distribution jobs for this page
-	var jobs []models.DistributionJob
-	if err := s.db.Preload("Platform").Where("page_id = ?", page.ID).Find(&jobs).Error; err != nil {
-		return false, fmt.Errorf("failed to get distribution jobs: %w", err)
-	}
-
-	// Create a map of platform name to job status
-	platformStatus := make(map[string]string)
-	for _, job := range jobs {
-		platformStatus[job.Platform.Name] = job.Status
-	}
+		// Keep retrying on later cycles until every platform has been cleaned up
+		if !allUnpublished {
+			continue
+		}
 
-	// Check if all required platforms are completed
-	for _, platformName := range page.Platforms {
-		status, exists := platformStatus[platformName]
-		if !exists || (status != "completed") {
-			// Platform either has no job or job is not completed
-			return true, nil
+		if err := s.db.Model(&page).Update("status", "Unpublished").Error; err != nil {
+			s.logger.Error("Failed to update page status to Unpublished",
+				zap.String("page_id", page.NotionID),
+				zap.Error(err))
+			continue
 		}
+
+		s.logger.Info("Page unpublished from all platforms",
+			zap.String("page_id", page.NotionID),
+			zap.String("title", page.Title))
 	}
 
-	return false, nil
+	return nil
 }
 
-// checkAllPlatformsCompleted checks if all required platforms for a page have been successfully published
-func (s *PublisherService) checkAllPlatformsCompleted(ctx context.Context, page *models.NotionPage) (bool, error) {
-	// Get all distribution jobs for this page
-	var jobs []models.DistributionJob
-	if err := s.db.Preload("Platform").Where("page_id = ?", page.ID).Find(&jobs).Error; err != nil {
-		return false, fmt.Errorf("failed to get distribution jobs: %w", err)
+// checkAllPlatformsCompleted checks if every platform the page is routed to, followers
+// included, has been published, judged by the latest job on each platform like the publish
+// queues do. It returns the route it checked.
+func (s *PublisherService) checkAllPlatformsCompleted(ctx context.Context, page *models.NotionPage) (bool, *publisher.Route, error) {
+	statuses, err := s.latestJobStatuses(ctx, []models.NotionPage{*page})
+	if err != nil {
+		return false, nil, err
 	}
 
-	// Create a map of platform name to job status
-	platformStatus := make(map[string]string)
-	for _, job := range jobs {
-		platformStatus[job.Platform.Name] = job.Status
+	route := s.manager.RoutePublishedPage(page, statuses[page.ID])
+	if len(route.Platforms) == 0 {
+		return false, route, nil
 	}
 
-	// Check if all required platforms are completed
-	// We need to map Notion platform names to system platform names
-	for _, notionPlatformName := range page.Platforms {
-		// Map the Notion platform name to the system platform name
-		systemPlatformName := s.manager.MapPlatformName(notionPlatformName)
-		if systemPlatformName == "" {
-			s.logger.Warn("Unknown platform name in checkAllPlatformsCompleted", 
-				zap.String("notion_platform", notionPlatformName))
-			return false, nil
-		}
-		
-		status, exists := platformStatus[systemPlatformName]
-		if !exists || status != "completed" {
+	for _, platformName := range route.Platforms {
+		// A draft-only platform is done once its draft is saved
+		status := statuses[page.ID][platformName]
+		if status != models.JobCompleted && !(route.IsDraft(platformName) && status == models.JobDraft) {
 			s.logger.Debug("Platform not completed",
-				zap.String("notion_platform", notionPlatformName),
-				zap.String("system_platform", systemPlatformName),
-				zap.String("status", status),
-				zap.Bool("exists", exists))
-			return false, nil
+				zap.String("page_id", page.NotionID),
+				zap.String("platform", platformName),
+				zap.String("status", status))
+			return false, route, nil
 		}
 	}
 
-	return true, nil
+	return true, route, nil
 }
 
 // updatePageToPublished updates the page status to 'Published' in the database
@@ -0,0 +1,31 @@
+package publisher
+
+import "strings"
+
+// AccountSeparator separates a platform from one of its named accounts, e.g. "wechat-official:en"
+const AccountSeparator = ":"
+
+// AccountPlatformName names an account of a platform; the default account is the platform itself
+func AccountPlatformName(platform, account string) string {
+	if account == "" {
+		return platform
+	}
+	return platform + AccountSeparator + account
+}
+
+// SplitPlatformName splits a platform name into the platform and the name of the account
+func SplitPlatformName(platformName string) (platform, account string) {
+	platform, account, _ = strings.Cut(platformName, AccountSeparator)
+	return platform, account
+}
+
+// PlatformType returns the platform a platform name publishes to, without its account
+func PlatformType(platformName string) string {
+	platform, _ := SplitPlatformName(platformName)
+	return platform
+}
+
+// AccountPublisher is implemented by publishers that can be registered once per named account
+type AccountPublisher interface {
+	SetAccountName(name string)
+}
@@ -0,0 +1,90 @@
+package al_folio
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/git"
+	"github.com/ifuryst/ripple/pkg/httpclient"
+
+	"go.uber.org/zap"
+)
+
+// VerifyDeployment checks that the site build for the pushed commit succeeded and that the
+// post URL is being served
+func (p *AlFolioPublisher) VerifyDeployment(ctx context.Context, metadata map[string]string, config publisher.PublishConfig) (*publisher.DeploymentStatus, error) {
+	url := metadata["url"]
+
+	// With a token the Actions/pipeline result tells us about failed builds instead of waiting for a timeout
+	if token, commitHash := config.Config["git_token"], metadata["commit_hash"]; token != "" && commitHash != "" {
+		client, err := git.NewHostingClient(config.Config["repo_url"], config.Config["pr_provider"], config.Config["pr_api_url"], token)
+		if err != nil {
+			publisher.Logger(ctx, p.logger).Warn("Cannot check site build status", zap.Error(err))
+		} else {
+			status, err := client.CommitStatus(ctx, commitHash)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check site build status: %w", err)
+			}
+
+			switch status.State {
+			case git.CommitStateFailure:
+				return &publisher.DeploymentStatus{
+					State:   publisher.DeploymentFailed,
+					URL:     url,
+					Message: fmt.Sprintf("site build failed: %s (%s)", status.Message, status.URL),
+				}, nil
+			case git.CommitStatePending:
+				return &publisher.DeploymentStatus{
+					State:   publisher.DeploymentPending,
+					URL:     url,
+					Message: "site build is still running",
+				}, nil
+			}
+		}
+	}
+
+	if url == "" {
+		// Without a base URL there is nothing more to check
+		return &publisher.DeploymentStatus{State: publisher.DeploymentLive}, nil
+	}
+
+	statusCode, err := p.fetchStatus(ctx, url)
+	if err != nil {
+		return &publisher.DeploymentStatus{
+			State:   publisher.DeploymentPending,
+			URL:     url,
+			Message: err.Error(),
+		}, nil
+	}
+	if statusCode != http.StatusOK {
+		return &publisher.DeploymentStatus{
+			State:   publisher.DeploymentPending,
+			URL:     url,
+			Message: fmt.Sprintf("post URL returned status %d", statusCode),
+		}, nil
+	}
+
+	return &publisher.DeploymentStatus{State: publisher.DeploymentLive, URL: url}, nil
+}
+
+// fetchStatus requests the post URL and returns the response status code
+func (p *AlFolioPublisher) fetchStatus(ctx context.Context, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	// Avoid being served a stale 404 from the CDN in front of GitHub Pages
+	req.Header.Set("Cache-Control", "no-cache")
+
+	client := httpclient.New(httpclient.WithTimeout(15 * time.Second))
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch post URL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
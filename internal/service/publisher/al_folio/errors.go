@@ -0,0 +1,36 @@
+package al_folio
+
+import (
+	"strings"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// gitErrorMessages maps the errors go-git returns for the failures a push runs into to error
+// codes
+var gitErrorMessages = []struct {
+	message string
+	code    publisher.ErrorCode
+}{
+	{"authentication required", publisher.ErrorCodeAuthExpired},
+	{"authorization failed", publisher.ErrorCodeAuthExpired},
+	{"unable to authenticate", publisher.ErrorCodeAuthExpired},
+	{"status code: 429", publisher.ErrorCodeRateLimited},
+	{"status code: 5", publisher.ErrorCodePlatform5xx},
+	{"no such host", publisher.ErrorCodeNetwork},
+	{"i/o timeout", publisher.ErrorCodeNetwork},
+	{"connection timed out", publisher.ErrorCodeNetwork},
+	{"connection refused", publisher.ErrorCodeNetwork},
+	{"connection reset", publisher.ErrorCodeNetwork},
+}
+
+// gitError classifies a failed git operation by its error
+func gitError(err error) error {
+	message := strings.ToLower(err.Error())
+	for _, known := range gitErrorMessages {
+		if strings.Contains(message, known.message) {
+			return publisher.NewError(known.code, err)
+		}
+	}
+	return err
+}
@@ -0,0 +1,115 @@
+package al_folio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/httpclient"
+)
+
+// maxAttachmentSize bounds the attachments committed to the repository; larger files keep
+// linking to where they are hosted
+const maxAttachmentSize = 50 << 20
+
+// processFiles downloads the attachments of a post into assets/files/<image_dir> of the
+// repository and points their download links at the copies. Attachments that fail to download
+// keep their original links.
+func (p *AlFolioPublisher) processFiles(ctx context.Context, text string, resources []publisher.Resource, fileDir, repoPath string) (string, []publisher.Resource, error) {
+	if len(resources) == 0 {
+		return text, nil, nil
+	}
+
+	assetsFilePath := filepath.Join(repoPath, "assets", "files", fileDir)
+	if err := os.MkdirAll(assetsFilePath, 0755); err != nil {
+		return text, nil, fmt.Errorf("failed to create assets file directory: %w", err)
+	}
+
+	var processed []publisher.Resource
+	used := make(map[string]bool)
+	for _, resource := range resources {
+		if resource.Type != publisher.ResourceTypeFile || strings.HasPrefix(resource.URL, "/") {
+			continue
+		}
+
+		filename := uniqueFileName(resource.Metadata["filename"], used)
+		localPath := filepath.Join(assetsFilePath, filename)
+		if err := downloadAttachment(ctx, resource.URL, localPath); err != nil {
+			publisher.Logger(ctx, p.logger).Error("Failed to process attachment",
+				zap.String("url", resource.URL),
+				zap.Error(err))
+			continue
+		}
+
+		alFolioPath := fmt.Sprintf("/assets/files/%s/%s", fileDir, filename)
+		text = strings.ReplaceAll(text, `href="`+resource.URL+`"`, `href="`+alFolioPath+`"`)
+
+		resource.URL = alFolioPath
+		resource.LocalPath = localPath
+		processed = append(processed, resource)
+
+		publisher.Logger(ctx, p.logger).Info("Attachment processed",
+			zap.String("original_url", resource.Metadata["original_url"]),
+			zap.String("al_folio_path", alFolioPath))
+	}
+	return text, processed, nil
+}
+
+// uniqueFileName numbers a file name already taken by another attachment of the post
+func uniqueFileName(filename string, used map[string]bool) string {
+	if filename == "" {
+		filename = "file"
+	}
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+	for i := 2; used[filename]; i++ {
+		filename = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	used[filename] = true
+	return filename
+}
+
+// downloadAttachment saves an attachment to localPath, replacing an earlier copy since the
+// file may have changed in Notion
+func downloadAttachment(ctx context.Context, url, localPath string) error {
+	client := httpclient.New(httpclient.WithTimeout(2 * time.Minute))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download attachment: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download attachment: status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > maxAttachmentSize {
+		return fmt.Errorf("attachment is %d bytes, more than the %d allowed", resp.ContentLength, maxAttachmentSize)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAttachmentSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read attachment: %w", err)
+	}
+	if len(data) > maxAttachmentSize {
+		return fmt.Errorf("attachment is more than the %d bytes allowed", maxAttachmentSize)
+	}
+
+	if err := os.WriteFile(localPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to save attachment: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package al_folio
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultFrontMatter are the al-folio settings every post gets unless configured otherwise
+var defaultFrontMatter = []struct {
+	key   string
+	value interface{}
+}{
+	{"giscus_comments", true},
+	{"tabs", true},
+	{"pretty_table", true},
+}
+
+// defaultTOC is written as the toc field of posts that get a table of contents
+var defaultTOC = map[string]interface{}{"sidebar": "left"}
+
+// parseFrontMatter parses a YAML mapping of front matter fields; empty input is no fields
+func parseFrontMatter(source string) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if strings.TrimSpace(source) == "" {
+		return fields, nil
+	}
+	if err := yaml.Unmarshal([]byte(source), &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// encodeFrontMatterField renders a single field the way the generated front matter is indented
+func encodeFrontMatterField(key string, value interface{}) (string, error) {
+	var b strings.Builder
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(map[string]interface{}{key: value}); err != nil {
+		return "", fmt.Errorf("failed to encode front matter field %s: %w", key, err)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// frontMatterSettings merges the default settings, the configured fields and the page's own
+// fields, later ones winning. A null value removes the field.
+func frontMatterSettings(configured, page map[string]interface{}) map[string]interface{} {
+	settings := make(map[string]interface{}, len(defaultFrontMatter)+len(configured)+len(page))
+	for _, field := range defaultFrontMatter {
+		settings[field.key] = field.value
+	}
+	for key, value := range configured {
+		settings[key] = value
+	}
+	for key, value := range page {
+		settings[key] = value
+	}
+	return settings
+}
+
+// orderedFrontMatterKeys returns the default settings in their usual order, then the other
+// fields sorted
+func orderedFrontMatterKeys(settings map[string]interface{}) []string {
+	keys := make([]string, 0, len(settings))
+	isDefault := make(map[string]bool, len(defaultFrontMatter))
+	for _, field := range defaultFrontMatter {
+		isDefault[field.key] = true
+		if _, ok := settings[field.key]; ok {
+			keys = append(keys, field.key)
+		}
+	}
+
+	var extra []string
+	for key := range settings {
+		if !isDefault[key] {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	return append(keys, extra...)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/httpclient"
 	"io"
 	"net/http"
 	"os"
@@ -57,7 +58,7 @@ func (p *AlFolioImageProcessor) ProcessContent(ctx context.Context, content stri
 
 	// Find all images in the content
 	imageURLs := p.extractImageURLs(content)
-	p.logger.Info("Found images in content", zap.Int("count", len(imageURLs)))
+	publisher.Logger(ctx, p.logger).Info("Found images in content", zap.Int("count", len(imageURLs)))
 
 	// Download and process each image
 	imageMap := make(map[string]string) // original URL -> new path
@@ -65,7 +66,7 @@ func (p *AlFolioImageProcessor) ProcessContent(ctx context.Context, content stri
 	for _, url := range imageURLs {
 		resource, err := p.downloadAndProcessImage(ctx, url, assetsImagePath, imageDir)
 		if err != nil {
-			p.logger.Error("Failed to process image", zap.String("url", url), zap.Error(err))
+			publisher.Logger(ctx, p.logger).Error("Failed to process image", zap.String("url", url), zap.Error(err))
 			continue
 		}
 
@@ -187,7 +188,7 @@ func (p *AlFolioImageProcessor) downloadAndProcessImage(ctx context.Context, url
 		},
 	}
 
-	p.logger.Info("Image processed",
+	publisher.Logger(ctx, p.logger).Info("Image processed",
 		zap.String("original_url", url),
 		zap.String("al_folio_path", alFolioPath),
 		zap.String("local_path", localPath))
@@ -198,14 +199,11 @@ func (p *AlFolioImageProcessor) downloadAndProcessImage(ctx context.Context, url
 func (p *AlFolioImageProcessor) downloadImage(ctx context.Context, url, localPath string) error {
 	// Check if file already exists
 	if _, err := os.Stat(localPath); err == nil {
-		p.logger.Debug("Image already exists locally", zap.String("path", localPath))
+		publisher.Logger(ctx, p.logger).Debug("Image already exists locally", zap.String("path", localPath))
 		return nil
 	}
 
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
+	client := httpclient.New(httpclient.WithTimeout(30 * time.Second))
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -0,0 +1,105 @@
+package al_folio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// ListPosts reads the posts and notes in the repository, so posts written before Ripple can be
+// imported. The publish ID is the file name, as for posts Ripple publishes.
+func (p *AlFolioPublisher) ListPosts(ctx context.Context, config publisher.PublishConfig) ([]publisher.ExistingPost, error) {
+	state := p.current()
+	state.repository.Lock()
+	defer state.repository.Unlock()
+
+	var posts []publisher.ExistingPost
+	for _, collection := range []string{postsDir, notesDir} {
+		dir := filepath.Join(state.repository.GetLocalPath(), collection)
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", collection, err)
+		}
+
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || (!strings.HasSuffix(name, ".md") && !strings.HasSuffix(name, ".markdown")) {
+				continue
+			}
+
+			post, err := p.readExistingPost(collection, name, config.Config["base_url"])
+			if err != nil {
+				publisher.Logger(ctx, p.logger).Warn("Skipping unreadable post",
+					zap.String("file", filepath.Join(collection, name)),
+					zap.Error(err))
+				continue
+			}
+			posts = append(posts, *post)
+		}
+	}
+	return posts, nil
+}
+
+// readExistingPost reads the title and date of a post from its front matter, falling back to the
+// date in its file name
+func (p *AlFolioPublisher) readExistingPost(collection, filename, baseURL string) (*publisher.ExistingPost, error) {
+	state := p.current()
+	relativePath := filepath.Join(collection, filename)
+	data, err := os.ReadFile(filepath.Join(state.repository.GetLocalPath(), relativePath))
+	if err != nil {
+		return nil, err
+	}
+
+	var frontMatter struct {
+		Title string `yaml:"title"`
+		Date  string `yaml:"date"`
+	}
+	if rest, ok := bytes.CutPrefix(data, []byte("---")); ok {
+		if end := bytes.Index(rest, []byte("\n---")); end >= 0 {
+			// A broken front matter only loses the title, the file name still identifies the post
+			_ = yaml.Unmarshal(rest[:end], &frontMatter)
+		}
+	}
+
+	slug := strings.TrimSuffix(p.generateSlugFromFilename(filename), ".markdown")
+	post := &publisher.ExistingPost{
+		PublishID: filename,
+		Title:     strings.TrimSpace(frontMatter.Title),
+		Slug:      slug,
+		Metadata: map[string]string{
+			"file_path": relativePath,
+			"filename":  filename,
+		},
+	}
+
+	if date, err := time.Parse("2006-01-02", filename[:min(len(filename), len("2006-01-02"))]); err == nil {
+		post.PublishedAt = &date
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05-07:00", "2006-01-02 15:04:05 -0700", "2006-01-02 15:04:05", "2006-01-02"} {
+		if date, err := time.Parse(layout, strings.TrimSpace(frontMatter.Date)); err == nil {
+			post.PublishedAt = &date
+			break
+		}
+	}
+
+	if baseURL != "" {
+		if collection == notesDir {
+			post.URL = fmt.Sprintf("%s/notes/%s/", baseURL, slug)
+		} else if post.PublishedAt != nil {
+			post.URL = fmt.Sprintf("%s/blog/%d/%s/", baseURL, post.PublishedAt.Year(), slug)
+		}
+	}
+	return post, nil
+}
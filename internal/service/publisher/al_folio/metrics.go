@@ -0,0 +1,71 @@
+package al_folio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/git"
+)
+
+// trafficTTL is how long the repository traffic is reused, so a collection run asks for it once
+const trafficTTL = 10 * time.Minute
+
+type trafficCache struct {
+	mu        sync.Mutex
+	paths     []git.PathTraffic
+	fetchedAt time.Time
+}
+
+// CollectMetrics reads the views of the post from the GitHub repository traffic API. GitHub
+// only reports the ten most viewed repository paths of the last 14 days, so posts outside of
+// them have no stats.
+func (p *AlFolioPublisher) CollectMetrics(ctx context.Context, post publisher.PublishedPost, config publisher.PublishConfig) (*publisher.PostMetrics, error) {
+	token := config.Config["git_token"]
+	if token == "" || post.PublishID == "" {
+		return nil, nil
+	}
+
+	paths, err := p.popularPaths(ctx, config, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// The post can be viewed under several paths, e.g. its blob and its history
+	var metrics *publisher.PostMetrics
+	for _, path := range paths {
+		if !strings.Contains(path.Path, post.PublishID) {
+			continue
+		}
+		if metrics == nil {
+			metrics = &publisher.PostMetrics{}
+		}
+		metrics.Views += path.Count
+		metrics.Readers += path.Uniques
+	}
+	return metrics, nil
+}
+
+func (p *AlFolioPublisher) popularPaths(ctx context.Context, config publisher.PublishConfig, token string) ([]git.PathTraffic, error) {
+	p.traffic.mu.Lock()
+	defer p.traffic.mu.Unlock()
+	if time.Since(p.traffic.fetchedAt) < trafficTTL {
+		return p.traffic.paths, nil
+	}
+
+	client, err := git.NewHostingClient(config.Config["repo_url"], config.Config["pr_provider"], config.Config["pr_api_url"], token)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := client.PopularPaths(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository traffic: %w", err)
+	}
+	p.traffic.paths = paths
+	p.traffic.fetchedAt = time.Now()
+	return paths, nil
+}
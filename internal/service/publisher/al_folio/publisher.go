@@ -6,34 +6,76 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ifuryst/ripple/pkg/util"
 
+	"github.com/ifuryst/ripple/internal/models"
 	"github.com/ifuryst/ripple/internal/service/publisher"
 	"github.com/ifuryst/ripple/pkg/git"
 
 	"go.uber.org/zap"
 )
 
+const (
+	// postsDir holds long-form articles
+	postsDir = "_posts"
+	// notesDir holds short-form notes in a separate Jekyll collection
+	notesDir = "_notes"
+)
+
 // AlFolioPublisher handles publishing to Al-Folio blogs
 type AlFolioPublisher struct {
-	logger             *zap.Logger
-	contentTransformer *AlFolioTransformer
-	imageProcessor     *AlFolioImageProcessor
-	repository         *git.Repository
+	logger         *zap.Logger
+	imageProcessor *AlFolioImageProcessor
+	traffic        trafficCache
+	// slugs keeps two pages with the same title and date from writing the same post file
+	slugs *publisher.SlugRegistry
+	// translator translates titles for the translation slug strategy; nil without AI enrichment
+	translator publisher.TitleTranslator
+
+	// mu guards state, which every Initialize replaces while other publishes may be running
+	mu    sync.RWMutex
+	state *publishState
 }
 
-func NewAlFolioPublisher(logger *zap.Logger) publisher.Publisher {
-	alFolioTransformer := NewAlFolioTransformer()
+// publishState is what Initialize derives from the platform configuration. It is never changed
+// once built, so a publish can keep using the state it started with.
+type publishState struct {
+	repository   *git.Repository
+	transformer  *AlFolioTransformer
+	slugStrategy string
+	prClient     *git.HostingClient
+	// footnotes turns links into footnotes for pages that don't set their Footnotes property
+	footnotes bool
+}
 
+func NewAlFolioPublisher(logger *zap.Logger) publisher.Publisher {
 	return &AlFolioPublisher{
-		logger:             logger,
-		contentTransformer: alFolioTransformer,
-		imageProcessor:     NewAlFolioImageProcessor(logger, "temp/images"),
+		logger:         logger,
+		imageProcessor: NewAlFolioImageProcessor(logger, "temp/images"),
+		state:          &publishState{transformer: NewAlFolioTransformer()},
 	}
 }
 
+// current returns the state of the last Initialize
+func (p *AlFolioPublisher) current() *publishState {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.state
+}
+
+// SetSlugRegistry sets where the post paths of each page are reserved
+func (p *AlFolioPublisher) SetSlugRegistry(registry *publisher.SlugRegistry) {
+	p.slugs = registry
+}
+
+// SetTitleTranslator sets the translator of titles slugged with the translation strategy
+func (p *AlFolioPublisher) SetTitleTranslator(translator publisher.TitleTranslator) {
+	p.translator = translator
+}
+
 func (p *AlFolioPublisher) GetPlatformName() string {
 	return "al-folio"
 }
@@ -51,16 +93,45 @@ func (p *AlFolioPublisher) Initialize(ctx context.Context, config publisher.Publ
 		WorkspaceDir: config.Config["workspace_dir"],
 		GitUsername:  config.Config["git_username"],
 		GitEmail:     config.Config["git_email"],
+		AuthToken:    config.Config["git_token"],
+		SSHKeyPath:   config.Config["ssh_key_path"],
 	}
 
-	p.repository = git.NewRepository(repoConfig, p.logger)
+	state := &publishState{
+		repository:   git.NewRepository(repoConfig, p.logger),
+		transformer:  NewAlFolioTransformer(),
+		slugStrategy: config.Config["slug_strategy"],
+		footnotes:    config.Config["footnotes"] == "true",
+	}
 
-	// Initialize (clone or pull) the repository
-	if err := p.repository.Initialize(); err != nil {
+	frontMatter, err := parseFrontMatter(config.Config["front_matter"])
+	if err != nil {
+		return fmt.Errorf("invalid front_matter config: %w", err)
+	}
+	state.transformer.SetFrontMatter(frontMatter)
+
+	// In PR mode posts go to a review branch and a pull request instead of the live branch
+	if config.Config["pr_mode"] == "true" {
+		prClient, err := git.NewHostingClient(config.Config["repo_url"], config.Config["pr_provider"], config.Config["pr_api_url"], config.Config["git_token"])
+		if err != nil {
+			return fmt.Errorf("failed to configure pull request mode: %w", err)
+		}
+		state.prClient = prClient
+	}
+
+	// Initialize (clone or pull) the repository; another publish may be using the workspace
+	state.repository.Lock()
+	err = state.repository.Initialize()
+	state.repository.Unlock()
+	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
 
-	p.logger.Info("Al-Folio blog publisher initialized",
+	p.mu.Lock()
+	p.state = state
+	p.mu.Unlock()
+
+	publisher.Logger(ctx, p.logger).Info("Al-Folio blog publisher initialized",
 		zap.String("repo_url", config.Config["repo_url"]),
 		zap.String("branch", config.Config["branch"]))
 
@@ -76,25 +147,58 @@ func (p *AlFolioPublisher) ValidateConfig(config publisher.PublishConfig) error
 		}
 	}
 
+	switch strategy := config.Config["slug_strategy"]; strategy {
+	case "", util.SlugStrategyPinyin, util.SlugStrategyTranslation, util.SlugStrategyNotionID:
+	default:
+		return fmt.Errorf("invalid slug_strategy %q: use %s, %s or %s", strategy, util.SlugStrategyPinyin, util.SlugStrategyTranslation, util.SlugStrategyNotionID)
+	}
+
+	return nil
+}
+
+// VerifyCredentials checks the repository can be read with the configured credentials, without
+// cloning or pulling it
+func (p *AlFolioPublisher) VerifyCredentials(ctx context.Context, config publisher.PublishConfig) error {
+	repository := git.NewRepository(git.RepositoryConfig{
+		URL:         config.Config["repo_url"],
+		Branch:      config.Config["branch"],
+		GitUsername: config.Config["git_username"],
+		AuthToken:   config.Config["git_token"],
+		SSHKeyPath:  config.Config["ssh_key_path"],
+	}, p.logger)
+	if err := repository.CheckAccess(ctx); err != nil {
+		return gitError(err)
+	}
 	return nil
 }
 
 func (p *AlFolioPublisher) TransformContent(ctx context.Context, content publisher.PublishContent) (*publisher.PublishContent, error) {
+	state := p.current()
+	defer publisher.TimeStage(ctx, models.StageTransform)()
 	// Generate filename and image directory
 	publishDate := time.Now()
 	if content.PublishDate != nil {
 		publishDate = *content.PublishDate
 	}
 
-	// Use metadata-aware filename generation
-	filename := util.GenerateFilenameWithMetadata(content.Title, publishDate, content.Metadata)
-	imageDir := util.GenerateImageDirWithMetadata(content.Title, publishDate, content.Metadata)
-
 	// Prepare metadata for Jekyll transformation
 	metadata := make(map[string]string)
 	for k, v := range content.Metadata {
 		metadata[k] = v
 	}
+	if state.slugStrategy != "" {
+		metadata["slug_strategy"] = state.slugStrategy
+	}
+	if state.slugStrategy == util.SlugStrategyTranslation {
+		p.translateTitle(ctx, content.Title, metadata)
+	}
+	if metadata["footnotes"] == "" && state.footnotes {
+		metadata["footnotes"] = "true"
+	}
+
+	// Use metadata-aware filename generation
+	filename := util.GenerateFilenameWithMetadata(content.Title, publishDate, metadata)
+	imageDir := util.GenerateImageDirWithMetadata(content.Title, publishDate, metadata)
 
 	// Add content fields to metadata
 	metadata["title"] = content.Title
@@ -104,6 +208,13 @@ func (p *AlFolioPublisher) TransformContent(ctx context.Context, content publish
 	metadata["image_dir"] = imageDir
 	metadata["content"] = content.Content // For TOC detection
 
+	// Notes are short, so they never get a TOC
+	collection := postsDir
+	if content.IsNote() {
+		metadata["toc"] = "false"
+		collection = notesDir
+	}
+
 	if content.PublishDate != nil {
 		metadata["publish_date"] = content.PublishDate.Format(time.RFC3339)
 	}
@@ -120,8 +231,13 @@ func (p *AlFolioPublisher) TransformContent(ctx context.Context, content publish
 		metadata["categories"] = content.Tags[0]
 	}
 
+	doc, err := content.ContentDocument()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse content: %w", err)
+	}
+
 	// Transform content to Al-Folio format
-	transformedContent, err := p.contentTransformer.Transform(ctx, content.Content, metadata)
+	transformedContent, err := state.transformer.Transform(ctx, doc, metadata)
 	if err != nil {
 		return nil, fmt.Errorf("failed to transform content: %w", err)
 	}
@@ -131,13 +247,46 @@ func (p *AlFolioPublisher) TransformContent(ctx context.Context, content publish
 	result.Content = transformedContent
 	result.Metadata["filename"] = filename
 	result.Metadata["image_dir"] = imageDir
+	result.Metadata["collection"] = collection
+	result.Resources = publisher.FileResources(doc)
 
 	return &result, nil
 }
 
+// translateTitle sets the translated_title CJK titles without an EN title are slugged with. A
+// title that cannot be translated is left to the slug, which transliterates it instead.
+func (p *AlFolioPublisher) translateTitle(ctx context.Context, title string, metadata map[string]string) {
+	if metadata["slug"] != "" || metadata["en_title"] != "" || !util.ContainsCJK(title) {
+		return
+	}
+	logger := publisher.Logger(ctx, p.logger)
+	if p.translator == nil {
+		logger.Warn("Slug strategy translation needs AI enrichment, transliterating the title")
+		return
+	}
+
+	translated, err := p.translator.TranslateTitle(ctx, title)
+	if err != nil {
+		logger.Warn("Failed to translate title for the slug, transliterating it", zap.String("title", title), zap.Error(err))
+		return
+	}
+	metadata["translated_title"] = translated
+}
+
+// ProcessResources leaves the resources to PublishDirect and SaveToDraft, which download them
+// into the repository while they hold the workspace, so a concurrent publish can't reset them
+// away before they are committed
 func (p *AlFolioPublisher) ProcessResources(ctx context.Context, content *publisher.PublishContent, config publisher.PublishConfig) error {
+	return nil
+}
+
+// processResources downloads images and attachments into the repository; callers must hold the
+// workspace lock
+func (p *AlFolioPublisher) processResources(ctx context.Context, content *publisher.PublishContent) error {
+	state := p.current()
+	defer publisher.TimeStage(ctx, models.StageMedia)()
 	// Get repository path
-	repoPath := p.repository.GetLocalPath()
+	repoPath := state.repository.GetLocalPath()
 
 	// Process images and update content
 	processedContent, resources, err := p.imageProcessor.ProcessContent(
@@ -150,20 +299,34 @@ func (p *AlFolioPublisher) ProcessResources(ctx context.Context, content *publis
 		return fmt.Errorf("failed to process images: %w", err)
 	}
 
-	// Update content with processed images
+	// Attachments go next to the images, under assets/files
+	processedContent, files, err := p.processFiles(ctx, processedContent, content.Resources, content.Metadata["image_dir"], repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to process attachments: %w", err)
+	}
+
+	// Update content with processed images and attachments
 	content.Content = processedContent
-	content.Resources = resources
+	content.Resources = append(resources, files...)
 
-	p.logger.Info("Processed resources",
+	publisher.Logger(ctx, p.logger).Info("Processed resources",
 		zap.Int("image_count", len(resources)),
+		zap.Int("file_count", len(files)),
 		zap.String("image_dir", content.Metadata["image_dir"]))
 
 	return nil
 }
 
 func (p *AlFolioPublisher) SaveToDraft(ctx context.Context, content publisher.PublishContent, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	state := p.current()
+	state.repository.Lock()
+	defer state.repository.Unlock()
+
 	// Transform content first
 	transformedContent, err := p.TransformContent(ctx, content)
+	if err == nil {
+		err = p.reservePostFilename(ctx, transformedContent, config)
+	}
 	if err != nil {
 		return &publisher.PublishResult{
 			Success:  false,
@@ -173,7 +336,7 @@ func (p *AlFolioPublisher) SaveToDraft(ctx context.Context, content publisher.Pu
 	}
 
 	// Process resources (images)
-	if err := p.ProcessResources(ctx, transformedContent, config); err != nil {
+	if err := p.processResources(ctx, transformedContent); err != nil {
 		return &publisher.PublishResult{
 			Success:  false,
 			Error:    err,
@@ -195,11 +358,22 @@ func (p *AlFolioPublisher) SaveToDraft(ctx context.Context, content publisher.Pu
 }
 
 func (p *AlFolioPublisher) Publish(ctx context.Context, draftID string, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	state := p.current()
+	state.repository.Lock()
+	defer state.repository.Unlock()
+
+	return p.publish(ctx, draftID, config)
+}
+
+// publish commits and pushes the working tree; callers must hold the workspace lock
+func (p *AlFolioPublisher) publish(ctx context.Context, draftID string, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	state := p.current()
+	defer publisher.TimeStage(ctx, models.StagePublish)()
 	// For Al-Folio, publishing means committing and pushing to git
-	repoPath := p.repository.GetLocalPath()
+	repoPath := state.repository.GetLocalPath()
 
 	// Check if there are changes to commit
-	hasChanges, err := p.repository.HasChanges()
+	hasChanges, err := state.repository.HasChanges()
 	if err != nil {
 		return &publisher.PublishResult{
 			Success: false,
@@ -208,7 +382,7 @@ func (p *AlFolioPublisher) Publish(ctx context.Context, draftID string, config p
 	}
 
 	if !hasChanges {
-		p.logger.Info("No changes to commit")
+		publisher.Logger(ctx, p.logger).Info("No changes to commit")
 		return &publisher.PublishResult{
 			Success:     true,
 			PublishID:   draftID,
@@ -217,7 +391,7 @@ func (p *AlFolioPublisher) Publish(ctx context.Context, draftID string, config p
 	}
 
 	// Stage all changes
-	if err := p.repository.Add(); err != nil {
+	if err := state.repository.Add(); err != nil {
 		return &publisher.PublishResult{
 			Success: false,
 			Error:   fmt.Errorf("failed to stage changes: %w", err),
@@ -230,7 +404,7 @@ func (p *AlFolioPublisher) Publish(ctx context.Context, draftID string, config p
 		commitMessage = customMessage
 	}
 
-	if err := p.repository.Commit(commitMessage); err != nil {
+	if err := state.repository.Commit(commitMessage); err != nil {
 		return &publisher.PublishResult{
 			Success: false,
 			Error:   fmt.Errorf("failed to commit changes: %w", err),
@@ -244,10 +418,10 @@ func (p *AlFolioPublisher) Publish(ctx context.Context, draftID string, config p
 	}
 
 	if autoPublish {
-		if err := p.repository.Push(); err != nil {
+		if err := state.repository.Push(); err != nil {
 			return &publisher.PublishResult{
 				Success: false,
-				Error:   fmt.Errorf("failed to push changes: %w", err),
+				Error:   fmt.Errorf("failed to push changes: %w", gitError(err)),
 			}, nil
 		}
 	}
@@ -256,41 +430,70 @@ func (p *AlFolioPublisher) Publish(ctx context.Context, draftID string, config p
 	var url string
 	if baseURL := config.Config["base_url"]; baseURL != "" {
 		slug := p.generateSlugFromFilename(draftID)
-		// Al-Folio URL format: /blog/YYYY/title/
-		publishDate := time.Now()
-		url = fmt.Sprintf("%s/blog/%d/%s/", baseURL, publishDate.Year(), slug)
+		if strings.HasPrefix(p.postPath(draftID), notesDir) {
+			// Notes collection URL format: /notes/title/
+			url = fmt.Sprintf("%s/notes/%s/", baseURL, slug)
+		} else {
+			// Al-Folio URL format: /blog/YYYY/title/
+			publishDate := time.Now()
+			url = fmt.Sprintf("%s/blog/%d/%s/", baseURL, publishDate.Year(), slug)
+		}
 	}
 
 	// Get commit hash
-	commitHash, _ := p.repository.GetLastCommitHash()
+	commitHash, _ := state.repository.GetLastCommitHash()
 
 	logMsg := "Successfully committed to Al-Folio blog"
 	if autoPublish {
 		logMsg = "Successfully published to Al-Folio blog"
 	}
 
-	p.logger.Info(logMsg,
+	publisher.Logger(ctx, p.logger).Info(logMsg,
 		zap.String("draft_id", draftID),
 		zap.String("url", url),
 		zap.String("commit_hash", commitHash),
 		zap.Bool("auto_publish", autoPublish))
 
+	metadata := map[string]string{
+		"commit_hash": commitHash,
+		"branch":      state.repository.GetBranch(),
+		"repo_path":   repoPath,
+		"url":         url,
+	}
+	if autoPublish {
+		// The post is only live once the site has been rebuilt from the pushed commit
+		metadata["deploy_status"] = publisher.DeploymentPending
+	}
+
 	return &publisher.PublishResult{
 		Success:     true,
 		PublishID:   draftID,
 		URL:         url,
 		PublishedAt: time.Now(),
-		Metadata: map[string]string{
-			"commit_hash": commitHash,
-			"branch":      p.repository.GetBranch(),
-			"repo_path":   repoPath,
-		},
+		Metadata:    metadata,
 	}, nil
 }
 
 func (p *AlFolioPublisher) PublishDirect(ctx context.Context, content publisher.PublishContent, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	state := p.current()
+	// Keep the workspace to ourselves from pulling the latest commits until the post is pushed
+	state.repository.Lock()
+	defer state.repository.Unlock()
+
+	if err := state.repository.Initialize(); err != nil {
+		err = fmt.Errorf("failed to pull repository: %w", gitError(err))
+		return &publisher.PublishResult{
+			Success:  false,
+			Error:    err,
+			ErrorMsg: err.Error(),
+		}, nil
+	}
+
 	// Transform content
 	transformedContent, err := p.TransformContent(ctx, content)
+	if err == nil {
+		err = p.reservePostFilename(ctx, transformedContent, config)
+	}
 	if err != nil {
 		return &publisher.PublishResult{
 			Success:  false,
@@ -299,8 +502,12 @@ func (p *AlFolioPublisher) PublishDirect(ctx context.Context, content publisher.
 		}, nil
 	}
 
+	if state.prClient != nil {
+		return p.publishPullRequest(ctx, transformedContent, config)
+	}
+
 	// Process resources (images)
-	if err := p.ProcessResources(ctx, transformedContent, config); err != nil {
+	if err := p.processResources(ctx, transformedContent); err != nil {
 		return &publisher.PublishResult{
 			Success:  false,
 			Error:    err,
@@ -320,7 +527,7 @@ func (p *AlFolioPublisher) PublishDirect(ctx context.Context, content publisher.
 	}
 
 	// Publish (commit and push)
-	publishResult, err := p.Publish(ctx, writeResult.PublishID, config)
+	publishResult, err := p.publish(ctx, writeResult.PublishID, config)
 	if err != nil {
 		return &publisher.PublishResult{
 			Success:  false,
@@ -333,8 +540,9 @@ func (p *AlFolioPublisher) PublishDirect(ctx context.Context, content publisher.
 }
 
 func (p *AlFolioPublisher) GetPublishStatus(ctx context.Context, publishID string, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	state := p.current()
 	// Check if the file exists in the repository
-	if !p.repository.FileExists(filepath.Join("_posts", publishID)) {
+	if !state.repository.FileExists(p.postPath(publishID)) {
 		err := fmt.Errorf("post file not found: %s", publishID)
 		return &publisher.PublishResult{
 			Success:   false,
@@ -350,21 +558,70 @@ func (p *AlFolioPublisher) GetPublishStatus(ctx context.Context, publishID strin
 	}, nil
 }
 
+func (p *AlFolioPublisher) Unpublish(ctx context.Context, publishID string, config publisher.PublishConfig) error {
+	// For Al-Folio, unpublishing means removing the post file, its images and attachments, then
+	// committing
+	state := p.current()
+	state.repository.Lock()
+	defer state.repository.Unlock()
+
+	postPath := p.postPath(publishID)
+	if !state.repository.FileExists(postPath) {
+		publisher.Logger(ctx, p.logger).Info("Post file already removed", zap.String("publish_id", publishID))
+		return nil
+	}
+
+	if err := state.repository.RemovePath(postPath); err != nil {
+		return fmt.Errorf("failed to remove post file: %w", err)
+	}
+
+	// Image directory shares the date-slug prefix with the post filename
+	imageDir := strings.TrimSuffix(publishID, ".md")
+	if err := state.repository.RemovePath(filepath.Join("assets", "img", imageDir)); err != nil {
+		return fmt.Errorf("failed to remove image directory: %w", err)
+	}
+	if err := state.repository.RemovePath(filepath.Join("assets", "files", imageDir)); err != nil {
+		return fmt.Errorf("failed to remove attachment directory: %w", err)
+	}
+
+	if err := state.repository.Add(); err != nil {
+		return fmt.Errorf("failed to stage changes: %w", err)
+	}
+
+	if err := state.repository.Commit(fmt.Sprintf("Remove post: %s", publishID)); err != nil {
+		return fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	if config.Config["auto_publish"] == "true" {
+		if err := state.repository.Push(); err != nil {
+			return fmt.Errorf("failed to push changes: %w", err)
+		}
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Post unpublished from Al-Folio blog", zap.String("publish_id", publishID))
+	return nil
+}
+
 func (p *AlFolioPublisher) Cleanup(ctx context.Context, publishID string, config publisher.PublishConfig) error {
 	// For Al-Folio, cleanup might involve removing temporary files
-	p.logger.Info("Al-Folio blog cleanup completed", zap.String("publish_id", publishID))
+	publisher.Logger(ctx, p.logger).Info("Al-Folio blog cleanup completed", zap.String("publish_id", publishID))
 	return nil
 }
 
 // Helper methods
 
 func (p *AlFolioPublisher) writePostFile(ctx context.Context, content publisher.PublishContent, filename string, isDraft bool) (*publisher.PublishResult, error) {
-	// Write to _posts directory
-	postsDir := "_posts"
-	relativePath := filepath.Join(postsDir, filename)
+	state := p.current()
+	defer publisher.TimeStage(ctx, models.StageUpload)()
+	// Write to the collection directory (_posts or _notes)
+	collection := content.Metadata["collection"]
+	if collection == "" {
+		collection = postsDir
+	}
+	relativePath := filepath.Join(collection, filename)
 
 	// Create the file in the repository
-	if err := p.repository.CreateFile(relativePath, []byte(content.Content)); err != nil {
+	if err := state.repository.CreateFile(relativePath, []byte(content.Content)); err != nil {
 		return &publisher.PublishResult{
 			Success: false,
 			Error:   fmt.Errorf("failed to create post file: %w", err),
@@ -373,11 +630,11 @@ func (p *AlFolioPublisher) writePostFile(ctx context.Context, content publisher.
 
 	// Run prettier to format the markdown file
 	if err := p.runPrettier(ctx); err != nil {
-		p.logger.Warn("Failed to run prettier, continuing without formatting",
+		publisher.Logger(ctx, p.logger).Warn("Failed to run prettier, continuing without formatting",
 			zap.Error(err))
 	}
 
-	p.logger.Info("Post file created",
+	publisher.Logger(ctx, p.logger).Info("Post file created",
 		zap.String("filename", filename),
 		zap.String("path", relativePath),
 		zap.Bool("is_draft", isDraft))
@@ -392,6 +649,57 @@ func (p *AlFolioPublisher) writePostFile(ctx context.Context, content publisher.
 	}, nil
 }
 
+// maxSlugSuffix bounds the suffixes tried for a post whose path belongs to other pages
+const maxSlugSuffix = 100
+
+// reservePostFilename reserves the post's path in the repository for its page. When another
+// page has the path, e.g. a post with the same title on the same date, the slug gets the first
+// free suffix (-2, -3...) and the image directory follows the filename.
+func (p *AlFolioPublisher) reservePostFilename(ctx context.Context, content *publisher.PublishContent, config publisher.PublishConfig) error {
+	state := p.current()
+	base := strings.TrimSuffix(content.Metadata["filename"], ".md")
+	collection := content.Metadata["collection"]
+	if collection == "" {
+		collection = postsDir
+	}
+
+	for n := 1; n <= maxSlugSuffix; n++ {
+		candidate := base
+		if n > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, n)
+		}
+		relativePath := filepath.ToSlash(filepath.Join(collection, candidate+".md"))
+
+		ok, err := p.slugs.Reserve(ctx, config.Config["repo_url"], config.PlatformName, relativePath, content.ID, state.repository.FileExists(relativePath))
+		if err != nil {
+			return fmt.Errorf("failed to reserve post path: %w", err)
+		}
+		if !ok {
+			continue
+		}
+
+		if n > 1 {
+			publisher.Logger(ctx, p.logger).Warn("Post path taken by another page, using a suffixed slug",
+				zap.String("filename", base+".md"),
+				zap.String("reserved", candidate+".md"))
+		}
+		content.Metadata["filename"] = candidate + ".md"
+		content.Metadata["image_dir"] = candidate
+		return nil
+	}
+	return fmt.Errorf("no free post path for %s after %d suffixes", base, maxSlugSuffix)
+}
+
+// postPath returns the repository-relative path of a post, looking in the notes collection first
+func (p *AlFolioPublisher) postPath(filename string) string {
+	state := p.current()
+	notePath := filepath.Join(notesDir, filename)
+	if state.repository.FileExists(notePath) {
+		return notePath
+	}
+	return filepath.Join(postsDir, filename)
+}
+
 func (p *AlFolioPublisher) generateSlugFromFilename(filename string) string {
 	// Extract slug from Al-Folio filename: YYYY-MM-DD-slug.md -> slug
 	parts := strings.Split(filename, "-")
@@ -408,11 +716,12 @@ func (p *AlFolioPublisher) generateSlugFromFilename(filename string) string {
 }
 
 func (p *AlFolioPublisher) runPrettier(ctx context.Context) error {
+	state := p.current()
 	// Get the repository path
-	repoPath := p.repository.GetLocalPath()
+	repoPath := state.repository.GetLocalPath()
 
 	// First, run npm ci to ensure dependencies are installed
-	p.logger.Info("Installing dependencies with npm ci...")
+	publisher.Logger(ctx, p.logger).Info("Installing dependencies with npm ci...")
 	npmCmd := exec.CommandContext(ctx, "npm", "ci")
 	npmCmd.Dir = repoPath
 
@@ -421,11 +730,11 @@ func (p *AlFolioPublisher) runPrettier(ctx context.Context) error {
 		return fmt.Errorf("npm ci command failed: %w, output: %s", err, string(npmOutput))
 	}
 
-	p.logger.Info("Dependencies installed successfully",
+	publisher.Logger(ctx, p.logger).Info("Dependencies installed successfully",
 		zap.String("output", string(npmOutput)))
 
 	// Then run prettier to format the markdown file
-	p.logger.Info("Running prettier to format files...")
+	publisher.Logger(ctx, p.logger).Info("Running prettier to format files...")
 	cmd := exec.CommandContext(ctx, "npx", "prettier", "--write", ".")
 	cmd.Dir = repoPath
 
@@ -435,7 +744,7 @@ func (p *AlFolioPublisher) runPrettier(ctx context.Context) error {
 		return fmt.Errorf("prettier command failed: %w, output: %s", err, string(output))
 	}
 
-	p.logger.Info("Prettier formatting completed",
+	publisher.Logger(ctx, p.logger).Info("Prettier formatting completed",
 		zap.String("output", string(output)))
 
 	return nil
@@ -0,0 +1,144 @@
+package al_folio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/git"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// prBranchPrefix namespaces review branches created in PR mode
+	prBranchPrefix = "ripple/"
+	// maxPreviewLength keeps the description under the GitHub and GitLab body limits
+	maxPreviewLength = 60000
+)
+
+// publishPullRequest commits the post to its own branch and opens a pull request for review;
+// callers must hold the workspace lock
+func (p *AlFolioPublisher) publishPullRequest(ctx context.Context, content *publisher.PublishContent, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	state := p.current()
+	filename := content.Metadata["filename"]
+	branch := prBranchPrefix + strings.TrimSuffix(filename, ".md")
+
+	result, err := p.pushReviewBranch(ctx, content, branch, config)
+	// Always return to the base branch so later publishes start from a clean tree
+	if checkoutErr := state.repository.CheckoutBase(); checkoutErr != nil {
+		publisher.Logger(ctx, p.logger).Error("Failed to switch back to base branch", zap.Error(checkoutErr))
+	}
+	if err != nil {
+		return &publisher.PublishResult{
+			Success:  false,
+			Error:    err,
+			ErrorMsg: err.Error(),
+		}, nil
+	}
+	if !result.Success {
+		return result, nil
+	}
+
+	endPublish := publisher.TimeStage(ctx, models.StagePublish)
+	pr, err := state.prClient.CreatePullRequest(ctx, git.PullRequestOptions{
+		Title: fmt.Sprintf("Add post: %s", content.Title),
+		Body:  p.pullRequestBody(content, config),
+		Head:  branch,
+		Base:  state.repository.GetBranch(),
+	})
+	endPublish()
+	if err != nil {
+		prErr := fmt.Errorf("failed to open pull request: %w", err)
+		return &publisher.PublishResult{
+			Success:  false,
+			Error:    prErr,
+			ErrorMsg: prErr.Error(),
+		}, nil
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Opened pull request for Al-Folio post",
+		zap.String("filename", filename),
+		zap.String("branch", branch),
+		zap.String("pr_url", pr.URL))
+
+	return &publisher.PublishResult{
+		Success:     true,
+		PublishID:   filename,
+		URL:         pr.URL,
+		PublishedAt: time.Now(),
+		Metadata: map[string]string{
+			"branch":        branch,
+			"pr_url":        pr.URL,
+			"pr_number":     fmt.Sprintf("%d", pr.Number),
+			"review_status": "pending_review",
+		},
+	}, nil
+}
+
+// pushReviewBranch writes the post and its images on the review branch, commits and pushes it
+func (p *AlFolioPublisher) pushReviewBranch(ctx context.Context, content *publisher.PublishContent, branch string, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	state := p.current()
+	if err := state.repository.CreateBranch(branch); err != nil {
+		return nil, err
+	}
+
+	if err := p.processResources(ctx, content); err != nil {
+		return nil, err
+	}
+
+	writeResult, err := p.writePostFile(ctx, *content, content.Metadata["filename"], false)
+	if err != nil {
+		return nil, err
+	}
+	if !writeResult.Success {
+		return writeResult, nil
+	}
+
+	if err := state.repository.Add(); err != nil {
+		return nil, fmt.Errorf("failed to stage changes: %w", err)
+	}
+
+	commitMessage := fmt.Sprintf("Add new post: %s", content.Metadata["filename"])
+	if customMessage := config.Config["commit_message"]; customMessage != "" {
+		commitMessage = customMessage
+	}
+	if err := state.repository.Commit(commitMessage); err != nil {
+		return nil, fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	if err := state.repository.PushBranch(branch); err != nil {
+		return nil, gitError(err)
+	}
+
+	return writeResult, nil
+}
+
+// pullRequestBody renders the post preview shown to reviewers
+func (p *AlFolioPublisher) pullRequestBody(content *publisher.PublishContent, config publisher.PublishConfig) string {
+	var body strings.Builder
+
+	body.WriteString(fmt.Sprintf("New post **%s** from Notion, opened by Ripple for review.\n\n", content.Title))
+	body.WriteString(fmt.Sprintf("- File: `%s/%s`\n", content.Metadata["collection"], content.Metadata["filename"]))
+	if baseURL := config.Config["base_url"]; baseURL != "" {
+		slug := p.generateSlugFromFilename(content.Metadata["filename"])
+		body.WriteString(fmt.Sprintf("- URL after merge: %s/blog/%d/%s/\n", baseURL, time.Now().Year(), slug))
+	}
+	if len(content.Tags) > 0 {
+		body.WriteString(fmt.Sprintf("- Tags: %s\n", strings.Join(content.Tags, ", ")))
+	}
+
+	preview := content.Content
+	if len(preview) > maxPreviewLength {
+		preview = preview[:maxPreviewLength] + "\n\n... (truncated)"
+	}
+
+	body.WriteString("\n<details>\n<summary>Preview</summary>\n\n")
+	body.WriteString(preview)
+	body.WriteString("\n\n</details>\n")
+
+	return body.String()
+}
@@ -0,0 +1,274 @@
+package al_folio
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/ifuryst/ripple/internal/content"
+)
+
+// markdownRenderer renders a document as markdown. With footnotes, web links become kramdown
+// footnotes and are listed again in a references section at the end of the post.
+type markdownRenderer struct {
+	footnotes bool
+	links     []footnoteLink
+	numbers   map[string]int
+}
+
+// footnoteLink is a link cited by a footnote, with the text it was first linked from
+type footnoteLink struct {
+	url  string
+	text string
+}
+
+// renderMarkdown renders a content document as markdown with Jekyll figures for images
+func renderMarkdown(doc *content.Document, footnotes bool) string {
+	r := &markdownRenderer{footnotes: footnotes, numbers: make(map[string]int)}
+
+	var lines []string
+	for _, block := range doc.Blocks {
+		lines = append(lines, r.renderBlock(block))
+	}
+	if len(r.links) > 0 {
+		lines = append(lines, r.renderReferences())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *markdownRenderer) renderBlock(block content.Block) string {
+	switch block.Type {
+	case content.BlockHeading:
+		text := r.renderSpans(block.Text)
+		if text == "" {
+			return ""
+		}
+		return strings.Repeat("#", block.Level) + " " + text
+	case content.BlockList:
+		return r.renderList(block.List)
+	case content.BlockQuote:
+		text := r.renderSpans(block.Text)
+		if text == "" {
+			return ""
+		}
+		return "> " + text
+	case content.BlockCode:
+		if block.Code == "" {
+			return ""
+		}
+		return "```" + block.Language + "\n" + cleanText(block.Code) + "\n```"
+	case content.BlockDivider:
+		return "---"
+	case content.BlockImage:
+		return renderImage(block.Image)
+	case content.BlockVideo:
+		return r.renderVideo(block.Video)
+	case content.BlockFile:
+		return r.renderFile(block.File)
+	case content.BlockTable:
+		return r.renderTable(block.Table)
+	default:
+		return r.renderSpans(block.Text)
+	}
+}
+
+func (r *markdownRenderer) renderList(list *content.List) string {
+	var items []string
+	for _, item := range list.Items {
+		text := r.renderSpans(item.Text)
+		if text == "" {
+			continue
+		}
+
+		marker := "-"
+		if list.Ordered {
+			marker = fmt.Sprintf("%d.", len(items)+1)
+		}
+		if item.Checked != nil {
+			if *item.Checked {
+				marker += " [x]"
+			} else {
+				marker += " [ ]"
+			}
+		}
+		items = append(items, marker+" "+text)
+	}
+	return strings.Join(items, "\n")
+}
+
+// renderImage returns the image in Jekyll figure format
+func renderImage(image *content.Image) string {
+	return fmt.Sprintf(`<div class="row mt-3">
+    <div class="col-sm mt-0 mb-0">
+        {%% include figure.liquid loading="eager" path="%s" class="img-fluid rounded z-depth-1" zoomable=true %%}
+    </div>
+</div>`, image.URL)
+}
+
+// renderVideo plays video files in a <video> tag and embeds YouTube's player; other video
+// links stay links
+func (r *markdownRenderer) renderVideo(video *content.Video) string {
+	caption := r.renderSpans(video.Caption)
+
+	var player string
+	switch {
+	case video.IsFile():
+		player = fmt.Sprintf(`<video src="%s" class="img-fluid rounded z-depth-1" controls preload="metadata"></video>`, video.URL)
+	case video.EmbedURL() != "":
+		player = fmt.Sprintf(`{%% include video.liquid path="%s" class="img-fluid rounded z-depth-1" %%}`, video.EmbedURL())
+	default:
+		if caption == "" {
+			caption = video.URL
+		}
+		return fmt.Sprintf("[%s](%s)", caption, video.URL)
+	}
+
+	figure := fmt.Sprintf(`<div class="row mt-3">
+    <div class="col-sm mt-0 mb-0">
+        %s
+    </div>
+</div>`, player)
+	if caption != "" {
+		figure += "\n<div class=\"caption\">\n    " + caption + "\n</div>"
+	}
+	return figure
+}
+
+// fileIcons are the Font Awesome icons al-folio ships for the kinds of attachments
+var fileIcons = map[string]string{
+	"PDF":         "fa-file-pdf",
+	"Slides":      "fa-file-powerpoint",
+	"Document":    "fa-file-word",
+	"Spreadsheet": "fa-file-excel",
+	"Archive":     "fa-file-zipper",
+}
+
+// renderFile renders an attachment as a download button with the file's icon
+func (r *markdownRenderer) renderFile(file *content.File) string {
+	icon, ok := fileIcons[file.Kind()]
+	if !ok {
+		icon = "fa-file"
+	}
+
+	figure := fmt.Sprintf(`<div class="row mt-3">
+    <div class="col-sm mt-0 mb-0">
+        <a href="%s" class="btn btn-outline-primary" download><i class="fa-solid %s"></i> %s</a> <span class="text-muted">%s</span>
+    </div>
+</div>`, file.URL, icon, html.EscapeString(file.FileName()), file.Kind())
+	if caption := r.renderSpans(file.Caption); caption != "" {
+		figure += "\n<div class=\"caption\">\n    " + caption + "\n</div>"
+	}
+	return figure
+}
+
+// renderTable returns a markdown table; al-folio posts enable pretty_table to style it
+func (r *markdownRenderer) renderTable(table *content.Table) string {
+	if len(table.Rows) == 0 {
+		return ""
+	}
+
+	row := func(cells [][]content.Span) string {
+		texts := make([]string, len(cells))
+		for i, cell := range cells {
+			texts[i] = strings.ReplaceAll(r.renderSpans(cell), "|", `\|`)
+		}
+		return "| " + strings.Join(texts, " | ") + " |"
+	}
+
+	// Markdown tables always have a header row, so an empty one is used when the table has none
+	rows := table.Rows
+	header := make([][]content.Span, len(rows[0]))
+	if table.HasHeader {
+		header, rows = rows[0], rows[1:]
+	}
+
+	lines := []string{row(header), "|" + strings.Repeat(" --- |", len(header))}
+	for _, cells := range rows {
+		lines = append(lines, row(cells))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cleanText removes unwanted characters and fixes encoding issues
+func cleanText(text string) string {
+	if text == "" {
+		return ""
+	}
+
+	// Replace non-breaking space (0xa0) with regular space
+	text = strings.ReplaceAll(text, "\u00a0", " ")
+
+	return text
+}
+
+func (r *markdownRenderer) renderSpans(spans []content.Span) string {
+	var text string
+	for _, span := range spans {
+		text += r.renderSpan(span)
+	}
+	return cleanText(text)
+}
+
+func (r *markdownRenderer) renderSpan(span content.Span) string {
+	text := span.Text
+
+	if span.Bold {
+		text = "**" + text + "**"
+	}
+	if span.Italic {
+		text = "*" + text + "*"
+	}
+	if span.Code {
+		text = "`" + text + "`"
+	}
+	if span.Strikethrough {
+		text = "~~" + text + "~~"
+	}
+	// Markdown doesn't have underline, use emphasis
+	if span.Underline {
+		text = "*" + text + "*"
+	}
+	if span.Link != "" {
+		if r.footnotes && isWebLink(span.Link) {
+			text += fmt.Sprintf("[^%d]", r.footnote(span.Link, span.Text))
+		} else {
+			text = "[" + text + "](" + span.Link + ")"
+		}
+	}
+
+	return text
+}
+
+// footnote returns the number of the footnote citing a link; a link cited again reuses it
+func (r *markdownRenderer) footnote(url, text string) int {
+	if number, ok := r.numbers[url]; ok {
+		return number
+	}
+	r.links = append(r.links, footnoteLink{url: url, text: cleanText(strings.TrimSpace(text))})
+	r.numbers[url] = len(r.links)
+	return len(r.links)
+}
+
+// renderReferences returns the references section listing every cited link, followed by the
+// kramdown footnote definitions
+func (r *markdownRenderer) renderReferences() string {
+	lines := []string{"", "## References", ""}
+	for i, link := range r.links {
+		text := link.text
+		if text == "" {
+			text = link.url
+		}
+		lines = append(lines, fmt.Sprintf("%d. [%s](%s)", i+1, text, link.url))
+	}
+
+	lines = append(lines, "")
+	for i, link := range r.links {
+		lines = append(lines, fmt.Sprintf("[^%d]: <%s>", i+1, link.url))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isWebLink reports whether a link points to another site rather than within the post
+func isWebLink(link string) bool {
+	return strings.HasPrefix(link, "http://") || strings.HasPrefix(link, "https://")
+}
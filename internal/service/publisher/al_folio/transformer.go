@@ -6,11 +6,16 @@ import (
 	"github.com/ifuryst/ripple/pkg/util"
 	"strings"
 	"time"
+
+	"github.com/ifuryst/ripple/internal/content"
+	"github.com/ifuryst/ripple/internal/service/publisher"
 )
 
 // AlFolioTransformer converts Notion content to Al-Folio-compatible Markdown
 type AlFolioTransformer struct {
 	baseTransformer *MarkdownTransformer
+	// frontMatter are the configured fields merged into every post's front matter
+	frontMatter map[string]interface{}
 }
 
 func NewAlFolioTransformer() *AlFolioTransformer {
@@ -19,48 +24,79 @@ func NewAlFolioTransformer() *AlFolioTransformer {
 	}
 }
 
-func (t *AlFolioTransformer) Transform(ctx context.Context, content string, metadata map[string]string) (string, error) {
-	// Convert Notion blocks JSON to markdown
-	markdownContent, err := convertNotionBlocksToMarkdown(content)
+// SetFrontMatter sets the fields merged into the front matter of every post, overriding the
+// default settings and generated fields
+func (t *AlFolioTransformer) SetFrontMatter(fields map[string]interface{}) {
+	t.frontMatter = fields
+}
+
+func (t *AlFolioTransformer) Transform(ctx context.Context, doc *content.Document, metadata map[string]string) (string, error) {
+	markdownContent := renderMarkdown(doc, metadata["footnotes"] == "true")
+
+	// The page's Front matter property overrides the configured fields
+	pageFields, err := parseFrontMatter(metadata["front_matter"])
 	if err != nil {
-		return "", fmt.Errorf("notion blocks to markdown conversion failed: %w", err)
+		return "", publisher.NewError(publisher.ErrorCodeContentInvalid, fmt.Errorf("invalid Front matter property: %w", err))
 	}
 
 	// Generate Al-Folio-specific front matter
-	frontMatter := t.generateAlFolioFrontMatter(metadata)
+	frontMatter, err := t.generateAlFolioFrontMatter(metadata, frontMatterSettings(t.frontMatter, pageFields))
+	if err != nil {
+		return "", err
+	}
 
 	return frontMatter + "\n\n" + markdownContent, nil
 }
 
-func (t *AlFolioTransformer) generateAlFolioFrontMatter(metadata map[string]string) string {
+// generateAlFolioFrontMatter writes the fields generated from the page, then the settings.
+// A setting with the name of a generated field replaces it.
+func (t *AlFolioTransformer) generateAlFolioFrontMatter(metadata map[string]string, settings map[string]interface{}) (string, error) {
 	var frontMatter []string
 	frontMatter = append(frontMatter, "---")
 
+	generated := func(key string) bool {
+		_, overridden := settings[key]
+		return !overridden
+	}
+
 	// Required fields
-	frontMatter = append(frontMatter, "layout: post")
+	if generated("layout") {
+		frontMatter = append(frontMatter, "layout: post")
+	}
 
 	// Title
-	if title := metadata["title"]; title != "" {
+	if title := metadata["title"]; title != "" && generated("title") {
 		frontMatter = append(frontMatter, fmt.Sprintf("title: \"%s\"", util.EscapeYAML(title)))
 	}
 
 	// Date - format for Al-Folio
-	if dateStr := metadata["publish_date"]; dateStr != "" {
-		// Try to parse the date and format it correctly
-		if date, err := time.Parse(time.RFC3339, dateStr); err == nil {
-			// Format as Al-Folio expects: YYYY-MM-DDTHH:MM:SS+08:00
-			formattedDate := date.Format("2006-01-02T15:04:05-07:00")
+	if generated("date") {
+		if dateStr := metadata["publish_date"]; dateStr != "" {
+			// Try to parse the date and format it correctly
+			if date, err := time.Parse(time.RFC3339, dateStr); err == nil {
+				// Format as Al-Folio expects: YYYY-MM-DDTHH:MM:SS+08:00
+				formattedDate := date.Format("2006-01-02T15:04:05-07:00")
+				frontMatter = append(frontMatter, fmt.Sprintf("date: %s", formattedDate))
+			}
+		} else {
+			// Use current time if no date provided
+			now := time.Now()
+			formattedDate := now.Format("2006-01-02T15:04:05-07:00")
 			frontMatter = append(frontMatter, fmt.Sprintf("date: %s", formattedDate))
 		}
-	} else {
-		// Use current time if no date provided
-		now := time.Now()
-		formattedDate := now.Format("2006-01-02T15:04:05-07:00")
-		frontMatter = append(frontMatter, fmt.Sprintf("date: %s", formattedDate))
+	}
+
+	// Description - the SEO description, falling back to the summary
+	description := metadata["description"]
+	if description == "" {
+		description = metadata["summary"]
+	}
+	if description != "" && generated("description") {
+		frontMatter = append(frontMatter, fmt.Sprintf("description: \"%s\"", util.EscapeYAML(description)))
 	}
 
 	// Tags - can be multiple, space-separated or array format
-	if tags := metadata["tags"]; tags != "" {
+	if tags := metadata["tags"]; tags != "" && generated("tags") {
 		// Parse tags from various formats
 		tagList := util.ParseTags(tags)
 		if len(tagList) > 0 {
@@ -76,7 +112,7 @@ func (t *AlFolioTransformer) generateAlFolioFrontMatter(metadata map[string]stri
 	}
 
 	// Categories - similar to tags
-	if categories := metadata["categories"]; categories != "" {
+	if categories := metadata["categories"]; categories != "" && generated("categories") {
 		categoryList := util.ParseTags(categories) // Same parsing logic
 		if len(categoryList) > 0 {
 			if len(categoryList) == 1 {
@@ -90,29 +126,65 @@ func (t *AlFolioTransformer) generateAlFolioFrontMatter(metadata map[string]stri
 		}
 	}
 
-	// Al-Folio-specific settings
-	frontMatter = append(frontMatter, "giscus_comments: true")
-	frontMatter = append(frontMatter, "tabs: true")
-	frontMatter = append(frontMatter, "pretty_table: true")
+	// Generated cover cards have a stable URL; Notion covers are signed URLs that expire
+	if metadata["cover_generated"] == "true" && strings.HasPrefix(metadata["cover_url"], "http") && generated("thumbnail") {
+		frontMatter = append(frontMatter, fmt.Sprintf("thumbnail: %s", metadata["cover_url"]))
+	}
+
+	// Al-Folio-specific settings; null values remove a field
+	for _, key := range orderedFrontMatterKeys(settings) {
+		if value := settings[key]; value != nil && key != "toc" {
+			field, err := encodeFrontMatterField(key, value)
+			if err != nil {
+				return "", err
+			}
+			frontMatter = append(frontMatter, field)
+		}
+	}
 
 	// Check if we need TOC (Table of Contents)
-	if t.shouldAddTOC(metadata) {
-		frontMatter = append(frontMatter, "toc:")
-		frontMatter = append(frontMatter, "  sidebar: left")
+	if toc, ok := t.tocField(metadata, settings); ok {
+		field, err := encodeFrontMatterField("toc", toc)
+		if err != nil {
+			return "", err
+		}
+		frontMatter = append(frontMatter, field)
 	}
 
 	frontMatter = append(frontMatter, "---")
 
-	return strings.Join(frontMatter, "\n")
+	return strings.Join(frontMatter, "\n"), nil
+}
+
+// tocField returns the toc field of a post that gets a table of contents. The page's TOC
+// checkbox decides first. Otherwise a toc setting of true or false always or never adds one,
+// and any other value is the field written when the post is long enough.
+func (t *AlFolioTransformer) tocField(metadata map[string]string, settings map[string]interface{}) (interface{}, bool) {
+	value, configured := settings["toc"]
+	switch toc := value.(type) {
+	case bool:
+		value = defaultTOC
+		if metadata["toc"] == "" {
+			return value, toc
+		}
+	case nil:
+		value = defaultTOC
+		if configured && metadata["toc"] == "" {
+			return value, false
+		}
+	}
+	return value, t.shouldAddTOC(metadata)
 }
 
 func (t *AlFolioTransformer) shouldAddTOC(metadata map[string]string) bool {
 	// Add TOC if the content is long enough or has headers
 	// This is a simple heuristic - you can make it more sophisticated
 
-	// Check if TOC is explicitly requested
+	// Check if TOC is explicitly requested or disabled
 	if toc := metadata["toc"]; toc == "true" || toc == "yes" {
 		return true
+	} else if toc == "false" || toc == "no" {
+		return false
 	}
 
 	// Check content length or other factors
@@ -0,0 +1,60 @@
+package al_folio
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/util"
+)
+
+// jekyllPostFilename is the name Jekyll needs to pick up a post: its date followed by a slug
+var jekyllPostFilename = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}-[^/\\\s]+\.md$`)
+
+// Validate checks that the post has a title and gets a filename Jekyll can build
+func (p *AlFolioPublisher) Validate(content publisher.PublishContent, config publisher.PublishConfig) []publisher.Violation {
+	var violations []publisher.Violation
+
+	if strings.TrimSpace(content.Title) == "" {
+		violations = append(violations, publisher.Violation{
+			Field:   "title",
+			Rule:    "required",
+			Message: "title is required",
+		})
+	}
+
+	// Name the file the way TransformContent will
+	publishDate := time.Now()
+	if content.PublishDate != nil {
+		publishDate = *content.PublishDate
+	}
+	metadata := make(map[string]string)
+	for k, v := range content.Metadata {
+		metadata[k] = v
+	}
+	if slugStrategy := config.Config["slug_strategy"]; slugStrategy != "" {
+		metadata["slug_strategy"] = slugStrategy
+	}
+	filename := util.GenerateFilenameWithMetadata(content.Title, publishDate, metadata)
+
+	switch {
+	case !utf8.ValidString(filename):
+		// Slugs are cut to 50 bytes, which can split a character
+		violations = append(violations, publisher.Violation{
+			Field:   "filename",
+			Rule:    "filename_encoding",
+			Message: fmt.Sprintf("filename %q is not valid UTF-8, set an EN title to get an ASCII slug", filename),
+		})
+	case !jekyllPostFilename.MatchString(filename):
+		violations = append(violations, publisher.Violation{
+			Field:   "filename",
+			Rule:    "filename_format",
+			Message: fmt.Sprintf("filename %q has no slug, set an EN title or a title with letters or digits", filename),
+		})
+	}
+
+	return violations
+}
@@ -0,0 +1,80 @@
+package announce
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/ifuryst/ripple/internal/content"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// announcement is the card announcing a page: its title and summary, the cover and the link
+// to the post on the canonical platform
+type announcement struct {
+	Title   string   `json:"title"`
+	Summary string   `json:"summary,omitempty"`
+	Link    string   `json:"link,omitempty"`
+	Image   string   `json:"image,omitempty"`
+	Author  string   `json:"author,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+// buildAnnouncement takes the summary of the page, else its description or first paragraph.
+// Chat apps can only show covers they can download, so local files such as cover cards rendered
+// without a public URL are left out.
+func buildAnnouncement(page publisher.PublishContent, doc *content.Document) *announcement {
+	summary := strings.TrimSpace(page.Summary)
+	if summary == "" {
+		summary = strings.TrimSpace(page.Metadata["description"])
+	}
+	if summary == "" && doc != nil {
+		for _, block := range doc.Blocks {
+			if block.Type != content.BlockParagraph {
+				continue
+			}
+			if text := strings.TrimSpace(content.PlainText(block.Text)); text != "" {
+				summary = text
+				break
+			}
+		}
+	}
+
+	a := &announcement{
+		Title:   strings.TrimSpace(page.Title),
+		Summary: summary,
+		Link:    page.Metadata["canonical_url"],
+		Author:  page.Author,
+		Tags:    page.Tags,
+	}
+	if cover := page.Metadata["cover_url"]; strings.HasPrefix(cover, "http://") || strings.HasPrefix(cover, "https://") {
+		a.Image = cover
+	}
+	return a
+}
+
+func (a *announcement) encode() (string, error) {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// decodeAnnouncement reads content that is already an announcement, reporting false for page
+// content that still has to be transformed
+func decodeAnnouncement(data string) (*announcement, bool) {
+	var a announcement
+	if err := json.Unmarshal([]byte(data), &a); err != nil || a.Title == "" {
+		return nil, false
+	}
+	return &a, true
+}
+
+// truncateRunes shortens text to limit characters, ending with an ellipsis when it was cut
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,276 @@
+package announce
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+const (
+	// Embed limits of the Discord API
+	discordMaxTitle       = 256
+	discordMaxDescription = 4096
+	discordMaxFooter      = 2048
+	discordMaxAuthor      = 256
+	// defaultDiscordColor is the Discord blurple shown along the embed
+	defaultDiscordColor = 0x5865F2
+)
+
+// discord sends announcements as embeds with channel webhooks, created in the integrations
+// settings of a channel; the webhook URL is the credential
+type discord struct {
+	client    *http.Client
+	webhooks  []string
+	username  string
+	avatarURL string
+	color     int
+	mention   string
+}
+
+type discordEmbed struct {
+	Title       string             `json:"title"`
+	Description string             `json:"description,omitempty"`
+	URL         string             `json:"url,omitempty"`
+	Color       int                `json:"color"`
+	Image       *discordEmbedImage `json:"image,omitempty"`
+	Author      *discordEmbedName  `json:"author,omitempty"`
+	Footer      *discordEmbedText  `json:"footer,omitempty"`
+	Timestamp   string             `json:"timestamp"`
+}
+
+type discordEmbedImage struct {
+	URL string `json:"url"`
+}
+
+type discordEmbedName struct {
+	Name string `json:"name"`
+}
+
+type discordEmbedText struct {
+	Text string `json:"text"`
+}
+
+type discordMessage struct {
+	ID        string `json:"id"`
+	ChannelID string `json:"channel_id"`
+	GuildID   string `json:"guild_id"`
+}
+
+func (d *discord) validate(config publisher.PublishConfig) error {
+	webhooks := splitList(config.Config["webhook_urls"])
+	if len(webhooks) == 0 {
+		return fmt.Errorf("missing required config: webhook_urls")
+	}
+	for _, webhook := range webhooks {
+		if _, err := discordWebhookID(webhook); err != nil {
+			return err
+		}
+	}
+
+	if value := config.Config["color"]; value != "" {
+		if _, err := parseColor(value); err != nil {
+			return fmt.Errorf("invalid color %q, expected a hex color such as #5865F2", value)
+		}
+	}
+	return nil
+}
+
+func (d *discord) initialize(client *http.Client, config publisher.PublishConfig) error {
+	d.client = client
+	d.webhooks = splitList(config.Config["webhook_urls"])
+	d.username = config.Config["username"]
+	d.avatarURL = config.Config["avatar_url"]
+	d.mention = config.Config["mention"]
+	d.color = defaultDiscordColor
+	if value := config.Config["color"]; value != "" {
+		color, err := parseColor(value)
+		if err != nil {
+			return fmt.Errorf("invalid color %q: %w", value, err)
+		}
+		d.color = color
+	}
+	return nil
+}
+
+// checkCredentials fetches every webhook
+func (d *discord) checkCredentials(ctx context.Context) error {
+	for _, webhook := range d.webhooks {
+		if err := d.call(ctx, http.MethodGet, webhook, nil, nil); err != nil {
+			return webhookError(webhook, err)
+		}
+	}
+	return nil
+}
+
+func (d *discord) targets() []string {
+	return d.webhooks
+}
+
+// send posts the embed and waits for the message, so it can be deleted later. The reference is
+// the webhook ID and message ID, the webhook token stays in the config.
+func (d *discord) send(ctx context.Context, target string, a *announcement) (string, string, error) {
+	embed := discordEmbed{
+		Title:       truncateRunes(a.Title, discordMaxTitle),
+		Description: truncateRunes(a.Summary, discordMaxDescription),
+		URL:         a.Link,
+		Color:       d.color,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+	}
+	if a.Image != "" {
+		embed.Image = &discordEmbedImage{URL: a.Image}
+	}
+	if a.Author != "" {
+		embed.Author = &discordEmbedName{Name: truncateRunes(a.Author, discordMaxAuthor)}
+	}
+	if len(a.Tags) > 0 {
+		embed.Footer = &discordEmbedText{Text: truncateRunes(strings.Join(a.Tags, " · "), discordMaxFooter)}
+	}
+
+	request := map[string]any{
+		"embeds": []discordEmbed{embed},
+		// Only the configured mention may ping anyone, never a title or summary
+		"allowed_mentions": map[string]any{"parse": []string{}},
+	}
+	if d.mention != "" {
+		request["content"] = d.mention
+		request["allowed_mentions"] = map[string]any{"parse": []string{"everyone", "roles", "users"}}
+	}
+	if d.username != "" {
+		request["username"] = d.username
+	}
+	if d.avatarURL != "" {
+		request["avatar_url"] = d.avatarURL
+	}
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	var message discordMessage
+	if err := d.call(ctx, http.MethodPost, target+"?wait=true", bytes.NewReader(data), &message); err != nil {
+		return "", "", webhookError(target, err)
+	}
+
+	webhookID, _ := discordWebhookID(target)
+	messageURL := ""
+	if message.GuildID != "" && message.ChannelID != "" {
+		messageURL = fmt.Sprintf("https://discord.com/channels/%s/%s/%s", message.GuildID, message.ChannelID, message.ID)
+	}
+	return webhookID + "/" + message.ID, messageURL, nil
+}
+
+// deleteMessage deletes a message with the webhook that sent it
+func (d *discord) deleteMessage(ctx context.Context, ref string) error {
+	webhookID, messageID, ok := strings.Cut(ref, "/")
+	if !ok || messageID == "" {
+		return fmt.Errorf("invalid Discord message reference %q", ref)
+	}
+
+	for _, webhook := range d.webhooks {
+		if id, _ := discordWebhookID(webhook); id == webhookID {
+			err := d.call(ctx, http.MethodDelete, webhook+"/messages/"+url.PathEscape(messageID), nil, nil)
+			if isNotFound(err) {
+				// Already deleted
+				return nil
+			}
+			return err
+		}
+	}
+	return fmt.Errorf("Discord webhook %s is no longer configured", webhookID)
+}
+
+// call sends a request to a webhook and decodes the JSON response into out
+func (d *discord) call(ctx context.Context, method, endpoint string, body io.Reader, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		// The error of the request names the URL, which holds the webhook token
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to call Discord: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		message := string(data)
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			message = apiErr.Message
+		}
+		switch resp.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return fmt.Errorf("%w: Discord rejected the webhook: %s", publisher.ErrCredentialsExpired, message)
+		case http.StatusNotFound:
+			return publisher.NewError(publisher.ErrorCodeContentInvalid, fmt.Errorf("%w: %s", errNotFound, message))
+		}
+		return publisher.NewError(publisher.CodeForStatus(resp.StatusCode), fmt.Errorf("Discord API returned status %d: %s", resp.StatusCode, message))
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// webhookError reports a webhook deleted in Discord as expired credentials, it is gone for good
+func webhookError(webhook string, err error) error {
+	if !isNotFound(err) {
+		return err
+	}
+	id, _ := discordWebhookID(webhook)
+	return fmt.Errorf("%w: Discord webhook %s was deleted", publisher.ErrCredentialsExpired, id)
+}
+
+// discordWebhookID reads the ID of a webhook URL, https://discord.com/api/webhooks/<id>/<token>
+func discordWebhookID(webhook string) (string, error) {
+	parsed, err := url.Parse(webhook)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return "", fmt.Errorf("invalid Discord webhook URL")
+	}
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	for i, part := range parts {
+		if part == "webhooks" && i+2 < len(parts) && parts[i+1] != "" && parts[i+2] != "" {
+			return parts[i+1], nil
+		}
+	}
+	return "", fmt.Errorf("invalid Discord webhook URL, expected .../webhooks/<id>/<token>")
+}
+
+// parseColor parses a hex color such as #5865F2
+func parseColor(value string) (int, error) {
+	color, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimSpace(value), "#"), 16, 32)
+	if err != nil || color < 0 || color > 0xFFFFFF {
+		return 0, fmt.Errorf("not a hex color")
+	}
+	return int(color), nil
+}
@@ -0,0 +1,289 @@
+package announce
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/httpclient"
+)
+
+// Platforms pages are announced on
+const (
+	DiscordPlatformName = "discord"
+	SlackPlatformName   = "slack"
+)
+
+// errNoDrafts is returned for draft-only routes, an announcement is only worth sending once the
+// post it links to is out
+var errNoDrafts = errors.New("announcements have no drafts, they are sent when the page is published")
+
+// errNotFound is wrapped when a message does not exist (anymore)
+var errNotFound = errors.New("not found")
+
+// channel is the API of a chat app announcements are sent to
+type channel interface {
+	// validate checks the config; it must not make network calls
+	validate(config publisher.PublishConfig) error
+	initialize(client *http.Client, config publisher.PublishConfig) error
+	checkCredentials(ctx context.Context) error
+	// targets are where each announcement is sent, e.g. webhooks or channels
+	targets() []string
+	// send posts the announcement to a target, returning a reference to delete the message by
+	// and its URL, when the app has one
+	send(ctx context.Context, target string, a *announcement) (ref string, url string, err error)
+	deleteMessage(ctx context.Context, ref string) error
+}
+
+// AnnouncePublisher sends a card announcing a page to the webhooks or channels of a chat app.
+// The publish ID lists the references of the messages sent, separated by commas.
+type AnnouncePublisher struct {
+	logger       *zap.Logger
+	platformName string
+	channel      channel
+	client       *http.Client
+	// newChannel creates the chat app channel, credentials are verified on a channel of their own
+	newChannel func() channel
+}
+
+// NewDiscordPublisher creates a publisher announcing pages with Discord webhooks
+func NewDiscordPublisher(logger *zap.Logger) publisher.Publisher {
+	return newAnnouncePublisher(DiscordPlatformName, func() channel { return &discord{} }, logger)
+}
+
+// NewSlackPublisher creates a publisher announcing pages in Slack channels with a bot token
+func NewSlackPublisher(logger *zap.Logger) publisher.Publisher {
+	return newAnnouncePublisher(SlackPlatformName, func() channel { return &slack{} }, logger)
+}
+
+func newAnnouncePublisher(platformName string, newChannel func() channel, logger *zap.Logger) *AnnouncePublisher {
+	return &AnnouncePublisher{
+		logger:       logger,
+		platformName: platformName,
+		channel:      newChannel(),
+		client:       httpclient.New(),
+		newChannel:   newChannel,
+	}
+}
+
+func (p *AnnouncePublisher) GetPlatformName() string {
+	return p.platformName
+}
+
+func (p *AnnouncePublisher) Initialize(ctx context.Context, config publisher.PublishConfig) error {
+	if err := p.ValidateConfig(config); err != nil {
+		return err
+	}
+
+	var opts []httpclient.Option
+	if proxyURL := config.Config["proxy_url"]; proxyURL != "" {
+		parsed, err := httpclient.ParseProxy(proxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy_url: %w", err)
+		}
+		opts = append(opts, httpclient.WithProxy(parsed))
+	}
+	p.client = httpclient.New(opts...)
+
+	if err := p.channel.initialize(p.client, config); err != nil {
+		return err
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Announcement publisher initialized successfully",
+		zap.String("platform", p.platformName),
+		zap.Int("targets", len(p.channel.targets())))
+	return nil
+}
+
+func (p *AnnouncePublisher) ValidateConfig(config publisher.PublishConfig) error {
+	return p.channel.validate(config)
+}
+
+// VerifyCredentials verifies every webhook or channel can still be posted to, on a publisher of
+// its own so the channel publishes use is left alone
+func (p *AnnouncePublisher) VerifyCredentials(ctx context.Context, config publisher.PublishConfig) error {
+	probe := newAnnouncePublisher(p.platformName, p.newChannel, p.logger)
+	if err := probe.Initialize(ctx, config); err != nil {
+		return err
+	}
+	return probe.channel.checkCredentials(ctx)
+}
+
+// Validate checks the page has a title to announce
+func (p *AnnouncePublisher) Validate(content publisher.PublishContent, config publisher.PublishConfig) []publisher.Violation {
+	if strings.TrimSpace(content.Title) == "" {
+		return []publisher.Violation{{
+			Field:   "title",
+			Rule:    "required",
+			Message: "title is required",
+		}}
+	}
+	return nil
+}
+
+// TransformContent turns the page into its announcement; the content becomes the announcement
+// as JSON
+func (p *AnnouncePublisher) TransformContent(ctx context.Context, content publisher.PublishContent) (*publisher.PublishContent, error) {
+	defer publisher.TimeStage(ctx, models.StageTransform)()
+	doc, err := content.ContentDocument()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse content: %w", err)
+	}
+
+	encoded, err := buildAnnouncement(content, doc).encode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode announcement: %w", err)
+	}
+
+	result := content
+	result.Content = encoded
+	result.Document = nil
+	result.Resources = nil
+	result.Metadata = make(map[string]string)
+	for k, v := range content.Metadata {
+		result.Metadata[k] = v
+	}
+	return &result, nil
+}
+
+// ProcessResources leaves the cover where it is, the chat app fetches it from its URL
+func (p *AnnouncePublisher) ProcessResources(ctx context.Context, content *publisher.PublishContent, config publisher.PublishConfig) error {
+	return nil
+}
+
+func (p *AnnouncePublisher) SaveToDraft(ctx context.Context, content publisher.PublishContent, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	return failed(publisher.NewError(publisher.ErrorCodeContentInvalid, errNoDrafts)), nil
+}
+
+func (p *AnnouncePublisher) Publish(ctx context.Context, draftID string, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	return failed(publisher.NewError(publisher.ErrorCodeContentInvalid, errNoDrafts)), nil
+}
+
+// PublishDirect sends the announcement to every target. It succeeds when any target got it,
+// so publishing again does not announce the page twice where it went through; the targets that
+// failed are logged and counted in the metadata.
+func (p *AnnouncePublisher) PublishDirect(ctx context.Context, content publisher.PublishContent, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	a, ok := decodeAnnouncement(content.Content)
+	if !ok {
+		transformed, err := p.TransformContent(ctx, content)
+		if err != nil {
+			return failed(err), nil
+		}
+		if a, ok = decodeAnnouncement(transformed.Content); !ok {
+			return failed(fmt.Errorf("content is not a transformed announcement")), nil
+		}
+	}
+
+	defer publisher.TimeStage(ctx, models.StagePublish)()
+	var refs []string
+	var errs []error
+	messageURL := ""
+	for _, target := range p.channel.targets() {
+		ref, url, err := p.channel.send(ctx, target, a)
+		if err != nil {
+			errs = append(errs, err)
+			publisher.Logger(ctx, p.logger).Warn("Failed to send announcement",
+				zap.String("platform", p.platformName),
+				zap.String("target", redactTarget(target)),
+				zap.Error(err))
+			continue
+		}
+		refs = append(refs, ref)
+		if messageURL == "" {
+			messageURL = url
+		}
+	}
+	if len(refs) == 0 {
+		if len(errs) == 0 {
+			return failed(fmt.Errorf("no %s targets configured", p.platformName)), nil
+		}
+		return failed(errors.Join(errs...)), nil
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Announcement sent",
+		zap.String("platform", p.platformName),
+		zap.String("title", a.Title),
+		zap.String("link", a.Link),
+		zap.Int("sent", len(refs)),
+		zap.Int("failed", len(errs)))
+
+	return &publisher.PublishResult{
+		Success:     true,
+		PublishID:   strings.Join(refs, ","),
+		URL:         messageURL,
+		PublishedAt: time.Now(),
+		Metadata: map[string]string{
+			"link":   a.Link,
+			"sent":   strconv.Itoa(len(refs)),
+			"failed": strconv.Itoa(len(errs)),
+		},
+	}, nil
+}
+
+// GetPublishStatus reports the announcement as published, messages are sent right away
+func (p *AnnouncePublisher) GetPublishStatus(ctx context.Context, publishID string, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	return &publisher.PublishResult{
+		Success:   true,
+		PublishID: publishID,
+		Metadata:  map[string]string{"status": "published"},
+	}, nil
+}
+
+// Unpublish deletes the messages of an announcement
+func (p *AnnouncePublisher) Unpublish(ctx context.Context, publishID string, config publisher.PublishConfig) error {
+	refs := strings.Split(publishID, ",")
+	for _, ref := range refs {
+		if err := p.channel.deleteMessage(ctx, ref); err != nil {
+			return fmt.Errorf("failed to delete message %s: %w", ref, err)
+		}
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Announcement deleted",
+		zap.String("platform", p.platformName),
+		zap.Int("messages", len(refs)))
+	return nil
+}
+
+// Cleanup has nothing to remove, announcements keep no files
+func (p *AnnouncePublisher) Cleanup(ctx context.Context, publishID string, config publisher.PublishConfig) error {
+	return nil
+}
+
+// redactTarget keeps webhook tokens out of the logs
+func redactTarget(target string) string {
+	if i := strings.LastIndex(target, "/"); i >= 0 && strings.HasPrefix(target, "http") {
+		return target[:i+1] + "***"
+	}
+	return target
+}
+
+func failed(err error) *publisher.PublishResult {
+	return &publisher.PublishResult{
+		Success:  false,
+		Error:    err,
+		ErrorMsg: err.Error(),
+	}
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, errNotFound)
+}
+
+// splitList splits a comma separated config value, dropping empty entries
+func splitList(value string) []string {
+	var entries []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,241 @@
+package announce
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+const (
+	slackAPIURL = "https://slack.com/api"
+	// Block Kit limits
+	slackMaxHeader  = 150
+	slackMaxSection = 3000
+	slackMaxAltText = 2000
+	// defaultSlackButton is the label of the button opening the post
+	defaultSlackButton = "Read the post"
+)
+
+// slackAuthErrors are the Web API errors of a revoked or invalid bot token
+var slackAuthErrors = map[string]bool{
+	"not_authed":       true,
+	"invalid_auth":     true,
+	"account_inactive": true,
+	"token_revoked":    true,
+	"token_expired":    true,
+}
+
+// slack posts announcements as Block Kit messages with the Web API, authenticated by the bot
+// token of a Slack app with the chat:write scope; the bot must be a member of the channels
+type slack struct {
+	client     *http.Client
+	apiURL     string
+	token      string
+	channels   []string
+	buttonText string
+}
+
+type slackResponse struct {
+	OK      bool   `json:"ok"`
+	Error   string `json:"error"`
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+	// Permalink is returned by chat.getPermalink
+	Permalink string `json:"permalink"`
+}
+
+func (s *slack) validate(config publisher.PublishConfig) error {
+	if config.Config["bot_token"] == "" {
+		return fmt.Errorf("missing required config: bot_token")
+	}
+	if len(splitList(config.Config["channels"])) == 0 {
+		return fmt.Errorf("missing required config: channels")
+	}
+	return nil
+}
+
+func (s *slack) initialize(client *http.Client, config publisher.PublishConfig) error {
+	s.client = client
+	s.apiURL = slackAPIURL
+	s.token = config.Config["bot_token"]
+	s.channels = splitList(config.Config["channels"])
+	s.buttonText = config.Config["button_text"]
+	if s.buttonText == "" {
+		s.buttonText = defaultSlackButton
+	}
+	return nil
+}
+
+func (s *slack) checkCredentials(ctx context.Context) error {
+	return s.call(ctx, "auth.test", map[string]any{}, nil)
+}
+
+func (s *slack) targets() []string {
+	return s.channels
+}
+
+// send posts the card to a channel. The reference is the channel and timestamp of the message,
+// which is how Slack identifies it.
+func (s *slack) send(ctx context.Context, target string, a *announcement) (string, string, error) {
+	title := truncateRunes(a.Title, slackMaxHeader)
+	blocks := []map[string]any{{
+		"type": "header",
+		"text": map[string]any{"type": "plain_text", "text": title},
+	}}
+	if a.Summary != "" {
+		blocks = append(blocks, map[string]any{
+			"type": "section",
+			"text": map[string]any{"type": "mrkdwn", "text": truncateRunes(escapeMrkdwn(a.Summary), slackMaxSection)},
+		})
+	}
+	if a.Image != "" {
+		blocks = append(blocks, map[string]any{
+			"type":      "image",
+			"image_url": a.Image,
+			"alt_text":  truncateRunes(a.Title, slackMaxAltText),
+		})
+	}
+	var details []string
+	if a.Author != "" {
+		details = append(details, escapeMrkdwn(a.Author))
+	}
+	if len(a.Tags) > 0 {
+		details = append(details, escapeMrkdwn(strings.Join(a.Tags, " · ")))
+	}
+	if len(details) > 0 {
+		blocks = append(blocks, map[string]any{
+			"type":     "context",
+			"elements": []map[string]any{{"type": "mrkdwn", "text": strings.Join(details, " | ")}},
+		})
+	}
+	if a.Link != "" {
+		blocks = append(blocks, map[string]any{
+			"type": "actions",
+			"elements": []map[string]any{{
+				"type": "button",
+				"text": map[string]any{"type": "plain_text", "text": s.buttonText},
+				"url":  a.Link,
+			}},
+		})
+	}
+
+	// The text is shown in notifications and where blocks can't be
+	text := a.Title
+	if a.Link != "" {
+		text += " " + a.Link
+	}
+	request := map[string]any{
+		"channel":      target,
+		"text":         text,
+		"blocks":       blocks,
+		"unfurl_links": false,
+		"unfurl_media": false,
+	}
+
+	var response slackResponse
+	if err := s.call(ctx, "chat.postMessage", request, &response); err != nil {
+		return "", "", fmt.Errorf("failed to post to %s: %w", target, err)
+	}
+	// The message is posted whether or not its permalink can be looked up
+	permalink, _ := s.permalink(ctx, response.Channel, response.TS)
+	return response.Channel + "/" + response.TS, permalink, nil
+}
+
+// permalink looks up the URL of a message. chat.getPermalink is a read method, which takes
+// form arguments rather than JSON.
+func (s *slack) permalink(ctx context.Context, channel, ts string) (string, error) {
+	form := url.Values{}
+	form.Set("channel", channel)
+	form.Set("message_ts", ts)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.apiURL+"/chat.getPermalink", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var response slackResponse
+	if err := s.do(req, "chat.getPermalink", &response); err != nil {
+		return "", err
+	}
+	return response.Permalink, nil
+}
+
+func (s *slack) deleteMessage(ctx context.Context, ref string) error {
+	channel, ts, ok := strings.Cut(ref, "/")
+	if !ok || ts == "" {
+		return fmt.Errorf("invalid Slack message reference %q", ref)
+	}
+
+	err := s.call(ctx, "chat.delete", map[string]any{"channel": channel, "ts": ts}, nil)
+	if isNotFound(err) {
+		// Already deleted
+		return nil
+	}
+	return err
+}
+
+// call calls a Web API method with a JSON body. Slack answers errors with "ok": false and an
+// error code, mostly with status 200.
+func (s *slack) call(ctx context.Context, method string, request any, out *slackResponse) error {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.apiURL+"/"+method, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	return s.do(req, method, out)
+}
+
+// do sends a request with the bot token and reads the response of the method
+func (s *slack) do(req *http.Request, method string, out *slackResponse) error {
+	req.Header.Set("Authorization", "Bearer "+s.token)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call Slack: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return publisher.NewError(publisher.CodeForStatus(resp.StatusCode), fmt.Errorf("Slack API returned status %d: %s", resp.StatusCode, string(body)))
+	}
+
+	var response slackResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if !response.OK {
+		switch {
+		case slackAuthErrors[response.Error]:
+			return fmt.Errorf("%w: Slack rejected the bot token: %s", publisher.ErrCredentialsExpired, response.Error)
+		case response.Error == "message_not_found":
+			return publisher.NewError(publisher.ErrorCodeContentInvalid, fmt.Errorf("%w: %s", errNotFound, response.Error))
+		}
+		return fmt.Errorf("Slack API %s failed: %s", method, response.Error)
+	}
+
+	if out != nil {
+		*out = response
+	}
+	return nil
+}
+
+// escapeMrkdwn escapes the characters Slack reads as markup in mrkdwn text
+func escapeMrkdwn(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
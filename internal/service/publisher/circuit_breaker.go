@@ -0,0 +1,202 @@
+package publisher
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitState represents the state of a platform circuit
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// CircuitStatus is a snapshot of a platform circuit
+type CircuitStatus struct {
+	Platform  string       `json:"platform"`
+	State     CircuitState `json:"state"`
+	Failures  int          `json:"failures"`
+	LastError string       `json:"last_error,omitempty"`
+	OpenedAt  *time.Time   `json:"opened_at,omitempty"`
+	RetryAt   *time.Time   `json:"retry_at,omitempty"`
+}
+
+// CircuitStateChangeFunc is called whenever a platform circuit changes state
+type CircuitStateChangeFunc func(platform string, from, to CircuitState, lastError string)
+
+type circuit struct {
+	state     CircuitState
+	failures  int
+	lastError string
+	openedAt  time.Time
+	probing   bool
+}
+
+// CircuitBreaker stops publishing to a platform after consecutive failures and
+// lets a single probe through once the cool-down has passed
+type CircuitBreaker struct {
+	mu               sync.Mutex
+	failureThreshold int
+	coolDown         time.Duration
+	circuits         map[string]*circuit
+	onStateChange    CircuitStateChangeFunc
+}
+
+// NewCircuitBreaker creates a circuit breaker; a threshold of 0 disables it
+func NewCircuitBreaker(failureThreshold int, coolDown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		coolDown:         coolDown,
+		circuits:         make(map[string]*circuit),
+	}
+}
+
+// OnStateChange registers a callback for circuit state transitions
+func (b *CircuitBreaker) OnStateChange(fn CircuitStateChangeFunc) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onStateChange = fn
+}
+
+// Allow reports whether a publish attempt to the platform may proceed
+func (b *CircuitBreaker) Allow(platform string) error {
+	if b.failureThreshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	c := b.getCircuit(platform)
+
+	switch c.state {
+	case CircuitOpen:
+		retryAt := c.openedAt.Add(b.coolDown)
+		if time.Now().Before(retryAt) {
+			b.mu.Unlock()
+			return fmt.Errorf("circuit open for platform %s until %s: %s", platform, retryAt.Format(time.RFC3339), c.lastError)
+		}
+		// Cool-down elapsed, let one probe through
+		c.probing = true
+		notify := b.transition(platform, c, CircuitHalfOpen)
+		b.mu.Unlock()
+		notify()
+		return nil
+	case CircuitHalfOpen:
+		if c.probing {
+			b.mu.Unlock()
+			return fmt.Errorf("circuit half-open for platform %s, probe in progress", platform)
+		}
+		c.probing = true
+	}
+
+	b.mu.Unlock()
+	return nil
+}
+
+// RecordSuccess closes the platform circuit and resets its failure count
+func (b *CircuitBreaker) RecordSuccess(platform string) {
+	if b.failureThreshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	c := b.getCircuit(platform)
+	c.failures = 0
+	c.lastError = ""
+	c.probing = false
+	notify := b.transition(platform, c, CircuitClosed)
+	b.mu.Unlock()
+	notify()
+}
+
+// RecordFailure counts a failure and opens the circuit once the threshold is reached
+func (b *CircuitBreaker) RecordFailure(platform, errMsg string) {
+	if b.failureThreshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	c := b.getCircuit(platform)
+	c.failures++
+	c.lastError = errMsg
+	c.probing = false
+
+	notify := func() {}
+	if c.state == CircuitHalfOpen || c.failures >= b.failureThreshold {
+		// A failed probe reopens the circuit for another cool-down
+		c.openedAt = time.Now()
+		notify = b.transition(platform, c, CircuitOpen)
+	}
+	b.mu.Unlock()
+	notify()
+}
+
+// Release ends a probe let through by Allow without recording a result, for publishes stopped
+// before they reached the platform
+func (b *CircuitBreaker) Release(platform string) {
+	if b.failureThreshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.getCircuit(platform).probing = false
+}
+
+// Reset closes the platform circuit manually
+func (b *CircuitBreaker) Reset(platform string) {
+	b.RecordSuccess(platform)
+}
+
+// Statuses returns a snapshot of all known platform circuits
+func (b *CircuitBreaker) Statuses() []CircuitStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	statuses := make([]CircuitStatus, 0, len(b.circuits))
+	for platform, c := range b.circuits {
+		status := CircuitStatus{
+			Platform:  platform,
+			State:     c.state,
+			Failures:  c.failures,
+			LastError: c.lastError,
+		}
+		if c.state != CircuitClosed {
+			openedAt := c.openedAt
+			retryAt := c.openedAt.Add(b.coolDown)
+			status.OpenedAt = &openedAt
+			status.RetryAt = &retryAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+func (b *CircuitBreaker) getCircuit(platform string) *circuit {
+	c, exists := b.circuits[platform]
+	if !exists {
+		c = &circuit{state: CircuitClosed}
+		b.circuits[platform] = c
+	}
+	return c
+}
+
+// transition updates the state and returns the notification to run after unlocking
+func (b *CircuitBreaker) transition(platform string, c *circuit, to CircuitState) func() {
+	from := c.state
+	if from == to {
+		return func() {}
+	}
+	c.state = to
+
+	fn := b.onStateChange
+	lastError := c.lastError
+	return func() {
+		if fn != nil {
+			fn(platform, from, to, lastError)
+		}
+	}
+}
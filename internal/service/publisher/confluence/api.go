@@ -0,0 +1,292 @@
+package confluence
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// Page statuses of the content API
+const (
+	statusCurrent = "current"
+	statusDraft   = "draft"
+)
+
+// errNotFound is wrapped when a page does not exist (anymore)
+var errNotFound = errors.New("not found")
+
+// apiClient calls the Confluence Cloud REST API, authenticated with the email of an Atlassian
+// account and an API token
+type apiClient struct {
+	client   *http.Client
+	baseURL  string
+	email    string
+	apiToken string
+}
+
+type confluencePage struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Title   string `json:"title"`
+	Version struct {
+		Number int `json:"number"`
+	} `json:"version"`
+	Body struct {
+		Storage struct {
+			Value string `json:"value"`
+		} `json:"storage"`
+	} `json:"body"`
+	Links struct {
+		Base  string `json:"base"`
+		WebUI string `json:"webui"`
+	} `json:"_links"`
+}
+
+// pageRequest is the body creating or updating a page
+type pageRequest struct {
+	ID        string         `json:"id,omitempty"`
+	Type      string         `json:"type"`
+	Status    string         `json:"status"`
+	Title     string         `json:"title"`
+	Space     *spaceRef      `json:"space,omitempty"`
+	Ancestors []ancestorRef  `json:"ancestors,omitempty"`
+	Version   *versionRef    `json:"version,omitempty"`
+	Body      map[string]any `json:"body"`
+	// Metadata sets the labels of a new page; labels of existing pages are added separately
+	Metadata *pageMetadata `json:"metadata,omitempty"`
+}
+
+type pageMetadata struct {
+	Labels []labelRef `json:"labels"`
+}
+
+type labelRef struct {
+	Prefix string `json:"prefix"`
+	Name   string `json:"name"`
+}
+
+type spaceRef struct {
+	Key string `json:"key"`
+}
+
+type ancestorRef struct {
+	ID string `json:"id"`
+}
+
+type versionRef struct {
+	Number  int    `json:"number"`
+	Message string `json:"message,omitempty"`
+}
+
+func labelRefs(labels []string) []labelRef {
+	refs := make([]labelRef, len(labels))
+	for i, label := range labels {
+		refs[i] = labelRef{Prefix: "global", Name: label}
+	}
+	return refs
+}
+
+func newPageRequest(title, body, status, spaceKey, parentID string) *pageRequest {
+	request := &pageRequest{
+		Type:   "page",
+		Status: status,
+		Title:  title,
+		Space:  &spaceRef{Key: spaceKey},
+		Body: map[string]any{
+			"storage": map[string]string{"value": body, "representation": "storage"},
+		},
+	}
+	if parentID != "" {
+		request.Ancestors = []ancestorRef{{ID: parentID}}
+	}
+	return request
+}
+
+func (c *apiClient) getSpace(ctx context.Context, spaceKey string) error {
+	return c.call(ctx, http.MethodGet, "/rest/api/space/"+url.PathEscape(spaceKey), nil, "", nil)
+}
+
+// findPage finds the page carrying a label in a space, or returns nil. Drafts are not
+// searchable, only published pages are found.
+func (c *apiClient) findPage(ctx context.Context, spaceKey, label string) (*confluencePage, error) {
+	query := url.Values{}
+	query.Set("cql", fmt.Sprintf(`space = "%s" and type = page and label = "%s"`, spaceKey, label))
+	query.Set("expand", "version")
+	var result struct {
+		Results []confluencePage `json:"results"`
+	}
+	if err := c.call(ctx, http.MethodGet, "/rest/api/content/search?"+query.Encode(), nil, "", &result); err != nil {
+		return nil, err
+	}
+	if len(result.Results) == 0 {
+		return nil, nil
+	}
+	return &result.Results[0], nil
+}
+
+// getPage fetches a page with its body; status is draft for unpublished pages
+func (c *apiClient) getPage(ctx context.Context, id, status string) (*confluencePage, error) {
+	query := url.Values{}
+	query.Set("expand", "version,body.storage")
+	if status != "" {
+		query.Set("status", status)
+	}
+	var page confluencePage
+	if err := c.call(ctx, http.MethodGet, "/rest/api/content/"+url.PathEscape(id)+"?"+query.Encode(), nil, "", &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func (c *apiClient) createPage(ctx context.Context, request *pageRequest) (*confluencePage, error) {
+	var page confluencePage
+	if err := c.sendJSON(ctx, http.MethodPost, "/rest/api/content", request, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// updatePage saves a new version of a page; updating a draft keeps it a draft, and publishing
+// one sets the status to current with version 1
+func (c *apiClient) updatePage(ctx context.Context, id, currentStatus string, request *pageRequest) (*confluencePage, error) {
+	request.ID = id
+	request.Space = nil
+	request.Metadata = nil
+	path := "/rest/api/content/" + url.PathEscape(id)
+	if currentStatus == statusDraft {
+		path += "?status=draft"
+	}
+	var page confluencePage
+	if err := c.sendJSON(ctx, http.MethodPut, path, request, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// deletePage moves a page to the trash of the space, or discards a draft
+func (c *apiClient) deletePage(ctx context.Context, id, status string) error {
+	path := "/rest/api/content/" + url.PathEscape(id)
+	if status != "" {
+		path += "?status=" + url.QueryEscape(status)
+	}
+	return c.call(ctx, http.MethodDelete, path, nil, "", nil)
+}
+
+// addLabels adds labels to a page; labels it already has are kept
+func (c *apiClient) addLabels(ctx context.Context, id string, labels []string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+	return c.sendJSON(ctx, http.MethodPost, "/rest/api/content/"+url.PathEscape(id)+"/label", labelRefs(labels), nil)
+}
+
+// uploadAttachment adds a file to a page, or replaces the attachment with the same name as a
+// new version of it
+func (c *apiClient) uploadAttachment(ctx context.Context, pageID, status, filename string, data []byte, mimeType string) error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, filename))
+	header.Set("Content-Type", mimeType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(data); err != nil {
+		return err
+	}
+	if err := writer.WriteField("minorEdit", "true"); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	path := "/rest/api/content/" + url.PathEscape(pageID) + "/child/attachment"
+	if status == statusDraft {
+		path += "?status=draft"
+	}
+	return c.call(ctx, http.MethodPut, path, &body, writer.FormDataContentType(), nil)
+}
+
+func (c *apiClient) sendJSON(ctx context.Context, method, path string, request, out any) error {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return c.call(ctx, method, path, bytes.NewReader(data), "application/json", out)
+}
+
+// call sends an authenticated request and decodes the JSON response into out
+func (c *apiClient) call(ctx context.Context, method, path string, body io.Reader, contentType string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(c.email, c.apiToken)
+	req.Header.Set("Accept", "application/json")
+	// Attachment uploads are refused without it, as protection against cross-site requests
+	req.Header.Set("X-Atlassian-Token", "no-check")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call Confluence: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		message := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			message = apiErr.Message
+		}
+		switch resp.StatusCode {
+		case http.StatusUnauthorized:
+			return fmt.Errorf("%w: Confluence rejected the API token: %s", publisher.ErrCredentialsExpired, message)
+		case http.StatusNotFound:
+			return publisher.NewError(publisher.ErrorCodeContentInvalid, fmt.Errorf("%w: %s", errNotFound, message))
+		}
+		return publisher.NewError(publisher.CodeForStatus(resp.StatusCode), fmt.Errorf("Confluence API returned status %d: %s", resp.StatusCode, message))
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// pageURL is the address of a page in the Confluence web UI
+func (c *apiClient) pageURL(page *confluencePage) string {
+	if page.Links.WebUI == "" {
+		return ""
+	}
+	base := page.Links.Base
+	if base == "" {
+		base = c.baseURL
+	}
+	return base + page.Links.WebUI
+}
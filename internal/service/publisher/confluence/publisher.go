@@ -0,0 +1,472 @@
+package confluence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/httpclient"
+)
+
+// PlatformName is the name pages are published to Confluence under
+const PlatformName = "confluence"
+
+var (
+	spaceKeyPattern = regexp.MustCompile(`^~?[A-Za-z0-9]+$`)
+	pageIDPattern   = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// ConfluencePublisher creates and updates pages in a Confluence Cloud space in the storage
+// format, uploading images as attachments of the page. The page of a Notion page is found again
+// by a label carrying its ID, so publishing it again updates the page, even after a new title.
+type ConfluencePublisher struct {
+	logger   *zap.Logger
+	client   *http.Client
+	api      *apiClient
+	spaceKey string
+	parentID string
+}
+
+// NewConfluencePublisher creates a publisher for a Confluence Cloud site
+func NewConfluencePublisher(logger *zap.Logger) publisher.Publisher {
+	return &ConfluencePublisher{
+		logger: logger,
+		client: httpclient.New(),
+	}
+}
+
+func (p *ConfluencePublisher) GetPlatformName() string {
+	return PlatformName
+}
+
+func (p *ConfluencePublisher) Initialize(ctx context.Context, config publisher.PublishConfig) error {
+	if err := p.ValidateConfig(config); err != nil {
+		return err
+	}
+
+	var opts []httpclient.Option
+	if proxyURL := config.Config["proxy_url"]; proxyURL != "" {
+		parsed, err := httpclient.ParseProxy(proxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy_url: %w", err)
+		}
+		opts = append(opts, httpclient.WithProxy(parsed))
+	}
+	p.client = httpclient.New(opts...)
+
+	p.api = &apiClient{
+		client:   p.client,
+		baseURL:  strings.TrimRight(config.Config["base_url"], "/"),
+		email:    config.Config["email"],
+		apiToken: config.Config["api_token"],
+	}
+	p.spaceKey = config.Config["space_key"]
+	p.parentID = config.Config["parent_page_id"]
+
+	publisher.Logger(ctx, p.logger).Info("Confluence publisher initialized successfully",
+		zap.String("base_url", p.api.baseURL),
+		zap.String("space_key", p.spaceKey),
+		zap.String("parent_page_id", p.parentID))
+	return nil
+}
+
+func (p *ConfluencePublisher) ValidateConfig(config publisher.PublishConfig) error {
+	for _, key := range []string{"base_url", "email", "api_token", "space_key"} {
+		if config.Config[key] == "" {
+			return fmt.Errorf("missing required config: %s", key)
+		}
+	}
+
+	if parsed, err := url.Parse(config.Config["base_url"]); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return fmt.Errorf("invalid base_url %q", config.Config["base_url"])
+	}
+	if spaceKey := config.Config["space_key"]; !spaceKeyPattern.MatchString(spaceKey) {
+		return fmt.Errorf("invalid space_key %q", spaceKey)
+	}
+	if parentID := config.Config["parent_page_id"]; parentID != "" && !pageIDPattern.MatchString(parentID) {
+		return fmt.Errorf("invalid parent_page_id %q, expected the numeric ID of a page", parentID)
+	}
+	return nil
+}
+
+// VerifyCredentials verifies the API token can read the space, on a publisher of its own so the
+// client publishes use is left alone
+func (p *ConfluencePublisher) VerifyCredentials(ctx context.Context, config publisher.PublishConfig) error {
+	probe := NewConfluencePublisher(p.logger).(*ConfluencePublisher)
+	if err := probe.Initialize(ctx, config); err != nil {
+		return err
+	}
+	if err := probe.api.getSpace(ctx, probe.spaceKey); err != nil {
+		if errors.Is(err, errNotFound) {
+			return fmt.Errorf("space %s not found or not visible to the account: %w", probe.spaceKey, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// TransformContent renders the page in the storage format; the images become resources,
+// uploaded as attachments once the page exists
+func (p *ConfluencePublisher) TransformContent(ctx context.Context, content publisher.PublishContent) (*publisher.PublishContent, error) {
+	defer publisher.TimeStage(ctx, models.StageTransform)()
+	doc, err := content.ContentDocument()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse content: %w", err)
+	}
+
+	body, resources := renderStorage(doc)
+
+	// The document stays set, so transforming the result again renders the page, not the body
+	result := content
+	result.Document = doc
+	result.Content = body
+	result.Resources = resources
+	result.Metadata = make(map[string]string)
+	for k, v := range content.Metadata {
+		result.Metadata[k] = v
+	}
+	return &result, nil
+}
+
+// ProcessResources leaves the images where they are; attachments belong to a page, so they are
+// uploaded when the page is saved
+func (p *ConfluencePublisher) ProcessResources(ctx context.Context, content *publisher.PublishContent, config publisher.PublishConfig) error {
+	return nil
+}
+
+// SaveToDraft creates the page as an unpublished draft. A page that is already published is
+// only updated by publishing, so its readers never see a half-reviewed version.
+func (p *ConfluencePublisher) SaveToDraft(ctx context.Context, content publisher.PublishContent, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	transformed, err := p.TransformContent(ctx, content)
+	if err != nil {
+		return failed(err), nil
+	}
+
+	existing, err := p.api.findPage(ctx, p.spaceKey, trackingLabel(content.ID))
+	if err != nil {
+		return failed(fmt.Errorf("failed to find the page: %w", err)), nil
+	}
+	if existing != nil {
+		return failed(publisher.NewError(publisher.ErrorCodeContentInvalid,
+			fmt.Errorf("the page is already published as Confluence page %s, publish it to update it", existing.ID))), nil
+	}
+
+	page, attachments, err := p.createPage(ctx, transformed, statusDraft)
+	if err != nil {
+		return failed(err), nil
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Confluence draft created",
+		zap.String("page_id", page.ID),
+		zap.Int("attachments", attachments))
+	return p.result(page, statusDraft, attachments), nil
+}
+
+// Publish publishes a draft created by SaveToDraft
+func (p *ConfluencePublisher) Publish(ctx context.Context, draftID string, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	draft, err := p.api.getPage(ctx, draftID, statusDraft)
+	if err != nil {
+		return failed(fmt.Errorf("failed to get draft %s: %w", draftID, err)), nil
+	}
+
+	defer publisher.TimeStage(ctx, models.StagePublish)()
+	request := newPageRequest(draft.Title, draft.Body.Storage.Value, statusCurrent, p.spaceKey, p.parentID)
+	request.Version = &versionRef{Number: 1}
+	page, err := p.api.updatePage(ctx, draftID, statusDraft, request)
+	if err != nil {
+		return failed(fmt.Errorf("failed to publish draft %s: %w", draftID, err)), nil
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Confluence draft published",
+		zap.String("page_id", page.ID),
+		zap.String("title", page.Title))
+	// The attachments were uploaded with the draft
+	return p.result(page, statusCurrent, -1), nil
+}
+
+// PublishDirect creates the page, or updates the page published for it before. Without auto
+// publish the page is only saved as a draft.
+func (p *ConfluencePublisher) PublishDirect(ctx context.Context, content publisher.PublishContent, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	if config.Config["auto_publish"] != "true" {
+		return p.SaveToDraft(ctx, content, config)
+	}
+
+	transformed, err := p.TransformContent(ctx, content)
+	if err != nil {
+		return failed(err), nil
+	}
+
+	existing, err := p.api.findPage(ctx, p.spaceKey, trackingLabel(content.ID))
+	if err != nil {
+		return failed(fmt.Errorf("failed to find the page: %w", err)), nil
+	}
+	if existing == nil {
+		page, attachments, err := p.createPage(ctx, transformed, statusCurrent)
+		if err != nil {
+			return failed(err), nil
+		}
+		publisher.Logger(ctx, p.logger).Info("Confluence page created",
+			zap.String("page_id", page.ID),
+			zap.String("url", p.api.pageURL(page)),
+			zap.Int("attachments", attachments))
+		return p.result(page, statusCurrent, attachments), nil
+	}
+
+	// The page exists, so the attachments can go first and the body is saved once
+	body, attachments, err := p.uploadAttachments(ctx, existing.ID, statusCurrent, transformed.Content, transformed.Resources)
+	if err != nil {
+		return failed(err), nil
+	}
+
+	endPublish := publisher.TimeStage(ctx, models.StagePublish)
+	request := newPageRequest(strings.TrimSpace(transformed.Title), body, statusCurrent, p.spaceKey, p.parentID)
+	request.Version = &versionRef{Number: existing.Version.Number + 1, Message: "Updated by Ripple"}
+	page, err := p.api.updatePage(ctx, existing.ID, statusCurrent, request)
+	endPublish()
+	if err != nil {
+		return failed(fmt.Errorf("failed to update page %s: %w", existing.ID, err)), nil
+	}
+	if err := p.api.addLabels(ctx, page.ID, pageLabels(transformed)); err != nil {
+		publisher.Logger(ctx, p.logger).Warn("Failed to label Confluence page",
+			zap.String("page_id", page.ID),
+			zap.Error(err))
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Confluence page updated",
+		zap.String("page_id", page.ID),
+		zap.String("url", p.api.pageURL(page)),
+		zap.Int("version", page.Version.Number),
+		zap.Int("attachments", attachments))
+	return p.result(page, statusCurrent, attachments), nil
+}
+
+// createPage creates the page with its labels, then uploads its attachments. Images that could
+// not be attached are pointed at their URL in a second version of the page.
+func (p *ConfluencePublisher) createPage(ctx context.Context, content *publisher.PublishContent, status string) (*confluencePage, int, error) {
+	endPublish := publisher.TimeStage(ctx, models.StagePublish)
+	request := newPageRequest(strings.TrimSpace(content.Title), content.Content, status, p.spaceKey, p.parentID)
+	request.Metadata = &pageMetadata{Labels: labelRefs(pageLabels(content))}
+	page, err := p.api.createPage(ctx, request)
+	endPublish()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create page: %w", err)
+	}
+
+	body, attachments, err := p.uploadAttachments(ctx, page.ID, status, content.Content, content.Resources)
+	if err != nil {
+		return nil, 0, err
+	}
+	if body == content.Content {
+		return page, attachments, nil
+	}
+
+	request = newPageRequest(strings.TrimSpace(content.Title), body, status, p.spaceKey, p.parentID)
+	request.Version = &versionRef{Number: page.Version.Number + 1}
+	if status == statusDraft {
+		// Drafts have no versions
+		request.Version = &versionRef{Number: 1}
+	}
+	updated, err := p.api.updatePage(ctx, page.ID, status, request)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to link images of page %s: %w", page.ID, err)
+	}
+	return updated, attachments, nil
+}
+
+// uploadAttachments uploads the images of a page as attachments. Images that can't be
+// downloaded or that Confluence refuses are linked by URL in the returned body instead of
+// failing the page.
+func (p *ConfluencePublisher) uploadAttachments(ctx context.Context, pageID, status, body string, resources []publisher.Resource) (string, int, error) {
+	defer publisher.TimeStage(ctx, models.StageUpload)()
+	uploaded := 0
+	for _, resource := range resources {
+		filename := resource.Metadata["filename"]
+		if resource.Type != publisher.ResourceTypeImage || filename == "" {
+			continue
+		}
+
+		data, mimeType, err := p.downloadImage(ctx, resource.URL)
+		if err == nil {
+			err = p.api.uploadAttachment(ctx, pageID, status, filename, data, mimeType)
+		}
+		if errors.Is(err, publisher.ErrCredentialsExpired) {
+			return "", 0, err
+		}
+		if err != nil {
+			publisher.Logger(ctx, p.logger).Warn("Linking image Confluence could not attach",
+				zap.String("page_id", pageID),
+				zap.String("url", resource.URL),
+				zap.Error(err))
+			body = replaceAttachment(body, filename, resource.URL)
+			continue
+		}
+		uploaded++
+	}
+	return body, uploaded, nil
+}
+
+// downloadImage fetches an image, or reads it when it is a local file such as a generated
+// cover card, and detects its type from its bytes
+func (p *ConfluencePublisher) downloadImage(ctx context.Context, imageURL string) ([]byte, string, error) {
+	var data []byte
+	if strings.HasPrefix(imageURL, "http://") || strings.HasPrefix(imageURL, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create request: %w", err)
+		}
+
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to download image: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, "", fmt.Errorf("failed to download image, status: %d", resp.StatusCode)
+		}
+
+		if data, err = io.ReadAll(resp.Body); err != nil {
+			return nil, "", fmt.Errorf("failed to read image data: %w", err)
+		}
+	} else {
+		var err error
+		if data, err = os.ReadFile(imageURL); err != nil {
+			return nil, "", fmt.Errorf("failed to read image: %w", err)
+		}
+	}
+
+	mimeType := http.DetectContentType(data)
+	if !strings.HasPrefix(mimeType, "image/") {
+		// SVG is text to content sniffing
+		if !strings.HasSuffix(strings.ToLower(strings.Split(imageURL, "?")[0]), ".svg") {
+			return nil, "", fmt.Errorf("not an image: %s", mimeType)
+		}
+		mimeType = "image/svg+xml"
+	}
+	return data, mimeType, nil
+}
+
+// GetPublishStatus reports whether the page is published, still a draft or deleted
+func (p *ConfluencePublisher) GetPublishStatus(ctx context.Context, publishID string, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	status := "deleted"
+	page, err := p.api.getPage(ctx, publishID, "")
+	if errors.Is(err, errNotFound) {
+		page, err = p.api.getPage(ctx, publishID, statusDraft)
+	}
+	switch {
+	case err == nil && page.Status == statusCurrent:
+		status = "published"
+	case err == nil && page.Status == statusDraft:
+		status = "draft"
+	case err != nil && !errors.Is(err, errNotFound):
+		return nil, fmt.Errorf("failed to get page %s: %w", publishID, err)
+	}
+
+	result := &publisher.PublishResult{
+		Success:   true,
+		PublishID: publishID,
+		Metadata:  map[string]string{"status": status},
+	}
+	if page != nil {
+		result.URL = p.api.pageURL(page)
+	}
+	return result, nil
+}
+
+// Unpublish moves the page to the trash of the space, where it can be restored, or discards
+// the draft
+func (p *ConfluencePublisher) Unpublish(ctx context.Context, publishID string, config publisher.PublishConfig) error {
+	err := p.api.deletePage(ctx, publishID, "")
+	if errors.Is(err, errNotFound) {
+		err = p.api.deletePage(ctx, publishID, statusDraft)
+	}
+	if err != nil && !errors.Is(err, errNotFound) {
+		return fmt.Errorf("failed to delete page %s: %w", publishID, err)
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Confluence page deleted", zap.String("page_id", publishID))
+	return nil
+}
+
+// Cleanup has nothing to remove, pages and their attachments live in Confluence
+func (p *ConfluencePublisher) Cleanup(ctx context.Context, publishID string, config publisher.PublishConfig) error {
+	return nil
+}
+
+func (p *ConfluencePublisher) result(page *confluencePage, status string, attachments int) *publisher.PublishResult {
+	result := &publisher.PublishResult{
+		Success:   true,
+		PublishID: page.ID,
+		URL:       p.api.pageURL(page),
+		Metadata: map[string]string{
+			"page_id":   page.ID,
+			"space_key": p.spaceKey,
+			"status":    status,
+			"version":   strconv.Itoa(page.Version.Number),
+		},
+	}
+	if attachments >= 0 {
+		result.Metadata["attachments"] = strconv.Itoa(attachments)
+	}
+	if status == statusCurrent {
+		result.PublishedAt = time.Now()
+	} else {
+		result.Metadata["draft_id"] = page.ID
+	}
+	return result
+}
+
+// trackingLabel is the label finding the Confluence page of a Notion page
+func trackingLabel(pageID string) string {
+	return "ripple-" + labelName(strings.ReplaceAll(pageID, "-", ""))
+}
+
+// pageLabels are the tags of the page as labels, and its tracking label
+func pageLabels(content *publisher.PublishContent) []string {
+	labels := []string{trackingLabel(content.ID)}
+	seen := map[string]bool{labels[0]: true}
+	for _, tag := range content.Tags {
+		if label := labelName(tag); label != "" && !seen[label] {
+			seen[label] = true
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+// labelName turns a tag into a label: lowercase, without spaces and the characters labels
+// can't have
+func labelName(tag string) string {
+	var label strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(tag)) {
+		switch {
+		case unicode.IsSpace(r):
+			label.WriteRune('-')
+		case strings.ContainsRune(`:;,.?&[]()#^*@!'"<>`, r):
+		default:
+			label.WriteRune(r)
+		}
+	}
+	return strings.Trim(label.String(), "-")
+}
+
+func failed(err error) *publisher.PublishResult {
+	return &publisher.PublishResult{
+		Success:  false,
+		Error:    err,
+		ErrorMsg: err.Error(),
+	}
+}
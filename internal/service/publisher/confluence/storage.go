@@ -0,0 +1,265 @@
+package confluence
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/ifuryst/ripple/internal/content"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// renderStorage renders a document in the Confluence storage format, the XHTML pages are saved
+// in. Images are referenced as attachments of the page, returned as the resources to upload.
+func renderStorage(doc *content.Document) (string, []publisher.Resource) {
+	r := &storageRenderer{attachments: make(map[string]bool)}
+	var body strings.Builder
+	for _, block := range doc.Blocks {
+		body.WriteString(r.renderBlock(block))
+	}
+	return body.String(), r.resources
+}
+
+type storageRenderer struct {
+	resources []publisher.Resource
+	// attachments are the file names already added, so a repeated image is uploaded once
+	attachments map[string]bool
+	// tasks numbers the to-do items, whose IDs are unique within the page
+	tasks int
+}
+
+func (r *storageRenderer) renderBlock(block content.Block) string {
+	switch block.Type {
+	case content.BlockHeading:
+		text := renderSpans(block.Text)
+		if text == "" {
+			return ""
+		}
+		level := block.Level
+		if level < 1 || level > 6 {
+			level = 2
+		}
+		return fmt.Sprintf("<h%d>%s</h%d>", level, text, level)
+	case content.BlockQuote:
+		text := renderSpans(block.Text)
+		if text == "" {
+			return ""
+		}
+		return "<blockquote><p>" + text + "</p></blockquote>"
+	case content.BlockList:
+		return r.renderList(block.List)
+	case content.BlockCode:
+		return renderCode(block.Code, block.Language)
+	case content.BlockDivider:
+		return "<hr />"
+	case content.BlockImage:
+		return r.renderImage(block.Image)
+	case content.BlockVideo:
+		return renderVideo(block.Video)
+	case content.BlockFile:
+		return renderFile(block.File)
+	case content.BlockTable:
+		return renderTable(block.Table)
+	default:
+		text := renderSpans(block.Text)
+		if text == "" {
+			return ""
+		}
+		return "<p>" + text + "</p>"
+	}
+}
+
+// renderList renders bullet and numbered lists; to-do items become a task list, which keeps
+// their check boxes
+func (r *storageRenderer) renderList(list *content.List) string {
+	if list == nil || len(list.Items) == 0 {
+		return ""
+	}
+
+	if list.Items[0].Checked != nil {
+		var tasks strings.Builder
+		for _, item := range list.Items {
+			status := "incomplete"
+			if item.Checked != nil && *item.Checked {
+				status = "complete"
+			}
+			r.tasks++
+			fmt.Fprintf(&tasks, "<ac:task><ac:task-id>%d</ac:task-id><ac:task-status>%s</ac:task-status><ac:task-body>%s</ac:task-body></ac:task>",
+				r.tasks, status, renderSpans(item.Text))
+		}
+		return "<ac:task-list>" + tasks.String() + "</ac:task-list>"
+	}
+
+	tag := "ul"
+	if list.Ordered {
+		tag = "ol"
+	}
+	var items strings.Builder
+	for _, item := range list.Items {
+		items.WriteString("<li>" + renderSpans(item.Text) + "</li>")
+	}
+	return "<" + tag + ">" + items.String() + "</" + tag + ">"
+}
+
+// renderCode renders the code macro, which highlights the language itself
+func renderCode(code, language string) string {
+	if code == "" {
+		return ""
+	}
+	var macro strings.Builder
+	macro.WriteString(`<ac:structured-macro ac:name="code">`)
+	if language != "" {
+		macro.WriteString(`<ac:parameter ac:name="language">` + escapeXML(language) + `</ac:parameter>`)
+	}
+	// CDATA can't contain its own end marker, so it is split across two sections
+	macro.WriteString("<ac:plain-text-body><![CDATA[" + strings.ReplaceAll(code, "]]>", "]]]]><![CDATA[>") + "]]></ac:plain-text-body>")
+	macro.WriteString("</ac:structured-macro>")
+	return macro.String()
+}
+
+// renderImage references the image as an attachment of the page, with its caption below
+func (r *storageRenderer) renderImage(image *content.Image) string {
+	if image == nil || image.URL == "" {
+		return ""
+	}
+
+	filename := attachmentFilename(image.URL)
+	caption := content.PlainText(image.Caption)
+	if !r.attachments[filename] {
+		r.attachments[filename] = true
+		r.resources = append(r.resources, publisher.Resource{
+			ID:       fmt.Sprintf("confluence_image_%d", len(r.resources)+1),
+			Type:     publisher.ResourceTypeImage,
+			URL:      image.URL,
+			Metadata: map[string]string{"filename": filename, "alt": caption},
+		})
+	}
+
+	html := fmt.Sprintf(`<ac:image ac:alt="%s"><ri:attachment ri:filename="%s" /></ac:image>`, escapeXML(caption), escapeXML(filename))
+	if text := renderSpans(image.Caption); text != "" {
+		html += "<p><em>" + text + "</em></p>"
+	}
+	return html
+}
+
+// renderVideo embeds videos of video sites with the widget macro; video files become links
+func renderVideo(video *content.Video) string {
+	if video == nil || video.URL == "" {
+		return ""
+	}
+	if !video.IsFile() {
+		html := fmt.Sprintf(`<ac:structured-macro ac:name="widget"><ac:parameter ac:name="url"><ri:url ri:value="%s" /></ac:parameter></ac:structured-macro>`, escapeXML(video.URL))
+		if text := renderSpans(video.Caption); text != "" {
+			html += "<p><em>" + text + "</em></p>"
+		}
+		return html
+	}
+
+	label := renderSpans(video.Caption)
+	if label == "" {
+		label = "Video"
+	}
+	return fmt.Sprintf(`<p>▶ <a href="%s">%s</a></p>`, escapeXML(video.URL), label)
+}
+
+// renderFile links an attachment with its name and kind, and its caption below
+func renderFile(file *content.File) string {
+	if file == nil || file.URL == "" {
+		return ""
+	}
+	html := fmt.Sprintf(`<p>📎 <a href="%s">%s</a> (%s)</p>`, escapeXML(file.URL), escapeXML(file.FileName()), escapeXML(file.Kind()))
+	if text := renderSpans(file.Caption); text != "" {
+		html += "<p><em>" + text + "</em></p>"
+	}
+	return html
+}
+
+func renderTable(table *content.Table) string {
+	if table == nil || len(table.Rows) == 0 {
+		return ""
+	}
+
+	var rows strings.Builder
+	for i, cells := range table.Rows {
+		tag := "td"
+		if i == 0 && table.HasHeader {
+			tag = "th"
+		}
+		rows.WriteString("<tr>")
+		for _, cell := range cells {
+			rows.WriteString("<" + tag + ">" + renderSpans(cell) + "</" + tag + ">")
+		}
+		rows.WriteString("</tr>")
+	}
+	return "<table><tbody>" + rows.String() + "</tbody></table>"
+}
+
+func renderSpans(spans []content.Span) string {
+	var text strings.Builder
+	for _, span := range spans {
+		text.WriteString(renderSpan(span))
+	}
+	return text.String()
+}
+
+func renderSpan(span content.Span) string {
+	if span.Text == "" {
+		return ""
+	}
+
+	text := strings.ReplaceAll(escapeXML(span.Text), "\n", "<br />")
+	if span.Code {
+		text = "<code>" + text + "</code>"
+	}
+	if span.Bold {
+		text = "<strong>" + text + "</strong>"
+	}
+	if span.Italic {
+		text = "<em>" + text + "</em>"
+	}
+	if span.Strikethrough {
+		text = "<s>" + text + "</s>"
+	}
+	if span.Underline {
+		text = "<u>" + text + "</u>"
+	}
+	if span.Link != "" {
+		text = fmt.Sprintf(`<a href="%s">%s</a>`, escapeXML(span.Link), text)
+	}
+	return text
+}
+
+// attachmentFilename names the attachment of an image after its URL without the query, so
+// publishing the page again replaces the attachment even though signed image URLs change
+func attachmentFilename(imageURL string) string {
+	key := imageURL
+	ext := ""
+	if parsed, err := url.Parse(imageURL); err == nil {
+		key = parsed.Scheme + "://" + parsed.Host + parsed.Path
+		ext = strings.ToLower(path.Ext(parsed.Path))
+	}
+	switch ext {
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp", ".svg", ".bmp":
+	default:
+		ext = ""
+	}
+
+	sum := sha1.Sum([]byte(key))
+	return "ripple-" + hex.EncodeToString(sum[:])[:12] + ext
+}
+
+// replaceAttachment points the references to an attachment that could not be uploaded at the
+// image URL instead
+func replaceAttachment(body, filename, imageURL string) string {
+	return strings.ReplaceAll(body,
+		fmt.Sprintf(`<ri:attachment ri:filename="%s" />`, escapeXML(filename)),
+		fmt.Sprintf(`<ri:url ri:value="%s" />`, escapeXML(imageURL)))
+}
+
+// escapeXML escapes text for XHTML content and attribute values
+func escapeXML(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(text)
+}
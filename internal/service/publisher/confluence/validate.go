@@ -0,0 +1,31 @@
+package confluence
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// maxTitleLength is the longest page title Confluence accepts
+const maxTitleLength = 255
+
+// Validate checks the page has a title Confluence accepts
+func (p *ConfluencePublisher) Validate(content publisher.PublishContent, config publisher.PublishConfig) []publisher.Violation {
+	title := strings.TrimSpace(content.Title)
+	switch {
+	case title == "":
+		return []publisher.Violation{{
+			Field:   "title",
+			Rule:    "required",
+			Message: "title is required",
+		}}
+	case len([]rune(title)) > maxTitleLength:
+		return []publisher.Violation{{
+			Field:   "title",
+			Rule:    "max_length",
+			Message: fmt.Sprintf("title is longer than %d characters", maxTitleLength),
+		}}
+	}
+	return nil
+}
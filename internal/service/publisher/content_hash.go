@@ -0,0 +1,40 @@
+package publisher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+)
+
+// signedURLQuery matches the expiring signatures and expiry times Notion attaches to file URLs
+var signedURLQuery = regexp.MustCompile(`\?X-Amz-[^"\s]*|"expiry_time":"[^"]*"`)
+
+// StripURLSignatures removes the parts of Notion file URLs that change on every fetch
+func StripURLSignatures(s string) string {
+	return signedURLQuery.ReplaceAllString(s, "")
+}
+
+// ContentHash fingerprints what a publish of the content puts on a platform. The page status and
+// platforms are left out, they route the page without changing what is published.
+func ContentHash(c *PublishContent) string {
+	metadata := make(map[string]string, len(c.Metadata))
+	for key, value := range c.Metadata {
+		if key != "status" && key != "platforms" {
+			metadata[key] = value
+		}
+	}
+
+	// Map keys are marshaled in sorted order, so equal content always hashes the same
+	data, _ := json.Marshal(struct {
+		Title    string            `json:"title"`
+		Content  string            `json:"content"`
+		Summary  string            `json:"summary"`
+		Tags     []string          `json:"tags"`
+		Author   string            `json:"author"`
+		Metadata map[string]string `json:"metadata"`
+	}{c.Title, c.Content, c.Summary, c.Tags, c.Author, metadata})
+
+	sum := sha256.Sum256([]byte(StripURLSignatures(string(data))))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,53 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrPreviewNotSupported is returned for drafts on platforms whose publisher can't preview them
+	ErrPreviewNotSupported = errors.New("platform does not support draft previews")
+	// ErrNoPreviewRecipient is returned when sending a preview without a recipient given or
+	// configured
+	ErrNoPreviewRecipient = errors.New("no preview recipient given or configured")
+)
+
+// PreviewDraft returns a saved draft for review. With send the draft is also sent as a preview
+// message to the recipient, or to the configured reviewer when it is empty, and who it was sent
+// to is returned.
+func (m *Manager) PreviewDraft(ctx context.Context, platformName, draftID string, send bool, recipient string) (*DraftPreview, string, error) {
+	publisher, err := m.GetPublisher(platformName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	previewer, ok := publisher.(DraftPreviewer)
+	if !ok {
+		return nil, "", fmt.Errorf("%w: %s", ErrPreviewNotSupported, platformName)
+	}
+
+	config, err := m.GetPlatformConfig(platformName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := publisher.Initialize(ctx, config); err != nil {
+		return nil, "", fmt.Errorf("failed to initialize publisher: %w", err)
+	}
+
+	preview, err := previewer.PreviewDraft(ctx, draftID, config)
+	if err != nil {
+		return nil, "", err
+	}
+	if !send {
+		return preview, "", nil
+	}
+
+	sentTo, err := previewer.SendDraftPreview(ctx, draftID, recipient, config)
+	if err != nil {
+		return nil, "", err
+	}
+	return preview, sentTo, nil
+}
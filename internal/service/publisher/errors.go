@@ -0,0 +1,125 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// ErrorCode classifies a publish failure, so callers can tell an expired session apart from
+// a rate limit or a post the platform will never accept
+type ErrorCode string
+
+const (
+	// ErrorCodeAuthExpired means the platform rejected the configured credentials
+	ErrorCodeAuthExpired ErrorCode = "AUTH_EXPIRED"
+	// ErrorCodeRateLimited means the platform asked us to slow down
+	ErrorCodeRateLimited ErrorCode = "RATE_LIMITED"
+	// ErrorCodeContentInvalid means the content itself was rejected and has to be changed
+	ErrorCodeContentInvalid ErrorCode = "CONTENT_INVALID"
+	// ErrorCodeNetwork means the platform could not be reached
+	ErrorCodeNetwork ErrorCode = "NETWORK"
+	// ErrorCodePlatform5xx means the platform failed on its side
+	ErrorCodePlatform5xx ErrorCode = "PLATFORM_5XX"
+)
+
+// Transient reports whether a failure is likely to go away on its own, so retrying later may succeed
+func (c ErrorCode) Transient() bool {
+	switch c {
+	case ErrorCodeRateLimited, ErrorCodeNetwork, ErrorCodePlatform5xx:
+		return true
+	default:
+		return false
+	}
+}
+
+// PublishError is a publish failure classified by an error code
+type PublishError struct {
+	Code ErrorCode
+	Err  error
+}
+
+// NewError classifies an error with a code; a nil error stays nil
+func NewError(code ErrorCode, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PublishError{Code: code, Err: err}
+}
+
+func (e *PublishError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PublishError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes AUTH_EXPIRED errors match ErrCredentialsExpired
+func (e *PublishError) Is(target error) bool {
+	return target == ErrCredentialsExpired && e.Code == ErrorCodeAuthExpired
+}
+
+// CodeForStatus classifies a failed HTTP response from a platform API
+func CodeForStatus(statusCode int) ErrorCode {
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return ErrorCodeAuthExpired
+	case statusCode == http.StatusTooManyRequests:
+		return ErrorCodeRateLimited
+	case statusCode >= 500:
+		return ErrorCodePlatform5xx
+	case statusCode >= 400:
+		return ErrorCodeContentInvalid
+	default:
+		return ""
+	}
+}
+
+// CodeOf returns the code of a publish failure: the code a publisher attached to it, or one
+// derived from well-known errors. Errors that cannot be classified return "".
+func CodeOf(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+
+	var publishErr *PublishError
+	if errors.As(err, &publishErr) {
+		return publishErr.Code
+	}
+
+	var validationErr *ValidationError
+	var panicErr *PanicError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ErrCredentialsExpired):
+		return ErrorCodeAuthExpired
+	case errors.As(err, &validationErr), errors.As(err, &panicErr):
+		// A transformer panicking is almost always content it cannot handle
+		return ErrorCodeContentInvalid
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr):
+		return ErrorCodeNetwork
+	default:
+		return ""
+	}
+}
+
+// resultError returns the error of a failed publish result
+func resultError(result *PublishResult) error {
+	switch {
+	case result.Error != nil:
+		return result.Error
+	case result.ErrorMsg != "":
+		return errors.New(result.ErrorMsg)
+	default:
+		return errors.New("unknown error")
+	}
+}
+
+// classifyResult sets the error code of a failed publish result
+func classifyResult(result *PublishResult) {
+	if result != nil && !result.Success && result.ErrorCode == "" {
+		result.ErrorCode = CodeOf(result.Error)
+	}
+}
@@ -0,0 +1,53 @@
+package publisher
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/ifuryst/ripple/internal/content"
+	"github.com/ifuryst/ripple/pkg/util"
+)
+
+// fileExtPattern matches the extensions kept in attachment file names
+var fileExtPattern = regexp.MustCompile(`^\.[a-z0-9]+$`)
+
+// FileResources returns the attachments of a document as file resources, for publishers that
+// host the files themselves
+func FileResources(doc *content.Document) []Resource {
+	var resources []Resource
+	for _, file := range doc.Files() {
+		resources = append(resources, Resource{
+			ID:   fmt.Sprintf("file_%d", len(resources)+1),
+			Type: ResourceTypeFile,
+			URL:  file.URL,
+			Metadata: map[string]string{
+				"filename":     AttachmentFileName(file),
+				"name":         file.FileName(),
+				"kind":         file.Kind(),
+				"original_url": file.URL,
+			},
+		})
+	}
+	return resources
+}
+
+// AttachmentFileName returns a name safe to store an attachment under: the slug of its name
+// with its extension, e.g. "Q3 Report (final).PDF" becomes "q3-report-final.pdf"
+func AttachmentFileName(file *content.File) string {
+	name := file.FileName()
+	ext := file.Ext()
+	if !fileExtPattern.MatchString(ext) {
+		ext = ""
+	}
+	if ext != "" && strings.EqualFold(path.Ext(name), ext) {
+		name = name[:len(name)-len(ext)]
+	}
+
+	slug := util.GenerateSlug(name)
+	if slug == "" {
+		slug = "file"
+	}
+	return slug + ext
+}
@@ -0,0 +1,128 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/content"
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+// HookStage is the point of the publish pipeline a hook runs at
+type HookStage string
+
+const (
+	// HookPreTransform runs before the content is transformed for the platform; hooks may
+	// modify the content
+	HookPreTransform HookStage = "pre_transform"
+	// HookPrePublish runs right before the content is sent to the platform
+	HookPrePublish HookStage = "pre_publish"
+	// HookPostPublish runs after the platform has answered, whether the publish succeeded or not
+	HookPostPublish HookStage = "post_publish"
+)
+
+// HookEvent describes the publish a hook runs for
+type HookEvent struct {
+	Stage    HookStage
+	Platform string
+	Draft    bool
+	// Content is the platform's own copy; publishers render Document, so hooks changing the
+	// body should modify or replace it
+	Content *PublishContent
+	// Result is only set for post-publish hooks
+	Result *PublishResult
+}
+
+// Hook extends the publish pipeline, e.g. to check links, filter content or send notifications.
+// An error returned before publishing stops the publish; after publishing it is only recorded.
+// The message is recorded on the job either way, so hooks can report warnings without failing.
+type Hook interface {
+	Name() string
+	Stages() []HookStage
+	Run(ctx context.Context, event *HookEvent) (string, error)
+}
+
+// RegisterHook adds a hook to every publish
+func (m *Manager) RegisterHook(hook Hook) {
+	m.hooks = append(m.hooks, hook)
+	m.logger.Info("Publish hook registered", zap.String("hook", hook.Name()))
+}
+
+// runHooks runs the hooks registered for a stage in order, stopping at the first failure
+// before publishing
+func (m *Manager) runHooks(ctx context.Context, event *HookEvent) (models.HookResults, error) {
+	var results models.HookResults
+	for _, hook := range m.hooks {
+		if !hasStage(hook, event.Stage) {
+			continue
+		}
+
+		start := time.Now()
+		message, err := hook.Run(ctx, event)
+		result := models.HookResult{
+			Hook:       hook.Name(),
+			Stage:      string(event.Stage),
+			Passed:     err == nil,
+			Message:    message,
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+		if err != nil {
+			result.Message = err.Error()
+		}
+		results = append(results, result)
+
+		if err == nil {
+			if message != "" {
+				Logger(ctx, m.logger).Info("Publish hook ran",
+					zap.String("hook", hook.Name()),
+					zap.String("stage", string(event.Stage)),
+					zap.String("message", message))
+			}
+			continue
+		}
+
+		Logger(ctx, m.logger).Warn("Publish hook failed",
+			zap.String("hook", hook.Name()),
+			zap.String("stage", string(event.Stage)),
+			zap.String("platform", event.Platform),
+			zap.Error(err))
+		if event.Stage != HookPostPublish {
+			rejected := fmt.Errorf("rejected by hook %s: %w", hook.Name(), err)
+			// Hooks judge the content unless they say otherwise
+			if CodeOf(err) == "" {
+				return results, NewError(ErrorCodeContentInvalid, rejected)
+			}
+			return results, rejected
+		}
+	}
+	return results, nil
+}
+
+// copyContent copies the content so hooks can modify it for one platform without affecting others
+func copyContent(c *PublishContent) *PublishContent {
+	copied := *c
+	copied.Tags = append([]string(nil), c.Tags...)
+	copied.Resources = append([]Resource(nil), c.Resources...)
+	copied.Metadata = make(map[string]string, len(c.Metadata))
+	for key, value := range c.Metadata {
+		copied.Metadata[key] = value
+	}
+	if c.Document != nil {
+		document := *c.Document
+		document.Blocks = append([]content.Block(nil), c.Document.Blocks...)
+		copied.Document = &document
+	}
+	return &copied
+}
+
+func hasStage(hook Hook, stage HookStage) bool {
+	for _, s := range hook.Stages() {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+// ErrListingNotSupported is returned when importing from a platform whose publisher can't list
+// its posts
+var ErrListingNotSupported = errors.New("platform does not support listing existing posts")
+
+// ListExistingPosts returns the posts already on a platform
+func (m *Manager) ListExistingPosts(ctx context.Context, platformName string) ([]ExistingPost, error) {
+	publisher, err := m.GetPublisher(platformName)
+	if err != nil {
+		return nil, err
+	}
+
+	lister, ok := publisher.(PostLister)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrListingNotSupported, platformName)
+	}
+
+	config, err := m.GetPlatformConfig(platformName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := publisher.Initialize(ctx, config); err != nil {
+		return nil, fmt.Errorf("failed to initialize publisher: %w", err)
+	}
+
+	return lister.ListPosts(ctx, config)
+}
+
+// ImportPost records an existing post as the completed job of a page on a platform, so the page
+// isn't published there again. Drafts become draft jobs that can be promoted. It returns false
+// when the page already has an active job there.
+func (m *Manager) ImportPost(page *models.NotionPage, platformName string, post ExistingPost) (*models.DistributionJob, bool, error) {
+	platformID := m.getPlatformID(platformName)
+	if platformID == 0 {
+		return nil, false, fmt.Errorf("failed to get platform ID for %s", platformName)
+	}
+
+	metadata := models.JSONMap{"imported": "true"}
+	if post.URL != "" {
+		metadata["url"] = post.URL
+	}
+	if post.Slug != "" {
+		metadata["slug"] = post.Slug
+	}
+	status := models.JobCompleted
+	if post.Draft {
+		status = models.JobDraft
+		metadata["publish_status"] = "draft"
+	}
+
+	var publishedAt *time.Time
+	liveURL := ""
+	if !post.Draft {
+		liveURL = post.URL
+		now := time.Now()
+		publishedAt = &now
+		if post.PublishedAt != nil {
+			publishedAt = post.PublishedAt
+		}
+	}
+	for key, value := range post.Metadata {
+		metadata[key] = value
+	}
+
+	var job *models.DistributionJob
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		var locked models.NotionPage
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&locked, page.ID).Error; err != nil {
+			return fmt.Errorf("failed to lock page: %w", err)
+		}
+
+		var active models.DistributionJob
+		err := tx.Where("page_id = ? AND platform_id = ? AND status IN ?", page.ID, platformID,
+			[]string{models.JobInProgress, models.JobDraft, models.JobCompleted, models.JobQuarantined}).
+			First(&active).Error
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		job = &models.DistributionJob{
+			PageID:      page.ID,
+			PlatformID:  platformID,
+			PublishID:   post.PublishID,
+			URL:         liveURL,
+			Metadata:    metadata,
+			PublishedAt: publishedAt,
+		}
+		return TransitionJob(tx, job, status, "")
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if job == nil {
+		return nil, false, nil
+	}
+
+	m.logger.Info("Imported existing post",
+		zap.String("platform", platformName),
+		zap.Uint("page_id", page.ID),
+		zap.String("publish_id", post.PublishID),
+		zap.String("url", post.URL))
+	return job, true, nil
+}
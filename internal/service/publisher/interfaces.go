@@ -2,9 +2,14 @@ package publisher
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ifuryst/ripple/internal/content"
 	"github.com/ifuryst/ripple/internal/models"
 )
 
@@ -19,6 +24,29 @@ type PublishContent struct {
 	PublishDate *time.Time        `json:"publish_date"`
 	Metadata    map[string]string `json:"metadata"`
 	Resources   []Resource        `json:"resources"`
+	// Document is the parsed page content the publishers render; Content keeps the source JSON
+	Document *content.Document `json:"-"`
+}
+
+// ContentDocument returns the parsed document, parsing Content when it wasn't set
+func (c PublishContent) ContentDocument() (*content.Document, error) {
+	if c.Document != nil {
+		return c.Document, nil
+	}
+	return content.Parse(c.Content)
+}
+
+// ContentTypeNote marks short-form notes in the Notion "Content type" property
+const ContentTypeNote = "Note"
+
+// IsNote reports whether the content is a short-form note rather than a long-form article
+func (c PublishContent) IsNote() bool {
+	for _, contentType := range strings.Split(c.Metadata["content_type"], ",") {
+		if strings.EqualFold(strings.TrimSpace(contentType), ContentTypeNote) {
+			return true
+		}
+	}
+	return false
 }
 
 // Resource represents a media resource (image, video, etc.)
@@ -46,10 +74,175 @@ type PublishResult struct {
 	URL         string            `json:"url,omitempty"`
 	Error       error             `json:"-"` // Don't serialize error directly
 	ErrorMsg    string            `json:"error,omitempty"` // Serialize error message as string
+	ErrorCode   ErrorCode         `json:"error_code,omitempty"`
 	Metadata    map[string]string `json:"metadata,omitempty"`
+	Violations  []Violation       `json:"violations,omitempty"`
 	PublishedAt time.Time         `json:"published_at"`
 }
 
+// ErrCredentialsExpired is wrapped by publishers when the platform rejects the configured
+// credentials, so callers can tell an expired session apart from other failures
+var ErrCredentialsExpired = errors.New("credentials expired")
+
+// CredentialVerifier is implemented by publishers that can verify their credentials with a
+// lightweight API call instead of waiting for a publish to fail. The credentials are verified
+// from the configuration, without initializing the publisher or touching any state a publish
+// running at the same time uses.
+type CredentialVerifier interface {
+	VerifyCredentials(ctx context.Context, config PublishConfig) error
+}
+
+// Deployment states tracked in a job's "deploy_status" metadata
+const (
+	DeploymentPending = "pending"
+	DeploymentLive    = "live"
+	DeploymentFailed  = "failed"
+)
+
+// DeploymentStatus is the result of checking whether published content is live yet
+type DeploymentStatus struct {
+	State   string
+	URL     string
+	Message string
+	// Rejected marks a failure where the platform refused the content after accepting it, so
+	// it never went out and the job fails
+	Rejected bool
+}
+
+// DeploymentVerifier is implemented by publishers whose content only goes live after a
+// separate build, such as a static site deployed by GitHub Pages, or after the platform
+// processed it in the background; publish results that still need verifying carry
+// "deploy_status": "pending" in their metadata
+type DeploymentVerifier interface {
+	VerifyDeployment(ctx context.Context, metadata map[string]string, config PublishConfig) (*DeploymentStatus, error)
+}
+
+// PublishedPost is a post published by a distribution job
+type PublishedPost struct {
+	JobID       uint
+	PublishID   string
+	Title       string
+	Metadata    map[string]string
+	PublishedAt time.Time
+}
+
+// PostMetrics are the performance stats of a published post; counts a platform does not report
+// are left at zero
+type PostMetrics struct {
+	Views    int64
+	Readers  int64
+	Likes    int64
+	Comments int64
+	Shares   int64
+}
+
+// MetricsProvider is implemented by publishers whose platform reports how posts perform. It
+// returns nil metrics when the platform has no stats for the post yet.
+type MetricsProvider interface {
+	CollectMetrics(ctx context.Context, post PublishedPost, config PublishConfig) (*PostMetrics, error)
+}
+
+// ExistingPost is a post found on a platform, e.g. one published before Ripple was set up
+type ExistingPost struct {
+	PublishID   string            `json:"publish_id"`
+	Title       string            `json:"title"`
+	Slug        string            `json:"slug"`
+	URL         string            `json:"url"`
+	Draft       bool              `json:"draft"`
+	PublishedAt *time.Time        `json:"published_at,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// PostLister is implemented by publishers that can list the posts already on their platform,
+// so they can be imported as published instead of being published again
+type PostLister interface {
+	ListPosts(ctx context.Context, config PublishConfig) ([]ExistingPost, error)
+}
+
+// DraftPreview is a saved draft as it can be reviewed before it is published
+type DraftPreview struct {
+	DraftID string `json:"draft_id"`
+	Title   string `json:"title"`
+	// URL is a temporary link to the draft for reviewing it on a phone
+	URL string `json:"url"`
+}
+
+// DraftPreviewer is implemented by publishers whose platform lets saved drafts be reviewed
+type DraftPreviewer interface {
+	PreviewDraft(ctx context.Context, draftID string, config PublishConfig) (*DraftPreview, error)
+	// SendDraftPreview sends the draft to a reviewer as a preview message and returns who it was
+	// sent to; an empty recipient sends it to the reviewer configured for the platform
+	SendDraftPreview(ctx context.Context, draftID, recipient string, config PublishConfig) (string, error)
+}
+
+// MediaRefresh reports what refreshing the media of a draft or post did
+type MediaRefresh struct {
+	// Refreshed counts the image references pointed at new uploads
+	Refreshed int `json:"refreshed"`
+	// Failed counts the images that could not be uploaded again and keep their old reference
+	Failed int `json:"failed"`
+}
+
+// MediaRefresher is implemented by publishers whose drafts or posts can keep image links that
+// expire, such as the signed links of Notion files. RefreshMedia uploads the images of the
+// content again and patches only the image references of the existing draft or post; its text
+// is left alone and no new publication is created.
+type MediaRefresher interface {
+	RefreshMedia(ctx context.Context, publishID string, content PublishContent, config PublishConfig) (*MediaRefresh, error)
+}
+
+// Material is media kept in a platform's permanent material library
+type Material struct {
+	MediaID   string    `json:"media_id"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MaterialList is one page of a material library
+type MaterialList struct {
+	Type   string     `json:"type"`
+	Total  int        `json:"total"`
+	Offset int        `json:"offset"`
+	Items  []Material `json:"items"`
+}
+
+// MaterialManager is implemented by publishers whose platform keeps a library of uploaded media,
+// so leftover uploads can be found and removed
+type MaterialManager interface {
+	ListMaterials(ctx context.Context, materialType string, offset, count int, config PublishConfig) (*MaterialList, error)
+	// UploadMaterial uploads a local file as permanent material of the type
+	UploadMaterial(ctx context.Context, materialType, filePath string, config PublishConfig) (*Material, error)
+	DeleteMaterial(ctx context.Context, mediaID string, config PublishConfig) error
+}
+
+// Violation is a platform limit that content breaks, such as a title that is too long
+type Violation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ContentValidator is implemented by publishers whose platform has hard limits on content.
+// Validate runs before the publisher is initialized, so it must not make network calls.
+type ContentValidator interface {
+	Validate(content PublishContent, config PublishConfig) []Violation
+}
+
+// ValidationError is returned when content breaks the limits of a platform
+type ValidationError struct {
+	Platform   string
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Message
+	}
+	return fmt.Sprintf("content does not meet %s limits: %s", e.Platform, strings.Join(messages, "; "))
+}
+
 // PublishConfig represents platform-specific configuration
 type PublishConfig struct {
 	PlatformName string            `json:"platform_name"`
@@ -72,6 +265,7 @@ type Publisher interface {
 	PublishDirect(ctx context.Context, content PublishContent, config PublishConfig) (*PublishResult, error)
 
 	GetPublishStatus(ctx context.Context, publishID string, config PublishConfig) (*PublishResult, error)
+	Unpublish(ctx context.Context, publishID string, config PublishConfig) error
 	Cleanup(ctx context.Context, publishID string, config PublishConfig) error
 }
 
@@ -100,16 +294,109 @@ func FromNotionPage(page *models.NotionPage) *PublishContent {
 	if page.ENTitle != "" {
 		metadata["en_title"] = page.ENTitle
 	}
+	if page.CoverURL != "" {
+		metadata["cover_url"] = page.CoverURL
+	}
+	if page.SEODescription != "" {
+		metadata["description"] = page.SEODescription
+	}
+	if page.Series != "" {
+		metadata["series"] = page.Series
+	}
+	if slug := pageText(page.Properties, "Slug"); slug != "" {
+		metadata["slug"] = slug
+	}
+	if footnotes, ok := pageFlag(page.Properties, "Footnotes"); ok {
+		metadata["footnotes"] = strconv.FormatBool(footnotes)
+	}
+	if toc, ok := pageFlag(page.Properties, "TOC"); ok {
+		metadata["toc"] = strconv.FormatBool(toc)
+	}
+	if frontMatter := pageText(page.Properties, "Front matter"); frontMatter != "" {
+		metadata["front_matter"] = frontMatter
+	}
+
+	// A summary written by the author wins over a generated one
+	summary := page.Summary
+	if summary == "" {
+		summary = page.AISummary
+	}
+
+	// Pages synced before documents were stored are parsed from their blocks when rendered
+	var document *content.Document
+	if page.Document != "" {
+		if parsed, err := content.Parse(page.Document); err == nil {
+			document = parsed
+		}
+	}
 
 	return &PublishContent{
 		ID:          page.NotionID,
 		Title:       page.Title,
 		Content:     page.Content,
-		Summary:     page.Summary,
+		Summary:     summary,
 		Tags:        tags,
 		Author:      page.Owner,
 		PublishDate: page.PostDate,
 		Metadata:    metadata,
 		Resources:   []Resource{}, // Will be populated during processing
+		Document:    document,
+	}
+}
+
+// pageText reads a text property of a page: the plain text of a Notion rich text property, or a
+// string from the front matter of other sources
+func pageText(properties, name string) string {
+	var props map[string]any
+	if properties == "" || json.Unmarshal([]byte(properties), &props) != nil {
+		return ""
+	}
+
+	for key, prop := range props {
+		if !strings.EqualFold(key, name) {
+			continue
+		}
+		switch value := prop.(type) {
+		case string:
+			return strings.TrimSpace(value)
+		case map[string]any:
+			segments, _ := value["rich_text"].([]any)
+			var text strings.Builder
+			for _, segment := range segments {
+				if segment, ok := segment.(map[string]any); ok {
+					plain, _ := segment["plain_text"].(string)
+					text.WriteString(plain)
+				}
+			}
+			return strings.TrimSpace(text.String())
+		}
+	}
+	return ""
+}
+
+// pageFlag reads a checkbox property of a page: a Notion checkbox, or a boolean from the front
+// matter of other sources. ok is false when the page doesn't have the property.
+func pageFlag(properties, name string) (value bool, ok bool) {
+	var props map[string]any
+	if properties == "" || json.Unmarshal([]byte(properties), &props) != nil {
+		return false, false
+	}
+
+	for key, prop := range props {
+		if !strings.EqualFold(key, name) {
+			continue
+		}
+		if notionProp, isNotion := prop.(map[string]any); isNotion {
+			prop = notionProp["checkbox"]
+		}
+		switch flag := prop.(type) {
+		case bool:
+			return flag, true
+		case string:
+			if parsed, err := strconv.ParseBool(flag); err == nil {
+				return parsed, true
+			}
+		}
 	}
+	return false, false
 }
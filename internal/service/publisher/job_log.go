@@ -0,0 +1,150 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gorm.io/gorm"
+
+	"github.com/ifuryst/ripple/internal/events"
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/pkg/requestid"
+)
+
+type loggerKey struct{}
+
+// WithLogger returns a context carrying the logger of the job being published
+func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Logger returns the logger of the job being published, so publishers can add their transform,
+// upload and API details to the job log, or fallback with the request ID outside of a publish
+func Logger(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok {
+		return logger
+	}
+	return requestid.Logger(ctx, fallback)
+}
+
+// jobLog stores the log lines of a publish in the job_logs table. Drafts only get a job once
+// they are saved, so lines written before then are kept until attach gives them one.
+type jobLog struct {
+	db *gorm.DB
+
+	mu      sync.Mutex
+	jobID   uint
+	pending []models.JobLog
+}
+
+func newJobLog(db *gorm.DB) *jobLog {
+	return &jobLog{db: db}
+}
+
+// attach stores the lines of the publish with the job; later calls are ignored
+func (l *jobLog) attach(jobID uint) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.jobID != 0 || jobID == 0 {
+		return
+	}
+	l.jobID = jobID
+
+	for _, line := range l.pending {
+		l.store(line)
+	}
+	l.pending = nil
+}
+
+func (l *jobLog) write(line models.JobLog) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.jobID == 0 {
+		l.pending = append(l.pending, line)
+		return
+	}
+	l.store(line)
+}
+
+// store saves a line and streams it to followers of the job; a line that cannot be saved is
+// still in the server log, so the error is dropped
+func (l *jobLog) store(line models.JobLog) {
+	line.JobID = l.jobID
+	if err := l.db.Create(&line).Error; err != nil {
+		return
+	}
+	events.Publish(events.JobLogged, line)
+}
+
+// logger returns a logger writing to both the server log and the job log
+func (l *jobLog) logger(base *zap.Logger) *zap.Logger {
+	return base.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, &jobLogCore{log: l})
+	}))
+}
+
+// jobLogCore is the zap core behind the job log; it records every level, down to the API
+// details publishers log at debug level
+type jobLogCore struct {
+	log    *jobLog
+	fields []zapcore.Field
+}
+
+func (c *jobLogCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c *jobLogCore) With(fields []zapcore.Field) zapcore.Core {
+	combined := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	combined = append(combined, c.fields...)
+	combined = append(combined, fields...)
+	return &jobLogCore{log: c.log, fields: combined}
+}
+
+func (c *jobLogCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checked.AddCore(entry, c)
+}
+
+func (c *jobLogCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	encoder := zapcore.NewMapObjectEncoder()
+	for _, field := range c.fields {
+		field.AddTo(encoder)
+	}
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+
+	values := make(models.JSONMap, len(encoder.Fields))
+	for key, value := range encoder.Fields {
+		values[key] = fieldString(value)
+	}
+
+	c.log.write(models.JobLog{
+		Level:     entry.Level.String(),
+		Message:   entry.Message,
+		Fields:    values,
+		CreatedAt: entry.Time,
+	})
+	return nil
+}
+
+func (c *jobLogCore) Sync() error {
+	return nil
+}
+
+func fieldString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	}
+	if data, err := json.Marshal(value); err == nil {
+		return string(data)
+	}
+	return fmt.Sprint(value)
+}
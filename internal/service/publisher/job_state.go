@@ -0,0 +1,72 @@
+package publisher
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ifuryst/ripple/internal/events"
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+// TransitionJob moves a job to a new status and records the change in the job history. The
+// job row is locked and the transition validated against its stored status, so concurrent
+// updates cannot skip states; jobs that are not stored yet are created. The error code set on
+// the job is stored with the error and cleared along with it. Moving to in progress starts a new
+// run of the job and leaving it, or creating a job with its start already set, finishes the run.
+func TransitionJob(db *gorm.DB, job *models.DistributionJob, to, errorMsg string) error {
+	from := ""
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if job.ID != 0 {
+			var current models.DistributionJob
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "status").First(&current, job.ID).Error; err != nil {
+				return fmt.Errorf("failed to lock job %d: %w", job.ID, err)
+			}
+			from = current.Status
+		}
+
+		if !models.CanTransitionJob(from, to) {
+			return fmt.Errorf("%w: job %d from %q to %q", models.ErrInvalidJobTransition, job.ID, from, to)
+		}
+
+		now := time.Now()
+		if to == models.JobInProgress {
+			job.StartedAt = &now
+			job.FinishedAt = nil
+			job.StageDurations = models.StageDurations{}
+		} else if from == models.JobInProgress || (from == "" && job.StartedAt != nil) {
+			job.FinishedAt = &now
+		}
+
+		job.Status = to
+		job.Error = errorMsg
+		if errorMsg == "" {
+			job.ErrorCode = ""
+		}
+		if err := tx.Omit(clause.Associations).Save(job).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.JobTransition{
+			JobID:      job.ID,
+			FromStatus: from,
+			ToStatus:   to,
+			Error:      errorMsg,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	events.Publish(events.JobStatus, events.JobStatusEvent{
+		JobID:      job.ID,
+		PageID:     job.PageID,
+		PlatformID: job.PlatformID,
+		From:       from,
+		To:         to,
+		Error:      errorMsg,
+	})
+	return nil
+}
@@ -0,0 +1,204 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ifuryst/ripple/pkg/httpclient"
+)
+
+// linkCheckCacheTTL keeps results while a page is published to several platforms
+const linkCheckCacheTTL = 10 * time.Minute
+
+// renderedLinkPatterns find links in transformed content: HTML, Markdown and Substack JSON
+var renderedLinkPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`href="(https?://[^"]+)"`),
+	regexp.MustCompile(`\]\((https?://[^)\s]+)\)`),
+	regexp.MustCompile(`"href":"(https?://[^"]+)"`),
+}
+
+// LinkCheckHook checks the links of a post before it is published. Dead links fail the
+// publish, or only produce a warning on the job when failOnDead is off.
+type LinkCheckHook struct {
+	client      *http.Client
+	concurrency int
+	failOnDead  bool
+	ignoreHosts map[string]bool
+
+	mu    sync.Mutex
+	cache map[string]linkCheckResult
+}
+
+type linkCheckResult struct {
+	problem   string
+	checkedAt time.Time
+}
+
+// NewLinkCheckHook creates a link checker checking up to concurrency links at once.
+// Links to the ignored hosts, and their subdomains, are never checked.
+func NewLinkCheckHook(timeout time.Duration, concurrency int, failOnDead bool, ignoreHosts []string) *LinkCheckHook {
+	if concurrency <= 0 {
+		concurrency = 8
+	}
+
+	ignored := make(map[string]bool)
+	for _, host := range ignoreHosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			ignored[host] = true
+		}
+	}
+
+	return &LinkCheckHook{
+		client:      httpclient.New(httpclient.WithTimeout(timeout)),
+		concurrency: concurrency,
+		failOnDead:  failOnDead,
+		ignoreHosts: ignored,
+		cache:       make(map[string]linkCheckResult),
+	}
+}
+
+func (h *LinkCheckHook) Name() string {
+	return "link_check"
+}
+
+func (h *LinkCheckHook) Stages() []HookStage {
+	return []HookStage{HookPrePublish}
+}
+
+func (h *LinkCheckHook) Run(ctx context.Context, event *HookEvent) (string, error) {
+	links := h.extractLinks(event.Content)
+	if len(links) == 0 {
+		return "", nil
+	}
+
+	problems := make([]string, len(links))
+	semaphore := make(chan struct{}, h.concurrency)
+	var wg sync.WaitGroup
+	for i, link := range links {
+		wg.Add(1)
+		go func(i int, link string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			problems[i] = h.check(ctx, link)
+		}(i, link)
+	}
+	wg.Wait()
+
+	var dead []string
+	for i, problem := range problems {
+		if problem != "" {
+			dead = append(dead, fmt.Sprintf("%s (%s)", links[i], problem))
+		}
+	}
+	if len(dead) == 0 {
+		return fmt.Sprintf("%d links ok", len(links)), nil
+	}
+
+	report := fmt.Sprintf("%d of %d links dead: %s", len(dead), len(links), strings.Join(dead, "; "))
+	if h.failOnDead {
+		return "", errors.New(report)
+	}
+	return report, nil
+}
+
+// extractLinks collects the external links of the document and of the rendered content
+func (h *LinkCheckHook) extractLinks(content *PublishContent) []string {
+	var candidates []string
+	if doc, err := content.ContentDocument(); err == nil {
+		candidates = append(candidates, doc.Links()...)
+	}
+	for _, pattern := range renderedLinkPatterns {
+		for _, match := range pattern.FindAllStringSubmatch(content.Content, -1) {
+			candidates = append(candidates, match[1])
+		}
+	}
+
+	var links []string
+	seen := make(map[string]bool)
+	for _, link := range candidates {
+		parsed, err := url.Parse(link)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || h.ignored(parsed.Hostname()) {
+			continue
+		}
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+func (h *LinkCheckHook) ignored(host string) bool {
+	host = strings.ToLower(host)
+	for ignored := range h.ignoreHosts {
+		if host == ignored || strings.HasSuffix(host, "."+ignored) {
+			return true
+		}
+	}
+	return false
+}
+
+// check returns why a link is dead, or an empty string when it works
+func (h *LinkCheckHook) check(ctx context.Context, link string) string {
+	h.mu.Lock()
+	cached, ok := h.cache[link]
+	h.mu.Unlock()
+	if ok && time.Since(cached.checkedAt) < linkCheckCacheTTL {
+		return cached.problem
+	}
+
+	// Some servers do not support HEAD, so anything but a clear answer is retried with GET
+	status, err := h.request(ctx, http.MethodHead, link)
+	if err != nil || status >= 400 && status != http.StatusNotFound && status != http.StatusGone {
+		status, err = h.request(ctx, http.MethodGet, link)
+	}
+
+	problem := ""
+	switch {
+	case err != nil:
+		problem = err.Error()
+	case status == http.StatusUnauthorized, status == http.StatusForbidden, status == http.StatusTooManyRequests:
+		// The page exists but does not let bots in
+	case status >= 400:
+		problem = fmt.Sprintf("status %d", status)
+	}
+
+	h.mu.Lock()
+	for cachedLink, result := range h.cache {
+		if time.Since(result.checkedAt) >= linkCheckCacheTTL {
+			delete(h.cache, cachedLink)
+		}
+	}
+	h.cache[link] = linkCheckResult{problem: problem, checkedAt: time.Now()}
+	h.mu.Unlock()
+
+	return problem
+}
+
+func (h *LinkCheckHook) request(ctx context.Context, method, link string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return 0, fmt.Errorf("invalid link: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; Ripple link checker)")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return 0, errors.New("timeout")
+		}
+		return 0, errors.New("unreachable")
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
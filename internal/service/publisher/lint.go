@@ -0,0 +1,253 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ifuryst/ripple/internal/content"
+)
+
+// Lint rule severities; an error stops the publish, a warning is only reported
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+	LintOff     = "off"
+)
+
+// Lint rules
+const (
+	LintTitleLength   = "title_length"
+	LintCoverRequired = "cover_required"
+	LintImageAlt      = "image_alt"
+	LintTodoMarkers   = "todo_markers"
+	LintMinWords      = "min_words"
+)
+
+// defaultLintSeverities apply to rules not listed in the configuration
+var defaultLintSeverities = map[string]string{
+	LintTitleLength:   LintError,
+	LintCoverRequired: LintWarning,
+	LintImageAlt:      LintWarning,
+	LintTodoMarkers:   LintError,
+	LintMinWords:      LintWarning,
+}
+
+// todoMarkerPattern finds leftover editing markers in the text
+var todoMarkerPattern = regexp.MustCompile(`\b(TODO|FIXME|TBD|XXX)\b|待补充|待完善`)
+
+// LintIssue is a rule violation found in a post
+type LintIssue struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// LintReport lists the issues of a post for a platform
+type LintReport struct {
+	Platform string      `json:"platform"`
+	Passed   bool        `json:"passed"`
+	Issues   []LintIssue `json:"issues"`
+}
+
+// Linter checks posts against editorial rules before they are published
+type Linter struct {
+	severities     map[string]string
+	maxTitleLength map[string]int
+	minWords       int
+}
+
+// NewLinter creates a linter. severities is a list like "cover_required:error,min_words:off";
+// maxTitleLength maps platforms to their longest title like "wechat-official:64,*:100".
+func NewLinter(severities, maxTitleLength string, minWords int) (*Linter, error) {
+	linter := &Linter{
+		severities:     make(map[string]string),
+		maxTitleLength: make(map[string]int),
+		minWords:       minWords,
+	}
+	for rule, severity := range defaultLintSeverities {
+		linter.severities[rule] = severity
+	}
+
+	for _, entry := range splitList(severities) {
+		rule, severity, ok := strings.Cut(entry, ":")
+		rule, severity = strings.TrimSpace(rule), strings.ToLower(strings.TrimSpace(severity))
+		if _, known := defaultLintSeverities[rule]; !ok || !known {
+			return nil, fmt.Errorf("invalid lint rule %q", entry)
+		}
+		if severity != LintError && severity != LintWarning && severity != LintOff {
+			return nil, fmt.Errorf("invalid severity %q for lint rule %s", severity, rule)
+		}
+		linter.severities[rule] = severity
+	}
+
+	for _, entry := range splitList(maxTitleLength) {
+		// Split at the last colon, platform names may name an account like wechat-official:en
+		separator := strings.LastIndex(entry, ":")
+		if separator < 0 {
+			return nil, fmt.Errorf("invalid max title length %q", entry)
+		}
+		platform, value := entry[:separator], entry[separator+1:]
+		length, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || length <= 0 {
+			return nil, fmt.Errorf("invalid max title length %q", entry)
+		}
+		linter.maxTitleLength[strings.TrimSpace(platform)] = length
+	}
+
+	return linter, nil
+}
+
+// Lint checks a post for a platform
+func (l *Linter) Lint(content *PublishContent, platform string) *LintReport {
+	report := &LintReport{Platform: platform, Issues: []LintIssue{}}
+	add := func(rule, message string) {
+		report.Issues = append(report.Issues, LintIssue{
+			Rule:     rule,
+			Severity: l.severities[rule],
+			Message:  message,
+		})
+	}
+	enabled := func(rule string) bool {
+		return l.severities[rule] != LintOff
+	}
+
+	if enabled(LintTitleLength) {
+		maxLength, ok := l.maxTitleLength[platform]
+		if !ok {
+			maxLength, ok = l.maxTitleLength[PlatformType(platform)]
+		}
+		if !ok {
+			maxLength = l.maxTitleLength["*"]
+		}
+		if length := utf8.RuneCountInString(content.Title); maxLength > 0 && length > maxLength {
+			add(LintTitleLength, fmt.Sprintf("title has %d characters, %s allows %d", length, platform, maxLength))
+		}
+	}
+
+	if enabled(LintCoverRequired) && content.Metadata["cover_url"] == "" {
+		add(LintCoverRequired, "post has no cover image")
+	}
+
+	doc, err := content.ContentDocument()
+	if err != nil {
+		report.Issues = append(report.Issues, LintIssue{
+			Rule:     "content",
+			Severity: LintError,
+			Message:  fmt.Sprintf("content could not be parsed: %v", err),
+		})
+		return report
+	}
+
+	if enabled(LintImageAlt) {
+		missing := 0
+		for _, block := range doc.Blocks {
+			if block.Image != nil && strings.TrimSpace(imageCaption(block.Image)) == "" {
+				missing++
+			}
+		}
+		if missing > 0 {
+			add(LintImageAlt, fmt.Sprintf("%d images have no caption to use as alt text", missing))
+		}
+	}
+
+	text := doc.Text()
+	if enabled(LintTodoMarkers) {
+		if markers := todoMarkerPattern.FindAllString(content.Title+"\n"+text, -1); len(markers) > 0 {
+			add(LintTodoMarkers, fmt.Sprintf("post contains %d TODO markers (first: %s)", len(markers), markers[0]))
+		}
+	}
+
+	if enabled(LintMinWords) && l.minWords > 0 {
+		// Notes are short by design
+		if words := countWords(text); words < l.minWords && !content.IsNote() {
+			add(LintMinWords, fmt.Sprintf("post has %d words, at least %d are required", words, l.minWords))
+		}
+	}
+
+	report.Passed = l.passed(report)
+	return report
+}
+
+func (l *Linter) passed(report *LintReport) bool {
+	for _, issue := range report.Issues {
+		if issue.Severity == LintError {
+			return false
+		}
+	}
+	return true
+}
+
+// countWords counts Latin words and CJK characters, which are read as a word each
+func countWords(text string) int {
+	words := 0
+	inWord := false
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			words++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				words++
+			}
+			inWord = true
+		default:
+			inWord = false
+		}
+	}
+	return words
+}
+
+func imageCaption(image *content.Image) string {
+	return content.PlainText(image.Caption)
+}
+
+func splitList(value string) []string {
+	var entries []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// LintHook runs the linter before publishing; errors stop the publish
+type LintHook struct {
+	linter *Linter
+}
+
+func NewLintHook(linter *Linter) *LintHook {
+	return &LintHook{linter: linter}
+}
+
+func (h *LintHook) Name() string {
+	return "lint"
+}
+
+func (h *LintHook) Stages() []HookStage {
+	return []HookStage{HookPrePublish}
+}
+
+func (h *LintHook) Run(ctx context.Context, event *HookEvent) (string, error) {
+	report := h.linter.Lint(event.Content, event.Platform)
+	if len(report.Issues) == 0 {
+		return "", nil
+	}
+
+	messages := make([]string, len(report.Issues))
+	for i, issue := range report.Issues {
+		messages[i] = fmt.Sprintf("[%s] %s: %s", issue.Severity, issue.Rule, issue.Message)
+	}
+	summary := strings.Join(messages, "; ")
+	if !report.Passed {
+		return "", errors.New(summary)
+	}
+	return summary, nil
+}
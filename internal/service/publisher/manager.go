@@ -2,20 +2,53 @@ package publisher
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/pkg/requestid"
 )
 
+// staleJobTimeout is how long an in-progress job blocks other publishes of the same page and
+// platform before it is considered abandoned
+const staleJobTimeout = 30 * time.Minute
+
+// ErrPublishInProgress is returned when another run is already publishing a page to a platform
+var ErrPublishInProgress = errors.New("publish already in progress")
+
+// ErrManualRemoval is returned when published content cannot be taken down through the platform
+// API and has to be removed by hand
+var ErrManualRemoval = errors.New("content has to be removed manually")
+
 // Manager implements the Manager interface
 type Manager struct {
+	// mu guards publishers and configs, which are replaced when the configuration is reloaded
+	mu         sync.RWMutex
 	publishers map[string]Publisher
 	logger     *zap.Logger
 	db         *gorm.DB
 	configs    map[string]PublishConfig
+	breaker    *CircuitBreaker
+	hooks      []Hook
+	mediaCache *MediaCache
+	slugs      *SlugRegistry
+	// cardRenderer renders the cards of image-centric notes; nil until cards are set up
+	cardRenderer CardRenderer
+	// titleTranslator translates titles for slugs; nil unless AI enrichment is enabled
+	titleTranslator TitleTranslator
+	// routingRules pick the platforms of pages without a Platform property, guarded by mu
+	routingRules []RoutingRule
+	// followers are the platforms pages follow a platform to, e.g. announcements, guarded by mu
+	followers map[string][]string
+
+	quarantineAfter int
+	onQuarantine    QuarantineFunc
 }
 
 func NewPublishManager(logger *zap.Logger, db *gorm.DB) *Manager {
@@ -24,21 +57,53 @@ func NewPublishManager(logger *zap.Logger, db *gorm.DB) *Manager {
 		logger:     logger,
 		db:         db,
 		configs:    make(map[string]PublishConfig),
+		breaker:    NewCircuitBreaker(0, 0),
+		mediaCache: NewMediaCache(db),
+		slugs:      NewSlugRegistry(db),
 	}
 }
 
+// SetCircuitBreaker replaces the circuit breaker guarding platform publishing
+func (m *Manager) SetCircuitBreaker(breaker *CircuitBreaker) {
+	m.breaker = breaker
+}
+
+// CircuitBreaker returns the circuit breaker guarding platform publishing
+func (m *Manager) CircuitBreaker() *CircuitBreaker {
+	return m.breaker
+}
+
 func (m *Manager) RegisterPublisher(publisher Publisher) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	platformName := publisher.GetPlatformName()
 	if _, exists := m.publishers[platformName]; exists {
 		return fmt.Errorf("publisher for platform %s already registered", platformName)
 	}
 
+	if user, ok := publisher.(MediaCacheUser); ok {
+		user.SetMediaCache(m.mediaCache)
+	}
+	if user, ok := publisher.(SlugRegistryUser); ok {
+		user.SetSlugRegistry(m.slugs)
+	}
+	if user, ok := publisher.(CardRendererUser); ok && m.cardRenderer != nil {
+		user.SetCardRenderer(m.cardRenderer)
+	}
+	if user, ok := publisher.(TitleTranslatorUser); ok && m.titleTranslator != nil {
+		user.SetTitleTranslator(m.titleTranslator)
+	}
+
 	m.publishers[platformName] = publisher
 	m.logger.Info("Publisher registered", zap.String("platform", platformName))
 	return nil
 }
 
 func (m *Manager) GetPublisher(platformName string) (Publisher, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	publisher, exists := m.publishers[platformName]
 	if !exists {
 		return nil, fmt.Errorf("publisher for platform %s not found", platformName)
@@ -47,6 +112,9 @@ func (m *Manager) GetPublisher(platformName string) (Publisher, error) {
 }
 
 func (m *Manager) GetAvailablePublishers() []Publisher {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	var publishers []Publisher
 	for _, publisher := range m.publishers {
 		publishers = append(publishers, publisher)
@@ -55,10 +123,15 @@ func (m *Manager) GetAvailablePublishers() []Publisher {
 }
 
 func (m *Manager) SetPlatformConfig(platformName string, config PublishConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.configs[platformName] = config
 }
 
 func (m *Manager) GetPlatformConfig(platformName string) (PublishConfig, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	config, exists := m.configs[platformName]
 	if !exists {
 		return PublishConfig{}, fmt.Errorf("config for platform %s not found", platformName)
@@ -66,176 +139,479 @@ func (m *Manager) GetPlatformConfig(platformName string) (PublishConfig, error)
 	return config, nil
 }
 
-func (m *Manager) PublishToAll(ctx context.Context, page *models.NotionPage) (map[string]*PublishResult, error) {
-	// Use platforms directly from page.Platforms (now a StringArray)
-	notionPlatforms := []string(page.Platforms)
+// PlatformConfigs returns the configs of the registered platforms
+func (m *Manager) PlatformConfigs() map[string]PublishConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
-	// Map Notion platform names to system platform names
-	var platforms []string
-	for _, notionPlatform := range notionPlatforms {
-		if systemPlatform := m.mapPlatformName(notionPlatform); systemPlatform != "" {
-			platforms = append(platforms, systemPlatform)
+	configs := make(map[string]PublishConfig, len(m.configs))
+	for platformName, config := range m.configs {
+		configs[platformName] = config
+	}
+	return configs
+}
+
+// ReplacePublishers swaps in the publishers and platform configs registered on another manager,
+// e.g. one set up from a reloaded configuration. Publishes already running finish with the
+// publishers they started with.
+func (m *Manager) ReplacePublishers(from *Manager) {
+	m.mu.RLock()
+	cardRenderer := m.cardRenderer
+	titleTranslator := m.titleTranslator
+	m.mu.RUnlock()
+
+	from.mu.RLock()
+	publishers := make(map[string]Publisher, len(from.publishers))
+	for platformName, publisher := range from.publishers {
+		if user, ok := publisher.(MediaCacheUser); ok {
+			user.SetMediaCache(m.mediaCache)
+		}
+		if user, ok := publisher.(SlugRegistryUser); ok {
+			user.SetSlugRegistry(m.slugs)
+		}
+		if user, ok := publisher.(CardRendererUser); ok && cardRenderer != nil {
+			user.SetCardRenderer(cardRenderer)
+		}
+		if user, ok := publisher.(TitleTranslatorUser); ok && titleTranslator != nil {
+			user.SetTitleTranslator(titleTranslator)
 		}
+		publishers[platformName] = publisher
+	}
+	configs := make(map[string]PublishConfig, len(from.configs))
+	for platformName, config := range from.configs {
+		configs[platformName] = config
+	}
+	from.mu.RUnlock()
+
+	m.mu.Lock()
+	m.publishers = publishers
+	m.configs = configs
+	m.mu.Unlock()
+}
+
+// CheckCredentials verifies the configured credentials of a platform, calling the platform API
+// when the publisher implements CredentialVerifier. The publisher is never initialized, so the
+// check cannot interfere with publishes running on the same instance.
+func (m *Manager) CheckCredentials(ctx context.Context, platformName string) error {
+	publisher, err := m.GetPublisher(platformName)
+	if err != nil {
+		return err
+	}
+
+	config, err := m.GetPlatformConfig(platformName)
+	if err != nil {
+		return err
+	}
+
+	if err := publisher.ValidateConfig(config); err != nil {
+		return err
+	}
+	if verifier, ok := publisher.(CredentialVerifier); ok {
+		return verifier.VerifyCredentials(ctx, config)
+	}
+	return nil
+}
+
+// VerifyDeployment checks whether content published to the platform has gone live; platforms
+// that publish immediately are always reported as live
+func (m *Manager) VerifyDeployment(ctx context.Context, platformName string, metadata map[string]string) (*DeploymentStatus, error) {
+	publisher, err := m.GetPublisher(platformName)
+	if err != nil {
+		return nil, err
+	}
+
+	verifier, ok := publisher.(DeploymentVerifier)
+	if !ok {
+		return &DeploymentStatus{State: DeploymentLive, URL: metadata["url"]}, nil
+	}
+
+	config, err := m.GetPlatformConfig(platformName)
+	if err != nil {
+		return nil, err
+	}
+
+	return verifier.VerifyDeployment(ctx, metadata, config)
+}
+
+// CollectMetrics collects the stats of posts published to a platform, keyed by job ID. Platforms
+// without stats return nil; posts whose stats cannot be collected are left out.
+func (m *Manager) CollectMetrics(ctx context.Context, platformName string, posts []PublishedPost) (map[uint]*PostMetrics, error) {
+	publisher, err := m.GetPublisher(platformName)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(platforms) == 0 {
-		// If no platforms specified, publish to all available platforms
-		for platformName := range m.publishers {
-			platforms = append(platforms, platformName)
+	provider, ok := publisher.(MetricsProvider)
+	if !ok {
+		return nil, nil
+	}
+
+	config, err := m.GetPlatformConfig(platformName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := publisher.Initialize(ctx, config); err != nil {
+		return nil, fmt.Errorf("failed to initialize publisher: %w", err)
+	}
+
+	metrics := make(map[uint]*PostMetrics)
+	for _, post := range posts {
+		postMetrics, err := provider.CollectMetrics(ctx, post, config)
+		if err != nil {
+			m.logger.Warn("Failed to collect post metrics",
+				zap.String("platform", platformName),
+				zap.Uint("job_id", post.JobID),
+				zap.Error(err))
+			continue
+		}
+		if postMetrics != nil {
+			metrics[post.JobID] = postMetrics
 		}
 	}
+	return metrics, nil
+}
 
-	return m.PublishToPlatforms(ctx, page, platforms)
+func (m *Manager) PublishToAll(ctx context.Context, page *models.NotionPage) (map[string]*PublishResult, error) {
+	route := m.RoutePage(page)
+	if route.Source != RouteSourceProperty {
+		m.logger.Info("Routing page without platforms",
+			zap.String("page_id", page.NotionID),
+			zap.String("source", route.Source),
+			zap.Strings("rules", route.Rules),
+			zap.Strings("platforms", route.Platforms))
+	}
+
+	return m.PublishToPlatforms(ctx, page, route.Platforms)
 }
 
+// PublishToPlatforms publishes a page to the given platforms; platforms the page marks draft-only
+// get a draft instead, see saveDraftOnce
 func (m *Manager) PublishToPlatforms(ctx context.Context, page *models.NotionPage, platforms []string) (map[string]*PublishResult, error) {
 	results := make(map[string]*PublishResult)
 	content := FromNotionPage(page)
+	route := m.RoutePage(page)
 
 	for _, platformName := range platforms {
-		publisher, err := m.GetPublisher(platformName)
-		if err != nil {
-			m.logger.Error("Publisher not found",
-				zap.String("platform", platformName),
-				zap.Error(err))
-			results[platformName] = &PublishResult{
-				Success:  false,
-				Error:    err,
-				ErrorMsg: err.Error(),
-			}
-			continue
+		var result *PublishResult
+		if route.IsDraft(platformName) {
+			result = m.saveDraftOnce(ctx, page, platformName)
+		} else {
+			result = m.publishToPlatform(ctx, page, content, platformName)
 		}
+		classifyResult(result)
+		results[platformName] = result
+	}
 
-		config, err := m.GetPlatformConfig(platformName)
-		if err != nil {
-			m.logger.Error("Platform config not found",
-				zap.String("platform", platformName),
-				zap.Error(err))
-			results[platformName] = &PublishResult{
-				Success:  false,
-				Error:    err,
-				ErrorMsg: err.Error(),
-			}
-			continue
+	return results, nil
+}
+
+// saveDraftOnce saves a page as a draft on a draft-only platform, unless a draft was already
+// saved or promoted. The draft job waits there for the promote action.
+func (m *Manager) saveDraftOnce(ctx context.Context, page *models.NotionPage, platformName string) *PublishResult {
+	var latest models.DistributionJob
+	err := m.db.Where("page_id = ? AND status IN ?", page.ID, []string{models.JobDraft, models.JobCompleted}).
+		Where("platform_id IN (?)", m.db.Model(&models.Platform{}).Select("id").Where("name = ?", platformName)).
+		Order("updated_at DESC").
+		First(&latest).Error
+	if err == nil {
+		m.logger.Info("Draft already saved, skipping",
+			zap.String("platform", platformName),
+			zap.Uint("page_id", page.ID),
+			zap.Uint("job_id", latest.ID),
+			zap.String("status", latest.Status))
+		return &PublishResult{
+			Success:   true,
+			PublishID: latest.PublishID,
+			Metadata:  latest.Metadata,
 		}
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return &PublishResult{
+			Success:  false,
+			Error:    err,
+			ErrorMsg: err.Error(),
+		}
+	}
 
-		// Check if platform is enabled
-		if !config.Enabled {
-			m.logger.Info("Platform disabled, skipping",
-				zap.String("platform", platformName))
-			err := fmt.Errorf("platform %s is disabled", platformName)
-			results[platformName] = &PublishResult{
-				Success:  false,
-				Error:    err,
-				ErrorMsg: err.Error(),
-			}
-			continue
+	result, err := m.PublishSinglePlatform(ctx, page, platformName, true)
+	if err != nil {
+		return &PublishResult{
+			Success:  false,
+			Error:    err,
+			ErrorMsg: err.Error(),
 		}
+	}
+	return result
+}
 
-		// Get platform ID
-		platformID := m.getPlatformID(platformName)
-		if platformID == 0 {
-			m.logger.Error("Failed to get platform ID",
-				zap.String("platform", platformName))
-			err := fmt.Errorf("failed to get platform ID for %s", platformName)
-			results[platformName] = &PublishResult{
+// publishToPlatform publishes a page to one platform. A panic, e.g. a transformer choking on
+// malformed content, fails the job instead of taking down the caller's goroutine.
+func (m *Manager) publishToPlatform(ctx context.Context, page *models.NotionPage, content *PublishContent, platformName string) (result *PublishResult) {
+	var job *models.DistributionJob
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err := recoverPanic(recovered)
+			m.logger.Error("Recovered from panic while publishing",
+				zap.String("platform", platformName),
+				zap.Uint("page_id", page.ID),
+				zap.Any("panic", recovered),
+				zap.String("stack", err.Stack))
+			if job != nil {
+				m.failJob(job, platformName, err)
+			}
+			m.recordPlatformFailure(platformName, err)
+			result = &PublishResult{
 				Success:  false,
 				Error:    err,
 				ErrorMsg: err.Error(),
 			}
-			continue
 		}
+	}()
 
-		// Check if this platform already has a completed job
-		var existingJob models.DistributionJob
-		if err := m.db.Where("page_id = ? AND platform_id = ? AND status = ?", 
-			page.ID, platformID, "completed").First(&existingJob).Error; err == nil {
-			// Job already completed, skip
-			m.logger.Info("Platform already completed, skipping",
-				zap.String("platform", platformName),
-				zap.Uint("page_id", page.ID))
-			results[platformName] = &PublishResult{
-				Success: true,
-				PublishID: fmt.Sprintf("existing-job-%d", existingJob.ID),
-			}
-			continue
+	publisher, err := m.GetPublisher(platformName)
+	if err != nil {
+		m.logger.Error("Publisher not found",
+			zap.String("platform", platformName),
+			zap.Error(err))
+		return &PublishResult{
+			Success:  false,
+			Error:    err,
+			ErrorMsg: err.Error(),
 		}
+	}
 
-		// Record distribution job start
-		job := &models.DistributionJob{
-			PageID:     page.ID,
-			PlatformID: platformID,
-			Status:     "in_progress",
-			Content:    content.Content,
+	config, err := m.GetPlatformConfig(platformName)
+	if err != nil {
+		m.logger.Error("Platform config not found",
+			zap.String("platform", platformName),
+			zap.Error(err))
+		return &PublishResult{
+			Success:  false,
+			Error:    err,
+			ErrorMsg: err.Error(),
 		}
+	}
 
-		if err := m.db.Create(job).Error; err != nil {
-			m.logger.Error("Failed to create distribution job",
-				zap.String("platform", platformName),
-				zap.Error(err))
+	// Check if platform is enabled
+	if !config.Enabled {
+		m.logger.Info("Platform disabled, skipping",
+			zap.String("platform", platformName))
+		err := fmt.Errorf("platform %s is disabled", platformName)
+		return &PublishResult{
+			Success:  false,
+			Error:    err,
+			ErrorMsg: err.Error(),
 		}
+	}
 
-		// Initialize publisher
-		if err := publisher.Initialize(ctx, config); err != nil {
-			m.logger.Error("Failed to initialize publisher",
-				zap.String("platform", platformName),
-				zap.Error(err))
+	// Get platform ID
+	platformID := m.getPlatformID(platformName)
+	if platformID == 0 {
+		m.logger.Error("Failed to get platform ID",
+			zap.String("platform", platformName))
+		err := fmt.Errorf("failed to get platform ID for %s", platformName)
+		return &PublishResult{
+			Success:  false,
+			Error:    err,
+			ErrorMsg: err.Error(),
+		}
+	}
 
-			m.updateJobStatus(job, "failed", err.Error())
-			results[platformName] = &PublishResult{
-				Success:  false,
-				Error:    err,
-				ErrorMsg: err.Error(),
-			}
-			continue
+	// Check if this platform already has a completed job
+	var existingJob models.DistributionJob
+	if err := m.db.Where("page_id = ? AND platform_id = ? AND status = ?", 
+		page.ID, platformID, "completed").First(&existingJob).Error; err == nil {
+		// Job already completed, skip
+		m.logger.Info("Platform already completed, skipping",
+			zap.String("platform", platformName),
+			zap.Uint("page_id", page.ID))
+		return &PublishResult{
+			Success: true,
+			PublishID: fmt.Sprintf("existing-job-%d", existingJob.ID),
 		}
+	}
 
-		// Publish content
-		result, err := publisher.PublishDirect(ctx, *content, config)
-		if err != nil {
-			m.logger.Error("Failed to publish content",
-				zap.String("platform", platformName),
-				zap.Error(err))
+	// Hold the publish while the platform is paused, resuming the platform publishes it
+	if platform, paused := m.pausedPlatform(platformID); paused {
+		err := m.holdJob(page, platform, content)
+		return &PublishResult{
+			Success:  false,
+			Error:    err,
+			ErrorMsg: err.Error(),
+		}
+	}
 
-			m.updateJobStatus(job, "failed", err.Error())
-			results[platformName] = &PublishResult{
-				Success:  false,
-				Error:    err,
-				ErrorMsg: err.Error(),
-			}
-			continue
+	// Content the platform rejected fails the same way until the page is edited
+	if rejected, ok := m.unchangedRejection(page, platformID); ok {
+		err := NewError(ErrorCodeContentInvalid, fmt.Errorf("not retried, the page has not changed since %s rejected it (job %d): %s",
+			platformName, rejected.ID, rejected.Error))
+		m.logger.Info("Skipping rejected content",
+			zap.String("platform", platformName),
+			zap.Uint("page_id", page.ID),
+			zap.Uint("job_id", rejected.ID))
+		return &PublishResult{
+			Success:  false,
+			Error:    err,
+			ErrorMsg: err.Error(),
 		}
+	}
 
-		// Update job status
-		if result.Success {
-			m.updateJobStatus(job, "completed", "")
-			job.PublishedAt = &result.PublishedAt
-		} else {
-			errorMsg := "unknown error"
-			if result.Error != nil {
-				errorMsg = result.Error.Error()
-			}
-			m.updateJobStatus(job, "failed", errorMsg)
+	// Skip platforms that keep failing until their cool-down has passed
+	if err := m.breaker.Allow(platformName); err != nil {
+		m.logger.Warn("Circuit open, skipping platform",
+			zap.String("platform", platformName),
+			zap.Error(err))
+		return &PublishResult{
+			Success:  false,
+			Error:    err,
+			ErrorMsg: err.Error(),
 		}
+	}
 
-		// Cleanup
-		if result.Success && result.PublishID != "" {
-			if err := publisher.Cleanup(ctx, result.PublishID, config); err != nil {
-				m.logger.Warn("Cleanup failed",
-					zap.String("platform", platformName),
-					zap.Error(err))
-			}
+	// Record distribution job start; the claim makes sure no other run publishes the pair.
+	// Neither a failed claim nor an already published pair says anything about the platform
+	job, completedJob, err := m.claimJob(page, platformID, content)
+	if err != nil || completedJob != nil {
+		m.breaker.Release(platformName)
+	}
+	if err != nil {
+		m.logger.Warn("Failed to claim distribution job",
+			zap.String("platform", platformName),
+			zap.Uint("page_id", page.ID),
+			zap.Error(err))
+		return &PublishResult{
+			Success:  false,
+			Error:    err,
+			ErrorMsg: err.Error(),
 		}
+	}
+	if completedJob != nil {
+		return &PublishResult{
+			Success:   true,
+			PublishID: fmt.Sprintf("existing-job-%d", completedJob.ID),
+		}
+	}
 
-		results[platformName] = result
+	// Log the publish to the job as well, so it can be followed and read back later
+	jobLog := newJobLog(m.db)
+	jobLog.attach(job.ID)
+	logger := jobLog.logger(requestid.Logger(ctx, m.logger)).With(zap.Uint("job_id", job.ID))
+	jobCtx, stages := withStageTimer(WithLogger(ctx, logger))
+
+	logger.Info("Publishing to platform",
+		zap.String("platform", platformName),
+		zap.String("title", page.Title))
+
+	// Run the pre-publish hooks on the platform's own copy of the content
+	platformContent := copyContent(content)
+	hookResults, err := m.runHooks(jobCtx, &HookEvent{Stage: HookPreTransform, Platform: platformName, Content: platformContent})
+	if err == nil {
+		var prePublishResults models.HookResults
+		prePublishResults, err = m.runHooks(jobCtx, &HookEvent{Stage: HookPrePublish, Platform: platformName, Content: platformContent})
+		hookResults = append(hookResults, prePublishResults...)
+	}
+	if err == nil {
+		err = m.validate(jobCtx, publisher, platformContent, config)
+	}
+	if err != nil {
+		logger.Warn("Publish rejected", zap.Error(err))
+
+		// A rejected publish says nothing about the platform, so the breaker only gives up the probe
+		m.breaker.Release(platformName)
+		job.HookResults = hookResults
+		job.ErrorCode = string(CodeOf(err))
+		m.updateJobStatus(job, "failed", err.Error())
+		return &PublishResult{
+			Success:    false,
+			Error:      err,
+			ErrorMsg:   err.Error(),
+			Violations: violations(err),
+		}
+	}
+
+	// Initialize publisher
+	if err := publisher.Initialize(jobCtx, config); err != nil {
+		logger.Error("Failed to initialize publisher",
+			zap.String("platform", platformName),
+			zap.Error(err))
+
+		m.failJob(job, platformName, err)
+		m.recordPlatformFailure(platformName, err)
+		return &PublishResult{
+			Success:  false,
+			Error:    err,
+			ErrorMsg: err.Error(),
+		}
+	}
 
-		m.logger.Info("Publishing completed",
+	// Publish content
+	result, err = publisher.PublishDirect(jobCtx, *platformContent, config)
+	job.StageDurations = stages.Durations()
+	if err != nil {
+		logger.Error("Failed to publish content",
 			zap.String("platform", platformName),
-			zap.Bool("success", result.Success),
-			zap.String("publish_id", result.PublishID))
+			zap.Error(err))
+
+		postPublishResults, _ := m.runHooks(jobCtx, &HookEvent{
+			Stage:    HookPostPublish,
+			Platform: platformName,
+			Content:  platformContent,
+			Result:   &PublishResult{Success: false, Error: err, ErrorMsg: err.Error()},
+		})
+		job.HookResults = append(hookResults, postPublishResults...)
+		m.failJob(job, platformName, err)
+		m.recordPlatformFailure(platformName, err)
+		return &PublishResult{
+			Success:  false,
+			Error:    err,
+			ErrorMsg: err.Error(),
+		}
 	}
 
-	return results, nil
+	// Post-publish hooks only report, they cannot undo the publish
+	postPublishResults, _ := m.runHooks(jobCtx, &HookEvent{Stage: HookPostPublish, Platform: platformName, Content: platformContent, Result: result})
+	job.HookResults = append(hookResults, postPublishResults...)
+
+	// Update job status
+	job.Metadata = models.JSONMap(result.Metadata)
+	if result.Success {
+		job.PublishID = result.PublishID
+		job.URL = result.URL
+		job.PublishedAt = &result.PublishedAt
+		m.updateJobStatus(job, "completed", "")
+		m.breaker.RecordSuccess(platformName)
+
+		// PublishDirect transforms the content itself, the snapshot renders it once more
+		if job.Status == models.JobCompleted {
+			output := ""
+			if transformed, err := publisher.TransformContent(jobCtx, *platformContent); err == nil {
+				output = transformed.Content
+			}
+			m.recordVersion(jobCtx, job, page, output)
+		}
+	} else {
+		failure := resultError(result)
+		m.failJob(job, platformName, failure)
+		m.recordPlatformFailure(platformName, failure)
+	}
+
+	// Cleanup
+	if result.Success && result.PublishID != "" {
+		if err := publisher.Cleanup(jobCtx, result.PublishID, config); err != nil {
+			logger.Warn("Cleanup failed",
+				zap.String("platform", platformName),
+				zap.Error(err))
+		}
+	}
+
+
+	logger.Info("Publishing completed",
+		zap.String("platform", platformName),
+		zap.Bool("success", result.Success),
+		zap.String("publish_id", result.PublishID))
+
+	return result
 }
 
 func (m *Manager) GetPublishHistory(ctx context.Context, pageID string) ([]*models.DistributionJob, error) {
@@ -259,7 +635,7 @@ func (m *Manager) GetPublishHistory(ctx context.Context, pageID string) ([]*mode
 }
 
 // PublishSinglePlatform publishes content to a single platform
-func (m *Manager) PublishSinglePlatform(ctx context.Context, page *models.NotionPage, platformName string, isDraft bool) (*PublishResult, error) {
+func (m *Manager) PublishSinglePlatform(ctx context.Context, page *models.NotionPage, platformName string, isDraft bool) (result *PublishResult, err error) {
 	publisher, err := m.GetPublisher(platformName)
 	if err != nil {
 		return &PublishResult{
@@ -287,10 +663,19 @@ func (m *Manager) PublishSinglePlatform(ctx context.Context, page *models.Notion
 		}, nil
 	}
 
-	content := FromNotionPage(page)
+	if err := m.breaker.Allow(platformName); err != nil {
+		return &PublishResult{
+			Success:  false,
+			Error:    err,
+			ErrorMsg: err.Error(),
+		}, nil
+	}
 
-	// Initialize publisher
-	if err := publisher.Initialize(ctx, config); err != nil {
+	// Get platform ID
+	platformID := m.getPlatformID(platformName)
+	if platformID == 0 {
+		m.breaker.Release(platformName)
+		err := fmt.Errorf("failed to get platform ID for %s", platformName)
 		return &PublishResult{
 			Success:  false,
 			Error:    err,
@@ -298,9 +683,16 @@ func (m *Manager) PublishSinglePlatform(ctx context.Context, page *models.Notion
 		}, nil
 	}
 
-	// Transform content
-	transformedContent, err := publisher.TransformContent(ctx, *content)
-	if err != nil {
+	content := FromNotionPage(page)
+	contentHash := ContentHash(content)
+
+	// Publishes wait for a paused platform, drafts are refused
+	if platform, paused := m.pausedPlatform(platformID); paused {
+		m.breaker.Release(platformName)
+		err := fmt.Errorf("%w: %s", ErrPlatformPaused, platformName)
+		if !isDraft {
+			err = m.holdJob(page, platform, content)
+		}
 		return &PublishResult{
 			Success:  false,
 			Error:    err,
@@ -308,8 +700,60 @@ func (m *Manager) PublishSinglePlatform(ctx context.Context, page *models.Notion
 		}, nil
 	}
 
-	// Process resources
-	if err := publisher.ProcessResources(ctx, transformedContent, config); err != nil {
+	// Drafts can be created any number of times, a publish only once per page and platform
+	var job *models.DistributionJob
+	if !isDraft {
+		claimed, completedJob, err := m.claimJob(page, platformID, content)
+		if err != nil || completedJob != nil {
+			m.breaker.Release(platformName)
+		}
+		if err != nil {
+			return &PublishResult{
+				Success:  false,
+				Error:    err,
+				ErrorMsg: err.Error(),
+			}, nil
+		}
+		if completedJob != nil {
+			m.logger.Info("Platform already completed, skipping",
+				zap.String("platform", platformName),
+				zap.Uint("page_id", page.ID))
+			return &PublishResult{
+				Success:   true,
+				PublishID: completedJob.PublishID,
+				Metadata:  completedJob.Metadata,
+			}, nil
+		}
+		job = claimed
+	}
+
+	// Log the publish to the job as well; drafts get their job, and with it their log, once saved
+	jobLog := newJobLog(m.db)
+	if job != nil {
+		jobLog.attach(job.ID)
+	}
+	defer func() {
+		if job != nil {
+			jobLog.attach(job.ID)
+		}
+	}()
+	logger := jobLog.logger(requestid.Logger(ctx, m.logger))
+	ctx, stages := withStageTimer(WithLogger(ctx, logger))
+	startedAt := time.Now()
+
+	logger.Info("Publishing to platform",
+		zap.String("platform", platformName),
+		zap.String("title", page.Title),
+		zap.Bool("draft", isDraft))
+
+	// fail releases the claimed job and reports the error
+	fail := func(err error) (*PublishResult, error) {
+		logger.Error("Publish failed", zap.String("platform", platformName), zap.Error(err))
+		m.recordPlatformFailure(platformName, err)
+		if job != nil {
+			job.StageDurations = stages.Durations()
+			m.failJob(job, platformName, err)
+		}
 		return &PublishResult{
 			Success:  false,
 			Error:    err,
@@ -317,7 +761,73 @@ func (m *Manager) PublishSinglePlatform(ctx context.Context, page *models.Notion
 		}, nil
 	}
 
-	var result *PublishResult
+	// A panic, e.g. a transformer choking on malformed content, fails the publish like any other error
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			panicErr := recoverPanic(recovered)
+			logger.Error("Recovered from panic while publishing",
+				zap.String("platform", platformName),
+				zap.Any("panic", recovered),
+				zap.String("stack", panicErr.Stack))
+			result, err = fail(panicErr)
+		}
+		classifyResult(result)
+	}()
+
+	// reject fails a publish stopped by a hook or validation; the platform itself is fine, so unlike fail it
+	// only releases the breaker probe and records a job for rejected drafts as well
+	reject := func(err error, hookResults models.HookResults) (*PublishResult, error) {
+		logger.Warn("Publish rejected", zap.String("platform", platformName), zap.Error(err))
+		m.breaker.Release(platformName)
+		if job == nil {
+			job = &models.DistributionJob{
+				PageID:     page.ID,
+				PlatformID: platformID,
+			}
+		}
+		job.HookResults = hookResults
+		job.ErrorCode = string(CodeOf(err))
+		m.updateJobStatus(job, "failed", err.Error())
+		return &PublishResult{
+			Success:    false,
+			Error:      err,
+			ErrorMsg:   err.Error(),
+			Violations: violations(err),
+		}, nil
+	}
+
+	content = copyContent(content)
+	hookResults, err := m.runHooks(ctx, &HookEvent{Stage: HookPreTransform, Platform: platformName, Draft: isDraft, Content: content})
+	if err != nil {
+		return reject(err, hookResults)
+	}
+
+	// Check the platform limits before the publisher makes any network call
+	if err := m.validate(ctx, publisher, content, config); err != nil {
+		return reject(err, hookResults)
+	}
+
+	// Initialize publisher
+	if err := publisher.Initialize(ctx, config); err != nil {
+		return fail(err)
+	}
+
+	// Transform content
+	transformedContent, err := publisher.TransformContent(ctx, *content)
+	if err != nil {
+		return fail(err)
+	}
+
+	// Process resources
+	if err := publisher.ProcessResources(ctx, transformedContent, config); err != nil {
+		return fail(err)
+	}
+
+	prePublishResults, err := m.runHooks(ctx, &HookEvent{Stage: HookPrePublish, Platform: platformName, Draft: isDraft, Content: transformedContent})
+	hookResults = append(hookResults, prePublishResults...)
+	if err != nil {
+		return reject(err, hookResults)
+	}
 
 	if isDraft {
 		// Save as draft
@@ -328,11 +838,18 @@ func (m *Manager) PublishSinglePlatform(ctx context.Context, page *models.Notion
 	}
 
 	if err != nil {
-		return &PublishResult{
-			Success:  false,
-			Error:    err,
-			ErrorMsg: err.Error(),
-		}, nil
+		result = &PublishResult{Success: false, Error: err, ErrorMsg: err.Error()}
+	}
+
+	// Post-publish hooks only report, they cannot undo the publish
+	postPublishResults, _ := m.runHooks(ctx, &HookEvent{Stage: HookPostPublish, Platform: platformName, Draft: isDraft, Content: transformedContent, Result: result})
+	hookResults = append(hookResults, postPublishResults...)
+
+	if err != nil {
+		if job != nil {
+			job.HookResults = hookResults
+		}
+		return fail(err)
 	}
 
 	// Record distribution job
@@ -342,44 +859,289 @@ func (m *Manager) PublishSinglePlatform(ctx context.Context, page *models.Notion
 	}
 	if !result.Success {
 		status = "failed"
+		m.recordPlatformFailure(platformName, resultError(result))
+	} else {
+		m.breaker.RecordSuccess(platformName)
 	}
 
-	// Get platform ID
-	platformID := m.getPlatformID(platformName)
-	if platformID == 0 {
-		err := fmt.Errorf("failed to get platform ID for %s", platformName)
-		return &PublishResult{
-			Success:  false,
-			Error:    err,
-			ErrorMsg: err.Error(),
-		}, nil
-	}
-
-	job := &models.DistributionJob{
-		PageID:     page.ID,
-		PlatformID: platformID,
-		Status:     status,
-		Content:    transformedContent.Content,
+	if job == nil {
+		// Saved drafts are recorded once done, as a run started with the publish
+		job = &models.DistributionJob{
+			PageID:      page.ID,
+			PlatformID:  platformID,
+			ContentHash: contentHash,
+			StartedAt:   &startedAt,
+		}
 	}
+	job.StageDurations = stages.Durations()
+	job.Content = transformedContent.Content
+	job.PublishID = result.PublishID
+	job.Metadata = models.JSONMap(result.Metadata)
+	job.HookResults = hookResults
 
 	if result.Success && !isDraft {
+		job.URL = result.URL
 		job.PublishedAt = &result.PublishedAt
 	}
 
+	errorMsg := ""
 	if result.Error != nil {
-		job.Error = result.Error.Error()
+		errorMsg = result.Error.Error()
 		// Ensure ErrorMsg is set for JSON serialization
 		if result.ErrorMsg == "" {
 			result.ErrorMsg = result.Error.Error()
 		}
 	}
 
-	if err := m.db.Create(job).Error; err != nil {
-		m.logger.Error("Failed to record distribution job",
-			zap.String("platform", platformName),
-			zap.Error(err))
+	if status == "failed" {
+		m.failJob(job, platformName, resultError(result))
+	} else {
+		m.updateJobStatus(job, status, errorMsg)
+		if job.Status == status {
+			m.recordVersion(ctx, job, page, transformedContent.Content)
+		}
 	}
 
+	logger.Info("Publishing completed",
+		zap.String("platform", platformName),
+		zap.String("status", status),
+		zap.String("publish_id", result.PublishID))
+
+	return result, nil
+}
+
+// validate checks content against the hard limits of the publisher's platform
+func (m *Manager) validate(ctx context.Context, publisher Publisher, content *PublishContent, config PublishConfig) error {
+	validator, ok := publisher.(ContentValidator)
+	if !ok {
+		return nil
+	}
+
+	found := validator.Validate(*content, config)
+	if len(found) == 0 {
+		return nil
+	}
+
+	Logger(ctx, m.logger).Warn("Content does not meet platform limits",
+		zap.String("platform", publisher.GetPlatformName()),
+		zap.String("title", content.Title),
+		zap.Int("violations", len(found)))
+
+	return &ValidationError{Platform: publisher.GetPlatformName(), Violations: found}
+}
+
+// violations returns the violations behind a validation error
+func violations(err error) []Violation {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Violations
+	}
+	return nil
+}
+
+// claimJob records an in-progress job for a page and platform unless the pair is already
+// published or being published, so concurrent scheduler and API runs publish it exactly once.
+// Quarantined pairs are refused until the quarantined job is released for republishing.
+// The page row is locked while checking, and a unique index on active jobs backs this up.
+// When the pair is already published the completed job is returned instead.
+func (m *Manager) claimJob(page *models.NotionPage, platformID uint, content *PublishContent) (*models.DistributionJob, *models.DistributionJob, error) {
+	var claimed, completed *models.DistributionJob
+
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		var locked models.NotionPage
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&locked, page.ID).Error; err != nil {
+			return fmt.Errorf("failed to lock page: %w", err)
+		}
+
+		var active models.DistributionJob
+		err := tx.Where("page_id = ? AND platform_id = ? AND status IN ?", page.ID, platformID, []string{"in_progress", "completed", "quarantined"}).
+			Order("updated_at DESC").
+			First(&active).Error
+		switch {
+		case err == nil && active.Status == "completed":
+			completed = &active
+			return nil
+		case err == nil && active.Status == models.JobQuarantined:
+			return fmt.Errorf("%w (job %d)", ErrJobQuarantined, active.ID)
+		case err == nil && time.Since(active.UpdatedAt) < staleJobTimeout:
+			return fmt.Errorf("%w (job %d)", ErrPublishInProgress, active.ID)
+		case err == nil:
+			// The run that claimed the job never finished it, most likely because the server stopped
+			abandoned := fmt.Sprintf("abandoned after %s without a result", staleJobTimeout)
+			if err := TransitionJob(tx, &active, models.JobFailed, abandoned); err != nil {
+				return err
+			}
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+
+		// A job held while the platform was paused is resumed with the current content
+		var held models.DistributionJob
+		err = tx.Where("page_id = ? AND platform_id = ? AND status = ?", page.ID, platformID, models.JobPaused).First(&held).Error
+		if err == nil {
+			held.Content = content.Content
+			held.ContentHash = ContentHash(content)
+			claimed = &held
+			return TransitionJob(tx, claimed, models.JobInProgress, "")
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		claimed = &models.DistributionJob{
+			PageID:      page.ID,
+			PlatformID:  platformID,
+			Content:     content.Content,
+			ContentHash: ContentHash(content),
+		}
+		return TransitionJob(tx, claimed, models.JobInProgress, "")
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, nil, ErrPublishInProgress
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return claimed, completed, nil
+}
+
+// Unpublish removes the content produced by a distribution job from its platform
+func (m *Manager) Unpublish(ctx context.Context, job *models.DistributionJob) error {
+	platformName := job.Platform.Name
+	if job.PublishID == "" {
+		return m.unpublishManually(job, fmt.Errorf("%w: job %d has no publish ID to unpublish", ErrManualRemoval, job.ID))
+	}
+
+	publisher, err := m.GetPublisher(platformName)
+	if err != nil {
+		return err
+	}
+
+	config, err := m.GetPlatformConfig(platformName)
+	if err != nil {
+		return err
+	}
+
+	if err := publisher.Initialize(ctx, config); err != nil {
+		return fmt.Errorf("failed to initialize publisher: %w", err)
+	}
+
+	if err := publisher.Unpublish(ctx, job.PublishID, config); err != nil {
+		if errors.Is(err, ErrManualRemoval) {
+			return m.unpublishManually(job, err)
+		}
+		m.recordJobError(job, fmt.Sprintf("unpublish failed: %v", err))
+		return fmt.Errorf("failed to unpublish from %s: %w", platformName, err)
+	}
+
+	m.updateJobStatus(job, "unpublished", "")
+
+	m.logger.Info("Content unpublished",
+		zap.String("platform", platformName),
+		zap.Uint("job_id", job.ID),
+		zap.String("publish_id", job.PublishID))
+
+	return nil
+}
+
+// unpublishManually marks a job unpublished that can never be unpublished through the platform,
+// so it is not retried, and notes on the job that the content has to be removed by hand
+func (m *Manager) unpublishManually(job *models.DistributionJob, err error) error {
+	m.updateJobStatus(job, models.JobUnpublished, fmt.Sprintf("remove manually: %v", err))
+	return err
+}
+
+// PromoteDraft publishes the draft created by a draft job and marks the job completed
+func (m *Manager) PromoteDraft(ctx context.Context, job *models.DistributionJob) (*PublishResult, error) {
+	platformName := job.Platform.Name
+	if job.PublishID == "" {
+		return nil, fmt.Errorf("job %d has no draft ID to publish", job.ID)
+	}
+
+	publisher, err := m.GetPublisher(platformName)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := m.GetPlatformConfig(platformName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.breaker.Allow(platformName); err != nil {
+		return nil, err
+	}
+
+	// Move the draft to in progress first, so promoting it twice at the same time or promoting
+	// a page that is already published fails instead of publishing again
+	if err := TransitionJob(m.db, job, models.JobInProgress, ""); err != nil {
+		m.breaker.Release(platformName)
+		switch {
+		case errors.Is(err, gorm.ErrDuplicatedKey):
+			return nil, fmt.Errorf("%w: page is already published to %s", ErrPublishInProgress, platformName)
+		case errors.Is(err, models.ErrInvalidJobTransition):
+			return nil, fmt.Errorf("%w (job %d)", ErrPublishInProgress, job.ID)
+		default:
+			return nil, err
+		}
+	}
+
+	if err := publisher.Initialize(ctx, config); err != nil {
+		m.recordPlatformFailure(platformName, err)
+		m.updateJobStatus(job, models.JobDraft, fmt.Sprintf("promote failed: %v", err))
+		return nil, fmt.Errorf("failed to initialize publisher: %w", err)
+	}
+
+	ctx, stages := withStageTimer(ctx)
+	result, err := publisher.Publish(ctx, job.PublishID, config)
+	job.StageDurations = stages.Durations()
+	if err != nil {
+		result = &PublishResult{
+			Success:  false,
+			Error:    err,
+			ErrorMsg: err.Error(),
+		}
+	}
+
+	if !result.Success {
+		// The draft is still there, so the job stays a draft and can be promoted again
+		m.recordPlatformFailure(platformName, resultError(result))
+		m.updateJobStatus(job, models.JobDraft, fmt.Sprintf("promote failed: %s", result.ErrorMsg))
+		return result, nil
+	}
+	m.breaker.RecordSuccess(platformName)
+
+	metadata := models.JSONMap{}
+	for k, v := range job.Metadata {
+		metadata[k] = v
+	}
+	for k, v := range result.Metadata {
+		metadata[k] = v
+	}
+	metadata["draft_id"] = job.PublishID
+	if result.URL != "" {
+		metadata["url"] = result.URL
+		job.URL = result.URL
+	}
+
+	job.Metadata = metadata
+	if result.PublishID != "" {
+		job.PublishID = result.PublishID
+	}
+	publishedAt := result.PublishedAt
+	if publishedAt.IsZero() {
+		publishedAt = time.Now()
+	}
+	job.PublishedAt = &publishedAt
+	m.updateJobStatus(job, "completed", "")
+
+	m.logger.Info("Draft promoted",
+		zap.String("platform", platformName),
+		zap.Uint("job_id", job.ID),
+		zap.String("publish_id", job.PublishID),
+		zap.String("url", result.URL))
+
 	return result, nil
 }
 
@@ -391,6 +1153,9 @@ func (m *Manager) MapPlatformName(notionPlatform string) string {
 }
 
 func (m *Manager) mapPlatformName(notionPlatform string) string {
+	// A draft-only platform is the same platform
+	notionPlatform, _ = SplitDraftPlatform(notionPlatform)
+
 	// Map Notion platform names to system platform names
 	platformMap := map[string]string{
 		"Blog":       "al-folio",
@@ -403,6 +1168,27 @@ func (m *Manager) mapPlatformName(notionPlatform string) string {
 		"wechat":     "wechat-official",
 		"Substack":   "substack",
 		"substack":   "substack",
+		"小红书":        "xiaohongshu",
+		"Xiaohongshu": "xiaohongshu",
+		"xiaohongshu": "xiaohongshu",
+		"Mastodon":   "mastodon",
+		"mastodon":   "mastodon",
+		"Bluesky":    "bluesky",
+		"bluesky":    "bluesky",
+		"Discord":    "discord",
+		"discord":    "discord",
+		"Slack":      "slack",
+		"slack":      "slack",
+		"Confluence": "confluence",
+		"confluence": "confluence",
+		"Wiki":       "confluence",
+		"wiki":       "confluence",
+		"Buffer":     "buffer",
+		"buffer":     "buffer",
+		"Typefully":  "typefully",
+		"typefully":  "typefully",
+		"Mock":       "mock",
+		"mock":       "mock",
 		// Direct matches (already using system names)
 		"al-folio":     "al-folio",
 		"wechat-official": "wechat-official",
@@ -412,6 +1198,13 @@ func (m *Manager) mapPlatformName(notionPlatform string) string {
 		return systemName
 	}
 
+	// Named accounts keep their account, so "WeChat:en" targets wechat-official:en
+	if platform, account := SplitPlatformName(notionPlatform); account != "" {
+		if systemName, exists := platformMap[strings.TrimSpace(platform)]; exists {
+			return AccountPlatformName(systemName, strings.TrimSpace(account))
+		}
+	}
+
 	m.logger.Warn("Unknown platform name", zap.String("notion_platform", notionPlatform))
 	return ""
 }
@@ -442,11 +1235,44 @@ func (m *Manager) getPlatformID(platformName string) uint {
 	return platform.ID
 }
 
+// unchangedRejection returns the latest job of a page and platform when it failed because the
+// content was invalid and the page has not been edited since. Republishing the job retries it.
+func (m *Manager) unchangedRejection(page *models.NotionPage, platformID uint) (*models.DistributionJob, bool) {
+	var latest models.DistributionJob
+	if err := m.db.Where("page_id = ? AND platform_id = ?", page.ID, platformID).
+		Order("created_at DESC").
+		First(&latest).Error; err != nil {
+		return nil, false
+	}
+	if latest.Status != models.JobFailed || ErrorCode(latest.ErrorCode) != ErrorCodeContentInvalid {
+		return nil, false
+	}
+	return &latest, !page.LastModified.After(latest.UpdatedAt)
+}
+
+// recordPlatformFailure counts a failure against the platform circuit; content the platform
+// rejected says nothing about the platform itself, so it only releases a half-open probe
+func (m *Manager) recordPlatformFailure(platformName string, err error) {
+	if CodeOf(err) == ErrorCodeContentInvalid {
+		m.breaker.Release(platformName)
+		return
+	}
+	m.breaker.RecordFailure(platformName, err.Error())
+}
+
 func (m *Manager) updateJobStatus(job *models.DistributionJob, status, errorMsg string) {
-	job.Status = status
-	job.Error = errorMsg
-	if err := m.db.Save(job).Error; err != nil {
+	if err := TransitionJob(m.db, job, status, errorMsg); err != nil {
 		m.logger.Error("Failed to update job status",
+			zap.Uint("job_id", job.ID),
+			zap.String("status", status),
+			zap.Error(err))
+	}
+}
+
+// recordJobError stores an error on a job without changing its status
+func (m *Manager) recordJobError(job *models.DistributionJob, errorMsg string) {
+	if err := m.db.Model(job).Update("error", errorMsg).Error; err != nil {
+		m.logger.Error("Failed to record job error",
 			zap.Uint("job_id", job.ID),
 			zap.Error(err))
 	}
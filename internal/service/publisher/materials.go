@@ -0,0 +1,64 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrMaterialsNotSupported is returned for platforms whose publisher has no material library
+	ErrMaterialsNotSupported = errors.New("platform does not support material management")
+	// ErrInvalidMaterialType is returned for material types the platform doesn't list or accept
+	ErrInvalidMaterialType = errors.New("invalid material type")
+)
+
+// ListMaterials returns a page of the material library of a platform
+func (m *Manager) ListMaterials(ctx context.Context, platformName, materialType string, offset, count int) (*MaterialList, error) {
+	materials, config, err := m.materialManager(ctx, platformName)
+	if err != nil {
+		return nil, err
+	}
+	return materials.ListMaterials(ctx, materialType, offset, count, config)
+}
+
+// UploadMaterial uploads a local file to the material library of a platform
+func (m *Manager) UploadMaterial(ctx context.Context, platformName, materialType, filePath string) (*Material, error) {
+	materials, config, err := m.materialManager(ctx, platformName)
+	if err != nil {
+		return nil, err
+	}
+	return materials.UploadMaterial(ctx, materialType, filePath, config)
+}
+
+// DeleteMaterial removes media from the material library of a platform
+func (m *Manager) DeleteMaterial(ctx context.Context, platformName, mediaID string) error {
+	materials, config, err := m.materialManager(ctx, platformName)
+	if err != nil {
+		return err
+	}
+	return materials.DeleteMaterial(ctx, mediaID, config)
+}
+
+// materialManager returns the initialized publisher of a platform as a MaterialManager
+func (m *Manager) materialManager(ctx context.Context, platformName string) (MaterialManager, PublishConfig, error) {
+	publisher, err := m.GetPublisher(platformName)
+	if err != nil {
+		return nil, PublishConfig{}, err
+	}
+
+	materials, ok := publisher.(MaterialManager)
+	if !ok {
+		return nil, PublishConfig{}, fmt.Errorf("%w: %s", ErrMaterialsNotSupported, platformName)
+	}
+
+	config, err := m.GetPlatformConfig(platformName)
+	if err != nil {
+		return nil, PublishConfig{}, err
+	}
+
+	if err := publisher.Initialize(ctx, config); err != nil {
+		return nil, PublishConfig{}, fmt.Errorf("failed to initialize publisher: %w", err)
+	}
+	return materials, config, nil
+}
@@ -0,0 +1,66 @@
+package publisher
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+// MediaCache remembers the images uploaded to each platform account in the media_assets table,
+// so media processors can reuse an earlier upload of the same bytes. A nil cache never hits.
+type MediaCache struct {
+	db *gorm.DB
+}
+
+// MediaCacheUser is implemented by publishers that upload media and want to skip repeated uploads
+type MediaCacheUser interface {
+	SetMediaCache(cache *MediaCache)
+}
+
+func NewMediaCache(db *gorm.DB) *MediaCache {
+	return &MediaCache{db: db}
+}
+
+// HashMedia returns the key media is cached under
+func HashMedia(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Lookup returns an earlier upload of the media of a kind, e.g. "image" or "thumb", to a
+// platform account; a failed lookup only costs an upload, so it is reported as a miss
+func (c *MediaCache) Lookup(ctx context.Context, platform, account, kind, hash string) (*models.MediaAsset, bool) {
+	if c == nil || c.db == nil {
+		return nil, false
+	}
+
+	var asset models.MediaAsset
+	err := c.db.WithContext(ctx).
+		Where("hash = ? AND platform = ? AND account = ? AND kind = ?", hash, platform, account, kind).
+		First(&asset).Error
+	if err != nil {
+		return nil, false
+	}
+
+	c.db.WithContext(ctx).Model(&asset).Update("last_used_at", time.Now())
+	return &asset, true
+}
+
+// Store records an upload; a failure only costs a repeated upload later, so it is not returned
+func (c *MediaCache) Store(ctx context.Context, asset models.MediaAsset) {
+	if c == nil || c.db == nil {
+		return
+	}
+
+	asset.LastUsedAt = time.Now()
+	c.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "hash"}, {Name: "platform"}, {Name: "account"}, {Name: "kind"}},
+		DoUpdates: clause.AssignmentColumns([]string{"media_id", "url", "size", "last_used_at"}),
+	}).Create(&asset)
+}
@@ -0,0 +1,106 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/pkg/requestid"
+)
+
+// ErrMediaRefreshNotSupported is returned for jobs on platforms whose publisher can't refresh
+// the media of a draft or post
+var ErrMediaRefreshNotSupported = errors.New("platform does not support refreshing media")
+
+// MediaKey identifies an image across syncs: its URL without the query and fragment, since
+// Notion and S3 sign their links in the query and the signature changes with every sync
+func MediaKey(imageURL string) string {
+	parsed, err := url.Parse(imageURL)
+	if err != nil || parsed.Host == "" {
+		return imageURL
+	}
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+	return parsed.String()
+}
+
+// FreshImages maps the media keys of images to their current URLs
+func FreshImages(imageURLs []string) map[string]string {
+	fresh := make(map[string]string, len(imageURLs))
+	for _, imageURL := range imageURLs {
+		fresh[MediaKey(imageURL)] = imageURL
+	}
+	return fresh
+}
+
+// RefreshMedia uploads the images of a job's page again and points its draft or post at them,
+// e.g. after the Notion links it was published with expired. The job keeps its status and
+// publication; the refresh is recorded in its metadata and log. The job needs its Page and
+// Platform loaded.
+func (m *Manager) RefreshMedia(ctx context.Context, job *models.DistributionJob) (*MediaRefresh, error) {
+	platformName := job.Platform.Name
+	if job.PublishID == "" {
+		return nil, fmt.Errorf("job %d has no draft or post to refresh", job.ID)
+	}
+
+	publisher, err := m.GetPublisher(platformName)
+	if err != nil {
+		return nil, err
+	}
+
+	refresher, ok := publisher.(MediaRefresher)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrMediaRefreshNotSupported, platformName)
+	}
+
+	config, err := m.GetPlatformConfig(platformName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.breaker.Allow(platformName); err != nil {
+		return nil, err
+	}
+
+	if err := publisher.Initialize(ctx, config); err != nil {
+		m.recordPlatformFailure(platformName, err)
+		return nil, fmt.Errorf("failed to initialize publisher: %w", err)
+	}
+
+	jobLog := newJobLog(m.db)
+	jobLog.attach(job.ID)
+	logger := jobLog.logger(requestid.Logger(ctx, m.logger)).With(zap.Uint("job_id", job.ID))
+
+	refresh, err := refresher.RefreshMedia(WithLogger(ctx, logger), job.PublishID, *FromNotionPage(&job.Page), config)
+	if err != nil {
+		logger.Error("Media refresh failed",
+			zap.String("platform", platformName),
+			zap.Error(err))
+		m.recordPlatformFailure(platformName, err)
+		return nil, err
+	}
+	m.breaker.RecordSuccess(platformName)
+
+	metadata := models.JSONMap{}
+	for k, v := range job.Metadata {
+		metadata[k] = v
+	}
+	metadata["media_refreshed_at"] = time.Now().Format(time.RFC3339)
+	metadata["media_refreshed"] = strconv.Itoa(refresh.Refreshed)
+	if err := m.db.Model(job).Update("metadata", metadata).Error; err != nil {
+		return nil, fmt.Errorf("failed to record media refresh: %w", err)
+	}
+
+	logger.Info("Media refreshed",
+		zap.String("platform", platformName),
+		zap.String("publish_id", job.PublishID),
+		zap.Int("refreshed", refresh.Refreshed),
+		zap.Int("failed", refresh.Failed))
+	return refresh, nil
+}
@@ -0,0 +1,375 @@
+package microblog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+const (
+	defaultBlueskyServiceURL = "https://bsky.social"
+	// blueskyLimit is the post length of Bluesky, counted in graphemes; counting runes instead
+	// never undercounts
+	blueskyLimit = 300
+	// blueskyMaxImageSize is the largest image blob a post can embed
+	blueskyMaxImageSize   = 1000000
+	blueskyPostCollection = "app.bsky.feed.post"
+	blueskyAppURL         = "https://bsky.app"
+)
+
+var (
+	blueskyLinkPattern = regexp.MustCompile(`https?://[^\s]+`)
+	blueskyTagPattern  = regexp.MustCompile(`(?:^|\s)(#[\p{L}\p{N}_]+)`)
+)
+
+// bluesky posts records to the repository of an account with the AT Protocol, signed in with
+// the handle and an app password. The session is kept across publishes and refreshed when it
+// expires, since Bluesky limits how often accounts sign in.
+type bluesky struct {
+	client      *http.Client
+	serviceURL  string
+	identifier  string
+	appPassword string
+	language    string
+
+	mu      sync.Mutex
+	session *blueskySession
+}
+
+type blueskySession struct {
+	AccessJwt  string `json:"accessJwt"`
+	RefreshJwt string `json:"refreshJwt"`
+	DID        string `json:"did"`
+	Handle     string `json:"handle"`
+	// account is the service and handle the session was created for
+	account string
+}
+
+type blueskyRecordRef struct {
+	URI string `json:"uri"`
+	CID string `json:"cid"`
+}
+
+// blueskyError is the body of a failed XRPC call
+type blueskyError struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+func (b *bluesky) validate(config publisher.PublishConfig) error {
+	for _, key := range []string{"handle", "app_password"} {
+		if config.Config[key] == "" {
+			return fmt.Errorf("missing required config: %s", key)
+		}
+	}
+
+	if serviceURL := config.Config["service_url"]; serviceURL != "" {
+		if parsed, err := url.Parse(serviceURL); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("invalid service_url %q", serviceURL)
+		}
+	}
+	return nil
+}
+
+// initialize signs in, unless a session for the same account is still around
+func (b *bluesky) initialize(ctx context.Context, client *http.Client, config publisher.PublishConfig) error {
+	b.client = client
+	b.serviceURL = strings.TrimRight(config.Config["service_url"], "/")
+	if b.serviceURL == "" {
+		b.serviceURL = defaultBlueskyServiceURL
+	}
+	b.identifier = strings.TrimPrefix(config.Config["handle"], "@")
+	b.appPassword = config.Config["app_password"]
+	b.language = config.Config["language"]
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.session != nil && b.session.account == b.serviceURL+"/"+b.identifier {
+		return nil
+	}
+	return b.createSession(ctx)
+}
+
+func (b *bluesky) limit(config publisher.PublishConfig) int {
+	return blueskyLimit
+}
+
+func (b *bluesky) length(text string) int {
+	return utf8.RuneCountInString(text)
+}
+
+// checkCredentials signs in again, which fails once the app password is revoked
+func (b *bluesky) checkCredentials(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.createSession(ctx)
+}
+
+func (b *bluesky) uploadImage(ctx context.Context, data []byte, mimeType, alt string) (*uploadedImage, error) {
+	if len(data) > blueskyMaxImageSize {
+		return nil, fmt.Errorf("image of %d bytes is larger than the %d bytes Bluesky accepts", len(data), blueskyMaxImageSize)
+	}
+
+	var response struct {
+		Blob map[string]any `json:"blob"`
+	}
+	if err := b.call(ctx, "com.atproto.repo.uploadBlob", data, mimeType, &response); err != nil {
+		return nil, fmt.Errorf("failed to upload image: %w", err)
+	}
+	return &uploadedImage{Blob: response.Blob, Alt: alt}, nil
+}
+
+// linkCards is true, Bluesky shows a preview card only when the post embeds one
+func (b *bluesky) linkCards() bool {
+	return true
+}
+
+// createPost creates a post record with facets marking its links and hashtags, which Bluesky
+// doesn't detect in the text itself. Images are embedded with their alt text; without images a
+// link card is embedded instead, Bluesky posts embed one or the other. XRPC has no idempotency
+// keys, posts are not retried after a failed request.
+func (b *bluesky) createPost(ctx context.Context, p post, images []*uploadedImage, thumb *uploadedImage, root, parent *postRef, key string) (*postRef, error) {
+	record := map[string]any{
+		"$type":     blueskyPostCollection,
+		"text":      p.Text,
+		"createdAt": time.Now().UTC().Format(time.RFC3339Nano),
+	}
+	if facets := blueskyFacets(p.Text); len(facets) > 0 {
+		record["facets"] = facets
+	}
+	if b.language != "" {
+		record["langs"] = []string{b.language}
+	}
+	if parent != nil {
+		record["reply"] = map[string]any{
+			"root":   blueskyRecordRef{URI: root.ID, CID: root.CID},
+			"parent": blueskyRecordRef{URI: parent.ID, CID: parent.CID},
+		}
+	}
+
+	switch {
+	case len(images) > 0:
+		embedded := make([]map[string]any, len(images))
+		for i, image := range images {
+			// Bluesky requires the alt text, empty when the image has no caption
+			embedded[i] = map[string]any{"image": image.Blob, "alt": image.Alt}
+		}
+		record["embed"] = map[string]any{"$type": "app.bsky.embed.images", "images": embedded}
+	case p.Link != nil:
+		external := map[string]any{
+			"uri":         p.Link.URL,
+			"title":       p.Link.Title,
+			"description": p.Link.Description,
+		}
+		if thumb != nil {
+			external["thumb"] = thumb.Blob
+		}
+		record["embed"] = map[string]any{"$type": "app.bsky.embed.external", "external": external}
+	}
+
+	b.mu.Lock()
+	did := b.session.DID
+	b.mu.Unlock()
+	data, err := json.Marshal(map[string]any{
+		"repo":       did,
+		"collection": blueskyPostCollection,
+		"record":     record,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal post: %w", err)
+	}
+
+	var ref blueskyRecordRef
+	if err := b.call(ctx, "com.atproto.repo.createRecord", data, "application/json", &ref); err != nil {
+		return nil, err
+	}
+	return &postRef{ID: ref.URI, CID: ref.CID, URL: blueskyPostURL(ref.URI)}, nil
+}
+
+// deletePost deletes a post record by its at:// URI
+func (b *bluesky) deletePost(ctx context.Context, id string) error {
+	repo, collection, rkey, ok := parseATURI(id)
+	if !ok {
+		return fmt.Errorf("invalid post URI %q", id)
+	}
+
+	data, err := json.Marshal(map[string]string{"repo": repo, "collection": collection, "rkey": rkey})
+	if err != nil {
+		return err
+	}
+	// Deleting a record that doesn't exist succeeds
+	return b.call(ctx, "com.atproto.repo.deleteRecord", data, "application/json", nil)
+}
+
+// createSession signs in with the app password; b.mu must be held
+func (b *bluesky) createSession(ctx context.Context) error {
+	data, err := json.Marshal(map[string]string{"identifier": b.identifier, "password": b.appPassword})
+	if err != nil {
+		return err
+	}
+
+	var session blueskySession
+	if err := b.send(ctx, "com.atproto.server.createSession", "", data, "application/json", &session); err != nil {
+		return fmt.Errorf("failed to sign in to Bluesky: %w", err)
+	}
+	session.account = b.serviceURL + "/" + b.identifier
+	b.session = &session
+	return nil
+}
+
+// refreshSession exchanges the refresh token for a new session, signing in again when the
+// refresh token has expired too; b.mu must be held
+func (b *bluesky) refreshSession(ctx context.Context) error {
+	var session blueskySession
+	if err := b.send(ctx, "com.atproto.server.refreshSession", b.session.RefreshJwt, nil, "", &session); err != nil {
+		return b.createSession(ctx)
+	}
+	session.account = b.session.account
+	b.session = &session
+	return nil
+}
+
+// call sends a procedure with the session, refreshing the session once when it has expired
+func (b *bluesky) call(ctx context.Context, nsid string, body []byte, contentType string, out any) error {
+	b.mu.Lock()
+	token := b.session.AccessJwt
+	b.mu.Unlock()
+
+	err := b.send(ctx, nsid, token, body, contentType, out)
+	if !errorIs(err, "ExpiredToken") {
+		return err
+	}
+
+	b.mu.Lock()
+	if b.session.AccessJwt == token {
+		err = b.refreshSession(ctx)
+	} else {
+		err = nil
+	}
+	token = b.session.AccessJwt
+	b.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return b.send(ctx, nsid, token, body, contentType, out)
+}
+
+// xrpcError is a failed XRPC call with the error name Bluesky gave
+type xrpcError struct {
+	name string
+	err  error
+}
+
+func (e *xrpcError) Error() string { return e.err.Error() }
+func (e *xrpcError) Unwrap() error { return e.err }
+
+// errorIs reports whether err is a failed XRPC call with the error name
+func errorIs(err error, name string) bool {
+	var xerr *xrpcError
+	return errors.As(err, &xerr) && xerr.name == name
+}
+
+// send posts an XRPC procedure and decodes the JSON response into out
+func (b *bluesky) send(ctx context.Context, nsid, token string, body []byte, contentType string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.serviceURL+"/xrpc/"+nsid, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call Bluesky: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr blueskyError
+		message := string(data)
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			message = apiErr.Error + ": " + apiErr.Message
+		}
+		err := fmt.Errorf("Bluesky %s returned status %d: %s", nsid, resp.StatusCode, message)
+		switch {
+		case apiErr.Error == "ExpiredToken":
+			return &xrpcError{name: apiErr.Error, err: err}
+		case resp.StatusCode == http.StatusUnauthorized || apiErr.Error == "AuthenticationRequired" || apiErr.Error == "AccountTakedown":
+			return &xrpcError{name: apiErr.Error, err: fmt.Errorf("%w: %v", publisher.ErrCredentialsExpired, err)}
+		}
+		return &xrpcError{name: apiErr.Error, err: publisher.NewError(publisher.CodeForStatus(resp.StatusCode), err)}
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// blueskyFacets marks the links and hashtags of a post by their byte range in the text
+func blueskyFacets(text string) []map[string]any {
+	var facets []map[string]any
+	facet := func(start, end int, feature map[string]any) {
+		facets = append(facets, map[string]any{
+			"index":    map[string]int{"byteStart": start, "byteEnd": end},
+			"features": []map[string]any{feature},
+		})
+	}
+
+	for _, match := range blueskyLinkPattern.FindAllStringIndex(text, -1) {
+		link := strings.TrimRight(text[match[0]:match[1]], `.,;:!?)"'”’`)
+		facet(match[0], match[0]+len(link), map[string]any{"$type": "app.bsky.richtext.facet#link", "uri": link})
+	}
+	for _, match := range blueskyTagPattern.FindAllStringSubmatchIndex(text, -1) {
+		start, end := match[2], match[3]
+		tag := text[start+1 : end]
+		if !strings.ContainsFunc(tag, unicode.IsLetter) {
+			continue
+		}
+		facet(start, end, map[string]any{"$type": "app.bsky.richtext.facet#tag", "tag": tag})
+	}
+	return facets
+}
+
+// parseATURI splits an at://repo/collection/rkey URI
+func parseATURI(uri string) (repo, collection, rkey string, ok bool) {
+	parts := strings.Split(strings.TrimPrefix(uri, "at://"), "/")
+	if !strings.HasPrefix(uri, "at://") || len(parts) != 3 {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
+// blueskyPostURL is the page of a post on bsky.app
+func blueskyPostURL(uri string) string {
+	repo, _, rkey, ok := parseATURI(uri)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s/profile/%s/post/%s", blueskyAppURL, repo, rkey)
+}
@@ -0,0 +1,263 @@
+package microblog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+const (
+	// defaultMastodonLimit is the post length of mastodon.social and most instances
+	defaultMastodonLimit = 500
+	// mastodonURLLength is how many characters a link counts as, however long it is
+	mastodonURLLength = 23
+	// mastodonMaxAltText is the longest image description Mastodon accepts
+	mastodonMaxAltText = 1500
+	// mediaProcessingTimeout is how long to wait for an uploaded image to be processed
+	mediaProcessingTimeout = 30 * time.Second
+)
+
+var mastodonURLPattern = regexp.MustCompile(`https?://\S+`)
+
+// errNotFound is wrapped when a post or upload does not exist (anymore)
+var errNotFound = errors.New("not found")
+
+// mastodon posts statuses with the REST API of a Mastodon instance, authenticated by the access
+// token of an application created in the account's development settings
+type mastodon struct {
+	client      *http.Client
+	instanceURL string
+	accessToken string
+	visibility  string
+	language    string
+}
+
+type mastodonStatus struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+type mastodonMedia struct {
+	ID  string  `json:"id"`
+	URL *string `json:"url"`
+}
+
+func (m *mastodon) validate(config publisher.PublishConfig) error {
+	for _, key := range []string{"instance_url", "access_token"} {
+		if config.Config[key] == "" {
+			return fmt.Errorf("missing required config: %s", key)
+		}
+	}
+
+	if parsed, err := url.Parse(config.Config["instance_url"]); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return fmt.Errorf("invalid instance_url %q", config.Config["instance_url"])
+	}
+
+	switch visibility := config.Config["visibility"]; visibility {
+	case "", "public", "unlisted", "private":
+	default:
+		return fmt.Errorf("invalid visibility %q, expected public, unlisted or private", visibility)
+	}
+
+	if value := config.Config["max_characters"]; value != "" {
+		if limit, err := strconv.Atoi(value); err != nil || limit < 100 {
+			return fmt.Errorf("invalid max_characters %q, expected a number of at least 100", value)
+		}
+	}
+	return nil
+}
+
+func (m *mastodon) initialize(ctx context.Context, client *http.Client, config publisher.PublishConfig) error {
+	m.client = client
+	m.instanceURL = strings.TrimRight(config.Config["instance_url"], "/")
+	m.accessToken = config.Config["access_token"]
+	m.visibility = config.Config["visibility"]
+	m.language = config.Config["language"]
+	return nil
+}
+
+// limit is the configured post length; instances may allow longer posts than the default
+func (m *mastodon) limit(config publisher.PublishConfig) int {
+	if limit, err := strconv.Atoi(config.Config["max_characters"]); err == nil && limit > 0 {
+		return limit
+	}
+	return defaultMastodonLimit
+}
+
+// length counts characters the way Mastodon does, with every link counting as 23
+func (m *mastodon) length(text string) int {
+	links := mastodonURLPattern.FindAllString(text, -1)
+	length := utf8.RuneCountInString(mastodonURLPattern.ReplaceAllString(text, ""))
+	return length + len(links)*mastodonURLLength
+}
+
+func (m *mastodon) checkCredentials(ctx context.Context) error {
+	return m.call(ctx, http.MethodGet, "/api/v1/accounts/verify_credentials", nil, "", "", nil)
+}
+
+// uploadImage uploads an image with its description. Large images are processed in the
+// background, so the upload is polled until the image can be attached.
+func (m *mastodon) uploadImage(ctx context.Context, data []byte, mimeType, alt string) (*uploadedImage, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="file"; filename="image"`)
+	header.Set("Content-Type", mimeType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, err
+	}
+	if alt != "" {
+		if err := writer.WriteField("description", truncateRunes(alt, mastodonMaxAltText)); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	var media mastodonMedia
+	if err := m.call(ctx, http.MethodPost, "/api/v2/media", &body, writer.FormDataContentType(), "", &media); err != nil {
+		return nil, fmt.Errorf("failed to upload image: %w", err)
+	}
+
+	deadline := time.Now().Add(mediaProcessingTimeout)
+	for media.URL == nil {
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("image %s is still processing", media.ID)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+		}
+		if err := m.call(ctx, http.MethodGet, "/api/v1/media/"+url.PathEscape(media.ID), nil, "", "", &media); err != nil {
+			return nil, fmt.Errorf("failed to check image %s: %w", media.ID, err)
+		}
+	}
+	return &uploadedImage{ID: media.ID, Alt: alt}, nil
+}
+
+// linkCards is false, Mastodon builds link previews from the page itself
+func (m *mastodon) linkCards() bool {
+	return false
+}
+
+// createPost posts a status; the key is sent as the Idempotency-Key, so a retried request
+// returns the status already posted
+func (m *mastodon) createPost(ctx context.Context, p post, images []*uploadedImage, thumb *uploadedImage, root, parent *postRef, key string) (*postRef, error) {
+	request := map[string]any{"status": p.Text}
+	if len(images) > 0 {
+		ids := make([]string, len(images))
+		for i, image := range images {
+			ids[i] = image.ID
+		}
+		request["media_ids"] = ids
+	}
+	if parent != nil {
+		request["in_reply_to_id"] = parent.ID
+	}
+	if m.visibility != "" {
+		request["visibility"] = m.visibility
+	}
+	if m.language != "" {
+		request["language"] = m.language
+	}
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal status: %w", err)
+	}
+
+	var status mastodonStatus
+	if err := m.call(ctx, http.MethodPost, "/api/v1/statuses", bytes.NewReader(data), "application/json", key, &status); err != nil {
+		return nil, err
+	}
+	return &postRef{ID: status.ID, URL: status.URL}, nil
+}
+
+func (m *mastodon) deletePost(ctx context.Context, id string) error {
+	err := m.call(ctx, http.MethodDelete, "/api/v1/statuses/"+url.PathEscape(id), nil, "", "", nil)
+	if errors.Is(err, errNotFound) {
+		// Already deleted
+		return nil
+	}
+	return err
+}
+
+// call sends an authenticated request to the instance and decodes the JSON response into out
+func (m *mastodon) call(ctx context.Context, method, path string, body io.Reader, contentType, idempotencyKey string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, m.instanceURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+m.accessToken)
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call Mastodon: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		message := string(data)
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			message = apiErr.Error
+		}
+		switch resp.StatusCode {
+		case http.StatusUnauthorized:
+			return fmt.Errorf("%w: Mastodon rejected the access token: %s", publisher.ErrCredentialsExpired, message)
+		case http.StatusNotFound:
+			return publisher.NewError(publisher.ErrorCodeContentInvalid, fmt.Errorf("%w: %s", errNotFound, message))
+		}
+		return publisher.NewError(publisher.CodeForStatus(resp.StatusCode), fmt.Errorf("Mastodon API returned status %d: %s", resp.StatusCode, message))
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
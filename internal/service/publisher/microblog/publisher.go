@@ -0,0 +1,506 @@
+package microblog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/httpclient"
+)
+
+// Platforms pages are posted to
+const (
+	MastodonPlatformName = "mastodon"
+	BlueskyPlatformName  = "bluesky"
+)
+
+// postRef identifies a published post; CID is only set on Bluesky, where replies name the
+// version of the posts they reply to
+type postRef struct {
+	ID  string
+	CID string
+	URL string
+}
+
+// uploadedImage is an image uploaded for a post: a Mastodon media ID or a Bluesky blob
+type uploadedImage struct {
+	ID   string
+	Blob map[string]any
+	Alt  string
+}
+
+// network is the API of a microblog network
+type network interface {
+	// validate checks the account config; it must not make network calls
+	validate(config publisher.PublishConfig) error
+	// initialize signs in with the account
+	initialize(ctx context.Context, client *http.Client, config publisher.PublishConfig) error
+	// limit is how many characters a post may have
+	limit(config publisher.PublishConfig) int
+	length(text string) int
+	checkCredentials(ctx context.Context) error
+	uploadImage(ctx context.Context, data []byte, mimeType, alt string) (*uploadedImage, error)
+	// linkCards reports whether posts embed the preview card of their link themselves, with the
+	// thumbnail uploaded like an image, rather than the network building it from the page
+	linkCards() bool
+	// createPost posts a post replying to parent and root, which are nil for the first post;
+	// key identifies the post so a retried request does not post it twice
+	createPost(ctx context.Context, p post, images []*uploadedImage, thumb *uploadedImage, root, parent *postRef, key string) (*postRef, error)
+	deletePost(ctx context.Context, id string) error
+}
+
+// MicroblogPublisher posts pages to a microblog network, either as the title and summary linking
+// to the post on the canonical platform, or as a thread of the whole page. Microblogs have no
+// drafts, so drafts are threads kept in the workspace, for review, until they are published.
+type MicroblogPublisher struct {
+	logger       *zap.Logger
+	platformName string
+	network      network
+	client       *http.Client
+	mode         string
+	limit        int
+	workspaceDir string
+	// newNetwork creates the network client, credentials are verified on a client of their own
+	newNetwork func() network
+}
+
+// NewMastodonPublisher creates a publisher posting with the Mastodon REST API
+func NewMastodonPublisher(logger *zap.Logger) publisher.Publisher {
+	return newMicroblogPublisher(MastodonPlatformName, func() network { return &mastodon{} }, logger)
+}
+
+// NewBlueskyPublisher creates a publisher posting with the AT Protocol
+func NewBlueskyPublisher(logger *zap.Logger) publisher.Publisher {
+	return newMicroblogPublisher(BlueskyPlatformName, func() network { return &bluesky{} }, logger)
+}
+
+func newMicroblogPublisher(platformName string, newNetwork func() network, logger *zap.Logger) *MicroblogPublisher {
+	return &MicroblogPublisher{
+		logger:       logger,
+		platformName: platformName,
+		network:      newNetwork(),
+		client:       httpclient.New(),
+		newNetwork:   newNetwork,
+	}
+}
+
+func (p *MicroblogPublisher) GetPlatformName() string {
+	return p.platformName
+}
+
+func (p *MicroblogPublisher) Initialize(ctx context.Context, config publisher.PublishConfig) error {
+	if err := p.ValidateConfig(config); err != nil {
+		return err
+	}
+
+	p.mode = postMode(config)
+	p.limit = p.network.limit(config)
+	p.workspaceDir = config.Config["workspace_dir"]
+
+	var opts []httpclient.Option
+	if proxyURL := config.Config["proxy_url"]; proxyURL != "" {
+		parsed, err := httpclient.ParseProxy(proxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy_url: %w", err)
+		}
+		opts = append(opts, httpclient.WithProxy(parsed))
+	}
+	p.client = httpclient.New(opts...)
+
+	if err := os.MkdirAll(p.workspaceDir, 0755); err != nil {
+		return fmt.Errorf("failed to create workspace directory: %w", err)
+	}
+	if err := p.network.initialize(ctx, p.client, config); err != nil {
+		return err
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Microblog publisher initialized successfully",
+		zap.String("platform", p.platformName),
+		zap.String("mode", p.mode))
+	return nil
+}
+
+func (p *MicroblogPublisher) ValidateConfig(config publisher.PublishConfig) error {
+	if config.Config["workspace_dir"] == "" {
+		return fmt.Errorf("missing required config: workspace_dir")
+	}
+
+	switch mode := config.Config["mode"]; mode {
+	case "", ModeLink, ModeThread:
+	default:
+		return fmt.Errorf("invalid mode %q, expected %s or %s", mode, ModeLink, ModeThread)
+	}
+
+	return p.network.validate(config)
+}
+
+// VerifyCredentials verifies the account can still post, signed in on a publisher of its own so
+// the session publishes use is left alone
+func (p *MicroblogPublisher) VerifyCredentials(ctx context.Context, config publisher.PublishConfig) error {
+	probe := newMicroblogPublisher(p.platformName, p.newNetwork, p.logger)
+	if err := probe.Initialize(ctx, config); err != nil {
+		return err
+	}
+	return probe.network.checkCredentials(ctx)
+}
+
+// TransformContent turns the page into a thread; the content becomes the thread as JSON
+func (p *MicroblogPublisher) TransformContent(ctx context.Context, content publisher.PublishContent) (*publisher.PublishContent, error) {
+	defer publisher.TimeStage(ctx, models.StageTransform)()
+	doc, err := content.ContentDocument()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse content: %w", err)
+	}
+
+	t := buildThread(content, doc, p.mode, p.limit, p.network.length)
+	encoded, err := t.encode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode thread: %w", err)
+	}
+
+	result := content
+	result.Content = encoded
+	result.Document = nil
+	result.Resources = nil
+	for _, post := range t.Posts {
+		for _, image := range post.Images {
+			result.Resources = append(result.Resources, publisher.Resource{
+				ID:       fmt.Sprintf("%s_image_%d", p.platformName, len(result.Resources)+1),
+				Type:     publisher.ResourceTypeImage,
+				URL:      image.URL,
+				Metadata: map[string]string{"alt": image.Alt},
+			})
+		}
+	}
+	result.Metadata = make(map[string]string)
+	for k, v := range content.Metadata {
+		result.Metadata[k] = v
+	}
+	return &result, nil
+}
+
+// ProcessResources leaves images where they are; they are uploaded with the post they belong to,
+// since both networks attach uploads to a single post
+func (p *MicroblogPublisher) ProcessResources(ctx context.Context, content *publisher.PublishContent, config publisher.PublishConfig) error {
+	return nil
+}
+
+// SaveToDraft keeps the thread in the workspace until it is published
+func (p *MicroblogPublisher) SaveToDraft(ctx context.Context, content publisher.PublishContent, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	t, ok := decodeThread(content.Content)
+	if !ok {
+		transformed, err := p.TransformContent(ctx, content)
+		if err != nil {
+			return failed(err), nil
+		}
+		if t, ok = decodeThread(transformed.Content); !ok {
+			return failed(fmt.Errorf("content is not a transformed thread")), nil
+		}
+	}
+
+	encoded, err := t.encode()
+	if err != nil {
+		return failed(fmt.Errorf("failed to encode thread: %w", err)), nil
+	}
+	draftID := fmt.Sprintf("%s-%d", sanitizeID(content.ID), time.Now().UnixNano())
+	if err := os.WriteFile(p.draftPath(draftID), []byte(encoded), 0644); err != nil {
+		return failed(fmt.Errorf("failed to save draft: %w", err)), nil
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Microblog draft saved",
+		zap.String("platform", p.platformName),
+		zap.String("draft_id", draftID),
+		zap.Int("posts", len(t.Posts)))
+
+	return &publisher.PublishResult{
+		Success:   true,
+		PublishID: draftID,
+		URL:       "file://" + p.draftPath(draftID),
+		Metadata: map[string]string{
+			"draft_id": draftID,
+			"mode":     t.Mode,
+			"posts":    strconv.Itoa(len(t.Posts)),
+		},
+	}, nil
+}
+
+// Publish posts the thread of a draft, each post replying to the one before. The publish ID
+// lists the IDs of the posts, first to last, separated by commas.
+func (p *MicroblogPublisher) Publish(ctx context.Context, draftID string, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	t, err := p.loadDraft(draftID)
+	if err != nil {
+		return failed(err), nil
+	}
+
+	result, err := p.postThread(ctx, draftID, t)
+	if err != nil {
+		return failed(err), nil
+	}
+
+	if err := os.Remove(p.draftPath(draftID)); err != nil {
+		publisher.Logger(ctx, p.logger).Warn("Failed to remove published microblog draft",
+			zap.String("platform", p.platformName),
+			zap.String("draft_id", draftID),
+			zap.Error(err))
+	}
+	result.Metadata["draft_id"] = draftID
+	return result, nil
+}
+
+func (p *MicroblogPublisher) PublishDirect(ctx context.Context, content publisher.PublishContent, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	draftResult, err := p.SaveToDraft(ctx, content, config)
+	if err != nil || !draftResult.Success {
+		return draftResult, err
+	}
+
+	if config.Config["auto_publish"] != "true" {
+		return draftResult, nil
+	}
+	return p.Publish(ctx, draftResult.PublishID, config)
+}
+
+// postThread uploads the images of each post and posts it as a reply to the post before
+func (p *MicroblogPublisher) postThread(ctx context.Context, draftID string, t *thread) (*publisher.PublishResult, error) {
+	var root, parent *postRef
+	var ids []string
+	images := 0
+	for i, post := range t.Posts {
+		endUpload := publisher.TimeStage(ctx, models.StageUpload)
+		uploaded, err := p.uploadImages(ctx, post.Images)
+		var thumb *uploadedImage
+		if err == nil && post.Link != nil && post.Link.Thumb != "" && p.network.linkCards() {
+			var thumbs []*uploadedImage
+			if thumbs, err = p.uploadImages(ctx, []postImage{{URL: post.Link.Thumb}}); len(thumbs) > 0 {
+				thumb = thumbs[0]
+			}
+		}
+		endUpload()
+		if err != nil {
+			return nil, p.partialError(ctx, ids, err)
+		}
+		images += len(uploaded)
+
+		endPublish := publisher.TimeStage(ctx, models.StagePublish)
+		ref, err := p.network.createPost(ctx, post, uploaded, thumb, root, parent, fmt.Sprintf("%s-%d", draftID, i))
+		endPublish()
+		if err != nil {
+			return nil, p.partialError(ctx, ids, fmt.Errorf("failed to post %d of %d: %w", i+1, len(t.Posts), err))
+		}
+
+		if root == nil {
+			root = ref
+		}
+		parent = ref
+		ids = append(ids, ref.ID)
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Microblog thread posted",
+		zap.String("platform", p.platformName),
+		zap.String("url", root.URL),
+		zap.Int("posts", len(ids)),
+		zap.Int("images", images))
+
+	return &publisher.PublishResult{
+		Success:     true,
+		PublishID:   strings.Join(ids, ","),
+		URL:         root.URL,
+		PublishedAt: time.Now(),
+		Metadata: map[string]string{
+			"mode":   t.Mode,
+			"posts":  strconv.Itoa(len(ids)),
+			"images": strconv.Itoa(images),
+		},
+	}, nil
+}
+
+// partialError removes the posts of a thread that failed halfway, so publishing it again does
+// not leave a second, broken thread behind
+func (p *MicroblogPublisher) partialError(ctx context.Context, ids []string, err error) error {
+	for _, id := range ids {
+		if deleteErr := p.network.deletePost(ctx, id); deleteErr != nil {
+			publisher.Logger(ctx, p.logger).Warn("Failed to remove post of an incomplete thread",
+				zap.String("platform", p.platformName),
+				zap.String("post_id", id),
+				zap.Error(deleteErr))
+		}
+	}
+	return err
+}
+
+// uploadImages uploads the images of a post with their alt text. Images that can't be
+// downloaded or that the network refuses are left out rather than failing the thread.
+func (p *MicroblogPublisher) uploadImages(ctx context.Context, images []postImage) ([]*uploadedImage, error) {
+	var uploaded []*uploadedImage
+	for _, image := range images {
+		data, mimeType, err := p.downloadImage(ctx, image.URL)
+		if err == nil {
+			var result *uploadedImage
+			if result, err = p.network.uploadImage(ctx, data, mimeType, image.Alt); err == nil {
+				uploaded = append(uploaded, result)
+				continue
+			}
+		}
+		if errors.Is(err, publisher.ErrCredentialsExpired) {
+			return nil, err
+		}
+		publisher.Logger(ctx, p.logger).Warn("Skipping image the post could not attach",
+			zap.String("platform", p.platformName),
+			zap.String("url", image.URL),
+			zap.Error(err))
+	}
+	return uploaded, nil
+}
+
+// downloadImage fetches an image, or reads it when it is a local file such as a generated
+// cover card, and detects its type from its bytes
+func (p *MicroblogPublisher) downloadImage(ctx context.Context, imageURL string) ([]byte, string, error) {
+	var data []byte
+	if strings.HasPrefix(imageURL, "http://") || strings.HasPrefix(imageURL, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create request: %w", err)
+		}
+
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to download image: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, "", fmt.Errorf("failed to download image, status: %d", resp.StatusCode)
+		}
+
+		if data, err = io.ReadAll(resp.Body); err != nil {
+			return nil, "", fmt.Errorf("failed to read image data: %w", err)
+		}
+	} else {
+		var err error
+		if data, err = os.ReadFile(imageURL); err != nil {
+			return nil, "", fmt.Errorf("failed to read image: %w", err)
+		}
+	}
+
+	mimeType := http.DetectContentType(data)
+	if !strings.HasPrefix(mimeType, "image/") {
+		return nil, "", fmt.Errorf("not an image: %s", mimeType)
+	}
+	return data, mimeType, nil
+}
+
+// GetPublishStatus reports drafts still waiting in the workspace; anything else is posted
+func (p *MicroblogPublisher) GetPublishStatus(ctx context.Context, publishID string, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	status := "published"
+	if _, err := p.loadDraft(publishID); err == nil {
+		status = "draft"
+	}
+	return &publisher.PublishResult{
+		Success:   true,
+		PublishID: publishID,
+		Metadata:  map[string]string{"status": status},
+	}, nil
+}
+
+// Unpublish discards a draft, or deletes every post of a posted thread
+func (p *MicroblogPublisher) Unpublish(ctx context.Context, publishID string, config publisher.PublishConfig) error {
+	if _, err := p.loadDraft(publishID); err == nil {
+		return p.Cleanup(ctx, publishID, config)
+	}
+
+	ids := strings.Split(publishID, ",")
+	// Delete replies first, so no post is left replying to a deleted one
+	for i := len(ids) - 1; i >= 0; i-- {
+		if err := p.network.deletePost(ctx, ids[i]); err != nil {
+			return fmt.Errorf("failed to delete post %s: %w", ids[i], err)
+		}
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Microblog thread deleted",
+		zap.String("platform", p.platformName),
+		zap.Int("posts", len(ids)))
+	return nil
+}
+
+// Cleanup removes a draft from the workspace
+func (p *MicroblogPublisher) Cleanup(ctx context.Context, publishID string, config publisher.PublishConfig) error {
+	if !validDraftID(publishID) {
+		return nil
+	}
+	if err := os.Remove(p.draftPath(publishID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove draft: %w", err)
+	}
+	return nil
+}
+
+// loadDraft reads the thread of a draft in the workspace
+func (p *MicroblogPublisher) loadDraft(draftID string) (*thread, error) {
+	if !validDraftID(draftID) {
+		return nil, fmt.Errorf("invalid draft ID %q", draftID)
+	}
+
+	data, err := os.ReadFile(p.draftPath(draftID))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("draft %s not found", draftID)
+		}
+		return nil, fmt.Errorf("failed to read draft: %w", err)
+	}
+
+	t, ok := decodeThread(string(data))
+	if !ok {
+		return nil, fmt.Errorf("draft %s has no posts", draftID)
+	}
+	return t, nil
+}
+
+func (p *MicroblogPublisher) draftPath(draftID string) string {
+	return filepath.Join(p.workspaceDir, draftID+".json")
+}
+
+// postMode is the configured way pages are posted, a link by default
+func postMode(config publisher.PublishConfig) string {
+	if config.Config["mode"] == ModeThread {
+		return ModeThread
+	}
+	return ModeLink
+}
+
+// validDraftID keeps publish IDs from reaching outside the workspace; posted threads have IDs
+// that are not file names
+func validDraftID(draftID string) bool {
+	return draftID != "" && draftID != "." && draftID != ".." && filepath.Base(draftID) == draftID &&
+		!strings.ContainsAny(draftID, ",:")
+}
+
+// sanitizeID keeps letters, digits, - and _ so a page ID can name a file
+func sanitizeID(id string) string {
+	sanitized := strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			return r
+		}
+		return '_'
+	}, id)
+	if sanitized == "" {
+		return "thread"
+	}
+	return sanitized
+}
+
+func failed(err error) *publisher.PublishResult {
+	return &publisher.PublishResult{
+		Success:  false,
+		Error:    err,
+		ErrorMsg: err.Error(),
+	}
+}
@@ -0,0 +1,388 @@
+package microblog
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ifuryst/ripple/internal/content"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// Ways a page is posted
+const (
+	// ModeLink posts the title and summary of the page with a link to the canonical post
+	ModeLink = "link"
+	// ModeThread posts the whole page as a thread
+	ModeThread = "thread"
+)
+
+const (
+	// maxImages is the most images Mastodon and Bluesky attach to a post
+	maxImages = 4
+	// maxThreadPosts keeps long pages from flooding timelines; longer threads are cut short and
+	// end with the link to the canonical post
+	maxThreadPosts = 20
+	// counterReserve is room left in thread posts for a "12/20" counter
+	counterReserve = 7
+)
+
+// lengthFunc counts the characters of a post the way the network does
+type lengthFunc func(text string) int
+
+// postImage is an image attached to a post, with its caption as the alt text
+type postImage struct {
+	URL string `json:"url"`
+	Alt string `json:"alt,omitempty"`
+}
+
+// linkCard is the preview of the canonical post shown under a post linking to it
+type linkCard struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Thumb       string `json:"thumb,omitempty"`
+}
+
+type post struct {
+	Text   string      `json:"text"`
+	Images []postImage `json:"images,omitempty"`
+	Link   *linkCard   `json:"link,omitempty"`
+}
+
+// thread is a page prepared as posts, each replying to the one before. It is the transformed
+// content of a page, and what a draft keeps until it is published.
+type thread struct {
+	Mode  string `json:"mode"`
+	Posts []post `json:"posts"`
+}
+
+// buildThread turns a page into posts that fit the character limit. Notes are always posted
+// whole, without a title.
+func buildThread(page publisher.PublishContent, doc *content.Document, mode string, limit int, length lengthFunc) *thread {
+	link := page.Metadata["canonical_url"]
+	tags := hashtags(page.Tags)
+	if mode == ModeLink && !page.IsNote() {
+		return &thread{Mode: ModeLink, Posts: []post{linkPost(page, doc, link, tags, limit, length)}}
+	}
+
+	var body []segment
+	if !page.IsNote() && strings.TrimSpace(page.Title) != "" {
+		body = append(body, segment{text: page.Title})
+	}
+	body = append(body, documentSegments(doc)...)
+
+	var closing []string
+	if link != "" {
+		closing = append(closing, link)
+	}
+	if tags != "" {
+		closing = append(closing, tags)
+	}
+	return &thread{Mode: ModeThread, Posts: packThread(body, strings.Join(closing, "\n\n"), limit, length)}
+}
+
+// linkPost is the title and summary of a page linking to its canonical post. The summary is
+// shortened to fit, then the hashtags are dropped and finally the title is shortened.
+func linkPost(page publisher.PublishContent, doc *content.Document, link, tags string, limit int, length lengthFunc) post {
+	summary := strings.TrimSpace(page.Summary)
+	if summary == "" {
+		summary = strings.TrimSpace(page.Metadata["description"])
+	}
+	if summary == "" {
+		for _, segment := range documentSegments(doc) {
+			if segment.text != "" {
+				summary = segment.text
+				break
+			}
+		}
+	}
+
+	title := strings.TrimSpace(page.Title)
+	compose := func(title, summary, tags string) string {
+		var parts []string
+		for _, part := range []string{title, summary, link, tags} {
+			if part != "" {
+				parts = append(parts, part)
+			}
+		}
+		return strings.Join(parts, "\n\n")
+	}
+
+	text := compose(title, summary, tags)
+	if length(text) > limit {
+		summary = fitText(summary, limit-length(compose(title, "", tags))-2, length)
+		text = compose(title, summary, tags)
+	}
+	if length(text) > limit {
+		tags = ""
+		text = compose(title, summary, tags)
+	}
+	if length(text) > limit {
+		title = fitText(title, limit-length(compose("", summary, ""))-2, length)
+		text = compose(title, summary, "")
+	}
+
+	p := post{Text: text}
+	cover := page.Metadata["cover_url"]
+	if link != "" {
+		p.Link = &linkCard{URL: link, Title: page.Title, Description: summary, Thumb: cover}
+	} else if cover != "" {
+		// Without a link there is no preview card to show the cover in
+		p.Images = []postImage{{URL: cover, Alt: page.Title}}
+	}
+	return p
+}
+
+// segment is a paragraph of a page, or an image with its caption
+type segment struct {
+	text  string
+	image *postImage
+}
+
+// documentSegments flattens a page into paragraphs and images. Links are kept as the URL after
+// the linked text, since posts have no inline links.
+func documentSegments(doc *content.Document) []segment {
+	var segments []segment
+	addText := func(text string) {
+		if text = strings.TrimSpace(text); text != "" {
+			segments = append(segments, segment{text: text})
+		}
+	}
+
+	for _, block := range doc.Blocks {
+		switch block.Type {
+		case content.BlockParagraph, content.BlockHeading:
+			addText(spanText(block.Text))
+		case content.BlockCallout:
+			text := strings.TrimSpace(spanText(block.Text))
+			if text != "" && block.Callout != nil && block.Callout.Icon != "" {
+				text = block.Callout.Icon + " " + text
+			}
+			addText(text)
+		case content.BlockQuote:
+			if text := strings.TrimSpace(spanText(block.Text)); text != "" {
+				addText("“" + text + "”")
+			}
+		case content.BlockCode:
+			addText(block.Code)
+		case content.BlockList:
+			if block.List == nil {
+				continue
+			}
+			items := make([]string, 0, len(block.List.Items))
+			for i, item := range block.List.Items {
+				marker := "•"
+				if block.List.Ordered {
+					marker = fmt.Sprintf("%d.", i+1)
+				}
+				items = append(items, marker+" "+strings.TrimSpace(spanText(item.Text)))
+			}
+			addText(strings.Join(items, "\n"))
+		case content.BlockImage:
+			if block.Image != nil && block.Image.URL != "" {
+				segments = append(segments, segment{image: &postImage{
+					URL: block.Image.URL,
+					Alt: strings.TrimSpace(content.PlainText(block.Image.Caption)),
+				}})
+			}
+		case content.BlockVideo:
+			if block.Video != nil {
+				addText(strings.TrimSpace(content.PlainText(block.Video.Caption) + " " + block.Video.URL))
+			}
+		case content.BlockFile:
+			if block.File != nil {
+				addText("📎 " + block.File.FileName() + " " + block.File.URL)
+			}
+		}
+	}
+	return segments
+}
+
+// spanText joins spans, adding the URL after linked text that isn't the URL itself
+func spanText(spans []content.Span) string {
+	var text strings.Builder
+	for _, span := range spans {
+		text.WriteString(span.Text)
+		if span.Link != "" && strings.TrimSpace(span.Text) != span.Link {
+			text.WriteString(" (" + span.Link + ")")
+		}
+	}
+	return text.String()
+}
+
+// packThread fills posts with whole paragraphs, splitting paragraphs longer than a post, and
+// attaches images to the post of the text before them. The closing text, the link and
+// hashtags, ends the last post. Posts are numbered when there is more than one.
+func packThread(segments []segment, closing string, limit int, length lengthFunc) []post {
+	budget := limit - counterReserve
+	var posts []post
+	var current *post
+	next := func() {
+		posts = append(posts, post{})
+		current = &posts[len(posts)-1]
+	}
+	next()
+
+	for _, segment := range segments {
+		if segment.image != nil {
+			if len(current.Images) == maxImages {
+				next()
+			}
+			current.Images = append(current.Images, *segment.image)
+			continue
+		}
+
+		for _, chunk := range splitText(segment.text, budget, length) {
+			if current.Text != "" && (len(current.Images) > 0 || length(current.Text+"\n\n"+chunk) > budget) {
+				next()
+			}
+			current.Text = joinText(current.Text, chunk)
+		}
+	}
+
+	if len(posts) > maxThreadPosts {
+		posts = posts[:maxThreadPosts-1]
+		last := &posts[len(posts)-1]
+		last.Text = strings.TrimRight(fitText(last.Text, budget-1, length), "…") + "…"
+		current = last
+	}
+
+	if closing != "" {
+		if (current.Text == "" && len(current.Images) == 0) || length(joinText(current.Text, closing)) <= budget {
+			current.Text = joinText(current.Text, closing)
+		} else {
+			next()
+			current.Text = closing
+		}
+	}
+
+	if len(posts) > 1 {
+		for i := range posts {
+			posts[i].Text = joinText(posts[i].Text, fmt.Sprintf("%d/%d", i+1, len(posts)))
+		}
+	}
+	return posts
+}
+
+func joinText(text, more string) string {
+	if text == "" {
+		return more
+	}
+	return text + "\n\n" + more
+}
+
+// sentencePattern matches a sentence with the space after it; CJK sentences end without one
+var sentencePattern = regexp.MustCompile(`(?s).*?(?:[.!?…]+(?:\s+|$)|[。！？]+|\n+|$)`)
+
+// splitters cut text into ever smaller pieces: sentences, words and finally characters
+var splitters = []func(string) []string{
+	func(text string) []string { return sentencePattern.FindAllString(text, -1) },
+	func(text string) []string { return strings.SplitAfter(text, " ") },
+	func(text string) []string { return strings.Split(text, "") },
+}
+
+// splitText splits text into chunks that fit the budget, at the end of sentences where it can,
+// then between words and only within a word that doesn't fit on its own
+func splitText(text string, budget int, length lengthFunc) []string {
+	return splitLevel(text, budget, length, 0)
+}
+
+func splitLevel(text string, budget int, length lengthFunc, level int) []string {
+	if length(text) <= budget || level == len(splitters) {
+		return []string{strings.TrimSpace(text)}
+	}
+
+	var chunks []string
+	var current string
+	flush := func() {
+		if chunk := strings.TrimSpace(current); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		current = ""
+	}
+
+	for _, piece := range splitters[level](text) {
+		if piece == "" {
+			continue
+		}
+		if length(strings.TrimSpace(piece)) > budget {
+			flush()
+			chunks = append(chunks, splitLevel(piece, budget, length, level+1)...)
+			continue
+		}
+		if length(strings.TrimSpace(current+piece)) > budget {
+			flush()
+		}
+		current += piece
+	}
+	flush()
+	return chunks
+}
+
+// fitText shortens text to fit the budget, ending it with "…"
+func fitText(text string, budget int, length lengthFunc) string {
+	if length(text) <= budget {
+		return text
+	}
+	if budget <= 1 {
+		return ""
+	}
+
+	runes := []rune(text)
+	cut := min(len(runes), budget)
+	for cut > 0 && length(strings.TrimSpace(string(runes[:cut]))+"…") > budget {
+		cut--
+	}
+	if cut == 0 {
+		return ""
+	}
+	return strings.TrimSpace(string(runes[:cut])) + "…"
+}
+
+// hashtags turns page tags into hashtags: words are joined in CamelCase, since hashtags end at
+// a space, and tags left without a letter are dropped
+func hashtags(tags []string) string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, tag := range tags {
+		words := strings.FieldsFunc(tag, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+		})
+		if len(words) > 1 {
+			for i, word := range words {
+				first, size := utf8.DecodeRuneInString(word)
+				words[i] = string(unicode.ToUpper(first)) + word[size:]
+			}
+		}
+
+		hashtag := strings.Join(words, "")
+		if !strings.ContainsFunc(hashtag, unicode.IsLetter) || seen[strings.ToLower(hashtag)] {
+			continue
+		}
+		seen[strings.ToLower(hashtag)] = true
+		result = append(result, "#"+hashtag)
+	}
+	return strings.Join(result, " ")
+}
+
+// decodeThread reads transformed content back as a thread; ok is false for content that is not
+// a thread
+func decodeThread(data string) (*thread, bool) {
+	var t thread
+	if err := json.Unmarshal([]byte(data), &t); err != nil || len(t.Posts) == 0 {
+		return nil, false
+	}
+	return &t, true
+}
+
+func (t *thread) encode() (string, error) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,39 @@
+package microblog
+
+import (
+	"strings"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// Validate checks that there is something to post: a title for the first post, except for notes,
+// and text. Posts are split and shortened to fit the character limit, so length is not checked.
+func (p *MicroblogPublisher) Validate(content publisher.PublishContent, config publisher.PublishConfig) []publisher.Violation {
+	var violations []publisher.Violation
+
+	if strings.TrimSpace(content.Title) == "" && !content.IsNote() {
+		violations = append(violations, publisher.Violation{
+			Field:   "title",
+			Rule:    "required",
+			Message: "title is required",
+		})
+	}
+
+	if postMode(config) == ModeLink && !content.IsNote() &&
+		(strings.TrimSpace(content.Summary) != "" || content.Metadata["canonical_url"] != "") {
+		return violations
+	}
+	doc, err := content.ContentDocument()
+	if err != nil {
+		// Unparseable content fails the transform with a clearer error
+		return violations
+	}
+	if len(documentSegments(doc)) == 0 {
+		violations = append(violations, publisher.Violation{
+			Field:   "content",
+			Rule:    "required",
+			Message: "the page has no text or images to post",
+		})
+	}
+	return violations
+}
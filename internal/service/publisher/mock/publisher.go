@@ -0,0 +1,426 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// PlatformName is the platform name of the standalone mock publisher
+const PlatformName = "mock"
+
+// mockOutput is the file written for every draft and published post
+type mockOutput struct {
+	Platform    string            `json:"platform"`
+	ID          string            `json:"id"`
+	Title       string            `json:"title"`
+	Summary     string            `json:"summary"`
+	Tags        []string          `json:"tags"`
+	Author      string            `json:"author"`
+	PublishDate *time.Time        `json:"publish_date,omitempty"`
+	Metadata    map[string]string `json:"metadata"`
+	Images      []string          `json:"images"`
+	Content     json.RawMessage   `json:"content"`
+	Published   bool              `json:"published"`
+	WrittenAt   time.Time         `json:"written_at"`
+}
+
+// MockPublisher writes content to disk instead of calling a platform API. It fails on demand,
+// either randomly with the configured failure rate or for pages carrying the fail tag, so
+// retries, circuit breaking and the dashboard can be exercised without platform credentials.
+type MockPublisher struct {
+	logger       *zap.Logger
+	platformName string
+	outputDir    string
+	failureRate  float64
+	failTag      string
+	delay        time.Duration
+}
+
+// NewMockPublisher creates a mock publisher registered under platformName, which is either
+// PlatformName or, in sandbox mode, the name of the real platform it stands in for
+func NewMockPublisher(platformName string, logger *zap.Logger) publisher.Publisher {
+	return &MockPublisher{
+		logger:       logger,
+		platformName: platformName,
+	}
+}
+
+func (p *MockPublisher) GetPlatformName() string {
+	return p.platformName
+}
+
+func (p *MockPublisher) Initialize(ctx context.Context, config publisher.PublishConfig) error {
+	if err := p.ValidateConfig(config); err != nil {
+		return err
+	}
+
+	p.outputDir = filepath.Join(config.Config["output_dir"], p.platformName)
+	p.failureRate, _ = strconv.ParseFloat(config.Config["failure_rate"], 64)
+	p.failTag = config.Config["fail_tag"]
+	p.delay, _ = time.ParseDuration(config.Config["delay"])
+
+	if err := os.MkdirAll(p.outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	return nil
+}
+
+func (p *MockPublisher) ValidateConfig(config publisher.PublishConfig) error {
+	if config.Config["output_dir"] == "" {
+		return fmt.Errorf("missing required config: output_dir")
+	}
+
+	if rate := config.Config["failure_rate"]; rate != "" {
+		value, err := strconv.ParseFloat(rate, 64)
+		if err != nil || value < 0 || value > 1 {
+			return fmt.Errorf("invalid failure_rate %q, expected a number between 0 and 1", rate)
+		}
+	}
+
+	if delay := config.Config["delay"]; delay != "" {
+		if _, err := time.ParseDuration(delay); err != nil {
+			return fmt.Errorf("invalid delay %q: %w", delay, err)
+		}
+	}
+
+	return nil
+}
+
+// VerifyCredentials checks the output directory can be written to, the mock publisher has no
+// credentials; it stands in for the platform credentials in sandbox mode
+func (p *MockPublisher) VerifyCredentials(ctx context.Context, config publisher.PublishConfig) error {
+	outputDir := filepath.Join(config.Config["output_dir"], p.platformName)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	return nil
+}
+
+func (p *MockPublisher) TransformContent(ctx context.Context, content publisher.PublishContent) (*publisher.PublishContent, error) {
+	doc, err := content.ContentDocument()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse content: %w", err)
+	}
+
+	encoded, err := doc.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []publisher.Resource
+	for i, url := range doc.Images() {
+		resources = append(resources, publisher.Resource{
+			ID:   fmt.Sprintf("mock_img_%d", i+1),
+			Type: publisher.ResourceTypeImage,
+			URL:  url,
+		})
+	}
+
+	result := content
+	result.Content = encoded
+	result.Resources = resources
+
+	result.Metadata = make(map[string]string)
+	for k, v := range content.Metadata {
+		result.Metadata[k] = v
+	}
+
+	return &result, nil
+}
+
+func (p *MockPublisher) ProcessResources(ctx context.Context, content *publisher.PublishContent, config publisher.PublishConfig) error {
+	// Images are never uploaded, they keep pointing at their original URL
+	for i, resource := range content.Resources {
+		content.Resources[i].Metadata = map[string]string{
+			"original_url": resource.URL,
+			"uploaded_url": resource.URL,
+		}
+	}
+	return nil
+}
+
+func (p *MockPublisher) SaveToDraft(ctx context.Context, content publisher.PublishContent, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	if err := p.simulate(ctx, content); err != nil {
+		return &publisher.PublishResult{
+			Success:  false,
+			Error:    err,
+			ErrorMsg: err.Error(),
+		}, nil
+	}
+
+	transformed, err := p.TransformContent(ctx, content)
+	if err != nil {
+		return &publisher.PublishResult{
+			Success:  false,
+			Error:    err,
+			ErrorMsg: err.Error(),
+		}, nil
+	}
+
+	publishID := fmt.Sprintf("%s-%d", sanitizeID(content.ID), time.Now().UnixNano())
+	path, err := p.writeOutput(publishID, transformed, false)
+	if err != nil {
+		return &publisher.PublishResult{
+			Success:  false,
+			Error:    err,
+			ErrorMsg: err.Error(),
+		}, nil
+	}
+
+	p.logger.Info("Mock draft written",
+		zap.String("platform", p.platformName),
+		zap.String("title", content.Title),
+		zap.String("path", path))
+
+	return &publisher.PublishResult{
+		Success:   true,
+		PublishID: publishID,
+		URL:       "file://" + path,
+		Metadata: map[string]string{
+			"draft_id": publishID,
+			"path":     path,
+			"mock":     "true",
+		},
+	}, nil
+}
+
+func (p *MockPublisher) Publish(ctx context.Context, draftID string, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	path := p.outputPath(draftID)
+	output, err := p.readOutput(path)
+	if err != nil {
+		return &publisher.PublishResult{
+			Success:  false,
+			Error:    err,
+			ErrorMsg: err.Error(),
+		}, nil
+	}
+
+	output.Published = true
+	output.WrittenAt = time.Now()
+	if err := writeJSON(path, output); err != nil {
+		return &publisher.PublishResult{
+			Success:  false,
+			Error:    err,
+			ErrorMsg: err.Error(),
+		}, nil
+	}
+
+	return &publisher.PublishResult{
+		Success:     true,
+		PublishID:   draftID,
+		URL:         "file://" + path,
+		PublishedAt: output.WrittenAt,
+		Metadata: map[string]string{
+			"path": path,
+			"mock": "true",
+		},
+	}, nil
+}
+
+func (p *MockPublisher) PublishDirect(ctx context.Context, content publisher.PublishContent, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	draftResult, err := p.SaveToDraft(ctx, content, config)
+	if err != nil || !draftResult.Success {
+		return draftResult, err
+	}
+
+	result, err := p.Publish(ctx, draftResult.PublishID, config)
+	if err != nil || !result.Success {
+		return result, err
+	}
+
+	p.logger.Info("Mock post published",
+		zap.String("platform", p.platformName),
+		zap.String("title", content.Title),
+		zap.String("url", result.URL))
+
+	return result, nil
+}
+
+func (p *MockPublisher) GetPublishStatus(ctx context.Context, publishID string, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	output, err := p.readOutput(p.outputPath(publishID))
+	if err != nil {
+		return nil, err
+	}
+
+	status := "draft"
+	if output.Published {
+		status = "published"
+	}
+
+	return &publisher.PublishResult{
+		Success:     true,
+		PublishID:   publishID,
+		URL:         "file://" + p.outputPath(publishID),
+		PublishedAt: output.WrittenAt,
+		Metadata: map[string]string{
+			"status": status,
+			"mock":   "true",
+		},
+	}, nil
+}
+
+// RefreshMedia points the images of the written output at the current URLs of the page's
+// images, matched by their URL without the signed query
+func (p *MockPublisher) RefreshMedia(ctx context.Context, publishID string, content publisher.PublishContent, config publisher.PublishConfig) (*publisher.MediaRefresh, error) {
+	if err := p.simulate(ctx, content); err != nil {
+		return nil, err
+	}
+
+	path := p.outputPath(publishID)
+	output, err := p.readOutput(path)
+	if err != nil {
+		return nil, err
+	}
+	transformed, err := p.TransformContent(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+	var imageURLs []string
+	for _, resource := range transformed.Resources {
+		imageURLs = append(imageURLs, resource.URL)
+	}
+	fresh := publisher.FreshImages(imageURLs)
+
+	refresh := &publisher.MediaRefresh{}
+	body := string(output.Content)
+	for i, image := range output.Images {
+		freshURL, ok := fresh[publisher.MediaKey(image)]
+		if !ok || freshURL == image {
+			continue
+		}
+		output.Images[i] = freshURL
+		// The content is JSON, where URLs may be escaped
+		body = strings.ReplaceAll(body, jsonString(image), jsonString(freshURL))
+		body = strings.ReplaceAll(body, image, freshURL)
+		refresh.Refreshed++
+	}
+	if refresh.Refreshed == 0 {
+		return refresh, nil
+	}
+
+	output.Content = json.RawMessage(body)
+	if err := writeJSON(path, output); err != nil {
+		return nil, err
+	}
+	return refresh, nil
+}
+
+func (p *MockPublisher) Unpublish(ctx context.Context, publishID string, config publisher.PublishConfig) error {
+	if err := os.Remove(p.outputPath(publishID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove mock output: %w", err)
+	}
+	return nil
+}
+
+func (p *MockPublisher) Cleanup(ctx context.Context, publishID string, config publisher.PublishConfig) error {
+	return nil
+}
+
+// simulate waits for the configured delay and returns an error when the publish should fail
+func (p *MockPublisher) simulate(ctx context.Context, content publisher.PublishContent) error {
+	if p.delay > 0 {
+		select {
+		case <-time.After(p.delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if p.failTag != "" {
+		for _, tag := range content.Tags {
+			if strings.EqualFold(tag, p.failTag) {
+				return fmt.Errorf("mock failure: page is tagged %q", p.failTag)
+			}
+		}
+	}
+
+	if p.failureRate > 0 && rand.Float64() < p.failureRate {
+		return fmt.Errorf("mock failure: random failure with rate %.2f", p.failureRate)
+	}
+
+	return nil
+}
+
+func (p *MockPublisher) outputPath(publishID string) string {
+	return filepath.Join(p.outputDir, sanitizeID(publishID)+".json")
+}
+
+func (p *MockPublisher) writeOutput(publishID string, content *publisher.PublishContent, published bool) (string, error) {
+	var images []string
+	for _, resource := range content.Resources {
+		images = append(images, resource.URL)
+	}
+
+	output := mockOutput{
+		Platform:    p.platformName,
+		ID:          content.ID,
+		Title:       content.Title,
+		Summary:     content.Summary,
+		Tags:        content.Tags,
+		Author:      content.Author,
+		PublishDate: content.PublishDate,
+		Metadata:    content.Metadata,
+		Images:      images,
+		Content:     json.RawMessage(content.Content),
+		Published:   published,
+		WrittenAt:   time.Now(),
+	}
+
+	path := p.outputPath(publishID)
+	if err := writeJSON(path, output); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func (p *MockPublisher) readOutput(path string) (*mockOutput, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mock output: %w", err)
+	}
+
+	var output mockOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse mock output: %w", err)
+	}
+	return &output, nil
+}
+
+func writeJSON(path string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal mock output: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write mock output: %w", err)
+	}
+	return nil
+}
+
+// sanitizeID keeps IDs safe to use as file names
+func sanitizeID(id string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == '.' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, id)
+}
+
+// jsonString is a string as it appears escaped inside a JSON string
+func jsonString(value string) string {
+	data, _ := json.Marshal(value)
+	return string(data[1 : len(data)-1])
+}
@@ -0,0 +1,41 @@
+package publisher
+
+import "context"
+
+// NoteCard is one image of an image-centric note, such as a Xiaohongshu note: the cover card
+// with the title, or a card of text
+type NoteCard struct {
+	Cover  bool     `json:"cover"`
+	Title  string   `json:"title"`
+	Text   string   `json:"text,omitempty"`
+	Author string   `json:"author,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	// Page and Pages number the card within the note, from 1
+	Page  int `json:"page"`
+	Pages int `json:"pages"`
+}
+
+// CardRenderer renders note cards as PNG images into a directory and returns their paths in
+// the order of the cards
+type CardRenderer interface {
+	RenderNoteCards(ctx context.Context, cards []NoteCard, dir string) ([]string, error)
+}
+
+// CardRendererUser is implemented by publishers that post rendered cards instead of the images
+// of a page
+type CardRendererUser interface {
+	SetCardRenderer(renderer CardRenderer)
+}
+
+// SetCardRenderer sets the renderer of note cards for the publishers that post cards
+func (m *Manager) SetCardRenderer(renderer CardRenderer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.cardRenderer = renderer
+	for _, publisher := range m.publishers {
+		if user, ok := publisher.(CardRendererUser); ok {
+			user.SetCardRenderer(renderer)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package publisher
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+// ErrPlatformPaused is returned when publishing to a paused platform. The publish is held as a
+// paused job and runs once the platform is resumed.
+var ErrPlatformPaused = errors.New("platform is paused")
+
+// PausePlatform holds every publish to a platform, e.g. while its credentials are renewed,
+// without disabling it in the configuration. The pause is stored, so it survives restarts.
+func (m *Manager) PausePlatform(platformName, reason string) error {
+	platformID := m.getPlatformID(platformName)
+	if platformID == 0 {
+		return fmt.Errorf("failed to get platform ID for %s", platformName)
+	}
+
+	now := time.Now()
+	return m.db.Model(&models.Platform{}).Where("id = ?", platformID).Updates(map[string]interface{}{
+		"paused":       true,
+		"pause_reason": reason,
+		"paused_at":    &now,
+	}).Error
+}
+
+// ResumePlatform lifts the pause of a platform and returns the jobs held while it was paused,
+// with their pages, so the caller can publish them
+func (m *Manager) ResumePlatform(platformName string) ([]models.DistributionJob, error) {
+	platformID := m.getPlatformID(platformName)
+	if platformID == 0 {
+		return nil, fmt.Errorf("failed to get platform ID for %s", platformName)
+	}
+
+	if err := m.db.Model(&models.Platform{}).Where("id = ?", platformID).Updates(map[string]interface{}{
+		"paused":       false,
+		"pause_reason": "",
+		"paused_at":    nil,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	var held []models.DistributionJob
+	if err := m.db.Preload("Page").
+		Where("platform_id = ? AND status = ?", platformID, models.JobPaused).
+		Order("created_at ASC").
+		Find(&held).Error; err != nil {
+		return nil, fmt.Errorf("failed to get paused jobs: %w", err)
+	}
+	return held, nil
+}
+
+// PausedPlatforms returns the platforms that are paused
+func (m *Manager) PausedPlatforms() ([]models.Platform, error) {
+	var platforms []models.Platform
+	if err := m.db.Where("paused = ?", true).Order("name").Find(&platforms).Error; err != nil {
+		return nil, err
+	}
+	return platforms, nil
+}
+
+// pausedPlatform returns the platform when it is paused
+func (m *Manager) pausedPlatform(platformID uint) (*models.Platform, bool) {
+	var platform models.Platform
+	if err := m.db.Select("id", "name", "paused", "pause_reason", "paused_at").First(&platform, platformID).Error; err != nil {
+		return nil, false
+	}
+	return &platform, platform.Paused
+}
+
+// holdJob records a paused job for a page on a paused platform, once per page and platform, and
+// returns the error the publish is refused with. Claiming the pair later resumes the held job.
+func (m *Manager) holdJob(page *models.NotionPage, platform *models.Platform, content *PublishContent) error {
+	pauseErr := fmt.Errorf("%w: %s", ErrPlatformPaused, platform.Name)
+	if platform.PauseReason != "" {
+		pauseErr = fmt.Errorf("%w: %s (%s)", ErrPlatformPaused, platform.Name, platform.PauseReason)
+	}
+
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		var locked models.NotionPage
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&locked, page.ID).Error; err != nil {
+			return fmt.Errorf("failed to lock page: %w", err)
+		}
+
+		var held models.DistributionJob
+		err := tx.Where("page_id = ? AND platform_id = ? AND status = ?", page.ID, platform.ID, models.JobPaused).First(&held).Error
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		job := &models.DistributionJob{
+			PageID:      page.ID,
+			PlatformID:  platform.ID,
+			Content:     content.Content,
+			ContentHash: ContentHash(content),
+		}
+		return TransitionJob(tx, job, models.JobPaused, pauseErr.Error())
+	})
+	if err != nil {
+		m.logger.Error("Failed to hold job for paused platform",
+			zap.String("platform", platform.Name),
+			zap.Uint("page_id", page.ID),
+			zap.Error(err))
+	} else {
+		m.logger.Info("Platform paused, holding publish",
+			zap.String("platform", platform.Name),
+			zap.Uint("page_id", page.ID))
+	}
+	return pauseErr
+}
@@ -0,0 +1,188 @@
+package promo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+const bufferAPIURL = "https://api.bufferapp.com/1"
+
+// buffer schedules promotions with the Buffer publish API, authenticated by an access token.
+// Every profile, a social account connected to Buffer, gets its own post so the text can be
+// rendered for its network.
+type buffer struct {
+	client      *http.Client
+	apiURL      string
+	accessToken string
+	// profileIDs limits posting to these profiles; empty posts to every profile
+	profileIDs []string
+}
+
+type bufferProfile struct {
+	ID      string `json:"id"`
+	Service string `json:"service"`
+}
+
+type bufferResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Updates []struct {
+		ID string `json:"id"`
+	} `json:"updates"`
+}
+
+func (b *buffer) validate(config publisher.PublishConfig) error {
+	if config.Config["access_token"] == "" {
+		return fmt.Errorf("missing required config: access_token")
+	}
+	return nil
+}
+
+func (b *buffer) initialize(client *http.Client, config publisher.PublishConfig) error {
+	b.client = client
+	b.apiURL = bufferAPIURL
+	b.accessToken = config.Config["access_token"]
+	b.profileIDs = splitList(config.Config["profile_ids"])
+	return nil
+}
+
+func (b *buffer) checkCredentials(ctx context.Context) error {
+	return b.call(ctx, http.MethodGet, "/user.json", nil, nil)
+}
+
+// channels lists the configured profiles, with the service of each as its network
+func (b *buffer) channels(ctx context.Context) ([]channel, error) {
+	var profiles []bufferProfile
+	if err := b.call(ctx, http.MethodGet, "/profiles.json", nil, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to list Buffer profiles: %w", err)
+	}
+
+	services := make(map[string]string, len(profiles))
+	var channels []channel
+	for _, profile := range profiles {
+		services[profile.ID] = profile.Service
+		if len(b.profileIDs) == 0 {
+			channels = append(channels, channel{ID: profile.ID, Network: profile.Service})
+		}
+	}
+	for _, id := range b.profileIDs {
+		service, ok := services[id]
+		if !ok {
+			return nil, publisher.NewError(publisher.ErrorCodeContentInvalid, fmt.Errorf("Buffer profile %s not found", id))
+		}
+		channels = append(channels, channel{ID: id, Network: service})
+	}
+	return channels, nil
+}
+
+// schedule adds an update to the queue of a profile. Posts with a link attach it with the title
+// and cover of the page as its preview.
+func (b *buffer) schedule(ctx context.Context, ch channel, text string, p *promo, when slot) (string, string, error) {
+	form := url.Values{}
+	form.Set("profile_ids[]", ch.ID)
+	form.Set("text", text)
+	form.Set("shorten", "false")
+	switch {
+	case when.now:
+		form.Set("now", "true")
+	case !when.at.IsZero():
+		form.Set("scheduled_at", when.at.UTC().Format("2006-01-02T15:04:05Z"))
+	}
+	switch {
+	case p.URL != "":
+		form.Set("media[link]", p.URL)
+		form.Set("media[title]", p.Title)
+		if p.Summary != "" {
+			form.Set("media[description]", p.Summary)
+		}
+		if p.Image != "" {
+			form.Set("media[picture]", p.Image)
+			form.Set("media[thumbnail]", p.Image)
+		}
+	case p.Image != "":
+		form.Set("media[photo]", p.Image)
+		form.Set("media[thumbnail]", p.Image)
+	}
+
+	var response bufferResponse
+	if err := b.call(ctx, http.MethodPost, "/updates/create.json", form, &response); err != nil {
+		return "", "", err
+	}
+	if len(response.Updates) == 0 {
+		return "", "", fmt.Errorf("Buffer created no update: %s", response.Message)
+	}
+	return response.Updates[0].ID, "", nil
+}
+
+// cancel deletes an update from the queue; updates already gone are fine
+func (b *buffer) cancel(ctx context.Context, ref string) error {
+	err := b.call(ctx, http.MethodPost, "/updates/"+url.PathEscape(ref)+"/destroy.json", url.Values{}, nil)
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// call calls the API with the access token; form is the body of POST requests
+func (b *buffer) call(ctx context.Context, method, path string, form url.Values, out any) error {
+	query := url.Values{}
+	query.Set("access_token", b.accessToken)
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, b.apiURL+path+"?"+query.Encode(), body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		// The URL carries the access token
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to call Buffer: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr bufferResponse
+		message := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			message = apiErr.Message
+		}
+		switch resp.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return fmt.Errorf("%w: Buffer rejected the access token: %s", publisher.ErrCredentialsExpired, message)
+		case http.StatusNotFound:
+			return publisher.NewError(publisher.ErrorCodeContentInvalid, fmt.Errorf("%w: %s", errNotFound, message))
+		}
+		return publisher.NewError(publisher.CodeForStatus(resp.StatusCode), fmt.Errorf("Buffer API returned status %d: %s", resp.StatusCode, message))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,361 @@
+package promo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/httpclient"
+)
+
+// Platforms promotions are scheduled with
+const (
+	BufferPlatformName    = "buffer"
+	TypefullyPlatformName = "typefully"
+)
+
+// When promotions are scheduled
+const (
+	// ScheduleQueue adds them to the next free slot of the posting schedule
+	ScheduleQueue = "queue"
+	// ScheduleNow posts them right away
+	ScheduleNow = "now"
+	// SchedulePublishDate posts them at the publish date of the page, or queues them when the
+	// date has passed
+	SchedulePublishDate = "publish_date"
+)
+
+// errNoDrafts is returned for draft-only routes, promotions are scheduled once the post they
+// link to is out and can still be edited in the queue of the app
+var errNoDrafts = errors.New("promotions have no drafts, they are scheduled when the page is published")
+
+// errNotFound is wrapped when a scheduled post does not exist (anymore)
+var errNotFound = errors.New("not found")
+
+// channel is a social account promotions are posted to, on a network such as twitter or
+// linkedin
+type channel struct {
+	ID      string
+	Network string
+	// Threaded channels split long texts into a thread, so the text is not held to the limit
+	// of the network
+	Threaded bool
+}
+
+// slot is when a promotion is posted: right away, at a time, or else in the next free slot of
+// the queue
+type slot struct {
+	now bool
+	at  time.Time
+}
+
+// scheduler is the API of an app scheduling social posts
+type scheduler interface {
+	// validate checks the config; it must not make network calls
+	validate(config publisher.PublishConfig) error
+	initialize(client *http.Client, config publisher.PublishConfig) error
+	checkCredentials(ctx context.Context) error
+	// channels lists the accounts each promotion is posted to
+	channels(ctx context.Context) ([]channel, error)
+	// schedule schedules the text on a channel, returning a reference to cancel the post by and
+	// its URL, when the app has one
+	schedule(ctx context.Context, ch channel, text string, p *promo, when slot) (ref string, url string, err error)
+	cancel(ctx context.Context, ref string) error
+}
+
+// PromoPublisher schedules posts promoting a page, rendered from templates per network, with a
+// social media scheduling app. The publish ID lists the references of the scheduled posts,
+// separated by commas.
+type PromoPublisher struct {
+	logger       *zap.Logger
+	platformName string
+	scheduler    scheduler
+	client       *http.Client
+	templates    *templates
+	schedule     string
+	// newScheduler creates the scheduling app client, credentials are verified on a client of their own
+	newScheduler func() scheduler
+}
+
+// NewBufferPublisher creates a publisher scheduling promotions on the profiles of a Buffer
+// account
+func NewBufferPublisher(logger *zap.Logger) publisher.Publisher {
+	return newPromoPublisher(BufferPlatformName, func() scheduler { return &buffer{} }, logger)
+}
+
+// NewTypefullyPublisher creates a publisher scheduling promotions as Typefully drafts
+func NewTypefullyPublisher(logger *zap.Logger) publisher.Publisher {
+	return newPromoPublisher(TypefullyPlatformName, func() scheduler { return &typefully{} }, logger)
+}
+
+func newPromoPublisher(platformName string, newScheduler func() scheduler, logger *zap.Logger) *PromoPublisher {
+	return &PromoPublisher{
+		logger:       logger,
+		platformName: platformName,
+		scheduler:    newScheduler(),
+		client:       httpclient.New(),
+		schedule:     ScheduleQueue,
+		newScheduler: newScheduler,
+	}
+}
+
+func (p *PromoPublisher) GetPlatformName() string {
+	return p.platformName
+}
+
+func (p *PromoPublisher) Initialize(ctx context.Context, config publisher.PublishConfig) error {
+	if err := p.ValidateConfig(config); err != nil {
+		return err
+	}
+
+	var opts []httpclient.Option
+	if proxyURL := config.Config["proxy_url"]; proxyURL != "" {
+		parsed, err := httpclient.ParseProxy(proxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy_url: %w", err)
+		}
+		opts = append(opts, httpclient.WithProxy(parsed))
+	}
+	p.client = httpclient.New(opts...)
+
+	if err := p.scheduler.initialize(p.client, config); err != nil {
+		return err
+	}
+	p.templates, _ = parseTemplates(config.Config["template"], config.Config["templates"])
+	if schedule := config.Config["schedule"]; schedule != "" {
+		p.schedule = schedule
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Promotion publisher initialized successfully",
+		zap.String("platform", p.platformName),
+		zap.String("schedule", p.schedule))
+	return nil
+}
+
+func (p *PromoPublisher) ValidateConfig(config publisher.PublishConfig) error {
+	if err := p.scheduler.validate(config); err != nil {
+		return err
+	}
+	switch config.Config["schedule"] {
+	case "", ScheduleQueue, ScheduleNow, SchedulePublishDate:
+	default:
+		return fmt.Errorf("invalid schedule %q: use %s, %s or %s", config.Config["schedule"], ScheduleQueue, ScheduleNow, SchedulePublishDate)
+	}
+	if _, err := parseTemplates(config.Config["template"], config.Config["templates"]); err != nil {
+		return err
+	}
+	return nil
+}
+
+// VerifyCredentials verifies the app still accepts the token, on a publisher of its own so the
+// client publishes use is left alone
+func (p *PromoPublisher) VerifyCredentials(ctx context.Context, config publisher.PublishConfig) error {
+	probe := newPromoPublisher(p.platformName, p.newScheduler, p.logger)
+	if err := probe.Initialize(ctx, config); err != nil {
+		return err
+	}
+	return probe.scheduler.checkCredentials(ctx)
+}
+
+// Validate checks the page has a title to promote
+func (p *PromoPublisher) Validate(content publisher.PublishContent, config publisher.PublishConfig) []publisher.Violation {
+	if strings.TrimSpace(content.Title) == "" {
+		return []publisher.Violation{{
+			Field:   "title",
+			Rule:    "required",
+			Message: "title is required",
+		}}
+	}
+	return nil
+}
+
+// TransformContent turns the page into the fields promotions are rendered from; the content
+// becomes the promotion as JSON. The text is rendered when it is scheduled, once the network
+// of each account is known.
+func (p *PromoPublisher) TransformContent(ctx context.Context, content publisher.PublishContent) (*publisher.PublishContent, error) {
+	defer publisher.TimeStage(ctx, models.StageTransform)()
+	doc, err := content.ContentDocument()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse content: %w", err)
+	}
+
+	encoded, err := buildPromo(content, doc).encode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode promotion: %w", err)
+	}
+
+	result := content
+	result.Content = encoded
+	result.Document = nil
+	result.Resources = nil
+	result.Metadata = make(map[string]string)
+	for k, v := range content.Metadata {
+		result.Metadata[k] = v
+	}
+	return &result, nil
+}
+
+// ProcessResources leaves the cover where it is, the app fetches it from its URL
+func (p *PromoPublisher) ProcessResources(ctx context.Context, content *publisher.PublishContent, config publisher.PublishConfig) error {
+	return nil
+}
+
+func (p *PromoPublisher) SaveToDraft(ctx context.Context, content publisher.PublishContent, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	return failed(publisher.NewError(publisher.ErrorCodeContentInvalid, errNoDrafts)), nil
+}
+
+func (p *PromoPublisher) Publish(ctx context.Context, draftID string, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	return failed(publisher.NewError(publisher.ErrorCodeContentInvalid, errNoDrafts)), nil
+}
+
+// PublishDirect schedules the promotion on every channel, with the text rendered for its
+// network. It succeeds when any channel got it, so publishing again does not promote the page
+// twice where it went through; the channels that failed are logged and counted in the metadata.
+func (p *PromoPublisher) PublishDirect(ctx context.Context, content publisher.PublishContent, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	promotion, ok := decodePromo(content.Content)
+	if !ok {
+		transformed, err := p.TransformContent(ctx, content)
+		if err != nil {
+			return failed(err), nil
+		}
+		if promotion, ok = decodePromo(transformed.Content); !ok {
+			return failed(fmt.Errorf("content is not a transformed promotion")), nil
+		}
+	}
+	if p.templates == nil {
+		return failed(fmt.Errorf("%s publisher is not initialized", p.platformName)), nil
+	}
+
+	defer publisher.TimeStage(ctx, models.StagePublish)()
+	channels, err := p.scheduler.channels(ctx)
+	if err != nil {
+		return failed(err), nil
+	}
+
+	when := p.slot(promotion)
+	var refs []string
+	var errs []error
+	postURL := ""
+	for _, ch := range channels {
+		text, err := p.templates.render(promotion, ch)
+		if err == nil {
+			var ref, url string
+			if ref, url, err = p.scheduler.schedule(ctx, ch, text, promotion, when); err == nil {
+				refs = append(refs, ref)
+				if postURL == "" {
+					postURL = url
+				}
+				continue
+			}
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", ch.ID, err))
+		publisher.Logger(ctx, p.logger).Warn("Failed to schedule promotion",
+			zap.String("platform", p.platformName),
+			zap.String("channel", ch.ID),
+			zap.String("network", ch.Network),
+			zap.Error(err))
+	}
+	if len(refs) == 0 {
+		if len(errs) == 0 {
+			return failed(fmt.Errorf("no %s channels to post to", p.platformName)), nil
+		}
+		return failed(errors.Join(errs...)), nil
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Promotion scheduled",
+		zap.String("platform", p.platformName),
+		zap.String("title", promotion.Title),
+		zap.String("link", promotion.URL),
+		zap.Int("scheduled", len(refs)),
+		zap.Int("failed", len(errs)))
+
+	metadata := map[string]string{
+		"link":      promotion.URL,
+		"schedule":  p.schedule,
+		"scheduled": strconv.Itoa(len(refs)),
+		"failed":    strconv.Itoa(len(errs)),
+	}
+	if !when.at.IsZero() {
+		metadata["scheduled_at"] = when.at.Format(time.RFC3339)
+	}
+	return &publisher.PublishResult{
+		Success:     true,
+		PublishID:   strings.Join(refs, ","),
+		URL:         postURL,
+		PublishedAt: time.Now(),
+		Metadata:    metadata,
+	}, nil
+}
+
+// slot resolves the configured schedule for a promotion
+func (p *PromoPublisher) slot(promotion *promo) slot {
+	switch p.schedule {
+	case ScheduleNow:
+		return slot{now: true}
+	case SchedulePublishDate:
+		if promotion.PublishDate != nil && promotion.PublishDate.After(time.Now()) {
+			return slot{at: *promotion.PublishDate}
+		}
+	}
+	return slot{}
+}
+
+// GetPublishStatus reports the promotion as scheduled, the apps post it on their own
+func (p *PromoPublisher) GetPublishStatus(ctx context.Context, publishID string, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	return &publisher.PublishResult{
+		Success:   true,
+		PublishID: publishID,
+		Metadata:  map[string]string{"status": "scheduled"},
+	}, nil
+}
+
+// Unpublish cancels the scheduled posts of a promotion
+func (p *PromoPublisher) Unpublish(ctx context.Context, publishID string, config publisher.PublishConfig) error {
+	refs := strings.Split(publishID, ",")
+	for _, ref := range refs {
+		if err := p.scheduler.cancel(ctx, ref); err != nil {
+			return fmt.Errorf("failed to cancel post %s: %w", ref, err)
+		}
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Promotion cancelled",
+		zap.String("platform", p.platformName),
+		zap.Int("posts", len(refs)))
+	return nil
+}
+
+// Cleanup has nothing to remove, promotions keep no files
+func (p *PromoPublisher) Cleanup(ctx context.Context, publishID string, config publisher.PublishConfig) error {
+	return nil
+}
+
+func failed(err error) *publisher.PublishResult {
+	return &publisher.PublishResult{
+		Success:  false,
+		Error:    err,
+		ErrorMsg: err.Error(),
+	}
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, errNotFound)
+}
+
+// splitList splits a comma separated config value, dropping empty entries
+func splitList(value string) []string {
+	var entries []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
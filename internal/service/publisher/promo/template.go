@@ -0,0 +1,265 @@
+package promo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ifuryst/ripple/internal/content"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// defaultTemplate is the promotion text unless a template is configured; lines left empty by
+// missing fields are dropped
+const defaultTemplate = "{{.Title}}\n\n{{.Summary}}\n\n{{.URL}}\n\n{{.Hashtags}}"
+
+// linkLength is what X and Mastodon count a link as, whatever its length
+const linkLength = 23
+
+// networkLimits are the character limits of the networks promotions are posted on; texts for
+// other networks are not shortened
+var networkLimits = map[string]int{
+	"twitter":   280,
+	"mastodon":  500,
+	"bluesky":   300,
+	"threads":   500,
+	"linkedin":  3000,
+	"instagram": 2200,
+	"facebook":  63206,
+}
+
+// shortLinkNetworks count every link as linkLength characters
+var shortLinkNetworks = map[string]bool{
+	"twitter":  true,
+	"mastodon": true,
+}
+
+var (
+	urlPattern        = regexp.MustCompile(`https?://\S+`)
+	blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// promo is what promotion texts are rendered from: the title and summary of a page, and the link
+// to its canonical post. It is the transformed content of a page.
+type promo struct {
+	Title    string   `json:"title"`
+	Summary  string   `json:"summary,omitempty"`
+	URL      string   `json:"url,omitempty"`
+	Image    string   `json:"image,omitempty"`
+	Author   string   `json:"author,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Hashtags string   `json:"hashtags,omitempty"`
+	// PublishDate is when the page goes out, promotions can be scheduled for it
+	PublishDate *time.Time `json:"publish_date,omitempty"`
+}
+
+// templateData is what templates see: the fields of the promotion, and the network the text
+// is rendered for
+type templateData struct {
+	*promo
+	Network string
+}
+
+// buildPromo takes the summary of the page, else its description or first paragraph. Only
+// covers with a public URL are kept, the scheduling apps fetch them.
+func buildPromo(page publisher.PublishContent, doc *content.Document) *promo {
+	summary := strings.TrimSpace(page.Summary)
+	if summary == "" {
+		summary = strings.TrimSpace(page.Metadata["description"])
+	}
+	if summary == "" && doc != nil {
+		for _, block := range doc.Blocks {
+			if block.Type != content.BlockParagraph {
+				continue
+			}
+			if text := strings.TrimSpace(content.PlainText(block.Text)); text != "" {
+				summary = text
+				break
+			}
+		}
+	}
+
+	p := &promo{
+		Title:       strings.TrimSpace(page.Title),
+		Summary:     summary,
+		URL:         page.Metadata["canonical_url"],
+		Author:      page.Author,
+		Tags:        page.Tags,
+		Hashtags:    hashtags(page.Tags),
+		PublishDate: page.PublishDate,
+	}
+	if cover := page.Metadata["cover_url"]; strings.HasPrefix(cover, "http://") || strings.HasPrefix(cover, "https://") {
+		p.Image = cover
+	}
+	return p
+}
+
+func (p *promo) encode() (string, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// decodePromo reads content that is already a promotion, reporting false for page content that
+// still has to be transformed
+func decodePromo(data string) (*promo, bool) {
+	var p promo
+	if err := json.Unmarshal([]byte(data), &p); err != nil || p.Title == "" {
+		return nil, false
+	}
+	return &p, true
+}
+
+// templates are the parsed promotion templates of a publisher: the default one and those
+// overriding it for a network, such as twitter or linkedin
+type templates struct {
+	fallback *template.Template
+	networks map[string]*template.Template
+}
+
+var templateFuncs = template.FuncMap{
+	// truncate shortens text to n characters, e.g. {{truncate 100 .Summary}}
+	"truncate": func(n int, text string) string {
+		if n < 1 {
+			return ""
+		}
+		return truncateRunes(text, n)
+	},
+}
+
+// parseTemplates parses the default template and the overrides per network, given as a YAML
+// mapping of network names to templates
+func parseTemplates(fallback, overrides string) (*templates, error) {
+	if strings.TrimSpace(fallback) == "" {
+		fallback = defaultTemplate
+	}
+	t := &templates{networks: make(map[string]*template.Template)}
+	var err error
+	if t.fallback, err = template.New("template").Funcs(templateFuncs).Parse(fallback); err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+
+	if strings.TrimSpace(overrides) == "" {
+		return t, nil
+	}
+	var sources map[string]string
+	if err := yaml.Unmarshal([]byte(overrides), &sources); err != nil {
+		return nil, fmt.Errorf("invalid templates: %w", err)
+	}
+	for network, source := range sources {
+		network = strings.ToLower(strings.TrimSpace(network))
+		if t.networks[network], err = template.New(network).Funcs(templateFuncs).Parse(source); err != nil {
+			return nil, fmt.Errorf("invalid template for %s: %w", network, err)
+		}
+	}
+	return t, nil
+}
+
+// render renders the promotion text for the network of a channel. Texts over the limit of the
+// network are rendered again with a shorter summary; when that is not enough the text is
+// rejected rather than cut by the network.
+func (t *templates) render(p *promo, ch channel) (string, error) {
+	network := strings.ToLower(ch.Network)
+	tmpl, ok := t.networks[network]
+	if !ok {
+		tmpl = t.fallback
+	}
+
+	text, err := execute(tmpl, p, network)
+	if err != nil {
+		return "", err
+	}
+	limit := networkLimits[network]
+	if limit == 0 || ch.Threaded || textLength(text, network) <= limit {
+		return text, nil
+	}
+
+	// Templates may shorten or repeat the summary, so it is cut until the text fits
+	shortened := *p
+	for over := textLength(text, network) - limit; over > 0; over = textLength(text, network) - limit {
+		summary := []rune(strings.TrimSpace(shortened.Summary))
+		if len(summary) <= over+1 {
+			break
+		}
+		shortened.Summary = truncateRunes(string(summary), len(summary)-over)
+		if text, err = execute(tmpl, &shortened, network); err != nil {
+			return "", err
+		}
+	}
+	if length := textLength(text, network); length > limit {
+		return "", publisher.NewError(publisher.ErrorCodeContentInvalid,
+			fmt.Errorf("promotion text for %s is %d characters, over the limit of %d", network, length, limit))
+	}
+	return text, nil
+}
+
+func execute(tmpl *template.Template, p *promo, network string) (string, error) {
+	var text bytes.Buffer
+	if err := tmpl.Execute(&text, templateData{promo: p, Network: network}); err != nil {
+		return "", publisher.NewError(publisher.ErrorCodeContentInvalid, fmt.Errorf("failed to render template: %w", err))
+	}
+	return cleanText(text.String()), nil
+}
+
+// cleanText drops trailing spaces and the blank lines fields left empty leave behind
+func cleanText(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRightFunc(line, unicode.IsSpace)
+	}
+	return strings.TrimSpace(blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// textLength counts characters the way a network does
+func textLength(text, network string) int {
+	if !shortLinkNetworks[network] {
+		return utf8.RuneCountInString(text)
+	}
+	links := urlPattern.FindAllString(text, -1)
+	return utf8.RuneCountInString(urlPattern.ReplaceAllString(text, "")) + len(links)*linkLength
+}
+
+// hashtags turns page tags into hashtags: words are joined in CamelCase, since hashtags end at
+// a space, and tags left without a letter are dropped
+func hashtags(tags []string) string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, tag := range tags {
+		words := strings.FieldsFunc(tag, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+		})
+		if len(words) > 1 {
+			for i, word := range words {
+				first, size := utf8.DecodeRuneInString(word)
+				words[i] = string(unicode.ToUpper(first)) + word[size:]
+			}
+		}
+
+		hashtag := strings.Join(words, "")
+		if !strings.ContainsFunc(hashtag, unicode.IsLetter) || seen[strings.ToLower(hashtag)] {
+			continue
+		}
+		seen[strings.ToLower(hashtag)] = true
+		result = append(result, "#"+hashtag)
+	}
+	return strings.Join(result, " ")
+}
+
+// truncateRunes shortens text to limit characters, ending with an ellipsis when it was cut
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:limit-1])) + "…"
+}
@@ -0,0 +1,156 @@
+package promo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+const (
+	typefullyAPIURL = "https://api.typefully.com/v1"
+	// typefullyNetwork is the network Typefully drafts are posted on, whose template they use
+	typefullyNetwork = "twitter"
+	// typefullyNextSlot schedules a draft in the next free slot of the queue
+	typefullyNextSlot = "next-free-slot"
+)
+
+// errNoDelete is returned when cancelling, the Typefully API can create drafts but not delete them
+var errNoDelete = errors.New("the Typefully API cannot delete drafts, delete them in Typefully")
+
+// typefully schedules promotions as drafts of the Typefully account an API key belongs to
+type typefully struct {
+	client *http.Client
+	apiURL string
+	apiKey string
+	// threadify lets Typefully split long texts into a thread
+	threadify bool
+}
+
+type typefullyDraft struct {
+	ID       json.Number `json:"id"`
+	ShareURL string      `json:"share_url"`
+}
+
+func (t *typefully) validate(config publisher.PublishConfig) error {
+	if config.Config["api_key"] == "" {
+		return fmt.Errorf("missing required config: api_key")
+	}
+	if value := config.Config["threadify"]; value != "" {
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("invalid threadify %q: %w", value, err)
+		}
+	}
+	return nil
+}
+
+func (t *typefully) initialize(client *http.Client, config publisher.PublishConfig) error {
+	t.client = client
+	t.apiURL = typefullyAPIURL
+	t.apiKey = config.Config["api_key"]
+	t.threadify, _ = strconv.ParseBool(config.Config["threadify"])
+	return nil
+}
+
+func (t *typefully) checkCredentials(ctx context.Context) error {
+	return t.call(ctx, http.MethodGet, "/drafts/recently-scheduled/", nil, nil)
+}
+
+// channels is the account of the API key, Typefully posts the drafts to its social accounts
+func (t *typefully) channels(ctx context.Context) ([]channel, error) {
+	return []channel{{ID: "typefully", Network: typefullyNetwork, Threaded: t.threadify}}, nil
+}
+
+// schedule creates a draft scheduled for the slot. Typefully has no immediate posting, so
+// posting now schedules the draft for the current time. The draft is shared, its share URL is
+// the URL of the post.
+func (t *typefully) schedule(ctx context.Context, ch channel, text string, p *promo, when slot) (string, string, error) {
+	scheduleDate := typefullyNextSlot
+	switch {
+	case when.now:
+		scheduleDate = time.Now().UTC().Format(time.RFC3339)
+	case !when.at.IsZero():
+		scheduleDate = when.at.UTC().Format(time.RFC3339)
+	}
+	request := map[string]any{
+		"content":       text,
+		"threadify":     t.threadify,
+		"share":         true,
+		"schedule-date": scheduleDate,
+	}
+
+	var draft typefullyDraft
+	if err := t.call(ctx, http.MethodPost, "/drafts/", request, &draft); err != nil {
+		return "", "", err
+	}
+	if draft.ID == "" {
+		return "", "", fmt.Errorf("Typefully returned no draft ID")
+	}
+	return draft.ID.String(), draft.ShareURL, nil
+}
+
+func (t *typefully) cancel(ctx context.Context, ref string) error {
+	return publisher.NewError(publisher.ErrorCodeContentInvalid, errNoDelete)
+}
+
+// call calls the API with the API key, sending request as JSON
+func (t *typefully) call(ctx context.Context, method, path string, request, out any) error {
+	var body io.Reader
+	if request != nil {
+		data, err := json.Marshal(request)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, t.apiURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-API-KEY", "Bearer "+t.apiKey)
+	req.Header.Set("Accept", "application/json")
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call Typefully: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Detail string `json:"detail"`
+		}
+		message := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Detail != "" {
+			message = apiErr.Detail
+		}
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return fmt.Errorf("%w: Typefully rejected the API key: %s", publisher.ErrCredentialsExpired, message)
+		}
+		return publisher.NewError(publisher.CodeForStatus(resp.StatusCode), fmt.Errorf("Typefully API returned status %d: %s", resp.StatusCode, message))
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package publisher
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+// ErrJobQuarantined is returned when a page and platform are quarantined after failing too often
+var ErrJobQuarantined = errors.New("publish quarantined after repeated failures")
+
+// PanicError is a panic recovered while publishing a job
+type PanicError struct {
+	Value interface{}
+	Stack string
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// recoverPanic turns a recovered panic value into a PanicError with the current stack
+func recoverPanic(value interface{}) *PanicError {
+	return &PanicError{Value: value, Stack: string(debug.Stack())}
+}
+
+// QuarantineFunc is called when a job is quarantined, with the number of consecutive failed
+// attempts and the error of the last one
+type QuarantineFunc func(job *models.DistributionJob, platform string, attempts int, cause error)
+
+// SetQuarantine quarantines a page and platform once it failed after attempts consecutive
+// publishes instead of retrying it on every sync; 0 disables quarantining
+func (m *Manager) SetQuarantine(after int, onQuarantine QuarantineFunc) {
+	m.quarantineAfter = after
+	m.onQuarantine = onQuarantine
+}
+
+// failJob marks a claimed job as failed, or as quarantined when its page and platform have
+// failed too often in a row. Jobs that were never claimed are simply marked as failed.
+func (m *Manager) failJob(job *models.DistributionJob, platformName string, cause error) {
+	job.ErrorCode = string(CodeOf(cause))
+	if m.quarantineAfter <= 0 || job.ID == 0 || job.Status != models.JobInProgress {
+		m.updateJobStatus(job, models.JobFailed, cause.Error())
+		return
+	}
+
+	attempts := m.consecutiveFailures(job) + 1
+	if attempts < m.quarantineAfter {
+		m.updateJobStatus(job, models.JobFailed, cause.Error())
+		return
+	}
+
+	m.logger.Error("Quarantining job after repeated failures",
+		zap.Uint("job_id", job.ID),
+		zap.Uint("page_id", job.PageID),
+		zap.String("platform", platformName),
+		zap.Int("attempts", attempts),
+		zap.Error(cause))
+
+	errorMsg := fmt.Sprintf("quarantined after %d failed attempts: %s", attempts, cause.Error())
+	if err := TransitionJob(m.db, job, models.JobQuarantined, errorMsg); err != nil {
+		m.logger.Error("Failed to quarantine job", zap.Uint("job_id", job.ID), zap.Error(err))
+		m.updateJobStatus(job, models.JobFailed, cause.Error())
+		return
+	}
+
+	if m.onQuarantine != nil {
+		m.onQuarantine(job, platformName, attempts, cause)
+	}
+}
+
+// consecutiveFailures counts the failed jobs of a job's page and platform since the last job
+// that did not fail
+func (m *Manager) consecutiveFailures(job *models.DistributionJob) int {
+	var statuses []string
+	if err := m.db.Model(&models.DistributionJob{}).
+		Where("page_id = ? AND platform_id = ? AND id <> ?", job.PageID, job.PlatformID, job.ID).
+		Order("created_at DESC").
+		Limit(m.quarantineAfter).
+		Pluck("status", &statuses).Error; err != nil {
+		m.logger.Warn("Failed to count previous failures", zap.Uint("job_id", job.ID), zap.Error(err))
+		return 0
+	}
+
+	count := 0
+	for _, status := range statuses {
+		if status != models.JobFailed {
+			break
+		}
+		count++
+	}
+	return count
+}
@@ -0,0 +1,225 @@
+package publisher
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+const (
+	// RoutingFieldTag matches pages by one of their tags
+	RoutingFieldTag = "tag"
+	// RoutingFieldContentType matches pages by one of their content types
+	RoutingFieldContentType = "content_type"
+)
+
+const (
+	// RouteSourceProperty means the platforms come from the page's Platform property
+	RouteSourceProperty = "property"
+	// RouteSourceRules means the platforms come from the routing rules the page matched
+	RouteSourceRules = "rules"
+	// RouteSourceAll means the page matched nothing and is published to every platform
+	RouteSourceAll = "all"
+)
+
+// RoutingRule sends pages with a tag or content type to platforms when their Platform
+// property is empty
+type RoutingRule struct {
+	Field     string   `json:"field"`
+	Value     string   `json:"value"`
+	Platforms []string `json:"platforms"`
+}
+
+// ParseRoutingRules parses comma separated field:value=platform+platform rules, e.g.
+// "tag:newsletter=substack,content_type:til=al-folio+wechat-official"
+func ParseRoutingRules(rules string) ([]RoutingRule, error) {
+	var parsed []RoutingRule
+	for _, entry := range splitList(rules) {
+		match, targets, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid routing rule %q: expected field:value=platforms", entry)
+		}
+		field, value, ok := strings.Cut(strings.TrimSpace(match), ":")
+		field = strings.TrimSpace(field)
+		if !ok || strings.TrimSpace(value) == "" {
+			return nil, fmt.Errorf("invalid routing rule %q: expected field:value=platforms", entry)
+		}
+		if field != RoutingFieldTag && field != RoutingFieldContentType {
+			return nil, fmt.Errorf("invalid routing rule %q: unknown field %s", entry, field)
+		}
+
+		rule := RoutingRule{Field: field, Value: strings.TrimSpace(value)}
+		for _, platform := range strings.Split(targets, "+") {
+			if platform = strings.TrimSpace(platform); platform != "" {
+				rule.Platforms = append(rule.Platforms, platform)
+			}
+		}
+		if len(rule.Platforms) == 0 {
+			return nil, fmt.Errorf("invalid routing rule %q: no platforms", entry)
+		}
+		parsed = append(parsed, rule)
+	}
+	return parsed, nil
+}
+
+func (r RoutingRule) String() string {
+	return fmt.Sprintf("%s:%s=%s", r.Field, r.Value, strings.Join(r.Platforms, "+"))
+}
+
+// Matches reports whether a page has the rule's tag or content type, ignoring case
+func (r RoutingRule) Matches(page *models.NotionPage) bool {
+	values := []string(page.Tags)
+	if r.Field == RoutingFieldContentType {
+		values = []string(page.ContentType)
+	}
+	for _, value := range values {
+		if strings.EqualFold(strings.TrimSpace(value), r.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// draftMarkers mark a platform as draft-only, e.g. "Substack (draft)"
+var draftMarkers = []string{"(draft)", "(草稿)"}
+
+// SplitDraftPlatform removes the draft-only marker from a platform name, reporting whether it
+// was marked
+func SplitDraftPlatform(notionPlatform string) (string, bool) {
+	trimmed := strings.TrimSpace(notionPlatform)
+	for _, marker := range draftMarkers {
+		if len(trimmed) >= len(marker) && strings.EqualFold(trimmed[len(trimmed)-len(marker):], marker) {
+			return strings.TrimSpace(trimmed[:len(trimmed)-len(marker)]), true
+		}
+	}
+	return notionPlatform, false
+}
+
+// Route is the platforms a page is published to and how they were chosen
+type Route struct {
+	Platforms []string `json:"platforms"`
+	// Drafts are the platforms only saved as drafts, to be promoted later
+	Drafts []string `json:"drafts,omitempty"`
+	Source string   `json:"source"`
+	// Rules are the routing rules the page matched
+	Rules []string `json:"rules,omitempty"`
+}
+
+// IsDraft reports whether the route only saves drafts on a platform
+func (r *Route) IsDraft(platformName string) bool {
+	for _, draft := range r.Drafts {
+		if draft == platformName {
+			return true
+		}
+	}
+	return false
+}
+
+// SetRoutingRules replaces the rules that route pages without a Platform property
+func (m *Manager) SetRoutingRules(rules []RoutingRule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.routingRules = rules
+}
+
+// SetFollowerPlatforms sets the platforms that follow a platform: pages published to the
+// platform, other than as a draft, are published to its followers too, e.g. announced once the
+// post is out
+func (m *Manager) SetFollowerPlatforms(platformName string, followers []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.followers = map[string][]string{}
+	if platformName != "" && len(followers) > 0 {
+		m.followers[platformName] = followers
+	}
+}
+
+// RoutePublishedPage routes a page like RoutePage, then adds the followers of its draft-only
+// platforms whose draft was promoted, given the status of the latest job of the page per
+// platform, so a promoted post is announced like one published directly
+func (m *Manager) RoutePublishedPage(page *models.NotionPage, status map[string]string) *Route {
+	route := m.RoutePage(page)
+	if len(route.Drafts) == 0 {
+		return route
+	}
+
+	m.mu.RLock()
+	followers := m.followers
+	m.mu.RUnlock()
+	for _, platformName := range route.Drafts {
+		if status[platformName] != models.JobCompleted {
+			continue
+		}
+		for _, follower := range followers[platformName] {
+			if m.mapPlatformName(follower) != "" && !slices.Contains(route.Platforms, follower) {
+				route.Platforms = append(route.Platforms, follower)
+			}
+		}
+	}
+	return route
+}
+
+// RoutePage returns the platforms a page is published to: the platforms of its Platform
+// property, else the platforms of every routing rule it matches, else all available platforms.
+// The followers of the platforms are added to the platforms of the property and the rules.
+func (m *Manager) RoutePage(page *models.NotionPage) *Route {
+	route := &Route{Platforms: []string{}, Source: RouteSourceProperty}
+	seen := make(map[string]bool)
+	add := func(notionPlatform string) {
+		name, draft := SplitDraftPlatform(notionPlatform)
+		if platformName := m.mapPlatformName(name); platformName != "" && !seen[platformName] {
+			seen[platformName] = true
+			route.Platforms = append(route.Platforms, platformName)
+			if draft {
+				route.Drafts = append(route.Drafts, platformName)
+			}
+		}
+	}
+
+	m.mu.RLock()
+	rules := m.routingRules
+	followers := m.followers
+	m.mu.RUnlock()
+	addFollowers := func() {
+		for _, platformName := range route.Platforms {
+			if route.IsDraft(platformName) {
+				continue
+			}
+			for _, follower := range followers[platformName] {
+				add(follower)
+			}
+		}
+	}
+
+	for _, notionPlatform := range page.Platforms {
+		add(notionPlatform)
+	}
+	if len(route.Platforms) > 0 {
+		addFollowers()
+		return route
+	}
+
+	route.Source = RouteSourceRules
+	for _, rule := range rules {
+		if !rule.Matches(page) {
+			continue
+		}
+		route.Rules = append(route.Rules, rule.String())
+		for _, platform := range rule.Platforms {
+			add(platform)
+		}
+	}
+	if len(route.Platforms) > 0 {
+		addFollowers()
+		return route
+	}
+
+	route.Source = RouteSourceAll
+	route.Rules = nil
+	for _, publisher := range m.GetAvailablePublishers() {
+		route.Platforms = append(route.Platforms, publisher.GetPlatformName())
+	}
+	return route
+}
@@ -0,0 +1,113 @@
+package publisher
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ifuryst/ripple/internal/content"
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+// Simulation is how ready a page is to publish on a platform, found by transforming and
+// validating it without publishing
+type Simulation struct {
+	Platform string `json:"platform"`
+	// Routed tells whether the page is routed to the platform, pages can be simulated on every
+	// enabled platform
+	Routed bool `json:"routed"`
+	Ready  bool `json:"ready"`
+	// OutputBytes is the size of the transformed content
+	OutputBytes int `json:"output_bytes"`
+	// Images estimates the images uploaded when publishing: the images of the page the
+	// transformed content still references
+	Images     int         `json:"images"`
+	Violations []Violation `json:"violations"`
+	// UnsupportedBlocks and DegradedBlocks count the blocks of the page dropped or kept only as
+	// plain paragraphs, per Notion block type
+	UnsupportedBlocks map[string]int `json:"unsupported_blocks,omitempty"`
+	DegradedBlocks    map[string]int `json:"degraded_blocks,omitempty"`
+	// Error is why the content could not be transformed, or the config is invalid
+	Error string `json:"error,omitempty"`
+}
+
+// Simulate transforms and validates a page for every enabled platform, sorted by name.
+// Publishers are not initialized and hooks do not run, so nothing is uploaded, published or
+// written. coverage is the block coverage of the page, or nil when it is not known.
+func (m *Manager) Simulate(ctx context.Context, page *models.NotionPage, coverage *content.Coverage) []*Simulation {
+	route := m.RoutePage(page)
+
+	configs := m.PlatformConfigs()
+	platforms := make([]string, 0, len(configs))
+	for platformName, config := range configs {
+		if config.Enabled {
+			platforms = append(platforms, platformName)
+		}
+	}
+	sort.Strings(platforms)
+
+	simulations := make([]*Simulation, 0, len(platforms))
+	for _, platformName := range platforms {
+		simulation := &Simulation{
+			Platform:   platformName,
+			Routed:     slices.Contains(route.Platforms, platformName),
+			Violations: []Violation{},
+		}
+		if coverage != nil {
+			simulation.UnsupportedBlocks = coverage.Unsupported
+			simulation.DegradedBlocks = coverage.Degraded
+		}
+		m.simulate(ctx, page, platformName, configs[platformName], simulation)
+		simulation.Ready = simulation.Error == "" && len(simulation.Violations) == 0
+		simulations = append(simulations, simulation)
+	}
+	return simulations
+}
+
+func (m *Manager) simulate(ctx context.Context, page *models.NotionPage, platformName string, config PublishConfig, simulation *Simulation) {
+	publisher, err := m.GetPublisher(platformName)
+	if err != nil {
+		simulation.Error = err.Error()
+		return
+	}
+	if err := publisher.ValidateConfig(config); err != nil {
+		simulation.Error = "invalid config: " + err.Error()
+		return
+	}
+
+	source := FromNotionPage(page)
+	if validator, ok := publisher.(ContentValidator); ok {
+		if found := validator.Validate(*source, config); len(found) > 0 {
+			simulation.Violations = found
+		}
+	}
+
+	transformed, err := publisher.TransformContent(ctx, *source)
+	if err != nil {
+		simulation.Error = err.Error()
+		return
+	}
+	simulation.OutputBytes = len(transformed.Content)
+	simulation.Images = estimateImages(source, transformed)
+}
+
+// estimateImages counts the distinct images of the page the transformed content references or
+// lists as resources. Images are matched by their media key, since transformers may escape the
+// signature in the query.
+func estimateImages(source, transformed *PublishContent) int {
+	images := make(map[string]bool)
+	for _, resource := range transformed.Resources {
+		if resource.Type == ResourceTypeImage && resource.URL != "" {
+			images[MediaKey(resource.URL)] = true
+		}
+	}
+	if doc, err := source.ContentDocument(); err == nil {
+		for _, imageURL := range doc.Images() {
+			if key := MediaKey(imageURL); strings.Contains(transformed.Content, key) {
+				images[key] = true
+			}
+		}
+	}
+	return len(images)
+}
@@ -0,0 +1,79 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"path"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+// SlugRegistry remembers which page each post path of a static-site repository belongs to, in
+// the post_slugs table. A nil registry lets every page write any path.
+type SlugRegistry struct {
+	db *gorm.DB
+}
+
+// SlugRegistryUser is implemented by publishers that write posts to paths derived from titles
+type SlugRegistryUser interface {
+	SetSlugRegistry(registry *SlugRegistry)
+}
+
+func NewSlugRegistry(db *gorm.DB) *SlugRegistry {
+	return &SlugRegistry{db: db}
+}
+
+// Reserve claims a post path of a repository for a page and reports whether the page may write
+// it. A path belongs to the first page reserving it; paths of posts published before the
+// registry belong to the page whose job published them. exists tells whether the file is in the
+// repository, a file no page published is never overwritten.
+func (r *SlugRegistry) Reserve(ctx context.Context, repository, platform, postPath, notionID string, exists bool) (bool, error) {
+	if r == nil || r.db == nil {
+		return true, nil
+	}
+	db := r.db.WithContext(ctx)
+
+	var reserved models.PostSlug
+	err := db.Where("repository = ? AND path = ?", repository, postPath).First(&reserved).Error
+	if err == nil {
+		return reserved.NotionID == notionID, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+
+	owner := notionID
+	if exists {
+		var owners []string
+		if err := db.Model(&models.DistributionJob{}).
+			Joins("JOIN notion_pages ON notion_pages.id = distribution_jobs.page_id").
+			Joins("JOIN platforms ON platforms.id = distribution_jobs.platform_id").
+			Where("platforms.name = ? AND distribution_jobs.publish_id = ?", platform, path.Base(postPath)).
+			Order("distribution_jobs.created_at DESC").
+			Limit(1).
+			Pluck("notion_pages.notion_id", &owners).Error; err != nil {
+			return false, err
+		}
+		if len(owners) == 0 {
+			return false, nil
+		}
+		owner = owners[0]
+	}
+
+	// Another publish may reserve the path at the same time; the first one keeps it
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.PostSlug{
+		Repository: repository,
+		Path:       postPath,
+		Platform:   platform,
+		NotionID:   owner,
+	}).Error; err != nil {
+		return false, err
+	}
+	if err := db.Where("repository = ? AND path = ?", repository, postPath).First(&reserved).Error; err != nil {
+		return false, err
+	}
+	return reserved.NotionID == notionID, nil
+}
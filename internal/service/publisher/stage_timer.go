@@ -0,0 +1,52 @@
+package publisher
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+type stageTimerKey struct{}
+
+// stageTimer adds up the time a publish spends in each stage
+type stageTimer struct {
+	mu        sync.Mutex
+	durations map[string]time.Duration
+}
+
+// withStageTimer returns a context that records the stages publishers time with TimeStage
+func withStageTimer(ctx context.Context) (context.Context, *stageTimer) {
+	timer := &stageTimer{durations: make(map[string]time.Duration)}
+	return context.WithValue(ctx, stageTimerKey{}, timer), timer
+}
+
+// TimeStage starts timing a stage of the job being published and returns the function ending it,
+// meant to be deferred or called once the stage is done. A stage timed several times, e.g. an
+// upload per segment, adds up. Outside of a publish it does nothing.
+func TimeStage(ctx context.Context, stage string) func() {
+	timer, ok := ctx.Value(stageTimerKey{}).(*stageTimer)
+	if !ok {
+		return func() {}
+	}
+
+	start := time.Now()
+	return func() {
+		timer.mu.Lock()
+		defer timer.mu.Unlock()
+		timer.durations[stage] += time.Since(start)
+	}
+}
+
+// Durations returns the milliseconds spent in each stage so far
+func (t *stageTimer) Durations() models.StageDurations {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	durations := make(models.StageDurations, len(t.durations))
+	for stage, duration := range t.durations {
+		durations[stage] = duration.Milliseconds()
+	}
+	return durations
+}
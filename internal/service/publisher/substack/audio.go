@@ -0,0 +1,259 @@
+package substack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/content"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/tts"
+)
+
+// Transcoding a narration usually takes a few seconds per minute of audio
+const (
+	audioTranscodePollInterval = 3 * time.Second
+	audioTranscodeTimeout      = 5 * time.Minute
+)
+
+type SubstackAudioUploadRequest struct {
+	Filename string `json:"filename"`
+	FileType string `json:"filetype"`
+	FileSize int    `json:"fileSize"`
+	PostID   int    `json:"postId"`
+}
+
+type SubstackAudioUploadResponse struct {
+	ID        int    `json:"id"`
+	UploadURL string `json:"upload_url"`
+}
+
+type SubstackAudioStatusResponse struct {
+	ID       int     `json:"id"`
+	State    string  `json:"state"`
+	Duration float64 `json:"duration"`
+	Error    string  `json:"error"`
+}
+
+// SubstackPodcastUpdateRequest only sets the podcast fields of a draft, so the body updated by
+// image uploads is left alone
+type SubstackPodcastUpdateRequest struct {
+	DraftPodcastUploadID *int `json:"draft_podcast_upload_id"`
+	DraftPodcastDuration *int `json:"draft_podcast_duration"`
+}
+
+// newNarrator creates the TTS provider configured for narrations, or nil when narration is off
+func newNarrator(config publisher.PublishConfig) (tts.Provider, error) {
+	if config.Config["tts_provider"] == "" {
+		return nil, nil
+	}
+	return tts.NewProvider(tts.Config{
+		Provider: config.Config["tts_provider"],
+		APIKey:   config.Config["tts_api_key"],
+		Model:    config.Config["tts_model"],
+		Voice:    config.Config["tts_voice"],
+		BaseURL:  config.Config["tts_base_url"],
+	})
+}
+
+// attachNarration generates an audio narration of the post and attaches it to the draft as
+// its podcast episode, returning the upload ID
+func (p *SubstackPublisher) attachNarration(ctx context.Context, draftID int, content publisher.PublishContent) (int, error) {
+	doc, err := content.ContentDocument()
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse content: %w", err)
+	}
+
+	text := narrationText(content.Title, doc)
+	audio, err := tts.Synthesize(ctx, p.narrator, text)
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate narration with %s: %w", p.narrator.Name(), err)
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Narration generated",
+		zap.Int("draft_id", draftID),
+		zap.String("provider", p.narrator.Name()),
+		zap.Int("characters", len([]rune(text))),
+		zap.Int("bytes", len(audio)))
+
+	upload, err := p.createAudioUpload(ctx, draftID, len(audio))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create audio upload: %w", err)
+	}
+
+	if err := p.putAudio(ctx, upload.UploadURL, audio); err != nil {
+		return 0, fmt.Errorf("failed to upload audio: %w", err)
+	}
+
+	status, err := p.transcodeAudio(ctx, draftID, upload.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	duration := int(math.Round(status.Duration))
+	request := SubstackPodcastUpdateRequest{
+		DraftPodcastUploadID: &upload.ID,
+		DraftPodcastDuration: &duration,
+	}
+	if _, err := p.audioRequest(ctx, http.MethodPut, fmt.Sprintf("/api/v1/drafts/%d", draftID), draftID, request); err != nil {
+		return 0, fmt.Errorf("failed to attach audio to draft: %w", err)
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Narration attached to draft",
+		zap.Int("draft_id", draftID),
+		zap.Int("upload_id", upload.ID),
+		zap.Int("duration_seconds", duration))
+
+	return upload.ID, nil
+}
+
+// narrationText is the part of the post worth reading aloud: the title and the prose, without
+// code, tables and images
+func narrationText(title string, doc *content.Document) string {
+	lines := []string{title}
+	for _, block := range doc.Blocks {
+		switch block.Type {
+		case content.BlockParagraph, content.BlockHeading, content.BlockQuote, content.BlockCallout:
+			lines = append(lines, content.PlainText(block.Text))
+		case content.BlockList:
+			if block.List != nil {
+				for _, item := range block.List.Items {
+					lines = append(lines, content.PlainText(item.Text))
+				}
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (p *SubstackPublisher) createAudioUpload(ctx context.Context, draftID, size int) (*SubstackAudioUploadResponse, error) {
+	request := SubstackAudioUploadRequest{
+		Filename: fmt.Sprintf("narration-%d.mp3", draftID),
+		FileType: "audio/mpeg",
+		FileSize: size,
+		PostID:   draftID,
+	}
+
+	body, err := p.audioRequest(ctx, http.MethodPost, "/api/v1/audio/upload", draftID, request)
+	if err != nil {
+		return nil, err
+	}
+
+	var upload SubstackAudioUploadResponse
+	if err := json.Unmarshal(body, &upload); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if upload.UploadURL == "" {
+		return nil, fmt.Errorf("no upload URL in response")
+	}
+	return &upload, nil
+}
+
+// putAudio uploads the audio to the storage URL handed out by Substack, which takes no cookies
+func (p *SubstackPublisher) putAudio(ctx context.Context, uploadURL string, audio []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, bytes.NewReader(audio))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "audio/mpeg")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("storage returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// transcodeAudio starts transcoding the upload and waits until Substack has finished it
+func (p *SubstackPublisher) transcodeAudio(ctx context.Context, draftID, uploadID int) (*SubstackAudioStatusResponse, error) {
+	if _, err := p.audioRequest(ctx, http.MethodPost, fmt.Sprintf("/api/v1/audio/upload/%d/transcode", uploadID), draftID, nil); err != nil {
+		return nil, fmt.Errorf("failed to start transcoding: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, audioTranscodeTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(audioTranscodePollInterval)
+	defer ticker.Stop()
+
+	for {
+		body, err := p.audioRequest(ctx, http.MethodGet, fmt.Sprintf("/api/v1/audio/upload/%d", uploadID), draftID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check transcoding: %w", err)
+		}
+
+		var status SubstackAudioStatusResponse
+		if err := json.Unmarshal(body, &status); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		switch status.State {
+		case "transcoded":
+			return &status, nil
+		case "error", "failed":
+			return nil, fmt.Errorf("transcoding failed: %s", status.Error)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("transcoding did not finish in time: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// audioRequest sends an authenticated request to the publication API from the draft editor
+func (p *SubstackPublisher) audioRequest(ctx context.Context, method, path string, draftID int, payload interface{}) ([]byte, error) {
+	var reader io.Reader
+	if payload != nil {
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("https://%s%s", p.domain, path), reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Cookie", p.sessionCookie())
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("Origin", fmt.Sprintf("https://%s", p.domain))
+	req.Header.Set("Referer", fmt.Sprintf("https://%s/publish/post/%d", p.domain, draftID))
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/138.0.0.0 Safari/537.36")
+
+	resp, err := p.doWithSession(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, statusError(resp.StatusCode, body)
+	}
+	return body, nil
+}
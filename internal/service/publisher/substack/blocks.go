@@ -0,0 +1,533 @@
+package substack
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ifuryst/ripple/internal/content"
+)
+
+// Substack-native block kinds that can be injected into a post
+const (
+	BlockSubscribe = "subscribe"
+	BlockShare     = "share"
+)
+
+// Injection positions; any other position is a number of top-level blocks to insert after
+const (
+	PositionStart  = "start"
+	PositionMiddle = "middle"
+	PositionEnd    = "end"
+)
+
+// BlockInjection places a Substack-native block at a position in the document
+type BlockInjection struct {
+	Kind     string
+	Position string
+}
+
+var (
+	// footnoteRefPattern matches footnote references like [1], [^1] or superscript digits
+	footnoteRefPattern = regexp.MustCompile(`\[\^?(\d+)\]|([⁰¹²³⁴⁵⁶⁷⁸⁹]+)`)
+	// footnoteDefPattern matches a footnote definition marker at the start of a block
+	footnoteDefPattern = regexp.MustCompile(`^\s*(?:\[\^?(\d+)\]:?|([⁰¹²³⁴⁵⁶⁷⁸⁹]+))\s*`)
+)
+
+var superscriptDigits = strings.NewReplacer(
+	"⁰", "0", "¹", "1", "²", "2", "³", "3", "⁴", "4",
+	"⁵", "5", "⁶", "6", "⁷", "7", "⁸", "8", "⁹", "9",
+)
+
+// ParseBlockInjections parses a spec like "subscribe:end,share:middle,subscribe:3"
+func ParseBlockInjections(spec string) ([]BlockInjection, error) {
+	var injections []BlockInjection
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kind, position, ok := strings.Cut(entry, ":")
+		if !ok {
+			position = PositionEnd
+		}
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		position = strings.ToLower(strings.TrimSpace(position))
+
+		if kind != BlockSubscribe && kind != BlockShare {
+			return nil, fmt.Errorf("unknown Substack block %q", kind)
+		}
+		switch position {
+		case PositionStart, PositionMiddle, PositionEnd:
+		default:
+			if n, err := strconv.Atoi(position); err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid position %q for Substack block %s", position, kind)
+			}
+		}
+
+		injections = append(injections, BlockInjection{Kind: kind, Position: position})
+	}
+	return injections, nil
+}
+
+// injectBlocks inserts the configured Substack-native blocks into the top-level content
+func injectBlocks(nodes []SubstackNode, injections []BlockInjection) []SubstackNode {
+	if len(injections) == 0 {
+		return nodes
+	}
+
+	// Resolve every position against the original document so injections don't shift each other
+	inserts := make(map[int][]SubstackNode)
+	for _, injection := range injections {
+		index := len(nodes)
+		switch injection.Position {
+		case PositionStart:
+			index = 0
+		case PositionMiddle:
+			index = len(nodes) / 2
+		case PositionEnd:
+		default:
+			if n, err := strconv.Atoi(injection.Position); err == nil && n < len(nodes) {
+				index = n
+			}
+		}
+		inserts[index] = append(inserts[index], newNativeBlock(injection.Kind))
+	}
+
+	result := make([]SubstackNode, 0, len(nodes)+len(injections))
+	for i := 0; i <= len(nodes); i++ {
+		result = append(result, inserts[i]...)
+		if i < len(nodes) {
+			result = append(result, nodes[i])
+		}
+	}
+	return result
+}
+
+func newNativeBlock(kind string) SubstackNode {
+	if kind == BlockShare {
+		return SubstackNode{
+			Type: "button",
+			Attrs: map[string]interface{}{
+				"url":    "%%share_url%%",
+				"text":   "Share",
+				"action": nil,
+				"class":  nil,
+			},
+		}
+	}
+
+	return SubstackNode{
+		Type: "subscribeWidget",
+		Attrs: map[string]interface{}{
+			"url":      "%%checkout_url%%",
+			"text":     "Subscribe",
+			"language": "en",
+		},
+		Content: []SubstackNode{
+			{
+				Type: "ctaCaption",
+				Content: []SubstackNode{
+					{
+						Type: "text",
+						Text: "Thanks for reading! Subscribe for free to receive new posts and support my work.",
+					},
+				},
+			},
+		},
+	}
+}
+
+// collectFootnotes finds footnote definitions, returning their converted content by number
+// and the indexes of the blocks so they can be left out of the body. Definitions are paragraphs
+// starting with a marker like "[^1]:", or the items of a list under a References heading,
+// numbered by their marker or else their position in a numbered list. The heading goes too when
+// nothing but footnotes is left under it.
+func (t *SubstackTransformer) collectFootnotes(blocks []content.Block) (map[int][]SubstackNode, map[int]bool) {
+	definitions := make(map[int][]SubstackNode)
+	definitionBlocks := make(map[int]bool)
+	// blockNumbers are the footnotes each definition block defines
+	blockNumbers := make(map[int][]int)
+
+	for i, block := range blocks {
+		if block.Type != content.BlockParagraph {
+			continue
+		}
+		number, nodes := t.footnoteDefinition(block.Text)
+		if number == 0 || len(nodes) == 0 {
+			continue
+		}
+		definitions[number] = nodes
+		definitionBlocks[i] = true
+		blockNumbers[i] = []int{number}
+	}
+
+	sections := referencesSections(blocks)
+	for _, section := range sections {
+		for _, i := range section.blocks {
+			if blocks[i].Type != content.BlockList || blocks[i].List == nil {
+				continue
+			}
+			items, ok := t.footnoteList(blocks[i].List)
+			if !ok || definesAny(definitions, items) {
+				continue
+			}
+			for number, nodes := range items {
+				definitions[number] = nodes
+				blockNumbers[i] = append(blockNumbers[i], number)
+			}
+			definitionBlocks[i] = true
+		}
+	}
+
+	// Only treat a block as a definition when the rest of the page actually references it; a
+	// list is kept as it is unless all of its footnotes are referenced
+	referenced := make(map[int]bool)
+	for i, block := range blocks {
+		if definitionBlocks[i] {
+			continue
+		}
+		for _, match := range footnoteRefPattern.FindAllStringSubmatch(blockText(block), -1) {
+			referenced[footnoteNumber(match)] = true
+		}
+	}
+	for i, numbers := range blockNumbers {
+		if allReferenced(numbers, referenced) {
+			continue
+		}
+		for _, number := range numbers {
+			delete(definitions, number)
+		}
+		delete(definitionBlocks, i)
+	}
+
+	for _, section := range sections {
+		if section.onlyFootnotes(blocks, definitionBlocks) {
+			definitionBlocks[section.heading] = true
+		}
+	}
+
+	return definitions, definitionBlocks
+}
+
+// footnoteDefinition returns the number and converted content of text starting with a footnote
+// definition marker, or 0 when it has none
+func (t *SubstackTransformer) footnoteDefinition(spans []content.Span) (int, []SubstackNode) {
+	nodes := t.renderSpans(spans)
+	if len(nodes) == 0 || nodes[0].Type != "text" {
+		return 0, nil
+	}
+
+	match := footnoteDefPattern.FindStringSubmatch(nodes[0].Text)
+	if match == nil {
+		return 0, nil
+	}
+	number := footnoteNumber(match)
+	if number == 0 {
+		return 0, nil
+	}
+
+	nodes[0].Text = nodes[0].Text[len(match[0]):]
+	if nodes[0].Text == "" {
+		nodes = nodes[1:]
+	}
+	return number, nodes
+}
+
+// footnoteList returns the footnotes of a list under a References heading. Items without a
+// marker are numbered by their position, so a bulleted list needs a marker on every item.
+func (t *SubstackTransformer) footnoteList(list *content.List) (map[int][]SubstackNode, bool) {
+	items := make(map[int][]SubstackNode, len(list.Items))
+	for i, item := range list.Items {
+		number, nodes := t.footnoteDefinition(item.Text)
+		if number == 0 {
+			if !list.Ordered {
+				return nil, false
+			}
+			number, nodes = i+1, t.renderSpans(item.Text)
+		}
+		if len(nodes) == 0 {
+			return nil, false
+		}
+		if _, duplicate := items[number]; duplicate {
+			return nil, false
+		}
+		items[number] = nodes
+	}
+	return items, len(items) > 0
+}
+
+func definesAny(definitions, items map[int][]SubstackNode) bool {
+	for number := range items {
+		if _, defined := definitions[number]; defined {
+			return true
+		}
+	}
+	return false
+}
+
+func allReferenced(numbers []int, referenced map[int]bool) bool {
+	for _, number := range numbers {
+		if !referenced[number] {
+			return false
+		}
+	}
+	return true
+}
+
+// referencesHeadingPattern matches the headings of sections that list the sources of a post
+var referencesHeadingPattern = regexp.MustCompile(`(?i)^\s*(references|notes|footnotes|sources|参考|参考资料|参考文献|参考链接|注释|脚注)\s*[:：]?\s*$`)
+
+// referencesSection is a References heading and the blocks up to the next heading
+type referencesSection struct {
+	heading int
+	blocks  []int
+}
+
+func referencesSections(blocks []content.Block) []referencesSection {
+	var sections []referencesSection
+	for i := 0; i < len(blocks); i++ {
+		if blocks[i].Type != content.BlockHeading || !referencesHeadingPattern.MatchString(content.PlainText(blocks[i].Text)) {
+			continue
+		}
+		section := referencesSection{heading: i}
+		for i+1 < len(blocks) && blocks[i+1].Type != content.BlockHeading {
+			i++
+			section.blocks = append(section.blocks, i)
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+// onlyFootnotes reports whether every block of the section with content became a footnote
+func (s referencesSection) onlyFootnotes(blocks []content.Block, definitionBlocks map[int]bool) bool {
+	converted := false
+	for _, i := range s.blocks {
+		switch {
+		case definitionBlocks[i]:
+			converted = true
+		case blocks[i].Type == content.BlockParagraph && strings.TrimSpace(content.PlainText(blocks[i].Text)) == "":
+			// Empty paragraphs are dropped from the body anyway
+		default:
+			return false
+		}
+	}
+	return converted
+}
+
+// footnoteLinkPattern matches the text of a link that is a footnote reference, like "1",
+// "[1]" or "[^1]", once superscript digits are replaced
+var footnoteLinkPattern = regexp.MustCompile(`^\s*\[?\^?(\d+)\]?\s*$`)
+
+// footnoteLinksAsMarkers turns footnote numbers linked to another block of the page, such as
+// an entry of its References section, into "[^1]" markers, which become footnote anchors. Only
+// the numbers defined reports true for are turned, or all of them when defined is nil. Changed
+// blocks are copied, so the document is left alone.
+func footnoteLinksAsMarkers(blocks []content.Block, defined func(number int) bool) []content.Block {
+	convert := func(spans []content.Span) []content.Span {
+		var converted []content.Span
+		for i, span := range spans {
+			if !isAnchorLink(span.Link) {
+				continue
+			}
+			match := footnoteLinkPattern.FindStringSubmatch(superscriptDigits.Replace(span.Text))
+			if match == nil {
+				continue
+			}
+			if number, _ := strconv.Atoi(match[1]); defined != nil && !defined(number) {
+				continue
+			}
+			if converted == nil {
+				converted = append([]content.Span(nil), spans...)
+			}
+			converted[i].Text = "[^" + match[1] + "]"
+			converted[i].Link = ""
+		}
+		if converted == nil {
+			return spans
+		}
+		return converted
+	}
+
+	result := make([]content.Block, len(blocks))
+	for i, block := range blocks {
+		block.Text = convert(block.Text)
+		if block.List != nil {
+			list := *block.List
+			list.Items = make([]content.ListItem, len(block.List.Items))
+			for j, item := range block.List.Items {
+				item.Text = convert(item.Text)
+				list.Items[j] = item
+			}
+			block.List = &list
+		}
+		if block.Table != nil {
+			table := *block.Table
+			table.Rows = make([][][]content.Span, len(block.Table.Rows))
+			for j, row := range block.Table.Rows {
+				cells := make([][]content.Span, len(row))
+				for k, cell := range row {
+					cells[k] = convert(cell)
+				}
+				table.Rows[j] = cells
+			}
+			block.Table = &table
+		}
+		result[i] = block
+	}
+	return result
+}
+
+// isAnchorLink reports whether a link points at a block of the page: a bare fragment, or a
+// Notion page link with one, which is how Notion links to blocks
+func isAnchorLink(link string) bool {
+	if strings.HasPrefix(link, "#") {
+		return len(link) > 1
+	}
+	parsed, err := url.Parse(link)
+	if err != nil || parsed.Fragment == "" {
+		return false
+	}
+	host := parsed.Hostname()
+	return host == "" || host == "notion.so" || strings.HasSuffix(host, ".notion.so") || strings.HasSuffix(host, ".notion.site")
+}
+
+// blockText returns the plain text of a block that may contain footnote references
+func blockText(block content.Block) string {
+	switch block.Type {
+	case content.BlockCode:
+		return ""
+	case content.BlockList:
+		var texts []string
+		for _, item := range block.List.Items {
+			texts = append(texts, content.PlainText(item.Text))
+		}
+		return strings.Join(texts, "\n")
+	case content.BlockTable:
+		var texts []string
+		for _, row := range block.Table.Rows {
+			for _, cell := range row {
+				texts = append(texts, content.PlainText(cell))
+			}
+		}
+		return strings.Join(texts, "\n")
+	default:
+		return content.PlainText(block.Text)
+	}
+}
+
+// applyFootnotes replaces references to defined footnotes with footnote anchors and appends
+// the footnotes themselves at the end of the document
+func applyFootnotes(nodes []SubstackNode, definitions map[int][]SubstackNode) []SubstackNode {
+	if len(definitions) == 0 {
+		return nodes
+	}
+
+	var order []int
+	used := make(map[int]bool)
+	nodes = replaceFootnoteRefs(nodes, definitions, func(number int) {
+		if !used[number] {
+			used[number] = true
+			order = append(order, number)
+		}
+	})
+
+	for _, number := range order {
+		nodes = append(nodes, SubstackNode{
+			Type: "footnote",
+			Attrs: map[string]interface{}{
+				"number": number,
+			},
+			Content: []SubstackNode{
+				{
+					Type:    "paragraph",
+					Content: definitions[number],
+				},
+			},
+		})
+	}
+	return nodes
+}
+
+func replaceFootnoteRefs(nodes []SubstackNode, definitions map[int][]SubstackNode, onRef func(int)) []SubstackNode {
+	var result []SubstackNode
+	for _, node := range nodes {
+		if node.Type != "text" {
+			if len(node.Content) > 0 && node.Type != "code_block" {
+				node.Content = replaceFootnoteRefs(node.Content, definitions, onRef)
+			}
+			result = append(result, node)
+			continue
+		}
+
+		// Inline code keeps its brackets as written
+		if hasMark(node, "code") {
+			result = append(result, node)
+			continue
+		}
+
+		last := 0
+		for _, loc := range footnoteRefPattern.FindAllStringSubmatchIndex(node.Text, -1) {
+			match := make([]string, 3)
+			for g := 0; g < 3; g++ {
+				if loc[2*g] >= 0 {
+					match[g] = node.Text[loc[2*g]:loc[2*g+1]]
+				}
+			}
+			number := footnoteNumber(match)
+			if _, defined := definitions[number]; !defined {
+				continue
+			}
+
+			if loc[0] > last {
+				before := node
+				before.Text = node.Text[last:loc[0]]
+				result = append(result, before)
+			}
+			result = append(result, SubstackNode{
+				Type: "footnoteAnchor",
+				Attrs: map[string]interface{}{
+					"number": number,
+				},
+			})
+			onRef(number)
+			last = loc[1]
+		}
+
+		if last < len(node.Text) {
+			rest := node
+			rest.Text = node.Text[last:]
+			result = append(result, rest)
+		}
+	}
+	return result
+}
+
+// footnoteNumber returns the number from a footnote pattern match, or 0 if there is none
+func footnoteNumber(match []string) int {
+	if len(match) < 3 {
+		return 0
+	}
+	digits := match[1]
+	if digits == "" {
+		digits = superscriptDigits.Replace(match[2])
+	}
+	number, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0
+	}
+	return number
+}
+
+func hasMark(node SubstackNode, markType string) bool {
+	for _, mark := range node.Marks {
+		if mark.Type == markType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,250 @@
+package substack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+const (
+	// maxDraftUpdateAttempts bounds the retries of a draft update that keeps conflicting
+	maxDraftUpdateAttempts = 4
+	// draftConflictBackoff is the wait before the first retry, doubled for every retry after it
+	draftConflictBackoff = 500 * time.Millisecond
+)
+
+// errDraftOutOfDate is wrapped when Substack refuses a draft update with 409 "Post out of date",
+// because the draft changed since the version the update was based on. Image uploads save the
+// draft on their own, so this happens right after uploading.
+var errDraftOutOfDate = errors.New("draft is out of date")
+
+// SubstackDraftBodyUpdateRequest updates only the body of a draft; last_updated_at is the
+// version of the draft the body was merged into
+type SubstackDraftBodyUpdateRequest struct {
+	DraftBody     string `json:"draft_body"`
+	LastUpdatedAt string `json:"last_updated_at"`
+}
+
+// imageReplacements maps the original URLs of the images uploaded to Substack to their
+// uploaded URLs
+func imageReplacements(resources []publisher.Resource) map[string]string {
+	replacements := make(map[string]string)
+	for _, resource := range resources {
+		if resource.Type != publisher.ResourceTypeImage {
+			continue
+		}
+		if original, uploaded := resource.Metadata["original_url"], resource.Metadata["uploaded_url"]; original != "" && uploaded != "" && original != uploaded {
+			replacements[original] = uploaded
+		}
+	}
+	return replacements
+}
+
+// rewriteDraftImages points the images of a draft at their uploads, so the post does not keep
+// the original URLs, which expire. Substack saves the draft while images are uploaded, so the
+// latest draft is fetched and the replacements are merged into its body rather than overwriting
+// it; when the draft changes again before the update lands, it is fetched and merged again.
+// It returns the number of images rewritten.
+func (p *SubstackPublisher) rewriteDraftImages(ctx context.Context, draftID int, replacements map[string]string) (int, error) {
+	if len(replacements) == 0 {
+		return 0, nil
+	}
+	defer publisher.TimeStage(ctx, models.StageUpload)()
+
+	backoff := draftConflictBackoff
+	for attempt := 1; ; attempt++ {
+		draft, err := p.getDraft(ctx, draftID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to fetch draft: %w", err)
+		}
+
+		body, rewritten, err := rewriteImageSources(draft.DraftBody, replacements)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse draft body: %w", err)
+		}
+		if rewritten == 0 {
+			// Nothing left to rewrite, e.g. an earlier attempt landed after all
+			return 0, nil
+		}
+
+		err = p.updateDraftBody(ctx, draftID, SubstackDraftBodyUpdateRequest{
+			DraftBody:     body,
+			LastUpdatedAt: draft.DraftUpdatedAt,
+		})
+		if err == nil {
+			return rewritten, nil
+		}
+		if !errors.Is(err, errDraftOutOfDate) {
+			return 0, err
+		}
+		if attempt == maxDraftUpdateAttempts {
+			return 0, fmt.Errorf("gave up after %d attempts: %w", attempt, err)
+		}
+
+		publisher.Logger(ctx, p.logger).Debug("Draft changed while rewriting images, retrying",
+			zap.Int("draft_id", draftID),
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff))
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// rewriteImageSources replaces the src of the images in a ProseMirror body. The body is walked
+// as plain JSON, so nodes and attributes Substack added are kept as they are.
+func rewriteImageSources(body string, replacements map[string]string) (string, int, error) {
+	doc, err := decodeDraftBody(body)
+	if err != nil {
+		return "", 0, err
+	}
+
+	rewritten := 0
+	walkImages(doc, func(attrs map[string]interface{}) {
+		if src, ok := attrs["src"].(string); ok && replacements[src] != "" {
+			attrs["src"] = replacements[src]
+			rewritten++
+		}
+	})
+
+	if rewritten == 0 {
+		return body, 0, nil
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return "", 0, err
+	}
+	return string(data), rewritten, nil
+}
+
+// imageSources lists the src of the images in a ProseMirror body
+func imageSources(body string) ([]string, error) {
+	doc, err := decodeDraftBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []string
+	walkImages(doc, func(attrs map[string]interface{}) {
+		if src, ok := attrs["src"].(string); ok && src != "" {
+			sources = append(sources, src)
+		}
+	})
+	return sources, nil
+}
+
+func decodeDraftBody(body string) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(body)))
+	// Numbers such as image sizes are kept exactly as they were
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// walkImages calls visit with the attributes of every image node in a decoded body
+func walkImages(node interface{}, visit func(attrs map[string]interface{})) {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		if value["type"] == "image2" {
+			if attrs, ok := value["attrs"].(map[string]interface{}); ok {
+				visit(attrs)
+			}
+		}
+		for _, child := range value {
+			walkImages(child, visit)
+		}
+	case []interface{}:
+		for _, child := range value {
+			walkImages(child, visit)
+		}
+	}
+}
+
+// getDraft fetches the latest version of a draft
+func (p *SubstackPublisher) getDraft(ctx context.Context, draftID int) (*SubstackDraftResponse, error) {
+	url := fmt.Sprintf("https://%s/api/v1/drafts/%d", p.domain, draftID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	p.setDraftHeaders(req, draftID)
+
+	resp, err := p.doWithSession(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp.StatusCode, body)
+	}
+
+	var draft SubstackDraftResponse
+	if err := json.Unmarshal(body, &draft); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &draft, nil
+}
+
+// updateDraftBody saves a new body for a draft, failing with errDraftOutOfDate when the draft
+// changed since request.LastUpdatedAt
+func (p *SubstackPublisher) updateDraftBody(ctx context.Context, draftID int, request SubstackDraftBodyUpdateRequest) error {
+	url := fmt.Sprintf("https://%s/api/v1/drafts/%d", p.domain, draftID)
+
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal update request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	p.setDraftHeaders(req, draftID)
+
+	resp, err := p.doWithSession(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusConflict {
+			return fmt.Errorf("%w: %s", errDraftOutOfDate, string(body))
+		}
+		return statusError(resp.StatusCode, body)
+	}
+	return nil
+}
+
+// setDraftHeaders sets the headers the Substack editor sends with requests for a draft
+func (p *SubstackPublisher) setDraftHeaders(req *http.Request, draftID int) {
+	req.Header.Set("Cookie", p.sessionCookie())
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("Origin", fmt.Sprintf("https://%s", p.domain))
+	req.Header.Set("Referer", fmt.Sprintf("https://%s/publish/post/%d", p.domain, draftID))
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/138.0.0.0 Safari/537.36")
+}
@@ -0,0 +1,121 @@
+package substack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// listPageSize is how many posts are requested per page of the archive and draft listings
+const listPageSize = 50
+
+// SubstackArchivePost is a published post in the publication archive
+type SubstackArchivePost struct {
+	ID           int    `json:"id"`
+	Title        string `json:"title"`
+	Slug         string `json:"slug"`
+	CanonicalURL string `json:"canonical_url"`
+	PostDate     string `json:"post_date"`
+}
+
+// ListPosts returns the published posts of the publication and its unpublished drafts. The
+// publish ID is the post ID, which is also its draft ID.
+func (p *SubstackPublisher) ListPosts(ctx context.Context, config publisher.PublishConfig) ([]publisher.ExistingPost, error) {
+	var posts []publisher.ExistingPost
+
+	for offset := 0; ; offset += listPageSize {
+		var archive []SubstackArchivePost
+		if err := p.getJSON(ctx, fmt.Sprintf("https://%s/api/v1/archive?sort=new&offset=%d&limit=%d", p.domain, offset, listPageSize), &archive); err != nil {
+			return nil, fmt.Errorf("failed to list published posts: %w", err)
+		}
+
+		for _, item := range archive {
+			post := publisher.ExistingPost{
+				PublishID: strconv.Itoa(item.ID),
+				Title:     item.Title,
+				Slug:      item.Slug,
+				URL:       item.CanonicalURL,
+				Metadata: map[string]string{
+					"draft_id":       strconv.Itoa(item.ID),
+					"platform":       "substack",
+					"publish_status": "published",
+				},
+			}
+			if post.URL == "" && item.Slug != "" {
+				post.URL = fmt.Sprintf("https://%s/p/%s", p.domain, item.Slug)
+			}
+			if postDate, err := time.Parse(time.RFC3339, item.PostDate); err == nil {
+				post.PublishedAt = &postDate
+			}
+			posts = append(posts, post)
+		}
+		if len(archive) < listPageSize {
+			break
+		}
+	}
+
+	for offset := 0; ; offset += listPageSize {
+		var drafts []SubstackDraftResponse
+		if err := p.getJSON(ctx, fmt.Sprintf("https://%s/api/v1/drafts?offset=%d&limit=%d", p.domain, offset, listPageSize), &drafts); err != nil {
+			return nil, fmt.Errorf("failed to list drafts: %w", err)
+		}
+
+		for _, draft := range drafts {
+			// Published posts are in the archive already
+			if draft.IsPublished {
+				continue
+			}
+			posts = append(posts, publisher.ExistingPost{
+				PublishID: strconv.Itoa(draft.ID),
+				Title:     draft.DraftTitle,
+				Draft:     true,
+				Metadata: map[string]string{
+					"draft_id": strconv.Itoa(draft.ID),
+					"platform": "substack",
+				},
+			})
+		}
+		if len(drafts) < listPageSize {
+			break
+		}
+	}
+
+	return posts, nil
+}
+
+// getJSON sends an authenticated GET request and decodes the JSON response into out
+func (p *SubstackPublisher) getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Cookie", p.sessionCookie())
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/138.0.0.0 Safari/537.36")
+
+	resp, err := p.doWithSession(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp.StatusCode, body)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package substack
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// RefreshMedia uploads the images of the page again and points the draft at them. Only images
+// the draft still links at their source are patched, matched by the URL without its signed
+// query, so text edited on Substack is kept. A published post is its draft published again
+// without sending it by email.
+func (p *SubstackPublisher) RefreshMedia(ctx context.Context, publishID string, content publisher.PublishContent, config publisher.PublishConfig) (*publisher.MediaRefresh, error) {
+	if strings.HasPrefix(publishID, notePublishIDPrefix) {
+		return nil, fmt.Errorf("%w: Substack notes cannot be edited", publisher.ErrMediaRefreshNotSupported)
+	}
+	draftID, err := strconv.Atoi(publishID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid draft ID: %w", err)
+	}
+
+	transformed, err := p.TransformContent(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+	var imageURLs []string
+	for _, resource := range transformed.Resources {
+		if resource.Type == publisher.ResourceTypeImage {
+			imageURLs = append(imageURLs, resource.URL)
+		}
+	}
+	fresh := publisher.FreshImages(imageURLs)
+
+	draft, err := p.getDraft(ctx, draftID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch draft: %w", err)
+	}
+	sources, err := imageSources(draft.DraftBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse draft body: %w", err)
+	}
+
+	refresh := &publisher.MediaRefresh{}
+	replacements := make(map[string]string)
+	for _, src := range sources {
+		freshURL, ok := fresh[publisher.MediaKey(src)]
+		if !ok || replacements[src] != "" {
+			continue
+		}
+		uploaded, err := p.uploadImage(ctx, freshURL, draftID)
+		if err != nil {
+			publisher.Logger(ctx, p.logger).Warn("Failed to upload image again, keeping its old link",
+				zap.String("image_url", freshURL),
+				zap.Error(err))
+			refresh.Failed++
+			continue
+		}
+		replacements[src] = uploaded
+	}
+
+	if refresh.Refreshed, err = p.rewriteDraftImages(ctx, draftID, replacements); err != nil {
+		return nil, fmt.Errorf("failed to point draft images at their uploads: %w", err)
+	}
+
+	if refresh.Refreshed > 0 && draft.IsPublished {
+		if _, err := p.publishPost(ctx, draftID, SubstackPublishRequest{Send: false}); err != nil {
+			return nil, fmt.Errorf("failed to update published post: %w", err)
+		}
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Substack images refreshed",
+		zap.Int("draft_id", draftID),
+		zap.Bool("published", draft.IsPublished),
+		zap.Int("images", len(sources)),
+		zap.Int("refreshed", refresh.Refreshed),
+		zap.Int("failed", refresh.Failed))
+	return refresh, nil
+}
@@ -0,0 +1,74 @@
+package substack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// SubstackPostStats is the part of a post that carries its engagement counts
+type SubstackPostStats struct {
+	Post struct {
+		ID           int            `json:"id"`
+		IsPublished  bool           `json:"is_published"`
+		Reactions    map[string]int `json:"reactions"`
+		CommentCount int64          `json:"comment_count"`
+		Restacks     int64          `json:"restacks"`
+	} `json:"post"`
+}
+
+// CollectMetrics reads the reactions, comments and restacks of a published post. Notes and
+// posts still waiting to go out have no stats.
+func (p *SubstackPublisher) CollectMetrics(ctx context.Context, post publisher.PublishedPost, config publisher.PublishConfig) (*publisher.PostMetrics, error) {
+	if strings.HasPrefix(post.PublishID, notePublishIDPrefix) || post.Metadata["publish_status"] != "published" {
+		return nil, nil
+	}
+
+	url := fmt.Sprintf("https://%s/api/v1/posts/by-id/%s", p.domain, post.PublishID)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Cookie", p.sessionCookie())
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/138.0.0.0 Safari/537.36")
+
+	resp, err := p.doWithSession(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp.StatusCode, body)
+	}
+
+	var stats SubstackPostStats
+	if err := json.Unmarshal(body, &stats); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if !stats.Post.IsPublished {
+		return nil, nil
+	}
+
+	var likes int64
+	for _, count := range stats.Post.Reactions {
+		likes += int64(count)
+	}
+
+	return &publisher.PostMetrics{
+		Likes:    likes,
+		Comments: stats.Post.CommentCount,
+		Shares:   stats.Post.Restacks,
+	}, nil
+}
@@ -9,9 +9,14 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/ifuryst/ripple/internal/models"
 	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/httpclient"
+	"github.com/ifuryst/ripple/pkg/tts"
 	"go.uber.org/zap"
 )
 
@@ -22,6 +27,16 @@ type SubstackPublisher struct {
 	client             *http.Client
 	domain             string
 	cookie             string
+	configuredCookie   string
+	sessionID          string
+	loginLink          string
+	sessionMu          sync.RWMutex
+	// narrator generates audio narrations attached to drafts; nil when narration is off
+	narrator tts.Provider
+	// mediaCache remembers uploaded images, so the same image is uploaded to the publication once
+	mediaCache *publisher.MediaCache
+	// account names the publication when several Substack publications are configured
+	account string
 }
 
 // Substack API request structures
@@ -39,6 +54,7 @@ type SubstackCreateDraftRequest struct {
 	DraftSectionID                  *int                      `json:"draft_section_id"`
 	DraftBylines                    []SubstackByline          `json:"draft_bylines"`
 	Audience                        string                    `json:"audience"`
+	CoverImage                      string                    `json:"cover_image,omitempty"`
 }
 
 type SubstackByline struct {
@@ -64,7 +80,7 @@ type SubstackUpdateDraftRequest struct {
 
 type SubstackImageUploadRequest struct {
 	Image  string `json:"image"`
-	PostID int    `json:"postId"`
+	PostID int    `json:"postId,omitempty"`
 }
 
 type SubstackImageUploadResponse struct {
@@ -76,6 +92,45 @@ type SubstackImageUploadResponse struct {
 	ImageHeight int    `json:"imageHeight"`
 }
 
+// notePublishIDPrefix distinguishes Substack Note IDs from draft IDs in stored publish IDs
+const notePublishIDPrefix = "note-"
+
+type SubstackNoteRequest struct {
+	BodyJSON         SubstackDocument `json:"bodyJson"`
+	AttachmentIDs    []string         `json:"attachmentIds,omitempty"`
+	ReplyMinimumRole string           `json:"replyMinimumRole"`
+}
+
+type SubstackNoteResponse struct {
+	ID int `json:"id"`
+}
+
+type SubstackNoteAttachmentRequest struct {
+	URL  string `json:"url"`
+	Type string `json:"type"`
+}
+
+type SubstackNoteAttachmentResponse struct {
+	ID string `json:"id"`
+}
+
+type SubstackPublishRequest struct {
+	Send               bool `json:"send"`
+	ShareAutomatically bool `json:"share_automatically"`
+}
+
+type SubstackScheduleRequest struct {
+	PostDate string `json:"post_date"`
+}
+
+type SubstackPublishResponse struct {
+	ID           int    `json:"id"`
+	Slug         string `json:"slug"`
+	CanonicalURL string `json:"canonical_url"`
+	PostDate     string `json:"post_date"`
+	Audience     string `json:"audience"`
+}
+
 type SubstackDraftResponse struct {
 	ID                 int                 `json:"id"`
 	UUID               string              `json:"uuid"`
@@ -96,14 +151,22 @@ func NewSubstackPublisher(logger *zap.Logger) publisher.Publisher {
 	return &SubstackPublisher{
 		logger:             logger,
 		contentTransformer: NewSubstackTransformer(),
-		client: &http.Client{
-			Timeout: 60 * time.Second,
-		},
+		client:             httpclient.New(),
 	}
 }
 
 func (p *SubstackPublisher) GetPlatformName() string {
-	return "substack"
+	return publisher.AccountPlatformName("substack", p.account)
+}
+
+// SetAccountName registers the publisher as a named publication, published to as substack:<name>
+func (p *SubstackPublisher) SetAccountName(name string) {
+	p.account = name
+}
+
+// SetMediaCache lets image uploads reuse images already uploaded to the publication
+func (p *SubstackPublisher) SetMediaCache(cache *publisher.MediaCache) {
+	p.mediaCache = cache
 }
 
 func (p *SubstackPublisher) Initialize(ctx context.Context, config publisher.PublishConfig) error {
@@ -111,16 +174,40 @@ func (p *SubstackPublisher) Initialize(ctx context.Context, config publisher.Pub
 		return err
 	}
 
+	injections, err := ParseBlockInjections(config.Config["inject_blocks"])
+	if err != nil {
+		return fmt.Errorf("invalid inject_blocks config: %w", err)
+	}
+	p.contentTransformer.SetBlockInjections(injections)
+
+	narrator, err := newNarrator(config)
+	if err != nil {
+		return fmt.Errorf("invalid TTS config: %w", err)
+	}
+	p.narrator = narrator
+
 	p.domain = config.Config["domain"]
-	p.cookie = config.Config["cookie"]
+	p.sessionID = config.Config["session_id"]
+	p.loginLink = config.Config["login_link"]
+
+	// Keep a refreshed session across re-initialization unless the configured cookie changed
+	p.sessionMu.Lock()
+	if cookie := config.Config["cookie"]; cookie != p.configuredCookie || p.cookie == "" {
+		p.configuredCookie = cookie
+		p.cookie = cookie
+		if p.cookie == "" && p.sessionID != "" {
+			p.cookie = sessionCookieName + "=" + p.sessionID
+		}
+	}
+	p.sessionMu.Unlock()
 
-	p.logger.Info("Substack publisher initialized successfully",
+	publisher.Logger(ctx, p.logger).Info("Substack publisher initialized successfully",
 		zap.String("domain", p.domain))
 	return nil
 }
 
 func (p *SubstackPublisher) ValidateConfig(config publisher.PublishConfig) error {
-	required := []string{"domain", "cookie"}
+	required := []string{"domain"}
 
 	for _, key := range required {
 		if config.Config[key] == "" {
@@ -128,18 +215,33 @@ func (p *SubstackPublisher) ValidateConfig(config publisher.PublishConfig) error
 		}
 	}
 
+	if config.Config["cookie"] == "" && config.Config["session_id"] == "" {
+		return fmt.Errorf("missing required config: cookie or session_id")
+	}
+
 	return nil
 }
 
 func (p *SubstackPublisher) TransformContent(ctx context.Context, content publisher.PublishContent) (*publisher.PublishContent, error) {
+	defer publisher.TimeStage(ctx, models.StageTransform)()
+	doc, err := content.ContentDocument()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse content: %w", err)
+	}
+
 	// Transform content to Substack's JSON format
-	transformedContent, err := p.contentTransformer.Transform(ctx, content.Content)
+	transformedContent, err := p.contentTransformer.Transform(ctx, doc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to transform content: %w", err)
 	}
 
-	// Extract images from content for processing
-	imageURLs := p.contentTransformer.ExtractImages(content.Content)
+	// Extract images from content for processing, including the thumbnails videos are shown as
+	imageURLs := doc.Images()
+	for _, video := range doc.Videos() {
+		if thumbnail := video.ThumbnailURL(); thumbnail != "" {
+			imageURLs = append(imageURLs, thumbnail)
+		}
+	}
 
 	// Create resources for images
 	var resources []publisher.Resource
@@ -165,6 +267,7 @@ func (p *SubstackPublisher) TransformContent(ctx context.Context, content publis
 }
 
 func (p *SubstackPublisher) ProcessResources(ctx context.Context, content *publisher.PublishContent, config publisher.PublishConfig) error {
+	defer publisher.TimeStage(ctx, models.StageMedia)()
 	if len(content.Resources) == 0 {
 		return nil
 	}
@@ -187,7 +290,7 @@ func (p *SubstackPublisher) ProcessResources(ctx context.Context, content *publi
 			// Upload image to Substack
 			uploadedImageURL, err := p.uploadImage(ctx, resource.URL, postID)
 			if err != nil {
-				p.logger.Warn("Failed to upload image, skipping", 
+				publisher.Logger(ctx, p.logger).Warn("Failed to upload image, skipping", 
 					zap.String("image_url", resource.URL),
 					zap.Error(err))
 				// Skip this image but continue with others
@@ -210,7 +313,7 @@ func (p *SubstackPublisher) ProcessResources(ctx context.Context, content *publi
 	// Store successful upload count in metadata for later use
 	content.Metadata["successful_uploads"] = fmt.Sprintf("%d", successfulUploads)
 
-	p.logger.Info("Processed Substack resources",
+	publisher.Logger(ctx, p.logger).Info("Processed Substack resources",
 		zap.Int("total_images", len(content.Resources)),
 		zap.Int("successful_uploads", successfulUploads))
 
@@ -218,14 +321,14 @@ func (p *SubstackPublisher) ProcessResources(ctx context.Context, content *publi
 }
 
 func (p *SubstackPublisher) SaveToDraft(ctx context.Context, content publisher.PublishContent, config publisher.PublishConfig) (*publisher.PublishResult, error) {
-	p.logger.Debug("Starting SaveToDraft for Substack", 
+	publisher.Logger(ctx, p.logger).Debug("Starting SaveToDraft for Substack", 
 		zap.String("title", content.Title),
 		zap.Int("resources_count", len(content.Resources)))
 		
 	// Transform content first
 	transformedContent, err := p.TransformContent(ctx, content)
 	if err != nil {
-		p.logger.Error("Failed to transform content", zap.Error(err))
+		publisher.Logger(ctx, p.logger).Error("Failed to transform content", zap.Error(err))
 		return &publisher.PublishResult{
 			Success:  false,
 			Error:    err,
@@ -233,7 +336,7 @@ func (p *SubstackPublisher) SaveToDraft(ctx context.Context, content publisher.P
 		}, nil
 	}
 	
-	p.logger.Debug("Content transformed successfully", 
+	publisher.Logger(ctx, p.logger).Debug("Content transformed successfully", 
 		zap.Int("transformed_resources_count", len(transformedContent.Resources)))
 
 	// Use English title as subtitle if available, otherwise fall back to summary
@@ -256,7 +359,24 @@ func (p *SubstackPublisher) SaveToDraft(ctx context.Context, content publisher.P
 		SectionChosen:                   false,
 		DraftSectionID:                  nil,
 		DraftBylines:                    []SubstackByline{}, // Will be populated by Substack
-		Audience:                        "everyone",
+		Audience:                        p.getAudience(config),
+	}
+
+	// Re-host the cover on Substack, since Notion cover URLs expire
+	if coverURL := transformedContent.Metadata["cover_url"]; strings.HasPrefix(coverURL, "http") {
+		if uploadedURL, err := p.uploadImage(ctx, coverURL, 0); err != nil {
+			publisher.Logger(ctx, p.logger).Warn("Failed to upload cover image, creating draft without cover",
+				zap.String("cover_url", coverURL),
+				zap.Error(err))
+		} else {
+			draftRequest.CoverImage = uploadedURL
+		}
+	}
+
+	// Route the post to a section based on its Notion content type
+	if sectionID := p.resolveSectionID(transformedContent.Metadata["content_type"], config.Config["section_mapping"]); sectionID != nil {
+		draftRequest.SectionChosen = true
+		draftRequest.DraftSectionID = sectionID
 	}
 
 	// Create draft
@@ -274,12 +394,12 @@ func (p *SubstackPublisher) SaveToDraft(ctx context.Context, content publisher.P
 	transformedContent.Metadata["draft_id"] = fmt.Sprintf("%d", draftResponse.ID)
 
 	// Process resources (images) now that we have a draft ID
-	p.logger.Debug("Processing resources", 
+	publisher.Logger(ctx, p.logger).Debug("Processing resources", 
 		zap.Int("resource_count", len(transformedContent.Resources)),
 		zap.String("draft_id", transformedContent.Metadata["draft_id"]))
 		
 	if err := p.ProcessResources(ctx, transformedContent, config); err != nil {
-		p.logger.Error("Failed to process resources", zap.Error(err))
+		publisher.Logger(ctx, p.logger).Error("Failed to process resources", zap.Error(err))
 		resourceErr := fmt.Errorf("failed to process resources: %w", err)
 		return &publisher.PublishResult{
 			Success:  false,
@@ -296,53 +416,67 @@ func (p *SubstackPublisher) SaveToDraft(ctx context.Context, content publisher.P
 		}
 	}
 	
-	p.logger.Debug("Resources processed successfully", 
+	publisher.Logger(ctx, p.logger).Debug("Resources processed successfully", 
 		zap.Int("successful_uploads", successfulUploads))
 
-	// Note: Skip final update step as image uploads may have already updated the draft
-	// and caused version conflicts (409 "Post out of date" error)
+	metadata := map[string]string{
+		"draft_id":     fmt.Sprintf("%d", draftResponse.ID),
+		"uuid":         draftResponse.UUID,
+		"platform":     "substack",
+		"draft_status": "saved",
+	}
+
+	// The draft was created with the original image URLs, which expire, so it is pointed at the
+	// uploads. A draft left with the original URLs still works for a while, so failures are
+	// reported rather than failing the draft.
 	if successfulUploads > 0 {
-		p.logger.Info("Images uploaded successfully, draft auto-updated by Substack", 
-			zap.Int("successful_uploads", successfulUploads),
-			zap.Int("draft_id", draftResponse.ID))
+		rewritten, err := p.rewriteDraftImages(ctx, draftResponse.ID, imageReplacements(transformedContent.Resources))
+		if err != nil {
+			publisher.Logger(ctx, p.logger).Warn("Failed to point draft images at their uploads",
+				zap.Int("draft_id", draftResponse.ID),
+				zap.Error(err))
+			metadata["image_rewrite_error"] = err.Error()
+		} else {
+			publisher.Logger(ctx, p.logger).Info("Draft images pointed at their uploads",
+				zap.Int("draft_id", draftResponse.ID),
+				zap.Int("successful_uploads", successfulUploads),
+				zap.Int("rewritten", rewritten))
+		}
 	}
 
-	p.logger.Info("Draft saved successfully",
+	publisher.Logger(ctx, p.logger).Info("Draft saved successfully",
 		zap.Int("draft_id", draftResponse.ID),
 		zap.String("title", transformedContent.Title))
 
+	// A missing narration should not hold back the post, so failures are only reported
+	if p.narrator != nil {
+		if uploadID, err := p.attachNarration(ctx, draftResponse.ID, content); err != nil {
+			publisher.Logger(ctx, p.logger).Warn("Failed to attach narration, draft saved without audio",
+				zap.Int("draft_id", draftResponse.ID),
+				zap.Error(err))
+			metadata["narration_error"] = err.Error()
+		} else {
+			metadata["podcast_upload_id"] = fmt.Sprintf("%d", uploadID)
+		}
+	}
+
 	return &publisher.PublishResult{
 		Success:   true,
 		PublishID: fmt.Sprintf("%d", draftResponse.ID),
-		Metadata: map[string]string{
-			"draft_id":     fmt.Sprintf("%d", draftResponse.ID),
-			"uuid":         draftResponse.UUID,
-			"platform":     "substack",
-			"draft_status": "saved",
-		},
+		Metadata:  metadata,
 	}, nil
 }
 
 func (p *SubstackPublisher) Publish(ctx context.Context, draftID string, config publisher.PublishConfig) (*publisher.PublishResult, error) {
-	// For Substack, publishing is done through the web interface
-	// The API doesn't provide a direct publish endpoint based on the documentation
-	// We'll return success but indicate that manual publishing is required
-	p.logger.Info("Substack draft created, manual publishing required",
-		zap.String("draft_id", draftID))
-
-	return &publisher.PublishResult{
-		Success:     true,
-		PublishID:   draftID,
-		PublishedAt: time.Now(),
-		Metadata: map[string]string{
-			"draft_id":       draftID,
-			"publish_status": "manual_required",
-			"message":        "Draft created successfully. Please publish manually through Substack interface.",
-		},
-	}, nil
+	return p.publishDraft(ctx, draftID, nil, config)
 }
 
 func (p *SubstackPublisher) PublishDirect(ctx context.Context, content publisher.PublishContent, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	// Notes skip the draft flow and go straight to the Notes feed
+	if content.IsNote() {
+		return p.publishNote(ctx, content)
+	}
+
 	// Save to draft first
 	draftResult, err := p.SaveToDraft(ctx, content, config)
 	if err != nil {
@@ -357,13 +491,22 @@ func (p *SubstackPublisher) PublishDirect(ctx context.Context, content publisher
 		return draftResult, nil
 	}
 
-	// Auto-publish if enabled (though for Substack this means just creating the draft)
+	// Auto-publish if enabled, scheduling the post when the Notion post date is in the future
 	if autoPublish := config.Config["auto_publish"]; autoPublish == "true" {
-		publishResult, err := p.Publish(ctx, draftResult.PublishID, config)
+		publishResult, err := p.publishDraft(ctx, draftResult.PublishID, content.PublishDate, config)
 		if err != nil {
-			draftResult.Metadata["publish_error"] = err.Error()
-			p.logger.Warn("Auto-publish not available for Substack, draft created successfully",
-				zap.String("draft_id", draftResult.PublishID))
+			return &publisher.PublishResult{
+				Success:  false,
+				Error:    err,
+				ErrorMsg: err.Error(),
+			}, nil
+		}
+		if !publishResult.Success {
+			// Keep the draft so it can still be published manually
+			draftResult.Metadata["publish_error"] = publishResult.ErrorMsg
+			publisher.Logger(ctx, p.logger).Warn("Failed to publish Substack draft, draft created successfully",
+				zap.String("draft_id", draftResult.PublishID),
+				zap.String("error", publishResult.ErrorMsg))
 			return draftResult, nil
 		}
 		return publishResult, nil
@@ -373,6 +516,14 @@ func (p *SubstackPublisher) PublishDirect(ctx context.Context, content publisher
 }
 
 func (p *SubstackPublisher) GetPublishStatus(ctx context.Context, publishID string, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	// Notes are live as soon as they are posted
+	if strings.HasPrefix(publishID, notePublishIDPrefix) {
+		return &publisher.PublishResult{
+			Success:   true,
+			PublishID: publishID,
+		}, nil
+	}
+
 	// Check draft status by trying to get draft info
 	draftID, err := strconv.Atoi(publishID)
 	if err != nil {
@@ -390,15 +541,44 @@ func (p *SubstackPublisher) GetPublishStatus(ctx context.Context, publishID stri
 	}, nil
 }
 
+func (p *SubstackPublisher) Unpublish(ctx context.Context, publishID string, config publisher.PublishConfig) error {
+	if strings.HasPrefix(publishID, notePublishIDPrefix) {
+		noteID, err := strconv.Atoi(strings.TrimPrefix(publishID, notePublishIDPrefix))
+		if err != nil {
+			return fmt.Errorf("invalid note ID: %w", err)
+		}
+
+		if err := p.deleteNote(ctx, noteID); err != nil {
+			return fmt.Errorf("failed to delete Substack note: %w", err)
+		}
+
+		publisher.Logger(ctx, p.logger).Info("Substack note deleted", zap.Int("note_id", noteID))
+		return nil
+	}
+
+	draftID, err := strconv.Atoi(publishID)
+	if err != nil {
+		return fmt.Errorf("invalid publish ID: %w", err)
+	}
+
+	if err := p.deleteDraft(ctx, draftID); err != nil {
+		return fmt.Errorf("failed to delete Substack draft: %w", err)
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Substack draft deleted", zap.Int("draft_id", draftID))
+	return nil
+}
+
 func (p *SubstackPublisher) Cleanup(ctx context.Context, publishID string, config publisher.PublishConfig) error {
 	// Clean up temporary files if any
-	p.logger.Info("Substack cleanup completed", zap.String("publish_id", publishID))
+	publisher.Logger(ctx, p.logger).Info("Substack cleanup completed", zap.String("publish_id", publishID))
 	return nil
 }
 
 // Helper methods
 
 func (p *SubstackPublisher) createDraft(ctx context.Context, request SubstackCreateDraftRequest) (*SubstackDraftResponse, error) {
+	defer publisher.TimeStage(ctx, models.StageUpload)()
 	url := fmt.Sprintf("https://%s/api/v1/drafts", p.domain)
 
 	jsonData, err := json.Marshal(request)
@@ -406,7 +586,7 @@ func (p *SubstackPublisher) createDraft(ctx context.Context, request SubstackCre
 		return nil, fmt.Errorf("failed to marshal draft request: %w", err)
 	}
 	
-	p.logger.Debug("Creating Substack draft", 
+	publisher.Logger(ctx, p.logger).Debug("Creating Substack draft", 
 		zap.String("url", url),
 		zap.String("request_body", string(jsonData)))
 
@@ -416,7 +596,7 @@ func (p *SubstackPublisher) createDraft(ctx context.Context, request SubstackCre
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Cookie", p.cookie)
+	req.Header.Set("Cookie", p.sessionCookie())
 	req.Header.Set("Accept", "*/*")
 	req.Header.Set("Accept-Language", "en,zh-CN;q=0.9,zh;q=0.8")
 	req.Header.Set("Origin", fmt.Sprintf("https://%s", p.domain))
@@ -429,29 +609,29 @@ func (p *SubstackPublisher) createDraft(ctx context.Context, request SubstackCre
 	req.Header.Set("Sec-Fetch-Mode", "cors")
 	req.Header.Set("Sec-Fetch-Site", "same-origin")
 
-	resp, err := p.client.Do(req)
+	resp, err := p.doWithSession(req)
 	if err != nil {
-		p.logger.Error("Failed to send Substack request", zap.Error(err), zap.String("url", url))
+		publisher.Logger(ctx, p.logger).Error("Failed to send Substack request", zap.Error(err), zap.String("url", url))
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		p.logger.Error("Failed to read Substack response", zap.Error(err))
+		publisher.Logger(ctx, p.logger).Error("Failed to read Substack response", zap.Error(err))
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	p.logger.Debug("Substack API response", 
+	publisher.Logger(ctx, p.logger).Debug("Substack API response", 
 		zap.Int("status_code", resp.StatusCode),
 		zap.String("response_body", string(body)))
 
 	if resp.StatusCode != http.StatusOK {
-		p.logger.Error("Substack API error", 
+		publisher.Logger(ctx, p.logger).Error("Substack API error", 
 			zap.Int("status_code", resp.StatusCode), 
 			zap.String("response_body", string(body)),
 			zap.String("request_url", url))
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, statusError(resp.StatusCode, body)
 	}
 
 	var draftResponse SubstackDraftResponse
@@ -476,7 +656,7 @@ func (p *SubstackPublisher) updateDraft(ctx context.Context, draftID int, reques
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Cookie", p.cookie)
+	req.Header.Set("Cookie", p.sessionCookie())
 	req.Header.Set("Accept", "*/*")
 	req.Header.Set("Accept-Language", "en,zh-CN;q=0.9,zh;q=0.8")
 	req.Header.Set("Origin", fmt.Sprintf("https://%s", p.domain))
@@ -489,7 +669,7 @@ func (p *SubstackPublisher) updateDraft(ctx context.Context, draftID int, reques
 	req.Header.Set("Sec-Fetch-Mode", "cors")
 	req.Header.Set("Sec-Fetch-Site", "same-origin")
 
-	resp, err := p.client.Do(req)
+	resp, err := p.doWithSession(req)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
@@ -497,7 +677,421 @@ func (p *SubstackPublisher) updateDraft(ctx context.Context, draftID int, reques
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		return statusError(resp.StatusCode, body)
+	}
+
+	return nil
+}
+
+func (p *SubstackPublisher) deleteDraft(ctx context.Context, draftID int) error {
+	url := fmt.Sprintf("https://%s/api/v1/drafts/%d", p.domain, draftID)
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Cookie", p.sessionCookie())
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("Origin", fmt.Sprintf("https://%s", p.domain))
+	req.Header.Set("Referer", fmt.Sprintf("https://%s/publish/posts", p.domain))
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/138.0.0.0 Safari/537.36")
+
+	resp, err := p.doWithSession(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return statusError(resp.StatusCode, body)
+	}
+
+	return nil
+}
+
+// publishDraft publishes a draft right away, or schedules it when publishAt is in the future
+func (p *SubstackPublisher) publishDraft(ctx context.Context, draftID string, publishAt *time.Time, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	defer publisher.TimeStage(ctx, models.StagePublish)()
+	id, err := strconv.Atoi(draftID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid draft ID: %w", err)
+	}
+
+	if publishAt != nil && publishAt.After(time.Now()) {
+		if err := p.scheduleDraft(ctx, id, *publishAt); err != nil {
+			scheduleErr := fmt.Errorf("failed to schedule Substack post: %w", err)
+			return &publisher.PublishResult{
+				Success:  false,
+				Error:    scheduleErr,
+				ErrorMsg: scheduleErr.Error(),
+			}, nil
+		}
+
+		publisher.Logger(ctx, p.logger).Info("Substack post scheduled",
+			zap.Int("draft_id", id),
+			zap.Time("publish_at", *publishAt))
+
+		return &publisher.PublishResult{
+			Success:   true,
+			PublishID: draftID,
+			Metadata: map[string]string{
+				"draft_id":       draftID,
+				"platform":       "substack",
+				"publish_status": "scheduled",
+				"scheduled_at":   publishAt.Format(time.RFC3339),
+			},
+		}, nil
+	}
+
+	request := SubstackPublishRequest{
+		Send:               config.Config["send_email"] == "true",
+		ShareAutomatically: false,
+	}
+
+	response, err := p.publishPost(ctx, id, request)
+	if err != nil {
+		publishErr := fmt.Errorf("failed to publish Substack post: %w", err)
+		return &publisher.PublishResult{
+			Success:  false,
+			Error:    publishErr,
+			ErrorMsg: publishErr.Error(),
+		}, nil
+	}
+
+	postURL := response.CanonicalURL
+	if postURL == "" && response.Slug != "" {
+		postURL = fmt.Sprintf("https://%s/p/%s", p.domain, response.Slug)
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Substack post published",
+		zap.Int("draft_id", id),
+		zap.String("url", postURL),
+		zap.Bool("send_email", request.Send))
+
+	return &publisher.PublishResult{
+		Success:     true,
+		PublishID:   draftID,
+		URL:         postURL,
+		PublishedAt: time.Now(),
+		Metadata: map[string]string{
+			"draft_id":       draftID,
+			"platform":       "substack",
+			"publish_status": "published",
+			"slug":           response.Slug,
+			"audience":       response.Audience,
+			"send_email":     fmt.Sprintf("%t", request.Send),
+		},
+	}, nil
+}
+
+func (p *SubstackPublisher) publishPost(ctx context.Context, draftID int, request SubstackPublishRequest) (*SubstackPublishResponse, error) {
+	url := fmt.Sprintf("https://%s/api/v1/drafts/%d/publish", p.domain, draftID)
+
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal publish request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Cookie", p.sessionCookie())
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("Origin", fmt.Sprintf("https://%s", p.domain))
+	req.Header.Set("Referer", fmt.Sprintf("https://%s/publish/post/%d", p.domain, draftID))
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/138.0.0.0 Safari/537.36")
+
+	resp, err := p.doWithSession(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp.StatusCode, body)
+	}
+
+	var publishResponse SubstackPublishResponse
+	if err := json.Unmarshal(body, &publishResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &publishResponse, nil
+}
+
+func (p *SubstackPublisher) scheduleDraft(ctx context.Context, draftID int, publishAt time.Time) error {
+	url := fmt.Sprintf("https://%s/api/v1/drafts/%d/schedule", p.domain, draftID)
+
+	jsonData, err := json.Marshal(SubstackScheduleRequest{PostDate: publishAt.UTC().Format(time.RFC3339)})
+	if err != nil {
+		return fmt.Errorf("failed to marshal schedule request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Cookie", p.sessionCookie())
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("Origin", fmt.Sprintf("https://%s", p.domain))
+	req.Header.Set("Referer", fmt.Sprintf("https://%s/publish/post/%d", p.domain, draftID))
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/138.0.0.0 Safari/537.36")
+
+	resp, err := p.doWithSession(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return statusError(resp.StatusCode, body)
+	}
+
+	return nil
+}
+
+// getAudience returns the configured post audience: everyone, only_paid, founding or only_free
+func (p *SubstackPublisher) getAudience(config publisher.PublishConfig) string {
+	switch audience := config.Config["audience"]; audience {
+	case "everyone", "only_paid", "founding", "only_free":
+		return audience
+	case "":
+		return "everyone"
+	default:
+		p.logger.Warn("Unknown Substack audience, using everyone", zap.String("audience", audience))
+		return "everyone"
+	}
+}
+
+// resolveSectionID maps the Notion content types to a Substack section using a mapping like
+// "Essay:123,Newsletter:456"; the first content type with a mapping wins
+func (p *SubstackPublisher) resolveSectionID(contentTypes, mapping string) *int {
+	if contentTypes == "" || mapping == "" {
+		return nil
+	}
+
+	sections := make(map[string]int)
+	for _, entry := range strings.Split(mapping, ",") {
+		name, idStr, ok := strings.Cut(entry, ":")
+		if !ok {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimSpace(idStr))
+		if err != nil {
+			p.logger.Warn("Invalid Substack section ID in mapping", zap.String("entry", entry))
+			continue
+		}
+		sections[strings.ToLower(strings.TrimSpace(name))] = id
+	}
+
+	for _, contentType := range strings.Split(contentTypes, ",") {
+		if id, ok := sections[strings.ToLower(strings.TrimSpace(contentType))]; ok {
+			return &id
+		}
+	}
+
+	return nil
+}
+
+// publishNote posts short-form content to Substack Notes with at most one image attachment
+func (p *SubstackPublisher) publishNote(ctx context.Context, content publisher.PublishContent) (*publisher.PublishResult, error) {
+	defer publisher.TimeStage(ctx, models.StagePublish)()
+	doc, err := content.ContentDocument()
+	if err != nil {
+		parseErr := fmt.Errorf("failed to parse content: %w", err)
+		return &publisher.PublishResult{
+			Success:  false,
+			Error:    parseErr,
+			ErrorMsg: parseErr.Error(),
+		}, nil
+	}
+
+	document, err := p.contentTransformer.TransformNote(ctx, doc)
+	if err != nil {
+		transformErr := fmt.Errorf("failed to transform note: %w", err)
+		return &publisher.PublishResult{
+			Success:  false,
+			Error:    transformErr,
+			ErrorMsg: transformErr.Error(),
+		}, nil
+	}
+
+	request := SubstackNoteRequest{
+		BodyJSON:         document,
+		ReplyMinimumRole: "everyone",
+	}
+
+	// Notes are single-image social posts, so only the first image is attached
+	if imageURLs := doc.Images(); len(imageURLs) > 0 {
+		attachmentID, err := p.createNoteAttachment(ctx, imageURLs[0])
+		if err != nil {
+			publisher.Logger(ctx, p.logger).Warn("Failed to attach image to note, posting text only",
+				zap.String("image_url", imageURLs[0]),
+				zap.Error(err))
+		} else {
+			request.AttachmentIDs = []string{attachmentID}
+		}
+
+		if len(imageURLs) > 1 {
+			publisher.Logger(ctx, p.logger).Info("Note has multiple images, only the first one is attached",
+				zap.Int("image_count", len(imageURLs)))
+		}
+	}
+
+	noteResponse, err := p.createNote(ctx, request)
+	if err != nil {
+		noteErr := fmt.Errorf("failed to create Substack note: %w", err)
+		return &publisher.PublishResult{
+			Success:  false,
+			Error:    noteErr,
+			ErrorMsg: noteErr.Error(),
+		}, nil
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Note published successfully",
+		zap.Int("note_id", noteResponse.ID),
+		zap.String("title", content.Title))
+
+	return &publisher.PublishResult{
+		Success:     true,
+		PublishID:   fmt.Sprintf("%s%d", notePublishIDPrefix, noteResponse.ID),
+		PublishedAt: time.Now(),
+		Metadata: map[string]string{
+			"note_id":      fmt.Sprintf("%d", noteResponse.ID),
+			"platform":     "substack",
+			"content_type": publisher.ContentTypeNote,
+		},
+	}, nil
+}
+
+func (p *SubstackPublisher) createNote(ctx context.Context, request SubstackNoteRequest) (*SubstackNoteResponse, error) {
+	url := "https://substack.com/api/v1/comment/feed"
+
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal note request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Cookie", p.sessionCookie())
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("Origin", "https://substack.com")
+	req.Header.Set("Referer", "https://substack.com/home")
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/138.0.0.0 Safari/537.36")
+
+	resp, err := p.doWithSession(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, statusError(resp.StatusCode, body)
+	}
+
+	var noteResponse SubstackNoteResponse
+	if err := json.Unmarshal(body, &noteResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &noteResponse, nil
+}
+
+// createNoteAttachment uploads an image to Substack and registers it as a note attachment
+func (p *SubstackPublisher) createNoteAttachment(ctx context.Context, imageURL string) (string, error) {
+	uploadedURL, err := p.uploadImage(ctx, imageURL, 0)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload image: %w", err)
+	}
+
+	jsonData, err := json.Marshal(SubstackNoteAttachmentRequest{
+		URL:  uploadedURL,
+		Type: "image",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal attachment request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://substack.com/api/v1/comment/attachment", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Cookie", p.sessionCookie())
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("Origin", "https://substack.com")
+	req.Header.Set("Referer", "https://substack.com/home")
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/138.0.0.0 Safari/537.36")
+
+	resp, err := p.doWithSession(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return "", statusError(resp.StatusCode, body)
+	}
+
+	var attachmentResponse SubstackNoteAttachmentResponse
+	if err := json.Unmarshal(body, &attachmentResponse); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return attachmentResponse.ID, nil
+}
+
+func (p *SubstackPublisher) deleteNote(ctx context.Context, noteID int) error {
+	url := fmt.Sprintf("https://substack.com/api/v1/comment/%d", noteID)
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Cookie", p.sessionCookie())
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("Origin", "https://substack.com")
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/138.0.0.0 Safari/537.36")
+
+	resp, err := p.doWithSession(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return statusError(resp.StatusCode, body)
 	}
 
 	return nil
@@ -505,11 +1099,19 @@ func (p *SubstackPublisher) updateDraft(ctx context.Context, draftID int, reques
 
 func (p *SubstackPublisher) uploadImage(ctx context.Context, imageURL string, postID int) (string, error) {
 	// Download the image from the URL
-	base64Image, err := p.downloadAndEncodeImage(ctx, imageURL)
+	base64Image, hash, err := p.downloadAndEncodeImage(ctx, imageURL)
 	if err != nil {
 		return "", fmt.Errorf("failed to download and encode image: %w", err)
 	}
-	
+
+	// Substack serves uploads from its CDN for any post, so an earlier upload can be reused
+	if asset, ok := p.mediaCache.Lookup(ctx, "substack", p.domain, "image", hash); ok {
+		publisher.Logger(ctx, p.logger).Debug("Reusing uploaded Substack image",
+			zap.String("image_url", imageURL),
+			zap.String("uploaded_url", asset.URL))
+		return asset.URL, nil
+	}
+
 	url := fmt.Sprintf("https://%s/api/v1/image", p.domain)
 
 	request := SubstackImageUploadRequest{
@@ -528,7 +1130,7 @@ func (p *SubstackPublisher) uploadImage(ctx context.Context, imageURL string, po
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Cookie", p.cookie)
+	req.Header.Set("Cookie", p.sessionCookie())
 	req.Header.Set("Accept", "*/*")
 	req.Header.Set("Accept-Language", "en,zh-CN;q=0.9,zh;q=0.8")
 	req.Header.Set("Origin", fmt.Sprintf("https://%s", p.domain))
@@ -541,7 +1143,7 @@ func (p *SubstackPublisher) uploadImage(ctx context.Context, imageURL string, po
 	req.Header.Set("Sec-Fetch-Mode", "cors")
 	req.Header.Set("Sec-Fetch-Site", "same-origin")
 
-	resp, err := p.client.Do(req)
+	resp, err := p.doWithSession(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to send request: %w", err)
 	}
@@ -553,7 +1155,7 @@ func (p *SubstackPublisher) uploadImage(ctx context.Context, imageURL string, po
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		return "", statusError(resp.StatusCode, body)
 	}
 
 	var uploadResponse SubstackImageUploadResponse
@@ -561,6 +1163,16 @@ func (p *SubstackPublisher) uploadImage(ctx context.Context, imageURL string, po
 		return "", fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	if uploadResponse.URL != "" {
+		p.mediaCache.Store(ctx, models.MediaAsset{
+			Hash:     hash,
+			Platform: "substack",
+			Account:  p.domain,
+			Kind:     "image",
+			URL:      uploadResponse.URL,
+		})
+	}
+
 	return uploadResponse.URL, nil
 }
 
@@ -570,27 +1182,28 @@ func (p *SubstackPublisher) checkDraftExists(ctx context.Context, draftID int) (
 	return true, nil
 }
 
-func (p *SubstackPublisher) downloadAndEncodeImage(ctx context.Context, imageURL string) (string, error) {
+// downloadAndEncodeImage returns the image as a data URL along with the hash of its bytes
+func (p *SubstackPublisher) downloadAndEncodeImage(ctx context.Context, imageURL string) (string, string, error) {
 	// Download the image
 	req, err := http.NewRequestWithContext(ctx, "GET", imageURL, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return "", "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to download image: %w", err)
+		return "", "", fmt.Errorf("failed to download image: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to download image, status: %d", resp.StatusCode)
+		return "", "", fmt.Errorf("failed to download image, status: %d", resp.StatusCode)
 	}
 
 	// Read image data
 	imageData, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read image data: %w", err)
+		return "", "", fmt.Errorf("failed to read image data: %w", err)
 	}
 
 	// Encode to base64 with data URL prefix
@@ -603,10 +1216,10 @@ func (p *SubstackPublisher) downloadAndEncodeImage(ctx context.Context, imageURL
 	base64Data := base64.StdEncoding.EncodeToString(imageData)
 	dataURL := fmt.Sprintf("data:%s;base64,%s", contentType, base64Data)
 
-	p.logger.Debug("Image downloaded and encoded", 
+	publisher.Logger(ctx, p.logger).Debug("Image downloaded and encoded", 
 		zap.String("url", imageURL),
 		zap.String("content_type", contentType),
 		zap.Int("data_size", len(imageData)))
 
-	return dataURL, nil
+	return dataURL, publisher.HashMedia(imageData), nil
 }
\ No newline at end of file
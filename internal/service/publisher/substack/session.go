@@ -0,0 +1,241 @@
+package substack
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"go.uber.org/zap"
+)
+
+// sessionCookieName is the Substack cookie that carries the login session
+const sessionCookieName = "substack.sid"
+
+// maxLoginRedirects bounds how many redirects are followed when exchanging a sign-in link
+const maxLoginRedirects = 10
+
+// VerifyCredentials lists a single draft to verify the session is still accepted. It checks the
+// session publishes use, the configured one until a publish refreshed it, on a publisher of its
+// own; a rejected session is not refreshed, as a sign-in link only works once and is kept for
+// the next publish.
+func (p *SubstackPublisher) VerifyCredentials(ctx context.Context, config publisher.PublishConfig) error {
+	probe := &SubstackPublisher{
+		logger: p.logger,
+		client: p.client,
+		domain: config.Config["domain"],
+	}
+	_, err := probe.probeSession(ctx, p.currentSession(config))
+	return err
+}
+
+// currentSession is the cookie publishes send: the refreshed session while the configured cookie
+// is unchanged, otherwise the configured one
+func (p *SubstackPublisher) currentSession(config publisher.PublishConfig) string {
+	p.sessionMu.RLock()
+	defer p.sessionMu.RUnlock()
+
+	cookie := config.Config["cookie"]
+	if cookie == p.configuredCookie && p.cookie != "" {
+		return p.cookie
+	}
+	if cookie == "" && config.Config["session_id"] != "" {
+		cookie = sessionCookieName + "=" + config.Config["session_id"]
+	}
+	return cookie
+}
+
+// doWithSession sends an authenticated request and, if Substack rejects the session,
+// refreshes it once and retries the request with the new cookie
+func (p *SubstackPublisher) doWithSession(req *http.Request) (*http.Response, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isAuthFailure(resp.StatusCode) || !p.canRefreshSession() {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	p.logger.Warn("Substack session rejected, refreshing",
+		zap.String("url", req.URL.String()),
+		zap.Int("status_code", resp.StatusCode))
+
+	if err := p.refreshSession(req.Context()); err != nil {
+		return nil, err
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewind request body: %w", err)
+		}
+		retry.Body = body
+	}
+	retry.Header.Set("Cookie", p.sessionCookie())
+
+	return p.client.Do(retry)
+}
+
+func (p *SubstackPublisher) sessionCookie() string {
+	p.sessionMu.RLock()
+	defer p.sessionMu.RUnlock()
+	return p.cookie
+}
+
+func (p *SubstackPublisher) canRefreshSession() bool {
+	p.sessionMu.RLock()
+	defer p.sessionMu.RUnlock()
+	return p.sessionID != "" || p.loginLink != ""
+}
+
+// refreshSession tries the configured substack.sid first, then exchanges the sign-in link
+func (p *SubstackPublisher) refreshSession(ctx context.Context) error {
+	p.sessionMu.Lock()
+	defer p.sessionMu.Unlock()
+
+	if p.sessionID != "" {
+		cookie := mergeCookies(p.cookie, []*http.Cookie{{Name: sessionCookieName, Value: p.sessionID}})
+		refreshed, err := p.probeSession(ctx, cookie)
+		if err == nil {
+			p.cookie = refreshed
+			publisher.Logger(ctx, p.logger).Info("Substack session refreshed from session ID")
+			return nil
+		}
+		publisher.Logger(ctx, p.logger).Warn("Failed to refresh Substack session from session ID", zap.Error(err))
+	}
+
+	if p.loginLink != "" {
+		// Sign-in links only work once, so never try the same link twice
+		link := p.loginLink
+		p.loginLink = ""
+
+		cookies, err := p.exchangeLoginLink(ctx, link)
+		if err != nil {
+			publisher.Logger(ctx, p.logger).Warn("Failed to exchange Substack sign-in link", zap.Error(err))
+		} else if refreshed, err := p.probeSession(ctx, mergeCookies(p.cookie, cookies)); err != nil {
+			publisher.Logger(ctx, p.logger).Warn("Session from Substack sign-in link was rejected", zap.Error(err))
+		} else {
+			p.cookie = refreshed
+			publisher.Logger(ctx, p.logger).Info("Substack session refreshed from sign-in link")
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: unable to refresh Substack session, update the cookie", publisher.ErrCredentialsExpired)
+}
+
+// probeSession checks a cookie against the drafts API and returns it merged with any cookies Substack sets
+func (p *SubstackPublisher) probeSession(ctx context.Context, cookie string) (string, error) {
+	url := fmt.Sprintf("https://%s/api/v1/drafts?offset=0&limit=1", p.domain)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Cookie", cookie)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", statusError(resp.StatusCode, body)
+	}
+
+	return mergeCookies(cookie, resp.Cookies()), nil
+}
+
+// exchangeLoginLink follows a sign-in link and collects the cookies set along the redirect chain
+func (p *SubstackPublisher) exchangeLoginLink(ctx context.Context, link string) ([]*http.Cookie, error) {
+	// A copy of the platform client, so the retries and proxy apply, that stops at redirects
+	client := *p.client
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	var cookies []*http.Cookie
+	next := link
+	for i := 0; i < maxLoginRedirects && next != ""; i++ {
+		req, err := http.NewRequestWithContext(ctx, "GET", next, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Cookie", mergeCookies("", cookies))
+		req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/138.0.0.0 Safari/537.36")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+		resp.Body.Close()
+
+		cookies = append(cookies, resp.Cookies()...)
+
+		next = ""
+		if location, err := resp.Location(); err == nil {
+			next = location.String()
+		}
+	}
+
+	for _, cookie := range cookies {
+		if cookie.Name == sessionCookieName && cookie.Value != "" {
+			return cookies, nil
+		}
+	}
+	return nil, fmt.Errorf("sign-in link did not return a %s cookie", sessionCookieName)
+}
+
+// mergeCookies overlays new cookies onto a Cookie header value, replacing cookies with the same name
+func mergeCookies(header string, cookies []*http.Cookie) string {
+	var names []string
+	values := make(map[string]string)
+
+	for _, part := range strings.Split(header, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || name == "" {
+			continue
+		}
+		if _, exists := values[name]; !exists {
+			names = append(names, name)
+		}
+		values[name] = value
+	}
+
+	for _, cookie := range cookies {
+		if cookie.MaxAge < 0 {
+			continue
+		}
+		if _, exists := values[cookie.Name]; !exists {
+			names = append(names, cookie.Name)
+		}
+		values[cookie.Name] = cookie.Value
+	}
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+"="+values[name])
+	}
+	return strings.Join(parts, "; ")
+}
+
+func isAuthFailure(statusCode int) bool {
+	return statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden
+}
+
+// statusError turns a failed Substack response into an error classified by its status, marking
+// rejected sessions as expired credentials
+func statusError(statusCode int, body []byte) error {
+	if isAuthFailure(statusCode) {
+		return fmt.Errorf("%w: Substack rejected the session cookie (status %d): %s", publisher.ErrCredentialsExpired, statusCode, string(body))
+	}
+	return publisher.NewError(publisher.CodeForStatus(statusCode), fmt.Errorf("API returned status %d: %s", statusCode, string(body)))
+}
@@ -4,15 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"regexp"
 	"strings"
 
+	"github.com/ifuryst/ripple/internal/content"
 	"github.com/ifuryst/ripple/internal/service/publisher"
 )
 
 // SubstackTransformer transforms content for Substack publication
 type SubstackTransformer struct {
-	imageURLPattern *regexp.Regexp
+	injections []BlockInjection
 }
 
 // SubstackDocument represents Substack's document structure
@@ -35,18 +35,17 @@ type SubstackMark struct {
 }
 
 func NewSubstackTransformer() *SubstackTransformer {
-	return &SubstackTransformer{
-		imageURLPattern: regexp.MustCompile(`!\[([^\]]*)\]\(([^)]+)\)`),
-	}
+	return &SubstackTransformer{}
 }
 
-func (t *SubstackTransformer) Transform(ctx context.Context, content string) (string, error) {
-	// Convert Notion blocks to Substack format
-	document, err := t.convertNotionBlocksToSubstack(content)
-	if err != nil {
-		return "", fmt.Errorf("failed to convert Notion blocks to Substack format: %w", err)
-	}
-	
+// SetBlockInjections configures the Substack-native blocks added to every transformed post
+func (t *SubstackTransformer) SetBlockInjections(injections []BlockInjection) {
+	t.injections = injections
+}
+
+func (t *SubstackTransformer) Transform(ctx context.Context, doc *content.Document) (string, error) {
+	document := t.renderDocument(doc)
+
 	// Serialize to JSON string
 	jsonBytes, err := json.Marshal(document)
 	if err != nil {
@@ -56,385 +55,271 @@ func (t *SubstackTransformer) Transform(ctx context.Context, content string) (st
 	return string(jsonBytes), nil
 }
 
-func (t *SubstackTransformer) ExtractImages(content string) []string {
-	var imageURLs []string
-	
-	// Try to parse as Notion blocks JSON first
-	var blocks []map[string]any
-	if err := json.Unmarshal([]byte(content), &blocks); err == nil {
-		// This is Notion blocks JSON, extract images from blocks
-		for _, block := range blocks {
-			if blockType, ok := block["type"].(string); ok && blockType == "image" {
-				if blockContent, ok := block["image"].(map[string]any); ok {
-					imageURL := t.extractImageURLFromBlock(blockContent)
-					if imageURL != "" {
-						imageURLs = append(imageURLs, imageURL)
-					}
-				}
-			}
-		}
-	} else {
-		// Fallback to markdown pattern matching
-		matches := t.imageURLPattern.FindAllStringSubmatch(content, -1)
-		for _, match := range matches {
-			if len(match) >= 3 {
-				imageURLs = append(imageURLs, match[2])
-			}
-		}
-	}
-	
-	return imageURLs
-}
-
-func (t *SubstackTransformer) extractImageURLFromBlock(blockContent map[string]any) string {
-	// Try to get from file object (for uploaded images)
-	if fileObj, ok := blockContent["file"].(map[string]any); ok {
-		if url, ok := fileObj["url"].(string); ok {
-			return url
+// TransformNote renders a document as the flat paragraph document accepted by Substack Notes
+func (t *SubstackTransformer) TransformNote(ctx context.Context, doc *content.Document) (SubstackDocument, error) {
+	// Notes only support paragraphs with inline marks, so every text block becomes a paragraph
+	var nodes []SubstackNode
+	addParagraph := func(spans []content.Span) {
+		if inline := t.renderSpans(spans); len(inline) > 0 {
+			nodes = append(nodes, SubstackNode{
+				Type:    "paragraph",
+				Content: inline,
+			})
 		}
 	}
 
-	// Try to get from external object (for external images)
-	if externalObj, ok := blockContent["external"].(map[string]any); ok {
-		if url, ok := externalObj["url"].(string); ok {
-			return url
+	for _, block := range doc.Blocks {
+		switch block.Type {
+		case content.BlockList:
+			for _, item := range block.List.Items {
+				addParagraph(item.Text)
+			}
+		case content.BlockCode:
+			addParagraph([]content.Span{{Text: block.Code}})
+		default:
+			addParagraph(block.Text)
 		}
 	}
 
-	return ""
+	return SubstackDocument{
+		Type:    "doc",
+		Content: nodes,
+	}, nil
 }
 
 func (t *SubstackTransformer) UpdateImageReferences(content string, resources []publisher.Resource) string {
 	result := content
-	
+
 	for _, resource := range resources {
 		if resource.Type == publisher.ResourceTypeImage && resource.Metadata["uploaded_url"] != "" {
 			originalURL := resource.Metadata["original_url"]
 			uploadedURL := resource.Metadata["uploaded_url"]
-			
+
 			// Update image references in the JSON content
 			result = strings.ReplaceAll(result, originalURL, uploadedURL)
 		}
 	}
-	
+
 	return result
 }
 
-func (t *SubstackTransformer) convertNotionBlocksToSubstack(blocksJSON string) (SubstackDocument, error) {
-	var blocks []map[string]any
-	if err := json.Unmarshal([]byte(blocksJSON), &blocks); err != nil {
-		return SubstackDocument{}, fmt.Errorf("failed to unmarshal Notion blocks: %w", err)
-	}
+func (t *SubstackTransformer) renderDocument(doc *content.Document) SubstackDocument {
+	// Footnote definitions are rendered as native footnotes instead of body paragraphs. Numbers
+	// linked to the References section count as references, but stay links unless they are
+	// footnotes after all.
+	footnotes, footnoteBlocks := t.collectFootnotes(footnoteLinksAsMarkers(doc.Blocks, nil))
+	blocks := footnoteLinksAsMarkers(doc.Blocks, func(number int) bool {
+		_, defined := footnotes[number]
+		return defined
+	})
 
 	var nodes []SubstackNode
-	var currentBulletList []SubstackNode
-	var currentOrderedList []SubstackNode
-	numberedListCounter := 0
-
 	for i, block := range blocks {
-		substackNode, skip, isNumberedList, isBulletList := t.convertBlockToSubstack(block, &numberedListCounter)
-		if skip {
+		if footnoteBlocks[i] {
 			continue
 		}
-
-		// Handle list grouping
-		if isBulletList {
-			currentBulletList = append(currentBulletList, substackNode)
-			// Check if next block is also a bullet list item
-			if i+1 < len(blocks) {
-				nextBlockType, _ := blocks[i+1]["type"].(string)
-				if nextBlockType != "bulleted_list_item" {
-					// End of bullet list
-					nodes = append(nodes, SubstackNode{
-						Type:    "bullet_list",
-						Content: currentBulletList,
-					})
-					currentBulletList = nil
-				}
-			} else {
-				// Last block, end bullet list
-				nodes = append(nodes, SubstackNode{
-					Type:    "bullet_list",
-					Content: currentBulletList,
-				})
-				currentBulletList = nil
-			}
-		} else if isNumberedList {
-			currentOrderedList = append(currentOrderedList, substackNode)
-			// Check if next block is also a numbered list item
-			if i+1 < len(blocks) {
-				nextBlockType, _ := blocks[i+1]["type"].(string)
-				if nextBlockType != "numbered_list_item" {
-					// End of ordered list
-					nodes = append(nodes, SubstackNode{
-						Type: "ordered_list",
-						Attrs: map[string]interface{}{
-							"start": 1,
-							"order": 1,
-						},
-						Content: currentOrderedList,
-					})
-					currentOrderedList = nil
-					numberedListCounter = 0
-				}
-			} else {
-				// Last block, end ordered list
-				nodes = append(nodes, SubstackNode{
-					Type: "ordered_list",
-					Attrs: map[string]interface{}{
-						"start": 1,
-						"order": 1,
-					},
-					Content: currentOrderedList,
-				})
-				currentOrderedList = nil
-				numberedListCounter = 0
-			}
-		} else {
-			// Reset counters if this is not a list item
-			numberedListCounter = 0
-			if substackNode.Type != "" {
-				nodes = append(nodes, substackNode)
-			}
+		if node, ok := t.renderBlock(block); ok {
+			nodes = append(nodes, node)
 		}
 	}
 
+	nodes = injectBlocks(nodes, t.injections)
+	nodes = applyFootnotes(nodes, footnotes)
+
 	return SubstackDocument{
 		Type:    "doc",
 		Content: nodes,
-	}, nil
-}
-
-func (t *SubstackTransformer) convertBlockToSubstack(block map[string]any, numberedListCounter *int) (substackNode SubstackNode, skip bool, isNumberedList bool, isBulletList bool) {
-	blockType, ok := block["type"].(string)
-	if !ok {
-		return SubstackNode{}, true, false, false
-	}
-
-	blockContent, ok := block[blockType].(map[string]any)
-	if !ok {
-		return SubstackNode{}, true, false, false
 	}
+}
 
-	switch blockType {
-	case "paragraph":
-		content := t.extractRichTextToSubstack(blockContent)
-		if len(content) > 0 {
-			return SubstackNode{
-				Type:    "paragraph",
-				Content: content,
-			}, false, false, false
+func (t *SubstackTransformer) renderBlock(block content.Block) (SubstackNode, bool) {
+	switch block.Type {
+	case content.BlockHeading:
+		inline := t.renderSpans(block.Text)
+		if len(inline) == 0 {
+			return SubstackNode{}, false
 		}
-		return SubstackNode{}, true, false, false
+		return SubstackNode{
+			Type: "heading",
+			Attrs: map[string]interface{}{
+				"level": block.Level,
+			},
+			Content: inline,
+		}, true
 
-	case "heading_1":
-		content := t.extractRichTextToSubstack(blockContent)
-		if len(content) > 0 {
-			return SubstackNode{
-				Type: "heading",
-				Attrs: map[string]interface{}{
-					"level": 1,
-				},
-				Content: content,
-			}, false, false, false
-		}
-		return SubstackNode{}, true, false, false
+	case content.BlockList:
+		return t.renderList(block.List)
 
-	case "heading_2":
-		content := t.extractRichTextToSubstack(blockContent)
-		if len(content) > 0 {
-			return SubstackNode{
-				Type: "heading",
-				Attrs: map[string]interface{}{
-					"level": 2,
-				},
-				Content: content,
-			}, false, false, false
+	case content.BlockQuote:
+		inline := t.renderSpans(block.Text)
+		if len(inline) == 0 {
+			return SubstackNode{}, false
 		}
-		return SubstackNode{}, true, false, false
-
-	case "heading_3":
-		content := t.extractRichTextToSubstack(blockContent)
-		if len(content) > 0 {
-			return SubstackNode{
-				Type: "heading",
-				Attrs: map[string]interface{}{
-					"level": 3,
-				},
-				Content: content,
-			}, false, false, false
-		}
-		return SubstackNode{}, true, false, false
-
-	case "bulleted_list_item":
-		content := t.extractRichTextToSubstack(blockContent)
-		if len(content) > 0 {
-			return SubstackNode{
-				Type: "list_item",
-				Content: []SubstackNode{
-					{
-						Type:    "paragraph",
-						Content: content,
-					},
-				},
-			}, false, false, true
-		}
-		return SubstackNode{}, true, false, false
-
-	case "numbered_list_item":
-		content := t.extractRichTextToSubstack(blockContent)
-		if len(content) > 0 {
-			*numberedListCounter++
-			return SubstackNode{
-				Type: "list_item",
-				Content: []SubstackNode{
-					{
-						Type:    "paragraph",
-						Content: content,
-					},
-				},
-			}, false, true, false
-		}
-		return SubstackNode{}, true, false, false
-
-	case "quote":
-		content := t.extractRichTextToSubstack(blockContent)
-		if len(content) > 0 {
-			return SubstackNode{
-				Type: "blockquote",
-				Content: []SubstackNode{
-					{
-						Type:    "paragraph",
-						Content: content,
-					},
+		return SubstackNode{
+			Type: "blockquote",
+			Content: []SubstackNode{
+				{
+					Type:    "paragraph",
+					Content: inline,
 				},
-			}, false, false, false
-		}
-		return SubstackNode{}, true, false, false
+			},
+		}, true
 
-	case "code":
-		text := t.extractPlainTextFromRichText(blockContent)
-		language := ""
-		if lang, ok := blockContent["language"].(string); ok && lang != "" {
-			language = lang
+	case content.BlockCode:
+		if block.Code == "" {
+			return SubstackNode{}, false
 		}
-		if text != "" {
-			return SubstackNode{
-				Type: "code_block",
-				Attrs: map[string]interface{}{
-					"language": language,
-				},
-				Content: []SubstackNode{
-					{
-						Type: "text",
-						Text: text,
-					},
+		return SubstackNode{
+			Type: "code_block",
+			Attrs: map[string]interface{}{
+				"language": block.Language,
+			},
+			Content: []SubstackNode{
+				{
+					Type: "text",
+					Text: block.Code,
 				},
-			}, false, false, false
-		}
-		return SubstackNode{}, true, false, false
+			},
+		}, true
 
-	case "divider":
+	case content.BlockDivider:
 		return SubstackNode{
 			Type: "horizontal_rule",
-		}, false, false, false
+		}, true
+
+	case content.BlockImage:
+		return t.renderImage(block.Image), true
+
+	case content.BlockVideo:
+		return t.renderVideo(block.Video), true
 
-	case "image":
-		return t.convertImageBlockToSubstack(blockContent), false, false, false
+	case content.BlockFile:
+		return t.renderFile(block.File), true
 
-	case "column_list", "column":
-		// These are container blocks, their content comes from children
-		return SubstackNode{}, true, false, false
+	case content.BlockTable:
+		return t.renderTable(block.Table)
 
 	default:
-		// For other block types, try to extract text as a paragraph
-		content := t.extractRichTextToSubstack(blockContent)
-		if len(content) > 0 {
-			return SubstackNode{
-				Type:    "paragraph",
-				Content: content,
-			}, false, false, false
+		inline := t.renderSpans(block.Text)
+		if len(inline) == 0 {
+			return SubstackNode{}, false
 		}
-		return SubstackNode{}, true, false, false
+		return SubstackNode{
+			Type:    "paragraph",
+			Content: inline,
+		}, true
 	}
 }
 
-func (t *SubstackTransformer) extractRichTextToSubstack(blockContent map[string]any) []SubstackNode {
-	richText, ok := blockContent["rich_text"].([]any)
-	if !ok {
-		return []SubstackNode{}
-	}
-
-	var nodes []SubstackNode
-	for _, rt := range richText {
-		if rtMap, ok := rt.(map[string]any); ok {
-			if plainText, ok := rtMap["plain_text"].(string); ok {
-				node := t.applySubstackFormatting(plainText, rtMap)
-				if node.Type != "" {
-					nodes = append(nodes, node)
-				}
+func (t *SubstackTransformer) renderList(list *content.List) (SubstackNode, bool) {
+	var items []SubstackNode
+	for _, item := range list.Items {
+		spans := item.Text
+		// Substack has no task lists, so to-do items keep their state as a checkbox character
+		if item.Checked != nil {
+			box := "☐ "
+			if *item.Checked {
+				box = "☑ "
 			}
+			spans = append([]content.Span{{Text: box}}, spans...)
 		}
-	}
 
-	return nodes
-}
+		inline := t.renderSpans(spans)
+		if len(inline) == 0 {
+			continue
+		}
+		items = append(items, SubstackNode{
+			Type: "list_item",
+			Content: []SubstackNode{
+				{
+					Type:    "paragraph",
+					Content: inline,
+				},
+			},
+		})
+	}
+	if len(items) == 0 {
+		return SubstackNode{}, false
+	}
 
-func (t *SubstackTransformer) extractPlainTextFromRichText(blockContent map[string]any) string {
-	richText, ok := blockContent["rich_text"].([]any)
-	if !ok {
-		return ""
+	if !list.Ordered {
+		return SubstackNode{
+			Type:    "bullet_list",
+			Content: items,
+		}, true
 	}
+	return SubstackNode{
+		Type: "ordered_list",
+		Attrs: map[string]interface{}{
+			"start": 1,
+			"order": 1,
+		},
+		Content: items,
+	}, true
+}
 
-	var text string
-	for _, rt := range richText {
-		if rtMap, ok := rt.(map[string]any); ok {
-			if plainText, ok := rtMap["plain_text"].(string); ok {
-				text += plainText
+// renderTable renders each row as a paragraph with the cells separated by " | " inside a
+// blockquote to keep the rows together, since the Substack editor has no tables
+func (t *SubstackTransformer) renderTable(table *content.Table) (SubstackNode, bool) {
+	var rows []SubstackNode
+	for i, cells := range table.Rows {
+		var inline []SubstackNode
+		for j, cell := range cells {
+			if j > 0 {
+				inline = append(inline, SubstackNode{Type: "text", Text: " | "})
 			}
+			cellNodes := t.renderSpans(cell)
+			if i == 0 && table.HasHeader {
+				for k := range cellNodes {
+					cellNodes[k].Marks = append(cellNodes[k].Marks, SubstackMark{Type: "strong"})
+				}
+			}
+			inline = append(inline, cellNodes...)
+		}
+		if len(inline) > 0 {
+			rows = append(rows, SubstackNode{Type: "paragraph", Content: inline})
 		}
 	}
+	if len(rows) == 0 {
+		return SubstackNode{}, false
+	}
+	return SubstackNode{Type: "blockquote", Content: rows}, true
+}
 
-	return text
+func (t *SubstackTransformer) renderSpans(spans []content.Span) []SubstackNode {
+	nodes := []SubstackNode{}
+	for _, span := range spans {
+		if span.Text != "" {
+			nodes = append(nodes, t.renderSpan(span))
+		}
+	}
+	return nodes
 }
 
-func (t *SubstackTransformer) applySubstackFormatting(text string, rtMap map[string]any) SubstackNode {
+func (t *SubstackTransformer) renderSpan(span content.Span) SubstackNode {
 	node := SubstackNode{
 		Type: "text",
-		Text: text,
+		Text: span.Text,
 	}
 
 	var marks []SubstackMark
-
-	// Check for annotations
-	if annotations, ok := rtMap["annotations"].(map[string]any); ok {
-		// Apply bold formatting
-		if bold, ok := annotations["bold"].(bool); ok && bold {
-			marks = append(marks, SubstackMark{Type: "strong"})
-		}
-
-		// Apply italic formatting
-		if italic, ok := annotations["italic"].(bool); ok && italic {
-			marks = append(marks, SubstackMark{Type: "em"})
-		}
-
-		// Apply code formatting
-		if code, ok := annotations["code"].(bool); ok && code {
-			marks = append(marks, SubstackMark{Type: "code"})
-		}
-
-		// Apply strikethrough formatting
-		if strikethrough, ok := annotations["strikethrough"].(bool); ok && strikethrough {
-			marks = append(marks, SubstackMark{Type: "strikethrough"})
-		}
+	if span.Bold {
+		marks = append(marks, SubstackMark{Type: "strong"})
 	}
-
-	// Handle links
-	if href, ok := rtMap["href"].(string); ok && href != "" {
+	if span.Italic {
+		marks = append(marks, SubstackMark{Type: "em"})
+	}
+	if span.Code {
+		marks = append(marks, SubstackMark{Type: "code"})
+	}
+	if span.Strikethrough {
+		marks = append(marks, SubstackMark{Type: "strikethrough"})
+	}
+	if span.Link != "" {
 		marks = append(marks, SubstackMark{
 			Type: "link",
 			Attrs: map[string]interface{}{
-				"href":   href,
+				"href":   span.Link,
 				"target": "_blank",
 				"rel":    "noopener noreferrer nofollow",
 				"class":  nil,
@@ -449,67 +334,68 @@ func (t *SubstackTransformer) applySubstackFormatting(text string, rtMap map[str
 	return node
 }
 
-func (t *SubstackTransformer) convertImageBlockToSubstack(blockContent map[string]any) SubstackNode {
-	// Extract image URL from different possible sources
-	var imageURL string
-	var alt string
-
-	// Try to get from file object (for uploaded images)
-	if fileObj, ok := blockContent["file"].(map[string]any); ok {
-		if url, ok := fileObj["url"].(string); ok {
-			imageURL = url
-		}
+func (t *SubstackTransformer) renderImage(image *content.Image) SubstackNode {
+	return SubstackNode{
+		Type: "captionedImage",
+		Content: []SubstackNode{
+			{
+				Type: "image2",
+				Attrs: map[string]interface{}{
+					"src":              image.URL,
+					"srcNoWatermark":   nil,
+					"fullscreen":       nil,
+					"imageSize":        nil,
+					"height":           nil,
+					"width":            nil,
+					"resizeWidth":      nil,
+					"bytes":            nil,
+					"alt":              content.PlainText(image.Caption),
+					"title":            nil,
+					"type":             "image/png",
+					"href":             nil,
+					"belowTheFold":     false,
+					"topImage":         false,
+					"internalRedirect": "",
+					"isProcessing":     false,
+					"align":            nil,
+					"offset":           false,
+				},
+			},
+		},
 	}
+}
 
-	// Try to get from external object (for external images)
-	if imageURL == "" {
-		if externalObj, ok := blockContent["external"].(map[string]any); ok {
-			if url, ok := externalObj["url"].(string); ok {
-				imageURL = url
-			}
-		}
+// renderVideo shows a video as its thumbnail linking to the video, since Substack posts can't
+// embed uploaded videos through the API. Videos without a thumbnail become a link.
+func (t *SubstackTransformer) renderVideo(video *content.Video) SubstackNode {
+	if thumbnail := video.ThumbnailURL(); thumbnail != "" {
+		node := t.renderImage(&content.Image{URL: thumbnail, Caption: video.Caption})
+		node.Content[0].Attrs["href"] = video.URL
+		return node
 	}
 
-	// Try to get caption for alt text
-	if caption, ok := blockContent["caption"].([]any); ok && len(caption) > 0 {
-		if captionMap, ok := caption[0].(map[string]any); ok {
-			if plainText, ok := captionMap["plain_text"].(string); ok {
-				alt = plainText
-			}
-		}
+	label := content.PlainText(video.Caption)
+	if label == "" {
+		label = "Watch the video"
 	}
-
-	if imageURL != "" {
-		return SubstackNode{
-			Type: "captionedImage",
-			Content: []SubstackNode{
-				{
-					Type: "image2",
-					Attrs: map[string]interface{}{
-						"src":                imageURL,
-						"srcNoWatermark":     nil,
-						"fullscreen":         nil,
-						"imageSize":          nil,
-						"height":             nil,
-						"width":              nil,
-						"resizeWidth":        nil,
-						"bytes":              nil,
-						"alt":                alt,
-						"title":              nil,
-						"type":               "image/png",
-						"href":               nil,
-						"belowTheFold":       false,
-						"topImage":           false,
-						"internalRedirect":   "",
-						"isProcessing":       false,
-						"align":              nil,
-						"offset":             false,
-					},
-				},
-			},
-		}
+	return SubstackNode{
+		Type:    "paragraph",
+		Content: []SubstackNode{t.renderSpan(content.Span{Text: "▶ " + label, Link: video.URL})},
 	}
-
-	return SubstackNode{}
 }
 
+// renderFile renders an attachment as a paragraph with its linked name, kind and caption
+func (t *SubstackTransformer) renderFile(file *content.File) SubstackNode {
+	spans := []content.Span{
+		{Text: "📎 "},
+		{Text: file.FileName(), Bold: true, Link: file.URL},
+		{Text: " · " + file.Kind()},
+	}
+	if len(file.Caption) > 0 {
+		spans = append(append(spans, content.Span{Text: " — "}), file.Caption...)
+	}
+	return SubstackNode{
+		Type:    "paragraph",
+		Content: t.renderSpans(spans),
+	}
+}
@@ -0,0 +1,76 @@
+package substack
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// unsupportedImageFormats are image types the Substack image endpoint rejects; it accepts
+// JPEG, PNG, GIF and WebP
+var unsupportedImageFormats = map[string]bool{
+	".svg":  true,
+	".heic": true,
+	".heif": true,
+	".tif":  true,
+	".tiff": true,
+	".bmp":  true,
+	".avif": true,
+	".ico":  true,
+}
+
+// Validate checks that the post has a title and that its images can be re-hosted on Substack,
+// which needs a URL to download them from and one of the formats it accepts
+func (p *SubstackPublisher) Validate(content publisher.PublishContent, config publisher.PublishConfig) []publisher.Violation {
+	var violations []publisher.Violation
+
+	// Notes have no title
+	if content.Title == "" && !content.IsNote() {
+		violations = append(violations, publisher.Violation{
+			Field:   "title",
+			Rule:    "required",
+			Message: "title is required",
+		})
+	}
+
+	doc, err := content.ContentDocument()
+	if err != nil {
+		// Unparseable content fails the transform with a clearer error
+		return violations
+	}
+
+	for _, imageURL := range doc.Images() {
+		parsed, err := url.Parse(imageURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			violations = append(violations, publisher.Violation{
+				Field:   "images",
+				Rule:    "image_url",
+				Message: fmt.Sprintf("image %s is not an http(s) URL Substack can fetch", truncateURL(imageURL)),
+			})
+			continue
+		}
+
+		// Notion file URLs keep the original file extension
+		ext := strings.ToLower(path.Ext(parsed.Path))
+		if unsupportedImageFormats[ext] {
+			violations = append(violations, publisher.Violation{
+				Field:   "images",
+				Rule:    "image_format",
+				Message: fmt.Sprintf("image %s is a %s file, Substack accepts JPEG, PNG, GIF and WebP", path.Base(parsed.Path), ext),
+			})
+		}
+	}
+
+	return violations
+}
+
+// truncateURL shortens data URLs and other long values for messages
+func truncateURL(value string) string {
+	if len(value) > 80 {
+		return value[:80] + "..."
+	}
+	return value
+}
@@ -0,0 +1,27 @@
+package publisher
+
+import "context"
+
+// TitleTranslator translates post titles into English, e.g. to slug titles written in other
+// languages
+type TitleTranslator interface {
+	TranslateTitle(ctx context.Context, title string) (string, error)
+}
+
+// TitleTranslatorUser is implemented by publishers that can derive paths from translated titles
+type TitleTranslatorUser interface {
+	SetTitleTranslator(translator TitleTranslator)
+}
+
+// SetTitleTranslator sets the title translator for the publishers that use one
+func (m *Manager) SetTitleTranslator(translator TitleTranslator) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.titleTranslator = translator
+	for _, publisher := range m.publishers {
+		if user, ok := publisher.(TitleTranslatorUser); ok {
+			user.SetTitleTranslator(translator)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ifuryst/ripple/pkg/util"
+)
+
+// UTMHook appends UTM parameters to the outbound links of a post, so the analytics of the
+// linked sites can tell which platform the traffic came from. Links that already carry UTM
+// parameters are left alone.
+type UTMHook struct {
+	sources     map[string]string
+	medium      string
+	campaign    string
+	ignoreHosts []string
+}
+
+// NewUTMHook creates a UTM hook. utm_source is the platform's entry in sources or else the
+// platform name, and utm_campaign is campaign or else the first tag of the page. Links to the
+// ignored hosts, and their subdomains, are never tagged.
+func NewUTMHook(sources map[string]string, medium, campaign string, ignoreHosts []string) *UTMHook {
+	var ignored []string
+	for _, host := range ignoreHosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			ignored = append(ignored, host)
+		}
+	}
+
+	return &UTMHook{
+		sources:     sources,
+		medium:      medium,
+		campaign:    campaign,
+		ignoreHosts: ignored,
+	}
+}
+
+func (h *UTMHook) Name() string {
+	return "utm"
+}
+
+func (h *UTMHook) Stages() []HookStage {
+	return []HookStage{HookPreTransform}
+}
+
+func (h *UTMHook) Run(ctx context.Context, event *HookEvent) (string, error) {
+	doc, err := event.Content.ContentDocument()
+	if err != nil {
+		return "", nil
+	}
+
+	params := h.params(event.Platform, event.Content.Tags)
+	tagged := 0
+	doc.MapLinks(func(link string) string {
+		tagLink, ok := h.tag(link, params)
+		if ok {
+			tagged++
+		}
+		return tagLink
+	})
+	event.Content.Document = doc
+
+	if tagged == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("added UTM parameters to %d links", tagged), nil
+}
+
+// params returns the encoded UTM parameters for a platform, in the usual order
+func (h *UTMHook) params(platform string, tags []string) string {
+	source := h.sources[platform]
+	if source == "" {
+		source = h.sources[PlatformType(platform)]
+	}
+	if source == "" {
+		source = PlatformType(platform)
+	}
+
+	campaign := h.campaign
+	if campaign == "" && len(tags) > 0 {
+		campaign = util.GenerateSlug(tags[0])
+	}
+
+	pairs := []string{"utm_source=" + url.QueryEscape(source)}
+	if h.medium != "" {
+		pairs = append(pairs, "utm_medium="+url.QueryEscape(h.medium))
+	}
+	if campaign != "" {
+		pairs = append(pairs, "utm_campaign="+url.QueryEscape(campaign))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// tag appends the parameters to an external http(s) link, keeping its query and fragment as they are
+func (h *UTMHook) tag(link, params string) (string, bool) {
+	parsed, err := url.Parse(link)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || h.ignored(parsed.Hostname()) {
+		return link, false
+	}
+	for key := range parsed.Query() {
+		if strings.HasPrefix(strings.ToLower(key), "utm_") {
+			return link, false
+		}
+	}
+
+	if parsed.RawQuery == "" {
+		parsed.RawQuery = params
+	} else {
+		parsed.RawQuery += "&" + params
+	}
+	return parsed.String(), true
+}
+
+func (h *UTMHook) ignored(host string) bool {
+	host = strings.ToLower(host)
+	for _, ignored := range h.ignoreHosts {
+		if host == ignored || strings.HasSuffix(host, "."+ignored) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ifuryst/ripple/internal/content"
+)
+
+// VariantDivider is the marker that splits pages at their divider blocks
+const VariantDivider = "divider"
+
+// VariantHook publishes different sections of a page to different platforms, e.g. the Chinese
+// half of a bilingual page to WeChat and the English half to Substack. Sections are separated
+// by divider blocks or by a block whose text is the marker, like a toggle titled "English",
+// and are numbered from 1. Pages without a marker, and platforms without a section, are
+// published whole.
+type VariantHook struct {
+	marker   string
+	sections map[string]int
+}
+
+// NewVariantHook creates a variant hook from the marker and a comma separated list of
+// platform:section pairs
+func NewVariantHook(marker, sections string) (*VariantHook, error) {
+	hook := &VariantHook{
+		marker:   strings.TrimSpace(marker),
+		sections: make(map[string]int),
+	}
+	if hook.marker == "" {
+		hook.marker = VariantDivider
+	}
+
+	for _, entry := range splitList(sections) {
+		// Split at the last colon, platform names may name an account like wechat-official:en
+		separator := strings.LastIndex(entry, ":")
+		if separator < 0 {
+			return nil, fmt.Errorf("invalid variant section %q", entry)
+		}
+		section, err := strconv.Atoi(strings.TrimSpace(entry[separator+1:]))
+		if err != nil || section <= 0 {
+			return nil, fmt.Errorf("invalid variant section %q", entry)
+		}
+		hook.sections[strings.TrimSpace(entry[:separator])] = section
+	}
+	return hook, nil
+}
+
+func (h *VariantHook) Name() string {
+	return "variant"
+}
+
+func (h *VariantHook) Stages() []HookStage {
+	return []HookStage{HookPreTransform}
+}
+
+func (h *VariantHook) Run(ctx context.Context, event *HookEvent) (string, error) {
+	section, ok := h.sections[event.Platform]
+	if !ok {
+		section, ok = h.sections[PlatformType(event.Platform)]
+	}
+	if !ok {
+		return "", nil
+	}
+
+	doc, err := event.Content.ContentDocument()
+	if err != nil {
+		return "", nil
+	}
+
+	sections := doc.Split(h.isMarker)
+	if len(sections) < 2 {
+		return "", nil
+	}
+	if section > len(sections) {
+		return "", fmt.Errorf("page has %d sections, %s publishes section %d", len(sections), event.Platform, section)
+	}
+
+	event.Content.Document = sections[section-1]
+	return fmt.Sprintf("published section %d of %d", section, len(sections)), nil
+}
+
+// isMarker reports whether a block separates two sections
+func (h *VariantHook) isMarker(block content.Block) bool {
+	if h.marker == VariantDivider {
+		return block.Type == content.BlockDivider
+	}
+	return len(block.Text) > 0 && strings.EqualFold(strings.TrimSpace(content.PlainText(block.Text)), h.marker)
+}
@@ -0,0 +1,93 @@
+package publisher
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+type restoredVersionKey struct{}
+
+// WithRestoredVersion returns a context for republishing a stored content version, so the
+// version recorded by the publish points at the one it restored
+func WithRestoredVersion(ctx context.Context, versionID uint) context.Context {
+	return context.WithValue(ctx, restoredVersionKey{}, versionID)
+}
+
+func restoredVersion(ctx context.Context) *uint {
+	if versionID, ok := ctx.Value(restoredVersionKey{}).(uint); ok {
+		return &versionID
+	}
+	return nil
+}
+
+// recordVersion stores a snapshot of the page a job published and the output the publisher
+// rendered from it, numbered after the latest version of the page and platform. The publish
+// already went out, so a failure is only logged.
+func (m *Manager) recordVersion(ctx context.Context, job *models.DistributionJob, page *models.NotionPage, output string) {
+	summary := page.Summary
+	if summary == "" {
+		summary = page.AISummary
+	}
+	properties := page.Properties
+	if properties == "" {
+		properties = "{}"
+	}
+
+	version := &models.ContentVersion{
+		PageID:         page.ID,
+		PlatformID:     job.PlatformID,
+		JobID:          job.ID,
+		JobStatus:      job.Status,
+		ContentHash:    job.ContentHash,
+		Title:          page.Title,
+		ENTitle:        page.ENTitle,
+		Summary:        summary,
+		SEODescription: page.SEODescription,
+		Tags:           page.Tags,
+		Owner:          page.Owner,
+		PostDate:       page.PostDate,
+		CoverURL:       page.CoverURL,
+		Series:         page.Series,
+		ContentType:    page.ContentType,
+		Properties:     properties,
+		Content:        page.Content,
+		Document:       page.Document,
+		Output:         output,
+		RestoredFrom:   restoredVersion(ctx),
+	}
+
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The page row serializes drafts of the same page saved at the same time
+		var locked models.NotionPage
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&locked, page.ID).Error; err != nil {
+			return err
+		}
+
+		var latest int
+		if err := tx.Model(&models.ContentVersion{}).
+			Where("page_id = ? AND platform_id = ?", page.ID, job.PlatformID).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&latest).Error; err != nil {
+			return err
+		}
+		version.Version = latest + 1
+		return tx.Omit(clause.Associations).Create(version).Error
+	})
+	if err != nil {
+		Logger(ctx, m.logger).Warn("Failed to record content version",
+			zap.Uint("job_id", job.ID),
+			zap.Uint("page_id", page.ID),
+			zap.Error(err))
+		return
+	}
+
+	Logger(ctx, m.logger).Debug("Content version recorded",
+		zap.Uint("job_id", job.ID),
+		zap.Uint("version_id", version.ID),
+		zap.Int("version", version.Version))
+}
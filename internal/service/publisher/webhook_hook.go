@@ -0,0 +1,183 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ifuryst/ripple/pkg/httpclient"
+)
+
+// WebhookHook posts every hook event to a URL. Before publishing, the endpoint can stop the
+// publish by answering with {"allow": false, "message": "..."}; a "message" is recorded on the
+// job in any case. With a secret, the body is signed in the X-Ripple-Signature header as
+// sha256=<hex HMAC>.
+type WebhookHook struct {
+	url         string
+	secret      string
+	stages      []HookStage
+	failOnError bool
+	client      *http.Client
+}
+
+// webhookPayload is the JSON body sent to the webhook
+type webhookPayload struct {
+	Stage     HookStage      `json:"stage"`
+	Platform  string         `json:"platform"`
+	Draft     bool           `json:"draft"`
+	PageID    string         `json:"page_id"`
+	Title     string         `json:"title"`
+	Summary   string         `json:"summary"`
+	Tags      []string       `json:"tags"`
+	Author    string         `json:"author"`
+	Content   string         `json:"content,omitempty"`
+	Result    *webhookResult `json:"result,omitempty"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+type webhookResult struct {
+	Success   bool              `json:"success"`
+	PublishID string            `json:"publish_id"`
+	URL       string            `json:"url"`
+	Error     string            `json:"error,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+type webhookResponse struct {
+	Allow   *bool  `json:"allow"`
+	Message string `json:"message"`
+}
+
+// NewWebhookHook creates a hook calling url at the given stages. Unless failOnError is set, an
+// unreachable or failing endpoint is recorded but does not stop the publish.
+func NewWebhookHook(url, secret string, stages []HookStage, failOnError bool, timeout time.Duration) *WebhookHook {
+	return &WebhookHook{
+		url:         url,
+		secret:      secret,
+		stages:      stages,
+		failOnError: failOnError,
+		client:      httpclient.New(httpclient.WithTimeout(timeout)),
+	}
+}
+
+// ParseHookStages parses a comma separated list of stages
+func ParseHookStages(value string) ([]HookStage, error) {
+	var stages []HookStage
+	for _, name := range strings.Split(value, ",") {
+		stage := HookStage(strings.TrimSpace(name))
+		switch stage {
+		case "":
+			continue
+		case HookPreTransform, HookPrePublish, HookPostPublish:
+			stages = append(stages, stage)
+		default:
+			return nil, fmt.Errorf("unknown hook stage %q", stage)
+		}
+	}
+	return stages, nil
+}
+
+func (h *WebhookHook) Name() string {
+	return "webhook"
+}
+
+func (h *WebhookHook) Stages() []HookStage {
+	return h.stages
+}
+
+func (h *WebhookHook) Run(ctx context.Context, event *HookEvent) (string, error) {
+	response, err := h.call(ctx, event)
+	if err != nil {
+		if h.failOnError {
+			return "", err
+		}
+		return fmt.Sprintf("ignored: %v", err), nil
+	}
+
+	if response.Allow != nil && !*response.Allow {
+		message := response.Message
+		if message == "" {
+			message = "publish not allowed"
+		}
+		return "", errors.New(message)
+	}
+	return response.Message, nil
+}
+
+func (h *WebhookHook) call(ctx context.Context, event *HookEvent) (*webhookResponse, error) {
+	payload := webhookPayload{
+		Stage:     event.Stage,
+		Platform:  event.Platform,
+		Draft:     event.Draft,
+		Timestamp: time.Now(),
+	}
+	if event.Content != nil {
+		payload.PageID = event.Content.ID
+		payload.Title = event.Content.Title
+		payload.Summary = event.Content.Summary
+		payload.Tags = event.Content.Tags
+		payload.Author = event.Content.Author
+		// Only checks before publishing need the content itself
+		if event.Stage != HookPostPublish {
+			payload.Content = event.Content.Content
+		}
+	}
+	if event.Result != nil {
+		payload.Result = &webhookResult{
+			Success:   event.Result.Success,
+			PublishID: event.Result.PublishID,
+			URL:       event.Result.URL,
+			Error:     event.Result.ErrorMsg,
+			Metadata:  event.Result.Metadata,
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Ripple")
+	if h.secret != "" {
+		mac := hmac.New(sha256.New, []byte(h.secret))
+		mac.Write(body)
+		req.Header.Set("X-Ripple-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err := fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+		// A failing endpoint is not a verdict on the content
+		if resp.StatusCode >= 500 {
+			return nil, NewError(ErrorCodePlatform5xx, err)
+		}
+		return nil, err
+	}
+
+	var response webhookResponse
+	if len(bytes.TrimSpace(respBody)) > 0 {
+		// Endpoints that do not answer with JSON simply allow the publish
+		_ = json.Unmarshal(respBody, &response)
+	}
+	return &response, nil
+}
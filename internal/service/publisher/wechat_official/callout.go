@@ -0,0 +1,114 @@
+package wechat_official
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ifuryst/ripple/internal/content"
+)
+
+// calloutColor is how callouts of a Notion color are boxed: the box background and the bar on
+// its left edge
+type calloutColor struct {
+	Background string `yaml:"background"`
+	Border     string `yaml:"border"`
+}
+
+// defaultCalloutColor names the palette entry of callouts left at Notion's default color
+const defaultCalloutColor = "default"
+
+// defaultCalloutPalette follows the colors of Notion's light theme
+var defaultCalloutPalette = map[string]calloutColor{
+	"default": {Background: "#f1f1ef", Border: "#9b9a97"},
+	"gray":    {Background: "#f1f1ef", Border: "#9b9a97"},
+	"brown":   {Background: "#f4eeee", Border: "#64473a"},
+	"orange":  {Background: "#fbecdd", Border: "#d9730d"},
+	"yellow":  {Background: "#fbf3db", Border: "#dfab01"},
+	"green":   {Background: "#edf3ec", Border: "#0f7b6c"},
+	"blue":    {Background: "#e7f3f8", Border: "#0b6e99"},
+	"purple":  {Background: "#f6f3f9", Border: "#6940a5"},
+	"pink":    {Background: "#faf1f5", Border: "#ad1a72"},
+	"red":     {Background: "#fdebec", Border: "#e03e3e"},
+}
+
+// cssColorPattern matches the colors a palette may use: hex, rgb()/rgba() or a color name.
+// Anything else could break out of the inline style it is written into.
+var cssColorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{3,8}|rgba?\([0-9.,%\s]+\)|[a-zA-Z]+)$`)
+
+// parseCalloutPalette parses a YAML mapping of Notion colors to callout colors over the default
+// palette, e.g. {blue: {background: "#e8f0fe", border: "#1a73e8"}}. A color that sets only one
+// of its fields keeps the default of the other; empty input is the default palette.
+func parseCalloutPalette(source string) (map[string]calloutColor, error) {
+	palette := make(map[string]calloutColor, len(defaultCalloutPalette))
+	for name, color := range defaultCalloutPalette {
+		palette[name] = color
+	}
+	if strings.TrimSpace(source) == "" {
+		return palette, nil
+	}
+
+	var configured map[string]calloutColor
+	if err := yaml.Unmarshal([]byte(source), &configured); err != nil {
+		return nil, err
+	}
+	for name, color := range configured {
+		name = calloutColorName(name)
+		for _, value := range []string{color.Background, color.Border} {
+			if value != "" && !cssColorPattern.MatchString(value) {
+				return nil, fmt.Errorf("invalid color %q for callout color %s", value, name)
+			}
+		}
+
+		entry, ok := palette[name]
+		if !ok {
+			entry = palette[defaultCalloutColor]
+		}
+		if color.Background != "" {
+			entry.Background = color.Background
+		}
+		if color.Border != "" {
+			entry.Border = color.Border
+		}
+		palette[name] = entry
+	}
+	return palette, nil
+}
+
+// calloutColorName maps a Notion color to its palette entry; text and background colors of the
+// same hue share one, since callouts are always boxed
+func calloutColorName(color string) string {
+	name := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(color)), "_background")
+	if name == "" {
+		return defaultCalloutColor
+	}
+	return name
+}
+
+// renderCallout renders a callout as a colored box with its emoji icon, in the color of the
+// palette entry of its Notion color
+func renderCallout(block content.Block, palette map[string]calloutColor) string {
+	text := renderSpans(block.Text)
+	if text == "" {
+		return ""
+	}
+
+	var icon, color string
+	if block.Callout != nil {
+		icon, color = block.Callout.Icon, block.Callout.Color
+	}
+	style, ok := palette[calloutColorName(color)]
+	if !ok {
+		style = palette[defaultCalloutColor]
+	}
+
+	iconHTML := ""
+	if icon != "" {
+		// Emoji fonts come first so the icon keeps its colors where the article font has glyphs too
+		iconHTML = fmt.Sprintf(`<span style="flex:none;margin-right:10px;font-size:18px;line-height:1.6;font-family:'Apple Color Emoji', 'Segoe UI Emoji', 'Noto Color Emoji', sans-serif">%s</span>`, escapeHTML(icon))
+	}
+	return fmt.Sprintf(`<section style="display:flex;align-items:flex-start;margin:20px 10px;padding:12px 14px;background:%s;border-left:4px solid %s;border-radius:4px">%s<p style="flex:1;margin:0;text-align:left;color:#3f3f3f;line-height:1.6;font-family:Optima-Regular, Optima, PingFangSC-light, PingFangTC-light, 'PingFang SC', Cambria, Cochin, Georgia, Times, 'Times New Roman', serif;font-size:16px">%s</p></section>`,
+		style.Background, style.Border, iconHTML, text)
+}
@@ -0,0 +1,85 @@
+package wechat_official
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// TestCheckCredentialsDuringPublish runs credential checks while the publisher is initialized for
+// publishes; run with -race to catch checks touching the state publishes use
+func TestCheckCredentialsDuringPublish(t *testing.T) {
+	var tokens, stableTokens atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var token string
+		switch r.URL.Path {
+		case "/cgi-bin/token":
+			token = fmt.Sprintf("token-%d", tokens.Add(1))
+		case "/cgi-bin/stable_token":
+			token = fmt.Sprintf("stable-token-%d", stableTokens.Add(1))
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(WeChatAccessTokenResponse{AccessToken: token, ExpiresIn: 7200})
+	}))
+	defer server.Close()
+
+	p := NewWeChatOfficialPublisher(zap.NewNop()).(*WeChatOfficialPublisher)
+	manager := publisher.NewPublishManager(zap.NewNop(), nil)
+	if err := manager.RegisterPublisher(p); err != nil {
+		t.Fatalf("RegisterPublisher: %v", err)
+	}
+	config := publisher.PublishConfig{
+		PlatformName: p.GetPlatformName(),
+		Enabled:      true,
+		Config: map[string]string{
+			"app_id":       "app",
+			"app_secret":   "secret",
+			"api_base_url": server.URL,
+		},
+	}
+	manager.SetPlatformConfig(p.GetPlatformName(), config)
+
+	const rounds = 20
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < rounds; i++ {
+			if err := p.Initialize(ctx, config); err != nil {
+				t.Errorf("Initialize: %v", err)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < rounds; i++ {
+			if err := manager.CheckCredentials(ctx, p.GetPlatformName()); err != nil {
+				t.Errorf("CheckCredentials: %v", err)
+			}
+		}
+	}()
+	wg.Wait()
+
+	// Only publishes fetch a token, which would invalidate the one in use
+	if got := tokens.Load(); got != rounds {
+		t.Errorf("token API called %d times, want %d", got, rounds)
+	}
+	if got := stableTokens.Load(); got != rounds {
+		t.Errorf("stable token API called %d times, want %d", got, rounds)
+	}
+	if want := fmt.Sprintf("token-%d", rounds); p.accessToken != want {
+		t.Errorf("access token = %q, want %q", p.accessToken, want)
+	}
+}
@@ -0,0 +1,30 @@
+package wechat_official
+
+import (
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// errorCodes classifies the WeChat errcodes publishing runs into; see
+// https://developers.weixin.qq.com/doc/offiaccount/Getting_Started/Global_Return_Code.html
+var errorCodes = map[int]publisher.ErrorCode{
+	-1:    publisher.ErrorCodePlatform5xx,    // system busy
+	40001: publisher.ErrorCodeAuthExpired,    // invalid credential or access_token
+	40014: publisher.ErrorCodeAuthExpired,    // invalid access_token
+	40125: publisher.ErrorCodeAuthExpired,    // invalid appsecret
+	40164: publisher.ErrorCodeAuthExpired,    // caller IP not whitelisted
+	42001: publisher.ErrorCodeAuthExpired,    // access_token expired
+	45009: publisher.ErrorCodeRateLimited,    // daily API quota reached
+	45011: publisher.ErrorCodeRateLimited,    // API called too often
+	40006: publisher.ErrorCodeContentInvalid, // invalid media size
+	40007: publisher.ErrorCodeContentInvalid, // invalid media_id
+	40113: publisher.ErrorCodeContentInvalid, // unsupported file type
+	45002: publisher.ErrorCodeContentInvalid, // content too long
+	45003: publisher.ErrorCodeContentInvalid, // title too long
+	45004: publisher.ErrorCodeContentInvalid, // digest too long
+	53404: publisher.ErrorCodeContentInvalid, // article rejected by review
+}
+
+// apiError classifies the error of a WeChat API call by the errcode it returned
+func apiError(errcode int, err error) error {
+	return publisher.NewError(errorCodes[errcode], err)
+}
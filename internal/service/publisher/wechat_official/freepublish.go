@@ -0,0 +1,220 @@
+package wechat_official
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"go.uber.org/zap"
+)
+
+// Publish statuses of freepublish/get; see
+// https://developers.weixin.qq.com/doc/offiaccount/Publish/Get_status.html
+const (
+	freepublishSucceeded = 0
+	freepublishRunning   = 1
+)
+
+// freepublishStatusNames name the publish statuses in job metadata
+var freepublishStatusNames = map[int]string{
+	0: "published",
+	1: "publishing",
+	2: "original_check_failed",
+	3: "failed",
+	4: "rejected",
+	5: "deleted",
+	6: "banned",
+}
+
+// freepublishFailures explain the statuses of publishes that did not go out, or were taken down
+var freepublishFailures = map[int]string{
+	2: "the original content declaration failed",
+	3: "publishing failed",
+	4: "the articles were rejected by platform review",
+	5: "the articles were deleted after publishing",
+	6: "the articles were banned after publishing",
+}
+
+type freepublishGetResponse struct {
+	PublishID     string `json:"publish_id"`
+	PublishStatus int    `json:"publish_status"`
+	ArticleID     string `json:"article_id"`
+	ArticleDetail struct {
+		Count int `json:"count"`
+		Item  []struct {
+			Idx        int    `json:"idx"`
+			ArticleURL string `json:"article_url"`
+		} `json:"item"`
+	} `json:"article_detail"`
+	FailIdx []int  `json:"fail_idx"`
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+// statusName names the publish status, unknown statuses by their number
+func (r *freepublishGetResponse) statusName() string {
+	if name, ok := freepublishStatusNames[r.PublishStatus]; ok {
+		return name
+	}
+	return strconv.Itoa(r.PublishStatus)
+}
+
+// articleURL is the URL of the first article of the publish, once it went out
+func (r *freepublishGetResponse) articleURL() string {
+	for _, item := range r.ArticleDetail.Item {
+		if item.ArticleURL != "" {
+			return item.ArticleURL
+		}
+	}
+	return ""
+}
+
+// failure explains why a publish did not go out, naming the articles that failed
+func (r *freepublishGetResponse) failure() string {
+	reason, ok := freepublishFailures[r.PublishStatus]
+	if !ok {
+		reason = "unknown publish status " + strconv.Itoa(r.PublishStatus)
+	}
+	if len(r.FailIdx) > 0 {
+		articles := make([]string, len(r.FailIdx))
+		for i, idx := range r.FailIdx {
+			articles[i] = strconv.Itoa(idx)
+		}
+		reason += fmt.Sprintf(" (articles %s)", strings.Join(articles, ", "))
+	}
+	return reason
+}
+
+// isPublishID tells the publish IDs freepublish/submit returns, which are numeric, from the
+// media IDs of drafts
+func isPublishID(id string) bool {
+	_, err := strconv.ParseUint(id, 10, 64)
+	return err == nil
+}
+
+// getPublish fetches the status of a publish, and the URLs of its articles once it succeeded
+func (p *WeChatOfficialPublisher) getPublish(ctx context.Context, publishID string) (*freepublishGetResponse, error) {
+	jsonData, err := json.Marshal(map[string]string{"publish_id": publishID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/cgi-bin/freepublish/get?access_token=%s", p.baseURL, p.accessToken)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get publish status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read publish status: %w", err)
+	}
+
+	var status freepublishGetResponse
+	if err := json.Unmarshal(body, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse publish status: %w", err)
+	}
+	if status.ErrCode != 0 {
+		return nil, apiError(status.ErrCode, fmt.Errorf("WeChat freepublish API error: %s", status.ErrMsg))
+	}
+	return &status, nil
+}
+
+// deletePublish takes down the article a publish put out. The delete API wants the article ID,
+// which freepublish/get returns once the publish succeeded.
+func (p *WeChatOfficialPublisher) deletePublish(ctx context.Context, publishID string) error {
+	logger := publisher.Logger(ctx, p.logger)
+
+	status, err := p.getPublish(ctx, publishID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case status.PublishStatus == freepublishRunning:
+		return fmt.Errorf("WeChat is still publishing %s, it can be deleted once it is out", publishID)
+	case status.ArticleID == "":
+		// The publish failed or was taken down already, nothing is left online
+		logger.Info("WeChat publish has no article to delete",
+			zap.String("publish_id", publishID),
+			zap.String("publish_status", status.statusName()))
+		return nil
+	}
+
+	url := fmt.Sprintf("%s/cgi-bin/freepublish/delete?access_token=%s", p.baseURL, p.accessToken)
+	body, err := p.postJSON(ctx, url, map[string]interface{}{"article_id": status.ArticleID, "index": 0})
+	if err != nil {
+		return fmt.Errorf("failed to delete published article: %w", err)
+	}
+
+	var deleteResp struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal(body, &deleteResp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if deleteResp.ErrCode != 0 {
+		return apiError(deleteResp.ErrCode, fmt.Errorf("WeChat freepublish delete API error: %d - %s", deleteResp.ErrCode, deleteResp.ErrMsg))
+	}
+
+	logger.Info("WeChat published article deleted",
+		zap.String("publish_id", publishID),
+		zap.String("article_id", status.ArticleID))
+	return nil
+}
+
+// VerifyDeployment follows up on a submitted publish, which WeChat runs in the background and
+// can still reject: it is live once freepublish/get returns the article URL, and rejected when
+// the publish failed or was taken down.
+func (p *WeChatOfficialPublisher) VerifyDeployment(ctx context.Context, metadata map[string]string, config publisher.PublishConfig) (*publisher.DeploymentStatus, error) {
+	publishID := metadata["publish_id"]
+	if publishID == "" {
+		// Drafts are not published, there is nothing to follow
+		return &publisher.DeploymentStatus{State: publisher.DeploymentLive}, nil
+	}
+
+	if p.accessToken == "" {
+		if err := p.Initialize(ctx, config); err != nil {
+			return nil, err
+		}
+	}
+	result, err := p.getPublishStatus(ctx, publishID)
+	if publisher.CodeOf(err) == publisher.ErrorCodeAuthExpired {
+		// The token expired since the publisher was initialized
+		if err := p.Initialize(ctx, config); err != nil {
+			return nil, err
+		}
+		result, err = p.getPublishStatus(ctx, publishID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !result.Success:
+		return &publisher.DeploymentStatus{
+			State:    publisher.DeploymentFailed,
+			Message:  result.ErrorMsg,
+			Rejected: true,
+		}, nil
+	case result.URL == "":
+		return &publisher.DeploymentStatus{
+			State:   publisher.DeploymentPending,
+			Message: "WeChat is still publishing the article",
+		}, nil
+	}
+	return &publisher.DeploymentStatus{State: publisher.DeploymentLive, URL: result.URL}, nil
+}
@@ -0,0 +1,123 @@
+package wechat_official
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// maxMaterialBatch is the most materials batchget_material returns at once
+const maxMaterialBatch = 20
+
+// listMaterialTypes are the material types batchget_material lists; thumbs are kept with images
+var listMaterialTypes = map[string]bool{"image": true, "video": true, "voice": true}
+
+// uploadMaterialTypes are the material types that can be uploaded as plain files; video needs a
+// title and description and is only uploaded while publishing
+var uploadMaterialTypes = map[string]bool{"image": true, "thumb": true}
+
+type batchGetMaterialResponse struct {
+	TotalCount int `json:"total_count"`
+	ItemCount  int `json:"item_count"`
+	Item       []struct {
+		MediaID    string `json:"media_id"`
+		Name       string `json:"name"`
+		UpdateTime int64  `json:"update_time"`
+		URL        string `json:"url"`
+	} `json:"item"`
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+// ListMaterials lists permanent materials of a type with the batchget_material API, newest first
+func (p *WeChatOfficialPublisher) ListMaterials(ctx context.Context, materialType string, offset, count int, config publisher.PublishConfig) (*publisher.MaterialList, error) {
+	if !listMaterialTypes[materialType] {
+		return nil, fmt.Errorf("%w: %q, expected image, video or voice", publisher.ErrInvalidMaterialType, materialType)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if count <= 0 || count > maxMaterialBatch {
+		count = maxMaterialBatch
+	}
+
+	url := fmt.Sprintf("%s/cgi-bin/material/batchget_material?access_token=%s", p.baseURL, p.accessToken)
+	body, err := p.postJSON(ctx, url, map[string]interface{}{
+		"type":   materialType,
+		"offset": offset,
+		"count":  count,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list materials: %w", err)
+	}
+
+	var batch batchGetMaterialResponse
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return nil, fmt.Errorf("failed to parse material list response: %w", err)
+	}
+	if batch.ErrCode != 0 {
+		return nil, apiError(batch.ErrCode, fmt.Errorf("WeChat material list API error: %d - %s", batch.ErrCode, batch.ErrMsg))
+	}
+
+	list := &publisher.MaterialList{
+		Type:   materialType,
+		Total:  batch.TotalCount,
+		Offset: offset,
+		Items:  make([]publisher.Material, 0, len(batch.Item)),
+	}
+	for _, item := range batch.Item {
+		list.Items = append(list.Items, publisher.Material{
+			MediaID:   item.MediaID,
+			Name:      item.Name,
+			URL:       item.URL,
+			UpdatedAt: time.Unix(item.UpdateTime, 0),
+		})
+	}
+	return list, nil
+}
+
+// UploadMaterial uploads an image or thumb as permanent material with the add_material API
+func (p *WeChatOfficialPublisher) UploadMaterial(ctx context.Context, materialType, filePath string, config publisher.PublishConfig) (*publisher.Material, error) {
+	if !uploadMaterialTypes[materialType] {
+		return nil, fmt.Errorf("%w: %q, expected image or thumb", publisher.ErrInvalidMaterialType, materialType)
+	}
+
+	mediaID, url, err := p.mediaProcessor.uploadPermanentMaterial(ctx, filePath, materialType, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload material: %w", err)
+	}
+
+	publisher.Logger(ctx, p.logger).Info("WeChat material uploaded",
+		zap.String("type", materialType),
+		zap.String("media_id", mediaID))
+	return &publisher.Material{MediaID: mediaID, URL: url, UpdatedAt: time.Now()}, nil
+}
+
+// DeleteMaterial deletes a permanent material with the del_material API. Articles already
+// published keep showing their images, but drafts using it can't be published anymore.
+func (p *WeChatOfficialPublisher) DeleteMaterial(ctx context.Context, mediaID string, config publisher.PublishConfig) error {
+	url := fmt.Sprintf("%s/cgi-bin/material/del_material?access_token=%s", p.baseURL, p.accessToken)
+	body, err := p.postJSON(ctx, url, map[string]string{"media_id": mediaID})
+	if err != nil {
+		return fmt.Errorf("failed to delete material: %w", err)
+	}
+
+	var deleteResp struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal(body, &deleteResp); err != nil {
+		return fmt.Errorf("failed to parse material delete response: %w", err)
+	}
+	if deleteResp.ErrCode != 0 {
+		return apiError(deleteResp.ErrCode, fmt.Errorf("WeChat material delete API error: %d - %s", deleteResp.ErrCode, deleteResp.ErrMsg))
+	}
+
+	publisher.Logger(ctx, p.logger).Info("WeChat material deleted", zap.String("media_id", mediaID))
+	return nil
+}
@@ -5,7 +5,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/ifuryst/ripple/internal/models"
 	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/httpclient"
 	"io"
 	"mime/multipart"
 	"net/http"
@@ -17,11 +19,17 @@ import (
 	"go.uber.org/zap"
 )
 
+// maxVideoMaterialSize is the largest video WeChat accepts as permanent material
+const maxVideoMaterialSize = 10 << 20
+
 // WeChatMediaProcessor handles WeChat media upload and management
 type WeChatMediaProcessor struct {
 	logger      *zap.Logger
 	client      *http.Client
+	baseURL     string
 	accessToken string
+	cache       *publisher.MediaCache
+	account     string
 }
 
 // WeChatMediaResponse represents WeChat media upload response
@@ -50,17 +58,70 @@ type WeChatUploadImageResponse struct {
 
 func NewWeChatMediaProcessor(logger *zap.Logger) *WeChatMediaProcessor {
 	return &WeChatMediaProcessor{
-		logger: logger,
-		client: &http.Client{
-			Timeout: 60 * time.Second,
-		},
+		logger:  logger,
+		client:  httpclient.New(),
+		baseURL: defaultAPIBaseURL,
 	}
 }
 
+// SetHTTPClient replaces the HTTP client, e.g. to route uploads through a proxy
+func (p *WeChatMediaProcessor) SetHTTPClient(client *http.Client) {
+	p.client = client
+}
+
+// SetBaseURL overrides the WeChat API base URL
+func (p *WeChatMediaProcessor) SetBaseURL(baseURL string) {
+	p.baseURL = baseURL
+}
+
 func (p *WeChatMediaProcessor) SetAccessToken(token string) {
 	p.accessToken = token
 }
 
+// SetMediaCache sets where uploads are remembered, so the same image is uploaded only once
+func (p *WeChatMediaProcessor) SetMediaCache(cache *publisher.MediaCache) {
+	p.cache = cache
+}
+
+// SetAccount sets the app ID uploads are remembered for; media IDs belong to one account
+func (p *WeChatMediaProcessor) SetAccount(appID string) {
+	p.account = appID
+}
+
+// cachedUpload returns an earlier upload of the file as media of the kind, or uploads it and
+// remembers the media ID and URL
+func (p *WeChatMediaProcessor) cachedUpload(ctx context.Context, kind, filePath string, upload func() (string, string, error)) (string, string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	hash := publisher.HashMedia(data)
+	if asset, ok := p.cache.Lookup(ctx, "wechat-official", p.account, kind, hash); ok {
+		publisher.Logger(ctx, p.logger).Debug("Reusing uploaded WeChat media",
+			zap.String("kind", kind),
+			zap.String("hash", hash),
+			zap.String("media_id", asset.MediaID))
+		return asset.MediaID, asset.URL, nil
+	}
+
+	mediaID, url, err := upload()
+	if err != nil {
+		return "", "", err
+	}
+
+	p.cache.Store(ctx, models.MediaAsset{
+		Hash:     hash,
+		Platform: "wechat-official",
+		Account:  p.account,
+		Kind:     kind,
+		MediaID:  mediaID,
+		URL:      url,
+		Size:     int64(len(data)),
+	})
+	return mediaID, url, nil
+}
+
 func (p *WeChatMediaProcessor) GetSupportedTypes() []publisher.ResourceType {
 	return []publisher.ResourceType{
 		publisher.ResourceTypeImage,
@@ -70,8 +131,12 @@ func (p *WeChatMediaProcessor) GetSupportedTypes() []publisher.ResourceType {
 }
 
 func (p *WeChatMediaProcessor) ProcessResource(ctx context.Context, resource publisher.Resource, config publisher.PublishConfig) (*publisher.Resource, error) {
-	if resource.Type != publisher.ResourceTypeImage {
-		return &resource, nil // Only process images for now
+	switch resource.Type {
+	case publisher.ResourceTypeImage:
+	case publisher.ResourceTypeVideo:
+		return p.processVideo(ctx, resource)
+	default:
+		return &resource, nil
 	}
 
 	// Download image if it's a URL
@@ -88,13 +153,25 @@ func (p *WeChatMediaProcessor) ProcessResource(ctx context.Context, resource pub
 		return nil, fmt.Errorf("no local path or URL provided for resource")
 	}
 
-	// Upload image using uploadimg API to get permanent URL
-	wechatImageURL, err := p.uploadImage(ctx, localPath)
+	// Upload image using uploadimg API to get permanent URL. uploadimg only takes JPG and PNG,
+	// so GIFs are uploaded as image material, which keeps them animated.
+	var wechatImageURL string
+	var err error
+	if isGIF(localPath) {
+		_, wechatImageURL, err = p.cachedUpload(ctx, "image", localPath, func() (string, string, error) {
+			return p.uploadPermanentMaterial(ctx, localPath, "image", nil)
+		})
+	} else {
+		_, wechatImageURL, err = p.cachedUpload(ctx, "uploadimg", localPath, func() (string, string, error) {
+			url, err := p.uploadImage(ctx, localPath)
+			return "", url, err
+		})
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload image to WeChat: %w", err)
 	}
 
-	p.logger.Info("Successfully uploaded image to WeChat",
+	publisher.Logger(ctx, p.logger).Info("Successfully uploaded image to WeChat",
 		zap.String("resource_id", resource.ID),
 		zap.String("wechat_image_url", wechatImageURL))
 
@@ -114,20 +191,75 @@ func (p *WeChatMediaProcessor) ProcessResource(ctx context.Context, resource pub
 	processedResource.Metadata["wechat_image_url"] = wechatImageURL
 	processedResource.Metadata["wechat_uploaded"] = "true"
 
-	p.logger.Info("Image processed successfully for WeChat",
+	publisher.Logger(ctx, p.logger).Info("Image processed successfully for WeChat",
 		zap.String("resource_id", resource.ID),
 		zap.String("wechat_image_url", wechatImageURL))
 
 	return &processedResource, nil
 }
 
+// processVideo uploads a video file as permanent video material. Articles can't embed it
+// through the API, the editor inserts it from the material library.
+func (p *WeChatMediaProcessor) processVideo(ctx context.Context, resource publisher.Resource) (*publisher.Resource, error) {
+	localPath := resource.LocalPath
+	if localPath == "" && resource.URL != "" {
+		var err error
+		localPath, err = p.download(ctx, resource.URL, ".mp4")
+		if err != nil {
+			return nil, fmt.Errorf("failed to download video: %w", err)
+		}
+		defer os.Remove(localPath)
+	}
+
+	if localPath == "" {
+		return nil, fmt.Errorf("no local path or URL provided for resource")
+	}
+
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read video: %w", err)
+	}
+	if info.Size() > maxVideoMaterialSize {
+		return nil, fmt.Errorf("video is %d MB, WeChat video material is limited to %d MB", info.Size()>>20, maxVideoMaterialSize>>20)
+	}
+
+	title := resource.Metadata["title"]
+	if title == "" {
+		title = "视频"
+	}
+	description, err := json.Marshal(map[string]string{"title": title, "introduction": title})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal video description: %w", err)
+	}
+
+	mediaID, _, err := p.cachedUpload(ctx, "video", localPath, func() (string, string, error) {
+		return p.uploadPermanentMaterial(ctx, localPath, "video", map[string]string{"description": string(description)})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload video to WeChat: %w", err)
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Uploaded video to WeChat material library",
+		zap.String("resource_id", resource.ID),
+		zap.String("media_id", mediaID))
+
+	processedResource := resource
+	processedResource.Metadata = make(map[string]string)
+	for k, v := range resource.Metadata {
+		processedResource.Metadata[k] = v
+	}
+	processedResource.Metadata["wechat_media_id"] = mediaID
+	processedResource.Metadata["wechat_uploaded"] = "true"
+	return &processedResource, nil
+}
+
 func (p *WeChatMediaProcessor) ProcessResources(ctx context.Context, resources []publisher.Resource, config publisher.PublishConfig) ([]publisher.Resource, error) {
 	var processedResources []publisher.Resource
 
 	for _, resource := range resources {
 		processed, err := p.ProcessResource(ctx, resource, config)
 		if err != nil {
-			p.logger.Error("Failed to process WeChat resource",
+			publisher.Logger(ctx, p.logger).Error("Failed to process WeChat resource",
 				zap.String("resource_id", resource.ID),
 				zap.Error(err))
 			// Continue with original resource
@@ -140,9 +272,55 @@ func (p *WeChatMediaProcessor) ProcessResources(ctx context.Context, resources [
 	return processedResources, nil
 }
 
-// uploadPermanentMaterial uploads image as permanent material (recommended for articles)
-func (p *WeChatMediaProcessor) uploadPermanentMaterial(ctx context.Context, filePath, mediaType string) (string, string, error) {
-	url := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/material/add_material?access_token=%s&type=%s", p.accessToken, mediaType)
+// UploadImageMaterial uploads a local image as permanent material and returns its media_id
+func (p *WeChatMediaProcessor) UploadImageMaterial(ctx context.Context, filePath string) (string, error) {
+	mediaID, _, err := p.cachedUpload(ctx, "image", filePath, func() (string, string, error) {
+		return p.uploadPermanentMaterial(ctx, filePath, "image", nil)
+	})
+	if err != nil {
+		return "", err
+	}
+	return mediaID, nil
+}
+
+// GenerateThumbMediaID builds a cover thumbnail from a local image or URL, uploads it as
+// thumb material and returns its media_id
+func (p *WeChatMediaProcessor) GenerateThumbMediaID(ctx context.Context, source string) (string, error) {
+	srcPath := source
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		downloaded, err := p.downloadImage(ctx, source)
+		if err != nil {
+			return "", fmt.Errorf("failed to download thumbnail source: %w", err)
+		}
+		defer os.Remove(downloaded)
+		srcPath = downloaded
+	}
+
+	thumbPath := strings.TrimSuffix(srcPath, filepath.Ext(srcPath)) + "_thumb.jpg"
+	if err := generateThumbnail(srcPath, thumbPath); err != nil {
+		return "", err
+	}
+	defer os.Remove(thumbPath)
+
+	mediaID, _, err := p.cachedUpload(ctx, "thumb", thumbPath, func() (string, string, error) {
+		mediaID, err := p.uploadThumbMaterial(ctx, thumbPath)
+		return mediaID, "", err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload thumbnail: %w", err)
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Generated WeChat thumbnail",
+		zap.String("source", source),
+		zap.String("media_id", mediaID))
+
+	return mediaID, nil
+}
+
+// uploadPermanentMaterial uploads image as permanent material (recommended for articles).
+// fields are extra form fields, like the description video material requires.
+func (p *WeChatMediaProcessor) uploadPermanentMaterial(ctx context.Context, filePath, mediaType string, fields map[string]string) (string, string, error) {
+	url := fmt.Sprintf("%s/cgi-bin/material/add_material?access_token=%s&type=%s", p.baseURL, p.accessToken, mediaType)
 
 	// Open file
 	file, err := os.Open(filePath)
@@ -155,6 +333,12 @@ func (p *WeChatMediaProcessor) uploadPermanentMaterial(ctx context.Context, file
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return "", "", fmt.Errorf("failed to write form field: %w", err)
+		}
+	}
+
 	// Add file field
 	part, err := writer.CreateFormFile("media", filepath.Base(filePath))
 	if err != nil {
@@ -199,7 +383,7 @@ func (p *WeChatMediaProcessor) uploadPermanentMaterial(ctx context.Context, file
 	}
 
 	if materialResp.ErrCode != 0 {
-		return "", "", fmt.Errorf("WeChat API error: %d - %s", materialResp.ErrCode, materialResp.ErrMsg)
+		return "", "", apiError(materialResp.ErrCode, fmt.Errorf("WeChat API error: %d - %s", materialResp.ErrCode, materialResp.ErrMsg))
 	}
 
 	return materialResp.MediaID, materialResp.URL, nil
@@ -207,7 +391,7 @@ func (p *WeChatMediaProcessor) uploadPermanentMaterial(ctx context.Context, file
 
 // uploadTemporaryMedia uploads image as temporary media (3 days expiry)
 func (p *WeChatMediaProcessor) uploadTemporaryMedia(ctx context.Context, filePath, mediaType string) (string, error) {
-	url := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/media/upload?access_token=%s&type=%s", p.accessToken, mediaType)
+	url := fmt.Sprintf("%s/cgi-bin/media/upload?access_token=%s&type=%s", p.baseURL, p.accessToken, mediaType)
 
 	// Open file
 	file, err := os.Open(filePath)
@@ -264,13 +448,18 @@ func (p *WeChatMediaProcessor) uploadTemporaryMedia(ctx context.Context, filePat
 	}
 
 	if mediaResp.ErrCode != 0 {
-		return "", fmt.Errorf("WeChat API error: %d - %s", mediaResp.ErrCode, mediaResp.ErrMsg)
+		return "", apiError(mediaResp.ErrCode, fmt.Errorf("WeChat API error: %d - %s", mediaResp.ErrCode, mediaResp.ErrMsg))
 	}
 
 	return mediaResp.MediaID, nil
 }
 
 func (p *WeChatMediaProcessor) downloadImage(ctx context.Context, url string) (string, error) {
+	return p.download(ctx, url, p.getFileExtension(url))
+}
+
+// download saves a URL to a temp file with the extension
+func (p *WeChatMediaProcessor) download(ctx context.Context, url, ext string) (string, error) {
 	// Create temp directory
 	tempDir := "temp/wechat_images"
 	if err := os.MkdirAll(tempDir, 0755); err != nil {
@@ -278,7 +467,7 @@ func (p *WeChatMediaProcessor) downloadImage(ctx context.Context, url string) (s
 	}
 
 	// Generate filename
-	filename := fmt.Sprintf("wechat_%d%s", time.Now().Unix(), p.getFileExtension(url))
+	filename := fmt.Sprintf("wechat_%d%s", time.Now().UnixNano(), ext)
 	localPath := filepath.Join(tempDir, filename)
 
 	// Download image
@@ -289,12 +478,12 @@ func (p *WeChatMediaProcessor) downloadImage(ctx context.Context, url string) (s
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to download image: %w", err)
+		return "", fmt.Errorf("failed to download: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to download image: status %d", resp.StatusCode)
+		return "", fmt.Errorf("failed to download: status %d", resp.StatusCode)
 	}
 
 	// Create file
@@ -307,7 +496,7 @@ func (p *WeChatMediaProcessor) downloadImage(ctx context.Context, url string) (s
 	// Copy content
 	_, err = io.Copy(file, resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to save image: %w", err)
+		return "", fmt.Errorf("failed to save file: %w", err)
 	}
 
 	return localPath, nil
@@ -315,7 +504,7 @@ func (p *WeChatMediaProcessor) downloadImage(ctx context.Context, url string) (s
 
 // uploadThumbMaterial uploads image as thumb material for WeChat articles
 func (p *WeChatMediaProcessor) uploadThumbMaterial(ctx context.Context, filePath string) (string, error) {
-	url := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/material/add_material?access_token=%s&type=thumb", p.accessToken)
+	url := fmt.Sprintf("%s/cgi-bin/material/add_material?access_token=%s&type=thumb", p.baseURL, p.accessToken)
 
 	// Open file
 	file, err := os.Open(filePath)
@@ -372,7 +561,7 @@ func (p *WeChatMediaProcessor) uploadThumbMaterial(ctx context.Context, filePath
 	}
 
 	if thumbResp.ErrCode != 0 {
-		return "", fmt.Errorf("WeChat thumb API error: %d - %s", thumbResp.ErrCode, thumbResp.ErrMsg)
+		return "", apiError(thumbResp.ErrCode, fmt.Errorf("WeChat thumb API error: %d - %s", thumbResp.ErrCode, thumbResp.ErrMsg))
 	}
 
 	return thumbResp.MediaID, nil
@@ -380,7 +569,7 @@ func (p *WeChatMediaProcessor) uploadThumbMaterial(ctx context.Context, filePath
 
 // uploadImage uploads image using the uploadimg API to get permanent URL
 func (p *WeChatMediaProcessor) uploadImage(ctx context.Context, filePath string) (string, error) {
-	url := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/media/uploadimg?access_token=%s", p.accessToken)
+	url := fmt.Sprintf("%s/cgi-bin/media/uploadimg?access_token=%s", p.baseURL, p.accessToken)
 
 	// Open file
 	file, err := os.Open(filePath)
@@ -437,12 +626,25 @@ func (p *WeChatMediaProcessor) uploadImage(ctx context.Context, filePath string)
 	}
 
 	if uploadResp.ErrCode != 0 {
-		return "", fmt.Errorf("WeChat uploadimg API error: %d - %s", uploadResp.ErrCode, uploadResp.ErrMsg)
+		return "", apiError(uploadResp.ErrCode, fmt.Errorf("WeChat uploadimg API error: %d - %s", uploadResp.ErrCode, uploadResp.ErrMsg))
 	}
 
 	return uploadResp.URL, nil
 }
 
+// isGIF reports whether a file is a GIF by its content, since URLs often lack an extension
+func isGIF(filePath string) bool {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	header := make([]byte, 512)
+	n, _ := io.ReadFull(file, header)
+	return http.DetectContentType(header[:n]) == "image/gif"
+}
+
 func (p *WeChatMediaProcessor) getFileExtension(url string) string {
 	parts := strings.Split(url, ".")
 	if len(parts) > 1 {
@@ -464,7 +666,7 @@ func (p *WeChatMediaProcessor) getFileExtension(url string) string {
 
 // GetMediaInfo retrieves information about uploaded media
 func (p *WeChatMediaProcessor) GetMediaInfo(ctx context.Context, mediaID string) (map[string]string, error) {
-	url := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/material/get_material?access_token=%s", p.accessToken)
+	url := fmt.Sprintf("%s/cgi-bin/material/get_material?access_token=%s", p.baseURL, p.accessToken)
 
 	reqBody := map[string]string{
 		"media_id": mediaID,
@@ -499,7 +701,7 @@ func (p *WeChatMediaProcessor) GetMediaInfo(ctx context.Context, mediaID string)
 		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err != nil {
 			return nil, fmt.Errorf("failed to decode error response: %w", err)
 		}
-		return nil, fmt.Errorf("WeChat API error: %d - %s", errorResp.ErrCode, errorResp.ErrMsg)
+		return nil, apiError(errorResp.ErrCode, fmt.Errorf("WeChat API error: %d - %s", errorResp.ErrCode, errorResp.ErrMsg))
 	}
 
 	// Success - media exists
@@ -0,0 +1,103 @@
+package wechat_official
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// datacubeLocation is the time zone the datacube API reports days in
+var datacubeLocation = time.FixedZone("CST", 8*60*60)
+
+type articleTotalRequest struct {
+	BeginDate string `json:"begin_date"`
+	EndDate   string `json:"end_date"`
+}
+
+type articleTotalResponse struct {
+	List []struct {
+		MsgID   string `json:"msgid"`
+		Title   string `json:"title"`
+		Details []struct {
+			StatDate         string `json:"stat_date"`
+			IntPageReadUser  int64  `json:"int_page_read_user"`
+			IntPageReadCount int64  `json:"int_page_read_count"`
+			ShareCount       int64  `json:"share_count"`
+		} `json:"details"`
+	} `json:"list"`
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+// CollectMetrics reads the article totals from the datacube API. WeChat reports them by the day
+// the article went out and only once that day is over, for the seven days that follow.
+func (p *WeChatOfficialPublisher) CollectMetrics(ctx context.Context, post publisher.PublishedPost, config publisher.PublishConfig) (*publisher.PostMetrics, error) {
+	msgID := post.Metadata["msg_id"]
+	if msgID == "" && post.Metadata["draft_status"] == "saved" {
+		// Drafts have no stats until they are sent from the WeChat backend
+		return nil, nil
+	}
+
+	publishDate := post.PublishedAt.In(datacubeLocation).Format("2006-01-02")
+	if publishDate >= time.Now().In(datacubeLocation).Format("2006-01-02") {
+		return nil, nil
+	}
+
+	jsonData, err := json.Marshal(articleTotalRequest{BeginDate: publishDate, EndDate: publishDate})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal article total request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/datacube/getarticletotal?access_token=%s", p.baseURL, p.accessToken)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request article totals: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read article total response: %w", err)
+	}
+
+	var totals articleTotalResponse
+	if err := json.Unmarshal(body, &totals); err != nil {
+		return nil, fmt.Errorf("failed to parse article total response: %w", err)
+	}
+	if totals.ErrCode != 0 {
+		return nil, apiError(totals.ErrCode, fmt.Errorf("WeChat datacube API error: %s", totals.ErrMsg))
+	}
+
+	for _, article := range totals.List {
+		// msgid is the msg_data_id of the send followed by the index of the article in it
+		matched := msgID != "" && strings.HasPrefix(article.MsgID, msgID+"_")
+		if !matched && msgID == "" {
+			matched = article.Title == post.Title
+		}
+		if !matched || len(article.Details) == 0 {
+			continue
+		}
+
+		// Details are cumulative per day, so the last one is the latest total
+		latest := article.Details[len(article.Details)-1]
+		return &publisher.PostMetrics{
+			Views:   latest.IntPageReadCount,
+			Readers: latest.IntPageReadUser,
+			Shares:  latest.ShareCount,
+		}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,119 @@
+package wechat_official
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+type draftGetResponse struct {
+	NewsItem []struct {
+		Title string `json:"title"`
+		// URL is a temporary link to the draft
+		URL string `json:"url"`
+	} `json:"news_item"`
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+// previewRequest sends an mpnews message to a single WeChat ID, which is how drafts are
+// reviewed on a phone before they go out to all followers
+type previewRequest struct {
+	ToWxName string `json:"towxname"`
+	MsgType  string `json:"msgtype"`
+	MPNews   struct {
+		MediaID string `json:"media_id"`
+	} `json:"mpnews"`
+}
+
+// PreviewDraft reads a draft with the draft/get API, which returns a temporary link to it
+func (p *WeChatOfficialPublisher) PreviewDraft(ctx context.Context, draftID string, config publisher.PublishConfig) (*publisher.DraftPreview, error) {
+	url := fmt.Sprintf("%s/cgi-bin/draft/get?access_token=%s", p.baseURL, p.accessToken)
+	body, err := p.postJSON(ctx, url, map[string]string{"media_id": draftID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get draft: %w", err)
+	}
+
+	var draft draftGetResponse
+	if err := json.Unmarshal(body, &draft); err != nil {
+		return nil, fmt.Errorf("failed to parse draft response: %w", err)
+	}
+	if draft.ErrCode != 0 {
+		return nil, apiError(draft.ErrCode, fmt.Errorf("WeChat draft get API error: %d - %s", draft.ErrCode, draft.ErrMsg))
+	}
+	if len(draft.NewsItem) == 0 {
+		return nil, fmt.Errorf("WeChat draft %s has no articles", draftID)
+	}
+
+	// Ripple saves one article per draft
+	return &publisher.DraftPreview{
+		DraftID: draftID,
+		Title:   draft.NewsItem[0].Title,
+		URL:     draft.NewsItem[0].URL,
+	}, nil
+}
+
+// SendDraftPreview sends a draft to a WeChat ID with the preview API. The reviewer has to follow
+// the account; preview_wxname configures the default reviewer.
+func (p *WeChatOfficialPublisher) SendDraftPreview(ctx context.Context, draftID, recipient string, config publisher.PublishConfig) (string, error) {
+	if recipient == "" {
+		recipient = config.Config["preview_wxname"]
+	}
+	if recipient == "" {
+		return "", publisher.ErrNoPreviewRecipient
+	}
+
+	request := previewRequest{ToWxName: recipient, MsgType: "mpnews"}
+	request.MPNews.MediaID = draftID
+
+	url := fmt.Sprintf("%s/cgi-bin/message/mass/preview?access_token=%s", p.baseURL, p.accessToken)
+	body, err := p.postJSON(ctx, url, request)
+	if err != nil {
+		return "", fmt.Errorf("failed to send draft preview: %w", err)
+	}
+
+	var previewResp struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal(body, &previewResp); err != nil {
+		return "", fmt.Errorf("failed to parse preview response: %w", err)
+	}
+	if previewResp.ErrCode != 0 {
+		return "", apiError(previewResp.ErrCode, fmt.Errorf("WeChat preview API error: %d - %s", previewResp.ErrCode, previewResp.ErrMsg))
+	}
+
+	publisher.Logger(ctx, p.logger).Info("WeChat draft preview sent",
+		zap.String("media_id", draftID),
+		zap.String("towxname", recipient))
+	return recipient, nil
+}
+
+// postJSON posts a JSON body to a WeChat API and returns the response body
+func (p *WeChatOfficialPublisher) postJSON(ctx context.Context, url string, payload interface{}) ([]byte, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
@@ -5,23 +5,43 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"html"
 	"io"
 	"net/http"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/ifuryst/ripple/internal/models"
 	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/httpclient"
 
 	"go.uber.org/zap"
 )
 
+const (
+	articleTypeNewsPic = "newspic"
+	defaultAPIBaseURL  = "https://api.weixin.qq.com"
+	// maxDigestChars is the longest digest the draft API accepts
+	maxDigestChars = 120
+)
+
+var (
+	blockEndPattern     = regexp.MustCompile(`(?i)</(p|section|h[1-6]|li|blockquote)>|<br\s*/?>`)
+	htmlTagPattern      = regexp.MustCompile(`<[^>]*>`)
+	multiNewlinePattern = regexp.MustCompile(`\n{3,}`)
+)
+
 // WeChatOfficialPublisher handles publishing to WeChat Official Account
 type WeChatOfficialPublisher struct {
 	logger             *zap.Logger
 	contentTransformer *WeChatTransformer
 	mediaProcessor     *WeChatMediaProcessor
 	client             *http.Client
+	baseURL            string
 	accessToken        string
+	// account names the account when several Official Accounts are configured
+	account string
 }
 
 // WeChat API response structures
@@ -37,15 +57,26 @@ type WeChatDraftAddRequest struct {
 }
 
 type WeChatArticle struct {
-	Title              string `json:"title"`
-	Author             string `json:"author"`
-	Digest             string `json:"digest"`
-	Content            string `json:"content"`
-	ContentSourceURL   string `json:"content_source_url"`
-	ThumbMediaID       string `json:"thumb_media_id"`
-	ShowCoverPic       int    `json:"show_cover_pic"`
-	NeedOpenComment    int    `json:"need_open_comment"`
-	OnlyFansCanComment int    `json:"only_fans_can_comment"`
+	ArticleType        string           `json:"article_type,omitempty"`
+	Title              string           `json:"title"`
+	Author             string           `json:"author"`
+	Digest             string           `json:"digest"`
+	Content            string           `json:"content"`
+	ContentSourceURL   string           `json:"content_source_url"`
+	ThumbMediaID       string           `json:"thumb_media_id,omitempty"`
+	ShowCoverPic       int              `json:"show_cover_pic"`
+	NeedOpenComment    int              `json:"need_open_comment"`
+	OnlyFansCanComment int              `json:"only_fans_can_comment"`
+	ImageInfo          *WeChatImageInfo `json:"image_info,omitempty"`
+}
+
+// WeChatImageInfo lists the images of a newspic (图片消息) article
+type WeChatImageInfo struct {
+	ImageList []WeChatImageItem `json:"image_list"`
+}
+
+type WeChatImageItem struct {
+	ImageMediaID string `json:"image_media_id"`
 }
 
 type WeChatDraftResponse struct {
@@ -73,20 +104,40 @@ func NewWeChatOfficialPublisher(logger *zap.Logger) publisher.Publisher {
 		logger:             logger,
 		contentTransformer: wechatTransformer,
 		mediaProcessor:     mediaProcessor,
-		client: &http.Client{
-			Timeout: 60 * time.Second,
-		},
+		client:             httpclient.New(),
+		baseURL:            defaultAPIBaseURL,
 	}
 }
 
 func (p *WeChatOfficialPublisher) GetPlatformName() string {
-	return "wechat-official"
+	return publisher.AccountPlatformName("wechat-official", p.account)
+}
+
+// SetAccountName registers the publisher as a named account, published to as wechat-official:<name>
+func (p *WeChatOfficialPublisher) SetAccountName(name string) {
+	p.account = name
+}
+
+// SetMediaCache lets the media processor reuse images already uploaded to the account
+func (p *WeChatOfficialPublisher) SetMediaCache(cache *publisher.MediaCache) {
+	p.mediaProcessor.SetMediaCache(cache)
 }
 
 func (p *WeChatOfficialPublisher) Initialize(ctx context.Context, config publisher.PublishConfig) error {
 	if err := p.ValidateConfig(config); err != nil {
 		return err
 	}
+	if err := p.contentTransformer.SetCodeTheme(config.Config["code_theme"]); err != nil {
+		return err
+	}
+	if err := p.contentTransformer.SetCalloutColors(config.Config["callout_colors"]); err != nil {
+		return fmt.Errorf("invalid callout_colors config: %w", err)
+	}
+
+	// Route WeChat calls through the configured base URL and proxy, e.g. to reach the API from a whitelisted IP
+	if err := p.configureTransport(config); err != nil {
+		return err
+	}
 
 	// Get access token
 	accessToken, err := p.getAccessToken(config)
@@ -96,8 +147,23 @@ func (p *WeChatOfficialPublisher) Initialize(ctx context.Context, config publish
 
 	p.accessToken = accessToken
 	p.mediaProcessor.SetAccessToken(accessToken)
+	p.mediaProcessor.SetAccount(config.Config["app_id"])
 
-	p.logger.Info("WeChat Official Account publisher initialized successfully")
+	publisher.Logger(ctx, p.logger).Info("WeChat Official Account publisher initialized successfully")
+	return nil
+}
+
+// VerifyCredentials requests an access token on a publisher of its own, leaving the token publishes
+// use alone. The token comes from the stable token API whatever use_stable_token says, since
+// unlike the token API it does not invalidate the token in use.
+func (p *WeChatOfficialPublisher) VerifyCredentials(ctx context.Context, config publisher.PublishConfig) error {
+	probe := NewWeChatOfficialPublisher(p.logger).(*WeChatOfficialPublisher)
+	if err := probe.configureTransport(config); err != nil {
+		return err
+	}
+	if _, err := probe.getStableAccessToken(config); err != nil {
+		return fmt.Errorf("failed to get access token: %w", err)
+	}
 	return nil
 }
 
@@ -114,6 +180,7 @@ func (p *WeChatOfficialPublisher) ValidateConfig(config publisher.PublishConfig)
 }
 
 func (p *WeChatOfficialPublisher) TransformContent(ctx context.Context, content publisher.PublishContent) (*publisher.PublishContent, error) {
+	defer publisher.TimeStage(ctx, models.StageTransform)()
 	// Prepare metadata for transformation
 	metadata := make(map[string]string)
 	for k, v := range content.Metadata {
@@ -153,6 +220,11 @@ func (p *WeChatOfficialPublisher) TransformContent(ctx context.Context, content
 		})
 	}
 
+	// Video files are uploaded as video material
+	if doc, err := content.ContentDocument(); err == nil {
+		resources = append(resources, videoResources(doc)...)
+	}
+
 	// Create new content with transformed data
 	result := content
 	result.Content = transformedHTMLContent
@@ -162,6 +234,7 @@ func (p *WeChatOfficialPublisher) TransformContent(ctx context.Context, content
 }
 
 func (p *WeChatOfficialPublisher) ProcessResources(ctx context.Context, content *publisher.PublishContent, config publisher.PublishConfig) error {
+	defer publisher.TimeStage(ctx, models.StageMedia)()
 	if len(content.Resources) == 0 {
 		return nil
 	}
@@ -178,7 +251,7 @@ func (p *WeChatOfficialPublisher) ProcessResources(ctx context.Context, content
 	// Update content to use WeChat media references
 	content.Content = p.contentTransformer.UpdateImageReferences(content.Content, processedResources)
 
-	p.logger.Info("Processed WeChat resources",
+	publisher.Logger(ctx, p.logger).Info("Processed WeChat resources",
 		zap.Int("image_count", len(processedResources)))
 
 	return nil
@@ -208,7 +281,7 @@ func (p *WeChatOfficialPublisher) SaveToDraft(ctx context.Context, content publi
 	article := WeChatArticle{
 		Title:              content.Title,
 		Author:             content.Author,
-		Digest:             "", // 暂时留空，避免长度超限问题
+		Digest:             truncateDigest(content.Summary),
 		Content:            content.Content,
 		ContentSourceURL:   config.Config["source_url"],
 		ShowCoverPic:       1,
@@ -216,18 +289,31 @@ func (p *WeChatOfficialPublisher) SaveToDraft(ctx context.Context, content publi
 		OnlyFansCanComment: p.getIntConfig(config.Config["only_fans_can_comment"], 0),
 	}
 
+	// Notes are posted as single-image newspic messages instead of news articles
+	if content.IsNote() {
+		if err := p.prepareNoteArticle(ctx, &article, content); err != nil {
+			publisher.Logger(ctx, p.logger).Warn("Failed to prepare note as image message, falling back to article",
+				zap.String("title", content.Title),
+				zap.Error(err))
+		}
+	}
+
 	// Use default thumb media ID from config
 	defaultThumbMediaID := config.Config["default_thumb_media_id"]
-	p.logger.Info("Checking default thumb media_id from config",
+	publisher.Logger(ctx, p.logger).Info("Checking default thumb media_id from config",
 		zap.String("default_thumb_media_id", defaultThumbMediaID),
 		zap.Any("all_config", config.Config))
 
-	if defaultThumbMediaID != "" {
+	if article.ArticleType == articleTypeNewsPic {
+		publisher.Logger(ctx, p.logger).Info("Note draft uses its image list instead of a thumbnail")
+	} else if thumbMediaID := p.generateArticleThumb(ctx, content, config); thumbMediaID != "" {
+		article.ThumbMediaID = thumbMediaID
+	} else if defaultThumbMediaID != "" {
 		article.ThumbMediaID = defaultThumbMediaID
-		p.logger.Info("Using default thumb media_id for article thumbnail",
+		publisher.Logger(ctx, p.logger).Info("Using default thumb media_id for article thumbnail",
 			zap.String("media_id", defaultThumbMediaID))
 	} else {
-		p.logger.Warn("No default thumb media_id configured, creating draft without thumbnail")
+		publisher.Logger(ctx, p.logger).Warn("No default thumb media_id configured, creating draft without thumbnail")
 	}
 
 	// Create draft request
@@ -236,7 +322,9 @@ func (p *WeChatOfficialPublisher) SaveToDraft(ctx context.Context, content publi
 	}
 
 	// Call WeChat API to add draft
+	endUpload := publisher.TimeStage(ctx, models.StageUpload)
 	mediaID, err := p.addDraft(draftRequest, config)
+	endUpload()
 	if err != nil {
 		draftErr := fmt.Errorf("failed to create WeChat draft: %w", err)
 		return &publisher.PublishResult{
@@ -246,7 +334,7 @@ func (p *WeChatOfficialPublisher) SaveToDraft(ctx context.Context, content publi
 		}, nil
 	}
 
-	p.logger.Info("Draft saved successfully",
+	publisher.Logger(ctx, p.logger).Info("Draft saved successfully",
 		zap.String("media_id", mediaID),
 		zap.String("title", content.Title))
 
@@ -262,6 +350,7 @@ func (p *WeChatOfficialPublisher) SaveToDraft(ctx context.Context, content publi
 }
 
 func (p *WeChatOfficialPublisher) Publish(ctx context.Context, draftID string, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	defer publisher.TimeStage(ctx, models.StagePublish)()
 	// Publish the draft using media_id
 	publishRequest := WeChatPublishRequest{
 		MediaID: draftID,
@@ -276,11 +365,11 @@ func (p *WeChatOfficialPublisher) Publish(ctx context.Context, draftID string, c
 		}, nil
 	}
 
-	p.logger.Info("Content published successfully",
+	publisher.Logger(ctx, p.logger).Info("Content published successfully",
 		zap.String("publish_id", publishResponse.PublishID),
 		zap.String("msg_id", publishResponse.MsgID))
 
-	return &publisher.PublishResult{
+	result := &publisher.PublishResult{
 		Success:     true,
 		PublishID:   publishResponse.PublishID,
 		PublishedAt: time.Now(),
@@ -289,7 +378,28 @@ func (p *WeChatOfficialPublisher) Publish(ctx context.Context, draftID string, c
 			"msg_id":     publishResponse.MsgID,
 			"media_id":   draftID,
 		},
-	}, nil
+	}
+
+	// Publishing runs in the background: short articles are often out by now and have their URL,
+	// otherwise the deployment tracker follows up until WeChat publishes or rejects them
+	status, err := p.getPublishStatus(ctx, publishResponse.PublishID)
+	switch {
+	case err != nil:
+		publisher.Logger(ctx, p.logger).Warn("Failed to get publish status",
+			zap.String("publish_id", publishResponse.PublishID),
+			zap.Error(err))
+		result.Metadata["deploy_status"] = publisher.DeploymentPending
+	case !status.Success:
+		return status, nil
+	case status.URL == "":
+		result.Metadata["deploy_status"] = publisher.DeploymentPending
+	default:
+		for key, value := range status.Metadata {
+			result.Metadata[key] = value
+		}
+		result.URL = status.URL
+	}
+	return result, nil
 }
 
 func (p *WeChatOfficialPublisher) PublishDirect(ctx context.Context, content publisher.PublishContent, config publisher.PublishConfig) (*publisher.PublishResult, error) {
@@ -341,7 +451,7 @@ func (p *WeChatOfficialPublisher) PublishDirect(ctx context.Context, content pub
 		if err != nil {
 			// Even if publish fails, draft was successful
 			draftResult.Metadata["publish_error"] = err.Error()
-			p.logger.Warn("Auto-publish failed but draft created successfully",
+			publisher.Logger(ctx, p.logger).Warn("Auto-publish failed but draft created successfully",
 				zap.String("draft_id", draftResult.PublishID),
 				zap.Error(err))
 			return draftResult, nil
@@ -353,9 +463,15 @@ func (p *WeChatOfficialPublisher) PublishDirect(ctx context.Context, content pub
 	return draftResult, nil
 }
 
+// GetPublishStatus reports the status of a publish from freepublish/get, with the URL of the
+// article once it is out. Drafts, known by their media ID, are checked for still existing.
 func (p *WeChatOfficialPublisher) GetPublishStatus(ctx context.Context, publishID string, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	if isPublishID(publishID) {
+		return p.getPublishStatus(ctx, publishID)
+	}
+
 	// Check draft status by trying to get material info
-	url := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/draft/get?access_token=%s", p.accessToken)
+	url := fmt.Sprintf("%s/cgi-bin/draft/get?access_token=%s", p.baseURL, p.accessToken)
 
 	reqBody := map[string]interface{}{
 		"media_id": publishID,
@@ -383,7 +499,7 @@ func (p *WeChatOfficialPublisher) GetPublishStatus(ctx context.Context, publishI
 	}
 
 	success := statusResp.ErrCode == 0
-	statusErr := fmt.Errorf("WeChat API error: %s", statusResp.ErrMsg)
+	statusErr := apiError(statusResp.ErrCode, fmt.Errorf("WeChat API error: %s", statusResp.ErrMsg))
 	return &publisher.PublishResult{
 		Success:   success,
 		PublishID: publishID,
@@ -392,17 +508,116 @@ func (p *WeChatOfficialPublisher) GetPublishStatus(ctx context.Context, publishI
 	}, nil
 }
 
+func (p *WeChatOfficialPublisher) getPublishStatus(ctx context.Context, publishID string) (*publisher.PublishResult, error) {
+	status, err := p.getPublish(ctx, publishID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &publisher.PublishResult{
+		Success:   true,
+		PublishID: publishID,
+		Metadata: map[string]string{
+			"publish_id":     publishID,
+			"publish_status": status.statusName(),
+		},
+	}
+	switch status.PublishStatus {
+	case freepublishSucceeded:
+		result.URL = status.articleURL()
+		result.Metadata["article_id"] = status.ArticleID
+	case freepublishRunning:
+		// Still publishing, the article has no URL yet
+	default:
+		statusErr := publisher.NewError(publisher.ErrorCodeContentInvalid, fmt.Errorf("WeChat publish %s: %s", status.statusName(), status.failure()))
+		result.Success = false
+		result.Error = statusErr
+		result.ErrorMsg = statusErr.Error()
+	}
+	return result, nil
+}
+
+func (p *WeChatOfficialPublisher) Unpublish(ctx context.Context, publishID string, config publisher.PublishConfig) error {
+	// With auto_publish the job holds the freepublish publish ID, the article it published is deleted instead
+	if isPublishID(publishID) {
+		return p.deletePublish(ctx, publishID)
+	}
+
+	// Delete the draft material created by SaveToDraft
+	url := fmt.Sprintf("%s/cgi-bin/draft/delete?access_token=%s", p.baseURL, p.accessToken)
+
+	reqBody := map[string]string{
+		"media_id": publishID,
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := p.client.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to delete draft: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var deleteResp struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&deleteResp); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if deleteResp.ErrCode != 0 {
+		return apiError(deleteResp.ErrCode, fmt.Errorf("WeChat draft delete API error: %d - %s", deleteResp.ErrCode, deleteResp.ErrMsg))
+	}
+
+	publisher.Logger(ctx, p.logger).Info("WeChat draft deleted", zap.String("media_id", publishID))
+	return nil
+}
+
 func (p *WeChatOfficialPublisher) Cleanup(ctx context.Context, publishID string, config publisher.PublishConfig) error {
 	// Clean up temporary files if any
-	p.logger.Info("WeChat cleanup completed", zap.String("publish_id", publishID))
+	publisher.Logger(ctx, p.logger).Info("WeChat cleanup completed", zap.String("publish_id", publishID))
 	return nil
 }
 
 // Helper methods
 
+func (p *WeChatOfficialPublisher) configureTransport(config publisher.PublishConfig) error {
+	p.baseURL = defaultAPIBaseURL
+	if baseURL := strings.TrimRight(config.Config["api_base_url"], "/"); baseURL != "" {
+		p.baseURL = baseURL
+	}
+
+	// The platform proxy takes precedence over the global one, WeChat only accepts calls from
+	// whitelisted IPs
+	var opts []httpclient.Option
+	if proxyURL := config.Config["proxy_url"]; proxyURL != "" {
+		parsed, err := httpclient.ParseProxy(proxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy_url: %w", err)
+		}
+		opts = append(opts, httpclient.WithProxy(parsed))
+		p.logger.Info("Routing WeChat API calls through proxy", zap.String("proxy_host", parsed.Host))
+	}
+
+	p.client = httpclient.New(opts...)
+	p.mediaProcessor.SetHTTPClient(p.client)
+	p.mediaProcessor.SetBaseURL(p.baseURL)
+
+	return nil
+}
+
 func (p *WeChatOfficialPublisher) getAccessToken(config publisher.PublishConfig) (string, error) {
-	url := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/token?grant_type=client_credential&appid=%s&secret=%s",
-		config.Config["app_id"], config.Config["app_secret"])
+	if config.Config["use_stable_token"] == "true" {
+		return p.getStableAccessToken(config)
+	}
+
+	url := fmt.Sprintf("%s/cgi-bin/token?grant_type=client_credential&appid=%s&secret=%s",
+		p.baseURL, config.Config["app_id"], config.Config["app_secret"])
 
 	resp, err := p.client.Get(url)
 	if err != nil {
@@ -421,14 +636,54 @@ func (p *WeChatOfficialPublisher) getAccessToken(config publisher.PublishConfig)
 	}
 
 	if tokenResponse.ErrCode != 0 {
-		return "", fmt.Errorf("WeChat API error: %s", tokenResponse.ErrMsg)
+		return "", apiError(tokenResponse.ErrCode, fmt.Errorf("WeChat API error: %s", tokenResponse.ErrMsg))
+	}
+
+	return tokenResponse.AccessToken, nil
+}
+
+// getStableAccessToken uses the stable_token API, which returns the same token to every caller
+// until it expires instead of invalidating tokens held by other services sharing the app
+func (p *WeChatOfficialPublisher) getStableAccessToken(config publisher.PublishConfig) (string, error) {
+	url := fmt.Sprintf("%s/cgi-bin/stable_token", p.baseURL)
+
+	reqBody := map[string]interface{}{
+		"grant_type":    "client_credential",
+		"appid":         config.Config["app_id"],
+		"secret":        config.Config["app_secret"],
+		"force_refresh": false,
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := p.client.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var tokenResponse WeChatAccessTokenResponse
+	if err := json.Unmarshal(body, &tokenResponse); err != nil {
+		return "", err
+	}
+
+	if tokenResponse.ErrCode != 0 {
+		return "", apiError(tokenResponse.ErrCode, fmt.Errorf("WeChat stable_token API error: %d - %s", tokenResponse.ErrCode, tokenResponse.ErrMsg))
 	}
 
 	return tokenResponse.AccessToken, nil
 }
 
 func (p *WeChatOfficialPublisher) addDraft(draftRequest WeChatDraftAddRequest, config publisher.PublishConfig) (string, error) {
-	url := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/draft/add?access_token=%s", p.accessToken)
+	url := fmt.Sprintf("%s/cgi-bin/draft/add?access_token=%s", p.baseURL, p.accessToken)
 
 	jsonData, err := json.Marshal(draftRequest)
 	if err != nil {
@@ -463,14 +718,14 @@ func (p *WeChatOfficialPublisher) addDraft(draftRequest WeChatDraftAddRequest, c
 		p.logger.Error("WeChat draft API returned error",
 			zap.Int("error_code", draftResponse.ErrCode),
 			zap.String("error_message", draftResponse.ErrMsg))
-		return "", fmt.Errorf("WeChat draft API error: %s", draftResponse.ErrMsg)
+		return "", apiError(draftResponse.ErrCode, fmt.Errorf("WeChat draft API error: %s", draftResponse.ErrMsg))
 	}
 
 	return draftResponse.MediaID, nil
 }
 
 func (p *WeChatOfficialPublisher) publishDraft(publishRequest WeChatPublishRequest, config publisher.PublishConfig) (*WeChatPublishResponse, error) {
-	url := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/freepublish/submit?access_token=%s", p.accessToken)
+	url := fmt.Sprintf("%s/cgi-bin/freepublish/submit?access_token=%s", p.baseURL, p.accessToken)
 
 	jsonData, err := json.Marshal(publishRequest)
 	if err != nil {
@@ -494,12 +749,96 @@ func (p *WeChatOfficialPublisher) publishDraft(publishRequest WeChatPublishReque
 	}
 
 	if publishResponse.ErrCode != 0 {
-		return nil, fmt.Errorf("WeChat publish API error: %s", publishResponse.ErrMsg)
+		return nil, apiError(publishResponse.ErrCode, fmt.Errorf("WeChat publish API error: %s", publishResponse.ErrMsg))
 	}
 
 	return &publishResponse, nil
 }
 
+// prepareNoteArticle turns an article into a newspic message with the first image and plain text body
+func (p *WeChatOfficialPublisher) prepareNoteArticle(ctx context.Context, article *WeChatArticle, content publisher.PublishContent) error {
+	var imagePath string
+	for _, resource := range content.Resources {
+		if resource.Type == publisher.ResourceTypeImage && resource.LocalPath != "" {
+			imagePath = resource.LocalPath
+			break
+		}
+	}
+	if imagePath == "" {
+		return fmt.Errorf("note has no image for newspic message")
+	}
+
+	// newspic messages reference images by permanent material media_id
+	mediaID, err := p.mediaProcessor.UploadImageMaterial(ctx, imagePath)
+	if err != nil {
+		return fmt.Errorf("failed to upload note image: %w", err)
+	}
+
+	article.ArticleType = articleTypeNewsPic
+	article.Content = htmlToPlainText(article.Content)
+	article.ShowCoverPic = 0
+	article.ImageInfo = &WeChatImageInfo{
+		ImageList: []WeChatImageItem{{ImageMediaID: mediaID}},
+	}
+
+	return nil
+}
+
+// generateArticleThumb creates a thumbnail from the page cover or the first content image,
+// returning an empty media_id when disabled or when no usable image exists
+func (p *WeChatOfficialPublisher) generateArticleThumb(ctx context.Context, content publisher.PublishContent, config publisher.PublishConfig) string {
+	if config.Config["auto_thumbnail"] == "false" {
+		return ""
+	}
+
+	var sources []string
+	if coverURL := content.Metadata["cover_url"]; coverURL != "" {
+		sources = append(sources, coverURL)
+	}
+	for _, resource := range content.Resources {
+		if resource.Type != publisher.ResourceTypeImage {
+			continue
+		}
+		if resource.LocalPath != "" {
+			sources = append(sources, resource.LocalPath)
+		} else if resource.URL != "" {
+			sources = append(sources, resource.URL)
+		}
+		break
+	}
+
+	for _, source := range sources {
+		mediaID, err := p.mediaProcessor.GenerateThumbMediaID(ctx, source)
+		if err != nil {
+			publisher.Logger(ctx, p.logger).Warn("Failed to generate thumbnail, trying next source",
+				zap.String("title", content.Title),
+				zap.Error(err))
+			continue
+		}
+		return mediaID
+	}
+
+	return ""
+}
+
+// htmlToPlainText strips tags from the converted HTML since newspic messages only accept plain text
+func htmlToPlainText(content string) string {
+	content = blockEndPattern.ReplaceAllString(content, "\n")
+	content = htmlTagPattern.ReplaceAllString(content, "")
+	content = html.UnescapeString(content)
+	return strings.TrimSpace(multiNewlinePattern.ReplaceAllString(content, "\n\n"))
+}
+
+// truncateDigest shortens the summary to the digest limit, which is counted in characters
+func truncateDigest(summary string) string {
+	summary = strings.TrimSpace(summary)
+	runes := []rune(summary)
+	if len(runes) <= maxDigestChars {
+		return summary
+	}
+	return string(runes[:maxDigestChars-1]) + "…"
+}
+
 func (p *WeChatOfficialPublisher) getIntConfig(value string, defaultValue int) int {
 	if value == "true" || value == "1" {
 		return 1
@@ -0,0 +1,271 @@
+package wechat_official
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ifuryst/ripple/internal/content"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/highlight"
+)
+
+// renderWeChatHTML renders a content document as WeChat article HTML with inline styles. Code
+// is highlighted with codeTheme; a nil theme leaves it plain. Callouts are boxed in the colors
+// of calloutPalette.
+func renderWeChatHTML(doc *content.Document, codeTheme *highlight.Theme, calloutPalette map[string]calloutColor) string {
+	var parts []string
+	for _, block := range doc.Blocks {
+		if html := renderBlock(block, codeTheme, calloutPalette); html != "" {
+			parts = append(parts, html)
+		}
+	}
+
+	// Clean up non-breaking spaces (0xa0) and replace with regular spaces
+	return cleanWeChatText(strings.Join(parts, ""))
+}
+
+func renderBlock(block content.Block, codeTheme *highlight.Theme, calloutPalette map[string]calloutColor) string {
+	switch block.Type {
+	case content.BlockHeading:
+		text := renderSpans(block.Text)
+		if text == "" {
+			return ""
+		}
+		if block.Level == 3 {
+			return fmt.Sprintf(`<h3 style="text-align:left;color:#3f3f3f;line-height:1.5;font-family:Optima-Regular, Optima, PingFangSC-light, PingFangTC-light, 'PingFang SC', Cambria, Cochin, Georgia, Times, 'Times New Roman', serif;font-size:120%%;margin:40px 10px 20px 10px;font-weight:bold">%s</h3>`, text)
+		}
+		return fmt.Sprintf(`<h2 style="text-align:center;color:#3f3f3f;line-height:1.5;font-family:Optima-Regular, Optima, PingFangSC-light, PingFangTC-light, 'PingFang SC', Cambria, Cochin, Georgia, Times, 'Times New Roman', serif;font-size:140%%;margin:80px 10px 40px 10px;font-weight:normal">%s</h2>`, text)
+	case content.BlockList:
+		return renderList(block.List)
+	case content.BlockQuote:
+		text := renderSpans(block.Text)
+		if text == "" {
+			return ""
+		}
+		quoteParagraph := fmt.Sprintf(`<p style="text-align:left;color:#3f3f3f;line-height:1.6;font-family:Optima-Regular, Optima, PingFangSC-light, PingFangTC-light, 'PingFang SC', Cambria, Cochin, Georgia, Times, 'Times New Roman', serif;font-size:16px;margin:10px 10px">%s</p>`, text)
+		return fmt.Sprintf(`<blockquote style="text-align:left;color:rgb(91, 91, 91);line-height:1.5;font-family:Optima-Regular, Optima, PingFangSC-light, PingFangTC-light, 'PingFang SC', Cambria, Cochin, Georgia, Times, 'Times New Roman', serif;font-size:16px;margin:20px 10px;padding:1px 0 1px 10px;background:rgba(158, 158, 158, 0.1);border-left:3px solid rgb(158,158,158)">%s</blockquote>`, quoteParagraph)
+	case content.BlockCallout:
+		return renderCallout(block, calloutPalette)
+	case content.BlockCode:
+		return renderCode(block.Code, block.Language, codeTheme)
+	case content.BlockDivider:
+		return `<hr style="margin: 40px 10px; border: none; border-top: 1px solid #ddd;">`
+	case content.BlockImage:
+		return renderImage(block.Image)
+	case content.BlockVideo:
+		return renderVideo(block.Video)
+	case content.BlockFile:
+		return renderFile(block.File)
+	case content.BlockTable:
+		return renderTable(block.Table)
+	default:
+		text := renderSpans(block.Text)
+		if text == "" {
+			return ""
+		}
+		return fmt.Sprintf(`<p style="text-align:left;color:#3f3f3f;line-height:1.6;font-family:Optima-Regular, Optima, PingFangSC-light, PingFangTC-light, 'PingFang SC', Cambria, Cochin, Georgia, Times, 'Times New Roman', serif;font-size:16px;margin:10px 10px">%s</p>`, text)
+	}
+}
+
+// renderList renders list items as styled paragraphs, since WeChat drops list markup styling
+func renderList(list *content.List) string {
+	var items []string
+	for _, item := range list.Items {
+		text := renderSpans(item.Text)
+		if text == "" {
+			continue
+		}
+
+		marker, listStyle := "•", ";list-style:circle"
+		if list.Ordered {
+			marker, listStyle = fmt.Sprintf("%d.", len(items)+1), ""
+		}
+		if item.Checked != nil {
+			marker = "☐"
+			if *item.Checked {
+				marker = "☑"
+			}
+		}
+		items = append(items, fmt.Sprintf(`<p style="text-align:left;color:#3f3f3f;line-height:1.5;font-family:Optima-Regular, Optima, PingFangSC-light, PingFangTC-light, 'PingFang SC', Cambria, Cochin, Georgia, Times, 'Times New Roman', serif;font-size:16px;margin:20px 10px;margin-left:0;padding-left:20px%s"><span style="text-align:left;color:#3f3f3f;line-height:1.5;font-family:Optima-Regular, Optima, PingFangSC-light, PingFangTC-light, 'PingFang SC', Cambria, Cochin, Georgia, Times, 'Times New Roman', serif;font-size:16px;text-indent:-20px;display:block;margin:10px 10px"><span style="margin-right: 10px;">%s</span>%s</span></p>`, listStyle, marker, text))
+	}
+	return strings.Join(items, "")
+}
+
+// renderCode renders a code block in WeChat's code snippet markup. WeChat strips classes and
+// style sheets from articles, so highlighted tokens carry their colors as inline styles.
+func renderCode(code, language string, theme *highlight.Theme) string {
+	if code == "" {
+		return ""
+	}
+	if language == "" {
+		language = "bash"
+	}
+
+	// WeChat renders tabs at inconsistent widths, so indentation uses spaces
+	code = strings.ReplaceAll(code, "\t", "    ")
+
+	var lines [][]highlight.Token
+	if theme != nil {
+		lines = highlight.Lines(highlight.Tokenize(code, language))
+	} else {
+		for _, line := range strings.Split(code, "\n") {
+			lines = append(lines, []highlight.Token{{Type: highlight.Text, Text: line}})
+		}
+	}
+
+	lineNumbers := ""
+	for range lines {
+		lineNumbers += "<li></li>"
+	}
+
+	codeLines := ""
+	for _, tokens := range lines {
+		line := ""
+		for _, token := range tokens {
+			text := escapeHTML(token.Text)
+			if style := tokenStyle(theme, token.Type); style != "" {
+				text = fmt.Sprintf(`<span style="%s">%s</span>`, style, text)
+			}
+			line += text
+		}
+		if line == "" {
+			line = " " // prevent empty lines from collapsing
+		}
+		codeLines += fmt.Sprintf(`<code><span class="code-snippet_outer">%s</span></code>`, line)
+	}
+
+	preStyle := ""
+	if theme != nil {
+		preStyle = fmt.Sprintf(` style="background:%s;color:%s"`, theme.Background, theme.Foreground)
+	}
+	return fmt.Sprintf(`<section class="code-snippet__fix code-snippet__js"><ul class="code-snippet__line-index code-snippet__js">%s</ul><pre class="code-snippet__js" data-lang="%s"%s>%s</pre></section>`, lineNumbers, language, preStyle, codeLines)
+}
+
+// tokenStyle returns the inline style of a token, or "" when code is not highlighted
+func tokenStyle(theme *highlight.Theme, tokenType highlight.TokenType) string {
+	if theme == nil {
+		return ""
+	}
+	return theme.Style(tokenType)
+}
+
+func renderImage(image *content.Image) string {
+	return fmt.Sprintf(`<p style="text-align:left;color:#3f3f3f;line-height:1.6;font-family:Optima-Regular, Optima, PingFangSC-light, PingFangTC-light, 'PingFang SC', Cambria, Cochin, Georgia, Times, 'Times New Roman', serif;font-size:16px;margin:10px 10px"><img style="text-align:left;color:#3f3f3f;line-height:1.5;font-family:Optima-Regular, Optima, PingFangSC-light, PingFangTC-light, 'PingFang SC', Cambria, Cochin, Georgia, Times, 'Times New Roman', serif;font-size:16px;margin:20px auto;border-radius:4px;display:block;width:100%%" src="%s" title="null" alt="%s"></p>`, image.URL, escapeHTML(content.PlainText(image.Caption)))
+}
+
+// renderVideo renders a placeholder for a video. Articles can't embed videos through the API,
+// so video files are uploaded as video material for the editor to insert, and videos on other
+// sites become links.
+func renderVideo(video *content.Video) string {
+	label := renderSpans(video.Caption)
+	if label == "" {
+		label = "视频"
+	}
+	if !video.IsFile() {
+		label = fmt.Sprintf(`<a href="%s">%s</a>`, video.URL, label)
+	}
+	return fmt.Sprintf(`<p style="text-align:center;color:#888;line-height:1.6;font-size:14px;margin:20px 10px;padding:12px;background:rgba(158, 158, 158, 0.1);border-radius:4px">▶ %s</p>`, label)
+}
+
+// renderFile renders an attachment as a box with its name and kind. Articles can't link to
+// files, so the link becomes a reference like any other link.
+func renderFile(file *content.File) string {
+	html := fmt.Sprintf(`<p style="text-align:left;color:#3f3f3f;line-height:1.6;font-size:15px;margin:20px 10px;padding:12px 14px;background:rgba(52, 152, 219, 0.08);border-left:3px solid #3498db;border-radius:4px">📎 %s <span style="color:#888;font-size:13px">· %s</span></p>`,
+		renderSpan(content.Span{Text: file.FileName(), Link: file.URL}), file.Kind())
+	if caption := renderSpans(file.Caption); caption != "" {
+		html += fmt.Sprintf(`<p style="text-align:center;color:#888;line-height:1.6;font-size:14px;margin:-10px 10px 20px 10px">%s</p>`, caption)
+	}
+	return html
+}
+
+// videoResources returns the video files of a document as resources to upload as video material
+func videoResources(doc *content.Document) []publisher.Resource {
+	var resources []publisher.Resource
+	for _, video := range doc.Videos() {
+		if !video.IsFile() {
+			continue
+		}
+		resources = append(resources, publisher.Resource{
+			ID:       fmt.Sprintf("wechat_video_%d", len(resources)+1),
+			Type:     publisher.ResourceTypeVideo,
+			URL:      video.URL,
+			Metadata: map[string]string{"title": content.PlainText(video.Caption)},
+		})
+	}
+	return resources
+}
+
+func renderTable(table *content.Table) string {
+	if len(table.Rows) == 0 {
+		return ""
+	}
+
+	var rows []string
+	for i, cells := range table.Rows {
+		tag := "td"
+		if i == 0 && table.HasHeader {
+			tag = "th"
+		}
+		var row string
+		for _, cell := range cells {
+			row += fmt.Sprintf(`<%s style="border:1px solid #ddd;padding:6px 10px;font-size:14px;color:#3f3f3f">%s</%s>`, tag, renderSpans(cell), tag)
+		}
+		rows = append(rows, "<tr>"+row+"</tr>")
+	}
+	return `<section style="margin:20px 10px;overflow-x:auto"><table style="border-collapse:collapse;width:100%">` + strings.Join(rows, "") + `</table></section>`
+}
+
+func renderSpans(spans []content.Span) string {
+	var text string
+	for _, span := range spans {
+		text += renderSpan(span)
+	}
+	return text
+}
+
+func renderSpan(span content.Span) string {
+	text := escapeHTML(span.Text)
+
+	if span.Bold {
+		text = fmt.Sprintf(`<strong style="text-align:left;color:#ff3502;line-height:1.5;font-family:Optima-Regular, Optima, PingFangSC-light, PingFangTC-light, 'PingFang SC', Cambria, Cochin, Georgia, Times, 'Times New Roman', serif;font-size:16px">%s</strong>`, text)
+	}
+	if span.Italic {
+		text = fmt.Sprintf(`<em style="color: #3498db; font-style: italic;">%s</em>`, text)
+	}
+	if span.Code {
+		text = fmt.Sprintf(`<code style="text-align:left;color:#ff3502;line-height:1.5;font-family:Operator Mono, Consolas, Monaco, Menlo, monospace;font-size:90%%;background:#f8f5ec;padding:3px 5px;border-radius:2px">%s</code>`, text)
+	}
+	if span.Strikethrough {
+		text = fmt.Sprintf(`<s>%s</s>`, text)
+	}
+	if span.Underline {
+		text = fmt.Sprintf(`<u>%s</u>`, text)
+	}
+	// Links are turned into references by the transformer afterwards
+	if span.Link != "" {
+		text = fmt.Sprintf(`<a href="%s" style="color: #3498db; text-decoration: none; border-bottom: 1px dotted #3498db;">%s</a>`, span.Link, text)
+	}
+
+	return text
+}
+
+func escapeHTML(text string) string {
+	text = strings.ReplaceAll(text, "&", "&amp;")
+	text = strings.ReplaceAll(text, "<", "&lt;")
+	text = strings.ReplaceAll(text, ">", "&gt;")
+	text = strings.ReplaceAll(text, "\"", "&quot;")
+	text = strings.ReplaceAll(text, "'", "&#39;")
+	return text
+}
+
+// cleanWeChatText removes unwanted characters and fixes encoding issues
+func cleanWeChatText(text string) string {
+	if text == "" {
+		return ""
+	}
+
+	// Replace non-breaking space (0xa0) with regular space
+	text = strings.ReplaceAll(text, "\u00a0", " ")
+
+	return text
+}
\ No newline at end of file
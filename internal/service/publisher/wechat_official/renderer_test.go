@@ -0,0 +1,37 @@
+package wechat_official
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ifuryst/ripple/pkg/highlight"
+)
+
+var (
+	codeLinePattern = regexp.MustCompile(`<code><span class="code-snippet_outer">(.*?)</span></code>`)
+	tagPattern      = regexp.MustCompile(`<[^>]+>`)
+)
+
+func TestRenderCodeKeepsIndentation(t *testing.T) {
+	code := "func main() {\n\tif ok {\n\t\t/* indented\n\t\t   comment */\n\t\treturn\n\t}\n}"
+	want := strings.Split(strings.ReplaceAll(code, "\t", "    "), "\n")
+
+	theme, err := highlight.GetTheme(highlight.DefaultTheme)
+	if err != nil {
+		t.Fatalf("GetTheme: %v", err)
+	}
+	for name, theme := range map[string]*highlight.Theme{"highlighted": theme, "plain": nil} {
+		html := renderCode(code, "go", theme)
+
+		matches := codeLinePattern.FindAllStringSubmatch(html, -1)
+		if len(matches) != len(want) {
+			t.Fatalf("%s: rendered %d lines, want %d", name, len(matches), len(want))
+		}
+		for i, match := range matches {
+			if got := tagPattern.ReplaceAllString(match[1], ""); got != want[i] {
+				t.Errorf("%s: line %d renders as %q, want %q", name, i+1, got, want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package wechat_official
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+
+	// Register decoders for the formats Notion images usually come in
+	_ "image/gif"
+	_ "image/png"
+)
+
+const (
+	// WeChat shows article covers at 2.35:1; 900x383 is the recommended size
+	thumbWidth  = 900
+	thumbHeight = 383
+	// thumb material must be a JPG no larger than 64KB
+	thumbMaxBytes = 64 * 1024
+)
+
+// generateThumbnail center-crops an image to the WeChat cover ratio, scales it down and
+// writes a JPG small enough to be uploaded as thumb material
+func generateThumbnail(srcPath, dstPath string) error {
+	file, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("failed to open image: %w", err)
+	}
+	defer file.Close()
+
+	src, _, err := image.Decode(file)
+	if err != nil {
+		return fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	cropped := cropToRatio(src, thumbWidth, thumbHeight)
+
+	width, height := thumbWidth, thumbHeight
+	if b := cropped.Bounds(); b.Dx() < width {
+		// Never upscale small images
+		width, height = b.Dx(), b.Dy()
+	}
+
+	// Lower the quality first, then the size, until the JPG fits the limit
+	for width >= 100 {
+		scaled := scaleImage(cropped, width, height)
+		for quality := 90; quality >= 40; quality -= 10 {
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: quality}); err != nil {
+				return fmt.Errorf("failed to encode thumbnail: %w", err)
+			}
+			if buf.Len() <= thumbMaxBytes {
+				return os.WriteFile(dstPath, buf.Bytes(), 0644)
+			}
+		}
+		width, height = width*3/4, height*3/4
+	}
+
+	return fmt.Errorf("unable to fit thumbnail within %d bytes", thumbMaxBytes)
+}
+
+// cropToRatio returns the largest centered region of img with the given aspect ratio
+func cropToRatio(img image.Image, ratioW, ratioH int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	cropW, cropH := w, w*ratioH/ratioW
+	if cropH > h {
+		cropW, cropH = h*ratioW/ratioH, h
+	}
+
+	x0 := b.Min.X + (w-cropW)/2
+	y0 := b.Min.Y + (h-cropH)/2
+	rect := image.Rect(x0, y0, x0+cropW, y0+cropH)
+
+	if sub, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(rect)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, cropW, cropH))
+	for y := 0; y < cropH; y++ {
+		for x := 0; x < cropW; x++ {
+			dst.Set(x, y, img.At(x0+x, y0+y))
+		}
+	}
+	return dst
+}
+
+// scaleImage resizes img with bilinear interpolation
+func scaleImage(img image.Image, width, height int) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	xRatio := float64(b.Dx()-1) / float64(max(width-1, 1))
+	yRatio := float64(b.Dy()-1) / float64(max(height-1, 1))
+
+	for y := 0; y < height; y++ {
+		sy := float64(y) * yRatio
+		y0 := int(sy)
+		y1 := min(y0+1, b.Dy()-1)
+		fy := sy - float64(y0)
+
+		for x := 0; x < width; x++ {
+			sx := float64(x) * xRatio
+			x0 := int(sx)
+			x1 := min(x0+1, b.Dx()-1)
+			fx := sx - float64(x0)
+
+			c00 := color.RGBAModel.Convert(img.At(b.Min.X+x0, b.Min.Y+y0)).(color.RGBA)
+			c10 := color.RGBAModel.Convert(img.At(b.Min.X+x1, b.Min.Y+y0)).(color.RGBA)
+			c01 := color.RGBAModel.Convert(img.At(b.Min.X+x0, b.Min.Y+y1)).(color.RGBA)
+			c11 := color.RGBAModel.Convert(img.At(b.Min.X+x1, b.Min.Y+y1)).(color.RGBA)
+
+			dst.SetRGBA(x, y, color.RGBA{
+				R: bilinear(c00.R, c10.R, c01.R, c11.R, fx, fy),
+				G: bilinear(c00.G, c10.G, c01.G, c11.G, fx, fy),
+				B: bilinear(c00.B, c10.B, c01.B, c11.B, fx, fy),
+				A: bilinear(c00.A, c10.A, c01.A, c11.A, fx, fy),
+			})
+		}
+	}
+
+	return dst
+}
+
+func bilinear(c00, c10, c01, c11 uint8, fx, fy float64) uint8 {
+	top := float64(c00)*(1-fx) + float64(c10)*fx
+	bottom := float64(c01)*(1-fx) + float64(c11)*fx
+	return uint8(top*(1-fy) + bottom*fy + 0.5)
+}
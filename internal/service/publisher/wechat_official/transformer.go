@@ -4,23 +4,56 @@ import (
 	"context"
 	"fmt"
 	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/highlight"
 	"regexp"
 	"strings"
 )
 
 // WeChatTransformer converts content to WeChat Official Account format
-type WeChatTransformer struct{}
+type WeChatTransformer struct {
+	// codeTheme highlights code blocks; nil leaves them plain
+	codeTheme *highlight.Theme
+	// calloutPalette colors callouts by their Notion color
+	calloutPalette map[string]calloutColor
+}
 
 func NewWeChatTransformer() *WeChatTransformer {
-	return &WeChatTransformer{}
+	theme, _ := highlight.GetTheme(highlight.DefaultTheme)
+	palette, _ := parseCalloutPalette("")
+	return &WeChatTransformer{codeTheme: theme, calloutPalette: palette}
+}
+
+// SetCodeTheme sets the theme code blocks are highlighted with; "none" turns highlighting off
+func (t *WeChatTransformer) SetCodeTheme(name string) error {
+	if strings.EqualFold(strings.TrimSpace(name), "none") {
+		t.codeTheme = nil
+		return nil
+	}
+	theme, err := highlight.GetTheme(name)
+	if err != nil {
+		return err
+	}
+	t.codeTheme = theme
+	return nil
+}
+
+// SetCalloutColors sets the palette callouts are colored with from a YAML mapping of Notion
+// colors, over the default palette
+func (t *WeChatTransformer) SetCalloutColors(source string) error {
+	palette, err := parseCalloutPalette(source)
+	if err != nil {
+		return err
+	}
+	t.calloutPalette = palette
+	return nil
 }
 
 func (t *WeChatTransformer) TransformContent(ctx context.Context, content publisher.PublishContent) (*publisher.PublishContent, error) {
-	// Convert Notion blocks JSON directly to WeChat HTML
-	wechatHTML, err := convertNotionBlocksToWeChatHTML(content.Content)
+	doc, err := content.ContentDocument()
 	if err != nil {
-		return nil, fmt.Errorf("notion blocks to WeChat HTML conversion failed: %w", err)
+		return nil, fmt.Errorf("failed to parse content: %w", err)
 	}
+	wechatHTML := renderWeChatHTML(doc, t.codeTheme, t.calloutPalette)
 
 	// Extract links and add references
 	wechatHTML, err = t.extractLinksAndAddReferences(wechatHTML)
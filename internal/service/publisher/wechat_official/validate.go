@@ -0,0 +1,48 @@
+package wechat_official
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// Field limits of the WeChat draft API, in characters
+const (
+	maxTitleLength  = 64
+	maxAuthorLength = 16
+)
+
+// Validate checks the article fields against the lengths the WeChat draft API accepts; the
+// digest is cut to its limit instead
+func (p *WeChatOfficialPublisher) Validate(content publisher.PublishContent, config publisher.PublishConfig) []publisher.Violation {
+	var violations []publisher.Violation
+
+	if content.Title == "" {
+		violations = append(violations, publisher.Violation{
+			Field:   "title",
+			Rule:    "required",
+			Message: "title is required",
+		})
+	}
+
+	fields := []struct {
+		name      string
+		value     string
+		maxLength int
+	}{
+		{"title", content.Title, maxTitleLength},
+		{"author", content.Author, maxAuthorLength},
+	}
+	for _, field := range fields {
+		if length := utf8.RuneCountInString(field.value); length > field.maxLength {
+			violations = append(violations, publisher.Violation{
+				Field:   field.name,
+				Rule:    "max_length",
+				Message: fmt.Sprintf("%s has %d characters, WeChat allows %d", field.name, length, field.maxLength),
+			})
+		}
+	}
+
+	return violations
+}
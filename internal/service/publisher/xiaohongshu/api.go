@@ -0,0 +1,354 @@
+package xiaohongshu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+const (
+	defaultAPIBaseURL     = "https://edith.xiaohongshu.com"
+	defaultCreatorBaseURL = "https://creator.xiaohongshu.com"
+	defaultUploadBaseURL  = "https://ros-upload.xiaohongshu.com"
+	noteURLPrefix         = "https://www.xiaohongshu.com/explore/"
+	userAgent             = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/138.0.0.0 Safari/537.36"
+)
+
+// Xiaohongshu answers with HTTP 200 and a code; these codes mean the session is no longer valid
+var sessionExpiredCodes = map[int]bool{-100: true, -101: true}
+
+// apiResponse is the envelope of every web API response
+type apiResponse struct {
+	Success bool            `json:"success"`
+	Code    int             `json:"code"`
+	Msg     string          `json:"msg"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type uploadPermitResponse struct {
+	UploadTempPermits []struct {
+		FileIDs []string `json:"fileIds"`
+		Token   string   `json:"token"`
+	} `json:"uploadTempPermits"`
+}
+
+// uploadedImage is a card uploaded for a note
+type uploadedImage struct {
+	FileID string
+	Width  int
+	Height int
+}
+
+type topicSearchResponse struct {
+	TopicInfos []noteTopic `json:"topic_info_dtos"`
+}
+
+// noteTopic is a hashtag linked to a Xiaohongshu topic
+type noteTopic struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Link string `json:"link"`
+	Type string `json:"type"`
+}
+
+type createNoteResponse struct {
+	ID     string `json:"id"`
+	NoteID string `json:"note_id"`
+}
+
+// VerifyCredentials reads the creator account to verify the cookie is still logged in, on a
+// publisher of its own so the client publishes use is left alone
+func (p *XiaohongshuPublisher) VerifyCredentials(ctx context.Context, config publisher.PublishConfig) error {
+	probe := &XiaohongshuPublisher{
+		logger:         p.logger,
+		apiBaseURL:     p.apiBaseURL,
+		creatorBaseURL: p.creatorBaseURL,
+		uploadBaseURL:  p.uploadBaseURL,
+	}
+	if err := probe.Initialize(ctx, config); err != nil {
+		return err
+	}
+	return probe.call(ctx, http.MethodGet, probe.creatorBaseURL, "/api/galaxy/user/info", nil, nil)
+}
+
+// uploadCard uploads a rendered card with an upload permit for a single image
+func (p *XiaohongshuPublisher) uploadCard(ctx context.Context, path string) (*uploadedImage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read card: %w", err)
+	}
+	size, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read card size: %w", err)
+	}
+
+	var permit uploadPermitResponse
+	uri := "/api/media/v1/upload/web/permit?biz_name=spectrum&scene=image&file_count=1&version=1&source=web"
+	if err := p.call(ctx, http.MethodGet, p.creatorBaseURL, uri, nil, &permit); err != nil {
+		return nil, fmt.Errorf("failed to get upload permit: %w", err)
+	}
+	if len(permit.UploadTempPermits) == 0 || len(permit.UploadTempPermits[0].FileIDs) == 0 {
+		return nil, fmt.Errorf("upload permit has no file ID")
+	}
+	fileID := permit.UploadTempPermits[0].FileIDs[0]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, p.uploadBaseURL+"/"+fileID, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create upload request: %w", err)
+	}
+	req.Header.Set("X-Cos-Security-Token", permit.UploadTempPermits[0].Token)
+	req.Header.Set("Content-Type", "image/png")
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload card: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, publisher.NewError(publisher.CodeForStatus(resp.StatusCode),
+			fmt.Errorf("card upload returned status %d: %s", resp.StatusCode, string(body)))
+	}
+
+	return &uploadedImage{FileID: fileID, Width: size.Width, Height: size.Height}, nil
+}
+
+// findTopic looks up the Xiaohongshu topic of a hashtag; hashtags without a topic of the same
+// name are posted as plain text
+func (p *XiaohongshuPublisher) findTopic(ctx context.Context, name string, n *note) (*noteTopic, bool) {
+	request := map[string]interface{}{
+		"keyword":               name,
+		"suggest_topic_request": map[string]string{"title": n.Title, "desc": n.Desc},
+		"page":                  map[string]int{"page_size": 20, "page": 1},
+	}
+	var found topicSearchResponse
+	if err := p.call(ctx, http.MethodPost, p.apiBaseURL, "/web_api/sns/v1/search/topic", request, &found); err != nil {
+		publisher.Logger(ctx, p.logger).Warn("Failed to look up Xiaohongshu topic",
+			zap.String("topic", name),
+			zap.Error(err))
+		return nil, false
+	}
+	for _, topic := range found.TopicInfos {
+		if topic.Name == name {
+			topic.Type = "topic"
+			return &topic, true
+		}
+	}
+	return nil, false
+}
+
+// createNote posts an image note with the uploaded cards as its images
+func (p *XiaohongshuPublisher) createNote(ctx context.Context, n *note, images []*uploadedImage, topics []noteTopic, desc string) (string, error) {
+	imageInfos := make([]map[string]interface{}, 0, len(images))
+	for _, img := range images {
+		imageInfos = append(imageInfos, map[string]interface{}{
+			"file_id":       img.FileID,
+			"width":         img.Width,
+			"height":        img.Height,
+			"metadata":      map[string]int{"source": -1},
+			"stickers":      map[string]interface{}{"version": 2, "floating": []interface{}{}},
+			"extra_info_v2": []interface{}{},
+		})
+	}
+
+	privacy := 0
+	if p.private {
+		privacy = 1
+	}
+	businessBinds, _ := json.Marshal(map[string]interface{}{
+		"version":            1,
+		"noteId":             0,
+		"noteOrderBind":      map[string]interface{}{},
+		"notePostTiming":     map[string]interface{}{},
+		"noteCollectionBind": map[string]string{"id": ""},
+	})
+
+	request := map[string]interface{}{
+		"common": map[string]interface{}{
+			"type":           "normal",
+			"title":          n.Title,
+			"note_id":        "",
+			"desc":           desc,
+			"source":         `{"type":"web","ids":"","extraInfo":"{\"subType\":\"official\"}"}`,
+			"business_binds": string(businessBinds),
+			"ats":            []interface{}{},
+			"hash_tag":       topics,
+			"post_loc":       map[string]interface{}{},
+			"privacy_info":   map[string]int{"op_type": 1, "type": privacy},
+		},
+		"image_info": map[string]interface{}{"images": imageInfos},
+		"video_info": nil,
+	}
+
+	var created createNoteResponse
+	if err := p.call(ctx, http.MethodPost, p.apiBaseURL, "/web_api/sns/v2/note", request, &created); err != nil {
+		return "", fmt.Errorf("failed to create note: %w", err)
+	}
+	if created.ID == "" {
+		created.ID = created.NoteID
+	}
+	if created.ID == "" {
+		return "", fmt.Errorf("note created without an ID")
+	}
+	return created.ID, nil
+}
+
+// call sends a signed web API request and decodes the data of the response into out
+func (p *XiaohongshuPublisher) call(ctx context.Context, method, baseURL, uri string, payload, out interface{}) error {
+	var data []byte
+	if payload != nil {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, baseURL+uri, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Cookie", p.cookie)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Origin", defaultCreatorBaseURL)
+	req.Header.Set("Referer", defaultCreatorBaseURL+"/")
+	req.Header.Set("User-Agent", userAgent)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json;charset=UTF-8")
+	}
+
+	signature, err := p.sign(ctx, uri, string(data))
+	if err != nil {
+		return err
+	}
+	for name, value := range signature {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp.StatusCode, body)
+	}
+
+	var result apiResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if !result.Success {
+		if sessionExpiredCodes[result.Code] {
+			return fmt.Errorf("%w: Xiaohongshu rejected the session cookie: %d - %s", publisher.ErrCredentialsExpired, result.Code, result.Msg)
+		}
+		return publisher.NewError(publisher.ErrorCodeContentInvalid, fmt.Errorf("Xiaohongshu API error: %d - %s", result.Code, result.Msg))
+	}
+
+	if out == nil || len(result.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(result.Data, out); err != nil {
+		return fmt.Errorf("failed to parse response data: %w", err)
+	}
+	return nil
+}
+
+// sign asks the signing service for the X-s and X-t headers of a request. Without a signing
+// service requests are sent unsigned, which the web API may reject.
+func (p *XiaohongshuPublisher) sign(ctx context.Context, uri, data string) (map[string]string, error) {
+	if p.signURL == "" {
+		return nil, nil
+	}
+
+	request, err := json.Marshal(map[string]string{
+		"uri":         uri,
+		"data":        data,
+		"a1":          cookieValue(p.cookie, "a1"),
+		"web_session": cookieValue(p.cookie, "web_session"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal sign request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.signURL, bytes.NewReader(request))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sign request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("signing service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	// X-t is a number with some signing services and a string with others
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse signature: %w", err)
+	}
+	headers := make(map[string]string, len(fields))
+	for name, raw := range fields {
+		if !strings.HasPrefix(strings.ToLower(name), "x-") {
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			value = string(raw)
+		}
+		headers[name] = value
+	}
+	return headers, nil
+}
+
+// statusError classifies a failed HTTP response; 461 and 471 ask for a captcha, which means the
+// account is calling too often
+func statusError(statusCode int, body []byte) error {
+	switch statusCode {
+	case http.StatusUnauthorized:
+		return fmt.Errorf("%w: Xiaohongshu rejected the session cookie (status %d): %s", publisher.ErrCredentialsExpired, statusCode, string(body))
+	case 461, 471:
+		return publisher.NewError(publisher.ErrorCodeRateLimited, fmt.Errorf("Xiaohongshu asked for verification (status %d): %s", statusCode, string(body)))
+	}
+	return publisher.NewError(publisher.CodeForStatus(statusCode), fmt.Errorf("API returned status %d: %s", statusCode, string(body)))
+}
+
+// cookieValue returns a cookie from a Cookie header
+func cookieValue(header, name string) string {
+	cookie, err := (&http.Request{Header: http.Header{"Cookie": {header}}}).Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// noteURL is the public page of a note
+func noteURL(noteID string) string {
+	return noteURLPrefix + url.PathEscape(noteID)
+}
@@ -0,0 +1,181 @@
+package xiaohongshu
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ifuryst/ripple/internal/content"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// Limits of a Xiaohongshu note
+const (
+	maxTitleRunes = 20
+	maxDescRunes  = 1000
+	maxTopics     = 10
+	maxCards      = 18
+	// cardTextRunes is about as much text as fits on a card at the font size of the card template
+	cardTextRunes = 160
+)
+
+// note is a page prepared as a Xiaohongshu note. It is the transformed content of a page, and is
+// kept next to its rendered cards while it waits as a draft.
+type note struct {
+	Title string `json:"title"`
+	Desc  string `json:"desc"`
+	// Topics are the hashtags of the note, from the page tags
+	Topics []string             `json:"topics"`
+	Cards  []publisher.NoteCard `json:"cards"`
+	// CardPaths are the rendered cards, in the order of Cards, once they are rendered
+	CardPaths []string `json:"card_paths,omitempty"`
+}
+
+// buildNote turns a page into a note: a cover card with the title, followed by cards of the
+// summary, or of the page text when the page has no summary
+func buildNote(page publisher.PublishContent, doc *content.Document, cardLimit int) *note {
+	if cardLimit < 2 || cardLimit > maxCards {
+		cardLimit = maxCards
+	}
+
+	paragraphs := noteParagraphs(page.Summary)
+	if len(paragraphs) == 0 {
+		paragraphs = noteParagraphs(doc.Text())
+	}
+	topics := noteTopics(page.Tags)
+
+	n := &note{
+		Title:  truncateRunes(page.Title, maxTitleRunes),
+		Desc:   truncateRunes(strings.Join(paragraphs, "\n"), maxDescRunes-topicsLength(topics)),
+		Topics: topics,
+	}
+
+	texts := packParagraphs(paragraphs, cardTextRunes)
+	if len(texts) > cardLimit-1 {
+		texts = texts[:cardLimit-1]
+		texts[len(texts)-1] = strings.TrimRight(texts[len(texts)-1], "。.!！?？") + "…"
+	}
+
+	pages := len(texts) + 1
+	n.Cards = append(n.Cards, publisher.NoteCard{
+		Cover:  true,
+		Title:  page.Title,
+		Author: page.Author,
+		Tags:   topics,
+		Page:   1,
+		Pages:  pages,
+	})
+	for i, text := range texts {
+		n.Cards = append(n.Cards, publisher.NoteCard{
+			Title: page.Title,
+			Text:  text,
+			Page:  i + 2,
+			Pages: pages,
+		})
+	}
+	return n
+}
+
+// decodeNote reads transformed content back as a note; ok is false for content that is not a note
+func decodeNote(data string) (*note, bool) {
+	var n note
+	if err := json.Unmarshal([]byte(data), &n); err != nil || len(n.Cards) == 0 {
+		return nil, false
+	}
+	return &n, true
+}
+
+func (n *note) encode() (string, error) {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// noteParagraphs splits text into its non-empty lines
+func noteParagraphs(text string) []string {
+	var paragraphs []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			paragraphs = append(paragraphs, line)
+		}
+	}
+	return paragraphs
+}
+
+// packParagraphs fills cards with whole paragraphs up to limit runes each, splitting paragraphs
+// longer than a card
+func packParagraphs(paragraphs []string, limit int) []string {
+	var cards []string
+	var current []string
+	size := 0
+	flush := func() {
+		if len(current) > 0 {
+			cards = append(cards, strings.Join(current, "\n\n"))
+			current, size = nil, 0
+		}
+	}
+
+	for _, paragraph := range paragraphs {
+		for _, chunk := range splitRunes(paragraph, limit) {
+			length := utf8.RuneCountInString(chunk)
+			if size > 0 && size+length > limit {
+				flush()
+			}
+			current = append(current, chunk)
+			size += length
+		}
+	}
+	flush()
+	return cards
+}
+
+// splitRunes splits text into chunks of at most limit runes
+func splitRunes(text string, limit int) []string {
+	runes := []rune(text)
+	var chunks []string
+	for len(runes) > limit {
+		chunks = append(chunks, string(runes[:limit]))
+		runes = runes[limit:]
+	}
+	return append(chunks, string(runes))
+}
+
+// noteTopics turns page tags into hashtags, which can't contain spaces or #
+func noteTopics(tags []string) []string {
+	seen := make(map[string]bool)
+	var topics []string
+	for _, tag := range tags {
+		topic := strings.NewReplacer(" ", "", "#", "").Replace(strings.TrimSpace(tag))
+		if topic == "" || seen[topic] {
+			continue
+		}
+		seen[topic] = true
+		topics = append(topics, topic)
+		if len(topics) == maxTopics {
+			break
+		}
+	}
+	return topics
+}
+
+// topicsLength is how many runes the hashtags take in the description, as "#topic[话题]# "
+func topicsLength(topics []string) int {
+	length := 0
+	for _, topic := range topics {
+		length += utf8.RuneCountInString(topic) + 7
+	}
+	return length
+}
+
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	if limit <= 1 {
+		return string(runes[:max(limit, 0)])
+	}
+	return string(runes[:limit-1]) + "…"
+}
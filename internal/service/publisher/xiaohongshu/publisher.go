@@ -0,0 +1,372 @@
+package xiaohongshu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/httpclient"
+)
+
+// PlatformName is the platform Xiaohongshu notes are published to
+const PlatformName = "xiaohongshu"
+
+// draftFile is the note a draft keeps next to its rendered cards
+const draftFile = "note.json"
+
+// XiaohongshuPublisher posts pages as Xiaohongshu (小红书) image notes. Xiaohongshu is about
+// images, so a page becomes a cover card with its title followed by cards of its summary,
+// rendered by the card renderer. Xiaohongshu has no drafts on the web, so drafts are the
+// rendered cards kept in the workspace, for review, until they are published.
+type XiaohongshuPublisher struct {
+	logger         *zap.Logger
+	client         *http.Client
+	renderer       publisher.CardRenderer
+	cookie         string
+	signURL        string
+	workspaceDir   string
+	maxCards       int
+	private        bool
+	apiBaseURL     string
+	creatorBaseURL string
+	uploadBaseURL  string
+}
+
+func NewXiaohongshuPublisher(logger *zap.Logger) publisher.Publisher {
+	return &XiaohongshuPublisher{
+		logger:         logger,
+		client:         httpclient.New(),
+		apiBaseURL:     defaultAPIBaseURL,
+		creatorBaseURL: defaultCreatorBaseURL,
+		uploadBaseURL:  defaultUploadBaseURL,
+	}
+}
+
+func (p *XiaohongshuPublisher) GetPlatformName() string {
+	return PlatformName
+}
+
+// SetCardRenderer sets the renderer of the cards notes are made of
+func (p *XiaohongshuPublisher) SetCardRenderer(renderer publisher.CardRenderer) {
+	p.renderer = renderer
+}
+
+func (p *XiaohongshuPublisher) Initialize(ctx context.Context, config publisher.PublishConfig) error {
+	if err := p.ValidateConfig(config); err != nil {
+		return err
+	}
+
+	p.cookie = config.Config["cookie"]
+	p.signURL = config.Config["sign_url"]
+	p.workspaceDir = config.Config["workspace_dir"]
+	p.private = config.Config["private"] == "true"
+	p.maxCards, _ = strconv.Atoi(config.Config["max_cards"])
+
+	var opts []httpclient.Option
+	if proxyURL := config.Config["proxy_url"]; proxyURL != "" {
+		parsed, err := httpclient.ParseProxy(proxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy_url: %w", err)
+		}
+		opts = append(opts, httpclient.WithProxy(parsed))
+	}
+	p.client = httpclient.New(opts...)
+
+	if err := os.MkdirAll(p.workspaceDir, 0755); err != nil {
+		return fmt.Errorf("failed to create workspace directory: %w", err)
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Xiaohongshu publisher initialized successfully",
+		zap.Bool("signed", p.signURL != ""))
+	return nil
+}
+
+func (p *XiaohongshuPublisher) ValidateConfig(config publisher.PublishConfig) error {
+	for _, key := range []string{"cookie", "workspace_dir"} {
+		if config.Config[key] == "" {
+			return fmt.Errorf("missing required config: %s", key)
+		}
+	}
+
+	if cookieValue(config.Config["cookie"], "a1") == "" {
+		return fmt.Errorf("invalid cookie: the a1 cookie is missing")
+	}
+
+	if value := config.Config["max_cards"]; value != "" {
+		count, err := strconv.Atoi(value)
+		if err != nil || count < 2 || count > maxCards {
+			return fmt.Errorf("invalid max_cards %q, expected a number between 2 and %d", value, maxCards)
+		}
+	}
+
+	return nil
+}
+
+// TransformContent turns the page into a note; the content becomes the note as JSON
+func (p *XiaohongshuPublisher) TransformContent(ctx context.Context, content publisher.PublishContent) (*publisher.PublishContent, error) {
+	defer publisher.TimeStage(ctx, models.StageTransform)()
+	doc, err := content.ContentDocument()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse content: %w", err)
+	}
+
+	encoded, err := buildNote(content, doc, p.maxCards).encode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode note: %w", err)
+	}
+
+	result := content
+	result.Content = encoded
+	result.Document = nil
+	result.Resources = nil
+	result.Metadata = make(map[string]string)
+	for k, v := range content.Metadata {
+		result.Metadata[k] = v
+	}
+	return &result, nil
+}
+
+// ProcessResources renders the cards of the note into a new draft in the workspace
+func (p *XiaohongshuPublisher) ProcessResources(ctx context.Context, content *publisher.PublishContent, config publisher.PublishConfig) error {
+	defer publisher.TimeStage(ctx, models.StageMedia)()
+	if p.renderer == nil {
+		return fmt.Errorf("no card renderer to render the note cards, configure CARD_RENDERER")
+	}
+
+	n, ok := decodeNote(content.Content)
+	if !ok {
+		return fmt.Errorf("content is not a transformed note")
+	}
+
+	draftID := fmt.Sprintf("%s-%d", sanitizeID(content.ID), time.Now().UnixNano())
+	paths, err := p.renderer.RenderNoteCards(ctx, n.Cards, p.draftDir(draftID))
+	if err != nil {
+		os.RemoveAll(p.draftDir(draftID))
+		return fmt.Errorf("failed to render note cards: %w", err)
+	}
+	n.CardPaths = paths
+
+	if content.Content, err = n.encode(); err != nil {
+		return fmt.Errorf("failed to encode note: %w", err)
+	}
+	content.Resources = nil
+	for i, path := range paths {
+		content.Resources = append(content.Resources, publisher.Resource{
+			ID:   fmt.Sprintf("xiaohongshu_card_%d", i+1),
+			Type: publisher.ResourceTypeImage,
+			URL:  path,
+		})
+	}
+	content.Metadata["draft_id"] = draftID
+	return nil
+}
+
+// SaveToDraft keeps the note with its rendered cards in the workspace until it is published
+func (p *XiaohongshuPublisher) SaveToDraft(ctx context.Context, content publisher.PublishContent, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	if _, ok := decodeNote(content.Content); !ok || content.Metadata["draft_id"] == "" {
+		transformed, err := p.TransformContent(ctx, content)
+		if err != nil {
+			return failed(err), nil
+		}
+		if err := p.ProcessResources(ctx, transformed, config); err != nil {
+			return failed(err), nil
+		}
+		content = *transformed
+	}
+
+	draftID := content.Metadata["draft_id"]
+	dir := p.draftDir(draftID)
+	if err := os.WriteFile(filepath.Join(dir, draftFile), []byte(content.Content), 0644); err != nil {
+		return failed(fmt.Errorf("failed to save draft: %w", err)), nil
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Xiaohongshu draft saved",
+		zap.String("draft_id", draftID),
+		zap.Int("cards", len(content.Resources)))
+
+	return &publisher.PublishResult{
+		Success:   true,
+		PublishID: draftID,
+		URL:       "file://" + dir,
+		Metadata: map[string]string{
+			"draft_id": draftID,
+			"cards":    strconv.Itoa(len(content.Resources)),
+		},
+	}, nil
+}
+
+// Publish uploads the cards of a draft and posts them as a note, with the tags of the page as
+// hashtags linked to the Xiaohongshu topics of the same name
+func (p *XiaohongshuPublisher) Publish(ctx context.Context, draftID string, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	n, err := p.loadDraft(draftID)
+	if err != nil {
+		return failed(err), nil
+	}
+
+	images := make([]*uploadedImage, 0, len(n.CardPaths))
+	endUpload := publisher.TimeStage(ctx, models.StageUpload)
+	for _, path := range n.CardPaths {
+		img, err := p.uploadCard(ctx, path)
+		if err != nil {
+			endUpload()
+			return failed(err), nil
+		}
+		images = append(images, img)
+	}
+	endUpload()
+
+	defer publisher.TimeStage(ctx, models.StagePublish)()
+
+	var topics []noteTopic
+	desc := n.Desc
+	for _, name := range n.Topics {
+		if topic, ok := p.findTopic(ctx, name, n); ok {
+			topics = append(topics, *topic)
+			desc += " #" + name + "[话题]#"
+		} else {
+			desc += " #" + name
+		}
+	}
+
+	noteID, err := p.createNote(ctx, n, images, topics, strings.TrimSpace(desc))
+	if err != nil {
+		return failed(err), nil
+	}
+
+	// The note is posted, a leftover draft only takes up space
+	if err := os.RemoveAll(p.draftDir(draftID)); err != nil {
+		publisher.Logger(ctx, p.logger).Warn("Failed to remove published Xiaohongshu draft",
+			zap.String("draft_id", draftID),
+			zap.Error(err))
+	}
+
+	publisher.Logger(ctx, p.logger).Info("Xiaohongshu note published",
+		zap.String("note_id", noteID),
+		zap.Int("cards", len(images)),
+		zap.Int("topics", len(topics)))
+
+	return &publisher.PublishResult{
+		Success:     true,
+		PublishID:   noteID,
+		URL:         noteURL(noteID),
+		PublishedAt: time.Now(),
+		Metadata: map[string]string{
+			"note_id":  noteID,
+			"draft_id": draftID,
+			"private":  strconv.FormatBool(p.private),
+		},
+	}, nil
+}
+
+func (p *XiaohongshuPublisher) PublishDirect(ctx context.Context, content publisher.PublishContent, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	draftResult, err := p.SaveToDraft(ctx, content, config)
+	if err != nil || !draftResult.Success {
+		return draftResult, err
+	}
+
+	if config.Config["auto_publish"] != "true" {
+		return draftResult, nil
+	}
+	return p.Publish(ctx, draftResult.PublishID, config)
+}
+
+// GetPublishStatus reports drafts still waiting in the workspace; anything else is a posted note
+func (p *XiaohongshuPublisher) GetPublishStatus(ctx context.Context, publishID string, config publisher.PublishConfig) (*publisher.PublishResult, error) {
+	if _, err := p.loadDraft(publishID); err == nil {
+		return &publisher.PublishResult{
+			Success:   true,
+			PublishID: publishID,
+			URL:       "file://" + p.draftDir(publishID),
+			Metadata:  map[string]string{"status": "draft"},
+		}, nil
+	}
+
+	return &publisher.PublishResult{
+		Success:   true,
+		PublishID: publishID,
+		URL:       noteURL(publishID),
+		Metadata:  map[string]string{"status": "published"},
+	}, nil
+}
+
+// Unpublish discards a draft. Posted notes can only be deleted in the Xiaohongshu app.
+func (p *XiaohongshuPublisher) Unpublish(ctx context.Context, publishID string, config publisher.PublishConfig) error {
+	if _, err := p.loadDraft(publishID); err != nil {
+		return fmt.Errorf("%w: note %s is posted, delete it in the Xiaohongshu app", publisher.ErrManualRemoval, publishID)
+	}
+	return p.Cleanup(ctx, publishID, config)
+}
+
+// Cleanup removes the rendered cards of a draft
+func (p *XiaohongshuPublisher) Cleanup(ctx context.Context, publishID string, config publisher.PublishConfig) error {
+	if !validDraftID(publishID) {
+		return nil
+	}
+	if err := os.RemoveAll(p.draftDir(publishID)); err != nil {
+		return fmt.Errorf("failed to remove draft: %w", err)
+	}
+	publisher.Logger(ctx, p.logger).Info("Xiaohongshu draft removed", zap.String("draft_id", publishID))
+	return nil
+}
+
+// loadDraft reads the note of a draft in the workspace
+func (p *XiaohongshuPublisher) loadDraft(draftID string) (*note, error) {
+	if !validDraftID(draftID) {
+		return nil, fmt.Errorf("invalid draft ID %q", draftID)
+	}
+
+	data, err := os.ReadFile(filepath.Join(p.draftDir(draftID), draftFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("draft %s not found", draftID)
+		}
+		return nil, fmt.Errorf("failed to read draft: %w", err)
+	}
+
+	n, ok := decodeNote(string(data))
+	if !ok || len(n.CardPaths) == 0 {
+		return nil, fmt.Errorf("draft %s has no rendered cards", draftID)
+	}
+	return n, nil
+}
+
+func (p *XiaohongshuPublisher) draftDir(draftID string) string {
+	return filepath.Join(p.workspaceDir, draftID)
+}
+
+// validDraftID keeps publish IDs from reaching outside the workspace
+func validDraftID(draftID string) bool {
+	return draftID != "" && draftID != "." && draftID != ".." && filepath.Base(draftID) == draftID
+}
+
+// sanitizeID keeps letters, digits, - and _ so a page ID can name a directory
+func sanitizeID(id string) string {
+	sanitized := strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			return r
+		}
+		return '_'
+	}, id)
+	if sanitized == "" {
+		return "note"
+	}
+	return sanitized
+}
+
+func failed(err error) *publisher.PublishResult {
+	return &publisher.PublishResult{
+		Success:  false,
+		Error:    err,
+		ErrorMsg: err.Error(),
+	}
+}
@@ -0,0 +1,38 @@
+package xiaohongshu
+
+import (
+	"strings"
+
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// Validate checks that the page has a title for the cover card and text for the other cards;
+// titles longer than a note title allows are shortened, the cover card keeps them whole
+func (p *XiaohongshuPublisher) Validate(content publisher.PublishContent, config publisher.PublishConfig) []publisher.Violation {
+	var violations []publisher.Violation
+
+	if strings.TrimSpace(content.Title) == "" {
+		violations = append(violations, publisher.Violation{
+			Field:   "title",
+			Rule:    "required",
+			Message: "title is required",
+		})
+	}
+
+	if strings.TrimSpace(content.Summary) != "" {
+		return violations
+	}
+	doc, err := content.ContentDocument()
+	if err != nil {
+		// Unparseable content fails the transform with a clearer error
+		return violations
+	}
+	if strings.TrimSpace(doc.Text()) == "" {
+		violations = append(violations, publisher.Violation{
+			Field:   "content",
+			Rule:    "required",
+			Message: "a summary or text is required for the note cards",
+		})
+	}
+	return violations
+}
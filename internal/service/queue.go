@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/notion"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// defaultPublishesPerCycle is used when publishes_per_cycle is not configured
+const defaultPublishesPerCycle = 10
+
+// ErrInvalidPriority is returned for priorities other than urgent, normal and backfill
+var ErrInvalidPriority = errors.New("invalid priority, expected urgent, normal or backfill")
+
+// QueuedPublish is a page waiting to be published to a platform
+type QueuedPublish struct {
+	PageID   string    `json:"page_id"`
+	Title    string    `json:"title"`
+	Priority string    `json:"priority"`
+	Platform string    `json:"platform"`
+	Since    time.Time `json:"since"`
+
+	page uint
+}
+
+// PublishQueues returns the pages waiting to be published per platform, most urgent first and
+// oldest first within a priority. Pages are waiting until their job on the platform completed
+// or was quarantined, or saved a draft on a draft-only platform. Held pages are not queued.
+func (s *PublisherService) PublishQueues(ctx context.Context) (map[string][]QueuedPublish, error) {
+	// The body is not needed to route pages, so it isn't loaded for the whole backlog
+	var pages []models.NotionPage
+	if err := s.db.WithContext(ctx).
+		Select("id", "notion_id", "title", "priority", "platforms", "tags", "content_type", "created_at").
+		Where("status = ?", "Done").
+		Where("archived_at IS NULL").
+		Where("hold = ? AND locked_at IS NULL", false).
+		Where("unpublish_at IS NULL OR unpublish_at > ?", time.Now()).
+		Find(&pages).Error; err != nil {
+		return nil, fmt.Errorf("failed to get pending pages: %w", err)
+	}
+
+	sort.SliceStable(pages, func(i, j int) bool {
+		if ri, rj := models.PriorityRank(pages[i].Priority), models.PriorityRank(pages[j].Priority); ri != rj {
+			return ri < rj
+		}
+		return pages[i].CreatedAt.Before(pages[j].CreatedAt)
+	})
+
+	status, err := s.latestJobStatuses(ctx, pages)
+	if err != nil {
+		return nil, err
+	}
+
+	// Paused platforms hold their queue until they are resumed
+	paused := make(map[string]bool)
+	if platforms, err := s.manager.PausedPlatforms(); err == nil {
+		for _, platform := range platforms {
+			paused[platform.Name] = true
+		}
+	}
+
+	queues := make(map[string][]QueuedPublish)
+	for _, page := range pages {
+		route := s.manager.RoutePublishedPage(&page, status[page.ID])
+		for _, platform := range route.Platforms {
+			if paused[platform] || !waitsToPublish(route, platform, status[page.ID][platform]) {
+				continue
+			}
+
+			priority := page.Priority
+			if !models.IsValidPriority(priority) {
+				priority = models.PriorityNormal
+			}
+			queues[platform] = append(queues[platform], QueuedPublish{
+				PageID:   page.NotionID,
+				Title:    page.Title,
+				Priority: priority,
+				Platform: platform,
+				Since:    page.CreatedAt,
+				page:     page.ID,
+			})
+		}
+	}
+	return queues, nil
+}
+
+// waitsToPublish reports whether a page routed to a platform still waits to be published there,
+// given the status of its latest job on the platform
+func waitsToPublish(route *publisher.Route, platformName, status string) bool {
+	switch status {
+	case models.JobCompleted, models.JobQuarantined:
+		// Quarantined jobs wait for a republish
+		return false
+	case models.JobDraft:
+		// Drafts of draft-only platforms wait for the promote action
+		return !route.IsDraft(platformName)
+	}
+	return true
+}
+
+// latestJobStatuses returns the status of the latest job of each page per platform name
+func (s *PublisherService) latestJobStatuses(ctx context.Context, pages []models.NotionPage) (map[uint]map[string]string, error) {
+	status := make(map[uint]map[string]string)
+	if len(pages) == 0 {
+		return status, nil
+	}
+
+	ids := make([]uint, len(pages))
+	for i, page := range pages {
+		ids[i] = page.ID
+	}
+
+	var jobs []models.DistributionJob
+	if err := s.db.WithContext(ctx).Preload("Platform").
+		Where("page_id IN ?", ids).
+		Order("updated_at ASC").
+		Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get distribution jobs: %w", err)
+	}
+
+	for _, job := range jobs {
+		if status[job.PageID] == nil {
+			status[job.PageID] = make(map[string]string)
+		}
+		status[job.PageID][job.Platform.Name] = job.Status
+	}
+	return status, nil
+}
+
+// scheduleFairly picks up to limit publishes from the platform queues. Each round takes the head
+// of every queue, most urgent heads first, so a long backlog on one platform can't hold back the
+// others and an urgent page waits for at most one round.
+func scheduleFairly(queues map[string][]QueuedPublish, limit int) []QueuedPublish {
+	platforms := make([]string, 0, len(queues))
+	for platform := range queues {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	var scheduled []QueuedPublish
+	for round := 0; len(scheduled) < limit; round++ {
+		var heads []QueuedPublish
+		for _, platform := range platforms {
+			if round < len(queues[platform]) {
+				heads = append(heads, queues[platform][round])
+			}
+		}
+		if len(heads) == 0 {
+			break
+		}
+
+		sort.SliceStable(heads, func(i, j int) bool {
+			return models.PriorityRank(heads[i].Priority) < models.PriorityRank(heads[j].Priority)
+		})
+		for _, head := range heads {
+			if len(scheduled) == limit {
+				break
+			}
+			scheduled = append(scheduled, head)
+		}
+	}
+	return scheduled
+}
+
+// publishesPerCycle returns how many publishes the scheduler runs per cycle
+func (s *PublisherService) publishesPerCycle() int {
+	if s.config.Publisher.PublishesPerCycle > 0 {
+		return s.config.Publisher.PublishesPerCycle
+	}
+	return defaultPublishesPerCycle
+}
+
+// SetPagePriority changes the priority a page is published with, e.g. to publish an urgent
+// post ahead of a backlog. Sync keeps the priority, it is not a Notion property.
+func (s *PublisherService) SetPagePriority(pageID, priority string) (*models.NotionPage, error) {
+	if !models.IsValidPriority(priority) {
+		return nil, ErrInvalidPriority
+	}
+
+	var page models.NotionPage
+	if err := s.db.Where("notion_id = ?", notion.NormalizePageID(pageID)).First(&page).Error; err != nil {
+		return nil, fmt.Errorf("page not found: %w", err)
+	}
+
+	if err := s.db.Model(&page).Update("priority", priority).Error; err != nil {
+		return nil, fmt.Errorf("failed to update priority: %w", err)
+	}
+	return &page, nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// ErrJobNotRepublishable is returned when republishing a job whose page or platform is gone
+var ErrJobNotRepublishable = errors.New("job cannot be republished")
+
+// RepublishReport describes what republishing a job did on its platform
+type RepublishReport struct {
+	JobID    uint   `json:"job_id"`
+	Platform string `json:"platform"`
+	// Action is one of the rerun actions: republished, unchanged or failed
+	Action         string `json:"action"`
+	ContentChanged bool   `json:"content_changed"`
+	// Job is the latest job of the page and platform after the republish
+	Job    *models.DistributionJob  `json:"job"`
+	Result *publisher.PublishResult `json:"result,omitempty"`
+}
+
+// RepublishJob publishes the page of a job again, to the job's platform only. A completed job
+// whose page content hasn't changed since it was published is left alone unless force is set.
+func (s *PublisherService) RepublishJob(ctx context.Context, jobID uint, force bool) (*RepublishReport, error) {
+	var job models.DistributionJob
+	if err := s.db.Preload("Page").Preload("Platform").First(&job, jobID).Error; err != nil {
+		return nil, err
+	}
+	if job.Page.NotionID == "" {
+		return nil, fmt.Errorf("%w: job %d has no associated page", ErrJobNotRepublishable, job.ID)
+	}
+	if job.Platform.Name == "" {
+		return nil, fmt.Errorf("%w: job %d has no associated platform", ErrJobNotRepublishable, job.ID)
+	}
+	page := &job.Page
+	if page.IsArchived() {
+		return nil, fmt.Errorf("%w: %s", ErrPageArchived, page.ArchiveReason)
+	}
+	platformName := job.Platform.Name
+
+	report := &RepublishReport{
+		JobID:          job.ID,
+		Platform:       platformName,
+		ContentChanged: contentChangedSince(&job, page),
+	}
+
+	if job.Status == models.JobCompleted && !force && !report.ContentChanged {
+		s.logger.Info("Skipping republish of unchanged content",
+			zap.Uint("job_id", job.ID),
+			zap.String("page_id", page.NotionID),
+			zap.String("platform", platformName))
+		report.Action = RerunActionUnchanged
+		report.Job = &job
+		return report, nil
+	}
+
+	s.logger.Info("Republishing job",
+		zap.Uint("job_id", job.ID),
+		zap.String("page_id", page.NotionID),
+		zap.String("platform", platformName),
+		zap.String("original_status", job.Status),
+		zap.Bool("content_changed", report.ContentChanged),
+		zap.Bool("force", force))
+
+	// The job no longer counts as completed, so the publish below records a new one. A job
+	// already marked is simply processed again.
+	if job.Status != models.JobRepublishRequested {
+		if err := publisher.TransitionJob(s.db, &job, models.JobRepublishRequested, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	// Like a rerun, a republish is an explicit operator action a tripped circuit shouldn't block
+	s.manager.CircuitBreaker().Reset(platformName)
+
+	result, err := s.manager.PublishSinglePlatform(ctx, page, platformName, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to republish to %s: %w", platformName, err)
+	}
+	s.recordPublishResult(ctx, page, platformName, result)
+	report.Result = result
+
+	if result.Success {
+		report.Action = RerunActionRepublished
+		s.markPublishedIfCompleted(ctx, page)
+	} else {
+		report.Action = RerunActionFailed
+	}
+
+	var latest models.DistributionJob
+	if err := s.db.Preload("Page").Preload("Platform").
+		Where("page_id = ? AND platform_id = ?", job.PageID, job.PlatformID).
+		Order("created_at DESC").
+		First(&latest).Error; err != nil {
+		return nil, fmt.Errorf("failed to get republished job: %w", err)
+	}
+	report.Job = &latest
+
+	s.logger.Info("Republish completed",
+		zap.Uint("job_id", job.ID),
+		zap.Uint("new_job_id", latest.ID),
+		zap.String("platform", platformName),
+		zap.String("action", report.Action))
+	return report, nil
+}
+
+// contentChangedSince reports whether the page content differs from what the job published. Jobs
+// recorded before content was hashed fall back to comparing the page's last edit.
+func contentChangedSince(job *models.DistributionJob, page *models.NotionPage) bool {
+	if job.ContentHash != "" {
+		return job.ContentHash != publisher.ContentHash(publisher.FromNotionPage(page))
+	}
+	if job.PublishedAt != nil {
+		return page.LastModified.After(*job.PublishedAt)
+	}
+	return true
+}
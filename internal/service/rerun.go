@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/notion"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// Rerun actions reported per platform
+const (
+	RerunActionPublished   = "published"
+	RerunActionRepublished = "republished"
+	RerunActionUnchanged   = "unchanged"
+	RerunActionFailed      = "failed"
+)
+
+// RerunPlatformResult describes what a rerun did on a single platform
+type RerunPlatformResult struct {
+	Platform          string `json:"platform"`
+	Action            string `json:"action"`
+	PreviousPublishID string `json:"previous_publish_id,omitempty"`
+	PublishID         string `json:"publish_id,omitempty"`
+	Error             string `json:"error,omitempty"`
+}
+
+// RerunReport summarizes a single-page rerun
+type RerunReport struct {
+	PageID         string                `json:"page_id"`
+	Title          string                `json:"title"`
+	ContentChanged bool                  `json:"content_changed"`
+	Platforms      []RerunPlatformResult `json:"platforms"`
+}
+
+// RerunPage re-syncs a page from Notion and republishes it to the given platforms ("all" uses the
+// page's own platforms). Platforms whose last publish is current are left alone unless force is set,
+// so running it twice does no extra work.
+func (s *PublisherService) RerunPage(ctx context.Context, pageID string, platforms []string, force bool) (*RerunReport, error) {
+	notionID := notion.NormalizePageID(pageID)
+
+	// Remember the stored content so we can tell whether the re-sync changed anything
+	previousHash := ""
+	fromNotion := true
+	var existing models.NotionPage
+	if err := s.db.Where("notion_id = ?", notionID).First(&existing).Error; err == nil {
+		previousHash = contentHash(existing.Content)
+		fromNotion = existing.IsFromNotion()
+	}
+
+	// Always refetch the content so expired Notion image URLs are replaced; other sources are
+	// re-read by their own sync
+	if fromNotion {
+		if _, _, err := s.notionService.SyncPage(notionID, true); err != nil {
+			return nil, fmt.Errorf("failed to re-sync page from Notion: %w", err)
+		}
+	}
+
+	var page models.NotionPage
+	if err := s.db.Where("notion_id = ?", notionID).First(&page).Error; err != nil {
+		return nil, fmt.Errorf("page not found after sync: %w", err)
+	}
+
+	report := &RerunReport{
+		PageID:         page.NotionID,
+		Title:          page.Title,
+		ContentChanged: previousHash != contentHash(page.Content),
+	}
+
+	for _, platformName := range s.resolveRerunPlatforms(&page, platforms) {
+		report.Platforms = append(report.Platforms, s.rerunPlatform(ctx, &page, platformName, force || report.ContentChanged))
+	}
+
+	s.logger.Info("Page rerun completed",
+		zap.String("page_id", page.NotionID),
+		zap.String("title", page.Title),
+		zap.Bool("content_changed", report.ContentChanged),
+		zap.Int("platforms", len(report.Platforms)))
+
+	return report, nil
+}
+
+func (s *PublisherService) rerunPlatform(ctx context.Context, page *models.NotionPage, platformName string, republish bool) RerunPlatformResult {
+	result := RerunPlatformResult{Platform: platformName}
+
+	var previousJob models.DistributionJob
+	hasPrevious := s.db.Where("page_id = ? AND status = ?", page.ID, "completed").
+		Where("platform_id IN (?)", s.db.Model(&models.Platform{}).Select("id").Where("name = ?", platformName)).
+		Order("updated_at DESC").
+		First(&previousJob).Error == nil
+
+	if hasPrevious {
+		result.PreviousPublishID = previousJob.PublishID
+		if !republish {
+			result.Action = RerunActionUnchanged
+			result.PublishID = previousJob.PublishID
+			return result
+		}
+
+		// Same as a manual republish: the old job no longer counts as completed
+		if err := publisher.TransitionJob(s.db, &previousJob, models.JobRepublishRequested, ""); err != nil {
+			result.Action = RerunActionFailed
+			result.Error = fmt.Sprintf("failed to mark previous job for republish: %v", err)
+			return result
+		}
+	}
+
+	// A rerun is an explicit operator action, so neither a tripped circuit nor a quarantined job should block it
+	s.manager.CircuitBreaker().Reset(platformName)
+
+	var quarantinedJob models.DistributionJob
+	if s.db.Where("page_id = ? AND status = ?", page.ID, models.JobQuarantined).
+		Where("platform_id IN (?)", s.db.Model(&models.Platform{}).Select("id").Where("name = ?", platformName)).
+		First(&quarantinedJob).Error == nil {
+		if err := publisher.TransitionJob(s.db, &quarantinedJob, models.JobRepublishRequested, ""); err != nil {
+			result.Action = RerunActionFailed
+			result.Error = fmt.Sprintf("failed to release quarantined job: %v", err)
+			return result
+		}
+	}
+
+	publishResult, err := s.manager.PublishSinglePlatform(ctx, page, platformName, false)
+	if err != nil {
+		result.Action = RerunActionFailed
+		result.Error = err.Error()
+		return result
+	}
+	if !publishResult.Success {
+		result.Action = RerunActionFailed
+		result.Error = publishResult.ErrorMsg
+		s.monitoringService.RecordError(publishErrorLevel(publishResult.Error), "publisher", fmt.Sprintf("Rerun failed on %s", platformName), publishResult.ErrorMsg,
+			WithPlatform(platformName),
+			WithPage(page.ID),
+			WithErrorType(errorTypeOf(publishResult.Error)),
+			WithErrorCode(publishResult.ErrorCode),
+			WithRequest(ctx))
+		return result
+	}
+
+	result.PublishID = publishResult.PublishID
+	if hasPrevious {
+		result.Action = RerunActionRepublished
+	} else {
+		result.Action = RerunActionPublished
+	}
+	return result
+}
+
+// resolveRerunPlatforms expands "all" into the platforms the page is routed to and keeps only
+// registered publishers
+func (s *PublisherService) resolveRerunPlatforms(page *models.NotionPage, platforms []string) []string {
+	requested := platforms
+	if len(requested) == 0 || (len(requested) == 1 && requested[0] == "all") {
+		requested = s.manager.RoutePage(page).Platforms
+	}
+
+	available := make(map[string]bool)
+	for _, name := range s.GetAvailablePlatforms() {
+		available[name] = true
+	}
+
+	seen := make(map[string]bool)
+	var resolved []string
+	for _, name := range requested {
+		systemName := s.manager.MapPlatformName(strings.TrimSpace(name))
+		if systemName == "" || seen[systemName] {
+			continue
+		}
+		seen[systemName] = true
+
+		if !available[systemName] {
+			s.logger.Warn("Skipping unavailable platform for rerun", zap.String("platform", systemName))
+			continue
+		}
+		resolved = append(resolved, systemName)
+	}
+
+	return resolved
+}
+
+// contentHash fingerprints page content, ignoring URL signatures that change on every fetch
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(publisher.StripURLSignatures(content)))
+	return hex.EncodeToString(sum[:])
+}
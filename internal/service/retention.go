@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/config"
+)
+
+// CleanupReport lists the rows a retention cleanup removed per table
+type CleanupReport struct {
+	StartedAt time.Time        `json:"started_at"`
+	Duration  string           `json:"duration"`
+	Deleted   map[string]int64 `json:"deleted"`
+	// PurgedPages are the archived pages removed after their retention period
+	PurgedPages []string `json:"purged_pages"`
+	Errors      []string `json:"errors,omitempty"`
+}
+
+// RetentionCleaner periodically removes monitoring data, logs and archived pages older than
+// their retention period
+type RetentionCleaner struct {
+	monitoringService *MonitoringService
+	publisherService  *PublisherService
+	policy            config.RetentionConfig
+	logger            *zap.Logger
+	done              chan struct{}
+
+	mu         sync.Mutex
+	lastReport *CleanupReport
+}
+
+// NewRetentionCleaner creates a retention cleaner; an interval of 0 disables the scheduled cleanup
+func NewRetentionCleaner(monitoringService *MonitoringService, publisherService *PublisherService, policy config.RetentionConfig, logger *zap.Logger) *RetentionCleaner {
+	return &RetentionCleaner{
+		monitoringService: monitoringService,
+		publisherService:  publisherService,
+		policy:            policy,
+		logger:            logger,
+		done:              make(chan struct{}),
+	}
+}
+
+// Start begins the scheduled cleanup
+func (c *RetentionCleaner) Start(ctx context.Context) {
+	if c.policy.Interval <= 0 {
+		c.logger.Info("Retention cleanup is disabled")
+		return
+	}
+
+	go func() {
+		c.logger.Info("Starting retention cleaner", zap.Duration("interval", c.policy.Interval))
+		ticker := time.NewTicker(c.policy.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c.done:
+				c.logger.Info("Retention cleaner stopped")
+				return
+			case <-ctx.Done():
+				c.logger.Info("Retention cleaner stopped due to context cancellation")
+				return
+			case <-ticker.C:
+				c.Run(ctx)
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduled cleanup
+func (c *RetentionCleaner) Stop() {
+	close(c.done)
+}
+
+// Run removes the data older than its retention period now. Failures of one kind of data are
+// reported without stopping the cleanup of the others, and a cleanup running on another replica
+// is reported as an error.
+func (c *RetentionCleaner) Run(ctx context.Context) *CleanupReport {
+	// A manual cleanup and the scheduled one don't run at the same time
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	report := &CleanupReport{
+		StartedAt:   time.Now(),
+		Deleted:     make(map[string]int64),
+		PurgedPages: []string{},
+	}
+
+	// Nor does it run on two replicas at once
+	unlock, err := c.publisherService.locker.acquire(ctx, LockRetention, false)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+		report.Duration = time.Since(report.StartedAt).String()
+		c.logger.Info("Retention cleanup skipped", zap.Error(err))
+		return report
+	}
+	defer unlock()
+
+	deleted, err := c.monitoringService.CleanupOldData(c.policy)
+	for table, count := range deleted {
+		report.Deleted[table] += count
+	}
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+	}
+
+	if c.publisherService.config.Notion.ArchivedRetention > 0 {
+		purge, err := c.publisherService.PurgeArchivedPages(ctx, 0)
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		} else {
+			report.PurgedPages = purge.Pages
+			for table, count := range purge.Deleted {
+				report.Deleted[table] += int64(count)
+			}
+		}
+	}
+
+	report.Duration = time.Since(report.StartedAt).String()
+	c.lastReport = report
+
+	if len(report.Errors) > 0 {
+		c.logger.Error("Retention cleanup failed", zap.Strings("errors", report.Errors), zap.Any("deleted", report.Deleted))
+	} else {
+		c.logger.Info("Retention cleanup completed",
+			zap.Any("deleted", report.Deleted),
+			zap.Int("purged_pages", len(report.PurgedPages)))
+	}
+	return report
+}
+
+// LastReport returns the report of the latest cleanup, or nil before the first one
+func (c *RetentionCleaner) LastReport() *CleanupReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastReport
+}
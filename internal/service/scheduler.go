@@ -2,42 +2,98 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/ifuryst/ripple/internal/service/notion"
+	"runtime/debug"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/ifuryst/ripple/internal/config"
+	"github.com/ifuryst/ripple/internal/service/source"
 )
 
 type Scheduler struct {
 	config           *config.SchedulerConfig
 	logger           *zap.Logger
 	notionService    *notion.Service
+	sourceSyncer     *source.Syncer
 	publisherService *PublisherService
-	ticker           *time.Ticker
-	stopCh           chan struct{}
+	// queue takes the pending publishes for the publish workers
+	queue TaskQueue
+
+	// mu guards the settings and the running loop, which change when the configuration is reloaded
+	mu     sync.Mutex
+	freeze *FreezeSchedule
+	ctx    context.Context
+	ticker *time.Ticker
+	stopCh chan struct{}
 }
 
-func NewScheduler(cfg *config.SchedulerConfig, logger *zap.Logger, notionService *notion.Service, publisherService *PublisherService) *Scheduler {
-	return &Scheduler{
+func NewScheduler(cfg *config.SchedulerConfig, logger *zap.Logger, notionService *notion.Service, sourceSyncer *source.Syncer, publisherService *PublisherService, queue TaskQueue) *Scheduler {
+	s := &Scheduler{
 		config:           cfg,
 		logger:           logger,
 		notionService:    notionService,
+		sourceSyncer:     sourceSyncer,
 		publisherService: publisherService,
-		stopCh:           make(chan struct{}),
+		queue:            queue,
 	}
+	s.setupFreeze(cfg)
+	return s
+}
+
+// setupFreeze parses the freeze schedule; invalid settings are logged and keep the current
+// schedule, or no freeze at startup. s.mu must be held or the scheduler not shared yet.
+func (s *Scheduler) setupFreeze(cfg *config.SchedulerConfig) {
+	freeze, err := ParseFreezeSchedule(cfg.Freeze)
+	if err != nil {
+		s.logger.Error("Invalid freeze settings, keeping the current schedule", zap.Error(err))
+		if s.freeze == nil {
+			s.freeze, _ = ParseFreezeSchedule(config.FreezeConfig{})
+		}
+		return
+	}
+	s.freeze = freeze
+}
+
+// FreezeSchedule returns the schedule of maintenance mode and the freeze windows
+func (s *Scheduler) FreezeSchedule() *FreezeSchedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.freeze
+}
+
+// FreezeStatus returns whether scheduled publishing is frozen at now
+func (s *Scheduler) FreezeStatus(now time.Time) FreezeStatus {
+	return s.FreezeSchedule().Status(now)
 }
 
 func (s *Scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ctx = ctx
 	if !s.config.Enabled {
 		s.logger.Info("Scheduler is disabled")
 		return nil
 	}
 
+	s.start()
+	return nil
+}
+
+// start runs a sync right away and then on every tick; s.mu must be held
+func (s *Scheduler) start() {
 	s.logger.Info("Starting scheduler", zap.String("sync_interval", s.config.SyncInterval.String()))
 
-	s.ticker = time.NewTicker(s.config.SyncInterval)
+	ticker := time.NewTicker(s.config.SyncInterval)
+	stopCh := make(chan struct{})
+	s.ticker = ticker
+	s.stopCh = stopCh
+	ctx := s.ctx
 
 	// Run first sync immediately
 	go func() {
@@ -51,12 +107,12 @@ func (s *Scheduler) Start(ctx context.Context) error {
 	go func() {
 		for {
 			select {
-			case <-s.ticker.C:
+			case <-ticker.C:
 				s.logger.Info("Running scheduled sync")
 				if err := s.runSync(); err != nil {
 					s.logger.Error("Scheduled sync failed", zap.Error(err))
 				}
-			case <-s.stopCh:
+			case <-stopCh:
 				s.logger.Info("Scheduler stopped")
 				return
 			case <-ctx.Done():
@@ -65,23 +121,95 @@ func (s *Scheduler) Start(ctx context.Context) error {
 			}
 		}
 	}()
-
-	return nil
 }
 
-func (s *Scheduler) Stop() {
-	if s.ticker != nil {
-		s.ticker.Stop()
+// stop ends the periodic sync; a sync already running finishes. s.mu must be held.
+func (s *Scheduler) stop() {
+	if s.ticker == nil {
+		return
 	}
+	s.ticker.Stop()
 	close(s.stopCh)
+	s.ticker = nil
+	s.stopCh = nil
+}
+
+// Reconfigure applies reloaded scheduler settings: the scheduler is started or stopped when it
+// was enabled or disabled, and a changed sync interval takes effect from the next tick
+func (s *Scheduler) Reconfigure(cfg *config.SchedulerConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.config
+	s.config = cfg
+	s.setupFreeze(cfg)
+	if s.ctx == nil {
+		// Not started yet, Start picks up the new settings
+		return
+	}
+
+	switch {
+	case !cfg.Enabled && s.ticker != nil:
+		s.stop()
+		s.logger.Info("Scheduler disabled by configuration reload")
+	case cfg.Enabled && s.ticker == nil:
+		s.start()
+	case cfg.Enabled && cfg.SyncInterval != previous.SyncInterval:
+		s.ticker.Reset(cfg.SyncInterval)
+		s.logger.Info("Scheduler sync interval changed",
+			zap.String("from", previous.SyncInterval.String()),
+			zap.String("to", cfg.SyncInterval.String()))
+	}
+}
+
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stop()
 	s.logger.Info("Scheduler shutdown completed")
 }
 
-func (s *Scheduler) runSync() error {
+func (s *Scheduler) runSync() (err error) {
 	start := time.Now()
 
-	// First sync pages from Notion
-	err := s.notionService.SyncPages()
+	// A panic must not stop the scheduler, the next tick simply tries again
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			s.logger.Error("Recovered from panic during sync",
+				zap.Any("panic", recovered),
+				zap.String("stack", string(debug.Stack())))
+			err = fmt.Errorf("sync panicked: %v", recovered)
+		}
+	}()
+
+	// Maintenance mode skips the whole cycle, a freeze only publishing and unpublishing
+	schedule := s.FreezeSchedule()
+	if schedule.Maintenance {
+		s.logger.Info("Skipping sync in maintenance mode")
+		return nil
+	}
+
+	// With several replicas, the one holding the lock syncs and publishes for all of them
+	unlockSync, err := s.publisherService.Locker().acquire(context.Background(), LockSync, false)
+	if errors.Is(err, ErrLockHeld) {
+		s.logger.Info("Skipping sync, another replica is syncing")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer unlockSync()
+
+	// Sync the other sources first so a Notion outage doesn't hold them back
+	if s.sourceSyncer != nil && s.sourceSyncer.HasSources() {
+		if err := s.sourceSyncer.SyncAll(context.Background()); err != nil {
+			s.logger.Error("Source sync failed", zap.Error(err))
+		}
+	}
+
+	// Then sync pages from Notion
+	report, err := s.notionService.SyncPages()
 	if err != nil {
 		syncDuration := time.Since(start)
 		s.logger.Error("Notion sync failed",
@@ -92,25 +220,49 @@ func (s *Scheduler) runSync() error {
 
 	syncDuration := time.Since(start)
 	s.logger.Info("Notion sync completed successfully",
-		zap.Duration("sync_duration", syncDuration))
+		zap.Duration("sync_duration", syncDuration),
+		zap.Int("pages_failed", report.Failed))
+
+	if freeze := schedule.Status(time.Now()); freeze.Frozen {
+		s.logger.Info("Publishing is frozen, skipping scheduled publishing",
+			zap.String("reason", freeze.Reason))
+		return nil
+	}
 
-	// Then process pending pages for publishing
+	// Then queue pending pages for the publish workers, unless a replica is already queueing them
 	publishStart := time.Now()
+	unlockPublish, err := s.publisherService.Locker().acquire(context.Background(), LockPublish, false)
+	if err != nil {
+		s.logger.Info("Skipping scheduled publishing", zap.Error(err))
+		return nil
+	}
+	defer unlockPublish()
+
 	if s.publisherService != nil {
-		err = s.publisherService.ProcessPendingPages(context.Background())
+		s.publisherService.EnrichChangedPages(context.Background())
+
+		queued, err := s.publisherService.EnqueuePendingPages(context.Background(), s.queue)
 		publishDuration := time.Since(publishStart)
 
 		if err != nil {
-			s.logger.Error("Publishing pending pages failed",
+			s.logger.Error("Queueing pending pages failed",
 				zap.Error(err),
 				zap.Duration("publish_duration", publishDuration))
 			// Don't return error here - sync was successful, just publishing failed
 		} else {
-			s.logger.Info("Publishing pending pages completed successfully",
+			s.logger.Info("Queueing pending pages completed successfully",
+				zap.Int("queued", queued),
 				zap.Duration("publish_duration", publishDuration))
 		}
 	}
 
+	// Finally unpublish pages whose unpublish date has passed
+	if s.publisherService != nil {
+		if err := s.publisherService.ProcessExpiredPages(context.Background()); err != nil {
+			s.logger.Error("Processing expired pages failed", zap.Error(err))
+		}
+	}
+
 	totalDuration := time.Since(start)
 	s.logger.Info("Full sync and publish cycle completed",
 		zap.Duration("total_duration", totalDuration))
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+// SearchQuery filters a full-text page search
+type SearchQuery struct {
+	Query    string
+	Status   string
+	Platform string
+	// From and To bound the post date, or the creation date of pages without one
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+	Offset int
+	// Columns are the page columns loaded, all when empty
+	Columns []string
+}
+
+// SearchResult is a matching page with its relevance and a highlighted excerpt
+type SearchResult struct {
+	models.NotionPage
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// SearchService searches pages by title, tags and content
+type SearchService struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewSearchService(db *gorm.DB, logger *zap.Logger) *SearchService {
+	return &SearchService{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Search returns the matching pages ordered by relevance, and the total number of matches.
+// Pages are matched against the full-text index; since the simple text search configuration
+// does not segment Chinese, titles and text containing the query as a substring match too.
+func (s *SearchService) Search(query SearchQuery) ([]SearchResult, int64, error) {
+	terms := strings.TrimSpace(query.Query)
+	like := "%" + escapeLike(terms) + "%"
+
+	applyFilters := func(q *gorm.DB) *gorm.DB {
+		if terms != "" {
+			q = q.Where("(search_vector @@ websearch_to_tsquery('simple', ?) OR title ILIKE ? OR en_title ILIKE ? OR search_text ILIKE ?)",
+				terms, like, like, like)
+		}
+		if query.Status != "" {
+			q = q.Where("LOWER(status) = LOWER(?)", query.Status)
+		}
+		if query.Platform != "" {
+			q = q.Where("EXISTS (SELECT 1 FROM unnest(platforms) AS platform WHERE LOWER(platform) = LOWER(?))", query.Platform)
+		}
+		if query.From != nil {
+			q = q.Where("COALESCE(post_date, created_at) >= ?", *query.From)
+		}
+		if query.To != nil {
+			q = q.Where("COALESCE(post_date, created_at) <= ?", *query.To)
+		}
+		return q
+	}
+
+	var total int64
+	if err := applyFilters(s.db.Model(&models.NotionPage{})).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	columns := "notion_pages.*"
+	if len(query.Columns) > 0 {
+		columns = "notion_pages." + strings.Join(query.Columns, ", notion_pages.")
+	}
+
+	q := applyFilters(s.db.Model(&models.NotionPage{})).Select(columns)
+	if terms != "" {
+		q = q.Select(columns+", ts_rank(search_vector, websearch_to_tsquery('simple', ?)) AS rank, "+
+			"ts_headline('simple', COALESCE(search_text, ''), websearch_to_tsquery('simple', ?), 'MaxFragments=2, MaxWords=20, MinWords=5') AS snippet",
+			terms, terms).
+			Order("rank desc")
+	}
+
+	var results []SearchResult
+	err := q.Order("updated_at desc").
+		Limit(query.Limit).
+		Offset(query.Offset).
+		Find(&results).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return results, total, nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+
+	"github.com/ifuryst/ripple/internal/content"
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// seriesPart is a part of a series as listed on one platform
+type seriesPart struct {
+	Title   string `yaml:"title"`
+	URL     string `yaml:"url,omitempty"`
+	Current bool   `yaml:"current,omitempty"`
+}
+
+// seriesFrontMatter is the series field of al-folio posts, for the layout to render
+type seriesFrontMatter struct {
+	Name     string       `yaml:"name"`
+	Part     int          `yaml:"part"`
+	Total    int          `yaml:"total"`
+	Posts    []seriesPart `yaml:"posts"`
+	Previous *seriesPart  `yaml:"previous,omitempty"`
+	Next     *seriesPart  `yaml:"next,omitempty"`
+}
+
+// seriesHook links the parts of a series: al-folio posts get a series front matter field, the
+// other platforms previous/next links and an index of the parts appended to the body. Only
+// parts already published on the platform are listed, besides the part being published.
+type seriesHook struct {
+	db *gorm.DB
+}
+
+func (h *seriesHook) Name() string {
+	return "series"
+}
+
+func (h *seriesHook) Stages() []publisher.HookStage {
+	return []publisher.HookStage{publisher.HookPreTransform}
+}
+
+func (h *seriesHook) Run(ctx context.Context, event *publisher.HookEvent) (string, error) {
+	name := event.Content.Metadata["series"]
+	if name == "" {
+		return "", nil
+	}
+
+	// Missing links are not worth failing the publish for
+	parts, current, err := h.publishedParts(ctx, name, event.Content.ID, event.Platform)
+	if err != nil {
+		return fmt.Sprintf("no series links: %v", err), nil
+	}
+	if current < 0 || len(parts) < 2 {
+		return "", nil
+	}
+
+	if publisher.PlatformType(event.Platform) == "al-folio" {
+		if err := addSeriesFrontMatter(event.Content, name, parts, current); err != nil {
+			return fmt.Sprintf("no series front matter: %v", err), nil
+		}
+	} else {
+		doc, err := event.Content.ContentDocument()
+		if err != nil {
+			return "", nil
+		}
+		doc.Blocks = append(doc.Blocks, seriesBlocks(name, parts, current)...)
+		event.Content.Document = doc
+	}
+	return fmt.Sprintf("linked part %d of %d of series %s", current+1, len(parts), name), nil
+}
+
+// publishedParts returns the parts of a series published on a platform and the page being
+// published, in series order, with the index of that page
+func (h *seriesHook) publishedParts(ctx context.Context, name, notionID, platformName string) ([]seriesPart, int, error) {
+	var pages []models.NotionPage
+	if err := h.db.WithContext(ctx).
+		Select("id", "notion_id", "title").
+		Where("series = ? AND archived_at IS NULL", name).
+		Order("post_date ASC NULLS LAST").
+		Order("created_at ASC").
+		Find(&pages).Error; err != nil {
+		return nil, -1, fmt.Errorf("failed to get series parts: %w", err)
+	}
+
+	pageIDs := make([]uint, len(pages))
+	for i, page := range pages {
+		pageIDs[i] = page.ID
+	}
+	var jobs []models.DistributionJob
+	if err := h.db.WithContext(ctx).
+		Joins("JOIN platforms ON platforms.id = distribution_jobs.platform_id").
+		Where("platforms.name = ? AND distribution_jobs.status = ? AND distribution_jobs.page_id IN ?", platformName, models.JobCompleted, pageIDs).
+		Order("distribution_jobs.published_at ASC").
+		Find(&jobs).Error; err != nil {
+		return nil, -1, fmt.Errorf("failed to get published series parts: %w", err)
+	}
+	urls := make(map[uint]string, len(jobs))
+	for _, job := range jobs {
+		urls[job.PageID] = job.URL
+		if job.URL == "" {
+			urls[job.PageID] = job.Metadata["url"]
+		}
+	}
+
+	var parts []seriesPart
+	current := -1
+	for _, page := range pages {
+		url, published := urls[page.ID]
+		if page.NotionID == notionID {
+			current = len(parts)
+			parts = append(parts, seriesPart{Title: page.Title, URL: url, Current: true})
+		} else if published && url != "" {
+			parts = append(parts, seriesPart{Title: page.Title, URL: url})
+		}
+	}
+	return parts, current, nil
+}
+
+// addSeriesFrontMatter adds the series field to the page's own front matter, unless the page
+// sets one itself
+func addSeriesFrontMatter(c *publisher.PublishContent, name string, parts []seriesPart, current int) error {
+	fields := make(map[string]interface{})
+	if source := c.Metadata["front_matter"]; strings.TrimSpace(source) != "" {
+		if err := yaml.Unmarshal([]byte(source), &fields); err != nil {
+			return err
+		}
+	}
+	if _, ok := fields["series"]; ok {
+		return nil
+	}
+
+	series := seriesFrontMatter{
+		Name:  name,
+		Part:  current + 1,
+		Total: len(parts),
+		Posts: parts,
+	}
+	if current > 0 {
+		series.Previous = &parts[current-1]
+	}
+	if current < len(parts)-1 {
+		series.Next = &parts[current+1]
+	}
+	fields["series"] = series
+
+	data, err := yaml.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	c.Metadata["front_matter"] = string(data)
+	return nil
+}
+
+// seriesBlocks renders the series index and the previous/next links appended to a post
+func seriesBlocks(name string, parts []seriesPart, current int) []content.Block {
+	index := &content.List{Ordered: true}
+	for _, part := range parts {
+		span := content.Span{Text: part.Title, Link: part.URL}
+		if part.Current {
+			span = content.Span{Text: part.Title, Bold: true}
+		}
+		index.Items = append(index.Items, content.ListItem{Text: []content.Span{span}})
+	}
+
+	blocks := []content.Block{
+		{Type: content.BlockDivider},
+		{Type: content.BlockParagraph, Text: []content.Span{{Text: name, Bold: true}}},
+		{Type: content.BlockList, List: index},
+	}
+
+	var links []content.Span
+	if current > 0 {
+		previous := parts[current-1]
+		links = append(links, content.Span{Text: "← " + previous.Title, Link: previous.URL})
+	}
+	if current < len(parts)-1 {
+		if len(links) > 0 {
+			links = append(links, content.Span{Text: " | "})
+		}
+		next := parts[current+1]
+		links = append(links, content.Span{Text: next.Title + " →", Link: next.URL})
+	}
+	return append(blocks, content.Block{Type: content.BlockParagraph, Text: links})
+}
+
+// refreshSeries republishes the earlier parts of a series on a platform after a new part was
+// first published there, so their index and next link include it. Republishing them doesn't
+// refresh the series again, since they were published before.
+func (s *PublisherService) refreshSeries(ctx context.Context, page *models.NotionPage, platformName string) {
+	series := s.config.Publisher.Series
+	if !series.Enabled || page.Series == "" || !containsPlatform(series.RefreshPlatforms, platformName) {
+		return
+	}
+
+	var publishes int64
+	if err := s.db.WithContext(ctx).Model(&models.DistributionJob{}).
+		Joins("JOIN platforms ON platforms.id = distribution_jobs.platform_id").
+		Where("distribution_jobs.page_id = ? AND platforms.name = ? AND distribution_jobs.published_at IS NOT NULL", page.ID, platformName).
+		Count(&publishes).Error; err != nil || publishes != 1 {
+		return
+	}
+
+	var jobIDs []uint
+	if err := s.db.WithContext(ctx).Model(&models.DistributionJob{}).
+		Joins("JOIN platforms ON platforms.id = distribution_jobs.platform_id").
+		Joins("JOIN notion_pages ON notion_pages.id = distribution_jobs.page_id").
+		Where("notion_pages.series = ? AND notion_pages.id <> ? AND notion_pages.archived_at IS NULL", page.Series, page.ID).
+		Where("platforms.name = ? AND distribution_jobs.status = ?", platformName, models.JobCompleted).
+		Pluck("distribution_jobs.id", &jobIDs).Error; err != nil {
+		s.logger.Error("Failed to get series parts to refresh",
+			zap.String("series", page.Series),
+			zap.String("platform", platformName),
+			zap.Error(err))
+		return
+	}
+
+	s.logger.Info("Refreshing series after new part",
+		zap.String("series", page.Series),
+		zap.String("page_id", page.NotionID),
+		zap.String("platform", platformName),
+		zap.Int("parts", len(jobIDs)))
+	for _, jobID := range jobIDs {
+		if _, err := s.RepublishJob(ctx, jobID, true); err != nil {
+			s.logger.Error("Failed to refresh series part",
+				zap.String("series", page.Series),
+				zap.Uint("job_id", jobID),
+				zap.Error(err))
+		}
+	}
+}
+
+// containsPlatform reports whether a comma separated list names a platform or its type
+func containsPlatform(list, platformName string) bool {
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" && (name == platformName || name == publisher.PlatformType(platformName)) {
+			return true
+		}
+	}
+	return false
+}
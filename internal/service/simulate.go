@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ifuryst/ripple/internal/content"
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/notion"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+)
+
+// PageSimulation is how ready a page is to publish on every enabled platform
+type PageSimulation struct {
+	PageID string `json:"page_id"`
+	Title  string `json:"title"`
+	// Ready tells whether the page is ready on all the platforms it is routed to
+	Ready     bool                    `json:"ready"`
+	Coverage  *content.Coverage       `json:"coverage,omitempty"`
+	Platforms []*publisher.Simulation `json:"platforms"`
+}
+
+// SimulatePage runs the transformation and validation of a page for every enabled platform
+// without publishing it, see publisher.Manager.Simulate
+func (s *PublisherService) SimulatePage(ctx context.Context, pageID string) (*PageSimulation, error) {
+	var page models.NotionPage
+	if err := s.db.WithContext(ctx).Where("notion_id = ?", notion.NormalizePageID(pageID)).First(&page).Error; err != nil {
+		return nil, fmt.Errorf("page not found: %w", err)
+	}
+
+	simulation := &PageSimulation{
+		PageID: page.NotionID,
+		Title:  page.Title,
+		Ready:  true,
+	}
+	if page.Content != "" {
+		if coverage, err := content.NotionCoverageJSON(page.Content); err == nil {
+			simulation.Coverage = coverage
+		}
+	}
+
+	simulation.Platforms = s.manager.Simulate(ctx, &page, simulation.Coverage)
+	for _, platform := range simulation.Platforms {
+		if platform.Routed && !platform.Ready {
+			simulation.Ready = false
+		}
+	}
+	return simulation, nil
+}
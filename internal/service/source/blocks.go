@@ -0,0 +1,210 @@
+package source
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	headingPattern      = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	bulletPattern       = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	numberedPattern     = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	todoPattern         = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+	imageLinePattern    = regexp.MustCompile(`^!\[([^\]]*)\]\((?:<([^>]+)>|([^)\s]+))(?:\s+"[^"]*")?\)$`)
+	dividerPattern      = regexp.MustCompile(`^(?:-{3,}|\*{3,}|_{3,})$`)
+	inlineTokenPattern  = regexp.MustCompile("`[^`]+`|\\*\\*[^*]+\\*\\*|__[^_]+__|~~[^~]+~~|\\*[^*\\s][^*]*\\*|_[^_\\s][^_]*_|!?\\[[^\\]]*\\]\\([^)\\s]+\\)")
+	inlineLinkPattern   = regexp.MustCompile(`^!?\[([^\]]*)\]\(([^)\s]+)\)$`)
+	codeFencePattern    = regexp.MustCompile("^(```|~~~)\\s*([\\w+#-]*)")
+	blockquotePrefix    = regexp.MustCompile(`^\s*>\s?`)
+	notionCodeLanguages = map[string]string{
+		"":       "plain text",
+		"js":     "javascript",
+		"ts":     "typescript",
+		"py":     "python",
+		"sh":     "shell",
+		"bash":   "bash",
+		"yml":    "yaml",
+		"golang": "go",
+	}
+)
+
+// markdownToBlocks converts markdown into Notion API style blocks so the publishers' Notion
+// converters can render it; resolveImage maps image references to downloadable URLs
+func markdownToBlocks(markdown string, resolveImage func(string) string) []map[string]any {
+	var blocks []map[string]any
+	var paragraph []string
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, textBlock("paragraph", strings.Join(paragraph, " ")))
+			paragraph = nil
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		// Fenced code blocks keep their content verbatim
+		if match := codeFencePattern.FindStringSubmatch(trimmed); match != nil {
+			flushParagraph()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), match[1]); i++ {
+				code = append(code, lines[i])
+			}
+			blocks = append(blocks, codeBlock(strings.Join(code, "\n"), match[2]))
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			flushParagraph()
+		case dividerPattern.MatchString(trimmed):
+			flushParagraph()
+			blocks = append(blocks, newBlock("divider", map[string]any{}))
+		case headingPattern.MatchString(trimmed):
+			flushParagraph()
+			match := headingPattern.FindStringSubmatch(trimmed)
+			// Notion only has three heading levels
+			level := len(match[1])
+			if level > 3 {
+				level = 3
+			}
+			blocks = append(blocks, textBlock("heading_"+string(rune('0'+level)), match[2]))
+		case imageLinePattern.MatchString(trimmed):
+			flushParagraph()
+			// The source is either <path with spaces> or a plain path
+			match := imageLinePattern.FindStringSubmatch(trimmed)
+			blocks = append(blocks, imageBlock(resolveImage(match[2]+match[3]), match[1]))
+		case blockquotePrefix.MatchString(line):
+			flushParagraph()
+			var quote []string
+			for ; i < len(lines) && blockquotePrefix.MatchString(lines[i]); i++ {
+				quote = append(quote, strings.TrimSpace(blockquotePrefix.ReplaceAllString(lines[i], "")))
+			}
+			i--
+			blocks = append(blocks, textBlock("quote", strings.Join(quote, "\n")))
+		case bulletPattern.MatchString(line):
+			flushParagraph()
+			item := bulletPattern.FindStringSubmatch(line)[1]
+			if match := todoPattern.FindStringSubmatch(item); match != nil {
+				block := textBlock("to_do", match[2])
+				block["to_do"].(map[string]any)["checked"] = match[1] != " "
+				blocks = append(blocks, block)
+			} else {
+				blocks = append(blocks, textBlock("bulleted_list_item", item))
+			}
+		case numberedPattern.MatchString(line):
+			flushParagraph()
+			blocks = append(blocks, textBlock("numbered_list_item", numberedPattern.FindStringSubmatch(line)[1]))
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flushParagraph()
+
+	return blocks
+}
+
+func newBlock(blockType string, content map[string]any) map[string]any {
+	return map[string]any{
+		"object":       "block",
+		"type":         blockType,
+		"has_children": false,
+		blockType:      content,
+	}
+}
+
+func textBlock(blockType, text string) map[string]any {
+	return newBlock(blockType, map[string]any{
+		"rich_text": parseInline(text),
+		"color":     "default",
+	})
+}
+
+func codeBlock(code, language string) map[string]any {
+	language = strings.ToLower(language)
+	if mapped, ok := notionCodeLanguages[language]; ok {
+		language = mapped
+	}
+	return newBlock("code", map[string]any{
+		"rich_text": []map[string]any{richText(code, "", annotations{})},
+		"language":  language,
+		"caption":   []map[string]any{},
+	})
+}
+
+func imageBlock(url, caption string) map[string]any {
+	captionText := []map[string]any{}
+	if caption != "" {
+		captionText = append(captionText, richText(caption, "", annotations{}))
+	}
+	return newBlock("image", map[string]any{
+		"type":     "external",
+		"external": map[string]any{"url": url},
+		"caption":  captionText,
+	})
+}
+
+type annotations struct {
+	bold, italic, strikethrough, underline, code bool
+}
+
+func richText(text, link string, a annotations) map[string]any {
+	var linkValue, href any
+	if link != "" {
+		linkValue = map[string]any{"url": link}
+		href = link
+	}
+	return map[string]any{
+		"type": "text",
+		"text": map[string]any{
+			"content": text,
+			"link":    linkValue,
+		},
+		"annotations": map[string]any{
+			"bold":          a.bold,
+			"italic":        a.italic,
+			"strikethrough": a.strikethrough,
+			"underline":     a.underline,
+			"code":          a.code,
+			"color":         "default",
+		},
+		"plain_text": text,
+		"href":       href,
+	}
+}
+
+// parseInline splits text into Notion rich text runs for code, bold, italic, strikethrough and links
+func parseInline(text string) []map[string]any {
+	runs := []map[string]any{}
+	last := 0
+	for _, loc := range inlineTokenPattern.FindAllStringIndex(text, -1) {
+		if loc[0] > last {
+			runs = append(runs, richText(text[last:loc[0]], "", annotations{}))
+		}
+		runs = append(runs, inlineRun(text[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	if last < len(text) {
+		runs = append(runs, richText(text[last:], "", annotations{}))
+	}
+	return runs
+}
+
+func inlineRun(token string) map[string]any {
+	switch {
+	case strings.HasPrefix(token, "`"):
+		return richText(strings.Trim(token, "`"), "", annotations{code: true})
+	case strings.HasPrefix(token, "**"), strings.HasPrefix(token, "__"):
+		return richText(token[2:len(token)-2], "", annotations{bold: true})
+	case strings.HasPrefix(token, "~~"):
+		return richText(token[2:len(token)-2], "", annotations{strikethrough: true})
+	case strings.HasPrefix(token, "[") || strings.HasPrefix(token, "!["):
+		match := inlineLinkPattern.FindStringSubmatch(token)
+		return richText(match[1], match[2], annotations{})
+	default:
+		return richText(token[1:len(token)-1], "", annotations{italic: true})
+	}
+}
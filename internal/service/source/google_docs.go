@@ -0,0 +1,574 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/config"
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/pkg/httpclient"
+)
+
+const (
+	driveAPIURL  = "https://www.googleapis.com/drive/v3"
+	docsAPIURL   = "https://docs.googleapis.com/v1"
+	sheetsAPIURL = "https://sheets.googleapis.com/v4"
+
+	googleDocMimeType = "application/vnd.google-apps.document"
+)
+
+var (
+	googleScopes = []string{
+		"https://www.googleapis.com/auth/drive.readonly",
+		"https://www.googleapis.com/auth/documents.readonly",
+		"https://www.googleapis.com/auth/spreadsheets.readonly",
+	}
+	// orderedGlyphTypes are the list glyphs rendered as numbered lists; everything else is a bullet
+	orderedGlyphTypes = map[string]bool{
+		"DECIMAL": true, "ZERO_DECIMAL": true, "ALPHA": true, "UPPER_ALPHA": true, "ROMAN": true, "UPPER_ROMAN": true,
+	}
+	monospaceFonts = map[string]bool{
+		"courier new": true, "consolas": true, "roboto mono": true, "source code pro": true,
+		"inconsolata": true, "ubuntu mono": true, "fira code": true, "jetbrains mono": true,
+	}
+	// sheetKeyColumns are the header names accepted for the column naming the document in the properties sheet
+	sheetKeyColumns = []string{"document", "doc", "doc_id", "id", "name", "file"}
+)
+
+type (
+	driveFile struct {
+		ID           string `json:"id"`
+		Name         string `json:"name"`
+		ModifiedTime string `json:"modifiedTime"`
+	}
+
+	googleDocument struct {
+		DocumentID string `json:"documentId"`
+		Title      string `json:"title"`
+		Body       struct {
+			Content []docElement `json:"content"`
+		} `json:"body"`
+		Lists map[string]struct {
+			ListProperties struct {
+				NestingLevels []struct {
+					GlyphType string `json:"glyphType"`
+				} `json:"nestingLevels"`
+			} `json:"listProperties"`
+		} `json:"lists"`
+		InlineObjects map[string]struct {
+			InlineObjectProperties struct {
+				EmbeddedObject struct {
+					Description     string `json:"description"`
+					ImageProperties struct {
+						ContentURI string `json:"contentUri"`
+					} `json:"imageProperties"`
+				} `json:"embeddedObject"`
+			} `json:"inlineObjectProperties"`
+		} `json:"inlineObjects"`
+	}
+
+	docElement struct {
+		Paragraph *docParagraph `json:"paragraph"`
+		Table     *docTable     `json:"table"`
+	}
+
+	docParagraph struct {
+		Elements       []docParagraphElement `json:"elements"`
+		ParagraphStyle struct {
+			NamedStyleType string `json:"namedStyleType"`
+		} `json:"paragraphStyle"`
+		Bullet *struct {
+			ListID       string `json:"listId"`
+			NestingLevel int    `json:"nestingLevel"`
+		} `json:"bullet"`
+	}
+
+	docParagraphElement struct {
+		TextRun *struct {
+			Content   string       `json:"content"`
+			TextStyle docTextStyle `json:"textStyle"`
+		} `json:"textRun"`
+		InlineObjectElement *struct {
+			InlineObjectID string `json:"inlineObjectId"`
+		} `json:"inlineObjectElement"`
+		HorizontalRule *struct{} `json:"horizontalRule"`
+	}
+
+	docTextStyle struct {
+		Bold          bool `json:"bold"`
+		Italic        bool `json:"italic"`
+		Underline     bool `json:"underline"`
+		Strikethrough bool `json:"strikethrough"`
+		Link          *struct {
+			URL string `json:"url"`
+		} `json:"link"`
+		WeightedFontFamily *struct {
+			FontFamily string `json:"fontFamily"`
+		} `json:"weightedFontFamily"`
+	}
+
+	docTable struct {
+		TableRows []struct {
+			TableCells []struct {
+				Content []docElement `json:"content"`
+			} `json:"tableCells"`
+		} `json:"tableRows"`
+	}
+)
+
+// GoogleDocsSource reads the Google Docs in a Drive folder. Properties come from a companion
+// spreadsheet with a row per document, or from a two-column table at the top of the document.
+type GoogleDocsSource struct {
+	config           config.GoogleDocsSourceConfig
+	defaultStatus    string
+	defaultPlatforms []string
+	tokens           *googleTokenSource
+	tokenErr         error
+	client           *http.Client
+	logger           *zap.Logger
+}
+
+// NewGoogleDocsSource creates a source for the configured Drive folder. Credential errors are
+// reported when the source is synced so a bad key doesn't stop the server from starting.
+func NewGoogleDocsSource(cfg config.GoogleDocsSourceConfig, logger *zap.Logger) *GoogleDocsSource {
+	defaultStatus := cfg.DefaultStatus
+	if defaultStatus == "" {
+		defaultStatus = "Draft"
+	}
+	if cfg.PropertiesSheetRange == "" {
+		cfg.PropertiesSheetRange = "A:Z"
+	}
+
+	client := httpclient.New(httpclient.WithTimeout(30 * time.Second))
+	tokens, err := newGoogleTokenSource(cfg.CredentialsFile, client, googleScopes...)
+
+	return &GoogleDocsSource{
+		config:           cfg,
+		defaultStatus:    defaultStatus,
+		defaultPlatforms: stringList(cfg.DefaultPlatforms),
+		tokens:           tokens,
+		tokenErr:         err,
+		client:           client,
+		logger:           logger,
+	}
+}
+
+// Name returns the source name stored on synced pages
+func (s *GoogleDocsSource) Name() string {
+	return models.SourceGoogleDocs
+}
+
+// Documents reads every Google Doc in the folder
+func (s *GoogleDocsSource) Documents(ctx context.Context) ([]Document, error) {
+	if s.tokenErr != nil {
+		return nil, fmt.Errorf("invalid Google credentials: %w", s.tokenErr)
+	}
+
+	files, err := s.listFolder(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sheetProperties, sheetModified, err := s.sheetProperties(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []Document
+	for _, file := range files {
+		doc, err := s.readDocument(ctx, file, sheetProperties, sheetModified)
+		if err != nil {
+			s.logger.Warn("Skipping Google Doc",
+				zap.String("document_id", file.ID),
+				zap.String("name", file.Name),
+				zap.Error(err))
+			continue
+		}
+		documents = append(documents, *doc)
+	}
+
+	return documents, nil
+}
+
+func (s *GoogleDocsSource) listFolder(ctx context.Context) ([]driveFile, error) {
+	var files []driveFile
+	pageToken := ""
+
+	for {
+		query := url.Values{
+			"q":                         {fmt.Sprintf("'%s' in parents and mimeType = '%s' and trashed = false", s.config.FolderID, googleDocMimeType)},
+			"fields":                    {"nextPageToken, files(id, name, modifiedTime)"},
+			"pageSize":                  {"100"},
+			"supportsAllDrives":         {"true"},
+			"includeItemsFromAllDrives": {"true"},
+		}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+
+		var result struct {
+			Files         []driveFile `json:"files"`
+			NextPageToken string      `json:"nextPageToken"`
+		}
+		if err := s.getJSON(ctx, driveAPIURL+"/files?"+query.Encode(), &result); err != nil {
+			return nil, fmt.Errorf("failed to list Drive folder: %w", err)
+		}
+
+		files = append(files, result.Files...)
+		if result.NextPageToken == "" {
+			return files, nil
+		}
+		pageToken = result.NextPageToken
+	}
+}
+
+// sheetProperties reads the properties spreadsheet into rows keyed by lowercased document ID
+// and name, along with the time the sheet was last changed
+func (s *GoogleDocsSource) sheetProperties(ctx context.Context) (map[string]map[string]any, time.Time, error) {
+	rows := make(map[string]map[string]any)
+	if s.config.PropertiesSheetID == "" {
+		return rows, time.Time{}, nil
+	}
+
+	var sheet driveFile
+	if err := s.getJSON(ctx, driveAPIURL+"/files/"+url.PathEscape(s.config.PropertiesSheetID)+"?fields=modifiedTime&supportsAllDrives=true", &sheet); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to read properties sheet: %w", err)
+	}
+	modified, _ := time.Parse(time.RFC3339, sheet.ModifiedTime)
+
+	var values struct {
+		Values [][]string `json:"values"`
+	}
+	valuesURL := fmt.Sprintf("%s/spreadsheets/%s/values/%s", sheetsAPIURL,
+		url.PathEscape(s.config.PropertiesSheetID), url.PathEscape(s.config.PropertiesSheetRange))
+	if err := s.getJSON(ctx, valuesURL, &values); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to read properties sheet: %w", err)
+	}
+	if len(values.Values) == 0 {
+		return rows, modified, nil
+	}
+
+	header := make([]string, len(values.Values[0]))
+	keyColumn := -1
+	for i, name := range values.Values[0] {
+		header[i] = propertyKey(name)
+		for _, candidate := range sheetKeyColumns {
+			if header[i] == candidate && keyColumn < 0 {
+				keyColumn = i
+			}
+		}
+	}
+	if keyColumn < 0 {
+		return nil, time.Time{}, fmt.Errorf("properties sheet needs a column named one of %s", strings.Join(sheetKeyColumns, ", "))
+	}
+
+	for _, row := range values.Values[1:] {
+		if keyColumn >= len(row) || strings.TrimSpace(row[keyColumn]) == "" {
+			continue
+		}
+		properties := make(map[string]any)
+		for i, value := range row {
+			if i != keyColumn && i < len(header) && header[i] != "" && strings.TrimSpace(value) != "" {
+				properties[header[i]] = propertyValue(value)
+			}
+		}
+		rows[strings.ToLower(strings.TrimSpace(row[keyColumn]))] = properties
+	}
+
+	return rows, modified, nil
+}
+
+func (s *GoogleDocsSource) readDocument(ctx context.Context, file driveFile, sheetProperties map[string]map[string]any, sheetModified time.Time) (*Document, error) {
+	var gdoc googleDocument
+	if err := s.getJSON(ctx, docsAPIURL+"/documents/"+url.PathEscape(file.ID), &gdoc); err != nil {
+		return nil, fmt.Errorf("failed to read document: %w", err)
+	}
+
+	elements := gdoc.Body.Content
+	properties, ok := sheetProperties[strings.ToLower(file.ID)]
+	if !ok {
+		properties, ok = sheetProperties[strings.ToLower(file.Name)]
+	}
+	if !ok {
+		// Without a sheet row, a leading key/value table holds the properties
+		properties, elements = headerTableProperties(elements)
+	}
+
+	converter := &googleDocConverter{doc: &gdoc}
+	blocks := converter.convert(elements)
+	content, err := json.Marshal(blocks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal blocks: %w", err)
+	}
+
+	title := converter.title
+	if title == "" {
+		title = file.Name
+	}
+
+	// Edits to the sheet row count as changes to the document
+	lastModified, _ := time.Parse(time.RFC3339, file.ModifiedTime)
+	if sheetModified.After(lastModified) {
+		lastModified = sheetModified
+	}
+
+	doc := &Document{
+		ID:           "gdoc-" + file.ID,
+		Title:        title,
+		Content:      string(content),
+		LastModified: lastModified.UTC(),
+	}
+	doc.applyProperties(properties, s.defaultStatus, s.defaultPlatforms)
+	doc.Properties["google_doc_id"] = file.ID
+
+	return doc, nil
+}
+
+func (s *GoogleDocsSource) getJSON(ctx context.Context, requestURL string, out any) error {
+	token, err := s.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return json.Unmarshal(body, out)
+}
+
+// headerTableProperties reads a table at the top of the document with a key and a value per
+// row, and returns the remaining elements without it
+func headerTableProperties(elements []docElement) (map[string]any, []docElement) {
+	properties := make(map[string]any)
+
+	for i, element := range elements {
+		if element.Paragraph != nil && strings.TrimSpace(paragraphText(element.Paragraph)) == "" {
+			continue
+		}
+		if element.Table == nil {
+			return properties, elements
+		}
+
+		for _, row := range element.Table.TableRows {
+			if len(row.TableCells) != 2 {
+				return map[string]any{}, elements
+			}
+			key := propertyKey(cellText(row.TableCells[0].Content))
+			if value := cellText(row.TableCells[1].Content); key != "" && value != "" {
+				properties[key] = propertyValue(value)
+			}
+		}
+		return properties, elements[i+1:]
+	}
+
+	return properties, elements
+}
+
+// propertyKey normalizes a column or row label like "Post Date" to the front matter key post_date
+func propertyKey(label string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(label)), " ", "_")
+}
+
+// propertyValue keeps values as text except booleans, so draft: true works like in front matter
+func propertyValue(value string) any {
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(value) {
+	case "true", "yes":
+		return true
+	case "false", "no":
+		return false
+	}
+	return value
+}
+
+func paragraphText(paragraph *docParagraph) string {
+	var text strings.Builder
+	for _, element := range paragraph.Elements {
+		if element.TextRun != nil {
+			text.WriteString(element.TextRun.Content)
+		}
+	}
+	return text.String()
+}
+
+func cellText(content []docElement) string {
+	var lines []string
+	for _, element := range content {
+		if element.Paragraph != nil {
+			if line := strings.TrimSpace(paragraphText(element.Paragraph)); line != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return strings.Join(lines, ", ")
+}
+
+// googleDocConverter turns Docs API structural elements into Notion API style blocks
+type googleDocConverter struct {
+	doc    *googleDocument
+	title  string
+	blocks []map[string]any
+	code   []string
+}
+
+func (c *googleDocConverter) convert(elements []docElement) []map[string]any {
+	for _, element := range elements {
+		switch {
+		case element.Paragraph != nil:
+			c.paragraph(element.Paragraph)
+		case element.Table != nil:
+			c.flushCode()
+			c.table(element.Table)
+		}
+	}
+	c.flushCode()
+	return c.blocks
+}
+
+func (c *googleDocConverter) paragraph(paragraph *docParagraph) {
+	style := paragraph.ParagraphStyle.NamedStyleType
+
+	// The document title is kept out of the body, like a leading heading in markdown files
+	if style == "TITLE" && c.title == "" && len(c.blocks) == 0 {
+		c.title = strings.TrimSpace(paragraphText(paragraph))
+		return
+	}
+
+	// Consecutive monospace paragraphs form a code block
+	if style == "NORMAL_TEXT" && paragraph.Bullet == nil && isMonospace(paragraph) {
+		c.code = append(c.code, strings.TrimRight(paragraphText(paragraph), "\n"))
+		return
+	}
+	c.flushCode()
+
+	blockType := "paragraph"
+	switch style {
+	case "TITLE", "HEADING_1":
+		blockType = "heading_1"
+	case "HEADING_2":
+		blockType = "heading_2"
+	case "HEADING_3", "HEADING_4", "HEADING_5", "HEADING_6":
+		blockType = "heading_3"
+	}
+	if paragraph.Bullet != nil {
+		blockType = "bulleted_list_item"
+		if c.isOrdered(paragraph.Bullet.ListID, paragraph.Bullet.NestingLevel) {
+			blockType = "numbered_list_item"
+		}
+	}
+
+	var runs []map[string]any
+	flushRuns := func() {
+		if len(runs) > 0 {
+			c.blocks = append(c.blocks, newBlock(blockType, map[string]any{"rich_text": runs, "color": "default"}))
+			runs = nil
+		}
+	}
+
+	for _, element := range paragraph.Elements {
+		switch {
+		case element.TextRun != nil:
+			text := strings.TrimRight(element.TextRun.Content, "\n")
+			if text == "" {
+				continue
+			}
+			style := element.TextRun.TextStyle
+			link := ""
+			if style.Link != nil {
+				link = style.Link.URL
+			}
+			runs = append(runs, richText(text, link, annotations{
+				bold:          style.Bold,
+				italic:        style.Italic,
+				strikethrough: style.Strikethrough,
+				underline:     style.Underline && link == "",
+				code:          isMonospaceStyle(style),
+			}))
+		case element.InlineObjectElement != nil:
+			// Images become blocks of their own between the text around them
+			flushRuns()
+			object, ok := c.doc.InlineObjects[element.InlineObjectElement.InlineObjectID]
+			embedded := object.InlineObjectProperties.EmbeddedObject
+			if ok && embedded.ImageProperties.ContentURI != "" {
+				c.blocks = append(c.blocks, imageBlock(embedded.ImageProperties.ContentURI, embedded.Description))
+			}
+		case element.HorizontalRule != nil:
+			flushRuns()
+			c.blocks = append(c.blocks, newBlock("divider", map[string]any{}))
+		}
+	}
+	flushRuns()
+}
+
+// table renders each row as a paragraph of cells separated by " | ", since the publishers
+// don't support tables
+func (c *googleDocConverter) table(table *docTable) {
+	for _, row := range table.TableRows {
+		cells := make([]string, 0, len(row.TableCells))
+		for _, cell := range row.TableCells {
+			cells = append(cells, cellText(cell.Content))
+		}
+		c.blocks = append(c.blocks, textBlock("paragraph", strings.Join(cells, " | ")))
+	}
+}
+
+func (c *googleDocConverter) flushCode() {
+	if len(c.code) > 0 {
+		c.blocks = append(c.blocks, codeBlock(strings.Join(c.code, "\n"), ""))
+		c.code = nil
+	}
+}
+
+func (c *googleDocConverter) isOrdered(listID string, level int) bool {
+	list, ok := c.doc.Lists[listID]
+	if !ok || level >= len(list.ListProperties.NestingLevels) {
+		return false
+	}
+	return orderedGlyphTypes[list.ListProperties.NestingLevels[level].GlyphType]
+}
+
+func isMonospace(paragraph *docParagraph) bool {
+	hasText := false
+	for _, element := range paragraph.Elements {
+		if element.TextRun == nil {
+			return false
+		}
+		if strings.TrimSpace(element.TextRun.Content) == "" {
+			continue
+		}
+		if !isMonospaceStyle(element.TextRun.TextStyle) {
+			return false
+		}
+		hasText = true
+	}
+	return hasText
+}
+
+func isMonospaceStyle(style docTextStyle) bool {
+	return style.WeightedFontFamily != nil && monospaceFonts[strings.ToLower(style.WeightedFontFamily.FontFamily)]
+}
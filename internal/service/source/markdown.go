@@ -0,0 +1,316 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ifuryst/ripple/internal/config"
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+var (
+	markdownExtensions = map[string]bool{".md": true, ".markdown": true}
+	// frontMatterDelimiter opens and closes the YAML front matter block
+	frontMatterDelimiter = []byte("---")
+	// frontMatterDateLayouts are the date formats accepted for date fields written as strings
+	frontMatterDateLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+)
+
+var registerFileTransport sync.Once
+
+// MarkdownSource reads markdown files with YAML front matter from a local directory, such as
+// a checked out git repository
+type MarkdownSource struct {
+	name             string
+	dir              string
+	walkDir          string
+	idPrefix         string
+	defaultStatus    string
+	defaultPlatforms []string
+	imageBaseURL     string
+	logger           *zap.Logger
+
+	// rewriteBody and rewriteFrontMatter let flavored sources adapt their syntax before the
+	// markdown is converted
+	rewriteBody        func(body string) string
+	rewriteFrontMatter func(frontMatter map[string]any)
+}
+
+// NewMarkdownSource creates a source for the configured directory
+func NewMarkdownSource(cfg config.MarkdownSourceConfig, logger *zap.Logger) *MarkdownSource {
+	defaultStatus := cfg.DefaultStatus
+	if defaultStatus == "" {
+		defaultStatus = "Draft"
+	}
+
+	if cfg.ImageBaseURL == "" {
+		// Local images are referenced as file:// URLs, which the publishers download with the default transport
+		registerFileTransport.Do(func() {
+			if transport, ok := http.DefaultTransport.(*http.Transport); ok {
+				transport.RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
+			}
+		})
+	}
+
+	return &MarkdownSource{
+		name:             models.SourceMarkdown,
+		dir:              cfg.Dir,
+		walkDir:          cfg.Dir,
+		idPrefix:         "md-",
+		defaultStatus:    defaultStatus,
+		defaultPlatforms: stringList(cfg.DefaultPlatforms),
+		imageBaseURL:     strings.TrimSuffix(cfg.ImageBaseURL, "/"),
+		logger:           logger,
+	}
+}
+
+// Name returns the source name stored on synced pages
+func (s *MarkdownSource) Name() string {
+	return s.name
+}
+
+// Documents reads every markdown file under the directory, skipping hidden directories
+func (s *MarkdownSource) Documents(ctx context.Context) ([]Document, error) {
+	var documents []Document
+
+	err := filepath.WalkDir(s.walkDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		name := entry.Name()
+		if entry.IsDir() {
+			if filePath != s.walkDir && (strings.HasPrefix(name, ".") || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !markdownExtensions[strings.ToLower(filepath.Ext(name))] {
+			return nil
+		}
+
+		doc, err := s.readDocument(filePath)
+		if err != nil {
+			s.logger.Warn("Skipping markdown file", zap.String("path", filePath), zap.Error(err))
+			return nil
+		}
+		documents = append(documents, *doc)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read markdown directory %s: %w", s.walkDir, err)
+	}
+
+	return documents, nil
+}
+
+func (s *MarkdownSource) readDocument(filePath string) (*Document, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	relativePath, err := filepath.Rel(s.dir, filePath)
+	if err != nil {
+		return nil, err
+	}
+	relativePath = filepath.ToSlash(relativePath)
+
+	frontMatter, body, err := splitFrontMatter(data)
+	if err != nil {
+		return nil, err
+	}
+	if s.rewriteFrontMatter != nil {
+		s.rewriteFrontMatter(frontMatter)
+	}
+	if s.rewriteBody != nil {
+		body = s.rewriteBody(body)
+	}
+
+	title := stringValue(frontMatter["title"])
+	if title == "" {
+		// Use a leading "# Title" as the title and keep it out of the body
+		if heading, rest, ok := leadingHeading(body); ok {
+			title, body = heading, rest
+		} else {
+			title = strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+		}
+	}
+
+	resolveImage := func(src string) string {
+		return s.resolveImage(path.Dir(relativePath), src)
+	}
+
+	blocks := markdownToBlocks(body, resolveImage)
+	content, err := json.Marshal(blocks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal blocks: %w", err)
+	}
+
+	doc := &Document{
+		ID:           s.idPrefix + documentID(relativePath),
+		Title:        title,
+		Content:      string(content),
+		LastModified: info.ModTime().UTC(),
+	}
+	doc.applyProperties(frontMatter, s.defaultStatus, s.defaultPlatforms)
+	if doc.CoverURL != "" {
+		doc.CoverURL = resolveImage(doc.CoverURL)
+	}
+	doc.Properties["path"] = relativePath
+
+	return doc, nil
+}
+
+// resolveImage turns an image path relative to the document (or to the source root when it
+// starts with "/") into a URL the publishers can download
+func (s *MarkdownSource) resolveImage(documentDir, src string) string {
+	if parsed, err := url.Parse(src); err == nil && parsed.Scheme != "" {
+		return src
+	}
+
+	relativePath := path.Clean(path.Join(documentDir, src))
+	if strings.HasPrefix(src, "/") {
+		relativePath = path.Clean(strings.TrimPrefix(src, "/"))
+	}
+
+	if s.imageBaseURL != "" {
+		return s.imageBaseURL + "/" + (&url.URL{Path: relativePath}).EscapedPath()
+	}
+
+	absolutePath, err := filepath.Abs(filepath.Join(s.dir, filepath.FromSlash(relativePath)))
+	if err != nil {
+		return src
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(absolutePath)}).String()
+}
+
+// documentID derives a stable page ID from the file path; sources prefix it to keep it apart
+// from Notion IDs
+func documentID(relativePath string) string {
+	sum := sha256.Sum256([]byte(relativePath))
+	return hex.EncodeToString(sum[:16])
+}
+
+// splitFrontMatter separates a leading "---" delimited YAML block from the markdown body
+func splitFrontMatter(data []byte) (map[string]any, string, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	frontMatter := map[string]any{}
+
+	if !bytes.HasPrefix(data, frontMatterDelimiter) {
+		return frontMatter, string(data), nil
+	}
+
+	rest := data[len(frontMatterDelimiter):]
+	newline := bytes.IndexByte(rest, '\n')
+	if newline < 0 || len(bytes.TrimSpace(rest[:newline])) != 0 {
+		return frontMatter, string(data), nil
+	}
+	// Keep the newline before the first line so an empty block is found as well
+	rest = rest[newline:]
+
+	end := bytes.Index(rest, append([]byte("\n"), frontMatterDelimiter...))
+	if end < 0 {
+		return nil, "", fmt.Errorf("front matter is not closed")
+	}
+
+	if err := yaml.Unmarshal(rest[:end], &frontMatter); err != nil {
+		return nil, "", fmt.Errorf("invalid front matter: %w", err)
+	}
+
+	body := rest[end+1+len(frontMatterDelimiter):]
+	if newline := bytes.IndexByte(body, '\n'); newline >= 0 {
+		body = body[newline+1:]
+	} else {
+		body = nil
+	}
+	return frontMatter, string(body), nil
+}
+
+// leadingHeading returns the text of a level 1 heading on the first non-empty line
+func leadingHeading(body string) (string, string, bool) {
+	trimmed := strings.TrimLeft(body, " \t\r\n")
+	line, rest, _ := strings.Cut(trimmed, "\n")
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "# ") {
+		return "", body, false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(line, "# ")), rest, true
+}
+
+func firstValue(values map[string]any, keys ...string) any {
+	for _, key := range keys {
+		if value, ok := values[key]; ok && value != nil {
+			return value
+		}
+	}
+	return nil
+}
+
+func firstString(values map[string]any, keys ...string) string {
+	return stringValue(firstValue(values, keys...))
+}
+
+func firstTime(values map[string]any, keys ...string) *time.Time {
+	switch value := firstValue(values, keys...).(type) {
+	case time.Time:
+		return &value
+	case string:
+		for _, layout := range frontMatterDateLayouts {
+			if parsed, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+				return &parsed
+			}
+		}
+	}
+	return nil
+}
+
+func stringValue(value any) string {
+	if value == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprint(value))
+}
+
+// stringList accepts a YAML list or a comma-separated string
+func stringList(value any) []string {
+	var items []string
+	switch value := value.(type) {
+	case []any:
+		for _, item := range value {
+			if s := stringValue(item); s != "" {
+				items = append(items, s)
+			}
+		}
+	case string:
+		for _, item := range strings.Split(value, ",") {
+			if s := strings.TrimSpace(item); s != "" {
+				items = append(items, s)
+			}
+		}
+	}
+	return items
+}
@@ -0,0 +1,214 @@
+package source
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/config"
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+var (
+	// embedPattern matches ![[target]] and ![[target|alt or size]]
+	embedPattern = regexp.MustCompile(`!\[\[([^\]|]+)(?:\|([^\]]*))?\]\]`)
+	// wikilinkPattern matches [[target]], [[target#heading]] and [[target|alias]]
+	wikilinkPattern  = regexp.MustCompile(`\[\[([^\]|]+)(?:\|([^\]]*))?\]\]`)
+	commentPattern   = regexp.MustCompile(`(?s)%%.*?%%`)
+	highlightPattern = regexp.MustCompile(`==([^=\n]+)==`)
+	calloutPattern   = regexp.MustCompile(`^(\s*>\s*)\[!\w+\][+-]?\s*(.*)$`)
+	imageSizePattern = regexp.MustCompile(`^\d+(x\d+)?$`)
+	imageExtensions  = map[string]bool{
+		".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".svg": true, ".bmp": true, ".avif": true,
+	}
+)
+
+// ObsidianSource reads notes from an Obsidian vault. It builds on MarkdownSource and rewrites
+// the Obsidian syntax (wikilinks, embeds, comments, highlights and callouts) into plain markdown.
+type ObsidianSource struct {
+	*MarkdownSource
+	// attachments maps lowercased file names to their vault relative path, the way Obsidian
+	// resolves embeds that only name the file
+	attachments map[string]string
+}
+
+// NewObsidianSource creates a source for the configured vault
+func NewObsidianSource(cfg config.ObsidianSourceConfig, logger *zap.Logger) *ObsidianSource {
+	markdown := NewMarkdownSource(config.MarkdownSourceConfig{
+		Dir:              cfg.VaultDir,
+		DefaultStatus:    cfg.DefaultStatus,
+		DefaultPlatforms: cfg.DefaultPlatforms,
+		ImageBaseURL:     cfg.ImageBaseURL,
+	}, logger)
+	markdown.name = models.SourceObsidian
+	markdown.idPrefix = "obsidian-"
+	if cfg.Folder != "" {
+		markdown.walkDir = filepath.Join(cfg.VaultDir, cfg.Folder)
+	}
+
+	s := &ObsidianSource{MarkdownSource: markdown}
+	markdown.rewriteBody = s.rewriteBody
+	markdown.rewriteFrontMatter = s.rewriteFrontMatter
+	return s
+}
+
+// Documents indexes the vault's attachments and reads the notes
+func (s *ObsidianSource) Documents(ctx context.Context) ([]Document, error) {
+	s.attachments = make(map[string]string)
+
+	err := filepath.WalkDir(s.dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			// .obsidian and .trash hold settings and deleted files
+			if filePath != s.dir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(s.dir, filePath)
+		if err != nil {
+			return err
+		}
+		relativePath = filepath.ToSlash(relativePath)
+
+		// Prefer the attachment closest to the vault root when names collide
+		key := strings.ToLower(entry.Name())
+		if existing, ok := s.attachments[key]; !ok || strings.Count(relativePath, "/") < strings.Count(existing, "/") {
+			s.attachments[key] = relativePath
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Warn("Failed to index vault attachments", zap.String("vault", s.dir), zap.Error(err))
+	}
+
+	return s.MarkdownSource.Documents(ctx)
+}
+
+// rewriteBody converts Obsidian syntax outside of code blocks into plain markdown
+func (s *ObsidianSource) rewriteBody(body string) string {
+	body = commentPattern.ReplaceAllString(body, "")
+
+	lines := strings.Split(body, "\n")
+	inCode := false
+	for i, line := range lines {
+		if match := codeFencePattern.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+
+		if match := calloutPattern.FindStringSubmatch(line); match != nil {
+			line = match[1]
+			if match[2] != "" {
+				line += "**" + match[2] + "**"
+			}
+		}
+
+		line = embedPattern.ReplaceAllStringFunc(line, s.rewriteEmbed)
+		line = wikilinkPattern.ReplaceAllStringFunc(line, func(link string) string {
+			match := wikilinkPattern.FindStringSubmatch(link)
+			return wikilinkText(match[1], match[2])
+		})
+		lines[i] = highlightPattern.ReplaceAllString(line, "**$1**")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// rewriteEmbed turns an image embed into a markdown image on its own line; embedded notes are
+// replaced by their name since their content isn't part of this document
+func (s *ObsidianSource) rewriteEmbed(embed string) string {
+	match := embedPattern.FindStringSubmatch(embed)
+	target, alt := strings.TrimSpace(match[1]), strings.TrimSpace(match[2])
+
+	if !imageExtensions[strings.ToLower(path.Ext(target))] {
+		return wikilinkText(target, alt)
+	}
+
+	// The part after | is either a size like 300 or 300x200, or alt text
+	if imageSizePattern.MatchString(alt) {
+		alt = ""
+	}
+
+	return "\n![" + alt + "](</" + s.attachmentPath(target) + ">)\n"
+}
+
+// attachmentPath resolves an embed target to a vault relative path; targets with a folder are
+// already relative to the vault root
+func (s *ObsidianSource) attachmentPath(target string) string {
+	if resolved, ok := s.attachments[strings.ToLower(path.Base(target))]; ok && !strings.Contains(target, "/") {
+		return resolved
+	}
+	return strings.TrimPrefix(target, "/")
+}
+
+// wikilinkText renders a wikilink as Obsidian displays it: the alias, or the note name with
+// " > " before a heading
+func wikilinkText(target, alias string) string {
+	if alias = strings.TrimSpace(alias); alias != "" {
+		return alias
+	}
+	note, heading, _ := strings.Cut(strings.TrimSpace(target), "#")
+	note = strings.TrimSuffix(path.Base(note), ".md")
+	if heading == "" {
+		return note
+	}
+	heading = strings.TrimPrefix(heading, "^")
+	if note == "" || note == "." {
+		return heading
+	}
+	return note + " > " + heading
+}
+
+// rewriteFrontMatter normalizes the property spellings Obsidian allows: singular keys, tags
+// with a leading #, space separated lists and covers given as wikilinks
+func (s *ObsidianSource) rewriteFrontMatter(frontMatter map[string]any) {
+	for _, pair := range [][2]string{{"tag", "tags"}, {"platform", "platforms"}} {
+		if value, ok := frontMatter[pair[0]]; ok {
+			if _, exists := frontMatter[pair[1]]; !exists {
+				frontMatter[pair[1]] = value
+			}
+		}
+	}
+
+	for _, key := range []string{"tags", "platforms"} {
+		value, ok := frontMatter[key]
+		if !ok {
+			continue
+		}
+
+		var items []any
+		for _, item := range stringList(value) {
+			for _, field := range strings.Fields(item) {
+				if field = strings.TrimPrefix(field, "#"); field != "" {
+					items = append(items, field)
+				}
+			}
+		}
+		frontMatter[key] = items
+	}
+
+	for _, key := range []string{"cover", "image"} {
+		if value, ok := frontMatter[key].(string); ok {
+			if match := wikilinkPattern.FindStringSubmatch(value); match != nil {
+				frontMatter[key] = "/" + s.attachmentPath(strings.TrimSpace(match[1]))
+			}
+		}
+	}
+
+	// Notes excluded from Obsidian Publish stay drafts here as well
+	if publish, ok := frontMatter["publish"].(bool); ok && !publish {
+		frontMatter["draft"] = true
+	}
+}
@@ -0,0 +1,253 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/ifuryst/ripple/internal/config"
+	"github.com/ifuryst/ripple/internal/content"
+	"github.com/ifuryst/ripple/internal/events"
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+// Document is a piece of content read from a source. Content holds Notion-style blocks JSON
+// so documents go through the same publishers as pages synced from Notion.
+type Document struct {
+	ID           string
+	Title        string
+	ENTitle      string
+	Content      string
+	Summary      string
+	Tags         []string
+	Status       string
+	PostDate     *time.Time
+	UnpublishAt  *time.Time
+	Owner        string
+	Platforms    []string
+	ContentType  []string
+	Series       string
+	Hold         bool
+	CoverURL     string
+	Properties   map[string]any
+	LastModified time.Time
+}
+
+// applyProperties fills the document from front matter style properties, falling back to the
+// source defaults for status and platforms. The title is only replaced when one is given.
+func (d *Document) applyProperties(properties map[string]any, defaultStatus string, defaultPlatforms []string) {
+	if title := firstString(properties, "title"); title != "" {
+		d.Title = title
+	}
+	d.ENTitle = firstString(properties, "en_title")
+	d.Summary = firstString(properties, "summary", "description")
+	d.Tags = stringList(properties["tags"])
+	d.PostDate = firstTime(properties, "post_date", "date")
+	d.UnpublishAt = firstTime(properties, "unpublish_at", "unpublish_date")
+	d.Owner = firstString(properties, "author", "owner")
+	d.ContentType = stringList(firstValue(properties, "content_type", "type"))
+	d.CoverURL = firstString(properties, "cover", "image")
+	d.Series = firstString(properties, "series")
+	d.Hold, _ = properties["hold"].(bool)
+
+	d.Status = firstString(properties, "status")
+	if d.Status == "" {
+		d.Status = defaultStatus
+	}
+	if draft, ok := properties["draft"].(bool); ok && draft {
+		d.Status = "Draft"
+	}
+
+	d.Platforms = stringList(properties["platforms"])
+	if len(d.Platforms) == 0 {
+		d.Platforms = defaultPlatforms
+	}
+
+	d.Properties = make(map[string]any, len(properties))
+	for key, value := range properties {
+		d.Properties[key] = value
+	}
+}
+
+// Source provides documents from somewhere other than the Notion database
+type Source interface {
+	// Name identifies the source and is stored on every page it syncs
+	Name() string
+	// Documents reads every document currently in the source
+	Documents(ctx context.Context) ([]Document, error)
+}
+
+// Syncer stores documents from the configured sources as pages ready for publishing
+type Syncer struct {
+	db      *gorm.DB
+	logger  *zap.Logger
+	sources []Source
+}
+
+// NewSyncer creates a syncer for the given sources
+func NewSyncer(db *gorm.DB, logger *zap.Logger, sources ...Source) *Syncer {
+	return &Syncer{
+		db:      db,
+		logger:  logger,
+		sources: sources,
+	}
+}
+
+// NewSyncerFromConfig creates a syncer for the sources enabled in the config
+func NewSyncerFromConfig(cfg *config.SourcesConfig, db *gorm.DB, logger *zap.Logger) *Syncer {
+	var sources []Source
+	if cfg.Markdown.Enabled {
+		sources = append(sources, NewMarkdownSource(cfg.Markdown, logger))
+	}
+	if cfg.Obsidian.Enabled {
+		sources = append(sources, NewObsidianSource(cfg.Obsidian, logger))
+	}
+	if cfg.GoogleDocs.Enabled {
+		sources = append(sources, NewGoogleDocsSource(cfg.GoogleDocs, logger))
+	}
+	return NewSyncer(db, logger, sources...)
+}
+
+// HasSources reports whether any source is configured
+func (s *Syncer) HasSources() bool {
+	return len(s.sources) > 0
+}
+
+// SyncAll syncs every source, continuing with the others when one fails
+func (s *Syncer) SyncAll(ctx context.Context) error {
+	var errs []error
+	for _, src := range s.sources {
+		if err := s.Sync(ctx, src); err != nil {
+			s.logger.Error("Source sync failed", zap.String("source", src.Name()), zap.Error(err))
+			errs = append(errs, fmt.Errorf("%s: %w", src.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Sync reads a source and creates or updates a page for every document that changed, storing
+// the run as sync history
+func (s *Syncer) Sync(ctx context.Context, src Source) error {
+	s.logger.Info("Starting source sync", zap.String("source", src.Name()))
+
+	run := models.NewSyncRun(src.Name())
+	if err := s.db.WithContext(ctx).Create(run).Error; err != nil {
+		s.logger.Warn("Failed to record sync run", zap.String("source", src.Name()), zap.Error(err))
+	}
+
+	err := s.syncDocuments(ctx, src, run)
+
+	run.Finish(err)
+	if saveErr := s.db.WithContext(ctx).Save(run).Error; saveErr != nil {
+		s.logger.Warn("Failed to record sync run", zap.String("source", src.Name()), zap.Error(saveErr))
+	}
+	events.Publish(events.SyncProgress, *run)
+	return err
+}
+
+func (s *Syncer) syncDocuments(ctx context.Context, src Source, run *models.SyncRun) error {
+	documents, err := src.Documents(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read documents: %w", err)
+	}
+
+	for _, doc := range documents {
+		outcome, err := s.store(ctx, src.Name(), doc)
+		if err != nil {
+			s.logger.Error("Failed to store document",
+				zap.String("source", src.Name()),
+				zap.String("document_id", doc.ID),
+				zap.Error(err))
+		}
+		run.Record(doc.ID, doc.Title, outcome, err)
+		events.Publish(events.SyncProgress, *run)
+	}
+
+	s.logger.Info("Source sync completed",
+		zap.String("source", src.Name()),
+		zap.Int("documents", len(documents)),
+		zap.Int("created", run.Created),
+		zap.Int("updated", run.Updated),
+		zap.Int("failed", run.Failed))
+	return nil
+}
+
+// store creates or updates the page of a document, returning whether it was created, updated
+// or skipped
+func (s *Syncer) store(ctx context.Context, sourceName string, doc Document) (string, error) {
+	propertiesJSON, err := json.Marshal(doc.Properties)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal properties: %w", err)
+	}
+
+	document, err := content.FromNotionJSON(doc.Content)
+	if err != nil {
+		return "", fmt.Errorf("failed to build document: %w", err)
+	}
+	encodedDocument, err := document.Encode()
+	if err != nil {
+		return "", err
+	}
+
+	var page models.NotionPage
+	result := s.db.WithContext(ctx).Where("notion_id = ?", doc.ID).First(&page)
+	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return "", fmt.Errorf("failed to query existing page: %w", result.Error)
+	}
+
+	exists := result.Error == nil
+	if exists && !page.LastModified.Before(doc.LastModified) {
+		return models.PageSkipped, nil
+	}
+
+	page.NotionID = doc.ID
+	page.Source = sourceName
+	page.Title = doc.Title
+	page.ENTitle = doc.ENTitle
+	page.Content = doc.Content
+	page.Document = encodedDocument
+	page.Summary = doc.Summary
+	page.Tags = doc.Tags
+	page.PostDate = doc.PostDate
+	keepUnpublished := exists && page.StaysUnpublished(doc.UnpublishAt)
+	page.UnpublishAt = doc.UnpublishAt
+	page.Owner = doc.Owner
+	page.Platforms = doc.Platforms
+	page.ContentType = doc.ContentType
+	page.Series = doc.Series
+	page.Hold = doc.Hold
+	page.CoverURL = doc.CoverURL
+	page.Properties = string(propertiesJSON)
+	page.LastModified = doc.LastModified
+
+	// Ripple marks pages Published and Unpublished itself; keep that unless the file asks for
+	// something else
+	if !keepUnpublished && (!exists || page.Status != "Published" || doc.Status != "Done") {
+		page.Status = doc.Status
+	}
+
+	if !exists {
+		if err := s.db.WithContext(ctx).Create(&page).Error; err != nil {
+			return "", fmt.Errorf("failed to create page: %w", err)
+		}
+		s.logger.Info("Created page from source",
+			zap.String("source", sourceName),
+			zap.String("page_id", doc.ID),
+			zap.String("title", doc.Title))
+		return models.PageCreated, nil
+	}
+
+	if err := s.db.WithContext(ctx).Save(&page).Error; err != nil {
+		return "", fmt.Errorf("failed to update page: %w", err)
+	}
+	s.logger.Info("Updated page from source",
+		zap.String("source", sourceName),
+		zap.String("page_id", doc.ID),
+		zap.String("title", doc.Title))
+	return models.PageUpdated, nil
+}
@@ -10,15 +10,19 @@ import (
 // StatsUpdater handles periodic statistics updates
 type StatsUpdater struct {
 	monitoringService *MonitoringService
+	locker            *Locker
 	logger            *zap.Logger
 	ticker            *time.Ticker
 	done              chan bool
+	// poolWaits is the wait count of the database pool at the last update
+	poolWaits int64
 }
 
 // NewStatsUpdater creates a new stats updater
-func NewStatsUpdater(monitoringService *MonitoringService, logger *zap.Logger, interval time.Duration) *StatsUpdater {
+func NewStatsUpdater(monitoringService *MonitoringService, locker *Locker, logger *zap.Logger, interval time.Duration) *StatsUpdater {
 	return &StatsUpdater{
 		monitoringService: monitoringService,
+		locker:            locker,
 		logger:            logger,
 		ticker:            time.NewTicker(interval),
 		done:              make(chan bool),
@@ -38,7 +42,7 @@ func (s *StatsUpdater) Start(ctx context.Context) {
 				s.logger.Info("Stats updater stopped due to context cancellation")
 				return
 			case <-s.ticker.C:
-				s.updateStats()
+				s.updateStats(ctx)
 			}
 		}
 	}()
@@ -51,7 +55,16 @@ func (s *StatsUpdater) Stop() {
 }
 
 // updateStats performs the actual stats update
-func (s *StatsUpdater) updateStats() {
+func (s *StatsUpdater) updateStats(ctx context.Context) {
+	// Every process has its own connection pool, so each records it, not only the lock holder
+	s.poolWaits = s.monitoringService.RecordDatabasePool(s.poolWaits)
+
+	unlock, ok := s.locker.lockRound(ctx, LockStats, s.logger)
+	if !ok {
+		return
+	}
+	defer unlock()
+
 	s.logger.Debug("Updating statistics")
 
 	// Update system stats
@@ -69,10 +82,5 @@ func (s *StatsUpdater) updateStats() {
 		s.logger.Error("Failed to update dashboard summary", zap.Error(err))
 	}
 
-	// Clean up old data (keep last 90 days)
-	if err := s.monitoringService.CleanupOldData(90); err != nil {
-		s.logger.Error("Failed to cleanup old data", zap.Error(err))
-	}
-
 	s.logger.Debug("Statistics updated successfully")
 }
\ No newline at end of file
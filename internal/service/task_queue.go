@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ifuryst/ripple/internal/config"
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/pkg/redis"
+)
+
+// Queue backends
+const (
+	QueueDatabase = "database"
+	QueueRedis    = "redis"
+)
+
+const (
+	// defaultVisibilityTimeout is used when visibility_timeout is not configured
+	defaultVisibilityTimeout = 30 * time.Minute
+	// queuePollInterval is how often the database queue is polled while it is empty
+	queuePollInterval = 2 * time.Second
+)
+
+// TaskQueue hands the publishes the scheduler queued to the publish workers, which may run in
+// other processes. A page is queued once per platform until a worker is done with it, and a
+// task a worker took but never finished can be taken again after the visibility timeout.
+type TaskQueue interface {
+	// Enqueue adds tasks in order, skipping those already queued, and returns how many were added
+	Enqueue(ctx context.Context, tasks []models.PublishTask) (int, error)
+	// Dequeue takes the next task, most urgent first, waiting up to wait for one; it returns
+	// nil when none came
+	Dequeue(ctx context.Context, wait time.Duration) (*models.PublishTask, error)
+	// Done removes a task a worker finished, so the page can be queued again
+	Done(ctx context.Context, task *models.PublishTask) error
+	Close() error
+}
+
+// NewTaskQueue creates the configured queue backend
+func NewTaskQueue(cfg config.QueueConfig, db *gorm.DB, logger *zap.Logger) (TaskQueue, error) {
+	visibility := cfg.VisibilityTimeout
+	if visibility <= 0 {
+		visibility = defaultVisibilityTimeout
+	}
+
+	switch strings.ToLower(cfg.Backend) {
+	case "", QueueDatabase:
+		return &dbTaskQueue{db: db, visibility: visibility}, nil
+	case QueueRedis:
+		client, err := redis.NewClient(cfg.RedisURL)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Using redis publish queue")
+		return &redisTaskQueue{client: client, visibility: visibility}, nil
+	default:
+		return nil, fmt.Errorf("unsupported queue backend %q, expected database or redis", cfg.Backend)
+	}
+}
+
+// dbTaskQueue keeps the queue in the publish_tasks table; workers take tasks with
+// SELECT ... FOR UPDATE SKIP LOCKED, so they never take the same one
+type dbTaskQueue struct {
+	db         *gorm.DB
+	visibility time.Duration
+}
+
+func (q *dbTaskQueue) Enqueue(ctx context.Context, tasks []models.PublishTask) (int, error) {
+	added := 0
+	for _, task := range tasks {
+		task.Rank = models.PriorityRank(task.Priority)
+		result := q.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&task)
+		if result.Error != nil {
+			return added, fmt.Errorf("failed to queue publish: %w", result.Error)
+		}
+		added += int(result.RowsAffected)
+	}
+	return added, nil
+}
+
+func (q *dbTaskQueue) Dequeue(ctx context.Context, wait time.Duration) (*models.PublishTask, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		task, err := q.claim(ctx)
+		if task != nil || err != nil {
+			return task, err
+		}
+
+		pause := min(queuePollInterval, time.Until(deadline))
+		if pause <= 0 {
+			return nil, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pause):
+		}
+	}
+}
+
+// claim takes the next waiting task, or one claimed longer than the visibility timeout ago
+func (q *dbTaskQueue) claim(ctx context.Context) (*models.PublishTask, error) {
+	var task models.PublishTask
+	err := q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("claimed_at IS NULL OR claimed_at < ?", time.Now().Add(-q.visibility)).
+			Order("rank ASC").
+			Order("id ASC").
+			First(&task).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		task.ClaimedAt = &now
+		return tx.Model(&task).Update("claimed_at", now).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to take queued publish: %w", err)
+	}
+	return &task, nil
+}
+
+func (q *dbTaskQueue) Done(ctx context.Context, task *models.PublishTask) error {
+	return q.db.WithContext(ctx).Delete(&models.PublishTask{}, task.ID).Error
+}
+
+func (q *dbTaskQueue) Close() error {
+	return nil
+}
+
+// redisTaskQueue keeps a list per priority. A marker key with the visibility timeout as expiry
+// keeps a page from being queued twice for a platform; the worker deletes it when done, and
+// it expires on its own when the worker died.
+type redisTaskQueue struct {
+	client     *redis.Client
+	visibility time.Duration
+}
+
+// redisQueueKeys are the lists of the priorities, most urgent first
+var redisQueueKeys = []string{
+	"ripple:queue:" + models.PriorityUrgent,
+	"ripple:queue:" + models.PriorityNormal,
+	"ripple:queue:" + models.PriorityBackfill,
+}
+
+func redisMarkerKey(task *models.PublishTask) string {
+	return fmt.Sprintf("ripple:queued:%d:%s", task.PageID, task.Platform)
+}
+
+func (q *redisTaskQueue) Enqueue(ctx context.Context, tasks []models.PublishTask) (int, error) {
+	added := 0
+	ttl := strconv.Itoa(int(q.visibility.Seconds()))
+	for _, task := range tasks {
+		task.Rank = models.PriorityRank(task.Priority)
+		task.CreatedAt = time.Now()
+		reply, err := q.client.Do(ctx, "SET", redisMarkerKey(&task), "1", "NX", "EX", ttl)
+		if err != nil {
+			return added, fmt.Errorf("failed to queue publish: %w", err)
+		}
+		if reply == nil {
+			// Already queued
+			continue
+		}
+
+		data, err := json.Marshal(task)
+		if err != nil {
+			return added, err
+		}
+		if _, err := q.client.Do(ctx, "LPUSH", redisQueueKeys[task.Rank], string(data)); err != nil {
+			q.client.Do(ctx, "DEL", redisMarkerKey(&task))
+			return added, fmt.Errorf("failed to queue publish: %w", err)
+		}
+		added++
+	}
+	return added, nil
+}
+
+func (q *redisTaskQueue) Dequeue(ctx context.Context, wait time.Duration) (*models.PublishTask, error) {
+	// BRPOP pops from the first non-empty list, so urgent publishes go first
+	timeout := max(int(wait.Seconds()), 1)
+	args := append([]string{"BRPOP"}, redisQueueKeys...)
+	reply, err := q.client.Do(ctx, append(args, strconv.Itoa(timeout))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take queued publish: %w", err)
+	}
+	popped, ok := reply.([]interface{})
+	if !ok || len(popped) != 2 {
+		return nil, nil
+	}
+	data, _ := popped[1].(string)
+
+	var task models.PublishTask
+	if err := json.Unmarshal([]byte(data), &task); err != nil {
+		return nil, fmt.Errorf("invalid queued publish %q: %w", data, err)
+	}
+	now := time.Now()
+	task.ClaimedAt = &now
+	return &task, nil
+}
+
+func (q *redisTaskQueue) Done(ctx context.Context, task *models.PublishTask) error {
+	_, err := q.client.Do(ctx, "DEL", redisMarkerKey(task))
+	return err
+}
+
+func (q *redisTaskQueue) Close() error {
+	return q.client.Close()
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+// Trend buckets
+const (
+	TrendBucketHour = "hour"
+	TrendBucketDay  = "day"
+	TrendBucketWeek = "week"
+)
+
+// Trend metrics
+const (
+	TrendSuccessRate = "success_rate"
+	TrendVolume      = "volume"
+	TrendAvgTime     = "avg_time"
+)
+
+// maxTrendBuckets bounds the number of buckets a single trend query returns
+const maxTrendBuckets = 1000
+
+// ErrInvalidTrendQuery is returned for an unknown bucket or metric or an invalid time range
+var ErrInvalidTrendQuery = errors.New("invalid trend query")
+
+// trendSteps is the length of each bucket, as a Postgres interval
+var trendSteps = map[string]struct {
+	interval string
+	duration time.Duration
+	// window is the default range ending now
+	window time.Duration
+}{
+	TrendBucketHour: {"1 hour", time.Hour, 24 * time.Hour},
+	TrendBucketDay:  {"1 day", 24 * time.Hour, 30 * 24 * time.Hour},
+	TrendBucketWeek: {"1 week", 7 * 24 * time.Hour, 12 * 7 * 24 * time.Hour},
+}
+
+// TrendQuery selects a trend of the distribution jobs
+type TrendQuery struct {
+	Bucket string
+	Metric string
+	// From and To default to a window ending now that depends on the bucket
+	From time.Time
+	To   time.Time
+	// Platform limits the trend to one platform
+	Platform string
+}
+
+// TrendPoint is the value of a metric in one bucket. Value is null for rates and averages of
+// buckets without finished jobs.
+type TrendPoint struct {
+	Bucket     time.Time `json:"bucket"`
+	Value      *float64  `json:"value"`
+	Total      int64     `json:"total"`
+	Successful int64     `json:"successful"`
+	Failed     int64     `json:"failed"`
+}
+
+// Trend is a metric over consecutive buckets, including the empty ones
+type Trend struct {
+	Bucket   string       `json:"bucket"`
+	Metric   string       `json:"metric"`
+	From     time.Time    `json:"from"`
+	To       time.Time    `json:"to"`
+	Platform string       `json:"platform,omitempty"`
+	Points   []TrendPoint `json:"points"`
+}
+
+// GetTrends aggregates the distribution jobs created between From and To into buckets. Buckets
+// come from generate_series so those without jobs are returned with zero counts.
+func (m *MonitoringService) GetTrends(query TrendQuery) (*Trend, error) {
+	step, ok := trendSteps[query.Bucket]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown bucket %q", ErrInvalidTrendQuery, query.Bucket)
+	}
+	switch query.Metric {
+	case TrendSuccessRate, TrendVolume, TrendAvgTime:
+	default:
+		return nil, fmt.Errorf("%w: unknown metric %q", ErrInvalidTrendQuery, query.Metric)
+	}
+
+	if query.To.IsZero() {
+		query.To = time.Now()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-step.window)
+	}
+	if !query.From.Before(query.To) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidTrendQuery)
+	}
+	if query.To.Sub(query.From)/step.duration >= maxTrendBuckets {
+		return nil, fmt.Errorf("%w: at most %d buckets can be requested", ErrInvalidTrendQuery, maxTrendBuckets)
+	}
+
+	var platformID uint
+	if query.Platform != "" {
+		var platform models.Platform
+		if err := m.db.Select("id").Where("name = ?", query.Platform).First(&platform).Error; err != nil {
+			return nil, fmt.Errorf("%w: unknown platform %q", ErrInvalidTrendQuery, query.Platform)
+		}
+		platformID = platform.ID
+	}
+
+	// The series covers every bucket starting before To. Processing time is the latest run of
+	// each completed job.
+	var rows []struct {
+		Bucket     time.Time
+		Total      int64
+		Successful int64
+		Failed     int64
+		AvgSeconds *float64
+	}
+	err := m.db.Raw(`SELECT b.bucket,
+			COUNT(j.id) AS total,
+			COUNT(j.id) FILTER (WHERE j.status = 'completed') AS successful,
+			COUNT(j.id) FILTER (WHERE j.status = 'failed') AS failed,
+			AVG(EXTRACT(EPOCH FROM j.finished_at - j.started_at)) FILTER (WHERE j.status = 'completed' AND j.started_at IS NOT NULL AND j.finished_at IS NOT NULL) AS avg_seconds
+		FROM generate_series(date_trunc(@bucket, CAST(@from AS timestamptz)), CAST(@to AS timestamptz) - interval '1 microsecond', CAST(@step AS interval)) AS b(bucket)
+		LEFT JOIN distribution_jobs j ON j.deleted_at IS NULL
+			AND j.created_at >= b.bucket AND j.created_at < b.bucket + CAST(@step AS interval)
+			AND j.created_at >= @from AND j.created_at < @to
+			AND (@platform = 0 OR j.platform_id = @platform)
+		GROUP BY b.bucket
+		ORDER BY b.bucket`,
+		sql.Named("bucket", query.Bucket),
+		sql.Named("from", query.From),
+		sql.Named("to", query.To),
+		sql.Named("step", step.interval),
+		sql.Named("platform", platformID)).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate trends: %w", err)
+	}
+
+	trend := &Trend{
+		Bucket:   query.Bucket,
+		Metric:   query.Metric,
+		From:     query.From,
+		To:       query.To,
+		Platform: query.Platform,
+		Points:   make([]TrendPoint, 0, len(rows)),
+	}
+	for _, row := range rows {
+		point := TrendPoint{
+			Bucket:     row.Bucket,
+			Total:      row.Total,
+			Successful: row.Successful,
+			Failed:     row.Failed,
+		}
+		switch query.Metric {
+		case TrendVolume:
+			volume := float64(row.Total)
+			point.Value = &volume
+		case TrendSuccessRate:
+			if finished := row.Successful + row.Failed; finished > 0 {
+				rate := float64(row.Successful) / float64(finished)
+				point.Value = &rate
+			}
+		case TrendAvgTime:
+			point.Value = row.AvgSeconds
+		}
+		trend.Points = append(trend.Points, point)
+	}
+	return trend, nil
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/internal/content"
+	"github.com/ifuryst/ripple/internal/models"
+	"github.com/ifuryst/ripple/internal/service/notion"
+	"github.com/ifuryst/ripple/internal/service/publisher"
+	"github.com/ifuryst/ripple/pkg/textdiff"
+)
+
+// diffContext is the number of unchanged lines shown around each change of a version diff
+const diffContext = 3
+
+// ErrVersionNotRepublishable is returned when republishing a version whose platform is gone
+var ErrVersionNotRepublishable = errors.New("version cannot be republished")
+
+// VersionRef names one side of a version diff, a stored version or the current page
+type VersionRef struct {
+	// ID is the stored version, 0 for the current page
+	ID       uint   `json:"id"`
+	Version  int    `json:"version,omitempty"`
+	Platform string `json:"platform,omitempty"`
+	JobID    uint   `json:"job_id,omitempty"`
+}
+
+// FieldChange is a metadata field that differs between two versions
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// TextDiff is the line diff of a text between two versions
+type TextDiff struct {
+	Added   int    `json:"added"`
+	Removed int    `json:"removed"`
+	Unified string `json:"unified"`
+}
+
+// VersionDiff is what changed between two content versions of a page
+type VersionDiff struct {
+	PageID  string        `json:"page_id"`
+	From    VersionRef    `json:"from"`
+	To      VersionRef    `json:"to"`
+	Changed bool          `json:"changed"`
+	Fields  []FieldChange `json:"fields"`
+	// Content diffs the plain text of the pages, Output what the transformers rendered; the
+	// current page has no output yet
+	Content *TextDiff `json:"content"`
+	Output  *TextDiff `json:"output,omitempty"`
+}
+
+// VersionRepublishReport describes republishing a stored version
+type VersionRepublishReport struct {
+	VersionID uint   `json:"version_id"`
+	Version   int    `json:"version"`
+	Platform  string `json:"platform"`
+	// Action is one of the rerun actions: republished or failed
+	Action string `json:"action"`
+	// Job is the latest job of the page and platform after the republish
+	Job    *models.DistributionJob  `json:"job"`
+	Result *publisher.PublishResult `json:"result,omitempty"`
+}
+
+// ListVersions returns the content versions of a page, newest first, optionally of one
+// platform only. The content and output are left out, they are read with GetVersion.
+func (s *PublisherService) ListVersions(ctx context.Context, pageID, platform string) ([]models.ContentVersion, error) {
+	page, err := s.versionPage(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.db.WithContext(ctx).
+		Omit("content", "document", "output", "properties").
+		Preload("Platform").
+		Where("page_id = ?", page.ID)
+	if platform != "" {
+		query = query.Where("platform_id IN (?)", s.db.Model(&models.Platform{}).Select("id").Where("name = ?", platform))
+	}
+
+	var versions []models.ContentVersion
+	if err := query.Order("created_at DESC").Order("id DESC").Find(&versions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get content versions: %w", err)
+	}
+	return versions, nil
+}
+
+// GetVersion returns a content version of a page with its content and output
+func (s *PublisherService) GetVersion(ctx context.Context, pageID string, versionID uint) (*models.ContentVersion, error) {
+	page, err := s.versionPage(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+	return s.findVersion(ctx, page, versionID)
+}
+
+// DiffVersions compares two content versions of a page. Without a to version, the from
+// version is compared with the page as it is now.
+func (s *PublisherService) DiffVersions(ctx context.Context, pageID string, fromID, toID uint) (*VersionDiff, error) {
+	page, err := s.versionPage(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := s.findVersion(ctx, page, fromID)
+	if err != nil {
+		return nil, err
+	}
+	to := currentVersion(page)
+	if toID != 0 {
+		if to, err = s.findVersion(ctx, page, toID); err != nil {
+			return nil, err
+		}
+	}
+
+	diff := &VersionDiff{
+		PageID:  page.NotionID,
+		From:    versionRef(from),
+		To:      versionRef(to),
+		Fields:  []FieldChange{},
+		Content: diffText(versionText(from), versionText(to), from, to),
+	}
+	for _, field := range []struct {
+		name     string
+		from, to string
+	}{
+		{"title", from.Title, to.Title},
+		{"en_title", from.ENTitle, to.ENTitle},
+		{"summary", from.Summary, to.Summary},
+		{"seo_description", from.SEODescription, to.SEODescription},
+		{"tags", strings.Join(from.Tags, ", "), strings.Join(to.Tags, ", ")},
+		{"owner", from.Owner, to.Owner},
+		{"post_date", formatDate(from), formatDate(to)},
+		{"cover_url", from.CoverURL, to.CoverURL},
+		{"series", from.Series, to.Series},
+	} {
+		if field.from != field.to {
+			diff.Fields = append(diff.Fields, FieldChange{Field: field.name, From: field.from, To: field.to})
+		}
+	}
+	if toID != 0 {
+		diff.Output = diffText(from.Output, to.Output, from, to)
+	}
+
+	diff.Changed = len(diff.Fields) > 0 || diff.Content.Added+diff.Content.Removed > 0 ||
+		(diff.Output != nil && diff.Output.Added+diff.Output.Removed > 0)
+	return diff, nil
+}
+
+// RepublishVersion publishes a stored version of a page again to the platform it was published
+// to, replacing what is there now. The page itself is left as it is, so the next change in
+// Notion is published over the restored version as usual.
+func (s *PublisherService) RepublishVersion(ctx context.Context, pageID string, versionID uint) (*VersionRepublishReport, error) {
+	page, err := s.versionPage(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+	if page.IsArchived() {
+		return nil, fmt.Errorf("%w: %s", ErrPageArchived, page.ArchiveReason)
+	}
+	version, err := s.findVersion(ctx, page, versionID)
+	if err != nil {
+		return nil, err
+	}
+	platformName := version.Platform.Name
+	if platformName == "" {
+		return nil, fmt.Errorf("%w: version %d has no associated platform", ErrVersionNotRepublishable, version.ID)
+	}
+
+	s.logger.Info("Republishing content version",
+		zap.String("page_id", page.NotionID),
+		zap.String("platform", platformName),
+		zap.Uint("version_id", version.ID),
+		zap.Int("version", version.Version))
+
+	// The jobs holding the page on the platform no longer count, so the publish below records
+	// a new one
+	var active []models.DistributionJob
+	if err := s.db.WithContext(ctx).
+		Where("page_id = ? AND platform_id = ? AND status IN ?", page.ID, version.PlatformID,
+			[]string{models.JobCompleted, models.JobQuarantined}).
+		Find(&active).Error; err != nil {
+		return nil, fmt.Errorf("failed to get jobs: %w", err)
+	}
+	for i := range active {
+		if err := publisher.TransitionJob(s.db, &active[i], models.JobRepublishRequested, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	// Like a republish, restoring a version is an explicit operator action a tripped circuit
+	// shouldn't block
+	s.manager.CircuitBreaker().Reset(platformName)
+
+	restored := version.Page(*page)
+	result, err := s.manager.PublishSinglePlatform(publisher.WithRestoredVersion(ctx, version.ID), &restored, platformName, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to republish version %d to %s: %w", version.Version, platformName, err)
+	}
+	s.recordPublishResult(ctx, page, platformName, result)
+
+	report := &VersionRepublishReport{
+		VersionID: version.ID,
+		Version:   version.Version,
+		Platform:  platformName,
+		Result:    result,
+	}
+	if result.Success {
+		report.Action = RerunActionRepublished
+		s.markPublishedIfCompleted(ctx, page)
+	} else {
+		report.Action = RerunActionFailed
+	}
+
+	var latest models.DistributionJob
+	if err := s.db.WithContext(ctx).Preload("Page").Preload("Platform").
+		Where("page_id = ? AND platform_id = ?", page.ID, version.PlatformID).
+		Order("created_at DESC").
+		First(&latest).Error; err != nil {
+		return nil, fmt.Errorf("failed to get republished job: %w", err)
+	}
+	report.Job = &latest
+
+	s.logger.Info("Content version republished",
+		zap.String("page_id", page.NotionID),
+		zap.String("platform", platformName),
+		zap.Uint("version_id", version.ID),
+		zap.Uint("job_id", latest.ID),
+		zap.String("action", report.Action))
+	return report, nil
+}
+
+func (s *PublisherService) versionPage(ctx context.Context, pageID string) (*models.NotionPage, error) {
+	var page models.NotionPage
+	if err := s.db.WithContext(ctx).Where("notion_id = ?", notion.NormalizePageID(pageID)).First(&page).Error; err != nil {
+		return nil, fmt.Errorf("page not found: %w", err)
+	}
+	return &page, nil
+}
+
+func (s *PublisherService) findVersion(ctx context.Context, page *models.NotionPage, versionID uint) (*models.ContentVersion, error) {
+	var version models.ContentVersion
+	if err := s.db.WithContext(ctx).Preload("Platform").
+		Where("page_id = ?", page.ID).
+		First(&version, versionID).Error; err != nil {
+		return nil, fmt.Errorf("content version not found: %w", err)
+	}
+	return &version, nil
+}
+
+// currentVersion is the page as it is now, as an unsaved version
+func currentVersion(page *models.NotionPage) *models.ContentVersion {
+	summary := page.Summary
+	if summary == "" {
+		summary = page.AISummary
+	}
+	return &models.ContentVersion{
+		PageID:         page.ID,
+		Title:          page.Title,
+		ENTitle:        page.ENTitle,
+		Summary:        summary,
+		SEODescription: page.SEODescription,
+		Tags:           page.Tags,
+		Owner:          page.Owner,
+		PostDate:       page.PostDate,
+		CoverURL:       page.CoverURL,
+		Series:         page.Series,
+		Content:        page.Content,
+		Document:       page.Document,
+	}
+}
+
+func versionRef(version *models.ContentVersion) VersionRef {
+	return VersionRef{
+		ID:       version.ID,
+		Version:  version.Version,
+		Platform: version.Platform.Name,
+		JobID:    version.JobID,
+	}
+}
+
+// versionText is the plain text of a version, one block per line; content that isn't Notion
+// blocks, like that of Markdown sources, is compared as it is
+func versionText(version *models.ContentVersion) string {
+	if version.Document != "" {
+		if doc, err := content.Parse(version.Document); err == nil {
+			return doc.Text()
+		}
+	}
+	if doc, err := content.FromNotionJSON(version.Content); err == nil {
+		return doc.Text()
+	}
+	return version.Content
+}
+
+func diffText(from, to string, fromVersion, toVersion *models.ContentVersion) *TextDiff {
+	diff := textdiff.Compare(from, to)
+	return &TextDiff{
+		Added:   diff.Added,
+		Removed: diff.Removed,
+		Unified: diff.Unified(versionName(fromVersion), versionName(toVersion), diffContext),
+	}
+}
+
+// versionName names a version in diff headers, e.g. "hugo v3"
+func versionName(version *models.ContentVersion) string {
+	if version.ID == 0 {
+		return "current"
+	}
+	return fmt.Sprintf("%s v%d", version.Platform.Name, version.Version)
+}
+
+func formatDate(version *models.ContentVersion) string {
+	if version.PostDate == nil {
+		return ""
+	}
+	return version.PostDate.Format("2006-01-02")
+}
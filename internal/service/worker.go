@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/ifuryst/ripple/internal/models"
+)
+
+const (
+	// dequeueWait is how long a worker waits for a queued publish before checking for shutdown
+	// and freezes again
+	dequeueWait = 5 * time.Second
+	// workerRetryDelay is how long a worker waits after the queue failed
+	workerRetryDelay = 10 * time.Second
+	// frozenPollInterval is how often a worker checks whether a freeze has ended
+	frozenPollInterval = 30 * time.Second
+)
+
+// PublishWorkers publish the pages the scheduler queued. They run in the server, or in worker
+// processes of their own so the servers only serve the API and queue publishes.
+type PublishWorkers struct {
+	publisherService *PublisherService
+	queue            TaskQueue
+	freeze           func(time.Time) FreezeStatus
+	logger           *zap.Logger
+	count            int
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewPublishWorkers creates count publish workers consuming queue; freeze tells them when
+// publishing is frozen. A count of 0 disables them.
+func NewPublishWorkers(publisherService *PublisherService, queue TaskQueue, freeze func(time.Time) FreezeStatus, logger *zap.Logger, count int) *PublishWorkers {
+	return &PublishWorkers{
+		publisherService: publisherService,
+		queue:            queue,
+		freeze:           freeze,
+		logger:           logger,
+		count:            count,
+	}
+}
+
+// Start starts the workers
+func (w *PublishWorkers) Start(ctx context.Context) {
+	if w.count <= 0 {
+		w.logger.Info("Publish workers are disabled, queued publishes are left to worker processes")
+		return
+	}
+
+	w.logger.Info("Starting publish workers", zap.Int("workers", w.count))
+	// Stopping ends the waits for the queue, a publish in progress finishes
+	waitCtx, cancel := context.WithCancel(ctx)
+	w.cancel = cancel
+	for i := 0; i < w.count; i++ {
+		w.wg.Add(1)
+		go func(worker int) {
+			defer w.wg.Done()
+			w.run(ctx, waitCtx, w.logger.With(zap.Int("worker", worker)))
+		}(i + 1)
+	}
+}
+
+// Stop stops the workers, waiting for the publishes in progress
+func (w *PublishWorkers) Stop() {
+	if w.cancel == nil {
+		return
+	}
+	w.cancel()
+	w.wg.Wait()
+	w.logger.Info("Publish workers stopped")
+}
+
+func (w *PublishWorkers) run(ctx, waitCtx context.Context, logger *zap.Logger) {
+	for waitCtx.Err() == nil {
+		if freeze := w.freeze(time.Now()); freeze.Frozen {
+			logger.Debug("Publishing is frozen, holding queued publishes", zap.String("reason", freeze.Reason))
+			sleepContext(waitCtx, frozenPollInterval)
+			continue
+		}
+
+		task, err := w.queue.Dequeue(waitCtx, dequeueWait)
+		if err != nil {
+			if waitCtx.Err() != nil {
+				return
+			}
+			logger.Error("Failed to take queued publish", zap.Error(err))
+			sleepContext(waitCtx, workerRetryDelay)
+			continue
+		}
+		if task == nil {
+			continue
+		}
+
+		if err := w.process(ctx, task); err != nil {
+			logger.Error("Failed to process queued publish",
+				zap.Uint("page_id", task.PageID),
+				zap.String("platform", task.Platform),
+				zap.Error(err))
+		}
+		// The page is queued again by the next sync if it still waits
+		if err := w.queue.Done(context.Background(), task); err != nil {
+			logger.Warn("Failed to remove processed publish from the queue",
+				zap.Uint("page_id", task.PageID),
+				zap.String("platform", task.Platform),
+				zap.Error(err))
+		}
+	}
+}
+
+// process publishes a task; a panic must not stop the worker
+func (w *PublishWorkers) process(ctx context.Context, task *models.PublishTask) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			w.logger.Error("Recovered from panic during queued publish",
+				zap.Any("panic", recovered),
+				zap.String("stack", string(debug.Stack())))
+			err = fmt.Errorf("publish panicked: %v", recovered)
+		}
+	}()
+	return w.publisherService.ProcessTask(ctx, task)
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
+
+// ProcessTask publishes a page taken from the publish queue, then marks it published once it
+// completed on all its platforms. Pages that stopped waiting for the platform since they were
+// queued, e.g. because they were published by hand or held, are skipped.
+func (s *PublisherService) ProcessTask(ctx context.Context, task *models.PublishTask) error {
+	page := &models.NotionPage{}
+	if err := s.db.WithContext(ctx).First(page, task.PageID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to load queued page: %w", err)
+	}
+
+	if page.Status != "Done" || page.IsArchived() || page.IsHeld() || page.UnpublishAt != nil && !page.UnpublishAt.After(time.Now()) {
+		return nil
+	}
+	status, err := s.latestJobStatuses(ctx, []models.NotionPage{*page})
+	if err != nil {
+		return err
+	}
+	route := s.manager.RoutePublishedPage(page, status[page.ID])
+	if !slices.Contains(route.Platforms, task.Platform) {
+		return nil
+	}
+	if !waitsToPublish(route, task.Platform, status[page.ID][task.Platform]) {
+		s.logger.Debug("Skipping queued publish, the page no longer waits",
+			zap.String("page_id", page.NotionID),
+			zap.String("platform", task.Platform))
+		return nil
+	}
+
+	s.publishQueued(ctx, page, task.Platform, task.Priority)
+	s.markPublishedIfCompleted(ctx, page)
+	return nil
+}
@@ -0,0 +1,198 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Login exchanges a TOTP code for a session token, which the client uses from then on
+func (c *Client) Login(ctx context.Context, code string) (string, error) {
+	var resp struct {
+		SessionToken string `json:"session_token"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/auth/login", nil, map[string]string{"token": code}, &resp); err != nil {
+		return "", err
+	}
+	c.sessionToken = resp.SessionToken
+	return resp.SessionToken, nil
+}
+
+// ListPages returns every synced page
+func (c *Client) ListPages(ctx context.Context) ([]Page, error) {
+	var resp struct {
+		Pages []Page `json:"pages"`
+	}
+	err := c.do(ctx, http.MethodGet, "/notion/pages", nil, nil, &resp)
+	return resp.Pages, err
+}
+
+// SyncPages syncs every page from Notion and returns the report of the sync
+func (c *Client) SyncPages(ctx context.Context) (*SyncRun, error) {
+	var resp struct {
+		Report *SyncRun `json:"report"`
+	}
+	err := c.do(ctx, http.MethodPost, "/notion/sync", nil, nil, &resp)
+	return resp.Report, err
+}
+
+// SyncPage syncs a single page from Notion; force refreshes it even if Notion reports no edit
+func (c *Client) SyncPage(ctx context.Context, pageID string, force bool) (*Page, error) {
+	var resp struct {
+		Page *Page `json:"page"`
+	}
+	query := url.Values{"force": {strconv.FormatBool(force)}}
+	err := c.do(ctx, http.MethodPost, "/notion/sync/"+url.PathEscape(pageID), query, nil, &resp)
+	return resp.Page, err
+}
+
+// ListPlatforms returns the names of the registered platforms
+func (c *Client) ListPlatforms(ctx context.Context) ([]string, error) {
+	var resp struct {
+		Platforms []string `json:"platforms"`
+	}
+	err := c.do(ctx, http.MethodGet, "/publisher/platforms", nil, nil, &resp)
+	return resp.Platforms, err
+}
+
+// PublishPage publishes a page to all its platforms and returns the results per platform
+func (c *Client) PublishPage(ctx context.Context, pageID string) (map[string]*PublishResult, error) {
+	var resp struct {
+		Results map[string]*PublishResult `json:"results"`
+	}
+	err := c.do(ctx, http.MethodPost, "/publisher/publish/"+url.PathEscape(pageID), nil, nil, &resp)
+	return resp.Results, err
+}
+
+// PublishPageToPlatform publishes a page to one platform
+func (c *Client) PublishPageToPlatform(ctx context.Context, pageID, platform string) (*PublishResult, error) {
+	var resp struct {
+		Result *PublishResult `json:"result"`
+	}
+	err := c.do(ctx, http.MethodPost, "/publisher/publish/"+url.PathEscape(pageID)+"/"+url.PathEscape(platform), nil, nil, &resp)
+	return resp.Result, err
+}
+
+// SaveDraft saves a page as a draft on a platform
+func (c *Client) SaveDraft(ctx context.Context, pageID, platform string) (*PublishResult, error) {
+	var resp struct {
+		Result *PublishResult `json:"result"`
+	}
+	err := c.do(ctx, http.MethodPost, "/publisher/draft/"+url.PathEscape(pageID)+"/"+url.PathEscape(platform), nil, nil, &resp)
+	return resp.Result, err
+}
+
+// PromoteDraft publishes the draft a job saved
+func (c *Client) PromoteDraft(ctx context.Context, jobID uint) (*Job, *PublishResult, error) {
+	var resp struct {
+		Job    *Job           `json:"job"`
+		Result *PublishResult `json:"result"`
+	}
+	err := c.do(ctx, http.MethodPost, "/publisher/promote/"+strconv.FormatUint(uint64(jobID), 10), nil, nil, &resp)
+	return resp.Job, resp.Result, err
+}
+
+// PublishHistory returns the jobs of a page
+func (c *Client) PublishHistory(ctx context.Context, pageID string) ([]Job, error) {
+	var resp struct {
+		History []Job `json:"history"`
+	}
+	err := c.do(ctx, http.MethodGet, "/publisher/history/"+url.PathEscape(pageID), nil, nil, &resp)
+	return resp.History, err
+}
+
+// PublishQueues returns the pages waiting to be published per platform
+func (c *Client) PublishQueues(ctx context.Context) (map[string][]QueuedPublish, error) {
+	var resp struct {
+		Queues map[string][]QueuedPublish `json:"queues"`
+	}
+	err := c.do(ctx, http.MethodGet, "/publisher/queue", nil, nil, &resp)
+	return resp.Queues, err
+}
+
+// ProcessPending queues the pending pages for the publish workers and returns how many were
+// queued
+func (c *Client) ProcessPending(ctx context.Context) (int, error) {
+	var resp struct {
+		Queued int `json:"queued"`
+	}
+	err := c.do(ctx, http.MethodPost, "/publisher/process-pending", nil, nil, &resp)
+	return resp.Queued, err
+}
+
+// SetPriority changes the priority a page is published with: urgent, normal or backfill
+func (c *Client) SetPriority(ctx context.Context, pageID, priority string) error {
+	return c.do(ctx, http.MethodPut, "/publisher/priority/"+url.PathEscape(pageID), nil, map[string]string{"priority": priority}, nil)
+}
+
+// PausePlatform holds the publishes to a platform until it is resumed
+func (c *Client) PausePlatform(ctx context.Context, platform, reason string) error {
+	return c.do(ctx, http.MethodPost, "/publisher/platforms/"+url.PathEscape(platform)+"/pause", nil, map[string]string{"reason": reason}, nil)
+}
+
+// ResumePlatform resumes publishing to a platform and returns how many held jobs were resumed
+func (c *Client) ResumePlatform(ctx context.Context, platform string) (int, error) {
+	var resp struct {
+		ResumedJobs int `json:"resumed_jobs"`
+	}
+	err := c.do(ctx, http.MethodPost, "/publisher/platforms/"+url.PathEscape(platform)+"/resume", nil, nil, &resp)
+	return resp.ResumedJobs, err
+}
+
+// ListJobs returns the jobs matching a query, most recently updated first
+func (c *Client) ListJobs(ctx context.Context, q JobQuery) (*JobList, error) {
+	query := url.Values{}
+	if q.Status != "" {
+		query.Set("status", q.Status)
+	}
+	if q.PublishID != "" {
+		query.Set("publish_id", q.PublishID)
+	}
+	if q.ErrorCode != "" {
+		query.Set("error_code", q.ErrorCode)
+	}
+	for key, value := range q.Metadata {
+		query.Add("metadata", key+":"+value)
+	}
+	if q.Limit > 0 {
+		query.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Offset > 0 {
+		query.Set("offset", strconv.Itoa(q.Offset))
+	}
+
+	var list JobList
+	if err := c.do(ctx, http.MethodGet, "/dashboard/jobs", query, nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// JobHistory returns the status changes of a job
+func (c *Client) JobHistory(ctx context.Context, jobID uint) ([]JobTransition, error) {
+	var resp struct {
+		Transitions []JobTransition `json:"transitions"`
+	}
+	err := c.do(ctx, http.MethodGet, "/dashboard/jobs/"+strconv.FormatUint(uint64(jobID), 10)+"/history", nil, nil, &resp)
+	return resp.Transitions, err
+}
+
+// RepublishJob republishes a job; unless forced it is skipped when the content is unchanged
+func (c *Client) RepublishJob(ctx context.Context, jobID uint, force bool) (*RepublishResult, error) {
+	var result RepublishResult
+	query := url.Values{"force": {strconv.FormatBool(force)}}
+	if err := c.do(ctx, http.MethodPost, "/dashboard/republish-job/"+strconv.FormatUint(uint64(jobID), 10), query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DashboardSummary returns the overview shown on the dashboard
+func (c *Client) DashboardSummary(ctx context.Context) (*DashboardSummary, error) {
+	var resp struct {
+		Summary *DashboardSummary `json:"summary"`
+	}
+	err := c.do(ctx, http.MethodGet, "/dashboard/summary", nil, nil, &resp)
+	return resp.Summary, err
+}
@@ -0,0 +1,172 @@
+package client
+
+import "time"
+
+// Page is a page synced from Notion or another source
+type Page struct {
+	ID             uint       `json:"id"`
+	NotionID       string     `json:"notion_id"`
+	Title          string     `json:"title"`
+	ENTitle        string     `json:"en_title"`
+	Summary        string     `json:"summary"`
+	Tags           []string   `json:"tags"`
+	Status         string     `json:"status"`
+	PostDate       *time.Time `json:"post_date"`
+	UnpublishAt    *time.Time `json:"unpublish_at"`
+	Owner          string     `json:"owner"`
+	Platforms      []string   `json:"platforms"`
+	ContentType    []string   `json:"content_type"`
+	CoverURL       string     `json:"cover_url"`
+	Source         string     `json:"source"`
+	Priority       string     `json:"priority"`
+	Series         string     `json:"series"`
+	AISummary      string     `json:"ai_summary"`
+	SEODescription string     `json:"seo_description"`
+	SuggestedTags  []string   `json:"suggested_tags"`
+	ArchivedAt     *time.Time `json:"archived_at"`
+	LastModified   time.Time  `json:"last_modified"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Platform is a platform pages are published to
+type Platform struct {
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	DisplayName string     `json:"display_name"`
+	Enabled     bool       `json:"enabled"`
+	Paused      bool       `json:"paused"`
+	PauseReason string     `json:"pause_reason,omitempty"`
+	PausedAt    *time.Time `json:"paused_at,omitempty"`
+}
+
+// Job is the publish of a page to a platform
+type Job struct {
+	ID          uint              `json:"id"`
+	PageID      uint              `json:"page_id"`
+	PlatformID  uint              `json:"platform_id"`
+	Status      string            `json:"status"`
+	Error       string            `json:"error"`
+	ErrorCode   string            `json:"error_code,omitempty"`
+	PublishID   string            `json:"publish_id"`
+	Metadata    map[string]string `json:"metadata"`
+	PublishedAt *time.Time        `json:"published_at"`
+	StartedAt   *time.Time        `json:"started_at,omitempty"`
+	FinishedAt  *time.Time        `json:"finished_at,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	Page        Page              `json:"page"`
+	Platform    Platform          `json:"platform"`
+}
+
+// JobTransition is a status change of a job
+type JobTransition struct {
+	ID         uint      `json:"id"`
+	JobID      uint      `json:"job_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Error      string    `json:"error"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Violation is a content rule a page broke on a platform
+type Violation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// PublishResult is the outcome of publishing a page to a platform
+type PublishResult struct {
+	Success     bool              `json:"success"`
+	PublishID   string            `json:"publish_id,omitempty"`
+	URL         string            `json:"url,omitempty"`
+	Error       string            `json:"error,omitempty"`
+	ErrorCode   string            `json:"error_code,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Violations  []Violation       `json:"violations,omitempty"`
+	PublishedAt time.Time         `json:"published_at"`
+}
+
+// SyncPageError is a page that failed to sync
+type SyncPageError struct {
+	PageID string `json:"page_id"`
+	Title  string `json:"title"`
+	Error  string `json:"error"`
+}
+
+// SyncRun is the report of a sync
+type SyncRun struct {
+	ID         uint            `json:"id"`
+	Source     string          `json:"source"`
+	Status     string          `json:"status"`
+	StartedAt  time.Time       `json:"started_at"`
+	FinishedAt *time.Time      `json:"finished_at"`
+	Scanned    int             `json:"scanned"`
+	Created    int             `json:"created"`
+	Updated    int             `json:"updated"`
+	Skipped    int             `json:"skipped"`
+	Failed     int             `json:"failed"`
+	Archived   int             `json:"archived"`
+	Errors     []SyncPageError `json:"errors"`
+	Error      string          `json:"error"`
+}
+
+// QueuedPublish is a page waiting to be published to a platform
+type QueuedPublish struct {
+	PageID   string    `json:"page_id"`
+	Title    string    `json:"title"`
+	Priority string    `json:"priority"`
+	Platform string    `json:"platform"`
+	Since    time.Time `json:"since"`
+}
+
+// JobQuery filters ListJobs; zero fields don't filter
+type JobQuery struct {
+	Status    string
+	PublishID string
+	ErrorCode string
+	// Metadata matches jobs whose metadata has all these values
+	Metadata map[string]string
+	Limit    int
+	Offset   int
+}
+
+// JobList is a page of jobs
+type JobList struct {
+	Jobs   []Job `json:"jobs"`
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+// RepublishResult is the outcome of republishing a job
+type RepublishResult struct {
+	Message        string `json:"message"`
+	Action         string `json:"action"`
+	ContentChanged bool   `json:"content_changed"`
+	Job            struct {
+		ID          uint       `json:"id"`
+		Status      string     `json:"status"`
+		Error       string     `json:"error"`
+		PublishedAt *time.Time `json:"published_at"`
+	} `json:"job"`
+	Result *PublishResult `json:"result"`
+}
+
+// DashboardSummary is the overview shown on the dashboard
+type DashboardSummary struct {
+	TotalPages            int        `json:"total_pages"`
+	TotalJobsToday        int        `json:"total_jobs_today"`
+	SuccessfulJobsToday   int        `json:"successful_jobs_today"`
+	FailedJobsToday       int        `json:"failed_jobs_today"`
+	PendingJobsCount      int        `json:"pending_jobs_count"`
+	ActivePlatformsCount  int        `json:"active_platforms_count"`
+	TotalPlatformsCount   int        `json:"total_platforms_count"`
+	LastSyncTime          *time.Time `json:"last_sync_time"`
+	LastPublishTime       *time.Time `json:"last_publish_time"`
+	UnresolvedErrorsCount int        `json:"unresolved_errors_count"`
+	AvgProcessTimeToday   float64    `json:"avg_process_time_today"`
+	P95ProcessTimeToday   float64    `json:"p95_process_time_today"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,358 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ifuryst/ripple/pkg/httpclient"
+)
+
+// Supported pull request providers
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+// Commit CI states reported by CommitStatus
+const (
+	CommitStatePending = "pending"
+	CommitStateSuccess = "success"
+	CommitStateFailure = "failure"
+)
+
+// CommitStatus is the combined CI state of a commit
+type CommitStatus struct {
+	State   string
+	URL     string
+	Message string
+}
+
+// PullRequestOptions describes a pull request to open
+type PullRequestOptions struct {
+	Title string
+	Body  string
+	Head  string
+	Base  string
+}
+
+// PullRequest is an opened GitHub pull request or GitLab merge request
+type PullRequest struct {
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+}
+
+// PathTraffic is the traffic of a repository path over the last 14 days
+type PathTraffic struct {
+	Path    string `json:"path"`
+	Title   string `json:"title"`
+	Count   int64  `json:"count"`
+	Uniques int64  `json:"uniques"`
+}
+
+// HostingClient talks to the GitHub or GitLab API of the repository host
+type HostingClient struct {
+	provider string
+	apiURL   string
+	project  string
+	token    string
+	client   *http.Client
+}
+
+// NewHostingClient creates a client for the repository; an empty provider is detected from the
+// repository host and an empty apiURL uses the provider's default API
+func NewHostingClient(repoURL, provider, apiURL, token string) (*HostingClient, error) {
+	if token == "" {
+		return nil, fmt.Errorf("a token is required to open pull requests")
+	}
+
+	host, project, err := parseRepoURL(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if provider == "" {
+		provider = ProviderGitHub
+		if strings.Contains(host, "gitlab") {
+			provider = ProviderGitLab
+		}
+	}
+
+	if apiURL == "" {
+		switch provider {
+		case ProviderGitHub:
+			apiURL = "https://api.github.com"
+			if host != "github.com" {
+				// GitHub Enterprise serves the API under /api/v3
+				apiURL = fmt.Sprintf("https://%s/api/v3", host)
+			}
+		case ProviderGitLab:
+			apiURL = fmt.Sprintf("https://%s/api/v4", host)
+		default:
+			return nil, fmt.Errorf("unsupported pull request provider: %s", provider)
+		}
+	}
+
+	return &HostingClient{
+		provider: provider,
+		apiURL:   strings.TrimSuffix(apiURL, "/"),
+		project:  project,
+		token:    token,
+		client:   httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+	}, nil
+}
+
+// CreatePullRequest opens a pull request, returning the existing one if the head branch already has one open
+func (c *HostingClient) CreatePullRequest(ctx context.Context, options PullRequestOptions) (*PullRequest, error) {
+	if c.provider == ProviderGitLab {
+		return c.createMergeRequest(ctx, options)
+	}
+	return c.createGitHubPullRequest(ctx, options)
+}
+
+func (c *HostingClient) createGitHubPullRequest(ctx context.Context, options PullRequestOptions) (*PullRequest, error) {
+	body := map[string]string{
+		"title": options.Title,
+		"body":  options.Body,
+		"head":  options.Head,
+		"base":  options.Base,
+	}
+
+	var created struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+	}
+	status, err := c.doJSON(ctx, "POST", fmt.Sprintf("%s/repos/%s/pulls", c.apiURL, c.project), body, &created)
+	if err == nil {
+		return &PullRequest{Number: created.Number, URL: created.HTMLURL}, nil
+	}
+	if status != http.StatusUnprocessableEntity {
+		return nil, err
+	}
+
+	// 422 usually means a pull request for this branch is already open
+	owner, _, _ := strings.Cut(c.project, "/")
+	query := url.Values{"head": {owner + ":" + options.Head}, "state": {"open"}}
+	var existing []struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+	}
+	if _, listErr := c.doJSON(ctx, "GET", fmt.Sprintf("%s/repos/%s/pulls?%s", c.apiURL, c.project, query.Encode()), nil, &existing); listErr != nil || len(existing) == 0 {
+		return nil, err
+	}
+
+	// Keep the preview in the description current
+	update := map[string]string{"title": options.Title, "body": options.Body}
+	if _, updateErr := c.doJSON(ctx, "PATCH", fmt.Sprintf("%s/repos/%s/pulls/%d", c.apiURL, c.project, existing[0].Number), update, nil); updateErr != nil {
+		return nil, updateErr
+	}
+
+	return &PullRequest{Number: existing[0].Number, URL: existing[0].HTMLURL}, nil
+}
+
+func (c *HostingClient) createMergeRequest(ctx context.Context, options PullRequestOptions) (*PullRequest, error) {
+	projectPath := url.PathEscape(c.project)
+	body := map[string]string{
+		"title":         options.Title,
+		"description":   options.Body,
+		"source_branch": options.Head,
+		"target_branch": options.Base,
+	}
+
+	var created struct {
+		IID    int    `json:"iid"`
+		WebURL string `json:"web_url"`
+	}
+	status, err := c.doJSON(ctx, "POST", fmt.Sprintf("%s/projects/%s/merge_requests", c.apiURL, projectPath), body, &created)
+	if err == nil {
+		return &PullRequest{Number: created.IID, URL: created.WebURL}, nil
+	}
+	if status != http.StatusConflict {
+		return nil, err
+	}
+
+	// 409 means a merge request for this branch is already open
+	query := url.Values{"source_branch": {options.Head}, "state": {"opened"}}
+	var existing []struct {
+		IID    int    `json:"iid"`
+		WebURL string `json:"web_url"`
+	}
+	if _, listErr := c.doJSON(ctx, "GET", fmt.Sprintf("%s/projects/%s/merge_requests?%s", c.apiURL, projectPath, query.Encode()), nil, &existing); listErr != nil || len(existing) == 0 {
+		return nil, err
+	}
+
+	update := map[string]string{"title": options.Title, "description": options.Body}
+	if _, updateErr := c.doJSON(ctx, "PUT", fmt.Sprintf("%s/projects/%s/merge_requests/%d", c.apiURL, projectPath, existing[0].IID), update, nil); updateErr != nil {
+		return nil, updateErr
+	}
+
+	return &PullRequest{Number: existing[0].IID, URL: existing[0].WebURL}, nil
+}
+
+// CommitStatus reports the CI state of a commit from its GitHub Actions workflow runs or GitLab
+// pipelines; the state is CommitStatePending when no run has been reported yet
+func (c *HostingClient) CommitStatus(ctx context.Context, sha string) (*CommitStatus, error) {
+	if c.provider == ProviderGitLab {
+		return c.pipelineStatus(ctx, sha)
+	}
+	return c.workflowRunStatus(ctx, sha)
+}
+
+func (c *HostingClient) workflowRunStatus(ctx context.Context, sha string) (*CommitStatus, error) {
+	query := url.Values{"head_sha": {sha}}
+	var runs struct {
+		WorkflowRuns []struct {
+			Name       string `json:"name"`
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+			HTMLURL    string `json:"html_url"`
+		} `json:"workflow_runs"`
+	}
+	if _, err := c.doJSON(ctx, "GET", fmt.Sprintf("%s/repos/%s/actions/runs?%s", c.apiURL, c.project, query.Encode()), nil, &runs); err != nil {
+		return nil, err
+	}
+
+	status := &CommitStatus{State: CommitStatePending}
+	if len(runs.WorkflowRuns) == 0 {
+		return status, nil
+	}
+
+	status.State = CommitStateSuccess
+	for _, run := range runs.WorkflowRuns {
+		switch {
+		case run.Status != "completed":
+			status.State = CommitStatePending
+			status.URL = run.HTMLURL
+		case run.Conclusion != "success" && run.Conclusion != "skipped" && run.Conclusion != "neutral":
+			// One failed workflow is enough to know the site will not update
+			return &CommitStatus{
+				State:   CommitStateFailure,
+				URL:     run.HTMLURL,
+				Message: fmt.Sprintf("workflow %q finished with %s", run.Name, run.Conclusion),
+			}, nil
+		}
+	}
+	return status, nil
+}
+
+func (c *HostingClient) pipelineStatus(ctx context.Context, sha string) (*CommitStatus, error) {
+	query := url.Values{"sha": {sha}}
+	var pipelines []struct {
+		Status string `json:"status"`
+		WebURL string `json:"web_url"`
+	}
+	if _, err := c.doJSON(ctx, "GET", fmt.Sprintf("%s/projects/%s/pipelines?%s", c.apiURL, url.PathEscape(c.project), query.Encode()), nil, &pipelines); err != nil {
+		return nil, err
+	}
+
+	// Pipelines are listed newest first
+	if len(pipelines) == 0 {
+		return &CommitStatus{State: CommitStatePending}, nil
+	}
+	latest := pipelines[0]
+	switch latest.Status {
+	case "success", "skipped":
+		return &CommitStatus{State: CommitStateSuccess, URL: latest.WebURL}, nil
+	case "failed", "canceled":
+		return &CommitStatus{
+			State:   CommitStateFailure,
+			URL:     latest.WebURL,
+			Message: fmt.Sprintf("pipeline finished with status %s", latest.Status),
+		}, nil
+	default:
+		return &CommitStatus{State: CommitStatePending, URL: latest.WebURL}, nil
+	}
+}
+
+// PopularPaths returns the ten most viewed paths of the repository over the last 14 days; only
+// GitHub reports repository traffic, and it needs a token with push access
+func (c *HostingClient) PopularPaths(ctx context.Context) ([]PathTraffic, error) {
+	if c.provider != ProviderGitHub {
+		return nil, fmt.Errorf("repository traffic is not available on %s", c.provider)
+	}
+
+	var paths []PathTraffic
+	if _, err := c.doJSON(ctx, "GET", fmt.Sprintf("%s/repos/%s/traffic/popular/paths", c.apiURL, c.project), nil, &paths); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// doJSON sends an API request and decodes the response, returning the status code with any error
+func (c *HostingClient) doJSON(ctx context.Context, method, endpoint string, payload any, out any) (int, error) {
+	var reader io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.provider == ProviderGitLab {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		req.Header.Set("Accept", "application/vnd.github+json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("%s API returned status %d: %s", c.provider, resp.StatusCode, string(body))
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+
+	return resp.StatusCode, nil
+}
+
+// parseRepoURL extracts the host and owner/repo path from an HTTPS or SSH repository URL
+func parseRepoURL(repoURL string) (string, string, error) {
+	trimmed := strings.TrimSuffix(strings.TrimSpace(repoURL), ".git")
+
+	var host, path string
+	switch {
+	case strings.HasPrefix(trimmed, "git@"):
+		// git@github.com:owner/repo
+		host, path, _ = strings.Cut(strings.TrimPrefix(trimmed, "git@"), ":")
+	default:
+		parsed, err := url.Parse(trimmed)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid repository URL: %w", err)
+		}
+		host = parsed.Hostname()
+		path = parsed.Path
+	}
+
+	path = strings.Trim(path, "/")
+	if host == "" || !strings.Contains(path, "/") {
+		return "", "", fmt.Errorf("cannot determine repository from URL: %s", repoURL)
+	}
+
+	return host, path, nil
+}
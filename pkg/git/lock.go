@@ -0,0 +1,31 @@
+package git
+
+import (
+	"path/filepath"
+	"sync"
+)
+
+// workspaceLocks holds one mutex per working directory, shared by every Repository that
+// points at the same local path
+var workspaceLocks sync.Map
+
+// Lock waits until no other caller holds the working directory and takes exclusive use of it.
+// Hold the lock across a whole pull, write, commit and push sequence so concurrent publishes
+// to the same repository don't mix their changes.
+func (r *Repository) Lock() {
+	r.workspaceLock().Lock()
+}
+
+// Unlock releases the working directory taken by Lock
+func (r *Repository) Unlock() {
+	r.workspaceLock().Unlock()
+}
+
+func (r *Repository) workspaceLock() *sync.Mutex {
+	path, err := filepath.Abs(r.localPath)
+	if err != nil {
+		path = filepath.Clean(r.localPath)
+	}
+	lock, _ := workspaceLocks.LoadOrStore(path, &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
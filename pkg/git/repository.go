@@ -1,24 +1,51 @@
 package git
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
-
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/storage/memory"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	remoteName = "origin"
+	// defaultAuthorName and defaultAuthorEmail sign commits when neither the configuration nor
+	// the git config of the machine names an author
+	defaultAuthorName  = "Ripple"
+	defaultAuthorEmail = "ripple@localhost"
 )
 
-// Repository manages git repository operations
+// defaultSSHKeys are the identities tried for SSH remotes without a configured key, like ssh
+// does, before falling back to the SSH agent
+var defaultSSHKeys = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// Repository manages git repository operations. Everything runs in-process with go-git, so
+// no git binary is needed.
 type Repository struct {
-	logger      *zap.Logger
-	repoURL     string
-	localPath   string
-	branch      string
+	logger       *zap.Logger
+	repoURL      string
+	localPath    string
+	branch       string
 	workspaceDir string
-	gitUsername string
-	gitEmail    string
+	gitUsername  string
+	gitEmail     string
+	authToken    string
+	sshKeyPath   string
 }
 
 // RepositoryConfig contains configuration for git repository
@@ -28,6 +55,8 @@ type RepositoryConfig struct {
 	WorkspaceDir string `json:"workspace_dir"`
 	GitUsername  string `json:"git_username"`
 	GitEmail     string `json:"git_email"`
+	AuthToken    string `json:"auth_token"`
+	SSHKeyPath   string `json:"ssh_key_path"`
 }
 
 func NewRepository(config RepositoryConfig, logger *zap.Logger) *Repository {
@@ -43,6 +72,8 @@ func NewRepository(config RepositoryConfig, logger *zap.Logger) *Repository {
 		workspaceDir: config.WorkspaceDir,
 		gitUsername:  config.GitUsername,
 		gitEmail:     config.GitEmail,
+		authToken:    config.AuthToken,
+		sshKeyPath:   config.SSHKeyPath,
 	}
 }
 
@@ -55,7 +86,7 @@ func (r *Repository) Initialize() error {
 
 	// Check if directory exists but is not a valid git repository
 	if r.directoryExists() && !r.exists() {
-		r.logger.Warn("Directory exists but is not a valid git repository, cleaning up", 
+		r.logger.Warn("Directory exists but is not a valid git repository, cleaning up",
 			zap.String("path", r.localPath))
 		if err := r.cleanup(); err != nil {
 			return fmt.Errorf("failed to cleanup invalid repository: %w", err)
@@ -64,12 +95,12 @@ func (r *Repository) Initialize() error {
 
 	// Check if repository exists locally and is valid
 	if r.exists() {
-		r.logger.Info("Repository exists locally, pulling latest changes", 
+		r.logger.Info("Repository exists locally, pulling latest changes",
 			zap.String("path", r.localPath))
-		
+
 		// Try to pull, if it fails, cleanup and re-clone
 		if err := r.pull(); err != nil {
-			r.logger.Warn("Failed to pull repository, cleaning up and re-cloning", 
+			r.logger.Warn("Failed to pull repository, cleaning up and re-cloning",
 				zap.String("error", err.Error()))
 			if cleanupErr := r.cleanup(); cleanupErr != nil {
 				return fmt.Errorf("failed to cleanup repository after pull failure: %w", cleanupErr)
@@ -80,12 +111,27 @@ func (r *Repository) Initialize() error {
 	}
 
 	// Clone the repository
-	r.logger.Info("Repository not found locally, cloning", 
+	r.logger.Info("Repository not found locally, cloning",
 		zap.String("url", r.repoURL),
 		zap.String("path", r.localPath))
 	return r.clone()
 }
 
+// CheckAccess verifies the remote can be read with the configured credentials by listing its
+// references, without touching the local repository
+func (r *Repository) CheckAccess(ctx context.Context) error {
+	auth, err := r.auth()
+	if err != nil {
+		return err
+	}
+
+	remote := gogit.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: remoteName, URLs: []string{r.repoURL}})
+	if _, err := remote.ListContext(ctx, &gogit.ListOptions{Auth: auth}); err != nil {
+		return fmt.Errorf("failed to list remote references: %w", err)
+	}
+	return nil
+}
+
 // directoryExists checks if the local path directory exists
 func (r *Repository) directoryExists() bool {
 	if _, err := os.Stat(r.localPath); err != nil {
@@ -100,16 +146,18 @@ func (r *Repository) exists() bool {
 	if _, err := os.Stat(gitDir); err != nil {
 		return false
 	}
-	
+
 	// Additional check: verify it's a valid git repository
 	return r.isValidGitRepository()
 }
 
 // isValidGitRepository checks if the directory is a valid git repository
 func (r *Repository) isValidGitRepository() bool {
-	cmd := exec.Command("git", "status", "--porcelain")
-	cmd.Dir = r.localPath
-	err := cmd.Run()
+	repo, err := r.open()
+	if err != nil {
+		return false
+	}
+	_, err = repo.Head()
 	return err == nil
 }
 
@@ -124,134 +172,178 @@ func (r *Repository) cleanup() error {
 	return nil
 }
 
+func (r *Repository) open() (*gogit.Repository, error) {
+	repo, err := gogit.PlainOpen(r.localPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	return repo, nil
+}
+
+func (r *Repository) worktree() (*gogit.Repository, *gogit.Worktree, error) {
+	repo, err := r.open()
+	if err != nil {
+		return nil, nil, err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open working tree: %w", err)
+	}
+	return repo, worktree, nil
+}
+
 // clone clones the repository from remote
 func (r *Repository) clone() error {
-	// Extract just the repo name for git clone command
-	repoName := extractRepoName(r.repoURL)
-	cmd := exec.Command("git", "clone", "-b", r.branch, r.repoURL, repoName)
-	cmd.Dir = r.workspaceDir
-	
 	r.logger.Info("Starting to clone repository",
 		zap.String("url", r.repoURL),
 		zap.String("branch", r.branch),
-		zap.String("repo_name", repoName),
-		zap.String("workspace_dir", r.workspaceDir),
+		zap.String("path", r.localPath),
 		zap.Bool("is_ssh", r.isSSHURL(r.repoURL)))
-	
-	// Set up environment for SSH operations
-	if r.isSSHURL(r.repoURL) {
-		r.setupSSHEnvironment(cmd)
+
+	auth, err := r.auth()
+	if err != nil {
+		return err
 	}
-	
-	output, err := cmd.CombinedOutput()
+
+	_, err = gogit.PlainClone(r.localPath, false, &gogit.CloneOptions{
+		URL:           r.repoURL,
+		Auth:          auth,
+		RemoteName:    remoteName,
+		ReferenceName: plumbing.NewBranchReferenceName(r.branch),
+		SingleBranch:  true,
+	})
 	if err != nil {
 		r.logger.Error("Failed to clone repository",
 			zap.String("url", r.repoURL),
-			zap.String("error", err.Error()),
-			zap.String("output", string(output)))
-		return fmt.Errorf("failed to clone repository: %s, output: %s", err, string(output))
+			zap.String("error", err.Error()))
+		// Don't leave a partial clone behind for the next attempt to trip over
+		if cleanupErr := r.cleanup(); cleanupErr != nil {
+			r.logger.Warn("Failed to remove partial clone", zap.Error(cleanupErr))
+		}
+		return fmt.Errorf("failed to clone repository: %w", err)
 	}
 
-	r.logger.Info("Repository cloned successfully", 
+	r.logger.Info("Repository cloned successfully",
 		zap.String("url", r.repoURL),
 		zap.String("branch", r.branch),
 		zap.String("path", r.localPath))
-	
+
 	return nil
 }
 
-// pull pulls the latest changes from remote
+// pull fast-forwards the base branch to the remote; histories that diverged fail, and
+// Initialize clones the repository again
 func (r *Repository) pull() error {
 	// First, checkout the target branch
 	if err := r.checkoutBranch(r.branch); err != nil {
 		return fmt.Errorf("failed to checkout branch: %w", err)
 	}
 
-	cmd := exec.Command("git", "pull", "origin", r.branch)
-	cmd.Dir = r.localPath
-	
-	// Set up environment for SSH operations
-	if r.isSSHURL(r.repoURL) {
-		r.setupSSHEnvironment(cmd)
+	_, worktree, err := r.worktree()
+	if err != nil {
+		return err
 	}
-	
-	output, err := cmd.CombinedOutput()
+	auth, err := r.auth()
 	if err != nil {
-		return fmt.Errorf("failed to pull repository: %s, output: %s", err, string(output))
+		return err
 	}
 
-	r.logger.Info("Repository pulled successfully", 
-		zap.String("branch", r.branch),
-		zap.String("output", string(output)))
-	
+	err = worktree.Pull(&gogit.PullOptions{
+		RemoteName:    remoteName,
+		ReferenceName: plumbing.NewBranchReferenceName(r.branch),
+		SingleBranch:  true,
+		Auth:          auth,
+	})
+	if errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		r.logger.Info("Repository already up to date", zap.String("branch", r.branch))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to pull repository: %w", err)
+	}
+
+	r.logger.Info("Repository pulled successfully",
+		zap.String("branch", r.branch))
+
 	return nil
 }
 
 // checkoutBranch switches to the specified branch
 func (r *Repository) checkoutBranch(branch string) error {
-	cmd := exec.Command("git", "checkout", branch)
-	cmd.Dir = r.localPath
-	
-	output, err := cmd.CombinedOutput()
+	repo, worktree, err := r.worktree()
 	if err != nil {
+		return err
+	}
+
+	ref := plumbing.NewBranchReferenceName(branch)
+	if _, err := repo.Reference(ref, true); errors.Is(err, plumbing.ErrReferenceNotFound) {
 		// If branch doesn't exist locally, try to create it from remote
-		if strings.Contains(string(output), "did not match any file") {
-			return r.createBranchFromRemote(branch)
-		}
-		return fmt.Errorf("failed to checkout branch: %s, output: %s", err, string(output))
+		return r.createBranchFromRemote(repo, worktree, branch)
+	} else if err != nil {
+		return fmt.Errorf("failed to read branch: %w", err)
 	}
 
+	if err := worktree.Checkout(&gogit.CheckoutOptions{Branch: ref}); err != nil {
+		return fmt.Errorf("failed to checkout branch: %w", err)
+	}
 	return nil
 }
 
-// createBranchFromRemote creates a local branch tracking the remote branch
-func (r *Repository) createBranchFromRemote(branch string) error {
-	// Fetch latest refs
-	fetchCmd := exec.Command("git", "fetch", "origin")
-	fetchCmd.Dir = r.localPath
-	
-	// Set up environment for SSH operations
-	if r.isSSHURL(r.repoURL) {
-		r.setupSSHEnvironment(fetchCmd)
+// createBranchFromRemote creates a local branch from the remote branch
+func (r *Repository) createBranchFromRemote(repo *gogit.Repository, worktree *gogit.Worktree, branch string) error {
+	auth, err := r.auth()
+	if err != nil {
+		return err
 	}
-	
-	if err := fetchCmd.Run(); err != nil {
+
+	// Fetch latest refs
+	err = repo.Fetch(&gogit.FetchOptions{RemoteName: remoteName, Auth: auth})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
 		return fmt.Errorf("failed to fetch from origin: %w", err)
 	}
 
-	// Create and checkout branch from remote
-	cmd := exec.Command("git", "checkout", "-b", branch, "origin/"+branch)
-	cmd.Dir = r.localPath
-	
-	output, err := cmd.CombinedOutput()
+	remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName(remoteName, branch), true)
 	if err != nil {
-		return fmt.Errorf("failed to create branch from remote: %s, output: %s", err, string(output))
+		return fmt.Errorf("failed to find branch %s on origin: %w", branch, err)
+	}
+
+	// Create and checkout branch from remote
+	if err := worktree.Checkout(&gogit.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName(branch),
+		Hash:   remoteRef.Hash(),
+		Create: true,
+	}); err != nil {
+		return fmt.Errorf("failed to create branch from remote: %w", err)
 	}
 
-	r.logger.Info("Created local branch from remote", 
+	r.logger.Info("Created local branch from remote",
 		zap.String("branch", branch))
-	
+
 	return nil
 }
 
-// Add stages files for commit
+// Add stages files for commit, every change of the working tree, including removals, when
+// no files are given
 func (r *Repository) Add(files ...string) error {
-	if len(files) == 0 {
-		files = []string{"."}
+	_, worktree, err := r.worktree()
+	if err != nil {
+		return err
 	}
 
-	args := append([]string{"add"}, files...)
-	cmd := exec.Command("git", args...)
-	cmd.Dir = r.localPath
-	
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to add files: %s, output: %s", err, string(output))
+	if len(files) == 0 {
+		if err := worktree.AddWithOptions(&gogit.AddOptions{All: true}); err != nil {
+			return fmt.Errorf("failed to add files: %w", err)
+		}
+	}
+	for _, file := range files {
+		if err := worktree.AddWithOptions(&gogit.AddOptions{All: true, Path: filepath.ToSlash(file)}); err != nil {
+			return fmt.Errorf("failed to add %s: %w", file, err)
+		}
 	}
 
-	r.logger.Debug("Files added to git", 
+	r.logger.Debug("Files added to git",
 		zap.Strings("files", files))
-	
+
 	return nil
 }
 
@@ -264,18 +356,18 @@ func (r *Repository) ConfigureGitUser() error {
 		return nil
 	}
 
-	// Set git user name
-	cmd := exec.Command("git", "config", "user.name", r.gitUsername)
-	cmd.Dir = r.localPath
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("failed to set git user name: %s, output: %s", err, string(output))
+	repo, err := r.open()
+	if err != nil {
+		return err
 	}
-
-	// Set git user email
-	cmd = exec.Command("git", "config", "user.email", r.gitEmail)
-	cmd.Dir = r.localPath
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("failed to set git user email: %s, output: %s", err, string(output))
+	cfg, err := repo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to read git config: %w", err)
+	}
+	cfg.User.Name = r.gitUsername
+	cfg.User.Email = r.gitEmail
+	if err := repo.SetConfig(cfg); err != nil {
+		return fmt.Errorf("failed to set git user: %w", err)
 	}
 
 	r.logger.Info("Git user configured successfully",
@@ -292,71 +384,188 @@ func (r *Repository) Commit(message string) error {
 		return fmt.Errorf("failed to configure git user: %w", err)
 	}
 
-	cmd := exec.Command("git", "commit", "-m", message)
-	cmd.Dir = r.localPath
-	
-	output, err := cmd.CombinedOutput()
+	repo, worktree, err := r.worktree()
 	if err != nil {
-		// Check if there are no changes to commit
-		if strings.Contains(string(output), "nothing to commit") {
-			r.logger.Info("No changes to commit")
-			return nil
-		}
-		return fmt.Errorf("failed to commit: %s, output: %s", err, string(output))
+		return err
 	}
 
-	r.logger.Info("Committed changes", 
+	_, err = worktree.Commit(message, &gogit.CommitOptions{Author: r.author(repo)})
+	if errors.Is(err, gogit.ErrEmptyCommit) {
+		r.logger.Info("No changes to commit")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+
+	r.logger.Info("Committed changes",
 		zap.String("message", message))
-	
+
 	return nil
 }
 
+// author signs commits as the configured user, or as the user of the repository or global git
+// config like git does
+func (r *Repository) author(repo *gogit.Repository) *object.Signature {
+	name, email := r.gitUsername, r.gitEmail
+	for _, scope := range []config.Scope{config.LocalScope, config.GlobalScope} {
+		if name != "" && email != "" {
+			break
+		}
+		cfg, err := repo.ConfigScoped(scope)
+		if err != nil {
+			continue
+		}
+		if name == "" {
+			name = cfg.User.Name
+		}
+		if email == "" {
+			email = cfg.User.Email
+		}
+	}
+	if name == "" {
+		name = defaultAuthorName
+	}
+	if email == "" {
+		email = defaultAuthorEmail
+	}
+	return &object.Signature{Name: name, Email: email, When: time.Now()}
+}
+
 // Push pushes commits to remote
 func (r *Repository) Push() error {
-	cmd := exec.Command("git", "push", "origin", r.branch)
-	cmd.Dir = r.localPath
-	
-	// Set up environment for SSH operations
-	if r.isSSHURL(r.repoURL) {
-		r.setupSSHEnvironment(cmd)
+	if err := r.push(r.branch, false); err != nil {
+		return fmt.Errorf("failed to push: %w", err)
+	}
+
+	r.logger.Info("Pushed to remote",
+		zap.String("branch", r.branch))
+
+	return nil
+}
+
+func (r *Repository) push(branch string, force bool) error {
+	repo, err := r.open()
+	if err != nil {
+		return err
 	}
-	
-	output, err := cmd.CombinedOutput()
+	auth, err := r.auth()
 	if err != nil {
-		return fmt.Errorf("failed to push: %s, output: %s", err, string(output))
+		return err
 	}
 
-	r.logger.Info("Pushed to remote", 
-		zap.String("branch", r.branch),
-		zap.String("output", string(output)))
-	
+	ref := plumbing.NewBranchReferenceName(branch)
+	refSpec := config.RefSpec(fmt.Sprintf("%s:%s", ref, ref))
+	if force {
+		refSpec = "+" + refSpec
+	}
+
+	err = repo.Push(&gogit.PushOptions{
+		RemoteName: remoteName,
+		RefSpecs:   []config.RefSpec{refSpec},
+		Auth:       auth,
+		Force:      force,
+	})
+	if errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return nil
+	}
+	return err
+}
+
+// CreateBranch creates (or resets) a local branch from the base branch and checks it out
+func (r *Repository) CreateBranch(branch string) error {
+	repo, worktree, err := r.worktree()
+	if err != nil {
+		return err
+	}
+
+	base, err := repo.Reference(plumbing.NewBranchReferenceName(r.branch), true)
+	if err != nil {
+		return fmt.Errorf("failed to find base branch %s: %w", r.branch, err)
+	}
+	ref := plumbing.NewBranchReferenceName(branch)
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(ref, base.Hash())); err != nil {
+		return fmt.Errorf("failed to create branch: %w", err)
+	}
+	if err := worktree.Checkout(&gogit.CheckoutOptions{Branch: ref, Keep: true}); err != nil {
+		return fmt.Errorf("failed to create branch: %w", err)
+	}
+
+	r.logger.Info("Created branch",
+		zap.String("branch", branch),
+		zap.String("base", r.branch))
+
 	return nil
 }
 
+// PushBranch pushes a branch to remote, replacing an earlier push of the same branch
+func (r *Repository) PushBranch(branch string) error {
+	if err := r.push(branch, true); err != nil {
+		return fmt.Errorf("failed to push branch: %w", err)
+	}
+
+	r.logger.Info("Pushed branch to remote",
+		zap.String("branch", branch))
+
+	return nil
+}
+
+// CheckoutBase discards uncommitted changes and switches back to the base branch
+func (r *Repository) CheckoutBase() error {
+	_, worktree, err := r.worktree()
+	if err != nil {
+		return err
+	}
+
+	if err := worktree.Reset(&gogit.ResetOptions{Mode: gogit.HardReset}); err != nil {
+		return fmt.Errorf("failed to reset working tree: %w", err)
+	}
+	if err := worktree.Clean(&gogit.CleanOptions{Dir: true}); err != nil {
+		return fmt.Errorf("failed to clean working tree: %w", err)
+	}
+	if err := worktree.Checkout(&gogit.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName(r.branch),
+		Force:  true,
+	}); err != nil {
+		return fmt.Errorf("failed to checkout %s: %w", r.branch, err)
+	}
+
+	return nil
+}
+
+// GetRepoURL returns the remote repository URL
+func (r *Repository) GetRepoURL() string {
+	return r.repoURL
+}
+
 // GetLastCommitHash returns the hash of the last commit
 func (r *Repository) GetLastCommitHash() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "HEAD")
-	cmd.Dir = r.localPath
-	
-	output, err := cmd.Output()
+	repo, err := r.open()
+	if err != nil {
+		return "", err
+	}
+
+	head, err := repo.Head()
 	if err != nil {
 		return "", fmt.Errorf("failed to get commit hash: %w", err)
 	}
 
-	return strings.TrimSpace(string(output)), nil
+	return head.Hash().String(), nil
 }
 
-// GetStatus returns the git status
+// GetStatus returns the git status in the short format
 func (r *Repository) GetStatus() (string, error) {
-	cmd := exec.Command("git", "status", "--porcelain")
-	cmd.Dir = r.localPath
-	
-	output, err := cmd.Output()
+	_, worktree, err := r.worktree()
+	if err != nil {
+		return "", err
+	}
+
+	status, err := worktree.Status()
 	if err != nil {
 		return "", fmt.Errorf("failed to get git status: %w", err)
 	}
 
-	return string(output), nil
+	return status.String(), nil
 }
 
 // HasChanges checks if there are any uncommitted changes
@@ -381,7 +590,7 @@ func (r *Repository) GetBranch() string {
 // CreateFile creates a file in the repository
 func (r *Repository) CreateFile(relativePath string, content []byte) error {
 	fullPath := filepath.Join(r.localPath, relativePath)
-	
+
 	// Create directory if it doesn't exist
 	dir := filepath.Dir(fullPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -393,9 +602,23 @@ func (r *Repository) CreateFile(relativePath string, content []byte) error {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
-	r.logger.Debug("File created in repository", 
+	r.logger.Debug("File created in repository",
+		zap.String("path", relativePath))
+
+	return nil
+}
+
+// RemovePath removes a file or directory from the repository working tree
+func (r *Repository) RemovePath(relativePath string) error {
+	fullPath := filepath.Join(r.localPath, relativePath)
+
+	if err := os.RemoveAll(fullPath); err != nil {
+		return fmt.Errorf("failed to remove path: %w", err)
+	}
+
+	r.logger.Debug("Path removed from repository",
 		zap.String("path", relativePath))
-	
+
 	return nil
 }
 
@@ -412,7 +635,7 @@ func extractRepoName(url string) string {
 	if strings.HasSuffix(url, ".git") {
 		url = strings.TrimSuffix(url, ".git")
 	}
-	
+
 	// Handle SSH URLs (git@github.com:user/repo)
 	if strings.Contains(url, ":") && strings.Contains(url, "@") {
 		// Split by colon and get the last part
@@ -426,13 +649,13 @@ func extractRepoName(url string) string {
 			}
 		}
 	}
-	
+
 	// Get the last part of the URL for HTTPS URLs
 	parts := strings.Split(url, "/")
 	if len(parts) > 0 {
 		return parts[len(parts)-1]
 	}
-	
+
 	return "repo"
 }
 
@@ -441,20 +664,71 @@ func (r *Repository) isSSHURL(url string) bool {
 	return strings.HasPrefix(url, "git@") || strings.HasPrefix(url, "ssh://")
 }
 
-// setupSSHEnvironment sets up the SSH environment for git commands
-func (r *Repository) setupSSHEnvironment(cmd *exec.Cmd) {
-	// Set up SSH options to handle host key verification
-	// This will automatically accept unknown host keys (be careful in production)
-	if cmd.Env == nil {
-		cmd.Env = os.Environ()
-	}
-	
-	// Set Git SSH command to use SSH with specific options
-	// We respect the user's SSH config, only disable strict host checking for convenience
-	sshCommand := "ssh -o StrictHostKeyChecking=no"
-	cmd.Env = append(cmd.Env, "GIT_SSH_COMMAND="+sshCommand)
-	
-	r.logger.Info("SSH environment configured for git command",
-		zap.String("url", r.repoURL),
-		zap.String("ssh_command", sshCommand))
-}
\ No newline at end of file
+// auth returns the credentials for talking to the remote: the token for HTTPS remotes, and
+// the configured key, a default identity or the SSH agent for SSH remotes
+func (r *Repository) auth() (transport.AuthMethod, error) {
+	if r.isSSHURL(r.repoURL) {
+		return r.sshAuth()
+	}
+	if r.authToken == "" {
+		return nil, nil
+	}
+
+	username := r.gitUsername
+	if username == "" {
+		username = "x-access-token"
+	}
+	return &githttp.BasicAuth{Username: username, Password: r.authToken}, nil
+}
+
+// sshAuth authenticates SSH remotes. Like the earlier ssh invocation with
+// StrictHostKeyChecking=no, unknown host keys are accepted.
+func (r *Repository) sshAuth() (transport.AuthMethod, error) {
+	user := sshUser(r.repoURL)
+
+	keyPaths := []string{r.sshKeyPath}
+	if r.sshKeyPath == "" {
+		keyPaths = nil
+		if home, err := os.UserHomeDir(); err == nil {
+			for _, name := range defaultSSHKeys {
+				keyPaths = append(keyPaths, filepath.Join(home, ".ssh", name))
+			}
+		}
+	}
+
+	for _, keyPath := range keyPaths {
+		if _, err := os.Stat(keyPath); err != nil {
+			if r.sshKeyPath != "" {
+				return nil, fmt.Errorf("failed to read SSH key: %w", err)
+			}
+			continue
+		}
+		keys, err := gitssh.NewPublicKeysFromFile(user, keyPath, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load SSH key %s: %w", keyPath, err)
+		}
+		keys.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+		return keys, nil
+	}
+
+	agent, err := gitssh.NewSSHAgentAuth(user)
+	if err != nil {
+		return nil, fmt.Errorf("no SSH key configured and no SSH agent available: %w", err)
+	}
+	agent.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+	return agent, nil
+}
+
+// sshUser returns the user of an SSH remote, git when it names none
+func sshUser(repoURL string) string {
+	if strings.HasPrefix(repoURL, "ssh://") {
+		if parsed, err := url.Parse(repoURL); err == nil && parsed.User != nil && parsed.User.Username() != "" {
+			return parsed.User.Username()
+		}
+		return "git"
+	}
+	if user, _, ok := strings.Cut(repoURL, "@"); ok && user != "" {
+		return user
+	}
+	return "git"
+}
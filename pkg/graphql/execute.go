@@ -0,0 +1,531 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Request is a GraphQL request as sent over HTTP
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Response is the result of a request. Data is nil when the request failed before execution.
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+// Error is an error of a request, located by the response path of the field that failed
+type Error struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// errNull is returned while a null propagates to the closest nullable parent; the error that
+// caused it has been recorded already
+var errNull = errors.New("null value for a non-null field")
+
+// Execute validates and runs a query. Field errors are reported in the response next to the
+// data of the other fields, as the spec requires.
+func (s *Schema) Execute(ctx context.Context, req Request) *Response {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+	if op.kind != "query" {
+		return &Response{Errors: []*Error{{Message: fmt.Sprintf("%s operations are not supported", op.kind)}}}
+	}
+
+	e := &executor{doc: doc}
+	if e.variables, err = coerceVariables(op.variables, req.Variables); err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+	if errs := e.validate(s.Query, op.selectionSet, map[string]bool{}); len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+
+	data, err := e.executeSelections(ctx, s.Query, nil, op.selectionSet, nil)
+	resp := &Response{Data: data, Errors: e.errors}
+	if err != nil {
+		// A null for a non-null root field nulls the whole data
+		resp.Data = json.RawMessage("null")
+	}
+	return resp
+}
+
+func selectOperation(doc *document, name string) (*operation, error) {
+	if name == "" {
+		if len(doc.operations) > 1 {
+			return nil, fmt.Errorf("operationName is required for documents with several operations")
+		}
+		return doc.operations[0], nil
+	}
+	for _, op := range doc.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+type executor struct {
+	doc       *document
+	variables map[string]interface{}
+	errors    []*Error
+}
+
+func (e *executor) addError(message string, path []interface{}) {
+	e.errors = append(e.errors, &Error{Message: message, Path: append([]interface{}(nil), path...)})
+}
+
+// validate checks the selections against the schema, so that execution only fails in resolvers
+func (e *executor) validate(obj *Object, selections []selection, visiting map[string]bool) []*Error {
+	var errs []*Error
+	fail := func(line int, format string, args ...interface{}) {
+		errs = append(errs, &Error{Message: fmt.Sprintf("line %d: ", line) + fmt.Sprintf(format, args...)})
+	}
+
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *field:
+			if sel.name == "__typename" {
+				if sel.selectionSet != nil {
+					fail(sel.line, "field __typename has no fields")
+				}
+				continue
+			}
+			def, ok := obj.Fields[sel.name]
+			if !ok {
+				fail(sel.line, "type %s has no field %q", obj.Name, sel.name)
+				continue
+			}
+			for name := range sel.arguments {
+				if findArgument(def.Args, name) == nil {
+					fail(sel.line, "field %s.%s has no argument %q", obj.Name, sel.name, name)
+				}
+			}
+			for _, arg := range def.Args {
+				if _, ok := arg.Type.(*NonNull); ok && arg.Default == nil && sel.arguments[arg.Name] == nil {
+					fail(sel.line, "argument %q of field %s.%s is required", arg.Name, obj.Name, sel.name)
+				}
+			}
+
+			child, isObject := namedType(def.Type).(*Object)
+			switch {
+			case isObject && sel.selectionSet == nil:
+				fail(sel.line, "field %s.%s of type %s must have a selection of fields", obj.Name, sel.name, def.Type)
+			case !isObject && sel.selectionSet != nil:
+				fail(sel.line, "field %s.%s of type %s has no fields", obj.Name, sel.name, def.Type)
+			case isObject:
+				errs = append(errs, e.validate(child, sel.selectionSet, visiting)...)
+			}
+		case *fragmentSpread:
+			frag, ok := e.doc.fragments[sel.name]
+			if !ok {
+				fail(sel.line, "unknown fragment %q", sel.name)
+				continue
+			}
+			if visiting[sel.name] {
+				fail(sel.line, "fragment %q spreads itself", sel.name)
+				continue
+			}
+			if frag.typeCondition != obj.Name {
+				continue
+			}
+			visiting[sel.name] = true
+			errs = append(errs, e.validate(obj, frag.selectionSet, visiting)...)
+			delete(visiting, sel.name)
+		case *inlineFragment:
+			if sel.typeCondition == "" || sel.typeCondition == obj.Name {
+				errs = append(errs, e.validate(obj, sel.selectionSet, visiting)...)
+			}
+		}
+	}
+	return errs
+}
+
+func findArgument(args []*Argument, name string) *Argument {
+	for _, arg := range args {
+		if arg.Name == name {
+			return arg
+		}
+	}
+	return nil
+}
+
+// namedType unwraps lists and non-null types
+func namedType(t Type) Type {
+	for {
+		switch wrapped := t.(type) {
+		case *List:
+			t = wrapped.OfType
+		case *NonNull:
+			t = wrapped.OfType
+		default:
+			return t
+		}
+	}
+}
+
+// orderedFields keeps the response fields in the order they were selected
+type orderedFields struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (o *orderedFields) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		b.Write(k)
+		b.WriteByte(':')
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// collectFields groups the selected fields by response key, applying fragments and directives
+func (e *executor) collectFields(obj *Object, selections []selection, keys *[]string, groups map[string][]*field, visited map[string]bool) {
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *field:
+			if !e.included(sel.directives) {
+				continue
+			}
+			key := sel.responseKey()
+			if _, ok := groups[key]; !ok {
+				*keys = append(*keys, key)
+			}
+			groups[key] = append(groups[key], sel)
+		case *fragmentSpread:
+			frag := e.doc.fragments[sel.name]
+			if visited[sel.name] || !e.included(sel.directives) || frag.typeCondition != obj.Name {
+				continue
+			}
+			visited[sel.name] = true
+			e.collectFields(obj, frag.selectionSet, keys, groups, visited)
+		case *inlineFragment:
+			if !e.included(sel.directives) || sel.typeCondition != "" && sel.typeCondition != obj.Name {
+				continue
+			}
+			e.collectFields(obj, sel.selectionSet, keys, groups, visited)
+		}
+	}
+}
+
+// included applies @skip(if:) and @include(if:)
+func (e *executor) included(directives []*directive) bool {
+	for _, d := range directives {
+		value, _ := e.resolveValue(d.arguments["if"]).(bool)
+		if d.name == "skip" && value || d.name == "include" && !value {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *executor) executeSelections(ctx context.Context, obj *Object, source interface{}, selections []selection, path []interface{}) (*orderedFields, error) {
+	result := &orderedFields{values: map[string]interface{}{}}
+	groups := map[string][]*field{}
+	e.collectFields(obj, selections, &result.keys, groups, map[string]bool{})
+
+	for _, key := range result.keys {
+		fields := groups[key]
+		if fields[0].name == "__typename" {
+			result.values[key] = obj.Name
+			continue
+		}
+		value, err := e.executeField(ctx, obj, source, fields, append(path, key))
+		if err != nil {
+			return nil, err
+		}
+		result.values[key] = value
+	}
+	return result, nil
+}
+
+func (e *executor) executeField(ctx context.Context, obj *Object, source interface{}, fields []*field, path []interface{}) (interface{}, error) {
+	def := obj.Fields[fields[0].name]
+	args, err := e.coerceArguments(def.Args, fields[0].arguments)
+	if err != nil {
+		e.addError(err.Error(), path)
+		return nullFor(def.Type)
+	}
+
+	value, err := e.resolve(def, ResolveParams{Context: ctx, Source: source, Args: args}, fields[0].name)
+	if err != nil {
+		e.addError(err.Error(), path)
+		return nullFor(def.Type)
+	}
+
+	var selections []selection
+	for _, f := range fields {
+		selections = append(selections, f.selectionSet...)
+	}
+	return e.completeValue(ctx, def.Type, selections, value, path)
+}
+
+// resolve runs the resolver of a field; a panic fails the field instead of the request
+func (e *executor) resolve(def *Field, p ResolveParams, name string) (value interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("internal error resolving %s: %v", name, recovered)
+		}
+	}()
+	if def.Resolve != nil {
+		return def.Resolve(p)
+	}
+	return defaultResolve(p.Source, name), nil
+}
+
+func nullFor(t Type) (interface{}, error) {
+	if _, ok := t.(*NonNull); ok {
+		return nil, errNull
+	}
+	return nil, nil
+}
+
+// completeValue shapes a resolved value by its type; a null propagating from a non-null
+// field stops at the first nullable position
+func (e *executor) completeValue(ctx context.Context, t Type, selections []selection, value interface{}, path []interface{}) (interface{}, error) {
+	nonNull, ok := t.(*NonNull)
+	if !ok {
+		completed, err := e.completeNullable(ctx, t, selections, value, path)
+		if err != nil {
+			return nil, nil
+		}
+		return completed, nil
+	}
+
+	completed, err := e.completeNullable(ctx, nonNull.OfType, selections, value, path)
+	if err != nil {
+		return nil, err
+	}
+	if completed == nil {
+		e.addError(fmt.Sprintf("cannot return null for non-null type %s", t), path)
+		return nil, errNull
+	}
+	return completed, nil
+}
+
+func (e *executor) completeNullable(ctx context.Context, t Type, selections []selection, value interface{}, path []interface{}) (interface{}, error) {
+	if isNil(value) {
+		return nil, nil
+	}
+	switch t := t.(type) {
+	case *List:
+		v := reflect.ValueOf(value)
+		for v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			e.addError(fmt.Sprintf("expected a list, got %T", value), path)
+			return nil, nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			item, err := e.completeValue(ctx, t.OfType, selections, v.Index(i).Interface(), append(path, i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	case *Object:
+		return e.executeSelections(ctx, t, value, selections, path)
+	}
+	return value, nil
+}
+
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// defaultResolve reads a field from a struct by its JSON name, or from a map
+func defaultResolve(source interface{}, name string) interface{} {
+	v := reflect.ValueOf(source)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		entry := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+		if !entry.IsValid() {
+			return nil
+		}
+		return entry.Interface()
+	case reflect.Struct:
+		if f, ok := structField(v, name); ok {
+			return f.Interface()
+		}
+	}
+	return nil
+}
+
+func structField(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if sf.Anonymous && tag == "" {
+			embedded := v.Field(i)
+			if embedded.Kind() == reflect.Ptr {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if f, ok := structField(embedded, name); ok {
+					return f, true
+				}
+			}
+			continue
+		}
+		if sf.IsExported() && (tag == name || tag == "" && sf.Name == name) {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func coerceVariables(defs []*variableDefinition, values map[string]interface{}) (map[string]interface{}, error) {
+	variables := map[string]interface{}{}
+	for _, def := range defs {
+		value, given := values[def.name]
+		if !given && def.hasDefault {
+			variables[def.name] = def.defaultValue
+			continue
+		}
+		if value == nil {
+			if def.typ.nonNull {
+				return nil, fmt.Errorf("variable $%s of type %s is required", def.name, def.typ)
+			}
+			variables[def.name] = nil
+			continue
+		}
+		variables[def.name] = value
+	}
+	return variables, nil
+}
+
+// resolveValue replaces variables in an argument value
+func (e *executor) resolveValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case variable:
+		return e.variables[string(v)]
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = e.resolveValue(item)
+		}
+		return items
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			object[key] = e.resolveValue(item)
+		}
+		return object
+	}
+	return value
+}
+
+func (e *executor) coerceArguments(defs []*Argument, given map[string]interface{}) (map[string]interface{}, error) {
+	args := map[string]interface{}{}
+	for _, def := range defs {
+		raw, ok := given[def.Name]
+		if ok {
+			if v, isVariable := raw.(variable); isVariable {
+				_, ok = e.variables[string(v)]
+			}
+		}
+		var value interface{}
+		if ok {
+			var err error
+			if value, err = coerceInput(def.Type, e.resolveValue(raw)); err != nil {
+				return nil, fmt.Errorf("argument %q: %w", def.Name, err)
+			}
+		}
+		// Null takes the default too, so resolvers can rely on arguments with defaults
+		if value == nil {
+			value = def.Default
+		}
+		if value != nil {
+			args[def.Name] = value
+		}
+	}
+	return args, nil
+}
+
+// coerceInput converts an argument value to the Go value of its type
+func coerceInput(t Type, value interface{}) (interface{}, error) {
+	if nonNull, ok := t.(*NonNull); ok {
+		if value == nil {
+			return nil, fmt.Errorf("expected a value of type %s, got null", t)
+		}
+		return coerceInput(nonNull.OfType, value)
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *List:
+		items, ok := value.([]interface{})
+		if !ok {
+			items = []interface{}{value}
+		}
+		coerced := make([]interface{}, len(items))
+		for i, item := range items {
+			var err error
+			if coerced[i], err = coerceInput(t.OfType, item); err != nil {
+				return nil, err
+			}
+		}
+		return coerced, nil
+	case *Scalar:
+		coerced, ok := t.coerce(value)
+		if !ok {
+			return nil, fmt.Errorf("expected a value of type %s, got %v", t, value)
+		}
+		return coerced, nil
+	}
+	return nil, fmt.Errorf("type %s cannot be used as input", t)
+}
@@ -0,0 +1,306 @@
+// Package graphql executes GraphQL queries against a schema of Go resolvers. It implements the
+// parts of the spec the dashboard needs: queries with arguments, variables, aliases, fragments
+// and the @skip and @include directives. Mutations, subscriptions and introspection are not
+// supported; Schema.SDL describes the schema instead.
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Type is a GraphQL type: a *Scalar, *Object, *List or *NonNull
+type Type interface {
+	String() string
+}
+
+// Scalar is a leaf type; coerce converts argument values to the Go value resolvers receive
+type Scalar struct {
+	Name        string
+	Description string
+	coerce      func(interface{}) (interface{}, bool)
+}
+
+func (s *Scalar) String() string { return s.Name }
+
+// The built-in scalars, plus Time for RFC 3339 times and JSON for values returned as they are
+var (
+	Int = &Scalar{Name: "Int", coerce: func(v interface{}) (interface{}, bool) {
+		switch n := v.(type) {
+		case int64:
+			return int(n), true
+		case float64:
+			return int(n), n == float64(int(n))
+		case json.Number:
+			i, err := n.Int64()
+			return int(i), err == nil
+		}
+		return nil, false
+	}}
+	Float = &Scalar{Name: "Float", coerce: func(v interface{}) (interface{}, bool) {
+		switch n := v.(type) {
+		case int64:
+			return float64(n), true
+		case float64:
+			return n, true
+		case json.Number:
+			f, err := n.Float64()
+			return f, err == nil
+		}
+		return nil, false
+	}}
+	String = &Scalar{Name: "String", coerce: func(v interface{}) (interface{}, bool) {
+		s, ok := v.(string)
+		return s, ok
+	}}
+	Boolean = &Scalar{Name: "Boolean", coerce: func(v interface{}) (interface{}, bool) {
+		b, ok := v.(bool)
+		return b, ok
+	}}
+	ID = &Scalar{Name: "ID", coerce: func(v interface{}) (interface{}, bool) {
+		switch id := v.(type) {
+		case string:
+			return id, true
+		case int64:
+			return fmt.Sprint(id), true
+		case float64:
+			return fmt.Sprint(int64(id)), id == float64(int64(id))
+		case json.Number:
+			return id.String(), true
+		}
+		return nil, false
+	}}
+	Time = &Scalar{Name: "Time", Description: "An RFC 3339 time", coerce: func(v interface{}) (interface{}, bool) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, false
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		return t, err == nil
+	}}
+	JSON = &Scalar{Name: "JSON", Description: "A JSON value", coerce: func(v interface{}) (interface{}, bool) {
+		return v, true
+	}}
+)
+
+// Object is a type with fields
+type Object struct {
+	Name        string
+	Description string
+	Fields      Fields
+}
+
+func (o *Object) String() string { return o.Name }
+
+// Fields are the fields of an object by name
+type Fields map[string]*Field
+
+// Field is a field of an object. Fields without a resolver read the struct field with the
+// same JSON name, or the map entry of that name, from the parent value.
+type Field struct {
+	Type        Type
+	Description string
+	Args        []*Argument
+	Resolve     ResolveFunc
+}
+
+// Argument is an argument of a field; a nil Default leaves optional arguments out of Args
+type Argument struct {
+	Name        string
+	Type        Type
+	Default     interface{}
+	Description string
+}
+
+// ResolveParams are passed to resolvers: the parent value and the coerced arguments
+type ResolveParams struct {
+	Context context.Context
+	Source  interface{}
+	Args    map[string]interface{}
+}
+
+// ResolveFunc resolves the value of a field
+type ResolveFunc func(p ResolveParams) (interface{}, error)
+
+// List is a list of another type
+type List struct {
+	OfType Type
+}
+
+func (l *List) String() string { return "[" + l.OfType.String() + "]" }
+
+// NonNull is a type whose values are never null
+type NonNull struct {
+	OfType Type
+}
+
+func (n *NonNull) String() string { return n.OfType.String() + "!" }
+
+// ListOf returns the list type of t
+func ListOf(t Type) *List { return &List{OfType: t} }
+
+// NonNullOf returns the non-null type of t
+func NonNullOf(t Type) *NonNull { return &NonNull{OfType: t} }
+
+// Schema is a GraphQL schema of queries
+type Schema struct {
+	Query *Object
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// ObjectOf builds an object type with a field per JSON field of the struct sample. Strings,
+// numbers, booleans, times and string slices map to their scalars, anything else to JSON.
+// Fields in extra are added or replace the reflected ones; a nil field removes one.
+func ObjectOf(name, description string, sample interface{}, extra Fields) *Object {
+	obj := &Object{Name: name, Description: description, Fields: Fields{}}
+	addStructFields(obj.Fields, reflect.TypeOf(sample))
+	for fieldName, f := range extra {
+		if f == nil {
+			delete(obj.Fields, fieldName)
+			continue
+		}
+		obj.Fields[fieldName] = f
+	}
+	return obj
+}
+
+func addStructFields(fields Fields, t reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if sf.Anonymous && name == "" {
+			addStructFields(fields, sf.Type)
+			continue
+		}
+		if !sf.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields[name] = &Field{Type: scalarOf(sf.Type)}
+	}
+}
+
+// scalarOf maps a Go type to the scalar its values are returned as
+func scalarOf(t reflect.Type) Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return Time
+	}
+	if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) {
+		return JSON
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return String
+	case reflect.Bool:
+		return Boolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Int
+	case reflect.Float32, reflect.Float64:
+		return Float
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String {
+			return ListOf(NonNullOf(String))
+		}
+	}
+	return JSON
+}
+
+// SDL describes the schema in the GraphQL schema definition language
+func (s *Schema) SDL() string {
+	objects := map[string]*Object{}
+	scalars := map[string]*Scalar{}
+	var collect func(t Type)
+	collect = func(t Type) {
+		switch t := t.(type) {
+		case *List:
+			collect(t.OfType)
+		case *NonNull:
+			collect(t.OfType)
+		case *Scalar:
+			scalars[t.Name] = t
+		case *Object:
+			if _, ok := objects[t.Name]; ok {
+				return
+			}
+			objects[t.Name] = t
+			for _, f := range t.Fields {
+				collect(f.Type)
+				for _, arg := range f.Args {
+					collect(arg.Type)
+				}
+			}
+		}
+	}
+	collect(s.Query)
+
+	var b strings.Builder
+	b.WriteString("schema {\n  query: " + s.Query.Name + "\n}\n")
+	for _, name := range sortedKeys(scalars) {
+		switch name {
+		case "Int", "Float", "String", "Boolean", "ID":
+			continue
+		}
+		b.WriteString("\n")
+		writeDescription(&b, "", scalars[name].Description)
+		b.WriteString("scalar " + name + "\n")
+	}
+	for _, name := range sortedKeys(objects) {
+		obj := objects[name]
+		b.WriteString("\n")
+		writeDescription(&b, "", obj.Description)
+		b.WriteString("type " + name + " {\n")
+		for _, fieldName := range sortedKeys(obj.Fields) {
+			f := obj.Fields[fieldName]
+			writeDescription(&b, "  ", f.Description)
+			b.WriteString("  " + fieldName)
+			if len(f.Args) > 0 {
+				args := make([]string, 0, len(f.Args))
+				for _, arg := range f.Args {
+					def := arg.Name + ": " + arg.Type.String()
+					if arg.Default != nil {
+						value, _ := json.Marshal(arg.Default)
+						def += " = " + string(value)
+					}
+					args = append(args, def)
+				}
+				b.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			b.WriteString(": " + f.Type.String() + "\n")
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func writeDescription(b *strings.Builder, indent, description string) {
+	if description != "" {
+		b.WriteString(indent + `"""` + description + `"""` + "\n")
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,212 @@
+// Package highlight splits source code into tokens for syntax highlighting. It is a small
+// lexer covering the languages posts usually contain; unknown languages come back as plain text.
+package highlight
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenType classifies a token for styling
+type TokenType int
+
+const (
+	Text TokenType = iota
+	Keyword
+	Builtin
+	String
+	Number
+	Comment
+	Function
+	Operator
+)
+
+// Token is a run of source code of one type
+type Token struct {
+	Type TokenType
+	Text string
+}
+
+// Tokenize splits code into tokens. Joining the text of the tokens gives back the code.
+func Tokenize(code, language string) []Token {
+	lang, ok := languages[normalizeLanguage(language)]
+	if !ok {
+		return []Token{{Type: Text, Text: code}}
+	}
+
+	l := &lexer{lang: lang, src: code}
+	l.run()
+	return l.tokens
+}
+
+// Lines splits tokens at line breaks, so each line can be rendered on its own. Tokens spanning
+// several lines, like block comments, are split into one token per line.
+func Lines(tokens []Token) [][]Token {
+	lines := [][]Token{nil}
+	for _, token := range tokens {
+		parts := strings.Split(token.Text, "\n")
+		for i, part := range parts {
+			if i > 0 {
+				lines = append(lines, nil)
+			}
+			if part != "" {
+				lines[len(lines)-1] = append(lines[len(lines)-1], Token{Type: token.Type, Text: part})
+			}
+		}
+	}
+	return lines
+}
+
+// Supported reports whether a language is highlighted
+func Supported(language string) bool {
+	_, ok := languages[normalizeLanguage(language)]
+	return ok
+}
+
+type lexer struct {
+	lang   *languageSpec
+	src    string
+	pos    int
+	tokens []Token
+}
+
+func (l *lexer) emit(tokenType TokenType, text string) {
+	if text == "" {
+		return
+	}
+	// Merge runs of the same type to keep the markup small
+	if n := len(l.tokens); n > 0 && l.tokens[n-1].Type == tokenType {
+		l.tokens[n-1].Text += text
+		return
+	}
+	l.tokens = append(l.tokens, Token{Type: tokenType, Text: text})
+}
+
+func (l *lexer) run() {
+	for l.pos < len(l.src) {
+		rest := l.src[l.pos:]
+
+		if end := l.comment(rest); end > 0 {
+			l.emit(Comment, rest[:end])
+			l.pos += end
+			continue
+		}
+		if end := l.stringLiteral(rest); end > 0 {
+			l.emit(String, rest[:end])
+			l.pos += end
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(rest)
+		switch {
+		case unicode.IsDigit(r):
+			end := scanWhile(rest, func(r rune) bool {
+				return unicode.IsDigit(r) || unicode.IsLetter(r) || r == '.' || r == '_'
+			})
+			l.emit(Number, rest[:end])
+			l.pos += end
+		case isIdentStart(r, l.lang):
+			end := scanWhile(rest, func(r rune) bool { return isIdentPart(r, l.lang) })
+			l.emitWord(rest[:end], rest[end:])
+			l.pos += end
+		case strings.ContainsRune(operatorChars, r):
+			l.emit(Operator, rest[:size])
+			l.pos += size
+		default:
+			l.emit(Text, rest[:size])
+			l.pos += size
+		}
+	}
+}
+
+// emitWord classifies an identifier by the language's keywords and builtins, or as a function
+// name when it is followed by a parenthesis
+func (l *lexer) emitWord(word, rest string) {
+	lookup := word
+	if l.lang.caseInsensitive {
+		lookup = strings.ToLower(word)
+	}
+
+	switch {
+	case l.lang.keywords[lookup]:
+		l.emit(Keyword, word)
+	case l.lang.builtins[lookup]:
+		l.emit(Builtin, word)
+	case strings.HasPrefix(strings.TrimLeft(rest, " \t"), "("):
+		l.emit(Function, word)
+	default:
+		l.emit(Text, word)
+	}
+}
+
+// comment returns the length of the comment at the start of s, or 0
+func (l *lexer) comment(s string) int {
+	for _, delimiters := range l.lang.blockComments {
+		if strings.HasPrefix(s, delimiters[0]) {
+			end := strings.Index(s[len(delimiters[0]):], delimiters[1])
+			if end < 0 {
+				return len(s)
+			}
+			return len(delimiters[0]) + end + len(delimiters[1])
+		}
+	}
+	for _, prefix := range l.lang.lineComments {
+		if strings.HasPrefix(s, prefix) {
+			if end := strings.IndexByte(s, '\n'); end >= 0 {
+				return end
+			}
+			return len(s)
+		}
+	}
+	return 0
+}
+
+// stringLiteral returns the length of the string literal at the start of s, or 0
+func (l *lexer) stringLiteral(s string) int {
+	for _, quote := range l.lang.multilineStrings {
+		if strings.HasPrefix(s, quote) {
+			end := strings.Index(s[len(quote):], quote)
+			if end < 0 {
+				return len(s)
+			}
+			return len(quote) + end + len(quote)
+		}
+	}
+
+	if s == "" || !strings.ContainsRune(l.lang.quotes, rune(s[0])) {
+		return 0
+	}
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		case '\n':
+			// Unterminated strings end at the line break
+			return i
+		}
+	}
+	return len(s)
+}
+
+const operatorChars = "+-*/%=<>!&|^~?:;,.()[]{}@"
+
+func isIdentStart(r rune, lang *languageSpec) bool {
+	return unicode.IsLetter(r) || r == '_' || strings.ContainsRune(lang.identChars, r)
+}
+
+func isIdentPart(r rune, lang *languageSpec) bool {
+	return isIdentStart(r, lang) || unicode.IsDigit(r)
+}
+
+func scanWhile(s string, fn func(r rune) bool) int {
+	for i, r := range s {
+		if !fn(r) {
+			return i
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,92 @@
+package highlight
+
+import (
+	"strings"
+	"testing"
+)
+
+const goSource = "package main\n\n/* Block comments\n   span lines */\nfunc main() {\n\tquery := `SELECT *\n  FROM posts`\n\tfmt.Println(query, 42) // done\n}\n"
+
+func joinTokens(tokens []Token) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteString(token.Text)
+	}
+	return b.String()
+}
+
+func TestTokenizeJoinsBackToSource(t *testing.T) {
+	tests := []struct {
+		language string
+		code     string
+	}{
+		{"go", goSource},
+		{"python", "def greet(name):\n    \"\"\"Say hello\n    to someone\"\"\"\n    print(f'hi {name}')  # greet\n"},
+		{"js", "const s = 'unterminated\nlet n = 0x1F; /* open comment"},
+		{"sql", "SELECT id, title FROM pages WHERE status = 'Published';\n"},
+		{"unknown", "plain\n  text"},
+		{"go", "中文注释 // 注释\nx := \"字符串\""},
+	}
+
+	for _, tt := range tests {
+		if got := joinTokens(Tokenize(tt.code, tt.language)); got != tt.code {
+			t.Errorf("Tokenize(%q) joins to %q, want the source back", tt.language, got)
+		}
+	}
+}
+
+func TestLinesSplitsMultilineTokens(t *testing.T) {
+	lines := Lines(Tokenize(goSource, "go"))
+
+	sourceLines := strings.Split(goSource, "\n")
+	if len(lines) != len(sourceLines) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(sourceLines))
+	}
+	for i, line := range lines {
+		if got := joinTokens(line); got != sourceLines[i] {
+			t.Errorf("line %d = %q, want %q", i+1, got, sourceLines[i])
+		}
+		for _, token := range line {
+			if strings.Contains(token.Text, "\n") {
+				t.Errorf("line %d has a token spanning lines: %q", i+1, token.Text)
+			}
+		}
+	}
+
+	// Both lines of the block comment and of the raw string keep their type
+	for _, want := range []struct {
+		line      int
+		text      string
+		tokenType TokenType
+	}{
+		{2, "/* Block comments", Comment},
+		{3, "   span lines */", Comment},
+		{5, "`SELECT *", String},
+		{6, "  FROM posts`", String},
+	} {
+		if !hasToken(lines[want.line], want.text, want.tokenType) {
+			t.Errorf("line %d has no %d token %q: %#v", want.line+1, want.tokenType, want.text, lines[want.line])
+		}
+	}
+}
+
+func TestLinesKeepsIndentation(t *testing.T) {
+	code := "def f():\n    if True:\n        return 1\n"
+	lines := Lines(Tokenize(code, "python"))
+
+	for i, want := range []string{"", "    ", "        "} {
+		got := joinTokens(lines[i])
+		if indent := got[:len(got)-len(strings.TrimLeft(got, " "))]; indent != want {
+			t.Errorf("line %d is indented with %q, want %q", i+1, indent, want)
+		}
+	}
+}
+
+func hasToken(line []Token, text string, tokenType TokenType) bool {
+	for _, token := range line {
+		if token.Text == text && token.Type == tokenType {
+			return true
+		}
+	}
+	return false
+}
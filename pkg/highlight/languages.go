@@ -0,0 +1,228 @@
+package highlight
+
+import "strings"
+
+// languageSpec describes the lexical rules of a language
+type languageSpec struct {
+	lineComments     []string
+	blockComments    [][2]string
+	quotes           string
+	multilineStrings []string
+	// identChars are characters besides letters, digits and _ that make up identifiers
+	identChars      string
+	caseInsensitive bool
+	keywords        map[string]bool
+	builtins        map[string]bool
+}
+
+// aliases maps the language names used by Notion and markdown fences to the lexers below
+var aliases = map[string]string{
+	"golang":     "go",
+	"py":         "python",
+	"python3":    "python",
+	"js":         "javascript",
+	"jsx":        "javascript",
+	"ts":         "typescript",
+	"tsx":        "typescript",
+	"c++":        "cpp",
+	"cc":         "cpp",
+	"h":          "c",
+	"c#":         "csharp",
+	"cs":         "csharp",
+	"rs":         "rust",
+	"sh":         "bash",
+	"shell":      "bash",
+	"zsh":        "bash",
+	"kt":         "kotlin",
+	"rb":         "ruby",
+	"yml":        "yaml",
+	"postgresql": "sql",
+	"mysql":      "sql",
+}
+
+func normalizeLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if alias, ok := aliases[language]; ok {
+		return alias
+	}
+	return language
+}
+
+func words(list string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(list) {
+		set[word] = true
+	}
+	return set
+}
+
+var cStyleComments = [][2]string{{"/*", "*/"}}
+
+var languages = map[string]*languageSpec{
+	"go": {
+		lineComments:     []string{"//"},
+		blockComments:    cStyleComments,
+		quotes:           `"'`,
+		multilineStrings: []string{"`"},
+		keywords: words(`break case chan const continue default defer else fallthrough for func go goto if
+			import interface map package range return select struct switch type var`),
+		builtins: words(`append bool byte cap close complex copy delete error false float32 float64 int int8
+			int16 int32 int64 iota len make new nil panic print println recover rune string true uint
+			uint8 uint16 uint32 uint64 uintptr any comparable`),
+	},
+	"python": {
+		lineComments:     []string{"#"},
+		quotes:           `"'`,
+		multilineStrings: []string{`"""`, `'''`},
+		keywords: words(`and as assert async await break class continue def del elif else except finally
+			for from global if import in is lambda nonlocal not or pass raise return try while with yield`),
+		builtins: words(`True False None self print len range int str float list dict set tuple bool
+			object super isinstance open enumerate zip map filter sorted`),
+	},
+	"javascript": {
+		lineComments:     []string{"//"},
+		blockComments:    cStyleComments,
+		quotes:           `"'`,
+		multilineStrings: []string{"`"},
+		identChars:       "$",
+		keywords: words(`async await break case catch class const continue debugger default delete do else
+			export extends finally for from function if import in instanceof let new of return static
+			super switch this throw try typeof var void while with yield`),
+		builtins: words(`true false null undefined NaN Infinity console window document Promise Array Object
+			String Number Boolean JSON Math Date Error Map Set`),
+	},
+	"typescript": {
+		lineComments:     []string{"//"},
+		blockComments:    cStyleComments,
+		quotes:           `"'`,
+		multilineStrings: []string{"`"},
+		identChars:       "$",
+		keywords: words(`abstract as async await break case catch class const continue debugger declare
+			default delete do else enum export extends finally for from function if implements import in
+			instanceof interface keyof let namespace new of private protected public readonly return static
+			super switch this throw try type typeof var void while with yield`),
+		builtins: words(`true false null undefined any unknown never string number boolean object void
+			console Promise Array Object String Number Boolean JSON Math Date Error Map Set Record Partial`),
+	},
+	"java": {
+		lineComments:  []string{"//"},
+		blockComments: cStyleComments,
+		quotes:        `"'`,
+		keywords: words(`abstract assert break case catch class continue default do else enum extends final
+			finally for if implements import instanceof interface native new package private protected
+			public return static super switch synchronized this throw throws try var void volatile while`),
+		builtins: words(`true false null boolean byte char double float int long short String Object
+			Integer List Map System`),
+	},
+	"kotlin": {
+		lineComments:     []string{"//"},
+		blockComments:    cStyleComments,
+		quotes:           `"'`,
+		multilineStrings: []string{`"""`},
+		keywords: words(`as break class continue do else false for fun if in interface is null object
+			package return super this throw true try typealias val var when while import private public
+			override open data sealed companion suspend`),
+		builtins: words(`Int Long Double Float Boolean String Unit Any List Map Set println listOf mapOf`),
+	},
+	"c": {
+		lineComments:  []string{"//"},
+		blockComments: cStyleComments,
+		quotes:        `"'`,
+		identChars:    "#",
+		keywords: words(`auto break case const continue default do else enum extern for goto if inline
+			register return sizeof static struct switch typedef union volatile while #include #define
+			#ifdef #ifndef #endif #if #else #pragma`),
+		builtins: words(`char double float int long short signed unsigned void NULL size_t printf malloc free`),
+	},
+	"cpp": {
+		lineComments:  []string{"//"},
+		blockComments: cStyleComments,
+		quotes:        `"'`,
+		identChars:    "#",
+		keywords: words(`auto break case catch class const constexpr continue default delete do else enum
+			explicit extern for friend goto if inline namespace new noexcept operator private protected
+			public return sizeof static struct switch template this throw try typedef typename union using
+			virtual volatile while #include #define #ifdef #ifndef #endif #if #else #pragma`),
+		builtins: words(`bool char double float int long short signed unsigned void nullptr true false std
+			string vector map cout endl size_t`),
+	},
+	"csharp": {
+		lineComments:  []string{"//"},
+		blockComments: cStyleComments,
+		quotes:        `"'`,
+		keywords: words(`abstract as async await base break case catch class const continue default
+			delegate do else enum event explicit extern finally fixed for foreach if implicit in interface
+			internal is lock namespace new operator out override params private protected public readonly
+			ref return sealed static struct switch this throw try typeof using var virtual void while`),
+		builtins: words(`true false null bool byte char decimal double float int long object sbyte short
+			string uint ulong ushort Console Task List`),
+	},
+	"rust": {
+		lineComments:  []string{"//"},
+		blockComments: cStyleComments,
+		quotes:        `"`,
+		keywords: words(`as async await break const continue crate dyn else enum extern fn for if impl in
+			let loop match mod move mut pub ref return self Self static struct super trait type unsafe use
+			where while`),
+		builtins: words(`true false bool char f32 f64 i8 i16 i32 i64 i128 isize str u8 u16 u32 u64 u128
+			usize String Vec Option Some None Result Ok Err Box println`),
+	},
+	"swift": {
+		lineComments:     []string{"//"},
+		blockComments:    cStyleComments,
+		quotes:           `"`,
+		multilineStrings: []string{`"""`},
+		keywords: words(`as break case catch class continue default defer do else enum extension for func
+			guard if import in init inout let protocol return self static struct switch throw throws try
+			var where while`),
+		builtins: words(`true false nil Int Double Float Bool String Array Dictionary print`),
+	},
+	"ruby": {
+		lineComments: []string{"#"},
+		quotes:       `"'`,
+		keywords: words(`alias and begin break case class def defined? do else elsif end ensure for if in
+			module next not or redo rescue retry return self super then unless until when while yield`),
+		builtins: words(`true false nil puts require attr_accessor attr_reader`),
+	},
+	"php": {
+		lineComments:  []string{"//", "#"},
+		blockComments: cStyleComments,
+		quotes:        `"'`,
+		identChars:    "$",
+		keywords: words(`abstract and as break case catch class const continue default do echo else
+			elseif extends final for foreach function if implements interface namespace new or private
+			protected public return static switch throw trait try use while`),
+		builtins: words(`true false null array isset empty count strlen`),
+	},
+	"bash": {
+		lineComments: []string{"#"},
+		quotes:       `"'`,
+		identChars:   "-",
+		keywords: words(`if then else elif fi for in do done while until case esac function return
+			export local readonly`),
+		builtins: words(`echo cd ls cat grep sed awk curl git docker make go npm pip sudo mkdir rm cp mv
+			chmod source set exit`),
+	},
+	"sql": {
+		lineComments:    []string{"--"},
+		blockComments:   cStyleComments,
+		quotes:          `'"`,
+		caseInsensitive: true,
+		keywords: words(`select from where and or not insert into values update set delete create table
+			alter drop index primary key foreign references join left right inner outer on as group by
+			order having limit offset union all distinct case when then else end is null in exists
+			between like returning with default unique constraint`),
+		builtins: words(`count sum avg min max coalesce now int integer bigint text varchar boolean
+			timestamp date jsonb serial true false`),
+	},
+	"json": {
+		quotes:   `"`,
+		builtins: words(`true false null`),
+	},
+	"yaml": {
+		lineComments: []string{"#"},
+		quotes:       `"'`,
+		identChars:   "-",
+		builtins:     words(`true false null yes no on off`),
+	},
+}
@@ -0,0 +1,118 @@
+package highlight
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultTheme is the theme used when none is configured
+const DefaultTheme = "github"
+
+// Theme holds the colors of a highlighting theme
+type Theme struct {
+	Name       string
+	Background string
+	Foreground string
+	colors     map[TokenType]string
+	italic     map[TokenType]bool
+}
+
+var themes = map[string]*Theme{
+	"github": {
+		Name:       "github",
+		Background: "#f6f8fa",
+		Foreground: "#24292e",
+		colors: map[TokenType]string{
+			Keyword:  "#d73a49",
+			Builtin:  "#005cc5",
+			String:   "#032f62",
+			Number:   "#005cc5",
+			Comment:  "#6a737d",
+			Function: "#6f42c1",
+			Operator: "#d73a49",
+		},
+		italic: map[TokenType]bool{Comment: true},
+	},
+	"monokai": {
+		Name:       "monokai",
+		Background: "#272822",
+		Foreground: "#f8f8f2",
+		colors: map[TokenType]string{
+			Keyword:  "#f92672",
+			Builtin:  "#66d9ef",
+			String:   "#e6db74",
+			Number:   "#ae81ff",
+			Comment:  "#75715e",
+			Function: "#a6e22e",
+			Operator: "#f92672",
+		},
+		italic: map[TokenType]bool{Comment: true},
+	},
+	"dracula": {
+		Name:       "dracula",
+		Background: "#282a36",
+		Foreground: "#f8f8f2",
+		colors: map[TokenType]string{
+			Keyword:  "#ff79c6",
+			Builtin:  "#8be9fd",
+			String:   "#f1fa8c",
+			Number:   "#bd93f9",
+			Comment:  "#6272a4",
+			Function: "#50fa7b",
+			Operator: "#ff79c6",
+		},
+		italic: map[TokenType]bool{Comment: true},
+	},
+	"solarized-light": {
+		Name:       "solarized-light",
+		Background: "#fdf6e3",
+		Foreground: "#657b83",
+		colors: map[TokenType]string{
+			Keyword:  "#859900",
+			Builtin:  "#b58900",
+			String:   "#2aa198",
+			Number:   "#d33682",
+			Comment:  "#93a1a1",
+			Function: "#268bd2",
+			Operator: "#859900",
+		},
+		italic: map[TokenType]bool{Comment: true},
+	},
+}
+
+// GetTheme returns a theme by name; an empty name returns the default theme
+func GetTheme(name string) (*Theme, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = DefaultTheme
+	}
+	theme, ok := themes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown code theme %q, available: %s", name, strings.Join(ThemeNames(), ", "))
+	}
+	return theme, nil
+}
+
+// ThemeNames returns the names of the available themes
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Style returns the inline CSS of a token type; plain text returns ""
+func (t *Theme) Style(tokenType TokenType) string {
+	color, ok := t.colors[tokenType]
+	if !ok {
+		return ""
+	}
+	style := "color:" + color
+	if t.italic[tokenType] {
+		style += ";font-style:italic"
+	}
+	return style
+}
@@ -0,0 +1,179 @@
+// Package httpclient builds the HTTP clients used for all outbound calls, so timeouts,
+// retries, rate limits and proxies are configured in one place.
+package httpclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/pkg/requestid"
+)
+
+type Config struct {
+	// Timeout bounds a single attempt; retries get their own timeout
+	Timeout      time.Duration `yaml:"timeout"`
+	MaxRetries   int           `yaml:"max_retries"`
+	RetryWaitMin time.Duration `yaml:"retry_wait_min"`
+	RetryWaitMax time.Duration `yaml:"retry_wait_max"`
+	// RateLimit caps the requests per second sent to a single host; 0 disables it
+	RateLimit float64 `yaml:"rate_limit"`
+	// ProxyURL routes requests through an http, https or socks5 proxy; the standard proxy
+	// environment variables are used when it is empty
+	ProxyURL    string `yaml:"proxy_url"`
+	LogRequests bool   `yaml:"log_requests"`
+}
+
+// RequestHook is called before every attempt of a request
+type RequestHook func(req *http.Request)
+
+// ResponseHook is called after every attempt with its response or error
+type ResponseHook func(req *http.Request, resp *http.Response, err error, elapsed time.Duration)
+
+var (
+	mu       sync.RWMutex
+	defaults Config
+	proxy    *url.URL
+	logger   = zap.NewNop()
+)
+
+// Configure sets the configuration used by every client created afterwards
+func Configure(cfg Config, log *zap.Logger) error {
+	var parsed *url.URL
+	if cfg.ProxyURL != "" {
+		var err error
+		if parsed, err = ParseProxy(cfg.ProxyURL); err != nil {
+			return err
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	defaults = cfg
+	proxy = parsed
+	if log != nil {
+		logger = log
+	}
+	return nil
+}
+
+// Option customizes a single client
+type Option func(*options)
+
+type options struct {
+	timeout    time.Duration
+	proxy      *url.URL
+	tlsConfig  *tls.Config
+	onRequest  []RequestHook
+	onResponse []ResponseHook
+}
+
+// WithTimeout overrides the configured per-attempt timeout
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithProxy overrides the configured proxy, e.g. for a platform that must call out from a
+// whitelisted IP
+func WithProxy(proxyURL *url.URL) Option {
+	return func(o *options) {
+		o.proxy = proxyURL
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the client's transport
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(o *options) {
+		o.tlsConfig = tlsConfig
+	}
+}
+
+// OnRequest adds a hook called before every attempt
+func OnRequest(hook RequestHook) Option {
+	return func(o *options) {
+		o.onRequest = append(o.onRequest, hook)
+	}
+}
+
+// OnResponse adds a hook called after every attempt
+func OnResponse(hook ResponseHook) Option {
+	return func(o *options) {
+		o.onResponse = append(o.onResponse, hook)
+	}
+}
+
+// New returns a client using the configured retries, rate limit and proxy
+func New(opts ...Option) *http.Client {
+	mu.RLock()
+	cfg, defaultProxy, log := defaults, proxy, logger
+	mu.RUnlock()
+
+	o := options{timeout: cfg.Timeout, proxy: defaultProxy}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	if o.proxy != nil {
+		base.Proxy = http.ProxyURL(o.proxy)
+	}
+	if o.tlsConfig != nil {
+		base.TLSClientConfig = o.tlsConfig
+	}
+
+	if cfg.LogRequests {
+		o.onResponse = append(o.onResponse, logResponse(log))
+	}
+
+	return &http.Client{
+		Transport: &transport{
+			base:       base,
+			timeout:    o.timeout,
+			maxRetries: cfg.MaxRetries,
+			waitMin:    cfg.RetryWaitMin,
+			waitMax:    cfg.RetryWaitMax,
+			rateLimit:  cfg.RateLimit,
+			onRequest:  o.onRequest,
+			onResponse: o.onResponse,
+			logger:     log,
+		},
+	}
+}
+
+// ParseProxy validates a proxy URL for use with WithProxy
+func ParseProxy(proxyURL string) (*url.URL, error) {
+	parsed, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch parsed.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return parsed, nil
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, use http, https or socks5", parsed.Scheme)
+	}
+}
+
+func logResponse(log *zap.Logger) ResponseHook {
+	return func(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+		fields := []zap.Field{
+			zap.String("method", req.Method),
+			zap.String("host", req.URL.Host),
+			zap.String("path", req.URL.Path),
+			zap.Duration("elapsed", elapsed),
+		}
+		log := requestid.Logger(req.Context(), log)
+		if err != nil {
+			log.Debug("HTTP request failed", append(fields, zap.Error(err))...)
+			return
+		}
+		log.Debug("HTTP request", append(fields, zap.Int("status", resp.StatusCode))...)
+	}
+}
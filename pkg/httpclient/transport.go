@@ -0,0 +1,253 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ifuryst/ripple/pkg/requestid"
+)
+
+const (
+	defaultRetryWaitMin = 500 * time.Millisecond
+	defaultRetryWaitMax = 30 * time.Second
+	// maxRetryAfter is the longest Retry-After a request waits for before giving up
+	maxRetryAfter = 2 * time.Minute
+)
+
+// transport retries failed attempts with jittered exponential backoff and spaces out
+// requests to the same host
+type transport struct {
+	base       http.RoundTripper
+	timeout    time.Duration
+	maxRetries int
+	waitMin    time.Duration
+	waitMax    time.Duration
+	rateLimit  float64
+	onRequest  []RequestHook
+	onResponse []ResponseHook
+	logger     *zap.Logger
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if t.rateLimit > 0 {
+			if err := limiterFor(req.URL.Host, t.rateLimit).wait(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+
+		attemptReq := req
+		if attempt > 0 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := t.roundTrip(attemptReq)
+
+		wait, retry := t.shouldRetry(req, resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		requestid.Logger(req.Context(), t.logger).Info("Retrying HTTP request",
+			zap.String("method", req.Method),
+			zap.String("host", req.URL.Host),
+			zap.Int("status", status),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("wait", wait),
+			zap.Error(err))
+
+		select {
+		case <-time.After(wait):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// roundTrip sends a single attempt, bounding it with the per-attempt timeout
+func (t *transport) roundTrip(req *http.Request) (*http.Response, error) {
+	// Calls made for an API request carry its ID, so the services called can be asked about it
+	if id := requestid.From(req.Context()); id != "" && req.Header.Get(requestid.Header) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(requestid.Header, id)
+	}
+
+	for _, hook := range t.onRequest {
+		hook(req)
+	}
+
+	cancel := func() {}
+	if t.timeout > 0 {
+		var ctx context.Context
+		ctx, cancel = context.WithTimeout(req.Context(), t.timeout)
+		req = req.WithContext(ctx)
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	for _, hook := range t.onResponse {
+		hook(req, resp, err, time.Since(start))
+	}
+
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The timeout covers reading the body too, so it is only released once the body is closed
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// shouldRetry decides whether an attempt is retried and how long to wait first. Rate limited
+// and unavailable responses are always retried since the server did not process the request;
+// other server and network errors only for idempotent requests, so a post is never created twice.
+func (t *transport) shouldRetry(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= t.maxRetries || req.Context().Err() != nil {
+		return 0, false
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return 0, false
+	}
+
+	if err != nil {
+		return t.backoff(attempt), isIdempotent(req.Method)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		if retryAfter, ok := ParseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return retryAfter, retryAfter <= maxRetryAfter
+		}
+		return t.backoff(attempt), true
+	case resp.StatusCode >= 500:
+		return t.backoff(attempt), isIdempotent(req.Method)
+	default:
+		return 0, false
+	}
+}
+
+// backoff doubles the wait for every attempt and picks a random duration in its upper half
+func (t *transport) backoff(attempt int) time.Duration {
+	waitMin, waitMax := t.waitMin, t.waitMax
+	if waitMin <= 0 {
+		waitMin = defaultRetryWaitMin
+	}
+	if waitMax <= 0 {
+		waitMax = defaultRetryWaitMax
+	}
+
+	wait := waitMin << attempt
+	if wait <= 0 || wait > waitMax {
+		wait = waitMax
+	}
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseRetryAfter reads a Retry-After header given either in seconds or as an HTTP date
+func ParseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// limiter spaces requests to a host evenly at the configured rate
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+var (
+	limitersMu sync.Mutex
+	limiters   = make(map[string]*limiter)
+)
+
+// limiterFor returns the limiter shared by all clients for a host
+func limiterFor(host string, rate float64) *limiter {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+
+	interval := time.Duration(float64(time.Second) / rate)
+	l, ok := limiters[host]
+	if !ok {
+		l = &limiter{}
+		limiters[host] = l
+	}
+	l.mu.Lock()
+	l.interval = interval
+	l.mu.Unlock()
+	return l
+}
+
+func (l *limiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,95 @@
+// Package imagehost uploads images to object storage behind our own CDN, so posts can reference
+// them by stable URLs instead of the expiring links Notion hands out.
+package imagehost
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ifuryst/ripple/pkg/httpclient"
+)
+
+// Supported providers
+const (
+	ProviderS3  = "s3"
+	ProviderR2  = "r2"
+	ProviderOSS = "oss"
+)
+
+// Config selects and configures a provider
+type Config struct {
+	Provider        string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// Region is the S3 region; R2 always uses "auto"
+	Region string
+	// Endpoint overrides the storage API host: any S3 compatible server, the R2 account endpoint
+	// (https://<account>.r2.cloudflarestorage.com) or the OSS region endpoint
+	// (oss-cn-hangzhou.aliyuncs.com)
+	Endpoint string
+	// PublicURL is the CDN base URL objects are served from; it is required for R2
+	PublicURL string
+	// Prefix is prepended to every object key
+	Prefix string
+}
+
+// Uploader stores images
+type Uploader interface {
+	Name() string
+	// Upload stores data under key and returns the public URL of the object
+	Upload(ctx context.Context, key, contentType string, data []byte) (string, error)
+}
+
+// NewUploader creates the configured uploader
+func NewUploader(cfg Config) (Uploader, error) {
+	if cfg.Bucket == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("bucket and credentials are required for image host %s", cfg.Provider)
+	}
+
+	client := httpclient.New(httpclient.WithTimeout(time.Minute))
+	switch strings.ToLower(cfg.Provider) {
+	case ProviderS3:
+		return newS3(cfg, client)
+	case ProviderR2:
+		if cfg.Endpoint == "" || cfg.PublicURL == "" {
+			return nil, fmt.Errorf("endpoint and public URL are required for R2")
+		}
+		cfg.Region = "auto"
+		uploader, err := newS3(cfg, client)
+		if err != nil {
+			return nil, err
+		}
+		uploader.name = ProviderR2
+		return uploader, nil
+	case ProviderOSS:
+		return newOSS(cfg, client)
+	default:
+		return nil, fmt.Errorf("unknown image host provider %q", cfg.Provider)
+	}
+}
+
+// objectKey joins the prefix and key
+func objectKey(prefix, key string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return key
+	}
+	return prefix + "/" + key
+}
+
+// publicURL returns the URL an object is served from
+func publicURL(base, key string) string {
+	return strings.TrimSuffix(base, "/") + "/" + key
+}
+
+// checkResponse turns an unsuccessful storage response into an error
+func checkResponse(provider string, resp *http.Response, body []byte) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return fmt.Errorf("%s returned status %d: %s", provider, resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,93 @@
+package imagehost
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// oss stores objects in Aliyun OSS, signed with the OSS header signature
+type oss struct {
+	endpoint  string
+	bucket    string
+	accessKey string
+	secretKey string
+	publicURL string
+	prefix    string
+	client    *http.Client
+}
+
+func newOSS(cfg Config, client *http.Client) (*oss, error) {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		if cfg.Region == "" {
+			return nil, fmt.Errorf("endpoint or region is required for OSS")
+		}
+		endpoint = fmt.Sprintf("oss-%s.aliyuncs.com", strings.TrimPrefix(cfg.Region, "oss-"))
+	}
+	endpoint = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(endpoint, "https://"), "http://"), "/")
+
+	base := cfg.PublicURL
+	if base == "" {
+		base = fmt.Sprintf("https://%s.%s", cfg.Bucket, endpoint)
+	}
+
+	return &oss{
+		endpoint:  endpoint,
+		bucket:    cfg.Bucket,
+		accessKey: cfg.AccessKeyID,
+		secretKey: cfg.SecretAccessKey,
+		publicURL: base,
+		prefix:    cfg.Prefix,
+		client:    client,
+	}, nil
+}
+
+func (u *oss) Name() string {
+	return ProviderOSS
+}
+
+func (u *oss) Upload(ctx context.Context, key, contentType string, data []byte) (string, error) {
+	key = objectKey(u.prefix, key)
+	objectURL := fmt.Sprintf("https://%s.%s/%s", u.bucket, u.endpoint, key)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL, bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	date := time.Now().UTC().Format(http.TimeFormat)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	req.Header.Set("Date", date)
+
+	// VERB, Content-MD5, Content-Type, Date and the canonicalized resource
+	stringToSign := strings.Join([]string{
+		http.MethodPut,
+		"",
+		contentType,
+		date,
+		fmt.Sprintf("/%s/%s", u.bucket, key),
+	}, "\n")
+	mac := hmac.New(sha1.New, []byte(u.secretKey))
+	mac.Write([]byte(stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("OSS %s:%s", u.accessKey, base64.StdEncoding.EncodeToString(mac.Sum(nil))))
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload to OSS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if err := checkResponse(ProviderOSS, resp, body); err != nil {
+		return "", err
+	}
+	return publicURL(u.publicURL, key), nil
+}
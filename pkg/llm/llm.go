@@ -0,0 +1,165 @@
+// Package llm generates text with the OpenAI chat completions API or a compatible server,
+// e.g. to write summaries and SEO metadata for posts.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ifuryst/ripple/pkg/httpclient"
+)
+
+const (
+	defaultBaseURL = "https://api.openai.com/v1"
+	defaultModel   = "gpt-4o-mini"
+)
+
+// Config configures the API the client talks to
+type Config struct {
+	APIKey string
+	// Model falls back to gpt-4o-mini when empty
+	Model string
+	// BaseURL points the client at a compatible API, e.g. a self-hosted or proxied server
+	BaseURL string
+	Timeout time.Duration
+}
+
+// Client sends chat completion requests
+type Client struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model          string            `json:"model"`
+	Messages       []chatMessage     `json:"messages"`
+	Temperature    float64           `json:"temperature"`
+	ResponseFormat map[string]string `json:"response_format,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// NewClient creates a client for the configured API
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("missing API key for the LLM API")
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = time.Minute
+	}
+
+	client := &Client{
+		baseURL: strings.TrimSuffix(cfg.BaseURL, "/"),
+		apiKey:  cfg.APIKey,
+		model:   cfg.Model,
+		client:  httpclient.New(httpclient.WithTimeout(timeout)),
+	}
+	if client.baseURL == "" {
+		client.baseURL = defaultBaseURL
+	}
+	if client.model == "" {
+		client.model = defaultModel
+	}
+	return client, nil
+}
+
+// Model returns the model the client generates with
+func (c *Client) Model() string {
+	return c.model
+}
+
+// CompleteJSON asks the model for a JSON object and decodes it into out. The system prompt
+// should describe the expected keys.
+func (c *Client) CompleteJSON(ctx context.Context, system, prompt string, out interface{}) error {
+	payload := chatRequest{
+		Model: c.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: prompt},
+		},
+		Temperature:    0.3,
+		ResponseFormat: map[string]string{"type": "json_object"},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call the LLM API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var result chatResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("unexpected LLM API response (status %d): %s", resp.StatusCode, truncate(string(data), 200))
+	}
+	if result.Error != nil {
+		return fmt.Errorf("LLM API error (status %d): %s", resp.StatusCode, result.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("LLM API returned status %d", resp.StatusCode)
+	}
+	if len(result.Choices) == 0 {
+		return fmt.Errorf("LLM API returned no choices")
+	}
+
+	answer := stripCodeFence(result.Choices[0].Message.Content)
+	if err := json.Unmarshal([]byte(answer), out); err != nil {
+		return fmt.Errorf("model did not answer with valid JSON: %w", err)
+	}
+	return nil
+}
+
+// stripCodeFence removes the ```json fence some compatible servers wrap JSON answers in
+func stripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	text = strings.TrimPrefix(text, "```")
+	text = strings.TrimPrefix(text, "json")
+	return strings.TrimSpace(strings.TrimSuffix(text, "```"))
+}
+
+func truncate(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	return text[:limit] + "..."
+}
@@ -0,0 +1,57 @@
+// Package requestid carries the ID of an API request through contexts, so the logs, outbound
+// HTTP calls and recorded errors caused by a request can be traced back to it.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"go.uber.org/zap"
+)
+
+// Header is the HTTP header the ID is read from and returned in
+const Header = "X-Request-ID"
+
+// validPattern matches the IDs accepted from clients and proxies; others are replaced, so
+// logs and headers never carry arbitrary input
+var validPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type contextKey struct{}
+
+// New returns a random ID
+func New() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// Valid reports whether an ID given by a client can be used as it is
+func Valid(id string) bool {
+	return validPattern.MatchString(id)
+}
+
+// With returns a context carrying a request ID
+func With(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// From returns the request ID of a context, empty outside of a request
+func From(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Logger returns log with the request ID of ctx added, or log itself outside of a request
+func Logger(ctx context.Context, log *zap.Logger) *zap.Logger {
+	if id := From(ctx); id != "" {
+		return log.With(zap.String("request_id", id))
+	}
+	return log
+}
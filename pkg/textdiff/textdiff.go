@@ -0,0 +1,251 @@
+// Package textdiff compares texts line by line with the Myers algorithm and renders the
+// differences as a unified diff.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxEdits bounds the edit distance searched for; texts further apart are shown as every line
+// of one replaced by every line of the other
+const maxEdits = 1000
+
+// Op tells whether a line is kept, added or removed
+type Op int
+
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+// Line is a line of a diff
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Diff is the line-by-line difference between two texts
+type Diff struct {
+	Lines   []Line
+	Added   int
+	Removed int
+}
+
+// Compare diffs two texts by line
+func Compare(a, b string) *Diff {
+	d := &Diff{Lines: diffLines(splitLines(a), splitLines(b))}
+	for _, line := range d.Lines {
+		switch line.Op {
+		case Insert:
+			d.Added++
+		case Delete:
+			d.Removed++
+		}
+	}
+	return d
+}
+
+// Changed reports whether the texts differ
+func (d *Diff) Changed() bool {
+	return d.Added > 0 || d.Removed > 0
+}
+
+// Unified renders the diff as a unified diff with context lines around each change, or an
+// empty string when the texts are the same
+func (d *Diff) Unified(fromName, toName string, context int) string {
+	if !d.Changed() {
+		return ""
+	}
+	if context < 0 {
+		context = 0
+	}
+
+	// aLine and bLine are the lines of each text before the diff line at the same index
+	aLine := make([]int, len(d.Lines)+1)
+	bLine := make([]int, len(d.Lines)+1)
+	for i, line := range d.Lines {
+		aLine[i+1], bLine[i+1] = aLine[i], bLine[i]
+		if line.Op != Insert {
+			aLine[i+1]++
+		}
+		if line.Op != Delete {
+			bLine[i+1]++
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+	for i := 0; i < len(d.Lines); {
+		for i < len(d.Lines) && d.Lines[i].Op == Equal {
+			i++
+		}
+		if i == len(d.Lines) {
+			break
+		}
+
+		// A hunk runs on while the changes are close enough for their context to overlap
+		start := max(i-context, 0)
+		end := i
+		for {
+			for end < len(d.Lines) && d.Lines[end].Op != Equal {
+				end++
+			}
+			next := end
+			for next < len(d.Lines) && d.Lines[next].Op == Equal {
+				next++
+			}
+			if next < len(d.Lines) && next-end <= 2*context {
+				end = next
+				continue
+			}
+			end = min(end+context, len(d.Lines))
+			break
+		}
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n",
+			hunkRange(aLine[start], aLine[end]-aLine[start]),
+			hunkRange(bLine[start], bLine[end]-bLine[start]))
+		for _, line := range d.Lines[start:end] {
+			switch line.Op {
+			case Insert:
+				out.WriteByte('+')
+			case Delete:
+				out.WriteByte('-')
+			default:
+				out.WriteByte(' ')
+			}
+			out.WriteString(line.Text)
+			out.WriteByte('\n')
+		}
+		i = end
+	}
+	return out.String()
+}
+
+// hunkRange formats the lines of a text a hunk covers; an empty range names the line before it
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// splitLines splits text into lines, without a last empty line for a trailing newline
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines diffs the lines between the prefix and suffix both texts share, which is usually
+// most of them
+func diffLines(a, b []string) []Line {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]Line, 0, len(a)+len(b)-prefix-suffix)
+	lines = appendLines(lines, Equal, a[:prefix])
+	lines = append(lines, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	return appendLines(lines, Equal, a[len(a)-suffix:])
+}
+
+// myers finds a shortest edit script turning a into b. The furthest reaching x of each diagonal
+// is kept for every edit distance, only for the diagonals reachable at that distance, to walk
+// the edits back from the end.
+func myers(a, b []string) []Line {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return appendLines(appendLines(nil, Delete, a), Insert, b)
+	}
+
+	limit := min(n+m, maxEdits)
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+				return backtrack(a, b, trace)
+			}
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+	}
+	return appendLines(appendLines(nil, Delete, a), Insert, b)
+}
+
+// backtrack walks the trace of myers back from the end of both texts
+func backtrack(a, b []string, trace [][]int) []Line {
+	x, y := len(a), len(b)
+	var reversed []Line
+	for d := len(trace) - 1; d > 0; d-- {
+		previous := trace[d-1]
+		at := func(k int) int { return previous[k+d-1] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, Line{Op: Equal, Text: a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			reversed = append(reversed, Line{Op: Insert, Text: b[y-1]})
+			y--
+		} else {
+			reversed = append(reversed, Line{Op: Delete, Text: a[x-1]})
+			x--
+		}
+	}
+	for x > 0 && y > 0 {
+		reversed = append(reversed, Line{Op: Equal, Text: a[x-1]})
+		x--
+		y--
+	}
+
+	lines := make([]Line, len(reversed))
+	for i, line := range reversed {
+		lines[len(reversed)-1-i] = line
+	}
+	return lines
+}
+
+func appendLines(lines []Line, op Op, texts []string) []Line {
+	for _, text := range texts {
+		lines = append(lines, Line{Op: op, Text: text})
+	}
+	return lines
+}
@@ -0,0 +1,104 @@
+package tts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// openAI uses the OpenAI speech API or a compatible server
+type openAI struct {
+	baseURL string
+	apiKey  string
+	model   string
+	voice   string
+	client  *http.Client
+}
+
+func newOpenAI(cfg Config, client *http.Client) *openAI {
+	provider := &openAI{
+		baseURL: strings.TrimSuffix(cfg.BaseURL, "/"),
+		apiKey:  cfg.APIKey,
+		model:   cfg.Model,
+		voice:   cfg.Voice,
+		client:  client,
+	}
+	if provider.baseURL == "" {
+		provider.baseURL = "https://api.openai.com/v1"
+	}
+	if provider.model == "" {
+		provider.model = "tts-1"
+	}
+	if provider.voice == "" {
+		provider.voice = "alloy"
+	}
+	return provider
+}
+
+func (p *openAI) Name() string {
+	return ProviderOpenAI
+}
+
+func (p *openAI) MaxChars() int {
+	return 4000
+}
+
+func (p *openAI) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	payload := map[string]string{
+		"model":           p.model,
+		"voice":           p.voice,
+		"input":           text,
+		"response_format": "mp3",
+	}
+	headers := map[string]string{"Authorization": "Bearer " + p.apiKey}
+	return postAudio(ctx, p.client, p.baseURL+"/audio/speech", headers, payload)
+}
+
+// elevenLabs uses the ElevenLabs text to speech API; Voice is the voice ID
+type elevenLabs struct {
+	baseURL string
+	apiKey  string
+	model   string
+	voice   string
+	client  *http.Client
+}
+
+func newElevenLabs(cfg Config, client *http.Client) *elevenLabs {
+	provider := &elevenLabs{
+		baseURL: strings.TrimSuffix(cfg.BaseURL, "/"),
+		apiKey:  cfg.APIKey,
+		model:   cfg.Model,
+		voice:   cfg.Voice,
+		client:  client,
+	}
+	if provider.baseURL == "" {
+		provider.baseURL = "https://api.elevenlabs.io/v1"
+	}
+	if provider.model == "" {
+		provider.model = "eleven_multilingual_v2"
+	}
+	if provider.voice == "" {
+		// Rachel, one of the premade voices every account has
+		provider.voice = "21m00Tcm4TlvDq8ikWAM"
+	}
+	return provider
+}
+
+func (p *elevenLabs) Name() string {
+	return ProviderElevenLabs
+}
+
+func (p *elevenLabs) MaxChars() int {
+	return 5000
+}
+
+func (p *elevenLabs) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	payload := map[string]string{
+		"text":     text,
+		"model_id": p.model,
+	}
+	headers := map[string]string{"xi-api-key": p.apiKey}
+	url := fmt.Sprintf("%s/text-to-speech/%s?output_format=mp3_44100_128", p.baseURL, p.voice)
+	return postAudio(ctx, p.client, url, headers, payload)
+}
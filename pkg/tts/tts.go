@@ -0,0 +1,177 @@
+// Package tts turns text into speech using a configurable provider, e.g. to narrate posts.
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ifuryst/ripple/pkg/httpclient"
+)
+
+// Supported providers
+const (
+	ProviderOpenAI     = "openai"
+	ProviderElevenLabs = "elevenlabs"
+)
+
+// Config selects and configures a provider
+type Config struct {
+	Provider string
+	APIKey   string
+	// Model and Voice fall back to the provider's defaults when empty
+	Model string
+	Voice string
+	// BaseURL points the provider at a compatible API, e.g. a self-hosted OpenAI compatible server
+	BaseURL string
+}
+
+// Provider synthesizes speech
+type Provider interface {
+	Name() string
+	// Synthesize returns MP3 audio of the text, which must not exceed MaxChars
+	Synthesize(ctx context.Context, text string) ([]byte, error)
+	// MaxChars is the longest text a single request accepts
+	MaxChars() int
+}
+
+// NewProvider creates the configured provider
+func NewProvider(cfg Config) (Provider, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("missing API key for TTS provider %s", cfg.Provider)
+	}
+
+	client := httpclient.New(httpclient.WithTimeout(2 * time.Minute))
+	switch strings.ToLower(cfg.Provider) {
+	case ProviderOpenAI:
+		return newOpenAI(cfg, client), nil
+	case ProviderElevenLabs:
+		return newElevenLabs(cfg, client), nil
+	default:
+		return nil, fmt.Errorf("unknown TTS provider %q", cfg.Provider)
+	}
+}
+
+// Synthesize narrates text of any length, splitting it into requests the provider accepts.
+// MP3 frames are self-contained, so the parts are simply concatenated.
+func Synthesize(ctx context.Context, provider Provider, text string) ([]byte, error) {
+	var audio bytes.Buffer
+	for i, chunk := range Split(text, provider.MaxChars()) {
+		data, err := provider.Synthesize(ctx, chunk)
+		if err != nil {
+			return nil, fmt.Errorf("failed to synthesize part %d: %w", i+1, err)
+		}
+		audio.Write(data)
+	}
+	if audio.Len() == 0 {
+		return nil, fmt.Errorf("no text to synthesize")
+	}
+	return audio.Bytes(), nil
+}
+
+// Split breaks text into chunks of at most maxChars characters, preferring paragraph and then
+// sentence boundaries so the narration does not pause mid-sentence
+func Split(text string, maxChars int) []string {
+	var chunks []string
+	var current strings.Builder
+
+	flush := func() {
+		if chunk := strings.TrimSpace(current.String()); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		current.Reset()
+	}
+
+	add := func(piece, separator string) {
+		if current.Len() > 0 && utf8.RuneCountInString(current.String())+utf8.RuneCountInString(separator+piece) > maxChars {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteString(separator)
+		}
+		current.WriteString(piece)
+	}
+
+	for _, paragraph := range strings.Split(text, "\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		if utf8.RuneCountInString(paragraph) <= maxChars {
+			add(paragraph, "\n")
+			continue
+		}
+
+		for _, sentence := range splitSentences(paragraph) {
+			// A single sentence longer than the limit is cut hard
+			for utf8.RuneCountInString(sentence) > maxChars {
+				runes := []rune(sentence)
+				add(string(runes[:maxChars]), " ")
+				sentence = string(runes[maxChars:])
+			}
+			add(sentence, " ")
+		}
+	}
+	flush()
+
+	return chunks
+}
+
+// splitSentences splits after sentence-ending punctuation, including the CJK full stops
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	runes := []rune(text)
+	for i, r := range runes {
+		switch r {
+		case '.', '!', '?', '。', '！', '？':
+			if sentence := strings.TrimSpace(string(runes[start : i+1])); sentence != "" {
+				sentences = append(sentences, sentence)
+			}
+			start = i + 1
+		}
+	}
+	if rest := strings.TrimSpace(string(runes[start:])); rest != "" {
+		sentences = append(sentences, rest)
+	}
+	return sentences
+}
+
+// postAudio sends a JSON request and returns the audio in the response body
+func postAudio(ctx context.Context, client *http.Client, url string, headers map[string]string, payload interface{}) ([]byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "audio/mpeg")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("TTS API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strings"
 	"time"
+	"unicode"
 )
 
 // EscapeYAML escapes special YAML characters in strings
@@ -35,6 +36,83 @@ func GenerateSlug(title string) string {
 	return slug
 }
 
+// GenerateSlugWithMetadata creates a slug from the explicit metadata["slug"] or the EN title if
+// available. CJK-only titles are slugged with metadata["slug_strategy"] (pinyin by default),
+// falling back to the Notion ID, also when the title has characters pinyin is unknown for. The
+// translation strategy slugs metadata["translated_title"], and transliterates titles that could
+// not be translated.
+func GenerateSlugWithMetadata(title string, metadata map[string]string) string {
+	if slug := GenerateSlug(metadata["slug"]); slug != "" {
+		return slug
+	}
+
+	if enTitle := metadata["en_title"]; enTitle != "" {
+		if slug := GenerateSlug(enTitle); slug != "" {
+			return slug
+		}
+	}
+
+	if !ContainsCJK(title) {
+		if slug := GenerateSlug(title); slug != "" {
+			return slug
+		}
+		return notionIDSlug(metadata["notion_id"])
+	}
+
+	var slug string
+	switch metadata["slug_strategy"] {
+	case SlugStrategyNotionID:
+		slug = notionIDSlug(metadata["notion_id"])
+	case SlugStrategyTranslation:
+		if slug = GenerateSlug(metadata["translated_title"]); slug == "" {
+			slug = transliterationSlug(title)
+		}
+	default:
+		slug = transliterationSlug(title)
+	}
+
+	if slug == "" {
+		slug = notionIDSlug(metadata["notion_id"])
+	}
+	if slug == "" {
+		slug = GenerateSlug(title)
+	}
+
+	return slug
+}
+
+// notionIDSlug builds a stable slug from the first characters of a Notion page ID
+func notionIDSlug(notionID string) string {
+	id := strings.ReplaceAll(strings.ToLower(notionID), "-", "")
+	if id == "" {
+		return ""
+	}
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	return "post-" + id
+}
+
+// transliterationSlug slugs the transliterated title, or returns "" when some of its characters
+// could not be transliterated: a slug missing some of the title's words could collide with other posts
+func transliterationSlug(title string) string {
+	words, complete := transliterate(title)
+	if !complete {
+		return ""
+	}
+	return GenerateSlug(words)
+}
+
+// ContainsCJK reports whether the text has Chinese characters or Japanese kana
+func ContainsCJK(text string) bool {
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) || isKana(r) {
+			return true
+		}
+	}
+	return false
+}
+
 // GenerateFilename creates a Jekyll post filename
 func GenerateFilename(title string, date time.Time) string {
 	slug := GenerateSlug(title)
@@ -44,13 +122,7 @@ func GenerateFilename(title string, date time.Time) string {
 
 // GenerateFilenameWithMetadata creates a Jekyll post filename using metadata
 func GenerateFilenameWithMetadata(title string, date time.Time, metadata map[string]string) string {
-	// Use EN title if available, fallback to regular title
-	titleForSlug := title
-	if enTitle, exists := metadata["en_title"]; exists && enTitle != "" {
-		titleForSlug = enTitle
-	}
-
-	slug := GenerateSlug(titleForSlug)
+	slug := GenerateSlugWithMetadata(title, metadata)
 	dateStr := date.Format("2006-01-02")
 	return fmt.Sprintf("%s-%s.md", dateStr, slug)
 }
@@ -64,13 +136,7 @@ func GenerateImageDir(title string, date time.Time) string {
 
 // GenerateImageDirWithMetadata creates the image directory name for a post using metadata
 func GenerateImageDirWithMetadata(title string, date time.Time, metadata map[string]string) string {
-	// Use EN title if available, fallback to regular title
-	titleForSlug := title
-	if enTitle, exists := metadata["en_title"]; exists && enTitle != "" {
-		titleForSlug = enTitle
-	}
-
-	slug := GenerateSlug(titleForSlug)
+	slug := GenerateSlugWithMetadata(title, metadata)
 	dateStr := date.Format("2006-01-02")
 	return fmt.Sprintf("%s-%s", dateStr, slug)
 }
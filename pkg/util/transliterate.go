@@ -0,0 +1,587 @@
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Slug strategies used when a post has no EN title
+const (
+	SlugStrategyPinyin      = "pinyin"
+	SlugStrategyTranslation = "translation"
+	SlugStrategyNotionID    = "notion_id"
+)
+
+var (
+	pinyinMu    sync.RWMutex
+	pinyinTable = buildPinyinTable()
+)
+
+// Transliterate converts CJK text into ASCII words: pinyin for Chinese characters and
+// romaji for Japanese kana. Other characters are kept as-is, unknown Han characters are dropped.
+func Transliterate(text string) string {
+	words, _ := transliterate(text)
+	return words
+}
+
+// transliterate is Transliterate, also reporting whether every Han character was known
+func transliterate(text string) (string, bool) {
+	pinyinMu.RLock()
+	defer pinyinMu.RUnlock()
+
+	runes := []rune(text)
+	complete := true
+	var words []string
+	var current strings.Builder
+
+	flush := func() {
+		if current.Len() > 0 {
+			words = append(words, current.String())
+			current.Reset()
+		}
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flush()
+			if syllable, ok := pinyinTable[r]; ok {
+				words = append(words, syllable)
+			} else {
+				complete = false
+			}
+		case isKana(r):
+			flush()
+			romaji, consumed := kanaRunToRomaji(runes[i:])
+			words = append(words, romaji)
+			i += consumed - 1
+		case unicode.IsSpace(r) || unicode.IsPunct(r):
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return strings.Join(words, " "), complete
+}
+
+// LoadPinyinDictionary extends the built-in pinyin table with a dictionary file in the
+// pinyin-data format: "U+4E2D: zhōng,zhòng  # 中". The first reading of each character is used.
+func LoadPinyinDictionary(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open pinyin dictionary: %w", err)
+	}
+	defer file.Close()
+
+	pinyinMu.Lock()
+	defer pinyinMu.Unlock()
+
+	loaded := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		var codepoint rune
+		if _, err := fmt.Sscanf(strings.TrimSpace(parts[0]), "U+%X", &codepoint); err != nil {
+			continue
+		}
+
+		reading := strings.TrimSpace(strings.Split(parts[1], ",")[0])
+		if reading == "" {
+			continue
+		}
+
+		pinyinTable[codepoint] = stripTones(reading)
+		loaded++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return loaded, fmt.Errorf("failed to read pinyin dictionary: %w", err)
+	}
+
+	return loaded, nil
+}
+
+var toneMarks = map[rune]rune{
+	'ā': 'a', 'á': 'a', 'ǎ': 'a', 'à': 'a',
+	'ē': 'e', 'é': 'e', 'ě': 'e', 'è': 'e',
+	'ī': 'i', 'í': 'i', 'ǐ': 'i', 'ì': 'i',
+	'ō': 'o', 'ó': 'o', 'ǒ': 'o', 'ò': 'o',
+	'ū': 'u', 'ú': 'u', 'ǔ': 'u', 'ù': 'u',
+	'ǖ': 'v', 'ǘ': 'v', 'ǚ': 'v', 'ǜ': 'v', 'ü': 'v',
+	'ń': 'n', 'ň': 'n', 'ǹ': 'n', 'ḿ': 'm',
+}
+
+func stripTones(reading string) string {
+	var b strings.Builder
+	for _, r := range reading {
+		if plain, ok := toneMarks[r]; ok {
+			r = plain
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func isKana(r rune) bool {
+	return (r >= 0x3041 && r <= 0x3096) || (r >= 0x30A1 && r <= 0x30FA) || r == 0x30FC
+}
+
+// kanaRunToRomaji converts a run of kana into a single romaji word and returns how many runes it consumed
+func kanaRunToRomaji(runes []rune) (string, int) {
+	var b strings.Builder
+	doubleNext := false
+	i := 0
+
+	for ; i < len(runes) && isKana(runes[i]); i++ {
+		r := toHiragana(runes[i])
+
+		switch r {
+		case 'っ':
+			doubleNext = true
+			continue
+		case 'ー':
+			// Long vowel mark repeats the previous vowel
+			if s := b.String(); len(s) > 0 {
+				b.WriteByte(s[len(s)-1])
+			}
+			continue
+		}
+
+		romaji := hiraganaRomaji[r]
+
+		// Contracted sounds such as きゃ -> kya, しゅ -> shu
+		if i+1 < len(runes) {
+			if small, ok := smallYoon[toHiragana(runes[i+1])]; ok && strings.HasSuffix(romaji, "i") {
+				base := strings.TrimSuffix(romaji, "i")
+				if !strings.HasSuffix(base, "sh") && !strings.HasSuffix(base, "ch") && base != "j" {
+					base += "y"
+				}
+				romaji = base + small
+				i++
+			}
+		}
+
+		if doubleNext && romaji != "" {
+			if strings.HasPrefix(romaji, "ch") {
+				b.WriteByte('t')
+			} else {
+				b.WriteByte(romaji[0])
+			}
+			doubleNext = false
+		}
+
+		b.WriteString(romaji)
+	}
+
+	return b.String(), i
+}
+
+func toHiragana(r rune) rune {
+	if r >= 0x30A1 && r <= 0x30F6 {
+		return r - 0x60
+	}
+	return r
+}
+
+var smallYoon = map[rune]string{'ゃ': "a", 'ゅ': "u", 'ょ': "o"}
+
+var hiraganaRomaji = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n",
+	'ゔ': "vu", 'ゕ': "ka", 'ゖ': "ke",
+}
+
+// buildPinyinTable indexes the built-in syllable list by character. Polyphonic characters
+// are listed under their most common reading only; LoadPinyinDictionary can extend coverage.
+func buildPinyinTable() map[rune]string {
+	table := make(map[rune]string)
+	for syllable, chars := range commonPinyin {
+		for _, r := range chars {
+			if _, exists := table[r]; !exists {
+				table[r] = syllable
+			}
+		}
+	}
+	return table
+}
+
+// commonPinyin covers frequently used simplified Chinese characters grouped by toneless syllable
+var commonPinyin = map[string]string{
+	"a":      "阿啊",
+	"ai":     "爱哀挨埃矮艾碍癌",
+	"an":     "安按案暗岸俺",
+	"ang":    "昂",
+	"ao":     "奥傲熬澳",
+	"ba":     "八把爸吧巴拔罢霸坝",
+	"bai":    "白百摆败拜柏",
+	"ban":    "办半班般板版搬伴扮",
+	"bang":   "帮棒绑榜",
+	"bao":    "包保报宝抱薄暴爆饱",
+	"bei":    "被北备背杯倍悲贝辈",
+	"ben":    "本奔笨",
+	"beng":   "崩蹦",
+	"bi":     "比必笔闭币毕避彼鼻壁臂逼",
+	"bian":   "边变便编遍辩扁",
+	"biao":   "表标",
+	"bie":    "别",
+	"bin":    "宾滨",
+	"bing":   "并病兵冰饼",
+	"bo":     "波博播伯拨泊玻勃",
+	"bu":     "不部步布补捕",
+	"ca":     "擦",
+	"cai":    "才采菜财材彩猜裁",
+	"can":    "参餐残惨灿",
+	"cang":   "藏仓",
+	"cao":    "草操曹",
+	"ce":     "测策侧册",
+	"ceng":   "层曾",
+	"cha":    "查差茶插察",
+	"chai":   "拆柴",
+	"chan":   "产缠",
+	"chang":  "长场常唱厂尝肠畅",
+	"chao":   "超朝潮炒吵",
+	"che":    "车彻撤",
+	"chen":   "陈沉晨称尘",
+	"cheng":  "成城程承诚乘呈",
+	"chi":    "吃持池迟尺赤齿",
+	"chong":  "充冲虫宠",
+	"chou":   "抽丑愁",
+	"chu":    "出处初除础楚触储",
+	"chuan":  "传船穿川",
+	"chuang": "创窗床闯",
+	"chui":   "吹垂",
+	"chun":   "春纯",
+	"ci":     "次此词辞磁刺",
+	"cong":   "从聪丛",
+	"cu":     "促粗",
+	"cui":    "催脆",
+	"cun":    "存村",
+	"cuo":    "错措",
+	"da":     "大打达答",
+	"dai":    "代带待贷袋戴",
+	"dan":    "单但担蛋弹淡胆",
+	"dang":   "当党挡档",
+	"dao":    "到道导倒岛刀盗",
+	"de":     "的得德",
+	"deng":   "等灯登",
+	"di":     "地第底低帝敌递滴",
+	"dian":   "点电店典",
+	"diao":   "调掉钓",
+	"die":    "跌叠",
+	"ding":   "定顶订丁",
+	"diu":    "丢",
+	"dong":   "动东懂冬洞",
+	"dou":    "都斗豆",
+	"du":     "度读独毒堵杜渡",
+	"duan":   "段短断端",
+	"dui":    "对队堆",
+	"dun":    "顿吨",
+	"duo":    "多夺朵",
+	"e":      "饿恶额俄鹅",
+	"en":     "恩",
+	"er":     "而二儿耳",
+	"fa":     "发法罚",
+	"fan":    "反饭范犯翻凡烦繁",
+	"fang":   "方放房防访仿",
+	"fei":    "非飞费肥废",
+	"fen":    "分份纷粉奋愤",
+	"feng":   "风丰封峰锋疯",
+	"fo":     "佛",
+	"fou":    "否",
+	"fu":     "服复父负富付福府副夫妇扶符浮腐覆",
+	"gai":    "该改概盖",
+	"gan":    "感干敢赶甘",
+	"gang":   "刚港钢岗",
+	"gao":    "高告搞稿",
+	"ge":     "个各歌格哥革割隔",
+	"gei":    "给",
+	"gen":    "根跟",
+	"geng":   "更耕",
+	"gong":   "工公共功供宫攻",
+	"gou":    "够构购狗",
+	"gu":     "故古顾固鼓骨谷股孤",
+	"gua":    "挂瓜",
+	"guai":   "怪乖",
+	"guan":   "关管观官馆惯冠",
+	"guang":  "光广",
+	"gui":    "规归贵鬼轨",
+	"gun":    "滚",
+	"guo":    "国过果锅",
+	"hai":    "还海害孩",
+	"han":    "含汉寒喊",
+	"hang":   "航",
+	"hao":    "好号毫豪",
+	"he":     "和合何河盒核",
+	"hei":    "黑",
+	"hen":    "很恨",
+	"heng":   "横衡",
+	"hong":   "红洪宏",
+	"hou":    "后候厚",
+	"hu":     "户护乎呼湖互忽胡虎",
+	"hua":    "话化花华画划",
+	"huai":   "坏怀",
+	"huan":   "换环欢缓",
+	"huang":  "黄皇慌",
+	"hui":    "会回汇挥灰恢毁",
+	"hun":    "婚混",
+	"huo":    "或活火获货伙",
+	"ji":     "几机及级记基集即技急计际济极既积击纪寄继激迹鸡",
+	"jia":    "家加价假架甲佳",
+	"jian":   "间见建件简检减坚键健渐监剑践",
+	"jiang":  "将讲江降奖",
+	"jiao":   "教交较角脚叫焦骄",
+	"jie":    "结解接节界借介街阶姐",
+	"jin":    "进今金近尽紧仅禁",
+	"jing":   "经精境静竟景京警镜井",
+	"jiu":    "就九久旧酒究救",
+	"ju":     "局据举具句剧聚居拒",
+	"juan":   "卷",
+	"jue":    "决觉绝",
+	"jun":    "军均君",
+	"ka":     "卡咖",
+	"kai":    "开凯",
+	"kan":    "看刊",
+	"kang":   "抗康",
+	"kao":    "考靠",
+	"ke":     "可科课客克刻渴",
+	"ken":    "肯",
+	"kong":   "空控孔恐",
+	"kou":    "口扣",
+	"ku":     "苦库哭",
+	"kua":    "跨夸",
+	"kuai":   "快块",
+	"kuan":   "宽款",
+	"kuang":  "况矿狂框",
+	"kun":    "困",
+	"kuo":    "扩",
+	"la":     "拉啦",
+	"lai":    "来",
+	"lan":    "蓝兰烂",
+	"lang":   "浪朗",
+	"lao":    "老劳",
+	"le":     "了乐勒",
+	"lei":    "类累泪雷",
+	"leng":   "冷",
+	"li":     "里理力利立例历离李丽礼",
+	"lian":   "连联练脸恋链",
+	"liang":  "两量亮良粮",
+	"liao":   "料聊疗",
+	"lie":    "列烈",
+	"lin":    "林临邻",
+	"ling":   "领另令零灵",
+	"liu":    "流六留刘",
+	"long":   "龙",
+	"lou":    "楼漏",
+	"lu":     "路录陆露",
+	"lv":     "律绿旅虑",
+	"lue":    "略",
+	"lun":    "论轮",
+	"luo":    "落罗逻络",
+	"ma":     "吗妈马码",
+	"mai":    "买卖麦",
+	"man":    "满慢",
+	"mang":   "忙",
+	"mao":    "毛猫贸冒",
+	"me":     "么",
+	"mei":    "没每美妹媒",
+	"men":    "们门",
+	"meng":   "梦猛",
+	"mi":     "米密秘迷",
+	"mian":   "面免",
+	"miao":   "秒妙",
+	"min":    "民敏",
+	"ming":   "明名命",
+	"mo":     "模末默莫摸",
+	"mou":    "某",
+	"mu":     "目木母幕",
+	"na":     "那拿哪",
+	"nai":    "奶耐",
+	"nan":    "南难男",
+	"nao":    "脑闹",
+	"ne":     "呢",
+	"nei":    "内",
+	"neng":   "能",
+	"ni":     "你尼泥",
+	"nian":   "年念",
+	"niang":  "娘",
+	"niao":   "鸟",
+	"nin":    "您",
+	"ning":   "宁",
+	"niu":    "牛",
+	"nong":   "农弄",
+	"nu":     "努",
+	"nv":     "女",
+	"nuan":   "暖",
+	"ou":     "欧偶",
+	"pa":     "怕爬",
+	"pai":    "排派拍",
+	"pan":    "判盘",
+	"pang":   "旁胖",
+	"pao":    "跑",
+	"pei":    "配培",
+	"peng":   "朋",
+	"pi":     "批皮",
+	"pian":   "篇片",
+	"piao":   "票",
+	"pin":    "品",
+	"ping":   "平评",
+	"po":     "破",
+	"pu":     "普",
+	"qi":     "其起期气七器企奇齐",
+	"qian":   "前钱千签浅",
+	"qiang":  "强墙",
+	"qiao":   "桥巧",
+	"qie":    "且切",
+	"qin":    "亲",
+	"qing":   "情清请青轻",
+	"qiu":    "求球秋",
+	"qu":     "去取区趣",
+	"quan":   "全权",
+	"que":    "却确缺",
+	"qun":    "群",
+	"ran":    "然",
+	"rang":   "让",
+	"re":     "热",
+	"ren":    "人任认",
+	"reng":   "仍",
+	"ri":     "日",
+	"rong":   "容",
+	"rou":    "肉",
+	"ru":     "如入",
+	"ruan":   "软",
+	"ruo":    "若弱",
+	"san":    "三散",
+	"se":     "色",
+	"sen":    "森",
+	"sha":    "杀沙",
+	"shan":   "山善",
+	"shang":  "上商伤",
+	"shao":   "少",
+	"she":    "社设",
+	"shei":   "谁",
+	"shen":   "什身深神",
+	"sheng":  "生声省胜",
+	"shi":    "是时事十使世实式市始示试",
+	"shou":   "手受收首",
+	"shu":    "书数术属树署",
+	"shuang": "双",
+	"shui":   "水税",
+	"shuo":   "说",
+	"si":     "思四死司私",
+	"song":   "送",
+	"su":     "速诉素",
+	"suan":   "算",
+	"sui":    "虽随",
+	"suo":    "所",
+	"ta":     "他她它",
+	"tai":    "太台态",
+	"tan":    "谈探",
+	"tao":    "讨套",
+	"te":     "特",
+	"ti":     "提体题",
+	"tian":   "天田",
+	"tiao":   "条",
+	"tie":    "铁",
+	"ting":   "听停",
+	"tong":   "同通统",
+	"tou":    "头投",
+	"tu":     "图突",
+	"tuan":   "团",
+	"tui":    "推",
+	"tuo":    "脱",
+	"wai":    "外",
+	"wan":    "万完晚",
+	"wang":   "王网往望",
+	"wei":    "为位未委维微",
+	"wen":    "文问",
+	"wo":     "我",
+	"wu":     "无五物务",
+	"xi":     "系西希习析",
+	"xia":    "下夏",
+	"xian":   "先现线",
+	"xiang":  "想向相项",
+	"xiao":   "小笑效",
+	"xie":    "些写",
+	"xin":    "新心信",
+	"xing":   "行性形型",
+	"xiong":  "兄",
+	"xiu":    "修",
+	"xu":     "需许序",
+	"xuan":   "选",
+	"xue":    "学",
+	"xun":    "训",
+	"ya":     "呀压",
+	"yan":    "研言眼验",
+	"yang":   "样",
+	"yao":    "要",
+	"ye":     "也业",
+	"yi":     "一以已意",
+	"yin":    "因音",
+	"ying":   "应影",
+	"yong":   "用",
+	"you":    "有又由优",
+	"yu":     "与于语",
+	"yuan":   "员原源",
+	"yue":    "月越",
+	"yun":    "运云",
+	"za":     "杂",
+	"zai":    "在再",
+	"zan":    "咱",
+	"zao":    "早造",
+	"ze":     "则",
+	"zen":    "怎",
+	"zeng":   "增",
+	"zhan":   "站展",
+	"zhang":  "张章",
+	"zhao":   "找",
+	"zhe":    "这者",
+	"zhen":   "真",
+	"zheng":  "正政",
+	"zhi":    "之只知智指",
+	"zhong":  "中种",
+	"zhou":   "周",
+	"zhu":    "主注",
+	"zhuan":  "专",
+	"zhuang": "装",
+	"zhun":   "准",
+	"zi":     "子自字",
+	"zong":   "总",
+	"zou":    "走",
+	"zu":     "组",
+	"zui":    "最",
+	"zuo":    "作做",
+}
@@ -0,0 +1,23 @@
+// Package web embeds the built dashboard into the binary, so the server doesn't depend on the
+// directory it runs from.
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// dist holds the output of `make web-build`. The .gitkeep placeholder, also copied there from
+// public/ by every build, keeps it compiling before the dashboard was built.
+//
+//go:embed all:dist
+var dist embed.FS
+
+// Dist returns the embedded dashboard, rooted at its index.html
+func Dist() fs.FS {
+	sub, err := fs.Sub(dist, "dist")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
@@ -8,7 +8,7 @@ import {
 } from '@/components/ui/dialog'
 import { Badge } from '@/components/ui/badge'
 import { Button } from '@/components/ui/button'
-import { FileText, Send, ChevronLeft, ChevronRight, Filter, RefreshCw } from 'lucide-react'
+import { FileText, Send, ChevronLeft, ChevronRight, Filter, RefreshCw, ExternalLink, ImageIcon } from 'lucide-react'
 import { dashboardApi } from '@/services/api'
 import { formatDate, formatNumber } from '@/lib/utils'
 import { ErrorDisplay } from '@/components/ErrorDisplay'
@@ -32,6 +32,7 @@ export function DetailedListDialog({ open, onOpenChange, type, title }: Detailed
   const [totalJobs, setTotalJobs] = useState(0)
   const [jobStatus, setJobStatus] = useState<string>('')
   const [republishingJobs, setRepublishingJobs] = useState<Set<number>>(new Set())
+  const [refreshingJobs, setRefreshingJobs] = useState<Set<number>>(new Set())
   const limit = 20
 
   const fetchData = async () => {
@@ -81,7 +82,10 @@ export function DetailedListDialog({ open, onOpenChange, type, title }: Detailed
   const handleRepublish = async (jobId: number) => {
     try {
       setRepublishingJobs(prev => new Set(prev).add(jobId))
-      await dashboardApi.republishJob(jobId)
+      const response = await dashboardApi.republishJob(jobId)
+      if (response.action === 'unchanged' && window.confirm('Content has not changed since the last publish. Republish anyway?')) {
+        await dashboardApi.republishJob(jobId, true)
+      }
       await fetchData() // Refresh the jobs list
     } catch (err) {
       console.error('Error republishing job:', err)
@@ -95,6 +99,23 @@ export function DetailedListDialog({ open, onOpenChange, type, title }: Detailed
     }
   }
 
+  const handleRefreshMedia = async (jobId: number) => {
+    try {
+      setRefreshingJobs(prev => new Set(prev).add(jobId))
+      await dashboardApi.refreshJobMedia(jobId)
+      await fetchData()
+    } catch (err) {
+      console.error('Error refreshing job media:', err)
+      setError('Failed to refresh media')
+    } finally {
+      setRefreshingJobs(prev => {
+        const newSet = new Set(prev)
+        newSet.delete(jobId)
+        return newSet
+      })
+    }
+  }
+
   const getStatusColor = (status: string) => {
     switch (status.toLowerCase()) {
       case 'done':
@@ -218,6 +239,19 @@ export function DetailedListDialog({ open, onOpenChange, type, title }: Detailed
                     <RefreshCw className={`h-3 w-3 mr-1 ${republishingJobs.has(job.id) ? 'animate-spin' : ''}`} />
                     {republishingJobs.has(job.id) ? 'Publishing...' : 'Republish'}
                   </Button>
+                  {(job.status === 'completed' || job.status === 'draft') && job.publish_id && (
+                    <Button
+                      variant="outline"
+                      size="sm"
+                      onClick={() => handleRefreshMedia(job.id)}
+                      disabled={refreshingJobs.has(job.id)}
+                      className="h-6 px-2 text-xs"
+                      title="Upload the images again without republishing"
+                    >
+                      <ImageIcon className={`h-3 w-3 mr-1 ${refreshingJobs.has(job.id) ? 'animate-pulse' : ''}`} />
+                      {refreshingJobs.has(job.id) ? 'Refreshing...' : 'Refresh media'}
+                    </Button>
+                  )}
                 </div>
               </div>
               <div className="text-sm text-muted-foreground space-y-1">
@@ -226,6 +260,19 @@ export function DetailedListDialog({ open, onOpenChange, type, title }: Detailed
                 {job.published_at && (
                   <p className="text-green-600">Published: {formatDate(job.published_at)}</p>
                 )}
+                {job.url && (
+                  <p>
+                    <a
+                      href={job.url}
+                      target="_blank"
+                      rel="noopener noreferrer"
+                      className="inline-flex items-center hover:underline break-all"
+                    >
+                      <ExternalLink className="h-3 w-3 mr-1 shrink-0" />
+                      {job.url}
+                    </a>
+                  </p>
+                )}
                 <p>Job ID: #{job.id}</p>
               </div>
               {job.error && (
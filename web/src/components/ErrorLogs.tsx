@@ -1,27 +1,32 @@
-import { useEffect, useState } from 'react'
+import { useEffect, useRef, useState } from 'react'
 import { Card, CardContent, CardHeader } from '@/components/ui/card'
 import { Badge } from '@/components/ui/badge'
 import { Button } from '@/components/ui/button'
-import { AlertTriangle, Check, RefreshCw, Filter, X } from 'lucide-react'
+import { AlertTriangle, Check, RefreshCw, Filter, X, KeyRound } from 'lucide-react'
 import { dashboardApi } from '@/services/api'
 import { formatDate } from '@/lib/utils'
 import { ErrorDisplay } from '@/components/ErrorDisplay'
-import type { ErrorLog } from '@/types/dashboard'
+import type { ErrorIssue, ErrorLog, IssueStatus } from '@/types/dashboard'
 
 export function ErrorLogs() {
-  const [errors, setErrors] = useState<ErrorLog[]>([])
+  const [issues, setIssues] = useState<ErrorIssue[]>([])
   const [loading, setLoading] = useState(true)
   const [error, setError] = useState<string | null>(null)
   const [limit, setLimit] = useState(20)
-  const [filter, setFilter] = useState<'all' | 'unresolved' | 'resolved'>('unresolved')
+  const [filter, setFilter] = useState<IssueStatus>('unresolved')
   const [resolving, setResolving] = useState<number | null>(null)
+  // Occurrences of the issues that were expanded, loaded on demand
+  const [occurrences, setOccurrences] = useState<Record<number, ErrorLog[]>>({})
+  const issuesRef = useRef<ErrorIssue[]>([])
+  issuesRef.current = issues
 
   const fetchErrors = async () => {
     try {
       setLoading(true)
       setError(null)
-      const data = await dashboardApi.getRecentErrors(limit)
-      setErrors(data)
+      const data = await dashboardApi.getRecentErrors(limit, filter)
+      setIssues(data)
+      setOccurrences({})
     } catch (err) {
       setError('Failed to fetch error logs')
       console.error('Error fetching errors:', err)
@@ -30,18 +35,28 @@ export function ErrorLogs() {
     }
   }
 
-  const handleResolveError = async (errorId: number) => {
+  const fetchOccurrences = async (issueId: number) => {
+    if (occurrences[issueId]) return
     try {
-      setResolving(errorId)
-      await dashboardApi.resolveError(errorId)
+      const data = await dashboardApi.getErrorIssue(issueId)
+      setOccurrences(prev => ({ ...prev, [issueId]: data.errors }))
+    } catch (err) {
+      console.error('Error fetching occurrences:', err)
+    }
+  }
+
+  const handleResolveIssue = async (issueId: number) => {
+    try {
+      setResolving(issueId)
+      await dashboardApi.resolveIssue(issueId)
       // 更新本地状态
-      setErrors(errors.map(err => 
-        err.id === errorId 
-          ? { ...err, resolved: true, resolved_at: new Date().toISOString() }
-          : err
+      setIssues(issues.map(issue =>
+        issue.id === issueId
+          ? { ...issue, resolved: true, resolved_at: new Date().toISOString() }
+          : issue
       ))
     } catch (err) {
-      console.error('Error resolving error:', err)
+      console.error('Error resolving issue:', err)
     } finally {
       setResolving(null)
     }
@@ -49,13 +64,26 @@ export function ErrorLogs() {
 
   useEffect(() => {
     fetchErrors()
-  }, [limit])
+  }, [limit, filter])
 
-  const filteredErrors = errors.filter(err => {
-    if (filter === 'resolved') return err.resolved
-    if (filter === 'unresolved') return !err.resolved
-    return true
-  })
+  // Count new occurrences as they are recorded; errors of a new issue reload the list
+  useEffect(() => {
+    return dashboardApi.subscribeEvents(event => {
+      if (event.type !== 'error') return
+      const errorLog = event.data
+      const issue = issuesRef.current.find(i => i.id === errorLog.issue_id)
+      if (!issue) {
+        fetchErrors()
+        return
+      }
+      const updated = { ...issue, count: issue.count + 1, last_seen_at: errorLog.created_at, resolved: false, resolved_at: undefined }
+      setIssues(prev => [updated, ...prev.filter(i => i.id !== issue.id)])
+      setOccurrences(prev => {
+        if (!errorLog.issue_id || !prev[errorLog.issue_id]) return prev
+        return { ...prev, [errorLog.issue_id]: [errorLog, ...prev[errorLog.issue_id]] }
+      })
+    })
+  }, [limit, filter])
 
   const getErrorLevelColor = (level: string) => {
     switch (level.toLowerCase()) {
@@ -159,8 +187,8 @@ export function ErrorLogs() {
         <Card>
           <CardContent className="p-4">
             <div className="flex items-center justify-between">
-              <span className="text-sm text-muted-foreground">Total Errors</span>
-              <span className="text-lg font-bold">{errors.length}</span>
+              <span className="text-sm text-muted-foreground">Issues</span>
+              <span className="text-lg font-bold">{issues.length}</span>
             </div>
           </CardContent>
         </Card>
@@ -169,7 +197,7 @@ export function ErrorLogs() {
             <div className="flex items-center justify-between">
               <span className="text-sm text-muted-foreground">Unresolved</span>
               <span className="text-lg font-bold text-red-600">
-                {errors.filter(e => !e.resolved).length}
+                {issues.filter(i => !i.resolved).length}
               </span>
             </div>
           </CardContent>
@@ -177,9 +205,9 @@ export function ErrorLogs() {
         <Card>
           <CardContent className="p-4">
             <div className="flex items-center justify-between">
-              <span className="text-sm text-muted-foreground">Resolved</span>
-              <span className="text-lg font-bold text-green-600">
-                {errors.filter(e => e.resolved).length}
+              <span className="text-sm text-muted-foreground">Occurrences</span>
+              <span className="text-lg font-bold">
+                {issues.reduce((total, i) => total + i.count, 0)}
               </span>
             </div>
           </CardContent>
@@ -189,7 +217,7 @@ export function ErrorLogs() {
       {/* 错误列表 */}
       <Card>
         <CardContent className="p-0">
-          {filteredErrors.length === 0 ? (
+          {issues.length === 0 ? (
             <div className="text-center py-8">
               <AlertTriangle className="h-8 w-8 text-muted-foreground mx-auto mb-2" />
               <p className="text-muted-foreground">
@@ -199,24 +227,35 @@ export function ErrorLogs() {
             </div>
           ) : (
             <div className="divide-y">
-              {filteredErrors.map((errorLog) => (
-                <div key={errorLog.id} className="p-4 hover:bg-muted/50">
+              {issues.map((issue) => (
+                <div key={issue.id} className="p-4 hover:bg-muted/50">
                   <div className="flex items-start justify-between">
                     <div className="flex-1 space-y-2">
                       {/* 错误标题和状态 */}
                       <div className="flex items-center space-x-2">
-                        <Badge variant={getErrorLevelColor(errorLog.level)}>
-                          {errorLog.level}
+                        <Badge variant={getErrorLevelColor(issue.level)}>
+                          {issue.level}
                         </Badge>
-                        <Badge variant={getSourceColor(errorLog.source)}>
-                          {errorLog.source}
+                        <Badge variant={getSourceColor(issue.source)}>
+                          {issue.source}
                         </Badge>
-                        {errorLog.platform_name && (
+                        {issue.platform_name && (
                           <Badge variant="outline">
-                            {errorLog.platform_name}
+                            {issue.platform_name}
                           </Badge>
                         )}
-                        {errorLog.resolved ? (
+                        {issue.error_code && (
+                          <Badge variant="outline">
+                            {issue.error_code}
+                          </Badge>
+                        )}
+                        {issue.error_type === 'credentials_expired' && (
+                          <Badge variant="warning">
+                            <KeyRound className="h-3 w-3 mr-1" />
+                            Cookie expired
+                          </Badge>
+                        )}
+                        {issue.resolved ? (
                           <Badge variant="success">
                             <Check className="h-3 w-3 mr-1" />
                             Resolved
@@ -230,44 +269,40 @@ export function ErrorLogs() {
                       </div>
 
                       {/* 错误标题 */}
-                      <h4 className="font-medium">{errorLog.title}</h4>
+                      <h4 className="font-medium">{issue.title}</h4>
                       
                       {/* 错误信息 */}
                       <ErrorDisplay 
-                        error={errorLog.message} 
+                        error={issue.message} 
                         compact={true}
                         className="mt-2"
                       />
 
                       {/* 元数据 */}
                       <div className="flex items-center space-x-4 text-xs text-muted-foreground">
-                        <span>{formatDate(errorLog.created_at)}</span>
-                        {errorLog.page && (
-                          <span>Page: {errorLog.page.title}</span>
-                        )}
-                        {errorLog.job && (
-                          <span>Job ID: {errorLog.job.id}</span>
-                        )}
+                        <span className="font-medium text-foreground">{issue.count}×</span>
+                        <span>First seen {formatDate(issue.first_seen_at)}</span>
+                        <span>Last seen {formatDate(issue.last_seen_at)}</span>
                       </div>
 
                       {/* 解决时间 */}
-                      {errorLog.resolved && errorLog.resolved_at && (
+                      {issue.resolved && issue.resolved_at && (
                         <div className="text-xs text-green-600">
-                          Resolved at {formatDate(errorLog.resolved_at)}
+                          Resolved at {formatDate(issue.resolved_at)}
                         </div>
                       )}
                     </div>
 
                     {/* 操作按钮 */}
                     <div className="ml-4">
-                      {!errorLog.resolved && (
+                      {!issue.resolved && (
                         <Button
-                          onClick={() => handleResolveError(errorLog.id)}
-                          disabled={resolving === errorLog.id}
+                          onClick={() => handleResolveIssue(issue.id)}
+                          disabled={resolving === issue.id}
                           size="sm"
                           variant="outline"
                         >
-                          {resolving === errorLog.id ? (
+                          {resolving === issue.id ? (
                             <RefreshCw className="h-3 w-3 animate-spin" />
                           ) : (
                             <Check className="h-3 w-3" />
@@ -277,29 +312,62 @@ export function ErrorLogs() {
                     </div>
                   </div>
 
-                  {/* 堆栈信息（可展开） */}
-                  {errorLog.stack_trace && (
-                    <details className="mt-2">
-                      <summary className="text-xs text-muted-foreground cursor-pointer hover:text-foreground">
-                        Stack Trace
-                      </summary>
-                      <pre className="mt-2 text-xs bg-muted p-2 rounded overflow-x-auto">
-                        {errorLog.stack_trace}
-                      </pre>
-                    </details>
-                  )}
+                  {/* 各次出现（展开时加载） */}
+                  <details className="mt-2" onToggle={(e) => e.currentTarget.open && fetchOccurrences(issue.id)}>
+                    <summary className="text-xs text-muted-foreground cursor-pointer hover:text-foreground">
+                      Occurrences
+                    </summary>
+                    {!occurrences[issue.id] ? (
+                      <div className="mt-2 text-xs text-muted-foreground">Loading...</div>
+                    ) : (
+                      <div className="mt-2 space-y-2">
+                        {occurrences[issue.id].map((errorLog) => (
+                          <div key={errorLog.id} className="border rounded-md p-2 space-y-1">
+                            <div className="flex items-center space-x-4 text-xs text-muted-foreground">
+                              <span>{formatDate(errorLog.created_at)}</span>
+                              {errorLog.platform_name && (
+                                <span>{errorLog.platform_name}</span>
+                              )}
+                              {errorLog.page && (
+                                <span>Page: {errorLog.page.title}</span>
+                              )}
+                              {errorLog.job && (
+                                <span>Job ID: {errorLog.job.id}</span>
+                              )}
+                              {errorLog.request_id && (
+                                <span className="font-mono">Request ID: {errorLog.request_id}</span>
+                              )}
+                            </div>
+                            <ErrorDisplay error={errorLog.message} compact={true} />
+
+                            {/* 堆栈信息（可展开） */}
+                            {errorLog.stack_trace && (
+                              <details>
+                                <summary className="text-xs text-muted-foreground cursor-pointer hover:text-foreground">
+                                  Stack Trace
+                                </summary>
+                                <pre className="mt-2 text-xs bg-muted p-2 rounded overflow-x-auto">
+                                  {errorLog.stack_trace}
+                                </pre>
+                              </details>
+                            )}
 
-                  {/* 上下文信息 */}
-                  {errorLog.context && (
-                    <details className="mt-2">
-                      <summary className="text-xs text-muted-foreground cursor-pointer hover:text-foreground">
-                        Context
-                      </summary>
-                      <pre className="mt-2 text-xs bg-muted p-2 rounded overflow-x-auto">
-                        {JSON.stringify(JSON.parse(errorLog.context), null, 2)}
-                      </pre>
-                    </details>
-                  )}
+                            {/* 上下文信息 */}
+                            {errorLog.context && (
+                              <details>
+                                <summary className="text-xs text-muted-foreground cursor-pointer hover:text-foreground">
+                                  Context
+                                </summary>
+                                <pre className="mt-2 text-xs bg-muted p-2 rounded overflow-x-auto">
+                                  {JSON.stringify(JSON.parse(errorLog.context), null, 2)}
+                                </pre>
+                              </details>
+                            )}
+                          </div>
+                        ))}
+                      </div>
+                    )}
+                  </details>
                 </div>
               ))}
             </div>
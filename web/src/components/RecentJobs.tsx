@@ -21,9 +21,9 @@ export function RecentJobs({ limit = 5, showHeader = true, onViewAll, statusFilt
   const [error, setError] = useState<string | null>(null)
   const [republishingJobs, setRepublishingJobs] = useState<Set<number>>(new Set())
 
-  const fetchJobs = async () => {
+  const fetchJobs = async (quiet: boolean = false) => {
     try {
-      setLoading(true)
+      if (!quiet) setLoading(true)
       setError(null)
       const data = await dashboardApi.getRecentJobs(limit)
       let filteredJobs = data
@@ -43,10 +43,20 @@ export function RecentJobs({ limit = 5, showHeader = true, onViewAll, statusFilt
     fetchJobs()
   }, [limit, statusFilter])
 
+  // Refresh as jobs change state on the server
+  useEffect(() => {
+    return dashboardApi.subscribeEvents(event => {
+      if (event.type === 'job_status') fetchJobs(true)
+    })
+  }, [limit, statusFilter])
+
   const handleRepublish = async (jobId: number) => {
     try {
       setRepublishingJobs(prev => new Set(prev).add(jobId))
-      await dashboardApi.republishJob(jobId)
+      const response = await dashboardApi.republishJob(jobId)
+      if (response.action === 'unchanged' && window.confirm('Content has not changed since the last publish. Republish anyway?')) {
+        await dashboardApi.republishJob(jobId, true)
+      }
       await fetchJobs() // Refresh the jobs list
     } catch (err) {
       console.error('Error republishing job:', err)
@@ -60,13 +70,32 @@ export function RecentJobs({ limit = 5, showHeader = true, onViewAll, statusFilt
     }
   }
 
+  const handlePromote = async (jobId: number) => {
+    try {
+      setRepublishingJobs(prev => new Set(prev).add(jobId))
+      await dashboardApi.promoteDraft(jobId)
+      await fetchJobs() // Refresh the jobs list
+    } catch (err) {
+      console.error('Error publishing draft:', err)
+      setError('Failed to publish draft')
+    } finally {
+      setRepublishingJobs(prev => {
+        const newSet = new Set(prev)
+        newSet.delete(jobId)
+        return newSet
+      })
+    }
+  }
+
   const getStatusIcon = (status: string) => {
     switch (status.toLowerCase()) {
       case 'completed':
         return <CheckCircle className="h-4 w-4 text-green-600" />
       case 'failed':
+      case 'quarantined':
         return <XCircle className="h-4 w-4 text-red-600" />
       case 'pending':
+      case 'paused':
         return <Clock className="h-4 w-4 text-yellow-600" />
       default:
         return <AlertCircle className="h-4 w-4 text-gray-600" />
@@ -76,8 +105,10 @@ export function RecentJobs({ limit = 5, showHeader = true, onViewAll, statusFilt
   const getStatusColor = (status: string) => {
     switch (status.toLowerCase()) {
       case 'completed': return 'success'
-      case 'failed': return 'destructive'
-      case 'pending': return 'warning'
+      case 'failed':
+      case 'quarantined': return 'destructive'
+      case 'pending':
+      case 'paused': return 'warning'
       default: return 'secondary'
     }
   }
@@ -123,7 +154,7 @@ export function RecentJobs({ limit = 5, showHeader = true, onViewAll, statusFilt
         <CardContent>
           <div className="text-center py-4">
             <p className="text-destructive text-sm">{error}</p>
-            <Button onClick={fetchJobs} variant="outline" size="sm" className="mt-2">
+            <Button onClick={() => fetchJobs()} variant="outline" size="sm" className="mt-2">
               Retry
             </Button>
           </div>
@@ -185,7 +216,23 @@ export function RecentJobs({ limit = 5, showHeader = true, onViewAll, statusFilt
                         Published: {formatDate(job.published_at)}
                       </span>
                     )}
+                    {job.url && (
+                      <a
+                        href={job.url}
+                        target="_blank"
+                        rel="noopener noreferrer"
+                        className="flex items-center hover:underline"
+                      >
+                        <ExternalLink className="h-3 w-3 mr-1" />
+                        View post
+                      </a>
+                    )}
                   </div>
+                  {job.error_code && (
+                    <Badge variant="outline" className="mt-2 text-xs">
+                      {job.error_code}
+                    </Badge>
+                  )}
                   {job.error && (
                     <ErrorDisplay 
                       error={job.error} 
@@ -193,9 +240,29 @@ export function RecentJobs({ limit = 5, showHeader = true, onViewAll, statusFilt
                       className="mt-2"
                     />
                   )}
+                  {job.hook_results?.filter(result => !result.passed || result.message).map((result, index) => (
+                    <div key={index} className="flex items-center space-x-2 mt-1 text-xs">
+                      <Badge variant={result.passed ? 'secondary' : 'destructive'} className="text-xs">
+                        {result.hook}
+                      </Badge>
+                      <span className="text-muted-foreground truncate">{result.message}</span>
+                    </div>
+                  ))}
                 </div>
                 <div className="flex flex-col items-end space-y-1">
                   <div className="flex items-center space-x-2">
+                    {job.status === 'draft' && (
+                      <Button
+                        variant="outline"
+                        size="sm"
+                        onClick={() => handlePromote(job.id)}
+                        disabled={republishingJobs.has(job.id)}
+                        className="h-6 px-2 text-xs"
+                      >
+                        <Send className="h-3 w-3 mr-1" />
+                        Publish Draft
+                      </Button>
+                    )}
                     <Button
                       variant="outline"
                       size="sm"
@@ -2,10 +2,10 @@ import { useEffect, useState } from 'react'
 import { Card, CardContent, CardHeader, CardTitle } from '@/components/ui/card'
 import { Badge } from '@/components/ui/badge'
 import { Button } from '@/components/ui/button'
-import { FileText, ExternalLink, Clock, Calendar } from 'lucide-react'
+import { FileText, ExternalLink, Clock, Calendar, ListChecks, BarChart3, Lock, Unlock } from 'lucide-react'
 import { dashboardApi } from '@/services/api'
 import { formatDate } from '@/lib/utils'
-import type { NotionPage } from '@/types/dashboard'
+import type { NotionPage, LintReport, PostMetric } from '@/types/dashboard'
 
 interface RecentPagesProps {
   limit?: number
@@ -17,6 +17,10 @@ export function RecentPages({ limit = 5, showHeader = true, onViewAll }: RecentP
   const [pages, setPages] = useState<NotionPage[]>([])
   const [loading, setLoading] = useState(true)
   const [error, setError] = useState<string | null>(null)
+  const [lintReports, setLintReports] = useState<Record<string, LintReport[]>>({})
+  const [lintingPages, setLintingPages] = useState<Set<string>>(new Set())
+  const [pageMetrics, setPageMetrics] = useState<Record<string, PostMetric[]>>({})
+  const [lockingPages, setLockingPages] = useState<Set<string>>(new Set())
 
   const fetchPages = async () => {
     try {
@@ -36,6 +40,65 @@ export function RecentPages({ limit = 5, showHeader = true, onViewAll }: RecentP
     fetchPages()
   }, [limit])
 
+  const handleLint = async (pageId: string) => {
+    try {
+      setLintingPages(prev => new Set(prev).add(pageId))
+      const data = await dashboardApi.lintPage(pageId)
+      setLintReports(prev => ({ ...prev, [pageId]: data.reports }))
+    } catch (err) {
+      console.error('Error linting page:', err)
+      setError('Failed to lint page')
+    } finally {
+      setLintingPages(prev => {
+        const newSet = new Set(prev)
+        newSet.delete(pageId)
+        return newSet
+      })
+    }
+  }
+
+  const handleStats = async (pageId: string) => {
+    if (pageMetrics[pageId]) {
+      setPageMetrics(prev => {
+        const next = { ...prev }
+        delete next[pageId]
+        return next
+      })
+      return
+    }
+    try {
+      const metrics = await dashboardApi.getPageMetrics(pageId)
+      // Snapshots come oldest first, so the last one per platform is the latest
+      const latest = new Map<string, PostMetric>()
+      metrics.forEach(metric => latest.set(metric.platform_name, metric))
+      setPageMetrics(prev => ({ ...prev, [pageId]: Array.from(latest.values()) }))
+    } catch (err) {
+      console.error('Error fetching page metrics:', err)
+      setError('Failed to fetch page metrics')
+    }
+  }
+
+  const handleToggleLock = async (page: NotionPage) => {
+    try {
+      setLockingPages(prev => new Set(prev).add(page.notion_id))
+      if (page.locked_at) {
+        await dashboardApi.unlockPage(page.notion_id)
+      } else {
+        await dashboardApi.lockPage(page.notion_id)
+      }
+      await fetchPages()
+    } catch (err) {
+      console.error('Error changing page lock:', err)
+      setError('Failed to change page lock')
+    } finally {
+      setLockingPages(prev => {
+        const newSet = new Set(prev)
+        newSet.delete(page.notion_id)
+        return newSet
+      })
+    }
+  }
+
   const getStatusColor = (status: string) => {
     switch (status.toLowerCase()) {
       case 'done': return 'success'
@@ -124,6 +187,14 @@ export function RecentPages({ limit = 5, showHeader = true, onViewAll }: RecentP
                     <Badge variant={getStatusColor(page.status)}>
                       {page.status}
                     </Badge>
+                    {(page.hold || page.locked_at) && (
+                      <Badge
+                        variant="warning"
+                        title={page.lock_reason || (page.hold ? 'Hold is ticked in Notion' : 'Locked')}
+                      >
+                        held
+                      </Badge>
+                    )}
                   </div>
                   <div className="flex items-center space-x-4 text-xs text-muted-foreground">
                     {page.owner && (
@@ -156,7 +227,78 @@ export function RecentPages({ limit = 5, showHeader = true, onViewAll }: RecentP
                       )}
                     </div>
                   )}
+                  {lintReports[page.notion_id] && (
+                    <div className="mt-2 space-y-1 text-xs">
+                      {lintReports[page.notion_id].every(report => report.issues.length === 0) ? (
+                        <p className="text-green-600">Lint passed</p>
+                      ) : (
+                        lintReports[page.notion_id].map(report =>
+                          report.issues.map((issue, index) => (
+                            <div key={`${report.platform}-${index}`} className="flex items-center space-x-2">
+                              <Badge variant={issue.severity === 'error' ? 'destructive' : 'warning'} className="text-xs">
+                                {issue.severity}
+                              </Badge>
+                              <span className="text-muted-foreground">
+                                {report.platform}: {issue.message}
+                              </span>
+                            </div>
+                          ))
+                        )
+                      )}
+                    </div>
+                  )}
+                  {pageMetrics[page.notion_id] && (
+                    <div className="mt-2 space-y-1 text-xs text-muted-foreground">
+                      {pageMetrics[page.notion_id].length === 0 ? (
+                        <p>No stats collected yet</p>
+                      ) : (
+                        pageMetrics[page.notion_id].map(metric => (
+                          <div key={metric.platform_name} className="flex items-center space-x-2">
+                            <Badge variant="secondary" className="text-xs">
+                              {metric.platform_name}
+                            </Badge>
+                            <span>
+                              {metric.views} views · {metric.readers} readers · {metric.likes} likes · {metric.comments} comments · {metric.shares} shares
+                            </span>
+                          </div>
+                        ))
+                      )}
+                    </div>
+                  )}
                 </div>
+                <Button
+                  variant="outline"
+                  size="sm"
+                  onClick={() => handleStats(page.notion_id)}
+                  className="h-6 px-2 text-xs"
+                >
+                  <BarChart3 className="h-3 w-3 mr-1" />
+                  Stats
+                </Button>
+                <Button
+                  variant="outline"
+                  size="sm"
+                  onClick={() => handleLint(page.notion_id)}
+                  disabled={lintingPages.has(page.notion_id)}
+                  className="h-6 px-2 text-xs"
+                >
+                  <ListChecks className="h-3 w-3 mr-1" />
+                  {lintingPages.has(page.notion_id) ? 'Linting...' : 'Lint'}
+                </Button>
+                <Button
+                  variant="outline"
+                  size="sm"
+                  onClick={() => handleToggleLock(page)}
+                  disabled={lockingPages.has(page.notion_id)}
+                  className="h-6 px-2 text-xs"
+                >
+                  {page.locked_at ? (
+                    <Unlock className="h-3 w-3 mr-1" />
+                  ) : (
+                    <Lock className="h-3 w-3 mr-1" />
+                  )}
+                  {page.locked_at ? 'Unlock' : 'Lock'}
+                </Button>
                 {page.platforms && page.platforms.length > 0 && (
                   <div className="flex flex-col items-end space-y-1">
                     <span className="text-xs text-muted-foreground">
@@ -3,9 +3,36 @@ import type {
   DashboardSummary,
   PlatformStats,
   ErrorLog,
+  ErrorIssue,
+  IssueStatus,
   SystemStats,
   NotionPage,
   DistributionJob,
+  SyncRun,
+  PageSearchResult,
+  PageContent,
+  PublishBatch,
+  JobTransition,
+  JobLog,
+  LintReport,
+  LiveEvent,
+  CalendarDay,
+  PostMetric,
+  Trend,
+  TrendBucket,
+  TrendMetric,
+  RepublishResponse,
+  MediaRefreshResponse,
+  CoverageReport,
+  PageCoverage,
+  PageSimulation,
+  ContentVersion,
+  VersionDiff,
+  VersionRepublishResponse,
+  DraftPreviewResponse,
+  Material,
+  MaterialLibrary,
+  GraphQLResponse,
   ApiResponse
 } from '@/types/dashboard'
 
@@ -36,6 +63,12 @@ api.interceptors.response.use(
 )
 
 export const dashboardApi = {
+  // Fetch what a view needs in one GraphQL query; field errors come back next to the data
+  graphql: async <T>(query: string, variables?: Record<string, unknown>): Promise<GraphQLResponse<T>> => {
+    const response = await api.post<GraphQLResponse<T>>('/graphql', { query, variables })
+    return response.data
+  },
+
   // Get dashboard summary
   getSummary: async (): Promise<DashboardSummary> => {
     const response = await api.get<ApiResponse<DashboardSummary>>('/dashboard/summary')
@@ -48,10 +81,30 @@ export const dashboardApi = {
     return response.data.stats
   },
 
-  // Get recent errors
-  getRecentErrors: async (limit: number = 20): Promise<ErrorLog[]> => {
-    const response = await api.get<ApiResponse<ErrorLog[]>>(`/dashboard/recent-errors?limit=${limit}`)
-    return response.data.errors
+  // Get a metric of the jobs per bucket, with empty buckets filled in; from and to are RFC 3339 times
+  getTrends: async (
+    bucket: TrendBucket = 'day',
+    metric: TrendMetric = 'volume',
+    options: { from?: string; to?: string; platform?: string } = {}
+  ): Promise<Trend> => {
+    const queryParams = new URLSearchParams({ bucket, metric })
+    if (options.from) queryParams.append('from', options.from)
+    if (options.to) queryParams.append('to', options.to)
+    if (options.platform) queryParams.append('platform', options.platform)
+    const response = await api.get<{ trend: Trend }>(`/dashboard/trends?${queryParams}`)
+    return response.data.trend
+  },
+
+  // Get the error issues seen most recently, each grouping the occurrences of one error
+  getRecentErrors: async (limit: number = 20, status: IssueStatus = 'unresolved'): Promise<ErrorIssue[]> => {
+    const response = await api.get<{ issues: ErrorIssue[] }>(`/dashboard/recent-errors?limit=${limit}&status=${status}`)
+    return response.data.issues
+  },
+
+  // Get an error issue with its latest occurrences
+  getErrorIssue: async (issueId: number, limit: number = 20): Promise<{ issue: ErrorIssue; errors: ErrorLog[] }> => {
+    const response = await api.get<{ issue: ErrorIssue; errors: ErrorLog[] }>(`/dashboard/issues/${issueId}?limit=${limit}`)
+    return response.data
   },
 
   // Get system statistics
@@ -78,11 +131,55 @@ export const dashboardApi = {
     return response.data.pages
   },
 
+  // Get the content of a page, left out of page lists
+  getPageContent: async (pageId: string): Promise<PageContent> => {
+    const response = await api.get<PageContent>(`/pages/${pageId}/content`)
+    return response.data
+  },
+
+  // Re-fetch a single page from Notion
+  syncPage: async (pageId: string, force: boolean = true): Promise<{
+    message: string
+    outcome: 'created' | 'updated' | 'skipped'
+    page: NotionPage
+  }> => {
+    const response = await api.post(`/notion/sync/${pageId}?force=${force}`)
+    return response.data
+  },
+
+  // Get sync run history, newest first
+  getSyncHistory: async (limit: number = 20, source?: string): Promise<{
+    runs: SyncRun[]
+    last_successful: SyncRun | null
+  }> => {
+    const queryParams = new URLSearchParams({ limit: limit.toString() })
+    if (source) queryParams.append('source', source)
+    const response = await api.get<{
+      runs: SyncRun[]
+      last_successful: SyncRun | null
+    }>(`/notion/sync-history?${queryParams}`)
+    return response.data
+  },
+
+  // List the synced pages with blocks that will not render completely, or every page with all
+  getContentCoverage: async (all: boolean = false): Promise<CoverageReport> => {
+    const response = await api.get<CoverageReport>(`/notion/coverage${all ? '?all=true' : ''}`)
+    return response.data
+  },
+
+  // Get the content size and block coverage of a page
+  getPageCoverage: async (pageId: string): Promise<PageCoverage> => {
+    const response = await api.get<PageCoverage>(`/notion/coverage/${pageId}`)
+    return response.data
+  },
+
   // Get jobs with pagination and filtering
   getJobs: async (params: {
     limit?: number
     offset?: number
     status?: string
+    publishId?: string
+    metadata?: Record<string, string>
   } = {}): Promise<{
     jobs: DistributionJob[]
     total: number
@@ -93,6 +190,10 @@ export const dashboardApi = {
     if (params.limit) queryParams.append('limit', params.limit.toString())
     if (params.offset) queryParams.append('offset', params.offset.toString())
     if (params.status) queryParams.append('status', params.status)
+    if (params.publishId) queryParams.append('publish_id', params.publishId)
+    Object.entries(params.metadata ?? {}).forEach(([key, value]) => {
+      queryParams.append('metadata', `${key}:${value}`)
+    })
     
     const response = await api.get<{
       jobs: DistributionJob[]
@@ -103,21 +204,246 @@ export const dashboardApi = {
     return response.data
   },
 
+  // Full-text search over page titles, tags and content
+  searchPages: async (params: {
+    q: string
+    status?: string
+    platform?: string
+    from?: string
+    to?: string
+    limit?: number
+    offset?: number
+  }): Promise<{
+    pages: PageSearchResult[]
+    total: number
+    limit: number
+    offset: number
+  }> => {
+    const queryParams = new URLSearchParams({ q: params.q })
+    if (params.status) queryParams.append('status', params.status)
+    if (params.platform) queryParams.append('platform', params.platform)
+    if (params.from) queryParams.append('from', params.from)
+    if (params.to) queryParams.append('to', params.to)
+    if (params.limit) queryParams.append('limit', params.limit.toString())
+    if (params.offset) queryParams.append('offset', params.offset.toString())
+
+    const response = await api.get<{
+      pages: PageSearchResult[]
+      total: number
+      limit: number
+      offset: number
+    }>(`/pages/search?${queryParams}`)
+    return response.data
+  },
+
+  // Check a page against the lint rules, for one platform or all of the page's platforms
+  lintPage: async (pageId: string, platform?: string): Promise<{ passed: boolean; reports: LintReport[] }> => {
+    const query = platform ? `?platform=${encodeURIComponent(platform)}` : ''
+    const response = await api.get<{ passed: boolean; reports: LintReport[] }>(`/publisher/lint/${pageId}${query}`)
+    return response.data
+  },
+
+  // Hold a page back from scheduled publishing until it is unlocked
+  lockPage: async (pageId: string, reason?: string): Promise<{ message: string; page_id: string; locked_at: string; lock_reason?: string }> => {
+    const response = await api.put(`/publisher/lock/${pageId}`, { reason })
+    return response.data
+  },
+
+  // Release the lock of a page; pages with the Hold checkbox ticked stay held
+  unlockPage: async (pageId: string): Promise<{ message: string; page_id: string; held: boolean }> => {
+    const response = await api.delete(`/publisher/lock/${pageId}`)
+    return response.data
+  },
+
+  // Transform and validate a page for every enabled platform without publishing it
+  simulatePage: async (pageId: string): Promise<PageSimulation> => {
+    const response = await api.get<PageSimulation>(`/publisher/simulate/${pageId}`)
+    return response.data
+  },
+
+  // Content versions published for a page, without their content and output
+  getVersions: async (pageId: string, platform?: string): Promise<ContentVersion[]> => {
+    const response = await api.get<{ versions: ContentVersion[] }>(`/publisher/versions/${pageId}`, {
+      params: platform ? { platform } : undefined
+    })
+    return response.data.versions
+  },
+
+  getVersion: async (pageId: string, versionId: number): Promise<ContentVersion> => {
+    const response = await api.get<ContentVersion>(`/publisher/versions/${pageId}/${versionId}`)
+    return response.data
+  },
+
+  // Diff two versions, or a version with the current page when to is left out
+  diffVersions: async (pageId: string, from: number, to?: number): Promise<VersionDiff> => {
+    const response = await api.get<VersionDiff>(`/publisher/versions/${pageId}/diff`, {
+      params: to ? { from, to } : { from }
+    })
+    return response.data
+  },
+
+  republishVersion: async (pageId: string, versionId: number): Promise<VersionRepublishResponse> => {
+    const response = await api.post<VersionRepublishResponse>(`/publisher/versions/${pageId}/${versionId}/republish`)
+    return response.data
+  },
+
+  // Stream job status changes, sync progress and new errors; returns a function that closes the stream.
+  // The browser reconnects on its own when the connection drops.
+  subscribeEvents: (onEvent: (event: LiveEvent) => void): (() => void) => {
+    const source = new EventSource('/api/v1/events')
+    const listener = (message: MessageEvent) => {
+      try {
+        onEvent(JSON.parse(message.data) as LiveEvent)
+      } catch (err) {
+        console.error('Invalid live event:', err)
+      }
+    }
+    const types: LiveEvent['type'][] = ['job_status', 'sync_progress', 'error']
+    types.forEach(type => source.addEventListener(type, listener))
+    return () => source.close()
+  },
+
+  // Get the status changes of a job, oldest first
+  getJobHistory: async (jobId: number): Promise<JobTransition[]> => {
+    const response = await api.get<{ transitions: JobTransition[] }>(`/dashboard/jobs/${jobId}/history`)
+    return response.data.transitions
+  },
+
+  // Get the log lines of a job, optionally only those after a line ID
+  getJobLogs: async (jobId: number, after: number = 0): Promise<{ status: string; logs: JobLog[] }> => {
+    const response = await api.get<{ status: string; logs: JobLog[] }>(`/jobs/${jobId}/logs?after=${after}`)
+    return response.data
+  },
+
+  // Follow the log of a running job; onEnd is called with the final status once the job finishes.
+  // Returns a function that stops following.
+  followJobLogs: (jobId: number, onLog: (log: JobLog) => void, onEnd?: (status: string) => void): (() => void) => {
+    const source = new EventSource(`/api/v1/jobs/${jobId}/logs?follow=true`)
+    source.addEventListener('log', (message: MessageEvent) => onLog(JSON.parse(message.data) as JobLog))
+    source.addEventListener('end', (message: MessageEvent) => {
+      source.close()
+      onEnd?.((JSON.parse(message.data) as { status: string }).status)
+    })
+    return () => source.close()
+  },
+
+  // Get planned and completed publications per day and platform for a range of months (YYYY-MM)
+  getCalendar: async (from?: string, to?: string, tz?: string): Promise<{
+    from: string
+    to: string
+    timezone: string
+    days: CalendarDay[]
+  }> => {
+    const queryParams = new URLSearchParams()
+    if (from) queryParams.append('from', from)
+    if (to) queryParams.append('to', to)
+    if (tz) queryParams.append('tz', tz)
+    const response = await api.get<{
+      from: string
+      to: string
+      timezone: string
+      days: CalendarDay[]
+    }>(`/dashboard/calendar?${queryParams}`)
+    return response.data
+  },
+
+  // Get the daily stats snapshots of a page on each platform, oldest first
+  getPageMetrics: async (pageId: string): Promise<PostMetric[]> => {
+    const response = await api.get<{ metrics: PostMetric[] }>(`/dashboard/page-metrics/${pageId}`)
+    return response.data.metrics
+  },
+
   // Update statistics
   updateStats: async (): Promise<{ message: string }> => {
     const response = await api.post<{ message: string }>('/dashboard/update-stats')
     return response.data
   },
 
+  // Resolve an error issue and all its occurrences
+  resolveIssue: async (issueId: number): Promise<{ message: string }> => {
+    const response = await api.post<{ message: string }>(`/dashboard/issues/${issueId}/resolve`)
+    return response.data
+  },
+
   // Resolve error
   resolveError: async (errorId: number): Promise<{ message: string }> => {
     const response = await api.post<{ message: string }>(`/dashboard/resolve-error/${errorId}`)
     return response.data
   },
 
-  // Republish job
-  republishJob: async (jobId: number): Promise<{ message: string; result?: any }> => {
-    const response = await api.post<{ message: string; result?: any }>(`/dashboard/republish-job/${jobId}`)
+  // Republish job; unchanged content is skipped unless forced
+  republishJob: async (jobId: number, force = false): Promise<RepublishResponse> => {
+    const response = await api.post<RepublishResponse>(`/dashboard/republish-job/${jobId}`, null, {
+      params: force ? { force: true } : undefined
+    })
+    return response.data
+  },
+
+  // Upload the images of a job's page again and patch them into its draft or post
+  refreshJobMedia: async (jobId: number): Promise<MediaRefreshResponse> => {
+    const response = await api.post<MediaRefreshResponse>(`/dashboard/refresh-media/${jobId}`)
+    return response.data
+  },
+
+  // Get the preview link of a saved draft and its QR code
+  previewDraft: async (jobId: number): Promise<DraftPreviewResponse> => {
+    const response = await api.get<DraftPreviewResponse>(`/publisher/drafts/${jobId}/preview`)
+    return response.data
+  },
+
+  // Send a saved draft to a reviewer's WeChat as a preview message
+  sendDraftPreview: async (jobId: number, recipient?: string): Promise<DraftPreviewResponse> => {
+    const response = await api.post<DraftPreviewResponse>(`/publisher/drafts/${jobId}/preview/send`, { recipient })
+    return response.data
+  },
+
+  // List a page of a platform's permanent material library
+  listMaterials: async (
+    platform: string,
+    params: { type?: 'image' | 'video' | 'voice'; offset?: number; count?: number } = {}
+  ): Promise<MaterialLibrary> => {
+    const response = await api.get<MaterialLibrary>(`/publisher/platforms/${platform}/materials`, { params })
+    return response.data
+  },
+
+  // Upload an image or cover thumb as permanent material
+  uploadMaterial: async (platform: string, file: File, type: 'image' | 'thumb' = 'image'): Promise<{ message: string; material: Material }> => {
+    const form = new FormData()
+    form.append('media', file)
+    form.append('type', type)
+    const response = await api.post<{ message: string; material: Material }>(`/publisher/platforms/${platform}/materials`, form)
+    return response.data
+  },
+
+  // Delete a permanent material; the default thumb is only deleted when forced
+  deleteMaterial: async (platform: string, mediaId: string, force = false): Promise<{ message: string }> => {
+    const response = await api.delete<{ message: string }>(`/publisher/platforms/${platform}/materials/${mediaId}`, {
+      params: force ? { force: true } : undefined
+    })
+    return response.data
+  },
+
+  // Publish a reviewed draft
+  promoteDraft: async (jobId: number): Promise<{ message: string; job: DistributionJob; result?: any }> => {
+    const response = await api.post<{ message: string; job: DistributionJob; result?: any }>(`/publisher/promote/${jobId}`)
+    return response.data
+  },
+
+  // Publish a selection of pages in the background
+  publishBatch: async (selection: {
+    page_ids?: string[]
+    tag?: string
+    from?: string
+    to?: string
+    platform?: string
+  }): Promise<{ message: string; batch_id: number; batch: PublishBatch }> => {
+    const response = await api.post<{ message: string; batch_id: number; batch: PublishBatch }>('/publisher/publish-batch', selection)
+    return response.data
+  },
+
+  // Get the progress of a publish batch
+  getPublishBatch: async (batchId: number): Promise<{ batch: PublishBatch; progress: number }> => {
+    const response = await api.get<{ batch: PublishBatch; progress: number }>(`/publisher/batch/${batchId}`)
     return response.data
   },
 }
@@ -11,6 +11,7 @@ export interface DashboardSummary {
   last_publish_time?: string
   unresolved_errors_count: number
   avg_process_time_today: number
+  p95_process_time_today: number
   updated_at: string
 }
 
@@ -34,6 +35,10 @@ export interface PlatformStats {
   failed_jobs: number
   pending_jobs: number
   avg_process_time: number
+  p50_process_time: number
+  p95_process_time: number
+  // average milliseconds per stage: transform, media, upload, publish
+  avg_stage_durations: Record<string, number>
   last_success_at?: string
   last_failure_at?: string
   error_count: number
@@ -47,27 +52,64 @@ export interface NotionPage {
   notion_id: string
   title: string
   en_title: string
-  content: string
+  // left out of page lists unless asked for with fields, read with getPageContent
+  content?: string
   summary: string
   tags: string[]
   status: string
   post_date?: string
+  unpublish_at?: string
   owner: string
   platforms: string[]
   content_type: string[]
+  cover_url?: string
+  series?: string
+  // held pages are synced but not published by the scheduler, by their Hold checkbox or a lock
+  hold: boolean
+  locked_at?: string
+  lock_reason?: string
+  source: string
   properties: string
   last_modified: string
   created_at: string
   updated_at: string
 }
 
+export interface PageContent {
+  page_id: string
+  title: string
+  source: string
+  // Notion block JSON, or Markdown for other sources
+  content: string
+  last_modified: string
+}
+
+export interface PageSearchResult extends NotionPage {
+  rank: number
+  snippet: string
+}
+
+export type ErrorCode = 'AUTH_EXPIRED' | 'RATE_LIMITED' | 'CONTENT_INVALID' | 'NETWORK' | 'PLATFORM_5XX'
+
 export interface DistributionJob {
   id: number
   page_id: number
   platform_id: number
   status: string
   content: string
+  // fingerprint of the page content the job published
+  content_hash?: string
   error: string
+  error_code?: ErrorCode
+  publish_id: string
+  // public URL of the post once it is live
+  url?: string
+  metadata: Record<string, string>
+  hook_results?: HookResult[]
+  started_at?: string
+  finished_at?: string
+  // milliseconds spent in each stage of the latest run
+  stage_durations?: Record<string, number>
   published_at?: string
   created_at: string
   updated_at: string
@@ -75,6 +117,74 @@ export interface DistributionJob {
   platform: Platform
 }
 
+export interface DraftReview {
+  job_id: number
+  platform: string
+  preview: {
+    draft_id: string
+    title: string
+    // temporary link to the draft
+    url: string
+  }
+  sent_to?: string
+}
+
+export interface DraftPreviewResponse {
+  message?: string
+  review: DraftReview
+  // data URL of a PNG QR code of the preview link
+  qr_code: string
+}
+
+export interface Material {
+  media_id: string
+  name: string
+  url?: string
+  updated_at: string
+}
+
+export interface MaterialEntry extends Material {
+  // the configured default_thumb_media_id
+  default_thumb: boolean
+  // uploaded by Ripple while publishing and reused for the same image
+  cached: boolean
+  last_used_at?: string
+}
+
+export interface MaterialLibrary {
+  platform: string
+  type: 'image' | 'video' | 'voice'
+  total: number
+  offset: number
+  items: MaterialEntry[]
+}
+
+export interface RepublishResponse {
+  message: string
+  // unchanged when the content hasn't changed since the job published it
+  action: 'republished' | 'unchanged' | 'failed'
+  content_changed: boolean
+  job: Pick<DistributionJob, 'id' | 'status' | 'error' | 'published_at'>
+  result?: any
+}
+
+export interface MediaRefreshResponse {
+  message: string
+  // image references pointed at new uploads
+  refreshed: number
+  // images that could not be uploaded again
+  failed: number
+  job: Pick<DistributionJob, 'id' | 'status' | 'publish_id' | 'url'>
+}
+
+export interface HookResult {
+  hook: string
+  stage: 'pre_transform' | 'pre_publish' | 'post_publish'
+  passed: boolean
+  message?: string
+  duration_ms: number
+}
+
 export interface ErrorLog {
   id: number
   level: string
@@ -82,10 +192,16 @@ export interface ErrorLog {
   platform_name: string
   page_id?: number
   job_id?: number
+  error_type?: string
+  error_code?: ErrorCode
+  fingerprint: string
+  issue_id?: number
   title: string
   message: string
   stack_trace: string
   context: string
+  // ID of the API request that caused the error, also in the server logs
+  request_id?: string
   resolved: boolean
   resolved_at?: string
   created_at: string
@@ -94,6 +210,28 @@ export interface ErrorLog {
   job?: DistributionJob
 }
 
+// ErrorIssue groups the occurrences of the same error
+export interface ErrorIssue {
+  id: number
+  fingerprint: string
+  level: string
+  source: string
+  platform_name: string
+  error_type?: string
+  error_code?: ErrorCode
+  title: string
+  message: string
+  count: number
+  first_seen_at: string
+  last_seen_at: string
+  resolved: boolean
+  resolved_at?: string
+  created_at: string
+  updated_at: string
+}
+
+export type IssueStatus = 'unresolved' | 'resolved' | 'all'
+
 export interface SystemStats {
   id: number
   date: string
@@ -108,6 +246,155 @@ export interface SystemStats {
   updated_at: string
 }
 
+export interface SyncPageError {
+  page_id: string
+  title: string
+  error: string
+}
+
+export interface SyncRun {
+  id: number
+  source: string
+  status: 'running' | 'completed' | 'failed'
+  started_at: string
+  finished_at?: string
+  scanned: number
+  created: number
+  updated: number
+  skipped: number
+  failed: number
+  errors: SyncPageError[]
+  error: string
+  created_at: string
+  updated_at: string
+  // content metrics of the Notion pages the run read
+  content_bytes: number
+  blocks: number
+  images: number
+  degraded_blocks: number
+  unsupported_blocks: number
+  incomplete_pages: number
+}
+
+export interface PageCoverage {
+  page_id: string
+  title: string
+  status: string
+  bytes: number
+  blocks: number
+  characters: number
+  images: number
+  // blocks per Notion block type
+  types: Record<string, number>
+  // blocks kept only as plain paragraphs, e.g. callouts and toggles
+  degraded?: Record<string, number>
+  // blocks dropped altogether, e.g. bookmarks and embeds
+  unsupported?: Record<string, number>
+}
+
+export interface Violation {
+  field: string
+  rule: string
+  message: string
+}
+
+export interface PlatformSimulation {
+  platform: string
+  // whether the page is routed to the platform
+  routed: boolean
+  ready: boolean
+  output_bytes: number
+  // estimated images uploaded when publishing
+  images: number
+  violations: Violation[]
+  unsupported_blocks?: Record<string, number>
+  degraded_blocks?: Record<string, number>
+  error?: string
+}
+
+export interface PageSimulation {
+  page_id: string
+  title: string
+  // ready on every platform the page is routed to
+  ready: boolean
+  coverage?: Omit<PageCoverage, 'page_id' | 'title' | 'status'>
+  platforms: PlatformSimulation[]
+}
+
+export interface ContentVersion {
+  id: number
+  page_id: number
+  platform_id: number
+  // numbered per page and platform
+  version: number
+  job_id: number
+  job_status: string
+  content_hash: string
+  title: string
+  en_title?: string
+  summary?: string
+  seo_description?: string
+  tags: string[]
+  owner?: string
+  post_date?: string
+  cover_url?: string
+  series?: string
+  content_type: string[]
+  // left out of version listings
+  properties?: string
+  content?: string
+  output?: string
+  // the version republished to record this one
+  restored_from?: number
+  created_at: string
+  platform: Platform
+}
+
+export interface VersionRef {
+  // 0 for the current page
+  id: number
+  version?: number
+  platform?: string
+  job_id?: number
+}
+
+export interface TextDiff {
+  added: number
+  removed: number
+  unified: string
+}
+
+export interface VersionDiff {
+  page_id: string
+  from: VersionRef
+  to: VersionRef
+  changed: boolean
+  fields: { field: string; from: string; to: string }[]
+  content: TextDiff
+  // left out when comparing with the current page
+  output?: TextDiff
+}
+
+export interface VersionRepublishResponse {
+  message: string
+  report: {
+    version_id: number
+    version: number
+    platform: string
+    action: 'republished' | 'failed'
+    job: DistributionJob
+    result?: any
+  }
+}
+
+export interface CoverageReport {
+  pages: PageCoverage[]
+  scanned: number
+  incomplete: number
+  unsupported: Record<string, number>
+  degraded: Record<string, number>
+}
+
 export interface MetricsSample {
   id: number
   metric_name: string
@@ -124,4 +411,130 @@ export interface ApiResponse<T> {
 
 export interface ErrorResponse {
   error: string
-}
\ No newline at end of file
+}
+
+export interface GraphQLError {
+  message: string
+  path?: (string | number)[]
+}
+
+export interface GraphQLResponse<T> {
+  data?: T | null
+  errors?: GraphQLError[]
+}
+export interface PublishBatchItem {
+  page_id: string
+  title: string
+  status: 'pending' | 'completed' | 'failed' | 'skipped'
+  platforms?: Record<string, string>
+  error?: string
+}
+
+export interface PublishBatch {
+  id: number
+  status: 'pending' | 'running' | 'completed'
+  platform: string
+  filter: Record<string, string>
+  total: number
+  completed: number
+  failed: number
+  skipped: number
+  items: PublishBatchItem[]
+  started_at?: string
+  finished_at?: string
+  created_at: string
+  updated_at: string
+}
+
+export interface LintIssue {
+  rule: string
+  severity: 'error' | 'warning'
+  message: string
+}
+
+export interface LintReport {
+  platform: string
+  passed: boolean
+  issues: LintIssue[]
+}
+
+export interface JobTransition {
+  id: number
+  job_id: number
+  from_status: string
+  to_status: string
+  error: string
+  created_at: string
+}
+
+export interface JobLog {
+  id: number
+  job_id: number
+  level: 'debug' | 'info' | 'warn' | 'error'
+  message: string
+  fields: Record<string, string>
+  created_at: string
+}
+
+export interface JobStatusEvent {
+  job_id: number
+  page_id: number
+  platform_id: number
+  from: string
+  to: string
+  error?: string
+}
+
+export type LiveEvent =
+  | { type: 'job_status'; data: JobStatusEvent; time: string }
+  | { type: 'sync_progress'; data: SyncRun; time: string }
+  | { type: 'error'; data: ErrorLog; time: string }
+
+export interface CalendarEntry {
+  page_id: number
+  notion_id: string
+  title: string
+  kind: 'planned' | 'scheduled' | 'completed'
+  job_id?: number
+  at: string
+}
+
+export interface CalendarDay {
+  date: string
+  platforms: Record<string, { planned: CalendarEntry[]; completed: CalendarEntry[] }>
+}
+
+export type TrendBucket = 'hour' | 'day' | 'week'
+export type TrendMetric = 'success_rate' | 'volume' | 'avg_time'
+
+export interface TrendPoint {
+  bucket: string
+  // null for rates and averages of buckets without finished jobs
+  value: number | null
+  total: number
+  successful: number
+  failed: number
+}
+
+export interface Trend {
+  bucket: TrendBucket
+  metric: TrendMetric
+  from: string
+  to: string
+  platform?: string
+  points: TrendPoint[]
+}
+
+export interface PostMetric {
+  id: number
+  job_id: number
+  page_id: number
+  platform_name: string
+  date: string
+  views: number
+  readers: number
+  likes: number
+  comments: number
+  shares: number
+  collected_at: string
+}